@@ -6,6 +6,15 @@
 (
 cd $COZEJS 
 esbuild join.js --bundle --format=esm --platform=browser --minify --sourcemap --outfile=coze.min.js
+# For `<script>`, global `Coze`.
+esbuild join.js --bundle --format=iife --global-name=Coze --platform=browser --minify --sourcemap --outfile=dist/coze.iife.min.js
+# CommonJS for Node.
+esbuild join.js --bundle --format=cjs --platform=node --inject:node/crypto.js --outfile=dist/coze.cjs
+# ES modules, not bundled, so module boundaries are kept for tree shaking.
+# Includes Coze standard.  Node imports these through `node/join.js`.
+esbuild $(ls *.js standard/*.js all/*.js | grep -v '\.min\.js$') --format=esm --platform=neutral --minify --outbase=. --outdir=dist/esm
+cp coze.min.js     verifier/coze.min.js
+cp coze.min.js.map verifier/coze.min.js.map
 )
 
 # Coze all 
@@ -15,10 +24,18 @@ esbuild join_all.js --bundle --format=esm --platform=browser --minify --sourcema
 # Copy for testing
 cp coze_all.min.js     ../verifier/coze_all.min.js
 cp coze_all.min.js.map ../verifier/coze_all.min.js.map
+cd $COZEJS
+esbuild all/join_all.js --bundle --format=cjs --platform=node --inject:node/crypto.js --outfile=dist/coze_all.cjs
 )
 
 # Coze standard
 (
 cd $COZEJS/standard; 
 esbuild join_standard.js --bundle --format=esm --platform=browser --minify --sourcemap --outfile=coze_standard.min.js
-)
\ No newline at end of file
+)
+
+# Node smoke test of the ES modules and the CommonJS build.
+(
+cd $COZEJS
+node node/smoke_test.js
+)
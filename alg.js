@@ -1,5 +1,10 @@
 "use strict";
 
+import {
+	CozeAlgError,
+	ErrCodes,
+} from './error.js';
+
 // For more documentation and notes, see the main Coze README.
 
 export {
@@ -21,6 +26,12 @@ export {
 	Use,
 	CurveOrder,
 	CurveHalfOrder,
+	CurveOID,
+	JOSEAlg,
+	JOSECrv,
+	COSEAlg,
+	AlgFromJOSE,
+	AlgFromCOSE,
 }
 
 /**
@@ -126,9 +137,13 @@ function Params(alg) {
 		p.Curve = Curve(alg);
 		p.SigSize = SigSize(alg);
 		p.SigSizeB64 = Math.ceil(4 * p.SigSize / 3);
+		p.CurveOID = CurveOID(alg);
+		p.JOSECrv = JOSECrv(alg);
 	} catch (e) {
 		// ignore error
 	}
+	p.JOSEAlg = JOSEAlg(alg);
+	p.COSEAlg = COSEAlg(alg);
 
 	return p;
 }
@@ -164,7 +179,9 @@ function Genus(alg) {
 		case Algs.SHAKE256:
 			return GenAlgs.SHA3;
 		default:
-			throw new Error("alg.Genus: unsupported algorithm: " + alg);
+			throw new CozeAlgError("alg.Genus: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 	}
 }
 
@@ -197,7 +214,9 @@ function Family(alg) {
 		case Algs.SHAKE256:
 			return FamAlgs.SHA
 		default:
-			throw new Error("alg.Family:  unsupported algorithm: " + alg);
+			throw new CozeAlgError("alg.Family:  unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 	}
 }
 
@@ -239,7 +258,9 @@ function HashAlg(alg) {
 		case Algs.SHA3512:
 			return Algs.SHA3512
 		default:
-			throw new Error("alg.HashAlg:  unsupported algorithm: " + alg);
+			throw new CozeAlgError("alg.HashAlg:  unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 	}
 }
 
@@ -273,7 +294,9 @@ function HashSize(alg) {
 		case Algs.SHAKE256:
 			return 64;
 		default:
-			throw new Error("alg.HashSize: unsupported algorithm: " + alg);
+			throw new CozeAlgError("alg.HashSize: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 	}
 }
 
@@ -303,7 +326,9 @@ function SigSize(alg) {
 		case Algs.ES512:
 			return 132
 		default:
-			throw new Error("alg.SigSize: unsupported algorithm: " + alg);
+			throw new CozeAlgError("alg.SigSize: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 	}
 }
 
@@ -335,7 +360,9 @@ function XSize(alg) {
 		case Algs.ES512:
 			return 132 // X and Y are 66 bytes (Rounded up for P521)
 		default:
-			throw new Error("alg.XSize: unsupported algorithm: " + alg);
+			throw new CozeAlgError("alg.XSize: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 	}
 }
 
@@ -366,7 +393,9 @@ function DSize(alg) {
 		case Algs.ES512:
 			return 66
 		default:
-			throw new Error("alg.DSize: unsupported algorithm: " + alg);
+			throw new CozeAlgError("alg.DSize: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 	}
 }
 
@@ -382,7 +411,9 @@ See notes on the Go implementation of Coze for more.
 function Curve(alg) {
 	switch (alg) {
 		default:
-			throw new Error("alg.Curve: unsupported algorithm: " + alg);
+			throw new CozeAlgError("alg.Curve: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 		case Algs.ES224:
 			return Curves.P224;
 		case Algs.ES256:
@@ -412,7 +443,9 @@ See notes on the Go implementation of Coze for more.
 function Use(alg) {
 	switch (Genus(alg)) {
 		default:
-			throw new Error("alg.Use: unsupported algorithm: " + alg);
+			throw new CozeAlgError("alg.Use: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 		case GenAlgs.EdDSA:
 		case GenAlgs.ECDSA:
 			return Uses.Sig;
@@ -445,7 +478,9 @@ Curve Order returns the Curve's order.
 function CurveOrder(alg) {
 	switch (alg) {
 		default:
-			throw new Error("CurveOrder: unsupported curve: " + alg);
+			throw new CozeAlgError("CurveOrder: unsupported curve: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 		case  "ES224": case "ES256": case "ES384": case "ES512":
 			return order[alg];
 	}
@@ -460,8 +495,173 @@ Curve Order returns the Curve's order halved.
 function CurveHalfOrder(alg) {
 	switch (alg) {
 		default:
-			throw new Error("CurveHalfOrder: unsupported curve: " + alg);
+			throw new CozeAlgError("CurveHalfOrder: unsupported curve: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
 	 case  "ES224": case "ES256": case "ES384": case "ES512":
 			return halfOrder[alg];
 	}
 }
+
+/**
+CurveOID returns the dotted object identifier of the curve of a signature
+algorithm, e.g. "1.2.840.10045.3.1.7" for ES256 (P-256).  For EdDSA, the OID
+is the algorithm's (RFC 8410).
+@param   {Alg}     alg
+@returns {string}
+@throws  {error}
+*/
+function CurveOID(alg) {
+	switch (alg) {
+		default:
+			throw new CozeAlgError("alg.CurveOID: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
+		case Algs.ES224:
+			return "1.3.132.0.33";
+		case Algs.ES256:
+			return "1.2.840.10045.3.1.7";
+		case Algs.ES384:
+			return "1.3.132.0.34";
+		case Algs.ES512:
+			return "1.3.132.0.35";
+		case Algs.Ed25519:
+		case Algs.Ed25519ph:
+			return "1.3.101.112";
+		case Algs.Ed448:
+			return "1.3.101.113";
+	}
+}
+
+/**
+JOSEAlg returns the JOSE "alg" (RFC 7518, RFC 8037) for alg, e.g. "ES256".
+Ed25519 and Ed448 are JOSE's "EdDSA".  Returns "" for supported algs without a
+JOSE alg, e.g. ES224 and hashing algorithms.
+@param   {Alg}     alg
+@returns {string}
+@throws  {error}
+*/
+function JOSEAlg(alg) {
+	switch (alg) {
+		case Algs.ES256:
+		case Algs.ES384:
+		case Algs.ES512:
+			return alg;
+		case Algs.Ed25519:
+		case Algs.Ed448:
+			return "EdDSA";
+	}
+	Genus(alg); // Throws on unsupported alg.
+	return "";
+}
+
+/**
+JOSECrv returns the JOSE (JWK) "crv" for a signature algorithm, e.g. "P-256"
+for ES256.  Returns "" for ES224, which has no registered JOSE curve.
+@param   {Alg}     alg
+@returns {string}
+@throws  {error}
+*/
+function JOSECrv(alg) {
+	switch (alg) {
+		default:
+			throw new CozeAlgError("alg.JOSECrv: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
+		case Algs.ES224:
+			return "";
+		case Algs.ES256:
+		case Algs.ES384:
+		case Algs.ES512:
+			return Curve(alg);
+		case Algs.Ed25519:
+		case Algs.Ed25519ph:
+			return "Ed25519";
+		case Algs.Ed448:
+			return "Ed448";
+	}
+}
+
+// coseAlgs are the COSE algorithm identifiers (IANA "COSE Algorithms").  EdDSA
+// uses the fully specified identifiers (RFC 9864) since the polymorphic -8
+// does not identify the curve.
+const coseAlgs = {
+	"ES256": -7,
+	"ES384": -35,
+	"ES512": -36,
+	"Ed25519": -19,
+	"Ed448": -53,
+	"SHA-256": -16,
+	"SHA-384": -43,
+	"SHA-512": -44,
+	"SHAKE128": -18,
+	"SHAKE256": -45,
+};
+
+/**
+COSEAlg returns the COSE algorithm identifier for alg, e.g. -7 for ES256.
+Returns 0, which is reserved in COSE, for supported algs without a COSE
+identifier.
+@param   {Alg}     alg
+@returns {number}
+@throws  {error}
+*/
+function COSEAlg(alg) {
+	Genus(alg); // Throws on unsupported alg.
+	let id = coseAlgs[alg];
+	if (id === undefined) {
+		return 0;
+	}
+	return id;
+}
+
+/**
+AlgFromJOSE returns the Coze alg for a JOSE "alg".  JOSE's "EdDSA" does not
+identify the curve, so for "EdDSA" the JWK's crv ("Ed25519" or "Ed448") must
+be given.  The fully specified "Ed25519" and "Ed448" (RFC 9864) are also
+accepted.
+@param   {string}  name   JOSE alg, e.g. "ES256".
+@param   {string}  [crv]  JOSE crv, required for "EdDSA".
+@returns {Alg}
+@throws  {error}
+*/
+function AlgFromJOSE(name, crv) {
+	switch (name) {
+		case "ES256":
+		case "ES384":
+		case "ES512":
+		case "Ed25519":
+		case "Ed448":
+			return name;
+		case "EdDSA":
+			if (crv === "Ed25519" || crv === "Ed448") {
+				return crv;
+			}
+			throw new CozeAlgError("alg.AlgFromJOSE: EdDSA requires crv Ed25519 or Ed448, got: " + crv, ErrCodes.AlgUnsupported, {
+				alg: name
+			});
+		default:
+			throw new CozeAlgError("alg.AlgFromJOSE: unsupported JOSE alg: " + name, ErrCodes.AlgUnsupported, {
+				alg: name
+			});
+	}
+}
+
+/**
+AlgFromCOSE returns the Coze alg for a COSE algorithm identifier.  The
+polymorphic EdDSA identifier (-8) does not identify the curve and is not
+supported, use -19 (Ed25519) or -53 (Ed448).
+@param   {number}  id   COSE algorithm identifier, e.g. -7.
+@returns {Alg}
+@throws  {error}
+*/
+function AlgFromCOSE(id) {
+	for (const alg in coseAlgs) {
+		if (coseAlgs[alg] === id) {
+			return alg;
+		}
+	}
+	throw new CozeAlgError("alg.AlgFromCOSE: unsupported COSE alg: " + id, ErrCodes.AlgUnsupported, {
+		alg: id
+	});
+}
@@ -1,2 +1,6 @@
-var t={UnknownAlg:"UnknownAlg",ES224:"ES224",ES256:"ES256",ES384:"ES384",ES512:"ES512",Ed25519:"Ed25519",Ed25519ph:"Ed25519ph",Ed448:"Ed448",SHA224:"SHA-224",SHA256:"SHA-256",SHA384:"SHA-384",SHA512:"SHA-512",SHA3224:"SHA3-224",SHA3256:"SHA3-256",SHA3384:"SHA3-384",SHA3512:"SHA3-512",SHAKE128:"SHAKE128",SHAKE256:"SHAKE256"},d={EC:"EC",SHA:"SHA",RSA:"RSA"},u={ECDSA:"ECDSA",EdDSA:"EdDSA",SHA2:"SHA2",SHA3:"SHA3"},l={P224:"P-224",P256:"P-256",P384:"P-384",P521:"P-521",Curve25519:"Curve25519",Curve448:"Curve448"},H={Sig:"sig",Enc:"enc",Hsh:"hsh"};function I(r){let e={};e.Name=r,e.Genus=y(r),e.Family=Z(r),e.Use=W(r),e.Hash=S(r),e.HashSize=_(r),e.HashSizeB64=Math.ceil(4*e.HashSize/3);try{e.XSize=T(r),e.XSizeB64=Math.ceil(4*e.XSize/3),e.DSize=J(r),e.DSizeB64=Math.ceil(4*e.DSize/3),e.Curve=v(r),e.SigSize=E(r),e.SigSizeB64=Math.ceil(4*e.SigSize/3)}catch{}return e}function y(r){switch(r){case t.ES224:case t.ES256:case t.ES384:case t.ES512:return u.ECDSA;case t.Ed25519:case t.Ed25519ph:case t.Ed448:return u.EdDSA;case t.SHA224:case t.SHA256:case t.SHA384:case t.SHA512:return u.SHA2;case t.SHA3224:case t.SHA3256:case t.SHA3384:case t.SHA3512:case t.SHAKE128:case t.SHAKE256:return u.SHA3;default:throw new Error("alg.Genus: unsupported algorithm: "+r)}}function Z(r){switch(r){case t.ES224:case t.ES256:case t.ES384:case t.ES512:case t.Ed25519:case t.Ed25519ph:case t.Ed448:return d.EC;case t.SHA224:case t.SHA256:case t.SHA384:case t.SHA512:case t.SHA3224:case t.SHA3256:case t.SHA3384:case t.SHA3512:case t.SHAKE128:case t.SHAKE256:return d.SHA;default:throw new Error("alg.Family:  unsupported algorithm: "+r)}}function S(r){switch(r){case t.ES224:case t.SHA224:return t.SHA224;case t.SHA256:case t.ES256:return t.SHA256;case t.SHA384:case t.ES384:return t.SHA384;case t.SHA512:case t.ES512:case t.Ed25519:case t.Ed25519ph:return t.SHA512;case t.SHAKE128:return t.SHAKE128;case t.SHAKE256:case t.Ed448:return t.SHAKE256;case t.SHA3224:return t.SHA3224;case t.SHA3256:return t.SHA3256;case t.SHA3384:return t.SHA3384;case t.SHA3512:return t.SHA3512;default:throw new Error("alg.HashAlg:  unsupported algorithm: "+r)}}function _(r){switch(S(r)){case t.SHA224:case t.SHA3224:return 28;case t.SHA256:case t.SHA3256:case t.SHAKE128:return 32;case t.SHA384:case t.SHA3384:return 48;case t.SHA512:case t.SHA3512:case t.SHAKE256:return 64;default:throw new Error("alg.HashSize: unsupported algorithm: "+r)}}function E(r){switch(r){case t.ES224:return 56;case t.ES256:case t.Ed25519:case t.Ed25519ph:return 64;case t.ES384:return 96;case t.Ed448:return 114;case t.ES512:return 132;default:throw new Error("alg.SigSize: unsupported algorithm: "+r)}}function T(r){switch(r){case t.Ed25519:case t.Ed25519ph:return 32;case t.ES224:return 56;case t.Ed448:return 57;case t.ES256:return 64;case t.ES384:return 96;case t.ES512:return 132;default:throw new Error("alg.XSize: unsupported algorithm: "+r)}}function J(r){switch(r){case t.ES224:return 28;case t.ES256:case t.Ed25519:case t.Ed25519ph:return 32;case t.ES384:return 48;case t.Ed448:return 57;case t.ES512:return 66;default:throw new Error("alg.DSize: unsupported algorithm: "+r)}}function v(r){switch(r){default:throw new Error("alg.Curve: unsupported algorithm: "+r);case t.ES224:return l.P224;case t.ES256:return l.P256;case t.ES384:return l.P384;case t.ES512:return l.P521;case t.Ed25519:case t.Ed25519ph:return l.Curve25519;case t.Ed448:return l.Curve448}}function W(r){switch(y(r)){default:throw new Error("alg.Use: unsupported algorithm: "+r);case u.EdDSA:case u.ECDSA:return H.Sig;case u.SHA2:case u.SHA3:return H.Hsh}}var w={ES224:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFF16A2E0B8F03E13DD29455C5C2A3D"),ES256:BigInt("0xFFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551"),ES384:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFC7634D81F4372DDF581A0DB248B0A77AECEC196ACCC52973"),ES512:BigInt("0x1FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFA51868783BF2F966B7FCC0148F709A5D03BB5C9B8899C47AEBB6FB71E91386409")},Q={ES224:w.ES224>>BigInt(1),ES256:w.ES256>>BigInt(1),ES384:w.ES384>>BigInt(1),ES512:w.ES512>>BigInt(1)};function z(r){switch(r){default:throw new Error("CurveOrder: unsupported curve: "+r);case"ES224":case"ES256":case"ES384":case"ES512":return w[r]}}function U(r){switch(r){default:throw new Error("CurveHalfOrder: unsupported curve: "+r);case"ES224":case"ES256":case"ES384":case"ES512":return Q[r]}}var s={New:async function(r){switch(i(r)&&(r=t.ES256),r){case t.ES256:case t.ES384:case t.ES512:return await window.crypto.subtle.generateKey({name:u.ECDSA,namedCurve:v(r)},!0,["sign","verify"]);default:throw new Error("CryptoKey.New: Unsupported key algorithm:"+r)}},FromCozeKey:async function(r,e){if(y(r.alg)!=u.ECDSA)throw new Error("CryptoKey.FromCozeKey: unsupported CryptoKey algorithm: "+r.alg);var a={};a.use=H.Sig,a.crv=v(r.alg),a.kty=d.EC;let n=T(r.alg)/2,f=await p(r.x);if(a.x=await c(f.slice(0,n)),a.y=await c(f.slice(n)),i(r.d)||e)var o="verify";else o="sign",a.d=r.d;return await crypto.subtle.importKey("jwk",a,{name:u.ECDSA,namedCurve:a.crv},!0,[o])},ToPublic:async function(r){delete r.d,r.key_ops=["verify"]},ToCozeKey:async function(r){let e=await window.crypto.subtle.exportKey("jwk",r);var a={};a.alg=await s.algFromCrv(e.crv);let n=p(e.x),f=p(e.y);var o=new Uint8Array([...n,...f]);return a.x=c(o.buffer),e.hasOwnProperty("d")&&(a.d=e.d),a.tmb=await F(a),a},SignBuffer:async function(r,e){let a=await s.algFromCrv(r.algorithm.namedCurve),n=await window.crypto.subtle.sign({name:u.ECDSA,hash:{name:S(a)}},r,e);return n=N(a,n),n},SignBufferB64:async function(r,e){return await c(await s.SignBuffer(r,e))},SignString:async function(r,e){return await s.SignBufferB64(r,await A(e))},VerifyArrayBuffer:async function(r,e,a,n){return await $(r,n)?(await s.ToPublic(e),await window.crypto.subtle.verify({name:u.ECDSA,hash:{name:await s.GetSignHashAlgoFromCryptoKey(e)}},e,n,a)):!1},VerifyMsg:async function(r,e,a,n){return s.VerifyArrayBuffer(r,e,await A(a),await x(n))},GetSignHashAlgoFromCryptoKey:async function(r){return S(await s.algFromCrv(r.algorithm.namedCurve))},algFromCrv:async function(r){switch(r){case l.P224:var e=t.ES224;break;case l.P256:e=t.ES256;break;case l.P384:e=t.ES384;break;case l.P521:e=t.ES512;break;default:throw new Error("CryptoKey.ToCozeKey: Unsupported key algorithm.")}return e}};function V(r,e){if(typeof e!="bigint")throw new Error("IsLowS: s is not of type bigint");return U(r)>e}function Y(r,e){if(typeof e!="bigint")throw new Error("toLowS: s is not of type bigint");return V(r,e)?e:z(r)-e}async function or(r,e){let a=await x(e),n=await N(r,a);return c(n)}async function $(r,e){let a=await q(r,e);return V(r,a)}function q(r,e){let a=E(r)/2,n=e.slice(a);return O(n)}async function N(r,e){let a=E(r)/2,n=e.slice(0,a),f=e.slice(a),o=O(f),g=Y(r,o),h=rr(E(r)/2,g);var C=new Uint8Array(n.byteLength+h.byteLength);return C.set(new Uint8Array(n),0),C.set(new Uint8Array(h),n.byteLength),e=C.buffer,e}function O(r){let e=0n,a=new Uint8Array(r);for(let n=0;n<a.length;n++)e=(e<<8n)+BigInt(a[n]);return e}function rr(r,e){let a=new ArrayBuffer(r),n=new DataView(a);do r--,n.setUint8(r,Number(e&BigInt(255))),e>>=8n;while(r>0);return a}var er=["alg","x"];async function fr(r){if(i(r)&&(r=t.ES256),y(r)==u.ECDSA)var e=await s.New(r);else throw new Error("Coze.NewKey: only ECDSA algs are currently supported.");let a=await s.ToCozeKey(e.privateKey);return a.iat=Math.floor(Date.now()/1e3),a.tmb=await F(a),a.kid="My Cyphr.me Key.",a}async function F(r){if(i(r.alg)||i(r.x))throw new Error("Coze.Thumbprint: alg or x is empty.");return m(r,await S(r.alg),er)}async function lr(r){if(i(r.d))return console.error("Coze key missing `d`"),!1;try{let e="7AtyaCHO2BAG06z0W1tOQlZFWbhxGgqej4k9-HWP3DE-zshRbrE-69DIfgY704_FDYez7h_rEI1WQVKhv5Hd5Q",a=await B(e,r);return P(e,r,a)}catch{return!1}}async function Sr(r){if(typeof r!="object")return console.error("Correct: CozeKey must be passed in as an object."),!1;if(i(r.alg))return console.error("Correct: Alg must be set"),!1;let e=I(r.alg),a=i(r.tmb),n=i(r.x),f=i(r.d);if(a&&n&&f)return console.error("Correct: At least one of [x, tmb, d] must be set"),!1;if(n&&f)return a||r.tmb.length!==e.HashSizeB64?(console.error("Correct: Incorrect `tmb` size: ",r.tmb.length),!1):!0;if(!n&&r.x.length!==e.XSizeB64)return console.error("Correct: Incorrect x size: ",r.x.length),!1;if(!a&&!n){let o=await F(r);if(r.tmb!==o)return console.error("Correct: Incorrect given `tmb`: ",r.tmb),!1}if(!f&&!n){let o=await s.FromCozeKey(r),g=await A("Test Signing"),h=await s.SignBuffer(o,g),C=await s.FromCozeKey(r,!0);if(!await s.VerifyArrayBuffer(r.alg,C,g,h))return console.error("Correct: private key invalid."),!1}return!0}async function cr(r,e){if(i(r))throw new Error("CozeKey.Revoke: Private key not set.  Cannot sign message");var a={};a.pay={},i(e)||(a.pay.msg=e),a.pay.rvk=Math.round(Date.now()/1e3);let n=r.rvk;return delete r.rvk,a=await j(a,r),n!==void 0?r.rvk=n:r.rvk=a.pay.rvk,a}function D(r){return!(i(r.rvk)||!(parseInt(r.rvk)>0))}var Ar=["alg","iat","tmb","typ"];async function j(r,e,a){if(console.log(),D(e))throw new Error("SignCoze: Cannot sign with revoked key.");return r.pay.alg=e.alg,r.pay.tmb=await F(e),r.pay.iat=Math.round(Date.now()/1e3),i(a)||(r.pay=await b(r.pay,a)),r.sig=await B(JSON.stringify(r.pay),e),r}async function B(r,e){return s.SignBufferB64(await s.FromCozeKey(e),await A(r))}async function yr(r,e,a){if(D(e))throw new Error("SignCozeRaw: Cannot sign with revoked key.");if(!i(r.pay.alg)&&r.pay.alg!==e.alg)throw new Error("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.");if(!i(r.pay.tmb)&&r.pay.tmb!==e.tmb)throw new Error("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.");return i(a)||(r.pay=await b(r.pay,a)),r.sig=await B(JSON.stringify(r.pay),e),r}async function k(r,e){if(!i(r.pay.alg)&&r.pay.alg!==e.alg)throw new Error("VerifyCoze: Coze key alg mismatch with coze.pay.alg.");if(!i(r.pay.tmb)&&r.pay.tmb!==e.tmb)throw new Error("VerifyCoze: Coze key tmb mismatch with coze.pay.tmb.");return P(JSON.stringify(r.pay),e,r.sig)}async function P(r,e,a){return s.VerifyMsg(e.alg,await s.FromCozeKey(e,!0),r,a)}async function Fr(r,e){if(i(r.pay))throw new Error("Meta: coze.pay must exist.");let a={};if(i(e)){if(i(r.pay.alg))throw new Error("Meta: either coze.pay.alg or parameter alg must be set.");a.alg=r.pay.alg}else a.alg=e;if(!i(r.pay.alg)&&a.alg!==r.pay.alg)throw new Error(`Meta: coze.pay.alg (${r.pay.alg}) and parameter alg (${e}) do not match. `);return i(r.pay.iat)||(a.iat=r.pay.iat),i(r.pay.tmb)||(a.tmb=r.pay.tmb),i(r.pay.typ)||(a.typ=r.pay.typ),a.can=await X(r.pay),a.cad=await m(r.pay,S(a.alg)),i(r.sig)||(a.sig=r.sig,a.czd=await m({cad:a.cad,sig:a.sig},S(a.alg))),a}async function A(r){return new TextEncoder().encode(r).buffer}function x(r){return p(r).buffer}function p(r){if(r=r.replace(/-/g,"+").replace(/_/g,"/"),btoa(atob(r)).replace(/=/g,"")!==r)throw new Error("Non-canonical base64 string");return Uint8Array.from(atob(r),a=>a.charCodeAt(0))}function c(r){return btoa(String.fromCharCode.apply(null,new Uint8Array(r))).replace(/\+/g,"-").replace(/\//g,"_").replace(/=/g,"")}function i(r){return typeof r=="function"?!1:Array.isArray(r)&&r.length==0?!0:r===Object(r)?Object.keys(r).length===0:!tr(r)}function tr(r){return!(r===!1||r==="false"||r===void 0||r==="undefined"||r===""||r===0||r==="0"||r===null||r==="null"||r==="NaN"||Number.isNaN(r)||r===Object(r))}function X(r){return Object.keys(r)}async function b(r,e){if(i(e))return r;let a={};for(let n of e)a[n]=r[n];return a}async function ar(r,e){return JSON.stringify(await b(r,e))}async function nr(r,e,a){if(i(e))throw new Error("Hash is not given");return await crypto.subtle.digest(e,await A(await ar(r,a)))}async function m(r,e,a){return await c(await nr(r,e,a))}async function wr(r,e){if(!Array.isArray(r))return k(r,e);var a={VerifiedAll:!1,VerifiedCount:0,FailedCount:0,FailedCozies:[],FailedPosition:[]};let n=0,f=[...r];for(let o of f)i(o.coze)||(o=o.coze),await k(o,e)?a.VerifiedCount++:(a.FailedCount++,a.FailedCozies.push(o),a.FailedPosition.push(n)),n++;return a.FailedCount==0&&a.VerifiedCount>1&&(a.VerifiedAll=!0),a}export{t as Algs,c as ArrayBufferTo64ut,p as B64ToUint8Array,x as B64uToArrayBuffer,X as Canon,b as Canonical,nr as CanonicalHash,m as CanonicalHash64,ar as CanonicalS,Sr as Correct,s as CryptoKey,v as Curve,U as CurveHalfOrder,z as CurveOrder,l as Curves,J as DSize,d as FamAlgs,Z as Family,u as GenAlgs,y as Genus,S as HashAlg,_ as HashSize,D as IsRevoked,$ as IsSigLowS,Fr as Meta,fr as NewKey,I as Params,Ar as PayCanon,cr as Revoke,A as SToArrayBuffer,E as SigSize,or as SigToLowS,j as Sign,yr as SignCozeRaw,B as SignPay,F as Thumbprint,er as TmbCanon,W as Use,H as Uses,lr as Valid,k as Verify,wr as VerifyCozeArray,P as VerifyPay,T as XSize,i as isEmpty};
+var s={AlgUnsupported:"ERR_ALG_UNSUPPORTED",AlgMismatch:"ERR_ALG_MISMATCH",TmbMismatch:"ERR_TMB_MISMATCH",KeyInvalid:"ERR_KEY_INVALID",KeyRevoked:"ERR_KEY_REVOKED",KeyMismatch:"ERR_KEY_MISMATCH",KeyNotFound:"ERR_KEY_NOT_FOUND",SigInvalid:"ERR_SIG_INVALID",CanonInvalid:"ERR_CANON_INVALID",CanonMissing:"ERR_CANON_MISSING",CanonExtra:"ERR_CANON_EXTRA",PayMissing:"ERR_PAY_MISSING",PrvMismatch:"ERR_PRV_MISMATCH",ThresholdInvalid:"ERR_THRESHOLD_INVALID",IatInvalid:"ERR_IAT_INVALID",Expired:"ERR_EXPIRED",NotYetValid:"ERR_NOT_YET_VALID",DigSize:"ERR_DIG_SIZE",HashInvalid:"ERR_HASH_INVALID",DuplicateField:"ERR_DUPLICATE_FIELD",JSONInvalid:"ERR_JSON_INVALID",FieldReserved:"ERR_FIELD_RESERVED",B64Invalid:"ERR_B64_INVALID",HexInvalid:"ERR_HEX_INVALID",QRCapacity:"ERR_QR_CAPACITY",QRInvalid:"ERR_QR_INVALID",KeystoreUnavailable:"ERR_KEYSTORE_UNAVAILABLE",BrowserRequired:"ERR_BROWSER_REQUIRED"},v=class extends Error{constructor(t,n,r){super(t),this.name="CozeError",this.code=n,r!==void 0&&Object.assign(this,r)}},m=class extends v{constructor(t,n,r){super(t,n,r),this.name="CozeKeyError"}},H=class extends v{constructor(t,n,r){super(t,n,r),this.name="CozeVerifyError"}},Y=class extends v{constructor(t,n,r){super(t,n,r),this.name="CozeCanonError"}},x=class extends v{constructor(t,n,r){super(t,n,r),this.name="CozeAlgError"}};async function G(e){return new TextEncoder().encode(e).buffer}var ge=class extends v{constructor(t,n){super(t,s.B64Invalid,{field:n}),this.name="B64Error"}};function ve(e,t){return B(e,t).buffer}function B(e,t){let n=c(t)?"":` for field "${t}"`;if(typeof e!="string")throw new ge(`B64ToUint8Array: b64ut must be a string${n}.`,t);if(!/^[A-Za-z0-9_-]*$/.test(e)||e.length%4===1)throw new ge(`B64ToUint8Array: invalid b64ut${n}.`,t);let r=atob(e.replace(/-/g,"+").replace(/_/g,"/")),i=Uint8Array.from(r,a=>a.charCodeAt(0));if(k(i)!==e)throw new ge(`B64ToUint8Array: non-canonical b64ut${n}.`,t);return i}function In(e){let t=e.replace(/\s/g,"").replace(/=+$/,"").replace(/-/g,"+").replace(/_/g,"/");if(!/^[A-Za-z0-9+/]*$/.test(t)||t.length%4===1)throw new ge("B64Lenient: invalid base64.");return k(Uint8Array.from(atob(t),n=>n.charCodeAt(0)))}function k(e){return btoa(String.fromCharCode.apply(null,new Uint8Array(e))).replace(/\+/g,"-").replace(/\//g,"_").replace(/=/g,"")}function We(e){if(e=e.replace(/^0x/i,""),e.length%2!==0)throw new v("HexToUint8Array: hex must have an even number of characters, got "+e.length+".",s.HexInvalid);if(!/^[0-9a-fA-F]*$/.test(e))throw new v("HexToUint8Array: invalid hex character.",s.HexInvalid);let t=new Uint8Array(e.length/2);for(let n=0;n<t.length;n++)t[n]=parseInt(e.substring(n*2,n*2+2),16);return t}function Me(e){return Array.from(new Uint8Array(e),t=>t.toString(16).padStart(2,"0")).join("")}function Tn(e){return k(We(e))}function Dn(e){return Me(B(e))}function c(e){return typeof e=="function"?!1:Array.isArray(e)&&e.length==0?!0:e===Object(e)?Object.keys(e).length===0:!Bn(e)}function Bn(e){return!(e===!1||e==="false"||e===void 0||e==="undefined"||e===""||e===0||e==="0"||e===null||e==="null"||e==="NaN"||Number.isNaN(e)||e===Object(e))}var u={UnknownAlg:"UnknownAlg",ES224:"ES224",ES256:"ES256",ES384:"ES384",ES512:"ES512",Ed25519:"Ed25519",Ed25519ph:"Ed25519ph",Ed448:"Ed448",SHA224:"SHA-224",SHA256:"SHA-256",SHA384:"SHA-384",SHA512:"SHA-512",SHA3224:"SHA3-224",SHA3256:"SHA3-256",SHA3384:"SHA3-384",SHA3512:"SHA3-512",SHAKE128:"SHAKE128",SHAKE256:"SHAKE256"},De={EC:"EC",SHA:"SHA",RSA:"RSA"},I={ECDSA:"ECDSA",EdDSA:"EdDSA",SHA2:"SHA2",SHA3:"SHA3"},$={P224:"P-224",P256:"P-256",P384:"P-384",P521:"P-521",Curve25519:"Curve25519",Curve448:"Curve448"},ye={Sig:"sig",Enc:"enc",Hsh:"hsh"};function Ye(e){let t={};t.Name=e,t.Genus=P(e),t.Family=Hn(e),t.Use=Kn(e),t.Hash=M(e),t.HashSize=te(e),t.HashSizeB64=Math.ceil(4*t.HashSize/3);try{t.XSize=Q(e),t.XSizeB64=Math.ceil(4*t.XSize/3),t.DSize=L(e),t.DSizeB64=Math.ceil(4*t.DSize/3),t.Curve=he(e),t.SigSize=z(e),t.SigSizeB64=Math.ceil(4*t.SigSize/3),t.CurveOID=_n(e),t.JOSECrv=Mn(e)}catch{}return t.JOSEAlg=Un(e),t.COSEAlg=Pn(e),t}function P(e){switch(e){case u.ES224:case u.ES256:case u.ES384:case u.ES512:return I.ECDSA;case u.Ed25519:case u.Ed25519ph:case u.Ed448:return I.EdDSA;case u.SHA224:case u.SHA256:case u.SHA384:case u.SHA512:return I.SHA2;case u.SHA3224:case u.SHA3256:case u.SHA3384:case u.SHA3512:case u.SHAKE128:case u.SHAKE256:return I.SHA3;default:throw new x("alg.Genus: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function Hn(e){switch(e){case u.ES224:case u.ES256:case u.ES384:case u.ES512:case u.Ed25519:case u.Ed25519ph:case u.Ed448:return De.EC;case u.SHA224:case u.SHA256:case u.SHA384:case u.SHA512:case u.SHA3224:case u.SHA3256:case u.SHA3384:case u.SHA3512:case u.SHAKE128:case u.SHAKE256:return De.SHA;default:throw new x("alg.Family:  unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function M(e){switch(e){case u.ES224:case u.SHA224:return u.SHA224;case u.SHA256:case u.ES256:return u.SHA256;case u.SHA384:case u.ES384:return u.SHA384;case u.SHA512:case u.ES512:case u.Ed25519:case u.Ed25519ph:return u.SHA512;case u.SHAKE128:return u.SHAKE128;case u.SHAKE256:case u.Ed448:return u.SHAKE256;case u.SHA3224:return u.SHA3224;case u.SHA3256:return u.SHA3256;case u.SHA3384:return u.SHA3384;case u.SHA3512:return u.SHA3512;default:throw new x("alg.HashAlg:  unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function te(e){switch(M(e)){case u.SHA224:case u.SHA3224:return 28;case u.SHA256:case u.SHA3256:case u.SHAKE128:return 32;case u.SHA384:case u.SHA3384:return 48;case u.SHA512:case u.SHA3512:case u.SHAKE256:return 64;default:throw new x("alg.HashSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function z(e){switch(e){case u.ES224:return 56;case u.ES256:case u.Ed25519:case u.Ed25519ph:return 64;case u.ES384:return 96;case u.Ed448:return 114;case u.ES512:return 132;default:throw new x("alg.SigSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function Q(e){switch(e){case u.Ed25519:case u.Ed25519ph:return 32;case u.ES224:return 56;case u.Ed448:return 57;case u.ES256:return 64;case u.ES384:return 96;case u.ES512:return 132;default:throw new x("alg.XSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function L(e){switch(e){case u.ES224:return 28;case u.ES256:case u.Ed25519:case u.Ed25519ph:return 32;case u.ES384:return 48;case u.Ed448:return 57;case u.ES512:return 66;default:throw new x("alg.DSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function he(e){switch(e){default:throw new x("alg.Curve: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case u.ES224:return $.P224;case u.ES256:return $.P256;case u.ES384:return $.P384;case u.ES512:return $.P521;case u.Ed25519:case u.Ed25519ph:return $.Curve25519;case u.Ed448:return $.Curve448}}function Kn(e){switch(P(e)){default:throw new x("alg.Use: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case I.EdDSA:case I.ECDSA:return ye.Sig;case I.SHA2:case I.SHA3:return ye.Hsh}}var Te={ES224:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFF16A2E0B8F03E13DD29455C5C2A3D"),ES256:BigInt("0xFFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551"),ES384:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFC7634D81F4372DDF581A0DB248B0A77AECEC196ACCC52973"),ES512:BigInt("0x1FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFA51868783BF2F966B7FCC0148F709A5D03BB5C9B8899C47AEBB6FB71E91386409")},Rn={ES224:Te.ES224>>BigInt(1),ES256:Te.ES256>>BigInt(1),ES384:Te.ES384>>BigInt(1),ES512:Te.ES512>>BigInt(1)};function Pe(e){switch(e){default:throw new x("CurveOrder: unsupported curve: "+e,s.AlgUnsupported,{alg:e});case"ES224":case"ES256":case"ES384":case"ES512":return Te[e]}}function pt(e){switch(e){default:throw new x("CurveHalfOrder: unsupported curve: "+e,s.AlgUnsupported,{alg:e});case"ES224":case"ES256":case"ES384":case"ES512":return Rn[e]}}function _n(e){switch(e){default:throw new x("alg.CurveOID: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case u.ES224:return"1.3.132.0.33";case u.ES256:return"1.2.840.10045.3.1.7";case u.ES384:return"1.3.132.0.34";case u.ES512:return"1.3.132.0.35";case u.Ed25519:case u.Ed25519ph:return"1.3.101.112";case u.Ed448:return"1.3.101.113"}}function Un(e){switch(e){case u.ES256:case u.ES384:case u.ES512:return e;case u.Ed25519:case u.Ed448:return"EdDSA"}return P(e),""}function Mn(e){switch(e){default:throw new x("alg.JOSECrv: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case u.ES224:return"";case u.ES256:case u.ES384:case u.ES512:return he(e);case u.Ed25519:case u.Ed25519ph:return"Ed25519";case u.Ed448:return"Ed448"}}var Xe={ES256:-7,ES384:-35,ES512:-36,Ed25519:-19,Ed448:-53,"SHA-256":-16,"SHA-384":-43,"SHA-512":-44,SHAKE128:-18,SHAKE256:-45};function Pn(e){P(e);let t=Xe[e];return t===void 0?0:t}function Nr(e,t){switch(e){case"ES256":case"ES384":case"ES512":case"Ed25519":case"Ed448":return e;case"EdDSA":if(t==="Ed25519"||t==="Ed448")return t;throw new x("alg.AlgFromJOSE: EdDSA requires crv Ed25519 or Ed448, got: "+t,s.AlgUnsupported,{alg:e});default:throw new x("alg.AlgFromJOSE: unsupported JOSE alg: "+e,s.AlgUnsupported,{alg:e})}}function Or(e){for(let t in Xe)if(Xe[t]===e)return t;throw new x("alg.AlgFromCOSE: unsupported COSE alg: "+e,s.AlgUnsupported,{alg:e})}async function ne(e,t){if(c(e))throw new x("Hash is not given",s.AlgUnsupported);if(e===u.SHA224){let n=vt(!0);return n.update(new Uint8Array(t)),n.digest().buffer}if(Ft[e]!==void 0){let n=kt(e);return n.update(new Uint8Array(t)),n.digest().buffer}return crypto.subtle.digest(e,t)}async function ze(e,t,n){let r=e===u.SHA384||e===u.SHA512?128:64;t.length>r&&(t=new Uint8Array(await ne(e,t)));let i=new Uint8Array(r+n.length),a=new Uint8Array(r);for(let o=0;o<r;o++){let d=o<t.length?t[o]:0;i[o]=d^54,a[o]=d^92}i.set(n,r);let l=new Uint8Array(await ne(e,i)),f=new Uint8Array(r+l.length);return f.set(a),f.set(l,r),new Uint8Array(await ne(e,f))}async function zn(e,t){let n=M(e),r;if(typeof t=="string")r=await G(t);else if(t instanceof Uint8Array||t instanceof ArrayBuffer)r=t;else if(typeof Blob<"u"&&t instanceof Blob)r=await t.arrayBuffer();else throw new TypeError("Hash: input must be a string, Uint8Array, ArrayBuffer, or Blob.");return k(await ne(n,r))}async function Nn(e,t,n){let r=M(e),i;switch(r){case u.SHA224:case u.SHA256:i=vt(r===u.SHA224);break;case u.SHA384:case u.SHA512:i=jn(r===u.SHA384);break;case u.SHA3224:case u.SHA3256:case u.SHA3384:case u.SHA3512:case u.SHAKE128:case u.SHAKE256:i=kt(r);break;default:throw new x("HashStream: unsupported hashing algorithm: "+r,s.AlgUnsupported,{alg:e})}c(n)&&(n={});let a=4*1024*1024;n.chunkSize>0&&(a=n.chunkSize);let l=function(){if(n.signal!==void 0&&n.signal.aborted)throw n.signal.reason},f=0,o=function(d){i.update(d),f+=d.length,typeof n.onProgress=="function"&&n.onProgress(f)};if(typeof Blob<"u"&&t instanceof Blob)for(let d=0;d<t.size;d+=a)l(),o(new Uint8Array(await t.slice(d,d+a).arrayBuffer()));else if(typeof ReadableStream<"u"&&t instanceof ReadableStream){let d=t.getReader();try{for(;;){l();let g=await d.read();if(g.done)break;o(g.value)}}catch(g){throw await d.cancel(g),g}}else throw new TypeError("HashStream: input must be a Blob or ReadableStream.");return l(),k(i.digest())}async function Lr(e,t,n,r,i){return e[t]=await zn(r,n),c(i)||(c(i.sizeField)||(typeof n=="string"?e[i.sizeField]=(await G(n)).byteLength:typeof Blob<"u"&&n instanceof Blob?e[i.sizeField]=n.size:e[i.sizeField]=n.byteLength),!c(i.nameField)&&!c(n.name)&&(e[i.nameField]=n.name)),e}async function Et(e,t,n){typeof Blob<"u"&&e instanceof Blob&&(e=[e]),e=Array.from(e),c(n)&&(n={});let r=e.reduce((l,f)=>l+f.size,0),i=0,a=[];for(let l of e){let f=await Nn(t,l,{chunkSize:n.chunkSize,signal:n.signal,onProgress:function(o){typeof n.onProgress=="function"&&n.onProgress(i+o,r)}});i+=l.size,a.push({name:c(l.name)?"":l.name,size:l.size,dig:f})}return a}async function Jr(e,t,n,r){let i=await Et(t,n,r);return e.file=typeof Blob<"u"&&t instanceof Blob?i[0]:i,e}async function jr(e,t,n,r){let i=[];Array.isArray(e.file)?i=e.file:typeof e.file=="object"&&e.file!==null&&(i=[e.file]);let l=(await Et(t,n,r)).map(f=>({...f,match:i.some(o=>o!==null&&o.dig===f.dig&&(o.size===void 0||o.size===f.size))}));return{match:l.length>0&&l.every(f=>f.match),files:l}}var On=new Uint32Array([1116352408,1899447441,3049323471,3921009573,961987163,1508970993,2453635748,2870763221,3624381080,310598401,607225278,1426881987,1925078388,2162078206,2614888103,3248222580,3835390401,4022224774,264347078,604807628,770255983,1249150122,1555081692,1996064986,2554220882,2821834349,2952996808,3210313671,3336571891,3584528711,113926993,338241895,666307205,773529912,1294757372,1396182291,1695183700,1986661051,2177026350,2456956037,2730485921,2820302411,3259730800,3345764771,3516065817,3600352804,4094571909,275423344,430227734,506948616,659060556,883997877,958139571,1322822218,1537002063,1747873779,1955562222,2024104815,2227730452,2361852424,2428436474,2756734187,3204031479,3329325298]),Vn=[3238371032,914150663,812702999,4144912697,4290775857,1750603025,1694076839,3204075428],$n=[1779033703,3144134277,1013904242,2773480762,1359893119,2600822924,528734635,1541459225];function vt(e){let t=new Uint32Array(e?Vn:$n),n=new Uint32Array(64),r=new Uint8Array(64),i=0,a=0,l=function(f,o){for(let p=0;p<16;p++)n[p]=f[o+4*p]<<24|f[o+4*p+1]<<16|f[o+4*p+2]<<8|f[o+4*p+3];for(let p=16;p<64;p++){let S=n[p-15],E=n[p-2],J=(S>>>7|S<<25)^(S>>>18|S<<14)^S>>>3,ae=(E>>>17|E<<15)^(E>>>19|E<<13)^E>>>10;n[p]=n[p-16]+J+n[p-7]+ae|0}let d=t[0],g=t[1],y=t[2],h=t[3],w=t[4],b=t[5],A=t[6],C=t[7];for(let p=0;p<64;p++){let S=(w>>>6|w<<26)^(w>>>11|w<<21)^(w>>>25|w<<7),E=w&b^~w&A,J=C+S+E+On[p]+n[p]|0,ae=(d>>>2|d<<30)^(d>>>13|d<<19)^(d>>>22|d<<10),Se=d&g^d&y^g&y,Ce=ae+Se|0;C=A,A=b,b=w,w=h+J|0,h=y,y=g,g=d,d=J+Ce|0}t[0]+=d,t[1]+=g,t[2]+=y,t[3]+=h,t[4]+=w,t[5]+=b,t[6]+=A,t[7]+=C};return{update:function(f){a+=f.length;let o=0;if(i>0){for(;i<64&&o<f.length;)r[i++]=f[o++];if(i<64)return;l(r,0),i=0}for(;o+64<=f.length;o+=64)l(f,o);for(;o<f.length;)r[i++]=f[o++]},digest:function(){let f=a*8;r[i++]=128,i>56&&(r.fill(0,i),l(r,0),i=0),r.fill(0,i);let o=new DataView(r.buffer);o.setUint32(56,Math.floor(f/4294967296)),o.setUint32(60,f>>>0),l(r,0);let d=new Uint8Array(32),g=new DataView(d.buffer);for(let y=0;y<8;y++)g.setUint32(4*y,t[y]);return e?d.slice(0,28):d}}}var bt=new Uint32Array([1116352408,3609767458,1899447441,602891725,3049323471,3964484399,3921009573,2173295548,961987163,4081628472,1508970993,3053834265,2453635748,2937671579,2870763221,3664609560,3624381080,2734883394,310598401,1164996542,607225278,1323610764,1426881987,3590304994,1925078388,4068182383,2162078206,991336113,2614888103,633803317,3248222580,3479774868,3835390401,2666613458,4022224774,944711139,264347078,2341262773,604807628,2007800933,770255983,1495990901,1249150122,1856431235,1555081692,3175218132,1996064986,2198950837,2554220882,3999719339,2821834349,766784016,2952996808,2566594879,3210313671,3203337956,3336571891,1034457026,3584528711,2466948901,113926993,3758326383,338241895,168717936,666307205,1188179964,773529912,1546045734,1294757372,1522805485,1396182291,2643833823,1695183700,2343527390,1986661051,1014477480,2177026350,1206759142,2456956037,344077627,2730485921,1290863460,2820302411,3158454273,3259730800,3505952657,3345764771,106217008,3516065817,3606008344,3600352804,1432725776,4094571909,1467031594,275423344,851169720,430227734,3100823752,506948616,1363258195,659060556,3750685593,883997877,3785050280,958139571,3318307427,1322822218,3812723403,1537002063,2003034995,1747873779,3602036899,1955562222,1575990012,2024104815,1125592928,2227730452,2716904306,2361852424,442776044,2428436474,593698344,2756734187,3733110249,3204031479,2999351573,3329325298,3815920427,3391569614,3928383900,3515267271,566280711,3940187606,3454069534,4118630271,4000239992,116418474,1914138554,174292421,2731055270,289380356,3203993006,460393269,320620315,685471733,587496836,852142971,1086792851,1017036298,365543100,1126000580,2618297676,1288033470,3409855158,1501505948,4234509866,1607167915,987167468,1816402316,1246189591]),Ln=[3418070365,3238371032,1654270250,914150663,2438529370,812702999,355462360,4144912697,1731405415,4290775857,2394180231,1750603025,3675008525,1694076839,1203062813,3204075428],Jn=[1779033703,4089235720,3144134277,2227873595,1013904242,4271175723,2773480762,1595750129,1359893119,2917565137,2600822924,725511199,528734635,4215389547,1541459225,327033209];function jn(e){let t=new Uint32Array(e?Ln:Jn),n=new Int32Array(80),r=new Int32Array(80),i=new Uint8Array(128),a=0,l=0,f=function(o,d){for(let T=0;T<16;T++){let U=d+8*T;n[T]=o[U]<<24|o[U+1]<<16|o[U+2]<<8|o[U+3],r[T]=o[U+4]<<24|o[U+5]<<16|o[U+6]<<8|o[U+7]}for(let T=16;T<80;T++){let U=n[T-15],O=r[T-15],Ee=(U>>>1|O<<31)^(U>>>8|O<<24)^U>>>7,Qe=(O>>>1|U<<31)^(O>>>8|U<<24)^(O>>>7|U<<25);U=n[T-2],O=r[T-2];let j=(U>>>19|O<<13)^(O>>>29|U<<3)^U>>>6,Ue=(O>>>19|U<<13)^(U>>>29|O<<3)^(O>>>6|U<<26),Ie=(r[T-16]>>>0)+(Qe>>>0)+(r[T-7]>>>0)+(Ue>>>0);n[T]=n[T-16]+Ee+n[T-7]+j+Math.floor(Ie/4294967296),r[T]=Ie}let g=t[0],y=t[1],h=t[2],w=t[3],b=t[4],A=t[5],C=t[6],p=t[7],S=t[8],E=t[9],J=t[10],ae=t[11],Se=t[12],Ce=t[13],je=t[14],Ge=t[15];for(let T=0;T<80;T++){let U=(S>>>14|E<<18)^(S>>>18|E<<14)^(E>>>9|S<<23),O=(E>>>14|S<<18)^(E>>>18|S<<14)^(S>>>9|E<<23),Ee=S&J^~S&Se,Qe=E&ae^~E&Ce,j=(Ge>>>0)+(O>>>0)+(Qe>>>0)+bt[2*T+1]+(r[T]>>>0),Ue=je+U+Ee+bt[2*T]+n[T]+Math.floor(j/4294967296)|0,Ie=j>>>0,Sn=(g>>>28|y<<4)^(y>>>2|g<<30)^(y>>>7|g<<25),Cn=(y>>>28|g<<4)^(g>>>2|y<<30)^(g>>>7|y<<25),En=g&h^g&b^h&b,vn=y&w^y&A^w&A;j=(Cn>>>0)+(vn>>>0);let Fn=Sn+En+Math.floor(j/4294967296)|0,kn=j>>>0;je=Se,Ge=Ce,Se=J,Ce=ae,J=S,ae=E,j=(p>>>0)+Ie,S=C+Ue+Math.floor(j/4294967296)|0,E=j>>>0,C=b,p=A,b=h,A=w,h=g,w=y,j=Ie+kn,g=Ue+Fn+Math.floor(j/4294967296)|0,y=j>>>0}let le=function(T,U,O){let Ee=t[T+1]+(O>>>0);t[T]=t[T]+U+Math.floor(Ee/4294967296),t[T+1]=Ee};le(0,g,y),le(2,h,w),le(4,b,A),le(6,C,p),le(8,S,E),le(10,J,ae),le(12,Se,Ce),le(14,je,Ge)};return{update:function(o){l+=o.length;let d=0;if(a>0){for(;a<128&&d<o.length;)i[a++]=o[d++];if(a<128)return;f(i,0),a=0}for(;d+128<=o.length;d+=128)f(o,d);for(;d<o.length;)i[a++]=o[d++]},digest:function(){let o=l*8;i[a++]=128,a>112&&(i.fill(0,a),f(i,0),a=0),i.fill(0,a);let d=new DataView(i.buffer);d.setUint32(120,Math.floor(o/4294967296)),d.setUint32(124,o>>>0),f(i,0);let g=new Uint8Array(64),y=new DataView(g.buffer);for(let h=0;h<16;h++)y.setUint32(4*h,t[h]);return e?g.slice(0,48):g}}}var St=new Uint32Array([1,0,32898,0,32906,2147483648,2147516416,2147483648,32907,0,2147483649,0,2147516545,2147483648,32777,2147483648,138,0,136,0,2147516425,0,2147483658,0,2147516555,0,139,2147483648,32905,2147483648,32771,2147483648,32770,2147483648,128,2147483648,32778,0,2147483658,2147483648,2147516545,2147483648,32896,2147483648,2147483649,0,2147516424,2147483648]),Gn=[0,1,62,28,27,36,44,6,55,20,3,10,43,25,39,41,45,15,21,8,18,2,61,56,14],Ft={"SHA3-224":[144,28,6],"SHA3-256":[136,32,6],"SHA3-384":[104,48,6],"SHA3-512":[72,64,6],SHAKE128:[168,32,31],SHAKE256:[136,64,31]};function Ct(e){let t=new Uint32Array(10),n=new Uint32Array(50);for(let r=0;r<24;r++){for(let i=0;i<5;i++)t[2*i]=e[2*i]^e[2*i+10]^e[2*i+20]^e[2*i+30]^e[2*i+40],t[2*i+1]=e[2*i+1]^e[2*i+11]^e[2*i+21]^e[2*i+31]^e[2*i+41];for(let i=0;i<5;i++){let a=2*((i+1)%5),l=2*((i+4)%5),f=t[l]^(t[a]<<1|t[a+1]>>>31),o=t[l+1]^(t[a+1]<<1|t[a]>>>31);for(let d=0;d<25;d+=5)e[2*(i+d)]^=f,e[2*(i+d)+1]^=o}for(let i=0;i<5;i++)for(let a=0;a<5;a++){let l=i+5*a,f=e[2*l],o=e[2*l+1],d=Gn[l];d>=32&&([f,o]=[o,f],d-=32);let g=2*(a+5*((2*i+3*a)%5));d===0?(n[g]=f,n[g+1]=o):(n[g]=f<<d|o>>>32-d,n[g+1]=o<<d|f>>>32-d)}for(let i=0;i<25;i+=5)for(let a=0;a<5;a++){let l=2*(a+i),f=2*((a+1)%5+i),o=2*((a+2)%5+i);e[l]=n[l]^~n[f]&n[o],e[l+1]=n[l+1]^~n[f+1]&n[o+1]}e[0]^=St[2*r],e[1]^=St[2*r+1]}}function kt(e){let[t,n,r]=Ft[e],i=new Uint32Array(50),a=new Uint8Array(t),l=0,f=function(){let o=new DataView(a.buffer);for(let d=0;d<t/4;d++)i[d]^=o.getUint32(4*d,!0);Ct(i),l=0};return{update:function(o){for(let d=0;d<o.length;d++)a[l++]=o[d],l===t&&f()},digest:function(){a.fill(0,l),a[l]^=r,a[t-1]^=128,f();let o=new Uint8Array(n);for(let d=0;d<n;d+=t){d>0&&Ct(i);let g=new DataView(new ArrayBuffer(t));for(let y=0;y<t/4;y++)g.setUint32(4*y,i[y],!0);o.set(new Uint8Array(g.buffer,0,Math.min(t,n-d)),d)}return o}}}function It(e){return Object.keys(e)}async function me(e,t){return c(t)?e:qe(e,t)}function qe(e,t){if(Array.isArray(e))return e.map(r=>qe(r,t));if(e===null||typeof e!="object")return e;let n={};for(let[r,i]of Wn(t))i===null||e[r]===void 0?n[r]=e[r]:n[r]=qe(e[r],i);return n}function Wn(e){let t=[];if(Array.isArray(e))for(let r of e)if(typeof r=="string")t.push([r,null]);else if(r!==null&&typeof r=="object"&&!Array.isArray(r))for(let[i,a]of Object.entries(r))t.push([i,a]);else throw new Y("Canonical: invalid canon element: "+JSON.stringify(r),s.CanonInvalid);else if(e!==null&&typeof e=="object")for(let[r,i]of Object.entries(e))t.push([r,i!==null&&typeof i=="object"?i:null]);else throw new Y("Canonical: canon must be an array or object.",s.CanonInvalid);if(new Set(t.map(r=>r[0])).size!==t.length)throw new Y("Canonical: Canon cannot have duplicate fields.",s.CanonInvalid);return t}async function Ne(e,t,n){return!c(n)&&n.normalizeUnicode===!0&&(e=q(e)),JSON.stringify(await me(e,t))}function q(e){if(typeof e=="string")return e.normalize("NFC");if(Array.isArray(e))return e.map(q);if(e!==null&&typeof e=="object"){let t={};for(let n of Object.keys(e))t[n]=q(e[n]);return t}return e}async function Zn(e,t,n,r){if(c(t))throw new x("Hash is not given",s.AlgUnsupported);if(e instanceof Uint8Array||e instanceof ArrayBuffer){if(c(n)&&c(r))return await ne(t,e);e=JSON.parse(new TextDecoder().decode(e))}return await ne(t,await G(await Ne(e,n,r)))}async function ke(e,t,n,r){return await k(await Zn(e,t,n,r))}var Xn={ES224:{p:BigInt("0xffffffffffffffffffffffffffffffff000000000000000000000001"),b:BigInt("0xb4050a850c04b3abf54132565044b0b7d7bfd8ba270b39432355ffb4"),gx:BigInt("0xb70e0cbd6bb4bf7f321390b94a03c1d356c21122343280d6115c1d21"),gy:BigInt("0xbd376388b5f723fb4c22dfe6cd4375a05a07476444d5819985007e34")},ES256:{p:BigInt("0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),b:BigInt("0x5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),gx:BigInt("0x6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),gy:BigInt("0x4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5")},ES384:{p:BigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff"),b:BigInt("0xb3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef"),gx:BigInt("0xaa87ca22be8b05378eb1c71ef320ad746e1d3b628ba79b9859f741e082542a385502f25dbf55296c3a545e3872760ab7"),gy:BigInt("0x3617de4a96262c6f5d9e98bf9292dc29f8f41dbd289a147ce9da3113b5f0b8c00a60b1ce1d7e819d7a431d7c90ea0e5f")},ES512:{p:(1n<<521n)-1n,b:BigInt("0x0051953eb9618e1c9a1f929a21a0b68540eea2da725b99b315f3b8b489918ef109e156193951ec7e937b1652c0bd3bb1bf073573df883d2c34f1ef451fd46b503f00"),gx:BigInt("0x00c6858e06b70404e9cd9e3ecb662395b4429c648139053fb521f828af606b4d3dbaa14b5e77efe75928fe1dc127a2ffa8de3348b3c1856a429bf97e7e31c2e5bd66"),gy:BigInt("0x011839296a789a3bc0045c8a5fb42c7d1bd998f54449579b446817afbd17273e662c97ee72995ef42640c550b9013fad0761353c7086a272c24088be94769fd16650")}},_={New:async function(e){let t=Dt(e),n={alg:e,d:k(ee(L(e),t)),x:await _.PublicFromD(e,t)};return{privateKey:await _.FromCozeKey(n),publicKey:await _.FromCozeKey(n,!0)}},FromCozeKey:async function(e,t){let n=we(e.alg),r=B(e.x);if(r.length!==Q(e.alg))throw new m("ECDSA.FromCozeKey: incorrect x size.",s.KeyInvalid,{field:"x"});let i=Q(e.alg)/2,a={x:fe(r.slice(0,i)),y:fe(r.slice(i))};if(!qn(n,a))throw new m("ECDSA.FromCozeKey: the key is not on the curve.",s.KeyInvalid,{field:"x"});let l={type:"public",extractable:!0,algorithm:{name:I.ECDSA,namedCurve:he(e.alg)},usages:["verify"],ecdsa:{alg:e.alg,point:a}};if(!c(e.d)&&!t){let f=fe(B(e.d));if(f<=0n||f>=n.n)throw new m("ECDSA.FromCozeKey: invalid private key.",s.KeyInvalid,{field:"d"});l.type="private",l.usages=["sign"],l.ecdsa.d=f}return l},IsKey:function(e){return typeof e=="object"&&e!==null&&typeof e.ecdsa=="object"},ToCozeKey:function(e){let t=e.ecdsa.alg,n=Q(t)/2,r={alg:t,x:k(xe(ee(n,e.ecdsa.point.x),ee(n,e.ecdsa.point.y)))};return e.ecdsa.d!==void 0&&(r.d=k(ee(L(t),e.ecdsa.d))),r},PublicFromD:async function(e,t){let n=we(e),r=et(n,Oe(n,t,{x:n.gx,y:n.gy,z:1n})),i=Q(e)/2;return k(xe(ee(i,r.x),ee(i,r.y)))},KeyFromSeed:async function(e,t){let n=we(e),r=M(e),i=Math.ceil(n.nBits/8),a=BigInt(i*8-n.nBits),l=await ze(r,new TextEncoder().encode("Coze NewKeyFromSeed"),t);for(let f=0;f<256;f++){let o=xe(new TextEncoder().encode(e),new Uint8Array([f])),d=fe(await er(r,l,o,i))>>a;if(d>0n&&d<n.n)return{alg:e,d:k(ee(L(e),d)),x:await _.PublicFromD(e,d)}}throw new m("ECDSA.KeyFromSeed: no valid scalar derived.",s.KeyInvalid,{field:"seed"})},SignBuffer:async function(e,t,n){let r=e.ecdsa.alg,i=await ne(M(r),t);return _.SignDigest(e,new Uint8Array(i),n)},SignDigest:async function(e,t,n){if(e.type!=="private")throw new m("ECDSA.SignDigest: key must be private.",s.KeyInvalid,{field:"d"});let r=e.ecdsa.alg,i=we(r),a=Ve(i,t),l=null;for(n===!0&&(l=await Yn(r,e.ecdsa.d,t));;){let f=l===null?Dt(r):await l.next(),o=F(et(i,Oe(i,f,{x:i.gx,y:i.gy,z:1n})).x,i.n);if(o===0n)continue;let d=F(tt(f,i.n)*(a+o*e.ecdsa.d),i.n);if(d===0n)continue;let g=z(r)/2;return xe(ee(g,o),ee(g,d)).buffer}},VerifyBuffer:async function(e,t,n){let r=await ne(M(e.ecdsa.alg),t);return _.VerifyDigest(e,new Uint8Array(r),n)},VerifyDigest:async function(e,t,n){let r=e.ecdsa.alg,i=we(r);if(n=new Uint8Array(n),n.length!==z(r))return!1;let a=z(r)/2,l=fe(n.slice(0,a)),f=fe(n.slice(a));if(l<=0n||l>=i.n||f<=0n||f>=i.n)return!1;let o=Ve(i,t),d=tt(f,i.n),g=Oe(i,F(o*d,i.n),{x:i.gx,y:i.gy,z:1n}),y=Oe(i,F(l*d,i.n),{x:e.ecdsa.point.x,y:e.ecdsa.point.y,z:1n}),h=Ht(i,g,y);return h.z===0n?!1:F(et(i,h).x,i.n)===l}};function we(e){let t=Xn[e];if(t===void 0)throw new x("ECDSA: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});return t.n===void 0&&(t.n=Pe(e),t.nBits=t.n.toString(2).length),t}function Dt(e){let t=we(e),n=Math.ceil(t.nBits/8),r=BigInt(n*8-t.nBits);for(;;){let i=fe(crypto.getRandomValues(new Uint8Array(n)))>>r;if(i>0n&&i<t.n)return i}}async function Yn(e,t,n){let r=we(e),i=M(e),a=Math.ceil(r.nBits/8),l=te(e),f=(w,...b)=>ze(i,w,Kt(b)),o=ee(a,t),d=ee(a,F(Ve(r,n),r.n)),g=new Uint8Array(l).fill(1),y=new Uint8Array(l);y=await f(y,g,[0],o,d),g=await f(y,g),y=await f(y,g,[1],o,d),g=await f(y,g);let h=!1;return{next:async function(){for(;;){h&&(y=await f(y,g,[0]),g=await f(y,g)),h=!0;let w=new Uint8Array(0);for(;w.length<a;)g=await f(y,g),w=xe(w,g);let b=Ve(r,w);if(b>0n&&b<r.n)return b}}}}function Ve(e,t){let n=fe(t),r=t.length*8;return r>e.nBits&&(n>>=BigInt(r-e.nBits)),n}function F(e,t){let n=e%t;return n<0n?n+t:n}function tt(e,t){let[n,r]=[F(e,t),t],[i,a]=[1n,0n];for(;r!==0n;){let l=n/r;[n,r]=[r,n-l*r],[i,a]=[a,i-l*a]}if(n!==1n)throw new m("ECDSA: no modular inverse.",s.KeyInvalid);return F(i,t)}function qn(e,t){return t.x<0n||t.x>=e.p||t.y<0n||t.y>=e.p?!1:F(t.y*t.y-(t.x*t.x*t.x-3n*t.x+e.b),e.p)===0n}function et(e,t){let n=tt(t.z,e.p),r=F(n*n,e.p);return{x:F(t.x*r,e.p),y:F(t.y*r*n,e.p)}}function Bt(e,t){if(t.z===0n||t.y===0n)return{x:0n,y:1n,z:0n};let n=e.p,r=F(t.z*t.z,n),i=F(t.y*t.y,n),a=F(t.x*i,n),l=F(3n*(t.x-r)*(t.x+r),n),f=F(l*l-8n*a,n),o=F((t.y+t.z)*(t.y+t.z)-i-r,n),d=F(l*(4n*a-f)-8n*i*i,n);return{x:f,y:d,z:o}}function Ht(e,t,n){if(t.z===0n)return n;if(n.z===0n)return t;let r=e.p,i=F(t.z*t.z,r),a=F(n.z*n.z,r),l=F(t.x*a,r),f=F(n.x*i,r),o=F(t.y*n.z*a,r),d=F(n.y*t.z*i,r),g=F(f-l,r),y=F(2n*(d-o),r);if(g===0n)return y===0n?Bt(e,t):{x:0n,y:1n,z:0n};let h=F(4n*g*g,r),w=F(g*h,r),b=F(l*h,r),A=F(y*y-w-2n*b,r),C=F(y*(b-A)-2n*o*w,r),p=F(((t.z+n.z)*(t.z+n.z)-i-a)*g,r);return{x:A,y:C,z:p}}function Oe(e,t,n){let r={x:0n,y:1n,z:0n};for(let i=BigInt(t.toString(2).length-1);i>=0n;i--)r=Bt(e,r),t>>i&1n&&(r=Ht(e,r,n));return r}async function er(e,t,n,r){let i=new Uint8Array(0),a=new Uint8Array(0);for(let l=1;i.length<r;l++)a=await ze(e,t,Kt([a,n,[l]])),i=xe(i,a);return i.slice(0,r)}function fe(e){let t=0n;for(let n of e)t=(t<<8n)+BigInt(n);return t}function ee(e,t){let n=new Uint8Array(e);for(let r=e-1;r>=0;r--)n[r]=Number(t&0xffn),t>>=8n;return n}function xe(e,t){let n=new Uint8Array(e.length+t.length);return n.set(e,0),n.set(t,e.length),n}function Kt(e){let t=new Uint8Array(0);for(let n of e)t=xe(t,new Uint8Array(n));return t}var rt=new WeakMap;function ti(){rt=new WeakMap}var K={New:async function(e){switch(c(e)&&(e=u.ES256),e){case u.ES224:return _.New(e);case u.ES256:case u.ES384:case u.ES512:return await crypto.subtle.generateKey({name:I.ECDSA,namedCurve:he(e)},!0,["sign","verify"]);case u.Ed25519:try{return await crypto.subtle.generateKey({name:u.Ed25519},!0,["sign","verify"])}catch(t){throw Ut("CryptoKey.New",e,t)}default:throw new x("CryptoKey.New: Unsupported key algorithm:"+e,s.AlgUnsupported,{alg:e})}},FromCozeKey:async function(e,t){let n=c(e.d)||t?"verify":"sign",r=rt.get(e);r===void 0&&(r={},rt.set(e,r));let i=r[n];if(i!==void 0&&i.alg===e.alg&&i.x===e.x&&i.d===e.d)return i.key;let a=rr(e,t);r[n]={alg:e.alg,x:e.x,d:e.d,key:a};try{return await a}catch(l){throw r[n]!==void 0&&r[n].key===a&&delete r[n],l}},ToPublic:async function(e){delete e.d,e.key_ops=["verify"]},ToCozeKey:async function(e){if(_.IsKey(e)){let n=_.ToCozeKey(e);return n.tmb=await D(n),n}let t=await crypto.subtle.exportKey("jwk",e);return tr(t)},SignBuffer:async function(e,t){let n=await K.algFromCryptoKey(e);if(_.IsKey(e))var r=await _.SignBuffer(e,t);else r=await crypto.subtle.sign(Rt(n),e,t);return P(n)==I.ECDSA&&(r=zt(n,r)),r},SignBufferB64:async function(e,t){return await k(await K.SignBuffer(e,t))},SignString:async function(e,t){return await K.SignBufferB64(e,await G(t))},VerifyArrayBuffer:async function(e,t,n,r){return _.IsKey(t)?_.VerifyBuffer(t,n,r):(await K.ToPublic(t),await crypto.subtle.verify(Rt(await K.algFromCryptoKey(t)),t,r,n))},VerifyMsg:async function(e,t,n,r){return K.VerifyArrayBuffer(e,t,await G(n),await ve(r))},GetSignHashAlgoFromCryptoKey:async function(e){return M(await K.algFromCryptoKey(e))},algFromCryptoKey:async function(e){return e.algorithm.name===u.Ed25519?u.Ed25519:K.algFromCrv(e.algorithm.namedCurve)},algFromCrv:async function(e){switch(e){case u.Ed25519:var t=u.Ed25519;break;case $.P224:t=u.ES224;break;case $.P256:t=u.ES256;break;case $.P384:t=u.ES384;break;case $.P521:t=u.ES512;break;default:throw new x("CryptoKey.ToCozeKey: Unsupported key algorithm.",s.AlgUnsupported,{crv:e})}return t}};function _t(e){if(c(e.x))throw new m("CozeKeyToJWK: key x must be set.",s.KeyInvalid,{field:"x"});var t={};switch(e.alg){case u.Ed25519:t.kty="OKP",t.crv=u.Ed25519,t.alg="EdDSA",t.use=ye.Sig,t.x=e.x;break;case u.ES256:case u.ES384:case u.ES512:{t.kty=De.EC,t.crv=he(e.alg),t.alg=e.alg,t.use=ye.Sig;let n=Q(e.alg)/2,r=B(e.x);if(r.length!==n*2)throw new m("CozeKeyToJWK: incorrect x size for "+e.alg+".",s.KeyInvalid,{field:"x"});t.x=k(r.slice(0,n)),t.y=k(r.slice(n));break}default:throw new x("CozeKeyToJWK: unsupported alg: "+e.alg,s.AlgUnsupported,{alg:e.alg})}return c(e.d)||(t.d=e.d),t}async function tr(e){let t;switch(e.crv){case u.Ed25519:if(e.kty!=="OKP")throw new m("JWKToCozeKey: kty must be OKP for Ed25519.",s.KeyInvalid,{field:"kty"});t=u.Ed25519;break;case $.P256:case $.P384:case $.P521:if(e.kty!==De.EC)throw new m("JWKToCozeKey: kty must be EC for curve "+e.crv+".",s.KeyInvalid,{field:"kty"});t=await K.algFromCrv(e.crv);break;default:throw new x("JWKToCozeKey: unsupported crv: "+e.crv,s.AlgUnsupported,{alg:e.crv})}if(!c(e.alg)&&e.alg!==t&&!(t===u.Ed25519&&e.alg==="EdDSA"))throw new x("JWKToCozeKey: JWK alg "+e.alg+" mismatch with crv "+e.crv+".",s.AlgMismatch,{alg:e.alg});var n={alg:t};if(c(e.x))throw new m("JWKToCozeKey: JWK x must be set.",s.KeyInvalid,{field:"x"});if(t===u.Ed25519){if(B(e.x).length!==Q(t))throw new m("JWKToCozeKey: incorrect x size for Ed25519.",s.KeyInvalid,{field:"x"});n.x=e.x,c(e.d)||(n.d=e.d)}else{if(c(e.y))throw new m("JWKToCozeKey: JWK y must be set.",s.KeyInvalid,{field:"y"});let r=Q(t)/2;n.x=k(ir(nt("x",r,B(e.x)),nt("y",r,B(e.y))).buffer),c(e.d)||(n.d=k(nt("d",L(t),B(e.d)).buffer))}return n.tmb=await D(n),n}async function nr(e,t){let n={name:u.Ed25519};try{return c(e.d)||t?await crypto.subtle.importKey("raw",B(e.x),n,!0,["verify"]):await crypto.subtle.importKey("jwk",_t(e),n,!0,["sign"])}catch(r){throw Ut("CryptoKey.FromCozeKey",e.alg,r)}}async function rr(e,t){if(e.alg===u.Ed25519)return nr(e,t);if(e.alg===u.ES224)return _.FromCozeKey(e,t);if(P(e.alg)!=I.ECDSA)throw new x("CryptoKey.FromCozeKey: unsupported CryptoKey algorithm: "+e.alg,s.AlgUnsupported,{alg:e.alg});let n=_t(e);if(c(e.d)||t){var r="verify";delete n.d}else r="sign";return await crypto.subtle.importKey("jwk",n,{name:I.ECDSA,namedCurve:n.crv},!0,[r])}function nt(e,t,n){if(n.length>t)throw new m("JWKToCozeKey: incorrect "+e+" size.",s.KeyInvalid,{field:e});let r=new Uint8Array(t);return r.set(n,t-n.length),r}function ir(e,t){let n=new Uint8Array(e.length+t.length);return n.set(e,0),n.set(t,e.length),n}function Rt(e){return e===u.Ed25519?{name:u.Ed25519}:{name:I.ECDSA,hash:{name:M(e)}}}function Ut(e,t,n){return n instanceof DOMException&&n.name==="NotSupportedError"?new x(e+": alg "+t+" unsupported in this browser.",s.AlgUnsupported,{alg:t}):n}function Mt(e,t){if(typeof t!="bigint")throw new TypeError("IsLowS: s is not of type bigint");return pt(e)>t}function ar(e,t){if(typeof t!="bigint")throw new TypeError("toLowS: s is not of type bigint");return Mt(e,t)?t:Pe(e)-t}async function it(e,t){let n=await ve(t),r=await zt(e,n);return k(r)}async function Pt(e,t){let n=await lr(e,t);return Mt(e,n)}function lr(e,t){let n=z(e)/2,r=t.slice(n);return Nt(r)}async function zt(e,t){let n=z(e)/2,r=t.slice(0,n),i=t.slice(n),a=Nt(i),l=ar(e,a),f=fr(z(e)/2,l);var o=new Uint8Array(r.byteLength+f.byteLength);return o.set(new Uint8Array(r),0),o.set(new Uint8Array(f),r.byteLength),t=o.buffer,t}function Nt(e){let t=0n,n=new Uint8Array(e);for(let r=0;r<n.length;r++)t=(t<<8n)+BigInt(n[r]);return t}function fr(e,t){let n=new ArrayBuffer(e),r=new DataView(n);do e--,r.setUint8(e,Number(t&BigInt(255))),t>>=8n;while(e>0);return n}var or=["alg","x"],sr=["alg","iat","kid","tmb","typ","rvk","x"];async function ai(e){if(c(e)&&(e=u.ES256),P(e)==I.ECDSA||e==u.Ed25519)var t=await K.New(e);else throw new x("Coze.NewKey: only ECDSA algs and Ed25519 are currently supported.",s.AlgUnsupported,{alg:e});let n=await K.ToCozeKey(t.privateKey);return n.iat=Math.floor(Date.now()/1e3),n.tmb=await D(n),n.kid="My Cyphr.me Key.",n}var Vt=new WeakMap,lt=[48,46,2,1,0,48,5,6,3,43,101,112,4,34,4,32];async function $t(e){let t=new Uint8Array(lt.length+e.length);t.set(lt),t.set(e,lt.length);let n=await crypto.subtle.importKey("pkcs8",t,{name:u.Ed25519},!0,["sign"]);return(await crypto.subtle.exportKey("jwk",n)).x}async function ur(e,t){if(!(t instanceof Uint8Array))throw new TypeError("Coze.NewKeyFromSeed: seed must be a Uint8Array.");let n;if(P(e)==I.ECDSA){let r=te(e)/2;if(t.length<r)throw new m(`Coze.NewKeyFromSeed: seed must be at least ${r} bytes for ${e}.`,s.KeyInvalid,{field:"seed"});n=await _.KeyFromSeed(e,t)}else if(e==u.Ed25519){if(t.length!==L(e))throw new m("Coze.NewKeyFromSeed: Ed25519 seed must be 32 bytes.",s.KeyInvalid,{field:"seed"});n={alg:e,d:k(t),x:await $t(t)}}else throw new x("Coze.NewKeyFromSeed: only ECDSA algs and Ed25519 are currently supported.",s.AlgUnsupported,{alg:e});return n.tmb=await D(n),n}var dr=21e4;async function li(e,t,n,r){if(typeof n=="string"&&(n=new TextEncoder().encode(n)),!(n instanceof Uint8Array)||n.length<16)throw new m("Coze.NewKeyFromPassword: salt must be at least 16 bytes.",s.KeyInvalid,{field:"salt"});let i=dr;if(!c(r)&&r.iterations!==void 0&&(i=r.iterations),!Number.isSafeInteger(i)||i<1)throw new m("Coze.NewKeyFromPassword: iterations must be a positive integer.",s.KeyInvalid,{field:"iterations"});let a=L(u.Ed25519);P(e)==I.ECDSA&&(a=te(e));let l=await crypto.subtle.importKey("raw",new TextEncoder().encode(t.normalize("NFC")),"PBKDF2",!1,["deriveBits"]),f=await crypto.subtle.deriveBits({name:"PBKDF2",hash:u.SHA512,salt:n,iterations:i},l,a*8);return{key:await ur(e,new Uint8Array(f)),params:{alg:e,kdf:"PBKDF2",hash:u.SHA512,iterations:i,salt:k(n)}}}async function D(e){if(c(e.alg)||c(e.x))throw new m("Coze.Thumbprint: alg or x is empty.",s.KeyInvalid,{field:c(e.alg)?"alg":"x"});let t=Vt.get(e);if(t!==void 0&&t.alg===e.alg&&t.x===e.x)return t.tmb;let n=await ke(e,await M(e.alg),or);return Vt.set(e,{alg:e.alg,x:e.x,tmb:n}),n}async function fi(e){let t=await D(e);if(t!==e.tmb)throw new m("Coze.ThumbprintMatch: key.tmb does not match the calculated thumbprint.",s.TmbMismatch,{field:"tmb"});return t}function Lt(e){let t={};for(let[n,r]of Object.entries(e))sr.includes(n)&&(t[n]=r);return t}function oi(e){return Jt(e)!==void 0}function si(e){let t=Jt(e);if(t!==void 0)throw new m(`AssertPublic: key has private component "${t}".`,s.KeyInvalid,{field:t})}function Jt(e){if(!(e===null||typeof e!="object"))return Object.keys(e).find(t=>t.toLowerCase()==="d")}async function ui(e){let t=[],n={},r=function(d,g,y){let h={name:d,status:g};c(y)||(h.message=y),t.push(h),n[d]=g},i=(...d)=>d.every(g=>n[g]==="pass"),a=function(d,...g){r(d,"skip","Requires passing "+g.filter(y=>n[y]!=="pass").join(", ")+".")},l={};(typeof e!="object"||e===null)&&(e={});let f;try{f=Ye(e.alg),f.Use!==ye.Sig?r("alg_known","fail",`alg "${e.alg}" is not a signing alg.`):r("alg_known","pass")}catch{r("alg_known","fail",`alg "${e.alg}" is not supported.`)}let o=[];for(let d of["x","d","tmb"])if(!c(e[d]))try{l[d]=B(e[d],d)}catch{o.push(d)}c(e.x)&&c(e.d)&&c(e.tmb)?r("b64ut","fail","At least one of x, d, and tmb must be set."):o.length>0?r("b64ut","fail","Not strict b64ut: "+o.join(", ")+"."):r("b64ut","pass");for(let[d,g,y]of[["x_length","x","XSize"],["d_length","d","DSize"]])c(e[g])?r(d,"skip",`No ${g}.`):i("alg_known","b64ut")?l[g].length!==f[y]?r(d,"fail",`${g} is ${l[g].length} bytes, ${e.alg} requires ${f[y]}.`):r(d,"pass"):a(d,"alg_known","b64ut"),d==="x_length"&&(c(e.x)?r("y_length","skip","No x."):i("alg_known","b64ut")?f.Genus!==I.ECDSA?r("y_length","skip",`${e.alg} has no Y coordinate.`):l.x.length===f.XSize/2?r("y_length","fail","x is only the X coordinate.  Coze x is X || Y."):l.x.length!==f.XSize?a("y_length","x_length"):r("y_length","pass"):a("y_length","alg_known","b64ut"));if(c(e.tmb)?r("tmb_matches","skip","No tmb."):i("alg_known","b64ut")?c(e.x)?l.tmb.length!==f.HashSize?r("tmb_matches","fail",`tmb is ${l.tmb.length} bytes, ${e.alg} requires ${f.HashSize}.`):r("tmb_matches","pass"):i("x_length")?await D(e)!==e.tmb?r("tmb_matches","fail","tmb does not match the thumbprint of alg and x."):r("tmb_matches","pass"):a("tmb_matches","x_length"):a("tmb_matches","alg_known","b64ut"),c(e.d)||c(e.x))r("d_derives_x","skip","Requires d and x.");else if(!i("x_length","d_length"))a("d_derives_x","x_length","d_length");else try{let d;f.Genus===I.ECDSA?d=await _.PublicFromD(e.alg,BigInt("0x"+Me(l.d))):e.alg===u.Ed25519&&(d=await $t(l.d)),d===void 0?r("d_derives_x","skip",`Deriving x is not supported for ${e.alg}.`):d!==e.x?r("d_derives_x","fail","x is not the public key of d."):r("d_derives_x","pass")}catch(d){r("d_derives_x","fail","d is invalid: "+d.message)}if(c(e.d)||c(e.x))r("sign_verify_roundtrip","skip","Requires d and x.");else if(n.d_derives_x==="fail"||!i("x_length","d_length"))a("sign_verify_roundtrip","x_length","d_length","d_derives_x");else try{let d="Coze Diagnose",g=await ie(d,e);await se(d,e,g)?r("sign_verify_roundtrip","pass"):r("sign_verify_roundtrip","fail","Signature by d did not verify with x.")}catch(d){r("sign_verify_roundtrip","fail",d.message)}return{ok:t.every(d=>d.status!=="fail"),checks:t}}async function di(e){if(c(e.d))return console.error("Coze key missing `d`"),!1;try{let t="7AtyaCHO2BAG06z0W1tOQlZFWbhxGgqej4k9-HWP3DE-zshRbrE-69DIfgY704_FDYez7h_rEI1WQVKhv5Hd5Q",n=await ie(t,e);return se(t,e,n)}catch{return!1}}async function ci(e){if(typeof e!="object")return console.error("Correct: CozeKey must be passed in as an object."),!1;if(c(e.alg))return console.error("Correct: Alg must be set"),!1;let t=Ye(e.alg),n=c(e.tmb),r=c(e.x),i=c(e.d);if(n&&r&&i)return console.error("Correct: At least one of [x, tmb, d] must be set"),!1;for(let a of["x","d","tmb"])if(!c(e[a]))try{B(e[a],a)}catch(l){return console.error("Correct: "+l.message),!1}if(r&&i)return n||e.tmb.length!==t.HashSizeB64?(console.error("Correct: Incorrect `tmb` size: ",e.tmb.length),!1):!0;if(!r&&e.x.length!==t.XSizeB64)return console.error("Correct: Incorrect x size: ",e.x.length),!1;if(!n&&!r){let a=await D(e);if(e.tmb!==a)return console.error("Correct: Incorrect given `tmb`: ",e.tmb),!1}if(!i&&!r){let a=await K.FromCozeKey(e),l=await G("Test Signing"),f=await K.SignBuffer(a,l),o=await K.FromCozeKey(e,!0);if(!await K.VerifyArrayBuffer(e.alg,o,l,f))return console.error("Correct: private key invalid."),!1}return!0}async function gi(e,t){if(c(e))throw new m("CozeKey.Revoke: Private key not set.  Cannot sign message",s.KeyInvalid,{field:"d"});typeof t=="string"&&(t={msg:t}),c(t)&&(t={});for(let i of["alg","iat","tmb","rvk"])if(i in t)throw new v(`CozeKey.Revoke: "${i}" is set by Revoke and may not be given.`,s.FieldReserved,{field:i});var n={};n.pay={},c(t.typ)||(n.pay.typ=t.typ),n.pay.rvk=Math.round(Date.now()/1e3),c(t.msg)||(n.pay.msg=t.msg);for(let[i,a]of Object.entries(t))i!=="typ"&&i!=="msg"&&(n.pay[i]=a);let r=e.rvk;delete e.rvk;try{n=await jt(n,e,null,{setStandard:!0})}catch(i){throw r!==void 0&&(e.rvk=r),i}return r!==void 0?e.rvk=r:e.rvk=n.pay.rvk,n}function Z(e){let t=e.rvk;return t==null?!1:typeof t=="number"&&Number.isInteger(t)?t>0:!0}async function yi(e,t){let n=e.pay.rvk;return!Number.isSafeInteger(n)||n<=0||e.pay.tmb!==await D(t)||Number.isInteger(t.rvk)&&t.rvk>0&&n<t.rvk?!1:oe(e,t,{allowRevoked:!0})}async function Ae(e,t){let n=e instanceof Map;if(!n&&!Array.isArray(e))return e;if(c(t))throw new m("LookupKey: no key for tmb: tmb is empty.",s.KeyNotFound,{field:"tmb"});let r=e;n&&(r=e.has(t)?[e.get(t)]:[]);for(let i of r){if(c(i))continue;let a;try{a=await D(i)}catch{continue}if(a===t)return i}throw new m(`LookupKey: no key for tmb ${t}.`,s.KeyNotFound,{field:"tmb",tmb:t})}var ue=2,$e=3,pe=4,be=6,V=48,Qt=160,ft=161,Wt=[42,134,72,206,61,2,1],Zt=[43,101,112],ot={ES224:[43,129,4,0,33],ES256:[42,134,72,206,61,3,1,7],ES384:[43,129,4,0,34],ES512:[43,129,4,0,35]};async function xi(e){let t=hr(e),n;switch(t.label){case"PUBLIC KEY":n=gr(t.der);break;case"PRIVATE KEY":n=await yr(t.der);break;case"EC PRIVATE KEY":n=await Xt(t.der,null);break;default:throw new m("PEMToCozeKey: unsupported PEM type: "+t.label,s.KeyInvalid,{field:"pem"})}return n.tmb=await D(n),n}function Ai(e,t){let n=!c(t)&&t.private===!0,r=!c(t)&&t.sec1===!0;if(c(e.x))throw new m("CozeKeyToPEM: key x must be set.",s.KeyInvalid,{field:"x"});if(n&&c(e.d))throw new m("CozeKeyToPEM: private key d must be set.",s.KeyInvalid,{field:"d"});let i=B(e.x);if(i.length!==Q(e.alg))throw new m("CozeKeyToPEM: incorrect x size for "+e.alg+".",s.KeyInvalid,{field:"x"});if(e.alg===u.Ed25519){if(r)throw new x("CozeKeyToPEM: SEC1 is only for EC keys.",s.AlgUnsupported,{alg:e.alg});let g=R(V,R(be,Zt));if(!n)return Be("PUBLIC KEY",R(V,g,R($e,[0],i)));let y=R(pe,B(e.d));return Be("PRIVATE KEY",R(V,R(ue,[0]),g,R(pe,y)))}let a=ot[e.alg];if(a===void 0)throw new x("CozeKeyToPEM: unsupported alg: "+e.alg,s.AlgUnsupported,{alg:e.alg});let l=R($e,[0,4],i),f=R(V,R(be,Wt),R(be,a));if(!n)return Be("PUBLIC KEY",R(V,f,l));let o=R(pe,B(e.d));if(r)return Be("EC PRIVATE KEY",R(V,R(ue,[1]),o,R(Qt,R(be,a)),R(ft,l)));let d=R(V,R(ue,[1]),o,R(ft,l));return Be("PRIVATE KEY",R(V,R(ue,[0]),f,R(pe,d)))}function pi(e,t){let n=B(e);if(P(t)!==I.ECDSA)throw new x("SigToDER: alg must be ECDSA: "+t,s.AlgUnsupported,{alg:t});if(n.length!==z(t))throw new H(`SigToDER: incorrect sig size for ${t}: ${n.length} bytes, expected ${z(t)}.`,s.SigInvalid,{field:"sig"});let r=n.length/2;return R(V,Gt(n.slice(0,r)),Gt(n.slice(r)))}function ut(e,t){if(typeof e=="string"&&(e=B(e)),e=new Uint8Array(e),P(t)!==I.ECDSA)throw new x("DERToSig: alg must be ECDSA: "+t,s.AlgUnsupported,{alg:t});let n,r;try{n=de(e,0),r=He(n)}catch(l){throw new H("DERToSig: invalid DER signature: "+l.message,s.SigInvalid,{field:"sig"})}if(n.tag!==V||n.end!==e.length||r.length!==2)throw new H("DERToSig: invalid DER signature.",s.SigInvalid,{field:"sig"});let i=z(t)/2,a=new Uint8Array(i*2);for(let l=0;l<2;l++){if(r[l].tag!==ue)throw new H("DERToSig: invalid signature integer.",s.SigInvalid,{field:"sig"});let f=r[l].content;if(f.length===0||f[0]&128)throw new H("DERToSig: signature integers must be positive.",s.SigInvalid,{field:"sig"});let o=0;for(;o<f.length-1&&f[o]===0;)o++;if(f=f.slice(o),f.length>i)throw new H("DERToSig: signature integer too large for "+t+".",s.SigInvalid,{field:"sig"});a.set(f,i*(l+1)-f.length)}return k(a)}function dt(e,t){return e.length>0&&e[0]===V&&e.length!==z(t)}function Gt(e){let t=0;for(;t<e.length-1&&e[t]===0;)t++;return e=e.slice(t),e[0]&128?R(ue,[0],e):R(ue,e)}function gr(e){let t=He(X(de(e,0),V,"SPKI")),n=Yt(t[0]),r=X(t[1],$e,"SPKI public key");if(r.content[0]!==0)throw new m("PEMToCozeKey: unsupported SPKI public key padding.",s.KeyInvalid);return{alg:n,x:en(n,r.content.slice(1))}}async function yr(e){let t=He(X(de(e,0),V,"PKCS #8"));if(t.length<3)throw new m("PEMToCozeKey: invalid PKCS #8.",s.KeyInvalid);let n=Yt(t[1]),r=X(t[2],pe,"PKCS #8 private key").content;if(n!==u.Ed25519)return Xt(r,n);let i=X(de(r,0),pe,"Ed25519 private key").content;if(i.length!==L(n))throw new m("PEMToCozeKey: incorrect Ed25519 private key size.",s.KeyInvalid,{field:"d"});let a=await crypto.subtle.importKey("pkcs8",e,{name:u.Ed25519},!0,["sign"]),l=await crypto.subtle.exportKey("jwk",a);return{alg:n,x:l.x,d:k(i)}}async function Xt(e,t){let n=He(X(de(e,0),V,"EC private key"));if(n.length<2||n[0].tag!==ue||n[0].content.length!==1||n[0].content[0]!==1)throw new m("PEMToCozeKey: unsupported EC private key version.",s.KeyInvalid);let r=null;for(let f of n.slice(2)){if(f.tag===Qt){let o=qt(X(de(f.content,0),be,"EC private key curve").content);if(t!==null&&t!==o)throw new x("PEMToCozeKey: EC private key curve mismatch.",s.AlgMismatch,{alg:o});t=o}f.tag===ft&&(r=X(de(f.content,0),$e,"EC public key").content)}if(t===null)throw new m("PEMToCozeKey: EC private key curve not given.",s.KeyInvalid);let i=X(n[1],pe,"EC private key").content;if(i.length>L(t))throw new m("PEMToCozeKey: incorrect private key size.",s.KeyInvalid,{field:"d"});let a=new Uint8Array(L(t));a.set(i,a.length-i.length);let l={alg:t};if(r!==null){if(r[0]!==0)throw new m("PEMToCozeKey: unsupported EC public key padding.",s.KeyInvalid);l.x=en(t,r.slice(1))}else l.x=await _.PublicFromD(t,mr(a));return l.d=k(a),l}function Yt(e){let t=He(X(e,V,"algorithm identifier")),n=X(t[0],be,"algorithm").content;if(st(n,Zt))return u.Ed25519;if(!st(n,Wt))throw new x("PEMToCozeKey: unsupported key algorithm.",s.AlgUnsupported);if(t.length<2)throw new m("PEMToCozeKey: EC named curve not given.",s.KeyInvalid);return qt(X(t[1],be,"named curve").content)}function qt(e){for(let t in ot)if(st(e,ot[t]))return t;throw new x("PEMToCozeKey: unsupported named curve.",s.AlgUnsupported)}function en(e,t){if(e!==u.Ed25519){if(t[0]!==4)throw new m("PEMToCozeKey: only uncompressed EC points are supported.",s.KeyInvalid,{field:"x"});t=t.slice(1)}if(t.length!==Q(e))throw new m("PEMToCozeKey: incorrect public key size for "+e+".",s.KeyInvalid,{field:"x"});return k(t)}function hr(e){let t=/-----BEGIN ([A-Z0-9 ]+)-----([\s\S]*?)-----END \1-----/g,n;for(;(n=t.exec(e))!==null;){if(n[1]==="EC PARAMETERS")continue;if(n[1]==="ENCRYPTED PRIVATE KEY"||n[2].includes("ENCRYPTED"))throw new m("PEMToCozeKey: encrypted PEM is not supported.",s.KeyInvalid,{field:"pem"});let i=n[2].replace(/\s+/g,"");try{var r=Uint8Array.from(atob(i),a=>a.charCodeAt(0))}catch{throw new m("PEMToCozeKey: invalid PEM base64.",s.KeyInvalid,{field:"pem"})}return{label:n[1],der:r}}throw new m("PEMToCozeKey: no PEM key found.",s.KeyInvalid,{field:"pem"})}function Be(e,t){let r=btoa(String.fromCharCode(...t)).match(/.{1,64}/g);return`-----BEGIN ${e}-----
+${r.join(`
+`)}
+-----END ${e}-----
+`}function de(e,t){if(t+2>e.length)throw new m("DER: unexpected end of input.",s.KeyInvalid);let n=e[t],r=e[t+1],i=t+2;if(r&128){let l=r&127;if(l===0||l>4)throw new m("DER: unsupported length.",s.KeyInvalid);r=0;for(let f=0;f<l;f++)r=r*256+e[i+f];i+=l}let a=i+r;if(a>e.length)throw new m("DER: length exceeds input.",s.KeyInvalid);return{tag:n,content:e.slice(i,a),end:a}}function He(e){let t=[];for(let n=0;n<e.content.length;){let r=de(e.content,n);t.push(r),n=r.end}return t}function X(e,t,n){if(e===void 0||e.tag!==t)throw new m("DER: invalid "+n+".",s.KeyInvalid);return e}function R(e,...t){let n=[];for(let a of t)n.push(...a);let r=n.length,i=[];if(r<128)i.push(r);else{let a=[];for(;r>0;r>>=8)a.unshift(r&255);i.push(128|a.length,...a)}return new Uint8Array([e,...i,...n])}function st(e,t){if(e.length!==t.length)return!1;for(let n=0;n<e.length;n++)if(e[n]!==t[n])return!1;return!0}function mr(e){let t=0n;for(let n of e)t=(t<<8n)+BigInt(n);return t}var Ei=["alg","iat","tmb","typ"];async function nn(e,t,n,r){if(console.log(),e=N(e),t=N(t),Z(t))throw new m("SignCoze: Cannot sign with revoked key.",s.KeyRevoked);return!c(r)&&r.setStandard===!0?e.pay=await rn(e.pay,t,r):(e.pay.alg=t.alg,e.pay.tmb=await D(t),e.pay.iat=an(r)),!c(r)&&r.normalizeUnicode===!0&&(e.pay=q(e.pay)),c(n)||(e.pay=await me(e.pay,n)),e.sig=await ie(JSON.stringify(e.pay),t,r),e}async function ie(e,t,n){let r=!0;try{JSON.parse(e)}catch{r=!1}if(r&&(un(e),!c(n)&&n.normalizeUnicode===!0&&(e=JSON.stringify(q(JSON.parse(e))))),ct(t.alg,n),!c(n)&&n.deterministic===!0&&P(t.alg)==I.ECDSA){if(c(t.d))throw new m("SignPay: deterministic signing requires private component d.",s.KeyInvalid,{field:"d"});let i=await _.SignBuffer(await _.FromCozeKey(t),await G(e),!0);return it(t.alg,k(i))}return K.SignBufferB64(await K.FromCozeKey(t),await G(e))}async function jt(e,t,n,r){if(e=N(e),t=N(t),Z(t))throw new m("SignCozeRaw: Cannot sign with revoked key.",s.KeyRevoked);if(!c(r)&&r.setStandard===!0)e.pay=await rn(e.pay,t,r);else{if(!c(e.pay.alg)&&e.pay.alg!==t.alg)throw new x("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.pay.alg});if(!c(e.pay.tmb)&&e.pay.tmb!==t.tmb)throw new m("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"tmb"})}return!c(r)&&r.normalizeUnicode===!0&&(e.pay=q(e.pay)),c(n)||(e.pay=await me(e.pay,n)),e.sig=await ie(JSON.stringify(e.pay),t,r),e}async function rn(e,t,n){let r=await D(t);if(!c(e.alg)&&e.alg!==t.alg)throw new x("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.alg});if(!c(e.tmb)&&e.tmb!==r)throw new m("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"tmb"});let i=e.iat;if(i===void 0)i=an(n);else if(n.iat!==void 0&&n.iat!==i)throw new v(`SignCozeRaw: coze.pay.iat (${i}) mismatch with opts.iat (${n.iat}).`,s.IatInvalid,{field:"iat"});let a={alg:t.alg,iat:i,tmb:r};for(let[l,f]of Object.entries(e))l in a||(a[l]=f);return a}function an(e){if(c(e)||e.iat===void 0)return Math.round(Date.now()/1e3);if(!Number.isSafeInteger(e.iat)||e.iat<0)throw new v("Sign: opts.iat must be a non-negative integer.",s.IatInvalid,{field:"iat"});return e.iat}async function vi(e,t,n,r){if(e=N(e),n=N(n),Z(n))throw new m("SignCryptoKey: Cannot sign with revoked key.",s.KeyRevoked);if(t.type!=="private")throw new m("SignCryptoKey: CryptoKey must be private.",s.KeyInvalid);if(await K.algFromCryptoKey(t)!==n.alg)throw new x("SignCryptoKey: CryptoKey alg mismatch with cozeKey.alg.",s.AlgMismatch,{alg:n.alg});e.alg=n.alg,e.tmb=await D(n),e.iat=Math.round(Date.now()/1e3),c(r)||(e=await me(e,r));let i={pay:e,sig:await K.SignString(t,JSON.stringify(e))};if(!await se(JSON.stringify(e),n,i.sig))throw new m("SignCryptoKey: CryptoKey is not the private key of cozeKey.",s.KeyMismatch);return i}async function oe(e,t,n,r){if(typeof n=="string"){let i;return typeof e=="string"&&(i=e,e=W(e)),tn({pay:e,sig:n},t,r,i)}return tn(e,t,n)}async function tn(e,t,n,r){e=N(e),t=N(t);let i=t!=null;if(i&&(t=await Ae(t,e.pay.tmb)),!c(e.key))t=await xr(e,i?t:void 0);else if(!i)throw new m("VerifyCoze: no key given and coze has no embedded key.",s.KeyInvalid,{field:"key"});if(Z(t)&&(c(n)||n.allowRevoked!==!0))throw new m("VerifyCoze: Coze key is revoked.",s.KeyRevoked);if(!c(e.pay.alg)&&e.pay.alg!==t.alg)throw new x("VerifyCoze: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.pay.alg});if(!c(e.pay.tmb)&&e.pay.tmb!==await D(t))throw new m("VerifyCoze: Coze key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"tmb"});ct(t.alg,n),B(e.sig,"sig"),B(t.x,"x");let a=e.sig,l=e.pay;if(!c(n)&&(n.normalizeUnicode===!0&&(l=q(l)),on(l,n),n.acceptDER===!0&&P(t.alg)==I.ECDSA&&dt(B(a),t.alg)&&(a=ut(a,t.alg)),await ln(t.alg,a,n)))return!1;r===void 0&&(r=JSON.stringify(l));let f=await se(r,t,a);return f&&!c(n)&&fn(l,n),f}async function ln(e,t,n){if(c(n)||n.requireLowS!==!0||P(e)!==I.ECDSA)return!1;let r=ve(t);return r.byteLength===z(e)&&!await Pt(e,r)}function ct(e,t){if(c(t)||c(t.hash))return;let n=M(e);if(t.hash!==n)throw new x(`Coze: hash not valid for alg: ${t.hash} is not ${e}'s hash ${n}.`,s.HashInvalid,{alg:e})}async function xr(e,t){let n=e.key,r=await D(n);if(!c(e.pay.tmb)&&e.pay.tmb!==r)throw new m("VerifyCoze: coze.key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"key"});if(!c(e.pay.alg)&&e.pay.alg!==n.alg)throw new x("VerifyCoze: coze.key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:n.alg});if(t!==void 0){if(await D(t)!==r)throw new m("VerifyCoze: Coze key tmb mismatch with coze.key.",s.TmbMismatch,{field:"key"});return t}return{...n,tmb:r}}function fn(e,t){if(t.maxAge===void 0&&t.notBefore===void 0&&t.notAfter===void 0)return;let n=e.iat,r={field:"iat",verified:!0};if(!Number.isSafeInteger(n)||n<0)throw new H("VerifyCoze: pay.iat must be a non-negative integer when time options are set.",s.IatInvalid,r);let i=t.clockSkew===void 0?60:t.clockSkew,a=Date.now()/1e3;if(t.maxAge!==void 0){if(n+t.maxAge+i<a)throw new H(`VerifyCoze: coze expired: iat ${n} is older than maxAge ${t.maxAge}.`,s.Expired,r);if(n-i>a)throw new H(`VerifyCoze: coze not yet valid: iat ${n} is in the future.`,s.NotYetValid,r)}if(t.notBefore!==void 0&&n+i<t.notBefore)throw new H(`VerifyCoze: coze not yet valid: iat ${n} is before notBefore ${t.notBefore}.`,s.NotYetValid,r);if(t.notAfter!==void 0&&n-i>t.notAfter)throw new H(`VerifyCoze: coze expired: iat ${n} is after notAfter ${t.notAfter}.`,s.Expired,r)}function on(e,t){let n=Object.keys(e),r=[];if(Array.isArray(t.canon)){r=t.canon.flatMap(l=>typeof l=="string"?[l]:Object.keys(l));let a=n.filter(l=>!r.includes(l));if(a.length>0)throw new Y("VerifyCoze: pay has extra field(s) not in canon: "+a.join(", "),s.CanonExtra,{fields:a})}c(t.canonContains)||(r=r.concat(t.canonContains));let i=r.filter(a=>!n.includes(a));if(i.length>0)throw i=[...new Set(i)],new Y("VerifyCoze: pay missing field(s) required by canon: "+i.join(", "),s.CanonMissing,{fields:i})}async function Fi(e,t,n){let r={verified:!1,meta:null,checks:[]},i={},a=function(w,b,A){let C={name:w,status:b};c(A)||(C.message=A),r.checks.push(C),i[w]=b},l=(...w)=>w.every(b=>i[b]==="pass"),f=function(w,...b){a(w,"skip","Requires passing "+b.filter(A=>i[A]!=="pass").join(", ")+".")};c(n)&&(n={});try{e=N(e),e===null||typeof e!="object"||e.pay===null||typeof e.pay!="object"||Array.isArray(e.pay)?a("pay_parsed","fail","coze.pay must be an object."):a("pay_parsed","pass")}catch(w){a("pay_parsed","fail",w.message)}let o,d=!1;if(!l("pay_parsed"))f("key_found","pay_parsed");else try{t=N(t),t!=null&&t!==""?o=await Ae(t,e.pay.tmb):c(e.key)||(o=e.key,d=!0),c(o)?a("key_found","fail","No key given and coze has no embedded key."):a("key_found","pass",d?"Embedded key.":"")}catch(w){a("key_found","fail",w.message)}if(!l("pay_parsed"))f("meta","pay_parsed");else try{r.meta=await re(e,c(e.pay.alg)&&!c(o)?o.alg:void 0),a("meta","pass")}catch(w){a("meta","fail",w.message)}if(l("key_found")?c(e.pay.alg)?a("alg_matches","skip","pay has no alg."):e.pay.alg!==o.alg?a("alg_matches","fail",`pay.alg "${e.pay.alg}" is not the key's alg "${o.alg}".`):a("alg_matches","pass"):f("alg_matches","pay_parsed","key_found"),!l("key_found"))f("tmb_matches","pay_parsed","key_found");else try{let w=await D(o),b=[];!c(e.pay.tmb)&&e.pay.tmb!==w&&b.push(`pay.tmb "${e.pay.tmb}" is not the key's thumbprint "${w}".`),!d&&!c(e.key)&&await D(e.key)!==w&&b.push("Given key is not the embedded key."),b.length>0?a("tmb_matches","fail",b.join("  ")):a("tmb_matches","pass",c(e.pay.tmb)?"pay has no tmb.":"")}catch(w){a("tmb_matches","fail",w.message)}if(l("key_found")?Z(o)?n.allowRevoked===!0?a("not_revoked","pass","Key is revoked, allowed by opts.allowRevoked."):a("not_revoked","fail","Key is revoked."):a("not_revoked","pass"):f("not_revoked","pay_parsed","key_found"),c(n.hash))a("hash","skip","opts.hash not given.");else if(!l("key_found"))f("hash","pay_parsed","key_found");else try{ct(o.alg,n),a("hash","pass")}catch(w){a("hash","fail",w.message)}let g;if(!l("pay_parsed"))f("sig_b64ut","pay_parsed");else if(c(e.sig))a("sig_b64ut","fail","coze has no sig.");else try{B(e.sig,"sig"),g=e.sig,a("sig_b64ut","pass")}catch(w){a("sig_b64ut","fail",w.message)}let y=!c(o)&&!c(o.alg)?o.alg:l("pay_parsed")?e.pay.alg:void 0;if(!l("sig_b64ut"))f("sig_size","sig_b64ut");else try{n.acceptDER===!0&&P(y)==I.ECDSA&&dt(B(g),y)&&(g=ut(g,y));let w=B(g).length;w!==z(y)?a("sig_size","fail",`sig is ${w} bytes, ${y} requires ${z(y)}.`):a("sig_size","pass")}catch(w){a("sig_size","fail",w.message)}let h=l("pay_parsed")?e.pay:void 0;if(!l("key_found","sig_size")||i.alg_matches==="fail")f("signature",...i.alg_matches==="fail"?["key_found","alg_matches","sig_size"]:["key_found","sig_size"]);else try{n.normalizeUnicode===!0&&(h=q(h)),await ln(o.alg,g,n)?a("signature","fail","High-S signature refused by opts.requireLowS."):await se(JSON.stringify(h),o,g)?a("signature","pass"):a("signature","fail","Signature did not verify.")}catch(w){a("signature","fail",w.message)}if(c(n.canon)&&c(n.canonContains))a("canon","skip","opts.canon and opts.canonContains not given.");else if(!l("pay_parsed"))f("canon","pay_parsed");else try{on(h,n),a("canon","pass")}catch(w){a("canon","fail",w.message)}if(n.maxAge===void 0&&n.notBefore===void 0&&n.notAfter===void 0)a("iat_window","skip","Time options not given.");else if(!l("pay_parsed"))f("iat_window","pay_parsed");else try{fn(h,n),a("iat_window","pass")}catch(w){a("iat_window","fail",w.message)}return r.verified=l("signature")&&r.checks.every(w=>w.status!=="fail"),r}async function se(e,t,n){return K.VerifyMsg(t.alg,await K.FromCozeKey(t,!0),e,n)}async function ki(e,t,n){if(Z(t))throw new m("SignDig: Cannot sign with revoked key.",s.KeyRevoked);let r=sn("SignDig",e,t,n),i=await _.SignDigest(await _.FromCozeKey(t),r);return it(e,k(i))}async function Ii(e,t,n,r){let i=sn("VerifyDig",e,t,n),a=ve(r);return a.byteLength!==z(e)?!1:_.VerifyDigest(await _.FromCozeKey(t,!0),i,a)}function sn(e,t,n,r){if(t!==n.alg)throw new x(`${e}: alg (${t}) mismatch with cozeKey.alg (${n.alg}).`,s.AlgMismatch,{alg:t});if(P(t)!==I.ECDSA)throw new x(`${e}: only ECDSA algs are supported.`,s.AlgUnsupported,{alg:t});if(r instanceof Uint8Array||(r.replace(/^0x/i,"").length===te(t)*2?r=We(r):r=B(r)),r.length!==te(t))throw new v(`${e}: incorrect digest size for ${t}: ${r.length} bytes, expected ${te(t)}.`,s.DigSize,{alg:t});return r}async function re(e,t){if(e=N(e),c(e.pay))throw new H("Meta: coze.pay must exist.",s.PayMissing,{field:"pay"});let n={},r=t,i="";if(!c(t)&&typeof t=="object"&&(r=t.alg,i=t.tmb),c(e.pay.alg))c(r)||(n.alg=r);else{if(!c(r)&&r!==e.pay.alg)throw new x(`Meta: alg mismatch: coze.pay.alg (${e.pay.alg}) and parameter alg (${r}) do not match.`,s.AlgMismatch,{alg:r});n.alg=e.pay.alg}if(c(e.pay.iat)||(n.iat=e.pay.iat),c(e.pay.tmb)?c(i)||(n.tmb=i):n.tmb=e.pay.tmb,c(e.pay.typ)||(n.typ=e.pay.typ),n.can=await It(e.pay),c(n.alg)||(n.cad=await ke(e.pay,M(n.alg))),c(e.sig)||(n.sig=e.sig),!c(n.alg)&&!c(e.sig)&&(n.czd=await ke({cad:n.cad,sig:n.sig},M(n.alg))),!c(n.alg)&&Array.isArray(e.sigs)){n.sigs=[];for(let a of e.sigs)n.sigs.push({tmb:a.tmb,sig:a.sig,czd:await ke({cad:n.cad,sig:a.sig},M(n.alg))})}return n}function Ar(e){e=N(e);let t={...e};return c(e.key)||(t.key=Lt(e.key)),!c(e.coze)&&typeof e.coze=="object"&&(t.coze=Ar(e.coze)),t}function Ti(e,t){if(typeof e=="string"){let n=e;if(e=W(n),JSON.stringify(e)!==n)throw new v("Attach: pay is not the compact serialization of pay, so sig is not of the coze's pay.  Verify with Verify(pay, cozeKey, sig) instead.",s.JSONInvalid,{field:"pay"})}return{pay:e,sig:t}}function Di(e){if(e=N(e),c(e.pay))throw new H("Detach: coze.pay must exist.",s.PayMissing,{field:"pay"});return{payCompact:JSON.stringify(e.pay),sig:e.sig}}async function pr(e,t){if(e=N(e),t=N(t),c(e)||c(e.pay)||c(t)||c(t.pay))throw new H("Equal: coze.pay must exist.",s.PayMissing,{field:"pay"});if(c(e.sig)!==c(t.sig))return!1;if(c(e.pay.alg)||e.pay.alg!==t.pay.alg)return e.sig===t.sig&&await Ne(e.pay)===await Ne(t.pay);let n=await re(e),r=await re(t);return c(e.sig)?n.cad===r.cad:n.czd===r.czd}async function Bi(e,t){return e=N(e),t=N(t),Object.keys(e).sort().join()!==Object.keys(t).sort().join()||!c(e.key)&&await D(e.key)!==await D(t.key)?!1:pr(e,t)}function W(e){let t=JSON.parse(e);return un(e),t}function un(e){let t=[];for(let n=0;n<e.length;n++){let r=t[t.length-1];switch(e[n]){case"{":t.push({names:new Set,expectName:!0});break;case"[":t.push(null);break;case"}":case"]":t.pop();break;case",":r&&(r.expectName=!0);break;case'"':{let i=n;for(n++;e[n]!=='"';n++)e[n]==="\\"&&n++;if(r&&r.expectName){let a=JSON.parse(e.slice(i,n+1));if(r.names.has(a))throw new v(`Coze: duplicate JSON field "${a}"`,s.DuplicateField,{field:a});r.names.add(a),r.expectName=!1}break}}}}var ce={Coze:"coze",Key:"key",Pay:"pay",Array:"array",Unknown:"unknown"};function Hi(e){if(typeof e=="string")try{e=W(e)}catch(n){throw n instanceof SyntaxError?br(e,n):n}if(Array.isArray(e))return ce.Array;if(typeof e!="object"||e===null)return ce.Unknown;let t=n=>typeof n=="object"&&n!==null&&!Array.isArray(n);return t(e.coze)&&t(e.coze.pay)&&typeof e.coze.sig=="string"?ce.Coze:t(e.pay)?typeof e.sig=="string"?ce.Coze:ce.Pay:e.sig!==void 0?ce.Unknown:typeof e.alg=="string"&&(typeof e.x=="string"||typeof e.d=="string")?ce.Key:ce.Pay}function br(e,t){let n=(a,l)=>new v(`Detect: ${a} at position ${l}.`,s.JSONInvalid,{position:l});if(e.charCodeAt(0)===65279)return n("byte order mark (BOM)",0);let r=!1;for(let a=0;a<e.length;a++){let l=e[a];if(r){l==="\\"?a++:l==='"'&&(r=!1);continue}if(l==='"')r=!0;else if(l===","){let f=e.slice(a+1).search(/[^ \t\n\r]/);if(f!==-1&&(e[a+1+f]==="}"||e[a+1+f]==="]"))return n("trailing comma",a)}else if(/[\s\u200B-\u200D\u2060]/.test(l)&&!/[ \t\n\r]/.test(l)){let f=l.charCodeAt(0).toString(16).toUpperCase().padStart(4,"0");return n(`non JSON whitespace (U+${f})`,a)}}let i=/position (\d+)/.exec(t.message);return i!==null?n("invalid JSON",Number(i[1])):new v("Detect: invalid JSON: "+t.message,s.JSONInvalid)}function N(e){return typeof e=="string"?W(e):e}async function zi(e,t,n){if(c(n)&&(n={}),Z(t))throw new m("SignCozeArray: Cannot sign with revoked key.",s.KeyRevoked);if(n.iat!==void 0&&(!Number.isSafeInteger(n.iat)||n.iat<0))throw new v("SignCozeArray: opts.iat must be a non-negative integer.",s.IatInvalid,{field:"iat"});if(c(t.d))throw new m("SignCozeArray: cozeKey must be private.",s.KeyInvalid,{field:"d"});let r=M(t.alg);if(!c(n.hash)&&n.hash!==r)throw new x(`SignCozeArray: hash not valid for alg: ${n.hash} is not ${t.alg}'s hash ${r}.`,s.HashInvalid,{alg:t.alg});let i=await D(t),a=null;return n.deterministic!==!0&&(a=await K.FromCozeKey(t)),Promise.all(e.map(l=>Sr(l,t,a,i,n)))}async function Sr(e,t,n,r,i){let a={coze:null,error:null};try{let l=typeof e=="string"?W(e):{...e};if(!c(l.alg)&&l.alg!==t.alg)throw new x("SignCozeArray: Coze key alg mismatch with pay.alg.",s.AlgMismatch,{alg:l.alg});if(!c(l.tmb)&&l.tmb!==r)throw new m("SignCozeArray: Coze key tmb mismatch with pay.tmb.",s.TmbMismatch,{field:"tmb"});let f=l.iat;if(f===void 0)f=i.iat!==void 0?i.iat:Math.round(Date.now()/1e3);else if(i.iat!==void 0&&i.iat!==f)throw new v(`SignCozeArray: pay.iat (${f}) mismatch with opts.iat (${i.iat}).`,s.IatInvalid,{field:"iat"});let o={alg:t.alg,iat:f,tmb:r};for(let[g,y]of Object.entries(l))g in o||(o[g]=y);if(l=o,i.normalizeUnicode===!0&&(l=q(l)),!c(i.canon)){let y=(Array.isArray(i.canon)?i.canon.flatMap(h=>typeof h=="string"?[h]:Object.keys(h)):Object.keys(i.canon)).filter(h=>l[h]===void 0);if(y.length>0)throw new Y("SignCozeArray: pay missing field(s) required by canon: "+y.join(", "),s.CanonMissing,{fields:y});l=await me(l,i.canon)}let d;n===null?d=await ie(JSON.stringify(l),t,i):d=await K.SignString(n,JSON.stringify(l)),a.coze={pay:l,sig:d}}catch(l){a.error=l}return a}async function Ni(e,t){return Array.isArray(e)?Promise.all(e.map(n=>Cr(n,t))):oe(e,t)}async function Cr(e,t){let n={czd:"",tmb:"",verified:!1,error:null};try{if(typeof e=="string"&&(e=W(e)),c(e))throw new H("VerifyCozeArray: coze is empty.",s.PayMissing,{field:"pay"});if(c(e.coze)||(e=e.coze),c(e.pay))throw new H("VerifyCozeArray: coze.pay must exist.",s.PayMissing,{field:"pay"});if(c(e.sig))throw new H("VerifyCozeArray: coze.sig must exist.",s.SigInvalid,{field:"sig"});if(c(e.pay.tmb)||(n.tmb=e.pay.tmb),t==null){if(c(e.key))throw new m("VerifyCozeArray: no key given and coze has no embedded key.",s.KeyInvalid,{field:"key"});return n.tmb=await D(e.key),n.czd=(await re(e,e.key.alg)).czd,n.verified=await oe(e),n}let r=await Ae(t,e.pay.tmb);n.tmb=await D(r),n.czd=(await re(e,r.alg)).czd,n.verified=await oe(e,r)}catch(r){n.error=r}return n}async function Oi(e,t){let n=await Promise.all(e.map(i=>Er(i,t))),r={count:n.length,failed:0,tmb:{},typ:{},iatMin:null,iatMax:null};for(let i of n){if(i.error!==null){r.failed++;continue}let a=i.meta;c(a.tmb)||(r.tmb[a.tmb]=(r.tmb[a.tmb]||0)+1),c(a.typ)||(r.typ[a.typ]=(r.typ[a.typ]||0)+1),typeof a.iat=="number"&&((r.iatMin===null||a.iat<r.iatMin)&&(r.iatMin=a.iat),(r.iatMax===null||a.iat>r.iatMax)&&(r.iatMax=a.iat))}return{results:n,summary:r}}async function Er(e,t){let n={meta:null,error:null};try{if(typeof e=="string"&&(e=W(e)),c(e))throw new H("MetaArray: coze is empty.",s.PayMissing,{field:"pay"});c(e.coze)||(e=e.coze),n.meta=await re(e,t)}catch(r){n.error=r}return n}var gt="";async function ji(e,t,n,r){let i={...e};return delete i.prv,c(n)||(i.prv=(await re(n)).czd),nn({pay:i},t,null,r)}async function Gi(e,t,n){let r={verified:!1,czds:[],index:-1,reason:"",error:null},i=gt;if(!c(n)&&n.prv!==void 0&&(i=n.prv),e.length===0)return r.index=0,r.error=new H("VerifyChain: chain is empty.",s.PayMissing,{field:"pay"}),r.reason=r.error.message,r;for(let a=0;a<e.length;a++)try{let l=e[a];if(typeof l=="string"&&(l=W(l)),c(l.coze)||(l=l.coze),c(l.pay))throw new H(`VerifyChain: coze ${a} has no pay.`,s.PayMissing,{field:"pay"});let f=await Ae(t,l.pay.tmb);if(!await oe(l,f))throw new H(`VerifyChain: coze ${a} signature is invalid.`,s.SigInvalid,{field:"sig"});let o=l.pay.prv===void 0?gt:l.pay.prv;if(o!==i){let d=i===gt?"the chain genesis":`"${i}"`;throw new H(`VerifyChain: coze ${a} prv "${o}" is not ${d}.`,s.PrvMismatch,{field:"prv"})}i=(await re(l,f.alg)).czd,r.czds.push(i)}catch(l){return r.index=a,r.reason=l.message,r.error=l,r}return r.verified=!0,r}async function Yi(e,t){if(Z(t))throw new m("SignAdd: Cannot sign with revoked key.",s.KeyRevoked);if(!c(e.pay.alg)&&e.pay.alg!==t.alg)throw new x("SignAdd: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.pay.alg});let n=await D(t),r=await ie(JSON.stringify(e.pay),t);Array.isArray(e.sigs)||(e.sigs=[]);let i=e.sigs.find(a=>a.tmb===n);return i!==void 0?i.sig=r:e.sigs.push({tmb:n,sig:r}),e}async function qi(e,t,n){let r=t.length;if(!c(n)&&n.threshold!==void 0&&(r=n.threshold),!Number.isInteger(r)||r<1||r>t.length)throw new v(`VerifyMulti: threshold must be an integer from 1 to ${t.length}.`,s.ThresholdInvalid,{field:"threshold"});let i=new Map;for(let g of t)i.set(await D(g),g);let a=Array.isArray(e.sigs)?[...e.sigs]:[];!c(e.sig)&&!c(e.pay.tmb)&&a.push({tmb:e.pay.tmb,sig:e.sig});let l=JSON.stringify(e.pay),f=new Set,o=new Set,d=new Set;for(let g of a){let y=i.get(g.tmb);if(y===void 0){d.add(g.tmb);continue}let h=!1;if(!Z(y)&&(c(e.pay.alg)||e.pay.alg===y.alg))try{h=await se(l,y,g.sig)}catch{h=!1}h?f.add(g.tmb):o.add(g.tmb)}for(let g of o)f.delete(g);return{verified:f.size>=r,valid:[...f],invalid:[...o],unknown:[...d]}}function na(e,t){if(typeof document>"u")throw new v("DownloadJSON: requires a browser.",s.BrowserRequired);let n=URL.createObjectURL(new Blob([JSON.stringify(e,null,"	")],{type:"application/json"})),r=document.createElement("a");r.href=n,r.download=t,document.body.appendChild(r),r.click(),r.remove(),setTimeout(()=>URL.revokeObjectURL(n),1e3)}var vr="coze",ht="keys",yt=null,Ke=class extends v{constructor(t){super(t,s.KeystoreUnavailable),this.name="KeystoreUnavailableError"}};async function fa(e,t){if(c(e))throw new m("StoreKey: name must be set.",s.KeyInvalid,{field:"name"});let n={name:e};if(!c(t.privateKey)||!c(t.publicKey)){if(c(t.publicKey))throw new m("StoreKey: CryptoKeyPair must have publicKey.",s.KeyInvalid,{field:"publicKey"});n.cozeKey=await K.ToCozeKey(t.publicKey),n.cryptoKeyPair=t}else{if(c(t.alg)||c(t.x))throw new m("StoreKey: Coze key must have alg and x.",s.KeyInvalid,{field:"x"});n.cozeKey=t}let r=await Le("readwrite");return await Re(r.put(n)),n}async function oa(e){let t=await Le("readonly"),n=await Re(t.get(e));return n===void 0?null:n}async function sa(){let e=await Le("readonly");return(await Re(e.getAll())).map(n=>({name:n.name,alg:n.cozeKey.alg,tmb:n.cozeKey.tmb,cryptoKey:!c(n.cryptoKeyPair)}))}async function ua(e){let t=await Le("readwrite");await Re(t.delete(e))}async function Fr(){if(typeof indexedDB>"u"||indexedDB===null)throw new Ke("Keystore: IndexedDB is unavailable.");try{var e=indexedDB.open(vr,1)}catch(t){throw new Ke("Keystore: IndexedDB is unavailable: "+t)}e.onupgradeneeded=()=>{e.result.createObjectStore(ht,{keyPath:"name"})};try{return await Re(e)}catch(t){throw new Ke("Keystore: IndexedDB is unavailable: "+t)}}async function Le(e){return yt===null&&(yt=await Fr()),yt.transaction(ht,e).objectStore(ht)}function Re(e){return new Promise((t,n)=>{e.onsuccess=()=>t(e.result),e.onerror=()=>n(e.error)})}var _e={L:{ordinal:0,bits:1},M:{ordinal:1,bits:0},Q:{ordinal:2,bits:3},H:{ordinal:3,bits:2}},cn=[[-1,7,10,15,20,26,18,20,24,30,18,20,24,26,30,22,24,28,30,28,28,28,28,30,30,26,28,30,30,30,30,30,30,30,30,30,30,30,30,30,30],[-1,10,16,26,18,24,16,18,22,22,26,30,22,22,24,24,28,28,26,26,26,26,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28],[-1,13,22,18,26,18,24,18,22,20,24,28,26,24,20,30,24,28,28,26,30,28,30,30,30,30,28,30,30,30,30,30,30,30,30,30,30,30,30,30,30],[-1,17,28,22,16,22,28,26,26,24,28,24,28,22,24,24,30,28,28,26,28,30,24,30,30,30,30,30,30,30,30,30,30,30,30,30,30,30,30,30,30]],gn=[[-1,1,1,1,1,1,2,2,2,2,4,4,4,4,4,6,6,6,6,7,8,8,9,9,10,12,12,12,13,14,15,16,17,18,19,19,20,21,22,24,25],[-1,1,1,1,2,2,4,4,4,5,5,5,8,9,9,10,10,11,13,14,16,17,17,18,20,21,23,25,26,28,29,31,33,35,37,38,40,43,45,47,49],[-1,1,1,2,2,4,4,6,6,8,8,8,10,12,16,12,17,16,18,21,20,23,23,25,27,29,34,34,35,38,40,43,45,48,51,53,56,59,62,65,68],[-1,1,1,2,4,4,4,5,6,8,8,11,11,16,16,18,16,19,21,25,25,25,34,30,32,35,37,40,42,45,48,51,54,57,60,63,66,70,74,77,81]];function ya(e,t,n){n==null&&(n={});let r=kr(e),i=Ir(r,n),a=n.scale>0?n.scale:4,l=n.margin>=0?n.margin:4,f=i.length+l*2;if(typeof t.getContext=="function"){t.width=f*a,t.height=f*a;let d=t.getContext("2d");d.fillStyle="#FFFFFF",d.fillRect(0,0,t.width,t.height),d.fillStyle="#000000";for(let g=0;g<i.length;g++)for(let y=0;y<i.length;y++)i[g][y]&&d.fillRect((y+l)*a,(g+l)*a,a,a);return r}let o=[];for(let d=0;d<i.length;d++)for(let g=0;g<i.length;g++)i[d][g]&&o.push(`M${g+l},${d+l}h1v1h-1z`);return t.setAttribute("viewBox",`0 0 ${f} ${f}`),t.setAttribute("shape-rendering","crispEdges"),t.innerHTML=`<rect width="100%" height="100%" fill="#FFFFFF"/><path d="${o.join("")}" fill="#000000"/>`,r}function kr(e){if(typeof e=="string"&&(e=W(e)),typeof e.pay!="object"||e.pay===null)return JSON.stringify(e);let t={pay:e.pay};return e.key!==void 0&&(t.key=e.key),e.sig!==void 0&&(t.sig=e.sig),JSON.stringify(t)}function Ir(e,t){let n="M";t!=null&&t.errorCorrection!==void 0&&(n=t.errorCorrection);let r=_e[n];if(r===void 0)throw new v(`QR: invalid error correction "${n}".  Must be L, M, Q, or H.`,s.QRInvalid);let i=new TextEncoder().encode(e),a=1;for(;a<=40&&!(i.length<=dn(a,r));a++);if(a>40){let h=dn(40,r);throw new v(`QR: payload is ${i.length} bytes, exceeding the ${h} byte maximum for error correction ${n}.`,s.QRCapacity,{size:i.length,max:h})}let l=[],f=(h,w)=>{for(let b=w-1;b>=0;b--)l.push(h>>>b&1)};f(4,4),f(i.length,At(a));for(let h of i)f(h,8);let o=wn(a,r)*8;f(0,Math.min(4,o-l.length)),f(0,(8-l.length%8)%8);for(let h=236;l.length<o;h^=253)f(h,8);let d=new Uint8Array(l.length/8);for(let h=0;h<l.length;h++)d[h>>>3]|=l[h]<<7-(h&7);let g=yn(a);Kr(g,Hr(d,a,r));let y=null;for(let h=0;h<8;h++){Je(g,h),mt(g,r,h);let w=Rr(g.modules);(y===null||w<y.penalty)&&(y={mask:h,penalty:w}),Je(g,h)}return Je(g,y.mask),mt(g,r,y.mask),g.modules}async function ha(e){let t;if(typeof BarcodeDetector<"u"){let n=await new BarcodeDetector({formats:["qr_code"]}).detect(e);if(n.length===0)throw new v("QRToCoze: no QR code found.",s.QRInvalid);t=n[0].rawValue}else t=_r(Dr(e));return W(Tr(t))}function Tr(e){if(e=e.trim(),e.startsWith("{")||e.startsWith("["))return e;try{e=decodeURIComponent(e)}catch{}let t=e.search(/[{[]/),n=Math.max(e.lastIndexOf("}"),e.lastIndexOf("]"));if(t===-1||n<t)throw new v("QRToCoze: QR code does not contain JSON.",s.QRInvalid);return e.slice(t,n+1)}function Dr(e){if(e.data!==void 0&&e.width>0)return e;if(typeof e.getContext=="function")return e.getContext("2d").getImageData(0,0,e.width,e.height);let t=e.videoWidth||e.naturalWidth||e.width,n=e.videoHeight||e.naturalHeight||e.height,r=document.createElement("canvas");r.width=t,r.height=n;let i=r.getContext("2d");return i.drawImage(e,0,0),i.getImageData(0,0,t,n)}function yn(e){let t=e*4+17,n={version:e,size:t,modules:Array.from({length:t},()=>new Array(t).fill(!1)),isFunction:Array.from({length:t},()=>new Array(t).fill(!1))},r=(a,l,f)=>{n.modules[l][a]=f,n.isFunction[l][a]=!0};for(let a=0;a<t;a++)r(6,a,a%2===0),r(a,6,a%2===0);for(let[a,l]of[[3,3],[t-4,3],[3,t-4]])for(let f=-4;f<=4;f++)for(let o=-4;o<=4;o++){let d=a+o,g=l+f;if(d>=0&&d<t&&g>=0&&g<t){let y=Math.max(Math.abs(o),Math.abs(f));r(d,g,y!==2&&y!==4)}}let i=Br(e);for(let a=0;a<i.length;a++)for(let l=0;l<i.length;l++)if(!(a===0&&l===0||a===0&&l===i.length-1||a===i.length-1&&l===0))for(let f=-2;f<=2;f++)for(let o=-2;o<=2;o++)r(i[a]+o,i[l]+f,Math.max(Math.abs(o),Math.abs(f))!==1);if(n.set=r,mt(n,_e.L,0),e>=7){let a=e;for(let f=0;f<12;f++)a=a<<1^(a>>>11)*7973;let l=e<<12|a;for(let f=0;f<18;f++){let o=(l>>>f&1)!==0,d=t-11+f%3,g=Math.floor(f/3);r(d,g,o),r(g,d,o)}}return n}function hn(e,t){let n=e.bits<<3|t,r=n;for(let i=0;i<10;i++)r=r<<1^(r>>>9)*1335;return(n<<10|r)^21522}function mn(e){let t=[],n=[];for(let r=0;r<15;r++)r<6?t.push([8,r]):r<8?t.push([8,r+1]):r===8?t.push([7,8]):t.push([14-r,8]),n.push(r<8?[e-1-r,8]:[8,e-15+r]);return[t,n]}function mt(e,t,n){let r=hn(t,n);for(let i of mn(e.size))for(let a=0;a<15;a++)e.set(i[a][0],i[a][1],(r>>>a&1)!==0);e.set(8,e.size-8,!0)}function Br(e){if(e===1)return[];let t=Math.floor(e/7)+2,n=Math.floor((e*8+t*3+5)/(t*4-4))*2,r=[6];for(let i=e*4+10;r.length<t;i-=n)r.splice(1,0,i);return r}function xt(e){let t=(16*e+128)*e+64;if(e>=2){let n=Math.floor(e/7)+2;t-=(25*n-10)*n-55,e>=7&&(t-=36)}return t}function wn(e,t){return Math.floor(xt(e)/8)-cn[t.ordinal][e]*gn[t.ordinal][e]}function At(e){return e<10?8:16}function dn(e,t){return Math.floor((wn(e,t)*8-4-At(e))/8)}function Hr(e,t,n){let r=xn(t,n),i=pn(r.eccLen),a=[];for(let f=0,o=0;f<r.numBlocks;f++){let d=r.shortDataLen+(f<r.numShort?0:1),g=Array.from(e.slice(o,o+d));o+=d,a.push({data:g,ecc:bn(g,i)})}let l=[];for(let f=0;f<=r.shortDataLen;f++)for(let o of a)f<o.data.length&&l.push(o.data[f]);for(let f=0;f<r.eccLen;f++)for(let o of a)l.push(o.ecc[f]);return Uint8Array.from(l)}function xn(e,t){let n=gn[t.ordinal][e],r=cn[t.ordinal][e],i=Math.floor(xt(e)/8);return{numBlocks:n,eccLen:r,numShort:n-i%n,shortDataLen:Math.floor(i/n)-r}}function An(e){let t=[];for(let n=e.size-1;n>=1;n-=2){n===6&&(n=5);let r=(n+1&2)===0;for(let i=0;i<e.size;i++)for(let a=0;a<2;a++){let l=n-a,f=r?e.size-1-i:i;e.isFunction[f][l]||t.push([l,f])}}return t}function Kr(e,t){let n=An(e);for(let r=0;r<t.length*8;r++)e.modules[n[r][1]][n[r][0]]=(t[r>>>3]>>>7-(r&7)&1)!==0}function Je(e,t){for(let n=0;n<e.size;n++)for(let r=0;r<e.size;r++){let i;switch(t){case 0:i=(r+n)%2===0;break;case 1:i=n%2===0;break;case 2:i=r%3===0;break;case 3:i=(r+n)%3===0;break;case 4:i=(Math.floor(r/3)+Math.floor(n/2))%2===0;break;case 5:i=r*n%2+r*n%3===0;break;case 6:i=(r*n%2+r*n%3)%2===0;break;case 7:i=((r+n)%2+r*n%3)%2===0;break}!e.isFunction[n][r]&&i&&(e.modules[n][r]=!e.modules[n][r])}}function Rr(e){let t=e.length,n=0,r=0,i=[];for(let l=0;l<t;l++)i.push(e[l].map(f=>f?"1":"0").join("")),i.push(e.map(f=>f[l]?"1":"0").join(""));for(let l of i){for(let o of l.match(/0{5,}|1{5,}/g)||[])n+=o.length-2;let f="0000"+l+"0000";for(let o=f.indexOf("1011101");o!==-1;o=f.indexOf("1011101",o+1))(f.startsWith("0000",o-4)||f.startsWith("0000",o+7))&&(n+=40)}for(let l=0;l<t;l++)for(let f=0;f<t;f++)if(e[l][f]&&r++,f<t-1&&l<t-1){let o=e[l][f];o===e[l][f+1]&&o===e[l+1][f]&&o===e[l+1][f+1]&&(n+=3)}let a=t*t;return n+=(Math.ceil(Math.abs(r*20-a*10)/a)-1)*10,n}function wt(e,t){let n=0;for(let r=7;r>=0;r--)n=n<<1^(n>>>7)*285,n^=(t>>>r&1)*e;return n}function pn(e){let t=new Array(e).fill(0);t[e-1]=1;let n=1;for(let r=0;r<e;r++){for(let i=0;i<e;i++)t[i]=wt(t[i],n),i+1<e&&(t[i]^=t[i+1]);n=wt(n,2)}return t}function bn(e,t){let n=new Array(t.length).fill(0);for(let r of e){let i=r^n.shift();n.push(0);for(let a=0;a<t.length;a++)n[a]^=wt(t[a],i)}return n}function _r(e){let t=e.width,n=e.height,r=new Float32Array(t*n),i=255,a=0;for(let S=0;S<t*n;S++){let E=(e.data[S*4]*299+e.data[S*4+1]*587+e.data[S*4+2]*114)/1e3;r[S]=E,i=Math.min(i,E),a=Math.max(a,E)}let l=(i+a)/2,f=(S,E)=>r[Math.floor(E)*t+Math.floor(S)]<l,o=()=>new v("QRToCoze: no QR code found.",s.QRInvalid),d=-1,g=-1;for(let S=0;S<n&&g===-1;S++)for(let E=0;E<t;E++)if(f(E,S)){d=E,g=S;break}if(g===-1)throw o();let y=0;for(;d+y<t&&f(d+y,g);)y++;let h=y/7,w=g+h/2,b=t-1;for(;b>d&&!f(b,w);)b--;let A=Math.round((b-d+1)/h),C=(A-17)/4;if(!Number.isInteger(C)||C<1||C>40||(h=(b-d+1)/A,g+A*h>n))throw o();let p=yn(C);for(let S=0;S<A;S++)for(let E=0;E<A;E++)p.modules[S][E]=f(d+(E+.5)*h,g+(S+.5)*h);return Ur(p)}function Ur(e){let t=A=>new v("QRToCoze: "+A,s.QRInvalid),n=null;for(let A of mn(e.size)){let C=0;for(let p=0;p<15;p++)C|=(e.modules[A[p][1]][A[p][0]]?1:0)<<p;for(let p of Object.keys(_e))for(let S=0;S<8;S++){let E=hn(_e[p],S)^C,J=0;for(;E!==0;E&=E-1)J++;(n===null||J<n.dist)&&(n={ecl:_e[p],mask:S,dist:J})}}if(n.dist>3)throw t("unreadable format.");Je(e,n.mask);let r=An(e),i=new Uint8Array(Math.floor(xt(e.version)/8));for(let A=0;A<i.length*8;A++)e.modules[r[A][1]][r[A][0]]&&(i[A>>>3]|=1<<7-(A&7));let a=xn(e.version,n.ecl),l=[];for(let A=0;A<a.numBlocks;A++)l.push([]);let f=0;for(let A=0;A<=a.shortDataLen;A++)for(let C=0;C<a.numBlocks;C++)(A<a.shortDataLen||C>=a.numShort)&&l[C].push(i[f++]);let o=pn(a.eccLen),d=[],g=f;for(let A=0;A<a.numBlocks;A++){let C=[];for(let p=0;p<a.eccLen;p++)C.push(i[g+p*a.numBlocks+A]);if(bn(l[A],o).some((p,S)=>p!==C[S]))throw t("damaged QR code.");d.push(...l[A])}let y=0,h=A=>{let C=0;for(let p=0;p<A;p++,y++)C=C<<1|d[y>>>3]>>>7-(y&7)&1;return C},w="0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:",b=[];for(;y+4<=d.length*8;){let A=h(4);if(A===0)break;if(A===4){let C=h(At(e.version));for(let p=0;p<C;p++)b.push(h(8))}else if(A===2){let C=h(e.version<10?9:e.version<27?11:13);for(;C>=2;C-=2){let p=h(11);b.push(w.charCodeAt(Math.floor(p/45)),w.charCodeAt(p%45))}C===1&&b.push(w.charCodeAt(h(6)))}else if(A===1){let C=h(e.version<10?10:e.version<27?12:14);for(;C>=3;C-=3)b.push(...new TextEncoder().encode(String(h(10)).padStart(3,"0")));C>0&&b.push(...new TextEncoder().encode(String(h(C===2?7:4)).padStart(C,"0")))}else if(A===7)h(8);else throw t("unsupported QR mode "+A+".")}return new TextDecoder().decode(Uint8Array.from(b))}export{Or as AlgFromCOSE,Nr as AlgFromJOSE,u as Algs,k as ArrayBufferTo64ut,si as AssertPublic,Ti as Attach,ge as B64Error,In as B64Lenient,B as B64ToUint8Array,ve as B64uToArrayBuffer,Dn as B64utToHex,Pn as COSEAlg,It as Canon,me as Canonical,Zn as CanonicalHash,ke as CanonicalHash64,Ne as CanonicalS,gt as ChainGenesis,un as CheckDuplicates,ti as ClearKeyCache,ci as Correct,x as CozeAlgError,Y as CozeCanonError,v as CozeError,m as CozeKeyError,_t as CozeKeyToJWK,Ai as CozeKeyToPEM,ya as CozeToQR,H as CozeVerifyError,K as CryptoKey,he as Curve,pt as CurveHalfOrder,_n as CurveOID,Pe as CurveOrder,$ as Curves,ut as DERToSig,L as DSize,ua as DeleteKey,Di as Detach,Hi as Detect,ui as Diagnose,ne as Digest,Et as DigestFiles,Lr as DigestPayField,Jr as DigestPayFile,na as DownloadJSON,_ as ECDSA,pr as Equal,Bi as EqualStrict,s as ErrCodes,De as FamAlgs,Hn as Family,I as GenAlgs,P as Genus,ze as HMAC,zn as Hash,M as HashAlg,te as HashSize,Nn as HashStream,Tn as HexToB64ut,We as HexToUint8Array,ce as InputTypes,dt as IsDERSig,oi as IsPrivate,Z as IsRevoked,Pt as IsSigLowS,Un as JOSEAlg,Mn as JOSECrv,tr as JWKToCozeKey,Ke as KeystoreUnavailableError,sa as ListKeys,oa as LoadKey,Ae as LookupKey,jr as MatchPayFile,re as Meta,Oi as MetaArray,ai as NewKey,li as NewKeyFromPassword,ur as NewKeyFromSeed,q as NormalizeUnicode,xi as PEMToCozeKey,Ye as Params,W as ParseStrict,Ei as PayCanon,Lt as PublicKey,Ir as QRMatrix,kr as QRText,ha as QRToCoze,gi as Revoke,G as SToArrayBuffer,Ar as ScrubCoze,z as SigSize,pi as SigToDER,it as SigToLowS,nn as Sign,Yi as SignAdd,ji as SignChained,zi as SignCozeArray,jt as SignCozeRaw,vi as SignCryptoKey,ki as SignDig,ie as SignPay,fa as StoreKey,D as Thumbprint,fi as ThumbprintMatch,or as TmbCanon,Me as Uint8ArrayToHex,Kn as Use,ye as Uses,di as Valid,oe as Verify,Gi as VerifyChain,Ni as VerifyCozeArray,Ii as VerifyDig,Fi as VerifyMeta,qi as VerifyMulti,se as VerifyPay,yi as VerifyRevoke,Q as XSize,c as isEmpty};
 //# sourceMappingURL=coze_all.min.js.map
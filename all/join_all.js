@@ -8,7 +8,17 @@
 export * from '../canon.js';
 export * from '../alg.js';
 export * from '../coze.js';
+export * from '../conversion.js';
 export * from '../key.js';
 export * from '../cryptokey.js';
+export * from '../ecdsa.js';
+export * from '../hash.js';
+export * from '../der.js';
+export * from '../error.js';
 // Coze Standard
-export * from '../standard/coze_array.js';
\ No newline at end of file
+export * from '../standard/coze_array.js';
+export * from '../standard/coze_chain.js';
+export * from '../standard/coze_multi.js';
+export * from '../standard/download.js';
+export * from '../standard/keystore.js';
+export * from '../standard/qr.js';
\ No newline at end of file
@@ -4,7 +4,15 @@ import {
 	isEmpty,
 	SToArrayBuffer,
 	ArrayBufferTo64ut
-} from './coze.js';
+} from './conversion.js';
+import {
+	Digest
+} from './hash.js';
+import {
+	CozeCanonError,
+	CozeAlgError,
+	ErrCodes,
+} from './error.js';
 
 export {
 	Canon,
@@ -12,12 +20,15 @@ export {
 	CanonicalS,
 	CanonicalHash,
 	CanonicalHash64,
+	NormalizeUnicode,
 }
 
 /**
 @typedef {import('./typedef.js').Hsh}     Hsh
 @typedef {import('./typedef.js').Dig}     Dig
 @typedef {import('./typedef.js').Can}     Can
+@typedef {import('./typedef.js').NestedCan}  NestedCan
+@typedef {import('./typedef.js').CanonOpts}  CanonOpts
  */
 
 /**
@@ -30,62 +41,162 @@ function Canon(obj) {
 }
 
 /**
-Canon canonicalizes the first level of "object" into the form of "can". 
+Canon canonicalizes "object" into the form of "can".  Fields are put in the
+order of the canon and fields not in the canon are dropped.
 
-Can may be an array or object.  If object, only the first level keys are used as
-canon.  If given cannon is array, array is converted to object for field
-deduplication.
-@param   {object}         object    Object to be canonicalized.
-@param   {Can|object}     [can]     Array|Object canon.
-@returns {object}                   Canonicalized object.
-@throws  {error}                    Fails on invalid canon.
+Can may be an array or object.  Array elements are field names or, for a nested
+canon, an object of field names to the canon for that field, e.g.
+`["alg",{"img":["dig","id"]},"tmb"]`.  If can is an object, its keys are the
+canon and a key whose value is an array or object is a nested canon for that
+field, e.g. `{"alg":"","img":{"dig":"","id":""},"tmb":""}`.  Nested canons are
+applied recursively, drop unknown nested fields like the top level, and are
+applied to each element of an array of objects.  Fields without a nested canon
+are copied as is.
+@param   {object}           object    Object to be canonicalized.
+@param   {NestedCan|object} [can]     Array|Object canon.
+@returns {object}                     Canonicalized object.
+@throws  {error}                      Fails on invalid canon or duplicate fields.
  */
 async function Canonical(object, can) {
 	if (isEmpty(can)) {
 		return object;
 	}
+	return canonical(object, can);
+}
+
+/**
+canonical recursively applies can to value.  See Canonical.
+@param   {any}        value
+@param   {NestedCan}  can
+@returns {any}
+@throws  {error}
+ */
+function canonical(value, can) {
+	if (Array.isArray(value)) {
+		return value.map(v => canonical(v, can));
+	}
+	if (value === null || typeof value !== "object") {
+		return value;
+	}
 	let obj = {};
-	for (const e of can) {
-		obj[e] = object[e];
+	for (const [f, sub] of canonFields(can)) {
+		if (sub === null || value[f] === undefined) {
+			obj[f] = value[f];
+		} else {
+			obj[f] = canonical(value[f], sub);
+		}
 	}
 	return obj;
 }
 
+/**
+canonFields returns the [field, nested canon] pairs of can.  The nested canon
+is null for fields without one.
+@param   {NestedCan}   can
+@returns {Array}
+@throws  {error}       Fails on invalid canon or duplicate fields.
+ */
+function canonFields(can) {
+	let fields = [];
+	if (Array.isArray(can)) {
+		for (const e of can) {
+			if (typeof e === "string") {
+				fields.push([e, null]);
+			} else if (e !== null && typeof e === "object" && !Array.isArray(e)) {
+				for (const [f, sub] of Object.entries(e)) {
+					fields.push([f, sub]);
+				}
+			} else {
+				throw new CozeCanonError("Canonical: invalid canon element: " + JSON.stringify(e), ErrCodes.CanonInvalid);
+			}
+		}
+	} else if (can !== null && typeof can === "object") {
+		for (const [f, sub] of Object.entries(can)) {
+			fields.push([f, (sub !== null && typeof sub === "object") ? sub : null]);
+		}
+	} else {
+		throw new CozeCanonError("Canonical: canon must be an array or object.", ErrCodes.CanonInvalid);
+	}
+	let names = new Set(fields.map(f => f[0]));
+	if (names.size !== fields.length) {
+		throw new CozeCanonError("Canonical: Canon cannot have duplicate fields.", ErrCodes.CanonInvalid);
+	}
+	return fields;
+}
+
 /**
 CanonicalS canonicalizes obj and returns a JSON string.
-@param   {object}   obj
-@param   {Can}      [can]
+@param   {object}     obj
+@param   {NestedCan}  [can]
+@param   {CanonOpts}  [opts]
 @returns {string}
 @throws  {error}
  */
-async function CanonicalS(obj, can) {
+async function CanonicalS(obj, can, opts) {
+	if (!isEmpty(opts) && opts.normalizeUnicode === true) {
+		obj = NormalizeUnicode(obj);
+	}
 	return JSON.stringify(await Canonical(obj, can));
 }
 
+/**
+NormalizeUnicode returns a copy of value with all strings, including those in
+nested objects and arrays, normalized to Unicode NFC.  Object keys are not
+normalized.  For example, "e" followed by U+0301 (combining acute accent)
+becomes "é" (U+00E9).
+@param   {any}   value
+@returns {any}
+ */
+function NormalizeUnicode(value) {
+	if (typeof value === "string") {
+		return value.normalize("NFC");
+	}
+	if (Array.isArray(value)) {
+		return value.map(NormalizeUnicode);
+	}
+	if (value !== null && typeof value === "object") {
+		let obj = {};
+		for (const k of Object.keys(value)) {
+			obj[k] = NormalizeUnicode(value[k]);
+		}
+		return obj;
+	}
+	return value;
+}
+
 /**
 CanonicalHash puts input into canonical form and returns the array buffer of
-the digest.
-@param   {object}        input     Object being canonicalized.
-@param   {Hsh}           hash      Must be SubtleCrypto.digest() compatible (i.e. 'SHA-256').
-@param   {Can}           [can]     Array for canonical keys.
+the digest.  Input may also be UTF-8 JSON bytes.  Without a canon, bytes are
+hashed as given, so bytes from other sources, like hex, need no conversion.
+@param   {object|Uint8Array|ArrayBuffer} input  Object being canonicalized.
+@param   {Hsh}           hash      SubtleCrypto.digest() compatible (i.e. 'SHA-256') or 'SHA-224'.
+@param   {NestedCan}     [can]     Array for canonical keys.  May be nested.
+@param   {CanonOpts}     [opts]
 @returns {ArrayBuffer}             ArrayBuffer of the digest.
-@throws  {error}                   Fails if hash is not given or invalid for SubtleCrypto.digest().
+@throws  {error}                   Fails if hash is not given or invalid.
  */
-async function CanonicalHash(input, hash, can) {
+async function CanonicalHash(input, hash, can, opts) {
 	if (isEmpty(hash)) {
-		throw new Error("Hash is not given");
+		throw new CozeAlgError("Hash is not given", ErrCodes.AlgUnsupported);
+	}
+	if (input instanceof Uint8Array || input instanceof ArrayBuffer) {
+		if (isEmpty(can) && isEmpty(opts)) {
+			return await Digest(hash, input);
+		}
+		input = JSON.parse(new TextDecoder().decode(input));
 	}
-	return await crypto.subtle.digest(hash, await SToArrayBuffer(await CanonicalS(input, can)));
+	return await Digest(hash, await SToArrayBuffer(await CanonicalS(input, can, opts)));
 }
 
 /**
 CanonicalHash64 wraps CanonicalHash to return b64ut digest. 
-@param   {object}         obj
+@param   {object|Uint8Array|ArrayBuffer} obj
 @param   {Hsh}            hash
-@param   {Can}            [canon]
+@param   {NestedCan}      [canon]
+@param   {CanonOpts}      [opts]
 @returns {Dig}
 @throws  {error}
  */
-async function CanonicalHash64(obj, hash, can) {
-	return await ArrayBufferTo64ut(await CanonicalHash(obj, hash, can));
+async function CanonicalHash64(obj, hash, can, opts) {
+	return await ArrayBufferTo64ut(await CanonicalHash(obj, hash, can, opts));
 }
\ No newline at end of file
@@ -0,0 +1,254 @@
+"use strict";
+
+import {
+	CozeError,
+	ErrCodes,
+} from './error.js';
+
+// Conversion has no dependencies other than `error.js` so that it may be
+// imported without the rest of Coze.
+export {
+	SToArrayBuffer,
+	B64uToArrayBuffer,
+	B64ToUint8Array,
+	ArrayBufferTo64ut,
+	B64Lenient,
+	B64Error,
+	HexToUint8Array,
+	Uint8ArrayToHex,
+	HexToB64ut,
+	B64utToHex,
+
+	// Helpers
+	isEmpty,
+}
+
+/**
+@typedef {import('./typedef.js').B64}  B64
+*/
+
+///////////////////////////////////
+// Base Conversion
+///////////////////////////////////
+
+/**
+Converts a string (UTF-8) to an ArrayBuffer.
+@param  {string}        string
+@return {ArrayBuffer}
+ */
+async function SToArrayBuffer(string) {
+	return new TextEncoder().encode(string).buffer; // Suppose to be always in UTF-8
+}
+
+/**
+B64Error is thrown on invalid or non-canonical b64ut, with code
+ERR_B64_INVALID.  `field` is the name of the offending field, e.g. "sig" or
+"x", when known.
+*/
+class B64Error extends CozeError {
+	constructor(message, field) {
+		super(message, ErrCodes.B64Invalid, {
+			field: field
+		});
+		this.name = "B64Error";
+	}
+}
+
+/**
+B64uToArrayBuffer decodes strict b64ut to an ArrayBuffer.  See B64ToUint8Array.
+@param   {B64}          string 
+@param   {string}       [field]   Field name for errors.
+@returns {ArrayBuffer}
+@throws  {B64Error}
+ */
+function B64uToArrayBuffer(string, field) {
+	return B64ToUint8Array(string, field).buffer;
+};
+
+/**
+B64ToUint8Array decodes strict b64ut (base64 URI truncated).  Padding,
+standard base64 characters (`+` and `/`), whitespace, and non-canonical
+encodings with non-zero trailing bits (e.g. "hOl" for "hOk") are refused, so
+that a value has only one valid encoding and two strings can never decode to
+the same bytes.  Use B64Lenient to normalize other base64 to b64ut.
+@param   {B64}          string 
+@param   {string}       [field]   Field name for errors.
+@returns {Uint8Array}
+@throws  {B64Error}
+ */
+function B64ToUint8Array(string, field) {
+	let f = isEmpty(field) ? "" : ` for field "${field}"`;
+	if (typeof string !== "string") {
+		throw new B64Error(`B64ToUint8Array: b64ut must be a string${f}.`, field);
+	}
+	// Length 1 mod 4 cannot be produced by any encoding.
+	if (!/^[A-Za-z0-9_-]*$/.test(string) || string.length % 4 === 1) {
+		throw new B64Error(`B64ToUint8Array: invalid b64ut${f}.`, field);
+	}
+
+	// Make sure that the encoding is canonical.  See issue "Enforce Canonical
+	// Base64 encoding" https://github.com/Cyphrme/Coze/issues/18. atob ignores
+	// trailing bits, so re-encoding detects non-canonical encodings.
+	let bin = atob(string.replace(/-/g, '+').replace(/_/g, '/'));
+	let bytes = Uint8Array.from(bin, c => c.charCodeAt(0));
+	if (ArrayBufferTo64ut(bytes) !== string) {
+		throw new B64Error(`B64ToUint8Array: non-canonical b64ut${f}.`, field);
+	}
+	return bytes;
+};
+
+/**
+B64Lenient normalizes base64 to b64ut.  Standard and URI alphabets, padding,
+whitespace, and non-zero trailing bits are accepted.  Only use B64Lenient for
+input from outside sources; Coze values must be strict b64ut.
+@param   {string}   string
+@returns {B64}
+@throws  {B64Error}           Fails if string is not base64.
+ */
+function B64Lenient(string) {
+	let s = string.replace(/\s/g, '').replace(/=+$/, '').replace(/-/g, '+').replace(/_/g, '/');
+	if (!/^[A-Za-z0-9+/]*$/.test(s) || s.length % 4 === 1) {
+		throw new B64Error("B64Lenient: invalid base64.");
+	}
+	return ArrayBufferTo64ut(Uint8Array.from(atob(s), c => c.charCodeAt(0)));
+}
+
+/**
+ArrayBufferTo64ut returns a b64 string from an Array buffer.
+@param   {ArrayBuffer} buffer  Arbitrary bytes. UTF-16 is Javascript native.
+@returns {B64}
+ */
+function ArrayBufferTo64ut(buffer) {
+	return btoa(String.fromCharCode.apply(null, new Uint8Array(buffer))).replace(/\+/g, '-').replace(/\//g, '_').replace(/=/g, '');
+}
+
+/**
+HexToUint8Array decodes a hex string.  Upper and lower case are accepted, as
+is an optional "0x" prefix.
+@param   {string}       hex
+@returns {Uint8Array}
+@throws  {error}        Fails on odd length or non-hex characters.
+ */
+function HexToUint8Array(hex) {
+	hex = hex.replace(/^0x/i, '');
+	if (hex.length % 2 !== 0) {
+		throw new CozeError("HexToUint8Array: hex must have an even number of characters, got " + hex.length + ".", ErrCodes.HexInvalid);
+	}
+	if (!/^[0-9a-fA-F]*$/.test(hex)) {
+		throw new CozeError("HexToUint8Array: invalid hex character.", ErrCodes.HexInvalid);
+	}
+	let bytes = new Uint8Array(hex.length / 2);
+	for (let i = 0; i < bytes.length; i++) {
+		bytes[i] = parseInt(hex.substring(i * 2, i * 2 + 2), 16);
+	}
+	return bytes;
+}
+
+/**
+Uint8ArrayToHex returns lower case hex, without a prefix, from bytes.
+@param   {Uint8Array|ArrayBuffer}  bytes
+@returns {string}
+ */
+function Uint8ArrayToHex(bytes) {
+	return Array.from(new Uint8Array(bytes), b => b.toString(16).padStart(2, '0')).join('');
+}
+
+/**
+HexToB64ut converts hex (see HexToUint8Array) to b64ut.
+@param   {string}  hex
+@returns {B64}
+@throws  {error}
+ */
+function HexToB64ut(hex) {
+	return ArrayBufferTo64ut(HexToUint8Array(hex));
+}
+
+/**
+B64utToHex converts b64ut to lower case hex.
+@param   {B64}     b64
+@returns {string}
+@throws  {error}
+ */
+function B64utToHex(b64) {
+	return Uint8ArrayToHex(B64ToUint8Array(b64));
+}
+
+
+///////////////////////////////////
+// Helpers - Taken from Cyphr.me
+///////////////////////////////////
+
+/**
+isEmpty is a helper function to determine if thing is empty. 
+
+Functions are considered always not empty. 
+
+Arrays: Only if an array has no elements it is empty.  isEmpty does not check
+element contents.  (For item contents, do: `isEmpty(array[0])`)
+
+Objects are empty if they have no keys. (Returns len === 0 of object keys.)
+
+NaN returns true.  (NaN === NaN is always false, as NaN is never equal to
+anything. NaN is the only JavaScript value unequal to itself.)
+
+Don't use on HTMl elements. For HTML elements, use the !== equality check
+(element !== null). TODO fix this
+
+Cannot use CryptoKey with this function since (len === 0) always. 
+@param   {any}     thing    Thing you wish was empty.  
+@returns {boolean}          Boolean.  
+*/
+function isEmpty(thing) {
+	if (typeof thing === 'function') {
+		return false
+	}
+
+	if (Array.isArray(thing)) {
+		if (thing.length == 0) {
+			return true
+		}
+	}
+
+	if (thing === Object(thing)) {
+		if (Object.keys(thing).length === 0) {
+			return true
+		}
+		return false
+	}
+
+	if (!isBool(thing)) {
+		return true
+	}
+	return false
+}
+
+
+/**
+isBool is a helper function to determine boolean.  
+
+Javascript, instead of considering everything false except a few key words,
+decided everything is true instead of a few key words.  Why?  Because
+Javascript.  This function inverts that assumption, so that everything can be
+considered false unless true. 
+@param   {any}      bool   Thing that you wish was a boolean.  
+@returns {boolean}         An actual boolean.
+*/
+function isBool(bool) {
+	if (
+		bool === false ||
+		bool === "false" ||
+		bool === undefined ||
+		bool === "undefined" ||
+		bool === "" ||
+		bool === 0 ||
+		bool === "0" ||
+		bool === null ||
+		bool === "null" ||
+		bool === "NaN" ||
+		Number.isNaN(bool) ||
+		bool === Object(bool) // isObject
+	) {
+		return false
+	}
+	return true
+}
\ No newline at end of file
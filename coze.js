@@ -4,20 +4,66 @@ import * as Can from './canon.js'; // import as "Can" since func "Canon" will co
 import * as Enum from './alg.js';
 import * as CZK from './key.js';
 import * as CTK from './cryptokey.js';
+import {
+	ECDSA
+} from './ecdsa.js';
+import * as DER from './der.js';
+import {
+	SToArrayBuffer,
+	B64uToArrayBuffer,
+	B64ToUint8Array,
+	ArrayBufferTo64ut,
+	B64Lenient,
+	B64Error,
+	HexToUint8Array,
+	Uint8ArrayToHex,
+	HexToB64ut,
+	B64utToHex,
+	isEmpty,
+} from './conversion.js';
+import {
+	CozeError,
+	CozeKeyError,
+	CozeVerifyError,
+	CozeCanonError,
+	CozeAlgError,
+	ErrCodes,
+} from './error.js';
 
 export {
 	Sign,
 	SignPay,
 	SignCozeRaw,
+	SignCryptoKey,
 	Verify,
+	VerifyMeta,
 	VerifyPay,
+	SignDig,
+	VerifyDig,
 	Meta,
+	ScrubCoze,
+	Attach,
+	Detach,
+	Equal,
+	EqualStrict,
 
-	// Base conversion
+	// Strict JSON
+	ParseStrict,
+	CheckDuplicates,
+	Detect,
+	InputTypes,
+
+	// Base conversion.  See `conversion.js`.
 	SToArrayBuffer,
 	B64uToArrayBuffer,
 	B64ToUint8Array,
 	ArrayBufferTo64ut,
+	B64Lenient,
+	B64Error,
+	HexToUint8Array,
+	Uint8ArrayToHex,
+	HexToB64ut,
+	B64utToHex,
 
 	// Helpers
 	isEmpty,
@@ -27,13 +73,18 @@ export {
 
 /**
 @typedef {import('./typedef.js').Alg}            Alg
+@typedef {import('./typedef.js').Iat}            Iat
 @typedef {import('./typedef.js').B64}            B64
 @typedef {import('./typedef.js').Coze}           Coze
 @typedef {import('./typedef.js').Pay}            Pay
 @typedef {import('./typedef.js').Sig}            Sig
 @typedef {import('./typedef.js').Key}            Key
+@typedef {import('./typedef.js').Keyring}        Keyring
 @typedef {import('./typedef.js').Can}            Can
+@typedef {import('./typedef.js').Dig}            Dig
 @typedef {import('./typedef.js').Meta}           Meta
+@typedef {import('./typedef.js').SignOpts}       SignOpts
+@typedef {import('./typedef.js').VerifyOpts}     VerifyOpts
 @typedef {import('./typedef.js').VerifiedArray}  VerifiedArray
  */
 
@@ -45,44 +96,90 @@ const PayCanon = ["alg", "iat", "tmb", "typ"];
 SignCoze signs in place coze.pay.  It populates/replaces alg and tmb using
 the given private Coze key and populates/updates iat. Returns the same, but
 updated, coze.  The optional canon is used to canonicalize pay before
-signing.  If needing a coze without alg, tmb, or iat, use SignCozeRaw.  
+signing.  If needing a coze without alg, tmb, or iat, use SignCozeRaw.  If
+opts.setStandard is set, values already in pay are not replaced and must match,
+as for SignCozeRaw.
 
 SignCoze, SignCozeRaw, and VerifyCoze assumes that object has no duplicate
-fields since this is disallowed in Javascript.
-@param   {Coze}      coze       Object coze.
-@param   {Key}       cozeKey    A private coze key.
+fields since this is disallowed in Javascript.  coze and cozeKey may also be
+given as JSON strings, which are parsed with ParseStrict and rejected if they
+contain duplicate fields.
+@param   {Coze|string}  coze       Object coze.
+@param   {Key|string}   cozeKey    A private coze key.
 @param   {Can}       [canon]    Array for canonical keys.
+@param   {SignOpts}  [opts]     Sign options.  See SignPay.  opts.iat sets iat.
 @returns {Coze}                 Coze that may have been modified from given.
 @throws  {error}                Fails on invalid key, parse error, mismatch fields.
  */
-async function Sign(coze, cozeKey, canon) {
+async function Sign(coze, cozeKey, canon, opts) {
 	console.log()
+	coze = fromJSON(coze);
+	cozeKey = fromJSON(cozeKey);
 	if (CZK.IsRevoked(cozeKey)) {
-		throw new Error("SignCoze: Cannot sign with revoked key.");
+		throw new CozeKeyError("SignCoze: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
 	}
 
-	coze.pay.alg = cozeKey.alg;
-	coze.pay.tmb = await CZK.Thumbprint(cozeKey);
-	coze.pay.iat = Math.round((Date.now() / 1000)); // Javascript's Date converted to Unix time.
+	if (!isEmpty(opts) && opts.setStandard === true) {
+		coze.pay = await setStandard(coze.pay, cozeKey, opts);
+	} else {
+		coze.pay.alg = cozeKey.alg;
+		coze.pay.tmb = await CZK.Thumbprint(cozeKey);
+		coze.pay.iat = iatFromOpts(opts);
+	}
+	if (!isEmpty(opts) && opts.normalizeUnicode === true) {
+		coze.pay = Can.NormalizeUnicode(coze.pay);
+	}
 
 	if (!isEmpty(canon)) {
 		coze.pay = await Can.Canonical(coze.pay, canon);
 	}
 
-	coze.sig = await SignPay(JSON.stringify(coze.pay), cozeKey);
+	coze.sig = await SignPay(JSON.stringify(coze.pay), cozeKey, opts);
 	return coze;
 }
 
 
 
 /**
-SignPay signs message with private Coze key and returns b64ut sig.
-@param   {Pay}       pay      ay. e.g. `{"alg"...}` May also be any message.  
+SignPay signs message with private Coze key and returns b64ut sig.  If pay is
+JSON, SignPay refuses to sign pay containing duplicate fields, and if
+opts.normalizeUnicode is set, the sig is over pay with string values normalized
+to NFC and re-serialized.  ECDSA signatures are always normalized to low-S, so
+the same sig (and czd) is not issued in both high-S and low-S form.
+
+If opts.deterministic is set, ECDSA nonces are generated as specified by RFC
+6979 so that the sig is always the same for the same pay and key.  The
+signature is calculated in Javascript using `d` which is slower than
+SubtleCrypto.  Deterministic signatures verify like any other signature.
+@param   {Pay|string} pay     Pay. e.g. `{"alg"...}` May also be any message.  
 @param   {Key}       cozeKey
+@param   {SignOpts}  [opts]   Sign options.
 @returns {Sig}
 @throws  {error}     Error, SyntaxError, DOMException, TypeError
  */
-async function SignPay(pay, cozeKey) {
+async function SignPay(pay, cozeKey, opts) {
+	let isJSON = true;
+	try {
+		JSON.parse(pay);
+	} catch (e) {
+		isJSON = false; // Any message may be signed.
+	}
+	if (isJSON) {
+		CheckDuplicates(pay);
+		if (!isEmpty(opts) && opts.normalizeUnicode === true) {
+			pay = JSON.stringify(Can.NormalizeUnicode(JSON.parse(pay)));
+		}
+	}
+	checkHash(cozeKey.alg, opts);
+	if (!isEmpty(opts) && opts.deterministic === true && Enum.Genus(cozeKey.alg) == Enum.GenAlgs.ECDSA) {
+		if (isEmpty(cozeKey.d)) {
+			throw new CozeKeyError("SignPay: deterministic signing requires private component d.", ErrCodes.KeyInvalid, {
+				field: "d"
+			});
+		}
+		let sig = await ECDSA.SignBuffer(await ECDSA.FromCozeKey(cozeKey), await SToArrayBuffer(pay), true);
+		return CTK.SigToLowS(cozeKey.alg, ArrayBufferTo64ut(sig));
+	}
 	return CTK.CryptoKey.SignBufferB64(
 		await CTK.CryptoKey.FromCozeKey(cozeKey),
 		await SToArrayBuffer(pay)
@@ -94,57 +191,718 @@ async function SignPay(pay, cozeKey) {
 /**
 SignCozeRaw signs in place coze.pay with a private Coze key, but unlike
 SignCoze, does not set `alg`, `tmb` or `iat`. The optional canon is used to
-canonicalize pay before signing. 
-@param   {Coze}      coze       Object coze.
-@param   {Key}       cozeKey    A private coze key.
+canonicalize pay before signing. coze and cozeKey may be JSON strings.
+
+If opts.setStandard is set, `alg`, `tmb`, and `iat` are set if missing, but
+unlike SignCoze, values already in pay are never replaced.  A set `alg` or
+`tmb` must match the key and a set `iat` must match opts.iat if given.
+@param   {Coze|string}  coze       Object coze.
+@param   {Key|string}   cozeKey    A private coze key.
 @param   {Can}     [canon]    Array for canonical keys.
+@param   {SignOpts}  [opts]   Sign options.  See SignPay.
 @returns {Coze}                 Coze with new `sig` and canonicalized `pay`.
 @throws  {error}                Fails on rvk or mismatch `alg` or `tmb`.
  */
-async function SignCozeRaw(coze, cozeKey, canon) {
+async function SignCozeRaw(coze, cozeKey, canon, opts) {
+	coze = fromJSON(coze);
+	cozeKey = fromJSON(cozeKey);
 	if (CZK.IsRevoked(cozeKey)) {
-		throw new Error("SignCozeRaw: Cannot sign with revoked key.");
+		throw new CozeKeyError("SignCozeRaw: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
 	}
-	if (!isEmpty(coze.pay.alg) && coze.pay.alg !== cozeKey.alg) {
-		throw new Error("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.");
+	if (!isEmpty(opts) && opts.setStandard === true) {
+		coze.pay = await setStandard(coze.pay, cozeKey, opts);
+	} else {
+		if (!isEmpty(coze.pay.alg) && coze.pay.alg !== cozeKey.alg) {
+			throw new CozeAlgError("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.", ErrCodes.AlgMismatch, {
+				alg: coze.pay.alg
+			});
+		}
+		if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== cozeKey.tmb) {
+			throw new CozeKeyError("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.", ErrCodes.TmbMismatch, {
+				field: "tmb"
+			});
+		}
 	}
-	if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== cozeKey.tmb) {
-		throw new Error("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.");
+	if (!isEmpty(opts) && opts.normalizeUnicode === true) {
+		coze.pay = Can.NormalizeUnicode(coze.pay);
 	}
 
 	if (!isEmpty(canon)) {
 		coze.pay = await Can.Canonical(coze.pay, canon);
 	}
-	coze.sig = await SignPay(JSON.stringify(coze.pay), cozeKey);
+	coze.sig = await SignPay(JSON.stringify(coze.pay), cozeKey, opts);
+	return coze;
+}
+
+
+/**
+setStandard returns pay with `alg`, `iat`, and `tmb` set, first and in that
+order, followed by the other fields of pay.  Set values are not replaced and
+must match.
+@param   {Pay}        pay
+@param   {Key}        cozeKey
+@param   {SignOpts}   opts
+@returns {Pay}
+@throws  {error}      Fails on mismatch alg, tmb, or iat.
+ */
+async function setStandard(pay, cozeKey, opts) {
+	let tmb = await CZK.Thumbprint(cozeKey);
+	if (!isEmpty(pay.alg) && pay.alg !== cozeKey.alg) {
+		throw new CozeAlgError("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.", ErrCodes.AlgMismatch, {
+			alg: pay.alg
+		});
+	}
+	if (!isEmpty(pay.tmb) && pay.tmb !== tmb) {
+		throw new CozeKeyError("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.", ErrCodes.TmbMismatch, {
+			field: "tmb"
+		});
+	}
+	let iat = pay.iat;
+	if (iat === undefined) {
+		iat = iatFromOpts(opts);
+	} else if (opts.iat !== undefined && opts.iat !== iat) {
+		throw new CozeError(`SignCozeRaw: coze.pay.iat (${iat}) mismatch with opts.iat (${opts.iat}).`, ErrCodes.IatInvalid, {
+			field: "iat"
+		});
+	}
+	let std = {
+		alg: cozeKey.alg,
+		iat: iat,
+		tmb: tmb,
+	};
+	for (const [k, v] of Object.entries(pay)) {
+		if (!(k in std)) {
+			std[k] = v;
+		}
+	}
+	return std;
+}
+
+/**
+iatFromOpts returns opts.iat if set, otherwise the current Unix time.
+@param   {SignOpts}  [opts]
+@returns {Iat}
+@throws  {error}     Fails if opts.iat is not a non-negative integer.
+ */
+function iatFromOpts(opts) {
+	if (isEmpty(opts) || opts.iat === undefined) {
+		return Math.round((Date.now() / 1000)); // Javascript's Date converted to Unix time.
+	}
+	if (!Number.isSafeInteger(opts.iat) || opts.iat < 0) {
+		throw new CozeError("Sign: opts.iat must be a non-negative integer.", ErrCodes.IatInvalid, {
+			field: "iat"
+		});
+	}
+	return opts.iat;
+}
+
+/**
+SignCryptoKey signs pay with a private CryptoKey and returns a new coze.  The
+CryptoKey may be non-extractable so that `d` never exists in Javascript.
+`alg`, `tmb`, and `iat` are populated like SignCoze using the given public Coze
+key, and tmb is calculated from the public key's `x`.  After signing, sig is
+verified with the public Coze key to guarantee the keys are a pair.
+@param   {Pay|string}   pay         Object pay.  May be a JSON string.
+@param   {CryptoKey}    cryptoKey   Private CryptoKey.  May be non-extractable.
+@param   {Key}          cozeKey     Public Coze key for the CryptoKey.
+@param   {Can}          [canon]     Array for canonical keys.
+@returns {Coze}
+@throws  {error}                    Fails on rvk, mismatch `alg`, or mismatch keys.
+ */
+async function SignCryptoKey(pay, cryptoKey, cozeKey, canon) {
+	pay = fromJSON(pay);
+	cozeKey = fromJSON(cozeKey);
+	if (CZK.IsRevoked(cozeKey)) {
+		throw new CozeKeyError("SignCryptoKey: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
+	}
+	if (cryptoKey.type !== "private") {
+		throw new CozeKeyError("SignCryptoKey: CryptoKey must be private.", ErrCodes.KeyInvalid);
+	}
+	if (await CTK.CryptoKey.algFromCryptoKey(cryptoKey) !== cozeKey.alg) {
+		throw new CozeAlgError("SignCryptoKey: CryptoKey alg mismatch with cozeKey.alg.", ErrCodes.AlgMismatch, {
+			alg: cozeKey.alg
+		});
+	}
+
+	pay.alg = cozeKey.alg;
+	pay.tmb = await CZK.Thumbprint(cozeKey);
+	pay.iat = Math.round((Date.now() / 1000)); // Javascript's Date converted to Unix time.
+	if (!isEmpty(canon)) {
+		pay = await Can.Canonical(pay, canon);
+	}
+
+	let coze = {
+		pay: pay,
+		sig: await CTK.CryptoKey.SignString(cryptoKey, JSON.stringify(pay)),
+	};
+	if (!await VerifyPay(JSON.stringify(pay), cozeKey, coze.sig)) {
+		throw new CozeKeyError("SignCryptoKey: CryptoKey is not the private key of cozeKey.", ErrCodes.KeyMismatch);
+	}
 	return coze;
 }
 
 
 /**
 VerifyCoze returns a whether or not the Coze is valid. coze.sig must be set.
-If set, pay.alg and pay.tmb must match with cozeKey.
-@param  {Coze}     coze         Coze with signed pay. e.g. `{"pay":..., "sig":...}`
-@param  {Key}      [cozeKey]    Public Coze key for verification.
-@param  {Sig}      [sig]        Signature.
+If set, pay.alg and pay.tmb must match with cozeKey.  Revoked keys (see
+IsRevoked) are refused unless opts.allowRevoked is set, e.g. for forensics.
+Use VerifyRevoke for self-revoke cozies.
+
+If opts.canon is set, pay's fields must be exactly the fields of the canon.  If
+opts.canonContains is set, pay must contain the given fields but may contain
+others.  Missing fields and extra fields throw CozeCanonError with codes
+ERR_CANON_MISSING and ERR_CANON_EXTRA and the offending `fields`.  The
+digest is calculated over pay as given unless opts.normalizeUnicode is set, in
+which case string values are first normalized to NFC (See SignOpts).  If
+opts.acceptDER is set, a DER encoded ECDSA sig (see DERToSig) is converted
+before verifying.  By default, only Coze's r || s is accepted.
+
+opts.maxAge, opts.notBefore, and opts.notAfter check pay.iat after the
+signature is verified.  Outside of the window, CozeVerifyError is thrown with
+code ERR_EXPIRED or ERR_NOT_YET_VALID and `verified` true, so that "valid
+signature but expired" is distinguishable from invalid signatures, for which
+Verify returns false.  See checkTime.
+
+cozeKey may be a keyring, an array of keys or a Map of tmb to key, in which
+case the key matching pay.tmb is used (See LookupKey).  If there is no such
+key, CozeKeyError is thrown with code ERR_KEY_NOT_FOUND.  As with a single
+key, a key with an alg other than pay.alg is rejected and not tried.
+
+If cozeKey is not given, the key embedded in coze.key is used.  The embedded
+key is only trusted after its thumbprint, as calculated by Thumbprint and not
+as given by key.tmb, matches pay.tmb and its alg matches pay.alg, if present.
+If both cozeKey and coze.key are given, their thumbprints must match.  Any
+mismatch throws and is never a fallback to the other key.  See embeddedKey.
+
+coze and cozeKey may be JSON strings, which are parsed with ParseStrict so that
+duplicate fields are rejected before any cryptography.
+
+For a detached pay and sig (See Detach), call `Verify(pay, cozeKey, sig, opts)`,
+i.e. with sig in place of opts.  A pay string is then the signed bytes as
+given and is never re-serialized, so that pays serialized by other
+implementations verify.  opts.normalizeUnicode does not apply to pay strings.
+@param  {Coze|Pay|string}     coze         Coze with signed pay. e.g. `{"pay":..., "sig":...}`, or a detached pay.
+@param  {Key|Keyring|string}  [cozeKey]    Public Coze key or keyring for verification.
+@param  {VerifyOpts|Sig}      [opts]       Verify options, or the sig of a detached pay.
+@param  {VerifyOpts}          [detachedOpts]  Verify options for a detached pay.
 @return {boolean}
 @throws {error}
  */
-async function Verify(coze, cozeKey) {
+async function Verify(coze, cozeKey, opts, detachedOpts) {
+	if (typeof opts === "string") {
+		let payBytes;
+		if (typeof coze === "string") {
+			payBytes = coze;
+			coze = ParseStrict(coze);
+		}
+		return verify({
+			pay: coze,
+			sig: opts
+		}, cozeKey, detachedOpts, payBytes);
+	}
+	return verify(coze, cozeKey, opts);
+}
+
+/**
+verify verifies coze.  See Verify.  If payBytes is given, the signature is
+verified over payBytes instead of the serialization of coze.pay.
+@param  {Coze|string}         coze
+@param  {Key|Keyring|string}  [cozeKey]
+@param  {VerifyOpts}          [opts]
+@param  {string}              [payBytes]
+@return {boolean}
+@throws {error}
+ */
+async function verify(coze, cozeKey, opts, payBytes) {
+	coze = fromJSON(coze);
+	cozeKey = fromJSON(cozeKey);
+	let given = cozeKey !== undefined && cozeKey !== null;
+	if (given) {
+		cozeKey = await CZK.LookupKey(cozeKey, coze.pay.tmb);
+	}
+	if (!isEmpty(coze.key)) {
+		cozeKey = await embeddedKey(coze, given ? cozeKey : undefined);
+	} else if (!given) {
+		throw new CozeKeyError("VerifyCoze: no key given and coze has no embedded key.", ErrCodes.KeyInvalid, {
+			field: "key"
+		});
+	}
+	if (CZK.IsRevoked(cozeKey) && (isEmpty(opts) || opts.allowRevoked !== true)) {
+		throw new CozeKeyError("VerifyCoze: Coze key is revoked.", ErrCodes.KeyRevoked);
+	}
 	if (!isEmpty(coze.pay.alg) && coze.pay.alg !== cozeKey.alg) {
-		throw new Error("VerifyCoze: Coze key alg mismatch with coze.pay.alg.");
+		throw new CozeAlgError("VerifyCoze: Coze key alg mismatch with coze.pay.alg.", ErrCodes.AlgMismatch, {
+			alg: coze.pay.alg
+		});
 	}
-	if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== cozeKey.tmb) {
-		throw new Error("VerifyCoze: Coze key tmb mismatch with coze.pay.tmb.");
+	// Calculated, not declared, tmb, which may be wrong or absent.
+	if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== await CZK.Thumbprint(cozeKey)) {
+		throw new CozeKeyError("VerifyCoze: Coze key tmb mismatch with coze.pay.tmb.", ErrCodes.TmbMismatch, {
+			field: "tmb"
+		});
+	}
+	checkHash(cozeKey.alg, opts);
+	// Malformed b64ut is an error, not a failed verification.
+	B64ToUint8Array(coze.sig, "sig");
+	B64ToUint8Array(cozeKey.x, "x");
+	let sig = coze.sig;
+	let pay = coze.pay;
+	if (!isEmpty(opts)) {
+		if (opts.normalizeUnicode === true) {
+			pay = Can.NormalizeUnicode(pay);
+		}
+		checkCanon(pay, opts);
+		if (opts.acceptDER === true && Enum.Genus(cozeKey.alg) == Enum.GenAlgs.ECDSA && DER.IsDERSig(B64ToUint8Array(sig), cozeKey.alg)) {
+			sig = DER.DERToSig(sig, cozeKey.alg);
+		}
+		if (await isHighS(cozeKey.alg, sig, opts)) {
+			return false;
+		}
+	}
+	if (payBytes === undefined) {
+		payBytes = JSON.stringify(pay);
+	}
+	let verified = await VerifyPay(payBytes, cozeKey, sig);
+	if (verified && !isEmpty(opts)) {
+		checkTime(pay, opts);
+	}
+	return verified;
+}
+
+/**
+isHighS returns whether opts.requireLowS is set and sig is a high-S ECDSA
+signature, which Verify then refuses.
+@param  {Alg}         alg
+@param  {Sig}         sig
+@param  {VerifyOpts}  [opts]
+@return {boolean}
+ */
+async function isHighS(alg, sig, opts) {
+	if (isEmpty(opts) || opts.requireLowS !== true || Enum.Genus(alg) !== Enum.GenAlgs.ECDSA) {
+		return false;
+	}
+	let ab = B64uToArrayBuffer(sig);
+	return ab.byteLength === Enum.SigSize(alg) && !await CTK.IsSigLowS(alg, ab);
+}
+
+/**
+checkHash throws CozeAlgError with code ERR_HASH_INVALID if opts.hash is set and
+is not the hashing algorithm of alg.  Coze signature algorithms have a fixed
+hash, so for example a SHA-3 digest cannot be used with ES256.
+@param  {Alg}                  alg
+@param  {SignOpts|VerifyOpts}  [opts]
+@return {void}
+@throws {error}
+ */
+function checkHash(alg, opts) {
+	if (isEmpty(opts) || isEmpty(opts.hash)) {
+		return;
+	}
+	let hsh = Enum.HashAlg(alg);
+	if (opts.hash !== hsh) {
+		throw new CozeAlgError(`Coze: hash not valid for alg: ${opts.hash} is not ${alg}'s hash ${hsh}.`, ErrCodes.HashInvalid, {
+			alg: alg
+		});
 	}
-	return VerifyPay(JSON.stringify(coze.pay), cozeKey, coze.sig);
 }
 
+/**
+embeddedKey checks coze.key, the key embedded in coze, and returns the key to
+use for verification.  The thumbprint of coze.key is calculated and must match
+pay.tmb, if set, so that an attacker cannot swap in their own key, and
+coze.key.alg must match pay.alg, if set.  If cozeKey is given, its thumbprint
+must match coze.key's and cozeKey is returned.  Otherwise, coze.key is returned
+with its calculated tmb.
+@param  {Coze}  coze
+@param  {Key}   [cozeKey]  Key given to Verify.
+@return {Key}
+@throws {error}
+ */
+async function embeddedKey(coze, cozeKey) {
+	let key = coze.key;
+	let tmb = await CZK.Thumbprint(key);
+	if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== tmb) {
+		throw new CozeKeyError("VerifyCoze: coze.key tmb mismatch with coze.pay.tmb.", ErrCodes.TmbMismatch, {
+			field: "key"
+		});
+	}
+	if (!isEmpty(coze.pay.alg) && coze.pay.alg !== key.alg) {
+		throw new CozeAlgError("VerifyCoze: coze.key alg mismatch with coze.pay.alg.", ErrCodes.AlgMismatch, {
+			alg: key.alg
+		});
+	}
+	if (cozeKey !== undefined) {
+		if (await CZK.Thumbprint(cozeKey) !== tmb) {
+			throw new CozeKeyError("VerifyCoze: Coze key tmb mismatch with coze.key.", ErrCodes.TmbMismatch, {
+				field: "key"
+			});
+		}
+		return cozeKey;
+	}
+	return {
+		...key,
+		tmb: tmb
+	};
+}
+
+/**
+checkTime throws if pay.iat is outside of the window given by opts.maxAge,
+opts.notBefore, and opts.notAfter, with opts.clockSkew tolerance (default 60
+seconds).  If any time option is set, pay.iat must be a non-negative integer.
+checkTime is only called for valid signatures, so errors have `verified` true,
+i.e. "valid signature but expired".
+
+- ERR_EXPIRED:        iat is older than maxAge or after notAfter.
+- ERR_NOT_YET_VALID:  iat is in the future or before notBefore.
+- ERR_IAT_INVALID:    iat is missing, not an integer, or negative.
+@param  {Pay}         pay
+@param  {VerifyOpts}  opts
+@return {void}
+@throws {error}
+ */
+function checkTime(pay, opts) {
+	if (opts.maxAge === undefined && opts.notBefore === undefined && opts.notAfter === undefined) {
+		return;
+	}
+	let iat = pay.iat;
+	let ctx = {
+		field: "iat",
+		verified: true
+	};
+	if (!Number.isSafeInteger(iat) || iat < 0) {
+		throw new CozeVerifyError("VerifyCoze: pay.iat must be a non-negative integer when time options are set.", ErrCodes.IatInvalid, ctx);
+	}
+	let skew = opts.clockSkew === undefined ? 60 : opts.clockSkew;
+	let now = Date.now() / 1000;
+	if (opts.maxAge !== undefined) {
+		if (iat + opts.maxAge + skew < now) {
+			throw new CozeVerifyError(`VerifyCoze: coze expired: iat ${iat} is older than maxAge ${opts.maxAge}.`, ErrCodes.Expired, ctx);
+		}
+		if (iat - skew > now) {
+			throw new CozeVerifyError(`VerifyCoze: coze not yet valid: iat ${iat} is in the future.`, ErrCodes.NotYetValid, ctx);
+		}
+	}
+	if (opts.notBefore !== undefined && iat + skew < opts.notBefore) {
+		throw new CozeVerifyError(`VerifyCoze: coze not yet valid: iat ${iat} is before notBefore ${opts.notBefore}.`, ErrCodes.NotYetValid, ctx);
+	}
+	if (opts.notAfter !== undefined && iat - skew > opts.notAfter) {
+		throw new CozeVerifyError(`VerifyCoze: coze expired: iat ${iat} is after notAfter ${opts.notAfter}.`, ErrCodes.Expired, ctx);
+	}
+}
+
+/**
+checkCanon throws if pay does not satisfy opts.canon or opts.canonContains.
+Missing and extra fields throw CozeCanonError with codes ERR_CANON_MISSING and
+ERR_CANON_EXTRA.  Only the top level of a nested canon is checked.
+@param  {Pay}         pay
+@param  {VerifyOpts}  opts
+@return {void}
+@throws {error}
+ */
+function checkCanon(pay, opts) {
+	let fields = Object.keys(pay);
+	let required = [];
+	if (Array.isArray(opts.canon)) {
+		required = opts.canon.flatMap(e => typeof e === "string" ? [e] : Object.keys(e));
+		let extra = fields.filter(f => !required.includes(f));
+		if (extra.length > 0) {
+			throw new CozeCanonError("VerifyCoze: pay has extra field(s) not in canon: " + extra.join(", "), ErrCodes.CanonExtra, {
+				fields: extra
+			});
+		}
+	}
+	if (!isEmpty(opts.canonContains)) {
+		required = required.concat(opts.canonContains);
+	}
+	let missing = required.filter(f => !fields.includes(f));
+	if (missing.length > 0) {
+		missing = [...new Set(missing)];
+		throw new CozeCanonError("VerifyCoze: pay missing field(s) required by canon: " + missing.join(", "), ErrCodes.CanonMissing, {
+			fields: missing
+		});
+	}
+}
+
+
+/**
+VerifyCheck is the result of a single VerifyMeta check.
+
+- name:     Check name, e.g. "sig_size".
+- status:   "pass", "fail", or "skip".  Checks are skipped when not requested
+            by opts or when a check they depend on did not pass.
+- message:  Human readable reason for "fail" and "skip".
+@typedef  {object}  VerifyCheck
+@property {string}  name
+@property {string}  status
+@property {string}  [message]
+*/
+
+/**
+VerifyReport is the result of VerifyMeta.
+
+- verified:  Signature verified and no check failed.
+- meta:      Meta of the coze (can, cad, czd, ...), or null if it could not be
+             calculated.
+- checks:    Every check, in order.  See VerifyMeta.
+@typedef  {object}         VerifyReport
+@property {boolean}        verified
+@property {Meta|null}      meta
+@property {VerifyCheck[]}  checks
+*/
+
+/**
+VerifyMeta verifies coze like Verify, but instead of a boolean returns a
+report of every check so that the stage at which a coze fails is known.
+VerifyMeta does not throw, and malformed input (e.g. non-JSON, no pay, or bad
+b64ut sig) is reported as a failed check.  Like Verify, if cozeKey is not
+given the embedded coze.key is used, and cozeKey may be a keyring.  Checks, in
+order:
+
+- pay_parsed:    coze is JSON with an object pay.
+- key_found:     A key was given or embedded, and found in a keyring.
+- meta:          can, cad, and czd were calculated (See Meta).
+- alg_matches:   pay.alg, if set, matches the key's alg.
+- tmb_matches:   pay.tmb, if set, matches the key's calculated thumbprint, and
+                 a given key matches an embedded key.
+- not_revoked:   Key is not revoked, or opts.allowRevoked is set.
+- hash:          opts.hash is the hash of alg.  Skipped if not given.
+- sig_b64ut:     sig is strict b64ut.
+- sig_size:      sig is Alg.SigSize for alg, after DER conversion if
+                 opts.acceptDER is set.
+- signature:     The signature cryptographically verified.
+- canon:         pay satisfies opts.canon and opts.canonContains.  Skipped if
+                 not given.
+- iat_window:    pay.iat is within the time options.  Skipped if not given.
+@param  {Coze|string}         coze
+@param  {Key|Keyring|string}  [cozeKey]
+@param  {VerifyOpts}          [opts]
+@return {VerifyReport}
+ */
+async function VerifyMeta(coze, cozeKey, opts) {
+	/** @type {VerifyReport} */
+	let report = {
+		verified: false,
+		meta: null,
+		checks: [],
+	};
+	let status = {};
+	let add = function(name, s, message) {
+		let c = {
+			name: name,
+			status: s
+		};
+		if (!isEmpty(message)) {
+			c.message = message;
+		}
+		report.checks.push(c);
+		status[name] = s;
+	};
+	let passed = (...names) => names.every(n => status[n] === "pass");
+	let skipAfter = function(name, ...deps) {
+		add(name, "skip", "Requires passing " + deps.filter(n => status[n] !== "pass").join(", ") + ".");
+	};
+	if (isEmpty(opts)) {
+		opts = {};
+	}
+
+	// pay_parsed
+	try {
+		coze = fromJSON(coze);
+		if (coze === null || typeof coze !== "object" || coze.pay === null || typeof coze.pay !== "object" || Array.isArray(coze.pay)) {
+			add("pay_parsed", "fail", "coze.pay must be an object.");
+		} else {
+			add("pay_parsed", "pass");
+		}
+	} catch (e) {
+		add("pay_parsed", "fail", e.message);
+	}
+
+	// key_found
+	let key;
+	let embedded = false;
+	if (!passed("pay_parsed")) {
+		skipAfter("key_found", "pay_parsed");
+	} else {
+		try {
+			cozeKey = fromJSON(cozeKey);
+			if (cozeKey !== undefined && cozeKey !== null && cozeKey !== "") {
+				key = await CZK.LookupKey(cozeKey, coze.pay.tmb);
+			} else if (!isEmpty(coze.key)) {
+				key = coze.key;
+				embedded = true;
+			}
+			if (isEmpty(key)) {
+				add("key_found", "fail", "No key given and coze has no embedded key.");
+			} else {
+				add("key_found", "pass", embedded ? "Embedded key." : "");
+			}
+		} catch (e) {
+			add("key_found", "fail", e.message);
+		}
+	}
+
+	// meta
+	if (!passed("pay_parsed")) {
+		skipAfter("meta", "pay_parsed");
+	} else {
+		try {
+			report.meta = await Meta(coze, isEmpty(coze.pay.alg) && !isEmpty(key) ? key.alg : undefined);
+			add("meta", "pass");
+		} catch (e) {
+			add("meta", "fail", e.message);
+		}
+	}
+	// alg_matches
+	if (!passed("key_found")) {
+		skipAfter("alg_matches", "pay_parsed", "key_found");
+	} else if (isEmpty(coze.pay.alg)) {
+		add("alg_matches", "skip", "pay has no alg.");
+	} else if (coze.pay.alg !== key.alg) {
+		add("alg_matches", "fail", `pay.alg "${coze.pay.alg}" is not the key's alg "${key.alg}".`);
+	} else {
+		add("alg_matches", "pass");
+	}
+
+	// tmb_matches
+	if (!passed("key_found")) {
+		skipAfter("tmb_matches", "pay_parsed", "key_found");
+	} else {
+		try {
+			let tmb = await CZK.Thumbprint(key);
+			let fail = [];
+			if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== tmb) {
+				fail.push(`pay.tmb "${coze.pay.tmb}" is not the key's thumbprint "${tmb}".`);
+			}
+			if (!embedded && !isEmpty(coze.key) && await CZK.Thumbprint(coze.key) !== tmb) {
+				fail.push("Given key is not the embedded key.");
+			}
+			if (fail.length > 0) {
+				add("tmb_matches", "fail", fail.join("  "));
+			} else {
+				add("tmb_matches", "pass", isEmpty(coze.pay.tmb) ? "pay has no tmb." : "");
+			}
+		} catch (e) {
+			add("tmb_matches", "fail", e.message);
+		}
+	}
+
+	// not_revoked
+	if (!passed("key_found")) {
+		skipAfter("not_revoked", "pay_parsed", "key_found");
+	} else if (!CZK.IsRevoked(key)) {
+		add("not_revoked", "pass");
+	} else if (opts.allowRevoked === true) {
+		add("not_revoked", "pass", "Key is revoked, allowed by opts.allowRevoked.");
+	} else {
+		add("not_revoked", "fail", "Key is revoked.");
+	}
+
+	// hash
+	if (isEmpty(opts.hash)) {
+		add("hash", "skip", "opts.hash not given.");
+	} else if (!passed("key_found")) {
+		skipAfter("hash", "pay_parsed", "key_found");
+	} else {
+		try {
+			checkHash(key.alg, opts);
+			add("hash", "pass");
+		} catch (e) {
+			add("hash", "fail", e.message);
+		}
+	}
+
+	// sig_b64ut, sig_size
+	let sig;
+	if (!passed("pay_parsed")) {
+		skipAfter("sig_b64ut", "pay_parsed");
+	} else if (isEmpty(coze.sig)) {
+		add("sig_b64ut", "fail", "coze has no sig.");
+	} else {
+		try {
+			B64ToUint8Array(coze.sig, "sig");
+			sig = coze.sig;
+			add("sig_b64ut", "pass");
+		} catch (e) {
+			add("sig_b64ut", "fail", e.message);
+		}
+	}
+	let alg = (!isEmpty(key) && !isEmpty(key.alg)) ? key.alg : passed("pay_parsed") ? coze.pay.alg : undefined;
+	if (!passed("sig_b64ut")) {
+		skipAfter("sig_size", "sig_b64ut");
+	} else {
+		try {
+			if (opts.acceptDER === true && Enum.Genus(alg) == Enum.GenAlgs.ECDSA && DER.IsDERSig(B64ToUint8Array(sig), alg)) {
+				sig = DER.DERToSig(sig, alg);
+			}
+			let size = B64ToUint8Array(sig).length;
+			if (size !== Enum.SigSize(alg)) {
+				add("sig_size", "fail", `sig is ${size} bytes, ${alg} requires ${Enum.SigSize(alg)}.`);
+			} else {
+				add("sig_size", "pass");
+			}
+		} catch (e) {
+			add("sig_size", "fail", e.message);
+		}
+	}
+
+	// signature
+	let pay = passed("pay_parsed") ? coze.pay : undefined;
+	if (!passed("key_found", "sig_size") || status.alg_matches === "fail") {
+		skipAfter("signature", ...(status.alg_matches === "fail" ? ["key_found", "alg_matches", "sig_size"] : ["key_found", "sig_size"]));
+	} else {
+		try {
+			if (opts.normalizeUnicode === true) {
+				pay = Can.NormalizeUnicode(pay);
+			}
+			if (await isHighS(key.alg, sig, opts)) {
+				add("signature", "fail", "High-S signature refused by opts.requireLowS.");
+			} else if (await VerifyPay(JSON.stringify(pay), key, sig)) {
+				add("signature", "pass");
+			} else {
+				add("signature", "fail", "Signature did not verify.");
+			}
+		} catch (e) {
+			add("signature", "fail", e.message);
+		}
+	}
+
+	// canon
+	if (isEmpty(opts.canon) && isEmpty(opts.canonContains)) {
+		add("canon", "skip", "opts.canon and opts.canonContains not given.");
+	} else if (!passed("pay_parsed")) {
+		skipAfter("canon", "pay_parsed");
+	} else {
+		try {
+			checkCanon(pay, opts);
+			add("canon", "pass");
+		} catch (e) {
+			add("canon", "fail", e.message);
+		}
+	}
+
+	// iat_window
+	if (opts.maxAge === undefined && opts.notBefore === undefined && opts.notAfter === undefined) {
+		add("iat_window", "skip", "Time options not given.");
+	} else if (!passed("pay_parsed")) {
+		skipAfter("iat_window", "pay_parsed");
+	} else {
+		try {
+			checkTime(pay, opts);
+			add("iat_window", "pass");
+		} catch (e) {
+			add("iat_window", "fail", e.message);
+		}
+	}
+
+	report.verified = passed("signature") && report.checks.every(c => c.status !== "fail");
+	return report;
+}
 
 /**
 VerifyPay verifies a `pay` with `sig` and returns whether or not the message is
 verified. Verify does no Coze checks.  If checks are needed, use
 Verify(); 
-@param  {Pay}       pay        pay. e.g. `{"alg"...}`  May also be any message.  
+@param  {Pay|string} pay       pay. e.g. `{"alg"...}`  May also be any message.  
 @param  {Key}       cozekey    Coze key for validation.
 @param  {Sig}       sig        Signature.
 @return {boolean}
@@ -161,199 +919,509 @@ async function VerifyPay(pay, cozekey, sig) {
 
 
 
+/**
+SignDig signs a digest with a private Coze key and returns the b64ut sig. Unlike
+SignPay, the digest is not hashed again, which is useful when pay only contains
+the digest of a large message, like a file.  The digest is signed using
+Javascript ECDSA since SubtleCrypto always hashes before signing.  The
+signature is low-S.  Only ECDSA algs are supported.
+@param   {Alg}              alg       Must match cozeKey.alg.
+@param   {Key}              cozeKey   Private Coze key.
+@param   {Dig|Uint8Array}   dig       b64ut, hex, or bytes digest.
+@returns {Sig}
+@throws  {error}                      Fails on alg mismatch or incorrect digest size.
+ */
+async function SignDig(alg, cozeKey, dig) {
+	if (CZK.IsRevoked(cozeKey)) {
+		throw new CozeKeyError("SignDig: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
+	}
+	let digest = digToUint8Array("SignDig", alg, cozeKey, dig);
+	let sig = await ECDSA.SignDigest(await ECDSA.FromCozeKey(cozeKey), digest);
+	return CTK.SigToLowS(alg, ArrayBufferTo64ut(sig));
+}
+
+/**
+VerifyDig verifies a sig over an already computed digest without re-hashing.
+Like VerifyPay, both low-S and high-S signatures are valid.  Only ECDSA algs
+are supported.
+@param   {Alg}              alg       Must match cozeKey.alg.
+@param   {Key}              cozeKey   Public Coze key.
+@param   {Dig|Uint8Array}   dig       b64ut, hex, or bytes digest.
+@param   {Sig}              sig
+@returns {boolean}
+@throws  {error}                      Fails on alg mismatch or incorrect digest size.
+ */
+async function VerifyDig(alg, cozeKey, dig, sig) {
+	let digest = digToUint8Array("VerifyDig", alg, cozeKey, dig);
+	let sigAB = B64uToArrayBuffer(sig);
+	if (sigAB.byteLength !== Enum.SigSize(alg)) {
+		return false;
+	}
+	return ECDSA.VerifyDigest(await ECDSA.FromCozeKey(cozeKey, true), digest, sigAB);
+}
+
+/**
+digToUint8Array checks alg and decodes the digest, checking its size.  String
+digests are hex if they have the length of hex for alg's hash size (with or
+without "0x"), and b64ut otherwise.  The lengths of hex and b64ut of the same
+size never match, so this is unambiguous.
+@param   {string}           fn       Name of the calling function for errors.
+@param   {Alg}              alg
+@param   {Key}              cozeKey
+@param   {Dig|Uint8Array}   dig
+@returns {Uint8Array}
+@throws  {error}
+ */
+function digToUint8Array(fn, alg, cozeKey, dig) {
+	if (alg !== cozeKey.alg) {
+		throw new CozeAlgError(`${fn}: alg (${alg}) mismatch with cozeKey.alg (${cozeKey.alg}).`, ErrCodes.AlgMismatch, {
+			alg: alg
+		});
+	}
+	if (Enum.Genus(alg) !== Enum.GenAlgs.ECDSA) {
+		throw new CozeAlgError(`${fn}: only ECDSA algs are supported.`, ErrCodes.AlgUnsupported, {
+			alg: alg
+		});
+	}
+	if (!(dig instanceof Uint8Array)) {
+		if (dig.replace(/^0x/i, '').length === Enum.HashSize(alg) * 2) {
+			dig = HexToUint8Array(dig);
+		} else {
+			dig = B64ToUint8Array(dig);
+		}
+	}
+	if (dig.length !== Enum.HashSize(alg)) {
+		throw new CozeError(`${fn}: incorrect digest size for ${alg}: ${dig.length} bytes, expected ${Enum.HashSize(alg)}.`, ErrCodes.DigSize, {
+			alg: alg
+		});
+	}
+	return dig;
+}
+
+
 /**
 Meta calculates a Meta object with the fields [alg,iat,tmb,typ,can,cad,sig,czd]
-derived from the given coze. Meta always calculates `can`, `cad`, if populated
-from pay [alg,iat,tmb,typ] are copied, and calculates `czd` if `sig` is set. Pay
-must be set even if it is an empty object. Either Coze.Pay.Alg or parameter alg
-must be set. If Coze.Sig is populated, `czd` is set. The empty coze (A coze with
-an empty pay but sig is set) is legitimate input for Meta.  
+derived from the given coze. Meta calculates every field it can and omits the
+fields it cannot.  Meta always calculates `can`, if populated from pay
+[alg,iat,tmb,typ] are copied, and if alg is known calculates `cad` and, if
+`sig` is set, `czd`.  For multi-signature cozies (see SignAdd), if alg is
+known `sigs` is calculated with a czd for each `{tmb, sig}` entry.  Pay must
+be set even if it is an empty object.  The empty coze (A coze with an empty pay
+but sig is set) is legitimate input for Meta.
+
+The optional second parameter may be an alg or a Coze key.  If coze.pay.alg is
+not set, alg is taken from the parameter.  If coze.pay.tmb is not set, tmb is
+taken from the key.  A key is not needed for Meta, which is useful for
+inspecting cozies long after the signing key is gone.
 
 Errors when
-1. Pay doesn't exist. 
-2. No alg is given (both coze.pay.alg and alg are empty).
-3. Pay.Alg doesn't match parameter alg if both are set.
+1. Pay doesn't exist (ERR_PAY_MISSING).
+2. Pay.Alg doesn't match the alg from the parameter if both are set ("alg
+   mismatch", ERR_ALG_MISMATCH).
+
+coze may be a JSON string, which is parsed with ParseStrict.
 
 Meta does no cryptographic verification.
-@param  {Coze}      coze     coze.
-@param  {Alg}       [alg]    coze.pay.alg takes precedence.
+@param  {Coze|string} coze   coze.
+@param  {Alg|Key}   [key]    Alg or Coze key.  Used for fields missing in pay.
 @return {Meta}               Meta object [alg,iat,tmb,typ,can,cad,sig,czd].
-@throws {error}              
+@throws {error}
  */
-async function Meta(coze, alg) {
+async function Meta(coze, key) {
+	coze = fromJSON(coze);
 	if (isEmpty(coze.pay)) {
-		throw new Error("Meta: coze.pay must exist.")
+		throw new CozeVerifyError("Meta: coze.pay must exist.", ErrCodes.PayMissing, {
+			field: "pay"
+		});
 	}
 	let meta = {}
 
-	// Alg check section. Assumes later call to CanonicalHas64() errors on bad or empty alg.  
-	if (isEmpty(alg)) {
-		if (isEmpty(coze.pay.alg)) {
-			throw new Error("Meta: either coze.pay.alg or parameter alg must be set.")
+	let alg = key;
+	let tmb = "";
+	if (!isEmpty(key) && typeof key === "object") {
+		alg = key.alg;
+		tmb = key.tmb;
+	}
+
+	// Alg check section. Assumes later call to CanonicalHas64() errors on bad alg.
+	if (!isEmpty(coze.pay.alg)) {
+		if (!isEmpty(alg) && alg !== coze.pay.alg) {
+			throw new CozeAlgError(`Meta: alg mismatch: coze.pay.alg (${coze.pay.alg}) and parameter alg (${alg}) do not match.`, ErrCodes.AlgMismatch, {
+				alg: alg
+			});
 		}
 		meta.alg = coze.pay.alg
-	} else {
+	} else if (!isEmpty(alg)) {
 		meta.alg = alg
 	}
-	if (!isEmpty(coze.pay.alg) && meta.alg !== coze.pay.alg) {
-		throw new Error(`Meta: coze.pay.alg (${coze.pay.alg}) and parameter alg (${alg}) do not match. `)
-	}
 
 	if (!isEmpty(coze.pay.iat)) {
 		meta.iat = coze.pay.iat
 	}
 	if (!isEmpty(coze.pay.tmb)) {
 		meta.tmb = coze.pay.tmb
+	} else if (!isEmpty(tmb)) {
+		meta.tmb = tmb
 	}
 	if (!isEmpty(coze.pay.typ)) {
 		meta.typ = coze.pay.typ
 	}
 
 	meta.can = await Can.Canon(coze.pay)
-	meta.cad = await Can.CanonicalHash64(coze.pay, Enum.HashAlg(meta.alg));
+	// Digests require alg.
+	if (!isEmpty(meta.alg)) {
+		meta.cad = await Can.CanonicalHash64(coze.pay, Enum.HashAlg(meta.alg));
+	}
 	if (!isEmpty(coze.sig)) {
 		meta.sig = coze.sig
+	}
+	if (!isEmpty(meta.alg) && !isEmpty(coze.sig)) {
 		meta.czd = await Can.CanonicalHash64({
 			cad: meta.cad,
 			sig: meta.sig
 		}, Enum.HashAlg(meta.alg));
 	}
+	// Multi-signature cozies have a czd per signer.
+	if (!isEmpty(meta.alg) && Array.isArray(coze.sigs)) {
+		meta.sigs = [];
+		for (const s of coze.sigs) {
+			meta.sigs.push({
+				tmb: s.tmb,
+				sig: s.sig,
+				czd: await Can.CanonicalHash64({
+					cad: meta.cad,
+					sig: s.sig
+				}, Enum.HashAlg(meta.alg)),
+			});
+		}
+	}
 
 	return meta;
 }
 
+/**
+ScrubCoze returns a copy of coze with the embedded `key`, if any, replaced by
+its public key (See PublicKey), so that a coze with an accidentally embedded
+private key may be serialized for transport.  Encapsulated cozies
+(`{"coze":{...}}`) are scrubbed as well.  pay and sig are unchanged.  coze is
+not modified and may be a JSON string.
+@param  {Coze|string}  coze
+@return {Coze}
+@throws {error}        Fails on invalid JSON.
+ */
+function ScrubCoze(coze) {
+	coze = fromJSON(coze);
+	let scrubbed = {
+		...coze
+	};
+	if (!isEmpty(coze.key)) {
+		scrubbed.key = CZK.PublicKey(coze.key);
+	}
+	if (!isEmpty(coze.coze) && typeof coze.coze === "object") {
+		scrubbed.coze = ScrubCoze(coze.coze);
+	}
+	return scrubbed;
+}
 
-///////////////////////////////////
-// Base Conversion
-///////////////////////////////////
 
 /**
-Converts a string (UTF-8) to an ArrayBuffer.
-@param  {string}        string
-@return {ArrayBuffer}
+Attach returns the coze of a detached pay and sig (See Detach).  A pay string
+must be the compact serialization of pay (JSON.stringify), the form of pay in
+a coze, otherwise the sig is not of the coze's pay and ERR_JSON_INVALID is
+thrown.  Use `Verify(pay, cozeKey, sig)` for a pay signed in another form.
+@param  {Pay|string}  pay
+@param  {Sig}         sig
+@return {Coze}
+@throws {error}       Fails on invalid, duplicate field, or non-compact JSON.
  */
-async function SToArrayBuffer(string) {
-	return new TextEncoder().encode(string).buffer; // Suppose to be always in UTF-8
+function Attach(pay, sig) {
+	if (typeof pay === "string") {
+		let s = pay;
+		pay = ParseStrict(s);
+		if (JSON.stringify(pay) !== s) {
+			throw new CozeError("Attach: pay is not the compact serialization of pay, so sig is not of the coze's pay.  Verify with Verify(pay, cozeKey, sig) instead.", ErrCodes.JSONInvalid, {
+				field: "pay"
+			});
+		}
+	}
+	return {
+		pay: pay,
+		sig: sig
+	};
 }
 
 /**
-B64uToArrayBuffer takes a b64 (truncated or not truncated, padded or not
-padded) UTF-8 string and decodes it to an ArrayBuffer.
-@param   {B64}          string 
-@returns {ArrayBuffer}
+Detach returns the pay and sig of coze for transports that send them
+separately.  payCompact is the exact serialization of pay that sig signs and
+must be sent byte for byte.  See Attach and Verify.
+@param  {Coze|string}  coze
+@return {{payCompact: string, sig: Sig}}
+@throws {error}        Fails on invalid JSON or missing pay.
  */
-function B64uToArrayBuffer(string) {
-	return B64ToUint8Array(string).buffer;
-};
+function Detach(coze) {
+	coze = fromJSON(coze);
+	if (isEmpty(coze.pay)) {
+		throw new CozeVerifyError("Detach: coze.pay must exist.", ErrCodes.PayMissing, {
+			field: "pay"
+		});
+	}
+	return {
+		payCompact: JSON.stringify(coze.pay),
+		sig: coze.sig
+	};
+}
+
 
 /**
-B64ToUint8Array takes a b64 string (truncated or not truncated, padded or not
-padded) and decodes it back into a string.
-@param   {B64}          string 
-@returns {Uint8Array}
- */
-function B64ToUint8Array(string) {
-	// Make sure that the encoding is canonical.  See issue "Enforce Canonical
-	// Base64 encoding" https://github.com/Cyphrme/Coze/issues/18. Alternatively
-	// to this method, we could write our own encoder as Mozilla suggests.
-	// https://developer.mozilla.org/en-US/docs/Glossary/Base64#solution_1_%E2%80%93_escaping_the_string_before_encoding_it
-	string = string.replace(/-/g, '+').replace(/_/g, '/')
+Equal returns whether cozeA and cozeB are the same signed object regardless
+of formatting, i.e. whitespace and the order of the coze's fields.  If both
+have sig, their czds must be equal, otherwise their cads must be equal and
+neither may have sig.  If pay.alg is not set, and so digests cannot be
+calculated, the canonical pay and sig are compared instead.
 
-	let reencode = btoa(atob(string)).replace(/=/g, '')
-	if (reencode !== string) {
-		throw new Error('Non-canonical base64 string');
+Note that the order of pay's fields is significant in Coze and is part of cad,
+so a pay with reordered fields is a different pay.  Equal does no
+cryptographic verification.  cozeA and cozeB may be JSON strings, which are
+parsed with ParseStrict.  A coze without pay throws ERR_PAY_MISSING, as for
+Meta.
+@param  {Coze|string}  cozeA
+@param  {Coze|string}  cozeB
+@return {boolean}
+@throws {error}        Fails on malformed cozies.
+ */
+async function Equal(cozeA, cozeB) {
+	cozeA = fromJSON(cozeA);
+	cozeB = fromJSON(cozeB);
+	if (isEmpty(cozeA) || isEmpty(cozeA.pay) || isEmpty(cozeB) || isEmpty(cozeB.pay)) {
+		throw new CozeVerifyError("Equal: coze.pay must exist.", ErrCodes.PayMissing, {
+			field: "pay"
+		});
 	}
-
-	// atob doesn't care about the padding character '=', but does not like URI
-	// encoding.  
-	return Uint8Array.from(atob(string), c => c.charCodeAt(0));
-};
+	if (isEmpty(cozeA.sig) !== isEmpty(cozeB.sig)) {
+		return false;
+	}
+	if (isEmpty(cozeA.pay.alg) || cozeA.pay.alg !== cozeB.pay.alg) {
+		return cozeA.sig === cozeB.sig && await Can.CanonicalS(cozeA.pay) === await Can.CanonicalS(cozeB.pay);
+	}
+	let a = await Meta(cozeA);
+	let b = await Meta(cozeB);
+	if (!isEmpty(cozeA.sig)) {
+		return a.czd === b.czd;
+	}
+	return a.cad === b.cad;
+}
 
 /**
-ArrayBufferTo64ut returns a b64 string from an Array buffer.
-@param   {ArrayBuffer} buffer  Arbitrary bytes. UTF-16 is Javascript native.
-@returns {B64}
+EqualStrict is like Equal, but the cozies must also have the same set of
+fields, e.g. both or neither have `key`, and if both have `key` the keys must
+have the same thumbprint.
+@param  {Coze|string}  cozeA
+@param  {Coze|string}  cozeB
+@return {boolean}
+@throws {error}        Fails on malformed cozies.
  */
-function ArrayBufferTo64ut(buffer) {
-	return btoa(String.fromCharCode.apply(null, new Uint8Array(buffer))).replace(/\+/g, '-').replace(/\//g, '_').replace(/=/g, '');
+async function EqualStrict(cozeA, cozeB) {
+	cozeA = fromJSON(cozeA);
+	cozeB = fromJSON(cozeB);
+	if (Object.keys(cozeA).sort().join() !== Object.keys(cozeB).sort().join()) {
+		return false;
+	}
+	if (!isEmpty(cozeA.key) && await CZK.Thumbprint(cozeA.key) !== await CZK.Thumbprint(cozeB.key)) {
+		return false;
+	}
+	return Equal(cozeA, cozeB);
 }
 
 
 ///////////////////////////////////
-// Helpers - Taken from Cyphr.me
+// Strict JSON
 ///////////////////////////////////
 
 /**
-isEmpty is a helper function to determine if thing is empty. 
-
-Functions are considered always not empty. 
-
-Arrays: Only if an array has no elements it is empty.  isEmpty does not check
-element contents.  (For item contents, do: `isEmpty(array[0])`)
-
-Objects are empty if they have no keys. (Returns len === 0 of object keys.)
+ParseStrict parses JSON like JSON.parse, but throws on duplicate field names
+at any nesting level.  JSON.parse silently keeps the last occurrence of a
+duplicate field, which may differ from the field the signer intended.
+@param   {string}  json
+@returns {any}
+@throws  {error}   SyntaxError on invalid JSON, Error on duplicate fields.
+ */
+function ParseStrict(json) {
+	let parsed = JSON.parse(json); // Validates JSON syntax.
+	CheckDuplicates(json);
+	return parsed;
+}
 
-NaN returns true.  (NaN === NaN is always false, as NaN is never equal to
-anything. NaN is the only JavaScript value unequal to itself.)
+/**
+CheckDuplicates scans valid JSON text and throws `Coze: duplicate JSON field
+"<name>"` on the first duplicate member name of any object at any nesting
+level.  Escaped names are decoded before comparison, so `"msg"` and
+`"\u006dsg"` are duplicates.  Input must be valid JSON (see ParseStrict).
+@param   {string}  json
+@returns {void}
+@throws  {error}
+ */
+function CheckDuplicates(json) {
+	// Stack of containers.  Objects are {names, expectName}, arrays are null.
+	let stack = [];
+	for (let i = 0; i < json.length; i++) {
+		let top = stack[stack.length - 1];
+		switch (json[i]) {
+			case '{':
+				stack.push({
+					names: new Set(),
+					expectName: true
+				});
+				break;
+			case '[':
+				stack.push(null);
+				break;
+			case '}':
+			case ']':
+				stack.pop();
+				break;
+			case ',':
+				if (top) {
+					top.expectName = true;
+				}
+				break;
+			case '"': {
+				let start = i;
+				for (i++; json[i] !== '"'; i++) {
+					if (json[i] === '\\') {
+						i++; // Skip escaped character.
+					}
+				}
+				if (top && top.expectName) {
+					let name = JSON.parse(json.slice(start, i + 1));
+					if (top.names.has(name)) {
+						throw new CozeError(`Coze: duplicate JSON field "${name}"`, ErrCodes.DuplicateField, {
+							field: name
+						});
+					}
+					top.names.add(name);
+					top.expectName = false;
+				}
+				break;
+			}
+		}
+	}
+}
 
-Don't use on HTMl elements. For HTML elements, use the !== equality check
-(element !== null). TODO fix this
+/**
+InputTypes are the types of input reported by Detect.
 
-Cannot use CryptoKey with this function since (len === 0) always. 
-@param   {any}     thing    Thing you wish was empty.  
-@returns {boolean}          Boolean.  
+- coze:     Coze with `pay` and `sig`, or an encapsulated coze (`{"coze":...}`).
+- key:      Coze key, with `alg` and `x` or `d`, and without `pay`.
+- pay:      Any other object, including a coze without `sig`.
+- array:    Array, e.g. of cozies.
+- unknown:  Not an object or array, or an object with `sig` but without `pay`.
 */
-function isEmpty(thing) {
-	if (typeof thing === 'function') {
-		return false
-	}
+const InputTypes = {
+	Coze: "coze",
+	Key: "key",
+	Pay: "pay",
+	Array: "array",
+	Unknown: "unknown",
+};
 
-	if (Array.isArray(thing)) {
-		if (thing.length == 0) {
-			return true
+/**
+Detect reports the InputTypes type of input, so that UIs may act on pasted
+input without the user stating what it is.  input may be a parsed value or
+JSON.  JSON is parsed with ParseStrict, and on invalid JSON Detect throws a
+CozeError with code ERR_JSON_INVALID and `position`, naming the problem
+character, e.g. a byte order mark (BOM), a trailing comma, or non JSON
+whitespace like a non-breaking space.
+@param   {any}     input   Value or JSON.
+@returns {string}          InputTypes type.
+@throws  {error}           Fails on invalid JSON or duplicate fields.
+ */
+function Detect(input) {
+	if (typeof input === "string") {
+		try {
+			input = ParseStrict(input);
+		} catch (e) {
+			if (e instanceof SyntaxError) {
+				throw jsonError(input, e);
+			}
+			throw e;
 		}
 	}
+	if (Array.isArray(input)) {
+		return InputTypes.Array;
+	}
+	if (typeof input !== "object" || input === null) {
+		return InputTypes.Unknown;
+	}
+	let isObject = (v) => typeof v === "object" && v !== null && !Array.isArray(v);
+	if (isObject(input.coze) && isObject(input.coze.pay) && typeof input.coze.sig === "string") {
+		return InputTypes.Coze;
+	}
+	if (isObject(input.pay)) {
+		return typeof input.sig === "string" ? InputTypes.Coze : InputTypes.Pay;
+	}
+	if (input.sig !== undefined) {
+		return InputTypes.Unknown;
+	}
+	if (typeof input.alg === "string" && (typeof input.x === "string" || typeof input.d === "string")) {
+		return InputTypes.Key;
+	}
+	return InputTypes.Pay;
+}
 
-	if (thing === Object(thing)) {
-		if (Object.keys(thing).length === 0) {
-			return true
+/**
+jsonError returns a CozeError naming the position of the problem in invalid
+json.  e is the error from JSON.parse.
+@param   {string}       json
+@param   {SyntaxError}  e
+@returns {CozeError}
+ */
+function jsonError(json, e) {
+	let err = (msg, position) => new CozeError(`Detect: ${msg} at position ${position}.`, ErrCodes.JSONInvalid, {
+		position: position
+	});
+	if (json.charCodeAt(0) === 0xFEFF) {
+		return err("byte order mark (BOM)", 0);
+	}
+	let inString = false;
+	for (let i = 0; i < json.length; i++) {
+		let c = json[i];
+		if (inString) {
+			if (c === '\\') {
+				i++;
+			} else if (c === '"') {
+				inString = false;
+			}
+			continue;
+		}
+		if (c === '"') {
+			inString = true;
+		} else if (c === ',') {
+			let next = json.slice(i + 1).search(/[^ \t\n\r]/);
+			if (next !== -1 && (json[i + 1 + next] === '}' || json[i + 1 + next] === ']')) {
+				return err("trailing comma", i);
+			}
+		} else if (/[\s\u200B-\u200D\u2060]/.test(c) && !/[ \t\n\r]/.test(c)) {
+			let code = c.charCodeAt(0).toString(16).toUpperCase().padStart(4, '0');
+			return err(`non JSON whitespace (U+${code})`, i);
 		}
-		return false
 	}
-
-	if (!isBool(thing)) {
-		return true
+	let m = /position (\d+)/.exec(e.message);
+	if (m !== null) {
+		return err("invalid JSON", Number(m[1]));
 	}
-	return false
+	return new CozeError("Detect: invalid JSON: " + e.message, ErrCodes.JSONInvalid);
 }
 
-
 /**
-isBool is a helper function to determine boolean.  
-
-Javascript, instead of considering everything false except a few key words,
-decided everything is true instead of a few key words.  Why?  Because
-Javascript.  This function inverts that assumption, so that everything can be
-considered false unless true. 
-@param   {any}      bool   Thing that you wish was a boolean.  
-@returns {boolean}         An actual boolean.
-*/
-function isBool(bool) {
-	if (
-		bool === false ||
-		bool === "false" ||
-		bool === undefined ||
-		bool === "undefined" ||
-		bool === "" ||
-		bool === 0 ||
-		bool === "0" ||
-		bool === null ||
-		bool === "null" ||
-		bool === "NaN" ||
-		Number.isNaN(bool) ||
-		bool === Object(bool) // isObject
-	) {
-		return false
-	}
-	return true
-}
\ No newline at end of file
+fromJSON returns thing parsed with ParseStrict if thing is a string, otherwise
+thing is returned unmodified.
+@param   {any}  thing
+@returns {any}
+@throws  {error}
+ */
+function fromJSON(thing) {
+	if (typeof thing === "string") {
+		return ParseStrict(thing);
+	}
+	return thing;
+}
@@ -1,2 +1,6 @@
-var t={UnknownAlg:"UnknownAlg",ES224:"ES224",ES256:"ES256",ES384:"ES384",ES512:"ES512",Ed25519:"Ed25519",Ed25519ph:"Ed25519ph",Ed448:"Ed448",SHA224:"SHA-224",SHA256:"SHA-256",SHA384:"SHA-384",SHA512:"SHA-512",SHA3224:"SHA3-224",SHA3256:"SHA3-256",SHA3384:"SHA3-384",SHA3512:"SHA3-512",SHAKE128:"SHAKE128",SHAKE256:"SHAKE256"},d={EC:"EC",SHA:"SHA",RSA:"RSA"},o={ECDSA:"ECDSA",EdDSA:"EdDSA",SHA2:"SHA2",SHA3:"SHA3"},u={P224:"P-224",P256:"P-256",P384:"P-384",P521:"P-521",Curve25519:"Curve25519",Curve448:"Curve448"},H={Sig:"sig",Enc:"enc",Hsh:"hsh"};function P(r){let e={};e.Name=r,e.Genus=y(r),e.Family=X(r),e.Use=J(r),e.Hash=l(r),e.HashSize=Z(r),e.HashSizeB64=Math.ceil(4*e.HashSize/3);try{e.XSize=T(r),e.XSizeB64=Math.ceil(4*e.XSize/3),e.DSize=_(r),e.DSizeB64=Math.ceil(4*e.DSize/3),e.Curve=B(r),e.SigSize=E(r),e.SigSizeB64=Math.ceil(4*e.SigSize/3)}catch{}return e}function y(r){switch(r){case t.ES224:case t.ES256:case t.ES384:case t.ES512:return o.ECDSA;case t.Ed25519:case t.Ed25519ph:case t.Ed448:return o.EdDSA;case t.SHA224:case t.SHA256:case t.SHA384:case t.SHA512:return o.SHA2;case t.SHA3224:case t.SHA3256:case t.SHA3384:case t.SHA3512:case t.SHAKE128:case t.SHAKE256:return o.SHA3;default:throw new Error("alg.Genus: unsupported algorithm: "+r)}}function X(r){switch(r){case t.ES224:case t.ES256:case t.ES384:case t.ES512:case t.Ed25519:case t.Ed25519ph:case t.Ed448:return d.EC;case t.SHA224:case t.SHA256:case t.SHA384:case t.SHA512:case t.SHA3224:case t.SHA3256:case t.SHA3384:case t.SHA3512:case t.SHAKE128:case t.SHAKE256:return d.SHA;default:throw new Error("alg.Family:  unsupported algorithm: "+r)}}function l(r){switch(r){case t.ES224:case t.SHA224:return t.SHA224;case t.SHA256:case t.ES256:return t.SHA256;case t.SHA384:case t.ES384:return t.SHA384;case t.SHA512:case t.ES512:case t.Ed25519:case t.Ed25519ph:return t.SHA512;case t.SHAKE128:return t.SHAKE128;case t.SHAKE256:case t.Ed448:return t.SHAKE256;case t.SHA3224:return t.SHA3224;case t.SHA3256:return t.SHA3256;case t.SHA3384:return t.SHA3384;case t.SHA3512:return t.SHA3512;default:throw new Error("alg.HashAlg:  unsupported algorithm: "+r)}}function Z(r){switch(l(r)){case t.SHA224:case t.SHA3224:return 28;case t.SHA256:case t.SHA3256:case t.SHAKE128:return 32;case t.SHA384:case t.SHA3384:return 48;case t.SHA512:case t.SHA3512:case t.SHAKE256:return 64;default:throw new Error("alg.HashSize: unsupported algorithm: "+r)}}function E(r){switch(r){case t.ES224:return 56;case t.ES256:case t.Ed25519:case t.Ed25519ph:return 64;case t.ES384:return 96;case t.Ed448:return 114;case t.ES512:return 132;default:throw new Error("alg.SigSize: unsupported algorithm: "+r)}}function T(r){switch(r){case t.Ed25519:case t.Ed25519ph:return 32;case t.ES224:return 56;case t.Ed448:return 57;case t.ES256:return 64;case t.ES384:return 96;case t.ES512:return 132;default:throw new Error("alg.XSize: unsupported algorithm: "+r)}}function _(r){switch(r){case t.ES224:return 28;case t.ES256:case t.Ed25519:case t.Ed25519ph:return 32;case t.ES384:return 48;case t.Ed448:return 57;case t.ES512:return 66;default:throw new Error("alg.DSize: unsupported algorithm: "+r)}}function B(r){switch(r){default:throw new Error("alg.Curve: unsupported algorithm: "+r);case t.ES224:return u.P224;case t.ES256:return u.P256;case t.ES384:return u.P384;case t.ES512:return u.P521;case t.Ed25519:case t.Ed25519ph:return u.Curve25519;case t.Ed448:return u.Curve448}}function J(r){switch(y(r)){default:throw new Error("alg.Use: unsupported algorithm: "+r);case o.EdDSA:case o.ECDSA:return H.Sig;case o.SHA2:case o.SHA3:return H.Hsh}}var w={ES224:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFF16A2E0B8F03E13DD29455C5C2A3D"),ES256:BigInt("0xFFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551"),ES384:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFC7634D81F4372DDF581A0DB248B0A77AECEC196ACCC52973"),ES512:BigInt("0x1FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFA51868783BF2F966B7FCC0148F709A5D03BB5C9B8899C47AEBB6FB71E91386409")},W={ES224:w.ES224>>BigInt(1),ES256:w.ES256>>BigInt(1),ES384:w.ES384>>BigInt(1),ES512:w.ES512>>BigInt(1)};function I(r){switch(r){default:throw new Error("CurveOrder: unsupported curve: "+r);case"ES224":case"ES256":case"ES384":case"ES512":return w[r]}}function U(r){switch(r){default:throw new Error("CurveHalfOrder: unsupported curve: "+r);case"ES224":case"ES256":case"ES384":case"ES512":return W[r]}}var s={New:async function(r){switch(i(r)&&(r=t.ES256),r){case t.ES256:case t.ES384:case t.ES512:return await window.crypto.subtle.generateKey({name:o.ECDSA,namedCurve:B(r)},!0,["sign","verify"]);default:throw new Error("CryptoKey.New: Unsupported key algorithm:"+r)}},FromCozeKey:async function(r,e){if(y(r.alg)!=o.ECDSA)throw new Error("CryptoKey.FromCozeKey: unsupported CryptoKey algorithm: "+r.alg);var a={};a.use=H.Sig,a.crv=B(r.alg),a.kty=d.EC;let n=T(r.alg)/2,f=await C(r.x);if(a.x=await c(f.slice(0,n)),a.y=await c(f.slice(n)),i(r.d)||e)var S="verify";else S="sign",a.d=r.d;return await crypto.subtle.importKey("jwk",a,{name:o.ECDSA,namedCurve:a.crv},!0,[S])},ToPublic:async function(r){delete r.d,r.key_ops=["verify"]},ToCozeKey:async function(r){let e=await window.crypto.subtle.exportKey("jwk",r);var a={};a.alg=await s.algFromCrv(e.crv);let n=C(e.x),f=C(e.y);var S=new Uint8Array([...n,...f]);return a.x=c(S.buffer),e.hasOwnProperty("d")&&(a.d=e.d),a.tmb=await F(a),a},SignBuffer:async function(r,e){let a=await s.algFromCrv(r.algorithm.namedCurve),n=await window.crypto.subtle.sign({name:o.ECDSA,hash:{name:l(a)}},r,e);return n=N(a,n),n},SignBufferB64:async function(r,e){return await c(await s.SignBuffer(r,e))},SignString:async function(r,e){return await s.SignBufferB64(r,await A(e))},VerifyArrayBuffer:async function(r,e,a,n){return await Y(r,n)?(await s.ToPublic(e),await window.crypto.subtle.verify({name:o.ECDSA,hash:{name:await s.GetSignHashAlgoFromCryptoKey(e)}},e,n,a)):!1},VerifyMsg:async function(r,e,a,n){return s.VerifyArrayBuffer(r,e,await A(a),await D(n))},GetSignHashAlgoFromCryptoKey:async function(r){return l(await s.algFromCrv(r.algorithm.namedCurve))},algFromCrv:async function(r){switch(r){case u.P224:var e=t.ES224;break;case u.P256:e=t.ES256;break;case u.P384:e=t.ES384;break;case u.P521:e=t.ES512;break;default:throw new Error("CryptoKey.ToCozeKey: Unsupported key algorithm.")}return e}};function z(r,e){if(typeof e!="bigint")throw new Error("IsLowS: s is not of type bigint");return U(r)>e}function Q(r,e){if(typeof e!="bigint")throw new Error("toLowS: s is not of type bigint");return z(r,e)?e:I(r)-e}async function sr(r,e){let a=await D(e),n=await N(r,a);return c(n)}async function Y(r,e){let a=await $(r,e);return z(r,a)}function $(r,e){let a=E(r)/2,n=e.slice(a);return O(n)}async function N(r,e){let a=E(r)/2,n=e.slice(0,a),f=e.slice(a),S=O(f),m=Q(r,S),h=q(E(r)/2,m);var g=new Uint8Array(n.byteLength+h.byteLength);return g.set(new Uint8Array(n),0),g.set(new Uint8Array(h),n.byteLength),e=g.buffer,e}function O(r){let e=0n,a=new Uint8Array(r);for(let n=0;n<a.length;n++)e=(e<<8n)+BigInt(a[n]);return e}function q(r,e){let a=new ArrayBuffer(r),n=new DataView(a);do r--,n.setUint8(r,Number(e&BigInt(255))),e>>=8n;while(r>0);return a}var rr=["alg","x"];async function ur(r){if(i(r)&&(r=t.ES256),y(r)==o.ECDSA)var e=await s.New(r);else throw new Error("Coze.NewKey: only ECDSA algs are currently supported.");let a=await s.ToCozeKey(e.privateKey);return a.iat=Math.floor(Date.now()/1e3),a.tmb=await F(a),a.kid="My Cyphr.me Key.",a}async function F(r){if(i(r.alg)||i(r.x))throw new Error("Coze.Thumbprint: alg or x is empty.");return p(r,await l(r.alg),rr)}async function fr(r){if(i(r.d))return console.error("Coze key missing `d`"),!1;try{let e="7AtyaCHO2BAG06z0W1tOQlZFWbhxGgqej4k9-HWP3DE-zshRbrE-69DIfgY704_FDYez7h_rEI1WQVKhv5Hd5Q",a=await v(e,r);return k(e,r,a)}catch{return!1}}async function Sr(r){if(typeof r!="object")return console.error("Correct: CozeKey must be passed in as an object."),!1;if(i(r.alg))return console.error("Correct: Alg must be set"),!1;let e=P(r.alg),a=i(r.tmb),n=i(r.x),f=i(r.d);if(a&&n&&f)return console.error("Correct: At least one of [x, tmb, d] must be set"),!1;if(n&&f)return a||r.tmb.length!==e.HashSizeB64?(console.error("Correct: Incorrect `tmb` size: ",r.tmb.length),!1):!0;if(!n&&r.x.length!==e.XSizeB64)return console.error("Correct: Incorrect x size: ",r.x.length),!1;if(!a&&!n){let S=await F(r);if(r.tmb!==S)return console.error("Correct: Incorrect given `tmb`: ",r.tmb),!1}if(!f&&!n){let S=await s.FromCozeKey(r),m=await A("Test Signing"),h=await s.SignBuffer(S,m),g=await s.FromCozeKey(r,!0);if(!await s.VerifyArrayBuffer(r.alg,g,m,h))return console.error("Correct: private key invalid."),!1}return!0}async function lr(r,e){if(i(r))throw new Error("CozeKey.Revoke: Private key not set.  Cannot sign message");var a={};a.pay={},i(e)||(a.pay.msg=e),a.pay.rvk=Math.round(Date.now()/1e3);let n=r.rvk;return delete r.rvk,a=await V(a,r),n!==void 0?r.rvk=n:r.rvk=a.pay.rvk,a}function x(r){return!(i(r.rvk)||!(parseInt(r.rvk)>0))}var cr=["alg","iat","tmb","typ"];async function V(r,e,a){if(console.log(),x(e))throw new Error("SignCoze: Cannot sign with revoked key.");return r.pay.alg=e.alg,r.pay.tmb=await F(e),r.pay.iat=Math.round(Date.now()/1e3),i(a)||(r.pay=await b(r.pay,a)),r.sig=await v(JSON.stringify(r.pay),e),r}async function v(r,e){return s.SignBufferB64(await s.FromCozeKey(e),await A(r))}async function Ar(r,e,a){if(x(e))throw new Error("SignCozeRaw: Cannot sign with revoked key.");if(!i(r.pay.alg)&&r.pay.alg!==e.alg)throw new Error("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.");if(!i(r.pay.tmb)&&r.pay.tmb!==e.tmb)throw new Error("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.");return i(a)||(r.pay=await b(r.pay,a)),r.sig=await v(JSON.stringify(r.pay),e),r}async function yr(r,e){if(!i(r.pay.alg)&&r.pay.alg!==e.alg)throw new Error("VerifyCoze: Coze key alg mismatch with coze.pay.alg.");if(!i(r.pay.tmb)&&r.pay.tmb!==e.tmb)throw new Error("VerifyCoze: Coze key tmb mismatch with coze.pay.tmb.");return k(JSON.stringify(r.pay),e,r.sig)}async function k(r,e,a){return s.VerifyMsg(e.alg,await s.FromCozeKey(e,!0),r,a)}async function Fr(r,e){if(i(r.pay))throw new Error("Meta: coze.pay must exist.");let a={};if(i(e)){if(i(r.pay.alg))throw new Error("Meta: either coze.pay.alg or parameter alg must be set.");a.alg=r.pay.alg}else a.alg=e;if(!i(r.pay.alg)&&a.alg!==r.pay.alg)throw new Error(`Meta: coze.pay.alg (${r.pay.alg}) and parameter alg (${e}) do not match. `);return i(r.pay.iat)||(a.iat=r.pay.iat),i(r.pay.tmb)||(a.tmb=r.pay.tmb),i(r.pay.typ)||(a.typ=r.pay.typ),a.can=await L(r.pay),a.cad=await p(r.pay,l(a.alg)),i(r.sig)||(a.sig=r.sig,a.czd=await p({cad:a.cad,sig:a.sig},l(a.alg))),a}async function A(r){return new TextEncoder().encode(r).buffer}function D(r){return C(r).buffer}function C(r){if(r=r.replace(/-/g,"+").replace(/_/g,"/"),btoa(atob(r)).replace(/=/g,"")!==r)throw new Error("Non-canonical base64 string");return Uint8Array.from(atob(r),a=>a.charCodeAt(0))}function c(r){return btoa(String.fromCharCode.apply(null,new Uint8Array(r))).replace(/\+/g,"-").replace(/\//g,"_").replace(/=/g,"")}function i(r){return typeof r=="function"?!1:Array.isArray(r)&&r.length==0?!0:r===Object(r)?Object.keys(r).length===0:!er(r)}function er(r){return!(r===!1||r==="false"||r===void 0||r==="undefined"||r===""||r===0||r==="0"||r===null||r==="null"||r==="NaN"||Number.isNaN(r)||r===Object(r))}function L(r){return Object.keys(r)}async function b(r,e){if(i(e))return r;let a={};for(let n of e)a[n]=r[n];return a}async function tr(r,e){return JSON.stringify(await b(r,e))}async function ar(r,e,a){if(i(e))throw new Error("Hash is not given");return await crypto.subtle.digest(e,await A(await tr(r,a)))}async function p(r,e,a){return await c(await ar(r,e,a))}export{t as Algs,c as ArrayBufferTo64ut,C as B64ToUint8Array,D as B64uToArrayBuffer,L as Canon,b as Canonical,ar as CanonicalHash,p as CanonicalHash64,tr as CanonicalS,Sr as Correct,s as CryptoKey,B as Curve,U as CurveHalfOrder,I as CurveOrder,u as Curves,_ as DSize,d as FamAlgs,X as Family,o as GenAlgs,y as Genus,l as HashAlg,Z as HashSize,x as IsRevoked,Y as IsSigLowS,Fr as Meta,ur as NewKey,P as Params,cr as PayCanon,lr as Revoke,A as SToArrayBuffer,E as SigSize,sr as SigToLowS,V as Sign,Ar as SignCozeRaw,v as SignPay,F as Thumbprint,rr as TmbCanon,J as Use,H as Uses,fr as Valid,yr as Verify,k as VerifyPay,T as XSize,i as isEmpty};
+var s={AlgUnsupported:"ERR_ALG_UNSUPPORTED",AlgMismatch:"ERR_ALG_MISMATCH",TmbMismatch:"ERR_TMB_MISMATCH",KeyInvalid:"ERR_KEY_INVALID",KeyRevoked:"ERR_KEY_REVOKED",KeyMismatch:"ERR_KEY_MISMATCH",KeyNotFound:"ERR_KEY_NOT_FOUND",SigInvalid:"ERR_SIG_INVALID",CanonInvalid:"ERR_CANON_INVALID",CanonMissing:"ERR_CANON_MISSING",CanonExtra:"ERR_CANON_EXTRA",PayMissing:"ERR_PAY_MISSING",PrvMismatch:"ERR_PRV_MISMATCH",ThresholdInvalid:"ERR_THRESHOLD_INVALID",IatInvalid:"ERR_IAT_INVALID",Expired:"ERR_EXPIRED",NotYetValid:"ERR_NOT_YET_VALID",DigSize:"ERR_DIG_SIZE",HashInvalid:"ERR_HASH_INVALID",DuplicateField:"ERR_DUPLICATE_FIELD",JSONInvalid:"ERR_JSON_INVALID",FieldReserved:"ERR_FIELD_RESERVED",B64Invalid:"ERR_B64_INVALID",HexInvalid:"ERR_HEX_INVALID",QRCapacity:"ERR_QR_CAPACITY",QRInvalid:"ERR_QR_INVALID",KeystoreUnavailable:"ERR_KEYSTORE_UNAVAILABLE",BrowserRequired:"ERR_BROWSER_REQUIRED"},D=class extends Error{constructor(t,n,r){super(t),this.name="CozeError",this.code=n,r!==void 0&&Object.assign(this,r)}},y=class extends D{constructor(t,n,r){super(t,n,r),this.name="CozeKeyError"}},N=class extends D{constructor(t,n,r){super(t,n,r),this.name="CozeVerifyError"}},Q=class extends D{constructor(t,n,r){super(t,n,r),this.name="CozeCanonError"}},x=class extends D{constructor(t,n,r){super(t,n,r),this.name="CozeAlgError"}};async function J(e){return new TextEncoder().encode(e).buffer}var fe=class extends D{constructor(t,n){super(t,s.B64Invalid,{field:n}),this.name="B64Error"}};function we(e,t){return C(e,t).buffer}function C(e,t){let n=u(t)?"":` for field "${t}"`;if(typeof e!="string")throw new fe(`B64ToUint8Array: b64ut must be a string${n}.`,t);if(!/^[A-Za-z0-9_-]*$/.test(e)||e.length%4===1)throw new fe(`B64ToUint8Array: invalid b64ut${n}.`,t);let r=atob(e.replace(/-/g,"+").replace(/_/g,"/")),i=Uint8Array.from(r,a=>a.charCodeAt(0));if(m(i)!==e)throw new fe(`B64ToUint8Array: non-canonical b64ut${n}.`,t);return i}function rn(e){let t=e.replace(/\s/g,"").replace(/=+$/,"").replace(/-/g,"+").replace(/_/g,"/");if(!/^[A-Za-z0-9+/]*$/.test(t)||t.length%4===1)throw new fe("B64Lenient: invalid base64.");return m(Uint8Array.from(atob(t),n=>n.charCodeAt(0)))}function m(e){return btoa(String.fromCharCode.apply(null,new Uint8Array(e))).replace(/\+/g,"-").replace(/\//g,"_").replace(/=/g,"")}function Oe(e){if(e=e.replace(/^0x/i,""),e.length%2!==0)throw new D("HexToUint8Array: hex must have an even number of characters, got "+e.length+".",s.HexInvalid);if(!/^[0-9a-fA-F]*$/.test(e))throw new D("HexToUint8Array: invalid hex character.",s.HexInvalid);let t=new Uint8Array(e.length/2);for(let n=0;n<t.length;n++)t[n]=parseInt(e.substring(n*2,n*2+2),16);return t}function He(e){return Array.from(new Uint8Array(e),t=>t.toString(16).padStart(2,"0")).join("")}function an(e){return m(Oe(e))}function ln(e){return He(C(e))}function u(e){return typeof e=="function"?!1:Array.isArray(e)&&e.length==0?!0:e===Object(e)?Object.keys(e).length===0:!fn(e)}function fn(e){return!(e===!1||e==="false"||e===void 0||e==="undefined"||e===""||e===0||e==="0"||e===null||e==="null"||e==="NaN"||Number.isNaN(e)||e===Object(e))}var l={UnknownAlg:"UnknownAlg",ES224:"ES224",ES256:"ES256",ES384:"ES384",ES512:"ES512",Ed25519:"Ed25519",Ed25519ph:"Ed25519ph",Ed448:"Ed448",SHA224:"SHA-224",SHA256:"SHA-256",SHA384:"SHA-384",SHA512:"SHA-512",SHA3224:"SHA3-224",SHA3256:"SHA3-256",SHA3384:"SHA3-384",SHA3512:"SHA3-512",SHAKE128:"SHAKE128",SHAKE256:"SHAKE256"},Ce={EC:"EC",SHA:"SHA",RSA:"RSA"},S={ECDSA:"ECDSA",EdDSA:"EdDSA",SHA2:"SHA2",SHA3:"SHA3"},V={P224:"P-224",P256:"P-256",P384:"P-384",P521:"P-521",Curve25519:"Curve25519",Curve448:"Curve448"},se={Sig:"sig",Enc:"enc",Hsh:"hsh"};function Le(e){let t={};t.Name=e,t.Genus=_(e),t.Family=sn(e),t.Use=on(e),t.Hash=B(e),t.HashSize=Y(e),t.HashSizeB64=Math.ceil(4*t.HashSize/3);try{t.XSize=G(e),t.XSizeB64=Math.ceil(4*t.XSize/3),t.DSize=$(e),t.DSizeB64=Math.ceil(4*t.DSize/3),t.Curve=oe(e),t.SigSize=R(e),t.SigSizeB64=Math.ceil(4*t.SigSize/3),t.CurveOID=dn(e),t.JOSECrv=gn(e)}catch{}return t.JOSEAlg=cn(e),t.COSEAlg=yn(e),t}function _(e){switch(e){case l.ES224:case l.ES256:case l.ES384:case l.ES512:return S.ECDSA;case l.Ed25519:case l.Ed25519ph:case l.Ed448:return S.EdDSA;case l.SHA224:case l.SHA256:case l.SHA384:case l.SHA512:return S.SHA2;case l.SHA3224:case l.SHA3256:case l.SHA3384:case l.SHA3512:case l.SHAKE128:case l.SHAKE256:return S.SHA3;default:throw new x("alg.Genus: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function sn(e){switch(e){case l.ES224:case l.ES256:case l.ES384:case l.ES512:case l.Ed25519:case l.Ed25519ph:case l.Ed448:return Ce.EC;case l.SHA224:case l.SHA256:case l.SHA384:case l.SHA512:case l.SHA3224:case l.SHA3256:case l.SHA3384:case l.SHA3512:case l.SHAKE128:case l.SHAKE256:return Ce.SHA;default:throw new x("alg.Family:  unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function B(e){switch(e){case l.ES224:case l.SHA224:return l.SHA224;case l.SHA256:case l.ES256:return l.SHA256;case l.SHA384:case l.ES384:return l.SHA384;case l.SHA512:case l.ES512:case l.Ed25519:case l.Ed25519ph:return l.SHA512;case l.SHAKE128:return l.SHAKE128;case l.SHAKE256:case l.Ed448:return l.SHAKE256;case l.SHA3224:return l.SHA3224;case l.SHA3256:return l.SHA3256;case l.SHA3384:return l.SHA3384;case l.SHA3512:return l.SHA3512;default:throw new x("alg.HashAlg:  unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function Y(e){switch(B(e)){case l.SHA224:case l.SHA3224:return 28;case l.SHA256:case l.SHA3256:case l.SHAKE128:return 32;case l.SHA384:case l.SHA3384:return 48;case l.SHA512:case l.SHA3512:case l.SHAKE256:return 64;default:throw new x("alg.HashSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function R(e){switch(e){case l.ES224:return 56;case l.ES256:case l.Ed25519:case l.Ed25519ph:return 64;case l.ES384:return 96;case l.Ed448:return 114;case l.ES512:return 132;default:throw new x("alg.SigSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function G(e){switch(e){case l.Ed25519:case l.Ed25519ph:return 32;case l.ES224:return 56;case l.Ed448:return 57;case l.ES256:return 64;case l.ES384:return 96;case l.ES512:return 132;default:throw new x("alg.XSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function $(e){switch(e){case l.ES224:return 28;case l.ES256:case l.Ed25519:case l.Ed25519ph:return 32;case l.ES384:return 48;case l.Ed448:return 57;case l.ES512:return 66;default:throw new x("alg.DSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function oe(e){switch(e){default:throw new x("alg.Curve: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case l.ES224:return V.P224;case l.ES256:return V.P256;case l.ES384:return V.P384;case l.ES512:return V.P521;case l.Ed25519:case l.Ed25519ph:return V.Curve25519;case l.Ed448:return V.Curve448}}function on(e){switch(_(e)){default:throw new x("alg.Use: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case S.EdDSA:case S.ECDSA:return se.Sig;case S.SHA2:case S.SHA3:return se.Hsh}}var Ee={ES224:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFF16A2E0B8F03E13DD29455C5C2A3D"),ES256:BigInt("0xFFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551"),ES384:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFC7634D81F4372DDF581A0DB248B0A77AECEC196ACCC52973"),ES512:BigInt("0x1FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFA51868783BF2F966B7FCC0148F709A5D03BB5C9B8899C47AEBB6FB71E91386409")},un={ES224:Ee.ES224>>BigInt(1),ES256:Ee.ES256>>BigInt(1),ES384:Ee.ES384>>BigInt(1),ES512:Ee.ES512>>BigInt(1)};function De(e){switch(e){default:throw new x("CurveOrder: unsupported curve: "+e,s.AlgUnsupported,{alg:e});case"ES224":case"ES256":case"ES384":case"ES512":return Ee[e]}}function ft(e){switch(e){default:throw new x("CurveHalfOrder: unsupported curve: "+e,s.AlgUnsupported,{alg:e});case"ES224":case"ES256":case"ES384":case"ES512":return un[e]}}function dn(e){switch(e){default:throw new x("alg.CurveOID: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case l.ES224:return"1.3.132.0.33";case l.ES256:return"1.2.840.10045.3.1.7";case l.ES384:return"1.3.132.0.34";case l.ES512:return"1.3.132.0.35";case l.Ed25519:case l.Ed25519ph:return"1.3.101.112";case l.Ed448:return"1.3.101.113"}}function cn(e){switch(e){case l.ES256:case l.ES384:case l.ES512:return e;case l.Ed25519:case l.Ed448:return"EdDSA"}return _(e),""}function gn(e){switch(e){default:throw new x("alg.JOSECrv: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case l.ES224:return"";case l.ES256:case l.ES384:case l.ES512:return oe(e);case l.Ed25519:case l.Ed25519ph:return"Ed25519";case l.Ed448:return"Ed448"}}var $e={ES256:-7,ES384:-35,ES512:-36,Ed25519:-19,Ed448:-53,"SHA-256":-16,"SHA-384":-43,"SHA-512":-44,SHAKE128:-18,SHAKE256:-45};function yn(e){_(e);let t=$e[e];return t===void 0?0:t}function tr(e,t){switch(e){case"ES256":case"ES384":case"ES512":case"Ed25519":case"Ed448":return e;case"EdDSA":if(t==="Ed25519"||t==="Ed448")return t;throw new x("alg.AlgFromJOSE: EdDSA requires crv Ed25519 or Ed448, got: "+t,s.AlgUnsupported,{alg:e});default:throw new x("alg.AlgFromJOSE: unsupported JOSE alg: "+e,s.AlgUnsupported,{alg:e})}}function nr(e){for(let t in $e)if($e[t]===e)return t;throw new x("alg.AlgFromCOSE: unsupported COSE alg: "+e,s.AlgUnsupported,{alg:e})}async function W(e,t){if(u(e))throw new x("Hash is not given",s.AlgUnsupported);if(e===l.SHA224){let n=ct(!0);return n.update(new Uint8Array(t)),n.digest().buffer}if(gt[e]!==void 0){let n=yt(e);return n.update(new Uint8Array(t)),n.digest().buffer}return crypto.subtle.digest(e,t)}async function _e(e,t,n){let r=e===l.SHA384||e===l.SHA512?128:64;t.length>r&&(t=new Uint8Array(await W(e,t)));let i=new Uint8Array(r+n.length),a=new Uint8Array(r);for(let c=0;c<r;c++){let d=c<t.length?t[c]:0;i[c]=d^54,a[c]=d^92}i.set(n,r);let f=new Uint8Array(await W(e,i)),o=new Uint8Array(r+f.length);return o.set(a),o.set(f,r),new Uint8Array(await W(e,o))}async function An(e,t){let n=B(e),r;if(typeof t=="string")r=await J(t);else if(t instanceof Uint8Array||t instanceof ArrayBuffer)r=t;else if(typeof Blob<"u"&&t instanceof Blob)r=await t.arrayBuffer();else throw new TypeError("Hash: input must be a string, Uint8Array, ArrayBuffer, or Blob.");return m(await W(n,r))}async function hn(e,t,n){let r=B(e),i;switch(r){case l.SHA224:case l.SHA256:i=ct(r===l.SHA224);break;case l.SHA384:case l.SHA512:i=Sn(r===l.SHA384);break;case l.SHA3224:case l.SHA3256:case l.SHA3384:case l.SHA3512:case l.SHAKE128:case l.SHAKE256:i=yt(r);break;default:throw new x("HashStream: unsupported hashing algorithm: "+r,s.AlgUnsupported,{alg:e})}u(n)&&(n={});let a=4*1024*1024;n.chunkSize>0&&(a=n.chunkSize);let f=function(){if(n.signal!==void 0&&n.signal.aborted)throw n.signal.reason},o=0,c=function(d){i.update(d),o+=d.length,typeof n.onProgress=="function"&&n.onProgress(o)};if(typeof Blob<"u"&&t instanceof Blob)for(let d=0;d<t.size;d+=a)f(),c(new Uint8Array(await t.slice(d,d+a).arrayBuffer()));else if(typeof ReadableStream<"u"&&t instanceof ReadableStream){let d=t.getReader();try{for(;;){f();let g=await d.read();if(g.done)break;c(g.value)}}catch(g){throw await d.cancel(g),g}}else throw new TypeError("HashStream: input must be a Blob or ReadableStream.");return f(),m(i.digest())}async function ar(e,t,n,r,i){return e[t]=await An(r,n),u(i)||(u(i.sizeField)||(typeof n=="string"?e[i.sizeField]=(await J(n)).byteLength:typeof Blob<"u"&&n instanceof Blob?e[i.sizeField]=n.size:e[i.sizeField]=n.byteLength),!u(i.nameField)&&!u(n.name)&&(e[i.nameField]=n.name)),e}async function dt(e,t,n){typeof Blob<"u"&&e instanceof Blob&&(e=[e]),e=Array.from(e),u(n)&&(n={});let r=e.reduce((f,o)=>f+o.size,0),i=0,a=[];for(let f of e){let o=await hn(t,f,{chunkSize:n.chunkSize,signal:n.signal,onProgress:function(c){typeof n.onProgress=="function"&&n.onProgress(i+c,r)}});i+=f.size,a.push({name:u(f.name)?"":f.name,size:f.size,dig:o})}return a}async function lr(e,t,n,r){let i=await dt(t,n,r);return e.file=typeof Blob<"u"&&t instanceof Blob?i[0]:i,e}async function fr(e,t,n,r){let i=[];Array.isArray(e.file)?i=e.file:typeof e.file=="object"&&e.file!==null&&(i=[e.file]);let f=(await dt(t,n,r)).map(o=>({...o,match:i.some(c=>c!==null&&c.dig===o.dig&&(c.size===void 0||c.size===o.size))}));return{match:f.length>0&&f.every(o=>o.match),files:f}}var xn=new Uint32Array([1116352408,1899447441,3049323471,3921009573,961987163,1508970993,2453635748,2870763221,3624381080,310598401,607225278,1426881987,1925078388,2162078206,2614888103,3248222580,3835390401,4022224774,264347078,604807628,770255983,1249150122,1555081692,1996064986,2554220882,2821834349,2952996808,3210313671,3336571891,3584528711,113926993,338241895,666307205,773529912,1294757372,1396182291,1695183700,1986661051,2177026350,2456956037,2730485921,2820302411,3259730800,3345764771,3516065817,3600352804,4094571909,275423344,430227734,506948616,659060556,883997877,958139571,1322822218,1537002063,1747873779,1955562222,2024104815,2227730452,2361852424,2428436474,2756734187,3204031479,3329325298]),wn=[3238371032,914150663,812702999,4144912697,4290775857,1750603025,1694076839,3204075428],bn=[1779033703,3144134277,1013904242,2773480762,1359893119,2600822924,528734635,1541459225];function ct(e){let t=new Uint32Array(e?wn:bn),n=new Uint32Array(64),r=new Uint8Array(64),i=0,a=0,f=function(o,c){for(let I=0;I<16;I++)n[I]=o[c+4*I]<<24|o[c+4*I+1]<<16|o[c+4*I+2]<<8|o[c+4*I+3];for(let I=16;I<64;I++){let K=n[I-15],U=n[I-2],q=(K>>>7|K<<25)^(K>>>18|K<<14)^K>>>3,te=(U>>>17|U<<15)^(U>>>19|U<<13)^U>>>10;n[I]=n[I-16]+q+n[I-7]+te|0}let d=t[0],g=t[1],A=t[2],b=t[3],h=t[4],p=t[5],P=t[6],j=t[7];for(let I=0;I<64;I++){let K=(h>>>6|h<<26)^(h>>>11|h<<21)^(h>>>25|h<<7),U=h&p^~h&P,q=j+K+U+xn[I]+n[I]|0,te=(d>>>2|d<<30)^(d>>>13|d<<19)^(d>>>22|d<<10),Ae=d&g^d&A^g&A,he=te+Ae|0;j=P,P=p,p=h,h=b+q|0,b=A,A=g,g=d,d=q+he|0}t[0]+=d,t[1]+=g,t[2]+=A,t[3]+=b,t[4]+=h,t[5]+=p,t[6]+=P,t[7]+=j};return{update:function(o){a+=o.length;let c=0;if(i>0){for(;i<64&&c<o.length;)r[i++]=o[c++];if(i<64)return;f(r,0),i=0}for(;c+64<=o.length;c+=64)f(o,c);for(;c<o.length;)r[i++]=o[c++]},digest:function(){let o=a*8;r[i++]=128,i>56&&(r.fill(0,i),f(r,0),i=0),r.fill(0,i);let c=new DataView(r.buffer);c.setUint32(56,Math.floor(o/4294967296)),c.setUint32(60,o>>>0),f(r,0);let d=new Uint8Array(32),g=new DataView(d.buffer);for(let A=0;A<8;A++)g.setUint32(4*A,t[A]);return e?d.slice(0,28):d}}}var st=new Uint32Array([1116352408,3609767458,1899447441,602891725,3049323471,3964484399,3921009573,2173295548,961987163,4081628472,1508970993,3053834265,2453635748,2937671579,2870763221,3664609560,3624381080,2734883394,310598401,1164996542,607225278,1323610764,1426881987,3590304994,1925078388,4068182383,2162078206,991336113,2614888103,633803317,3248222580,3479774868,3835390401,2666613458,4022224774,944711139,264347078,2341262773,604807628,2007800933,770255983,1495990901,1249150122,1856431235,1555081692,3175218132,1996064986,2198950837,2554220882,3999719339,2821834349,766784016,2952996808,2566594879,3210313671,3203337956,3336571891,1034457026,3584528711,2466948901,113926993,3758326383,338241895,168717936,666307205,1188179964,773529912,1546045734,1294757372,1522805485,1396182291,2643833823,1695183700,2343527390,1986661051,1014477480,2177026350,1206759142,2456956037,344077627,2730485921,1290863460,2820302411,3158454273,3259730800,3505952657,3345764771,106217008,3516065817,3606008344,3600352804,1432725776,4094571909,1467031594,275423344,851169720,430227734,3100823752,506948616,1363258195,659060556,3750685593,883997877,3785050280,958139571,3318307427,1322822218,3812723403,1537002063,2003034995,1747873779,3602036899,1955562222,1575990012,2024104815,1125592928,2227730452,2716904306,2361852424,442776044,2428436474,593698344,2756734187,3733110249,3204031479,2999351573,3329325298,3815920427,3391569614,3928383900,3515267271,566280711,3940187606,3454069534,4118630271,4000239992,116418474,1914138554,174292421,2731055270,289380356,3203993006,460393269,320620315,685471733,587496836,852142971,1086792851,1017036298,365543100,1126000580,2618297676,1288033470,3409855158,1501505948,4234509866,1607167915,987167468,1816402316,1246189591]),mn=[3418070365,3238371032,1654270250,914150663,2438529370,812702999,355462360,4144912697,1731405415,4290775857,2394180231,1750603025,3675008525,1694076839,1203062813,3204075428],pn=[1779033703,4089235720,3144134277,2227873595,1013904242,4271175723,2773480762,1595750129,1359893119,2917565137,2600822924,725511199,528734635,4215389547,1541459225,327033209];function Sn(e){let t=new Uint32Array(e?mn:pn),n=new Int32Array(80),r=new Int32Array(80),i=new Uint8Array(128),a=0,f=0,o=function(c,d){for(let E=0;E<16;E++){let T=d+8*E;n[E]=c[T]<<24|c[T+1]<<16|c[T+2]<<8|c[T+3],r[E]=c[T+4]<<24|c[T+5]<<16|c[T+6]<<8|c[T+7]}for(let E=16;E<80;E++){let T=n[E-15],z=r[E-15],xe=(T>>>1|z<<31)^(T>>>8|z<<24)^T>>>7,ze=(z>>>1|T<<31)^(z>>>8|T<<24)^(z>>>7|T<<25);T=n[E-2],z=r[E-2];let L=(T>>>19|z<<13)^(z>>>29|T<<3)^T>>>6,ke=(z>>>19|T<<13)^(T>>>29|z<<3)^(z>>>6|T<<26),Se=(r[E-16]>>>0)+(ze>>>0)+(r[E-7]>>>0)+(ke>>>0);n[E]=n[E-16]+xe+n[E-7]+L+Math.floor(Se/4294967296),r[E]=Se}let g=t[0],A=t[1],b=t[2],h=t[3],p=t[4],P=t[5],j=t[6],I=t[7],K=t[8],U=t[9],q=t[10],te=t[11],Ae=t[12],he=t[13],Ne=t[14],Me=t[15];for(let E=0;E<80;E++){let T=(K>>>14|U<<18)^(K>>>18|U<<14)^(U>>>9|K<<23),z=(U>>>14|K<<18)^(U>>>18|K<<14)^(K>>>9|U<<23),xe=K&q^~K&Ae,ze=U&te^~U&he,L=(Me>>>0)+(z>>>0)+(ze>>>0)+st[2*E+1]+(r[E]>>>0),ke=Ne+T+xe+st[2*E]+n[E]+Math.floor(L/4294967296)|0,Se=L>>>0,Wt=(g>>>28|A<<4)^(A>>>2|g<<30)^(A>>>7|g<<25),qt=(A>>>28|g<<4)^(g>>>2|A<<30)^(g>>>7|A<<25),Qt=g&b^g&p^b&p,en=A&h^A&P^h&P;L=(qt>>>0)+(en>>>0);let tn=Wt+Qt+Math.floor(L/4294967296)|0,nn=L>>>0;Ne=Ae,Me=he,Ae=q,he=te,q=K,te=U,L=(I>>>0)+Se,K=j+ke+Math.floor(L/4294967296)|0,U=L>>>0,j=p,I=P,p=b,P=h,b=g,h=A,L=Se+nn,g=ke+tn+Math.floor(L/4294967296)|0,A=L>>>0}let ne=function(E,T,z){let xe=t[E+1]+(z>>>0);t[E]=t[E]+T+Math.floor(xe/4294967296),t[E+1]=xe};ne(0,g,A),ne(2,b,h),ne(4,p,P),ne(6,j,I),ne(8,K,U),ne(10,q,te),ne(12,Ae,he),ne(14,Ne,Me)};return{update:function(c){f+=c.length;let d=0;if(a>0){for(;a<128&&d<c.length;)i[a++]=c[d++];if(a<128)return;o(i,0),a=0}for(;d+128<=c.length;d+=128)o(c,d);for(;d<c.length;)i[a++]=c[d++]},digest:function(){let c=f*8;i[a++]=128,a>112&&(i.fill(0,a),o(i,0),a=0),i.fill(0,a);let d=new DataView(i.buffer);d.setUint32(120,Math.floor(c/4294967296)),d.setUint32(124,c>>>0),o(i,0);let g=new Uint8Array(64),A=new DataView(g.buffer);for(let b=0;b<16;b++)A.setUint32(4*b,t[b]);return e?g.slice(0,48):g}}}var ot=new Uint32Array([1,0,32898,0,32906,2147483648,2147516416,2147483648,32907,0,2147483649,0,2147516545,2147483648,32777,2147483648,138,0,136,0,2147516425,0,2147483658,0,2147516555,0,139,2147483648,32905,2147483648,32771,2147483648,32770,2147483648,128,2147483648,32778,0,2147483658,2147483648,2147516545,2147483648,32896,2147483648,2147483649,0,2147516424,2147483648]),En=[0,1,62,28,27,36,44,6,55,20,3,10,43,25,39,41,45,15,21,8,18,2,61,56,14],gt={"SHA3-224":[144,28,6],"SHA3-256":[136,32,6],"SHA3-384":[104,48,6],"SHA3-512":[72,64,6],SHAKE128:[168,32,31],SHAKE256:[136,64,31]};function ut(e){let t=new Uint32Array(10),n=new Uint32Array(50);for(let r=0;r<24;r++){for(let i=0;i<5;i++)t[2*i]=e[2*i]^e[2*i+10]^e[2*i+20]^e[2*i+30]^e[2*i+40],t[2*i+1]=e[2*i+1]^e[2*i+11]^e[2*i+21]^e[2*i+31]^e[2*i+41];for(let i=0;i<5;i++){let a=2*((i+1)%5),f=2*((i+4)%5),o=t[f]^(t[a]<<1|t[a+1]>>>31),c=t[f+1]^(t[a+1]<<1|t[a]>>>31);for(let d=0;d<25;d+=5)e[2*(i+d)]^=o,e[2*(i+d)+1]^=c}for(let i=0;i<5;i++)for(let a=0;a<5;a++){let f=i+5*a,o=e[2*f],c=e[2*f+1],d=En[f];d>=32&&([o,c]=[c,o],d-=32);let g=2*(a+5*((2*i+3*a)%5));d===0?(n[g]=o,n[g+1]=c):(n[g]=o<<d|c>>>32-d,n[g+1]=c<<d|o>>>32-d)}for(let i=0;i<25;i+=5)for(let a=0;a<5;a++){let f=2*(a+i),o=2*((a+1)%5+i),c=2*((a+2)%5+i);e[f]=n[f]^~n[o]&n[c],e[f+1]=n[f+1]^~n[o+1]&n[c+1]}e[0]^=ot[2*r],e[1]^=ot[2*r+1]}}function yt(e){let[t,n,r]=gt[e],i=new Uint32Array(50),a=new Uint8Array(t),f=0,o=function(){let c=new DataView(a.buffer);for(let d=0;d<t/4;d++)i[d]^=c.getUint32(4*d,!0);ut(i),f=0};return{update:function(c){for(let d=0;d<c.length;d++)a[f++]=c[d],f===t&&o()},digest:function(){a.fill(0,f),a[f]^=r,a[t-1]^=128,o();let c=new Uint8Array(n);for(let d=0;d<n;d+=t){d>0&&ut(i);let g=new DataView(new ArrayBuffer(t));for(let A=0;A<t/4;A++)g.setUint32(4*A,i[A],!0);c.set(new Uint8Array(g.buffer,0,Math.min(t,n-d)),d)}return c}}}function At(e){return Object.keys(e)}async function ve(e,t){return u(t)?e:Je(e,t)}function Je(e,t){if(Array.isArray(e))return e.map(r=>Je(r,t));if(e===null||typeof e!="object")return e;let n={};for(let[r,i]of vn(t))i===null||e[r]===void 0?n[r]=e[r]:n[r]=Je(e[r],i);return n}function vn(e){let t=[];if(Array.isArray(e))for(let r of e)if(typeof r=="string")t.push([r,null]);else if(r!==null&&typeof r=="object"&&!Array.isArray(r))for(let[i,a]of Object.entries(r))t.push([i,a]);else throw new Q("Canonical: invalid canon element: "+JSON.stringify(r),s.CanonInvalid);else if(e!==null&&typeof e=="object")for(let[r,i]of Object.entries(e))t.push([r,i!==null&&typeof i=="object"?i:null]);else throw new Q("Canonical: canon must be an array or object.",s.CanonInvalid);if(new Set(t.map(r=>r[0])).size!==t.length)throw new Q("Canonical: Canon cannot have duplicate fields.",s.CanonInvalid);return t}async function Be(e,t,n){return!u(n)&&n.normalizeUnicode===!0&&(e=ee(e)),JSON.stringify(await ve(e,t))}function ee(e){if(typeof e=="string")return e.normalize("NFC");if(Array.isArray(e))return e.map(ee);if(e!==null&&typeof e=="object"){let t={};for(let n of Object.keys(e))t[n]=ee(e[n]);return t}return e}async function Fn(e,t,n,r){if(u(t))throw new x("Hash is not given",s.AlgUnsupported);if(e instanceof Uint8Array||e instanceof ArrayBuffer){if(u(n)&&u(r))return await W(t,e);e=JSON.parse(new TextDecoder().decode(e))}return await W(t,await J(await Be(e,n,r)))}async function me(e,t,n,r){return await m(await Fn(e,t,n,r))}var In={ES224:{p:BigInt("0xffffffffffffffffffffffffffffffff000000000000000000000001"),b:BigInt("0xb4050a850c04b3abf54132565044b0b7d7bfd8ba270b39432355ffb4"),gx:BigInt("0xb70e0cbd6bb4bf7f321390b94a03c1d356c21122343280d6115c1d21"),gy:BigInt("0xbd376388b5f723fb4c22dfe6cd4375a05a07476444d5819985007e34")},ES256:{p:BigInt("0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),b:BigInt("0x5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),gx:BigInt("0x6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),gy:BigInt("0x4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5")},ES384:{p:BigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff"),b:BigInt("0xb3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef"),gx:BigInt("0xaa87ca22be8b05378eb1c71ef320ad746e1d3b628ba79b9859f741e082542a385502f25dbf55296c3a545e3872760ab7"),gy:BigInt("0x3617de4a96262c6f5d9e98bf9292dc29f8f41dbd289a147ce9da3113b5f0b8c00a60b1ce1d7e819d7a431d7c90ea0e5f")},ES512:{p:(1n<<521n)-1n,b:BigInt("0x0051953eb9618e1c9a1f929a21a0b68540eea2da725b99b315f3b8b489918ef109e156193951ec7e937b1652c0bd3bb1bf073573df883d2c34f1ef451fd46b503f00"),gx:BigInt("0x00c6858e06b70404e9cd9e3ecb662395b4429c648139053fb521f828af606b4d3dbaa14b5e77efe75928fe1dc127a2ffa8de3348b3c1856a429bf97e7e31c2e5bd66"),gy:BigInt("0x011839296a789a3bc0045c8a5fb42c7d1bd998f54449579b446817afbd17273e662c97ee72995ef42640c550b9013fad0761353c7086a272c24088be94769fd16650")}},F={New:async function(e){let t=xt(e),n={alg:e,d:m(X($(e),t)),x:await F.PublicFromD(e,t)};return{privateKey:await F.FromCozeKey(n),publicKey:await F.FromCozeKey(n,!0)}},FromCozeKey:async function(e,t){let n=ue(e.alg),r=C(e.x);if(r.length!==G(e.alg))throw new y("ECDSA.FromCozeKey: incorrect x size.",s.KeyInvalid,{field:"x"});let i=G(e.alg)/2,a={x:re(r.slice(0,i)),y:re(r.slice(i))};if(!kn(n,a))throw new y("ECDSA.FromCozeKey: the key is not on the curve.",s.KeyInvalid,{field:"x"});let f={type:"public",extractable:!0,algorithm:{name:S.ECDSA,namedCurve:oe(e.alg)},usages:["verify"],ecdsa:{alg:e.alg,point:a}};if(!u(e.d)&&!t){let o=re(C(e.d));if(o<=0n||o>=n.n)throw new y("ECDSA.FromCozeKey: invalid private key.",s.KeyInvalid,{field:"d"});f.type="private",f.usages=["sign"],f.ecdsa.d=o}return f},IsKey:function(e){return typeof e=="object"&&e!==null&&typeof e.ecdsa=="object"},ToCozeKey:function(e){let t=e.ecdsa.alg,n=G(t)/2,r={alg:t,x:m(de(X(n,e.ecdsa.point.x),X(n,e.ecdsa.point.y)))};return e.ecdsa.d!==void 0&&(r.d=m(X($(t),e.ecdsa.d))),r},PublicFromD:async function(e,t){let n=ue(e),r=Ge(n,Ke(n,t,{x:n.gx,y:n.gy,z:1n})),i=G(e)/2;return m(de(X(i,r.x),X(i,r.y)))},KeyFromSeed:async function(e,t){let n=ue(e),r=B(e),i=Math.ceil(n.nBits/8),a=BigInt(i*8-n.nBits),f=await _e(r,new TextEncoder().encode("Coze NewKeyFromSeed"),t);for(let o=0;o<256;o++){let c=de(new TextEncoder().encode(e),new Uint8Array([o])),d=re(await Hn(r,f,c,i))>>a;if(d>0n&&d<n.n)return{alg:e,d:m(X($(e),d)),x:await F.PublicFromD(e,d)}}throw new y("ECDSA.KeyFromSeed: no valid scalar derived.",s.KeyInvalid,{field:"seed"})},SignBuffer:async function(e,t,n){let r=e.ecdsa.alg,i=await W(B(r),t);return F.SignDigest(e,new Uint8Array(i),n)},SignDigest:async function(e,t,n){if(e.type!=="private")throw new y("ECDSA.SignDigest: key must be private.",s.KeyInvalid,{field:"d"});let r=e.ecdsa.alg,i=ue(r),a=Ue(i,t),f=null;for(n===!0&&(f=await Tn(r,e.ecdsa.d,t));;){let o=f===null?xt(r):await f.next(),c=w(Ge(i,Ke(i,o,{x:i.gx,y:i.gy,z:1n})).x,i.n);if(c===0n)continue;let d=w(je(o,i.n)*(a+c*e.ecdsa.d),i.n);if(d===0n)continue;let g=R(r)/2;return de(X(g,c),X(g,d)).buffer}},VerifyBuffer:async function(e,t,n){let r=await W(B(e.ecdsa.alg),t);return F.VerifyDigest(e,new Uint8Array(r),n)},VerifyDigest:async function(e,t,n){let r=e.ecdsa.alg,i=ue(r);if(n=new Uint8Array(n),n.length!==R(r))return!1;let a=R(r)/2,f=re(n.slice(0,a)),o=re(n.slice(a));if(f<=0n||f>=i.n||o<=0n||o>=i.n)return!1;let c=Ue(i,t),d=je(o,i.n),g=Ke(i,w(c*d,i.n),{x:i.gx,y:i.gy,z:1n}),A=Ke(i,w(f*d,i.n),{x:e.ecdsa.point.x,y:e.ecdsa.point.y,z:1n}),b=bt(i,g,A);return b.z===0n?!1:w(Ge(i,b).x,i.n)===f}};function ue(e){let t=In[e];if(t===void 0)throw new x("ECDSA: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});return t.n===void 0&&(t.n=De(e),t.nBits=t.n.toString(2).length),t}function xt(e){let t=ue(e),n=Math.ceil(t.nBits/8),r=BigInt(n*8-t.nBits);for(;;){let i=re(crypto.getRandomValues(new Uint8Array(n)))>>r;if(i>0n&&i<t.n)return i}}async function Tn(e,t,n){let r=ue(e),i=B(e),a=Math.ceil(r.nBits/8),f=Y(e),o=(h,...p)=>_e(i,h,mt(p)),c=X(a,t),d=X(a,w(Ue(r,n),r.n)),g=new Uint8Array(f).fill(1),A=new Uint8Array(f);A=await o(A,g,[0],c,d),g=await o(A,g),A=await o(A,g,[1],c,d),g=await o(A,g);let b=!1;return{next:async function(){for(;;){b&&(A=await o(A,g,[0]),g=await o(A,g)),b=!0;let h=new Uint8Array(0);for(;h.length<a;)g=await o(A,g),h=de(h,g);let p=Ue(r,h);if(p>0n&&p<r.n)return p}}}}function Ue(e,t){let n=re(t),r=t.length*8;return r>e.nBits&&(n>>=BigInt(r-e.nBits)),n}function w(e,t){let n=e%t;return n<0n?n+t:n}function je(e,t){let[n,r]=[w(e,t),t],[i,a]=[1n,0n];for(;r!==0n;){let f=n/r;[n,r]=[r,n-f*r],[i,a]=[a,i-f*a]}if(n!==1n)throw new y("ECDSA: no modular inverse.",s.KeyInvalid);return w(i,t)}function kn(e,t){return t.x<0n||t.x>=e.p||t.y<0n||t.y>=e.p?!1:w(t.y*t.y-(t.x*t.x*t.x-3n*t.x+e.b),e.p)===0n}function Ge(e,t){let n=je(t.z,e.p),r=w(n*n,e.p);return{x:w(t.x*r,e.p),y:w(t.y*r*n,e.p)}}function wt(e,t){if(t.z===0n||t.y===0n)return{x:0n,y:1n,z:0n};let n=e.p,r=w(t.z*t.z,n),i=w(t.y*t.y,n),a=w(t.x*i,n),f=w(3n*(t.x-r)*(t.x+r),n),o=w(f*f-8n*a,n),c=w((t.y+t.z)*(t.y+t.z)-i-r,n),d=w(f*(4n*a-o)-8n*i*i,n);return{x:o,y:d,z:c}}function bt(e,t,n){if(t.z===0n)return n;if(n.z===0n)return t;let r=e.p,i=w(t.z*t.z,r),a=w(n.z*n.z,r),f=w(t.x*a,r),o=w(n.x*i,r),c=w(t.y*n.z*a,r),d=w(n.y*t.z*i,r),g=w(o-f,r),A=w(2n*(d-c),r);if(g===0n)return A===0n?wt(e,t):{x:0n,y:1n,z:0n};let b=w(4n*g*g,r),h=w(g*b,r),p=w(f*b,r),P=w(A*A-h-2n*p,r),j=w(A*(p-P)-2n*c*h,r),I=w(((t.z+n.z)*(t.z+n.z)-i-a)*g,r);return{x:P,y:j,z:I}}function Ke(e,t,n){let r={x:0n,y:1n,z:0n};for(let i=BigInt(t.toString(2).length-1);i>=0n;i--)r=wt(e,r),t>>i&1n&&(r=bt(e,r,n));return r}async function Hn(e,t,n,r){let i=new Uint8Array(0),a=new Uint8Array(0);for(let f=1;i.length<r;f++)a=await _e(e,t,mt([a,n,[f]])),i=de(i,a);return i.slice(0,r)}function re(e){let t=0n;for(let n of e)t=(t<<8n)+BigInt(n);return t}function X(e,t){let n=new Uint8Array(e);for(let r=e-1;r>=0;r--)n[r]=Number(t&0xffn),t>>=8n;return n}function de(e,t){let n=new Uint8Array(e.length+t.length);return n.set(e,0),n.set(t,e.length),n}function mt(e){let t=new Uint8Array(0);for(let n of e)t=de(t,new Uint8Array(n));return t}var Xe=new WeakMap;function hr(){Xe=new WeakMap}var k={New:async function(e){switch(u(e)&&(e=l.ES256),e){case l.ES224:return F.New(e);case l.ES256:case l.ES384:case l.ES512:return await crypto.subtle.generateKey({name:S.ECDSA,namedCurve:oe(e)},!0,["sign","verify"]);case l.Ed25519:try{return await crypto.subtle.generateKey({name:l.Ed25519},!0,["sign","verify"])}catch(t){throw Et("CryptoKey.New",e,t)}default:throw new x("CryptoKey.New: Unsupported key algorithm:"+e,s.AlgUnsupported,{alg:e})}},FromCozeKey:async function(e,t){let n=u(e.d)||t?"verify":"sign",r=Xe.get(e);r===void 0&&(r={},Xe.set(e,r));let i=r[n];if(i!==void 0&&i.alg===e.alg&&i.x===e.x&&i.d===e.d)return i.key;let a=Bn(e,t);r[n]={alg:e.alg,x:e.x,d:e.d,key:a};try{return await a}catch(f){throw r[n]!==void 0&&r[n].key===a&&delete r[n],f}},ToPublic:async function(e){delete e.d,e.key_ops=["verify"]},ToCozeKey:async function(e){if(F.IsKey(e)){let n=F.ToCozeKey(e);return n.tmb=await H(n),n}let t=await crypto.subtle.exportKey("jwk",e);return Dn(t)},SignBuffer:async function(e,t){let n=await k.algFromCryptoKey(e);if(F.IsKey(e))var r=await F.SignBuffer(e,t);else r=await crypto.subtle.sign(pt(n),e,t);return _(n)==S.ECDSA&&(r=Ft(n,r)),r},SignBufferB64:async function(e,t){return await m(await k.SignBuffer(e,t))},SignString:async function(e,t){return await k.SignBufferB64(e,await J(t))},VerifyArrayBuffer:async function(e,t,n,r){return F.IsKey(t)?F.VerifyBuffer(t,n,r):(await k.ToPublic(t),await crypto.subtle.verify(pt(await k.algFromCryptoKey(t)),t,r,n))},VerifyMsg:async function(e,t,n,r){return k.VerifyArrayBuffer(e,t,await J(n),await we(r))},GetSignHashAlgoFromCryptoKey:async function(e){return B(await k.algFromCryptoKey(e))},algFromCryptoKey:async function(e){return e.algorithm.name===l.Ed25519?l.Ed25519:k.algFromCrv(e.algorithm.namedCurve)},algFromCrv:async function(e){switch(e){case l.Ed25519:var t=l.Ed25519;break;case V.P224:t=l.ES224;break;case V.P256:t=l.ES256;break;case V.P384:t=l.ES384;break;case V.P521:t=l.ES512;break;default:throw new x("CryptoKey.ToCozeKey: Unsupported key algorithm.",s.AlgUnsupported,{crv:e})}return t}};function St(e){if(u(e.x))throw new y("CozeKeyToJWK: key x must be set.",s.KeyInvalid,{field:"x"});var t={};switch(e.alg){case l.Ed25519:t.kty="OKP",t.crv=l.Ed25519,t.alg="EdDSA",t.use=se.Sig,t.x=e.x;break;case l.ES256:case l.ES384:case l.ES512:{t.kty=Ce.EC,t.crv=oe(e.alg),t.alg=e.alg,t.use=se.Sig;let n=G(e.alg)/2,r=C(e.x);if(r.length!==n*2)throw new y("CozeKeyToJWK: incorrect x size for "+e.alg+".",s.KeyInvalid,{field:"x"});t.x=m(r.slice(0,n)),t.y=m(r.slice(n));break}default:throw new x("CozeKeyToJWK: unsupported alg: "+e.alg,s.AlgUnsupported,{alg:e.alg})}return u(e.d)||(t.d=e.d),t}async function Dn(e){let t;switch(e.crv){case l.Ed25519:if(e.kty!=="OKP")throw new y("JWKToCozeKey: kty must be OKP for Ed25519.",s.KeyInvalid,{field:"kty"});t=l.Ed25519;break;case V.P256:case V.P384:case V.P521:if(e.kty!==Ce.EC)throw new y("JWKToCozeKey: kty must be EC for curve "+e.crv+".",s.KeyInvalid,{field:"kty"});t=await k.algFromCrv(e.crv);break;default:throw new x("JWKToCozeKey: unsupported crv: "+e.crv,s.AlgUnsupported,{alg:e.crv})}if(!u(e.alg)&&e.alg!==t&&!(t===l.Ed25519&&e.alg==="EdDSA"))throw new x("JWKToCozeKey: JWK alg "+e.alg+" mismatch with crv "+e.crv+".",s.AlgMismatch,{alg:e.alg});var n={alg:t};if(u(e.x))throw new y("JWKToCozeKey: JWK x must be set.",s.KeyInvalid,{field:"x"});if(t===l.Ed25519){if(C(e.x).length!==G(t))throw new y("JWKToCozeKey: incorrect x size for Ed25519.",s.KeyInvalid,{field:"x"});n.x=e.x,u(e.d)||(n.d=e.d)}else{if(u(e.y))throw new y("JWKToCozeKey: JWK y must be set.",s.KeyInvalid,{field:"y"});let r=G(t)/2;n.x=m(Kn(Ze("x",r,C(e.x)),Ze("y",r,C(e.y))).buffer),u(e.d)||(n.d=m(Ze("d",$(t),C(e.d)).buffer))}return n.tmb=await H(n),n}async function _n(e,t){let n={name:l.Ed25519};try{return u(e.d)||t?await crypto.subtle.importKey("raw",C(e.x),n,!0,["verify"]):await crypto.subtle.importKey("jwk",St(e),n,!0,["sign"])}catch(r){throw Et("CryptoKey.FromCozeKey",e.alg,r)}}async function Bn(e,t){if(e.alg===l.Ed25519)return _n(e,t);if(e.alg===l.ES224)return F.FromCozeKey(e,t);if(_(e.alg)!=S.ECDSA)throw new x("CryptoKey.FromCozeKey: unsupported CryptoKey algorithm: "+e.alg,s.AlgUnsupported,{alg:e.alg});let n=St(e);if(u(e.d)||t){var r="verify";delete n.d}else r="sign";return await crypto.subtle.importKey("jwk",n,{name:S.ECDSA,namedCurve:n.crv},!0,[r])}function Ze(e,t,n){if(n.length>t)throw new y("JWKToCozeKey: incorrect "+e+" size.",s.KeyInvalid,{field:e});let r=new Uint8Array(t);return r.set(n,t-n.length),r}function Kn(e,t){let n=new Uint8Array(e.length+t.length);return n.set(e,0),n.set(t,e.length),n}function pt(e){return e===l.Ed25519?{name:l.Ed25519}:{name:S.ECDSA,hash:{name:B(e)}}}function Et(e,t,n){return n instanceof DOMException&&n.name==="NotSupportedError"?new x(e+": alg "+t+" unsupported in this browser.",s.AlgUnsupported,{alg:t}):n}function Ct(e,t){if(typeof t!="bigint")throw new TypeError("IsLowS: s is not of type bigint");return ft(e)>t}function Un(e,t){if(typeof t!="bigint")throw new TypeError("toLowS: s is not of type bigint");return Ct(e,t)?t:De(e)-t}async function Ye(e,t){let n=await we(t),r=await Ft(e,n);return m(r)}async function vt(e,t){let n=await Rn(e,t);return Ct(e,n)}function Rn(e,t){let n=R(e)/2,r=t.slice(n);return It(r)}async function Ft(e,t){let n=R(e)/2,r=t.slice(0,n),i=t.slice(n),a=It(i),f=Un(e,a),o=Pn(R(e)/2,f);var c=new Uint8Array(r.byteLength+o.byteLength);return c.set(new Uint8Array(r),0),c.set(new Uint8Array(o),r.byteLength),t=c.buffer,t}function It(e){let t=0n,n=new Uint8Array(e);for(let r=0;r<n.length;r++)t=(t<<8n)+BigInt(n[r]);return t}function Pn(e,t){let n=new ArrayBuffer(e),r=new DataView(n);do e--,r.setUint8(e,Number(t&BigInt(255))),t>>=8n;while(e>0);return n}var Nn=["alg","x"],Mn=["alg","iat","kid","tmb","typ","rvk","x"];async function mr(e){if(u(e)&&(e=l.ES256),_(e)==S.ECDSA||e==l.Ed25519)var t=await k.New(e);else throw new x("Coze.NewKey: only ECDSA algs and Ed25519 are currently supported.",s.AlgUnsupported,{alg:e});let n=await k.ToCozeKey(t.privateKey);return n.iat=Math.floor(Date.now()/1e3),n.tmb=await H(n),n.kid="My Cyphr.me Key.",n}var kt=new WeakMap,qe=[48,46,2,1,0,48,5,6,3,43,101,112,4,34,4,32];async function Ht(e){let t=new Uint8Array(qe.length+e.length);t.set(qe),t.set(e,qe.length);let n=await crypto.subtle.importKey("pkcs8",t,{name:l.Ed25519},!0,["sign"]);return(await crypto.subtle.exportKey("jwk",n)).x}async function zn(e,t){if(!(t instanceof Uint8Array))throw new TypeError("Coze.NewKeyFromSeed: seed must be a Uint8Array.");let n;if(_(e)==S.ECDSA){let r=Y(e)/2;if(t.length<r)throw new y(`Coze.NewKeyFromSeed: seed must be at least ${r} bytes for ${e}.`,s.KeyInvalid,{field:"seed"});n=await F.KeyFromSeed(e,t)}else if(e==l.Ed25519){if(t.length!==$(e))throw new y("Coze.NewKeyFromSeed: Ed25519 seed must be 32 bytes.",s.KeyInvalid,{field:"seed"});n={alg:e,d:m(t),x:await Ht(t)}}else throw new x("Coze.NewKeyFromSeed: only ECDSA algs and Ed25519 are currently supported.",s.AlgUnsupported,{alg:e});return n.tmb=await H(n),n}var On=21e4;async function pr(e,t,n,r){if(typeof n=="string"&&(n=new TextEncoder().encode(n)),!(n instanceof Uint8Array)||n.length<16)throw new y("Coze.NewKeyFromPassword: salt must be at least 16 bytes.",s.KeyInvalid,{field:"salt"});let i=On;if(!u(r)&&r.iterations!==void 0&&(i=r.iterations),!Number.isSafeInteger(i)||i<1)throw new y("Coze.NewKeyFromPassword: iterations must be a positive integer.",s.KeyInvalid,{field:"iterations"});let a=$(l.Ed25519);_(e)==S.ECDSA&&(a=Y(e));let f=await crypto.subtle.importKey("raw",new TextEncoder().encode(t.normalize("NFC")),"PBKDF2",!1,["deriveBits"]),o=await crypto.subtle.deriveBits({name:"PBKDF2",hash:l.SHA512,salt:n,iterations:i},f,a*8);return{key:await zn(e,new Uint8Array(o)),params:{alg:e,kdf:"PBKDF2",hash:l.SHA512,iterations:i,salt:m(n)}}}async function H(e){if(u(e.alg)||u(e.x))throw new y("Coze.Thumbprint: alg or x is empty.",s.KeyInvalid,{field:u(e.alg)?"alg":"x"});let t=kt.get(e);if(t!==void 0&&t.alg===e.alg&&t.x===e.x)return t.tmb;let n=await me(e,await B(e.alg),Nn);return kt.set(e,{alg:e.alg,x:e.x,tmb:n}),n}async function Sr(e){let t=await H(e);if(t!==e.tmb)throw new y("Coze.ThumbprintMatch: key.tmb does not match the calculated thumbprint.",s.TmbMismatch,{field:"tmb"});return t}function Dt(e){let t={};for(let[n,r]of Object.entries(e))Mn.includes(n)&&(t[n]=r);return t}function Er(e){return _t(e)!==void 0}function Cr(e){let t=_t(e);if(t!==void 0)throw new y(`AssertPublic: key has private component "${t}".`,s.KeyInvalid,{field:t})}function _t(e){if(!(e===null||typeof e!="object"))return Object.keys(e).find(t=>t.toLowerCase()==="d")}async function vr(e){let t=[],n={},r=function(d,g,A){let b={name:d,status:g};u(A)||(b.message=A),t.push(b),n[d]=g},i=(...d)=>d.every(g=>n[g]==="pass"),a=function(d,...g){r(d,"skip","Requires passing "+g.filter(A=>n[A]!=="pass").join(", ")+".")},f={};(typeof e!="object"||e===null)&&(e={});let o;try{o=Le(e.alg),o.Use!==se.Sig?r("alg_known","fail",`alg "${e.alg}" is not a signing alg.`):r("alg_known","pass")}catch{r("alg_known","fail",`alg "${e.alg}" is not supported.`)}let c=[];for(let d of["x","d","tmb"])if(!u(e[d]))try{f[d]=C(e[d],d)}catch{c.push(d)}u(e.x)&&u(e.d)&&u(e.tmb)?r("b64ut","fail","At least one of x, d, and tmb must be set."):c.length>0?r("b64ut","fail","Not strict b64ut: "+c.join(", ")+"."):r("b64ut","pass");for(let[d,g,A]of[["x_length","x","XSize"],["d_length","d","DSize"]])u(e[g])?r(d,"skip",`No ${g}.`):i("alg_known","b64ut")?f[g].length!==o[A]?r(d,"fail",`${g} is ${f[g].length} bytes, ${e.alg} requires ${o[A]}.`):r(d,"pass"):a(d,"alg_known","b64ut"),d==="x_length"&&(u(e.x)?r("y_length","skip","No x."):i("alg_known","b64ut")?o.Genus!==S.ECDSA?r("y_length","skip",`${e.alg} has no Y coordinate.`):f.x.length===o.XSize/2?r("y_length","fail","x is only the X coordinate.  Coze x is X || Y."):f.x.length!==o.XSize?a("y_length","x_length"):r("y_length","pass"):a("y_length","alg_known","b64ut"));if(u(e.tmb)?r("tmb_matches","skip","No tmb."):i("alg_known","b64ut")?u(e.x)?f.tmb.length!==o.HashSize?r("tmb_matches","fail",`tmb is ${f.tmb.length} bytes, ${e.alg} requires ${o.HashSize}.`):r("tmb_matches","pass"):i("x_length")?await H(e)!==e.tmb?r("tmb_matches","fail","tmb does not match the thumbprint of alg and x."):r("tmb_matches","pass"):a("tmb_matches","x_length"):a("tmb_matches","alg_known","b64ut"),u(e.d)||u(e.x))r("d_derives_x","skip","Requires d and x.");else if(!i("x_length","d_length"))a("d_derives_x","x_length","d_length");else try{let d;o.Genus===S.ECDSA?d=await F.PublicFromD(e.alg,BigInt("0x"+He(f.d))):e.alg===l.Ed25519&&(d=await Ht(f.d)),d===void 0?r("d_derives_x","skip",`Deriving x is not supported for ${e.alg}.`):d!==e.x?r("d_derives_x","fail","x is not the public key of d."):r("d_derives_x","pass")}catch(d){r("d_derives_x","fail","d is invalid: "+d.message)}if(u(e.d)||u(e.x))r("sign_verify_roundtrip","skip","Requires d and x.");else if(n.d_derives_x==="fail"||!i("x_length","d_length"))a("sign_verify_roundtrip","x_length","d_length","d_derives_x");else try{let d="Coze Diagnose",g=await Fe(d,e);await pe(d,e,g)?r("sign_verify_roundtrip","pass"):r("sign_verify_roundtrip","fail","Signature by d did not verify with x.")}catch(d){r("sign_verify_roundtrip","fail",d.message)}return{ok:t.every(d=>d.status!=="fail"),checks:t}}async function Fr(e){if(u(e.d))return console.error("Coze key missing `d`"),!1;try{let t="7AtyaCHO2BAG06z0W1tOQlZFWbhxGgqej4k9-HWP3DE-zshRbrE-69DIfgY704_FDYez7h_rEI1WQVKhv5Hd5Q",n=await Fe(t,e);return pe(t,e,n)}catch{return!1}}async function Ir(e){if(typeof e!="object")return console.error("Correct: CozeKey must be passed in as an object."),!1;if(u(e.alg))return console.error("Correct: Alg must be set"),!1;let t=Le(e.alg),n=u(e.tmb),r=u(e.x),i=u(e.d);if(n&&r&&i)return console.error("Correct: At least one of [x, tmb, d] must be set"),!1;for(let a of["x","d","tmb"])if(!u(e[a]))try{C(e[a],a)}catch(f){return console.error("Correct: "+f.message),!1}if(r&&i)return n||e.tmb.length!==t.HashSizeB64?(console.error("Correct: Incorrect `tmb` size: ",e.tmb.length),!1):!0;if(!r&&e.x.length!==t.XSizeB64)return console.error("Correct: Incorrect x size: ",e.x.length),!1;if(!n&&!r){let a=await H(e);if(e.tmb!==a)return console.error("Correct: Incorrect given `tmb`: ",e.tmb),!1}if(!i&&!r){let a=await k.FromCozeKey(e),f=await J("Test Signing"),o=await k.SignBuffer(a,f),c=await k.FromCozeKey(e,!0);if(!await k.VerifyArrayBuffer(e.alg,c,f,o))return console.error("Correct: private key invalid."),!1}return!0}async function Tr(e,t){if(u(e))throw new y("CozeKey.Revoke: Private key not set.  Cannot sign message",s.KeyInvalid,{field:"d"});typeof t=="string"&&(t={msg:t}),u(t)&&(t={});for(let i of["alg","iat","tmb","rvk"])if(i in t)throw new D(`CozeKey.Revoke: "${i}" is set by Revoke and may not be given.`,s.FieldReserved,{field:i});var n={};n.pay={},u(t.typ)||(n.pay.typ=t.typ),n.pay.rvk=Math.round(Date.now()/1e3),u(t.msg)||(n.pay.msg=t.msg);for(let[i,a]of Object.entries(t))i!=="typ"&&i!=="msg"&&(n.pay[i]=a);let r=e.rvk;delete e.rvk;try{n=await Bt(n,e,null,{setStandard:!0})}catch(i){throw r!==void 0&&(e.rvk=r),i}return r!==void 0?e.rvk=r:e.rvk=n.pay.rvk,n}function ce(e){let t=e.rvk;return t==null?!1:typeof t=="number"&&Number.isInteger(t)?t>0:!0}async function kr(e,t){let n=e.pay.rvk;return!Number.isSafeInteger(n)||n<=0||e.pay.tmb!==await H(t)||Number.isInteger(t.rvk)&&t.rvk>0&&n<t.rvk?!1:Kt(e,t,{allowRevoked:!0})}async function Qe(e,t){let n=e instanceof Map;if(!n&&!Array.isArray(e))return e;if(u(t))throw new y("LookupKey: no key for tmb: tmb is empty.",s.KeyNotFound,{field:"tmb"});let r=e;n&&(r=e.has(t)?[e.get(t)]:[]);for(let i of r){if(u(i))continue;let a;try{a=await H(i)}catch{continue}if(a===t)return i}throw new y(`LookupKey: no key for tmb ${t}.`,s.KeyNotFound,{field:"tmb",tmb:t})}var ie=2,Re=3,ge=4,ye=6,O=48,Rt=160,et=161,Pt=[42,134,72,206,61,2,1],Nt=[43,101,112],tt={ES224:[43,129,4,0,33],ES256:[42,134,72,206,61,3,1,7],ES384:[43,129,4,0,34],ES512:[43,129,4,0,35]};async function Br(e){let t=Jn(e),n;switch(t.label){case"PUBLIC KEY":n=$n(t.der);break;case"PRIVATE KEY":n=await Ln(t.der);break;case"EC PRIVATE KEY":n=await Mt(t.der,null);break;default:throw new y("PEMToCozeKey: unsupported PEM type: "+t.label,s.KeyInvalid,{field:"pem"})}return n.tmb=await H(n),n}function Kr(e,t){let n=!u(t)&&t.private===!0,r=!u(t)&&t.sec1===!0;if(u(e.x))throw new y("CozeKeyToPEM: key x must be set.",s.KeyInvalid,{field:"x"});if(n&&u(e.d))throw new y("CozeKeyToPEM: private key d must be set.",s.KeyInvalid,{field:"d"});let i=C(e.x);if(i.length!==G(e.alg))throw new y("CozeKeyToPEM: incorrect x size for "+e.alg+".",s.KeyInvalid,{field:"x"});if(e.alg===l.Ed25519){if(r)throw new x("CozeKeyToPEM: SEC1 is only for EC keys.",s.AlgUnsupported,{alg:e.alg});let g=v(O,v(ye,Nt));if(!n)return Ie("PUBLIC KEY",v(O,g,v(Re,[0],i)));let A=v(ge,C(e.d));return Ie("PRIVATE KEY",v(O,v(ie,[0]),g,v(ge,A)))}let a=tt[e.alg];if(a===void 0)throw new x("CozeKeyToPEM: unsupported alg: "+e.alg,s.AlgUnsupported,{alg:e.alg});let f=v(Re,[0,4],i),o=v(O,v(ye,Pt),v(ye,a));if(!n)return Ie("PUBLIC KEY",v(O,o,f));let c=v(ge,C(e.d));if(r)return Ie("EC PRIVATE KEY",v(O,v(ie,[1]),c,v(Rt,v(ye,a)),v(et,f)));let d=v(O,v(ie,[1]),c,v(et,f));return Ie("PRIVATE KEY",v(O,v(ie,[0]),o,v(ge,d)))}function Ur(e,t){let n=C(e);if(_(t)!==S.ECDSA)throw new x("SigToDER: alg must be ECDSA: "+t,s.AlgUnsupported,{alg:t});if(n.length!==R(t))throw new N(`SigToDER: incorrect sig size for ${t}: ${n.length} bytes, expected ${R(t)}.`,s.SigInvalid,{field:"sig"});let r=n.length/2;return v(O,Ut(n.slice(0,r)),Ut(n.slice(r)))}function rt(e,t){if(typeof e=="string"&&(e=C(e)),e=new Uint8Array(e),_(t)!==S.ECDSA)throw new x("DERToSig: alg must be ECDSA: "+t,s.AlgUnsupported,{alg:t});let n,r;try{n=ae(e,0),r=Te(n)}catch(f){throw new N("DERToSig: invalid DER signature: "+f.message,s.SigInvalid,{field:"sig"})}if(n.tag!==O||n.end!==e.length||r.length!==2)throw new N("DERToSig: invalid DER signature.",s.SigInvalid,{field:"sig"});let i=R(t)/2,a=new Uint8Array(i*2);for(let f=0;f<2;f++){if(r[f].tag!==ie)throw new N("DERToSig: invalid signature integer.",s.SigInvalid,{field:"sig"});let o=r[f].content;if(o.length===0||o[0]&128)throw new N("DERToSig: signature integers must be positive.",s.SigInvalid,{field:"sig"});let c=0;for(;c<o.length-1&&o[c]===0;)c++;if(o=o.slice(c),o.length>i)throw new N("DERToSig: signature integer too large for "+t+".",s.SigInvalid,{field:"sig"});a.set(o,i*(f+1)-o.length)}return m(a)}function it(e,t){return e.length>0&&e[0]===O&&e.length!==R(t)}function Ut(e){let t=0;for(;t<e.length-1&&e[t]===0;)t++;return e=e.slice(t),e[0]&128?v(ie,[0],e):v(ie,e)}function $n(e){let t=Te(Z(ae(e,0),O,"SPKI")),n=zt(t[0]),r=Z(t[1],Re,"SPKI public key");if(r.content[0]!==0)throw new y("PEMToCozeKey: unsupported SPKI public key padding.",s.KeyInvalid);return{alg:n,x:Vt(n,r.content.slice(1))}}async function Ln(e){let t=Te(Z(ae(e,0),O,"PKCS #8"));if(t.length<3)throw new y("PEMToCozeKey: invalid PKCS #8.",s.KeyInvalid);let n=zt(t[1]),r=Z(t[2],ge,"PKCS #8 private key").content;if(n!==l.Ed25519)return Mt(r,n);let i=Z(ae(r,0),ge,"Ed25519 private key").content;if(i.length!==$(n))throw new y("PEMToCozeKey: incorrect Ed25519 private key size.",s.KeyInvalid,{field:"d"});let a=await crypto.subtle.importKey("pkcs8",e,{name:l.Ed25519},!0,["sign"]),f=await crypto.subtle.exportKey("jwk",a);return{alg:n,x:f.x,d:m(i)}}async function Mt(e,t){let n=Te(Z(ae(e,0),O,"EC private key"));if(n.length<2||n[0].tag!==ie||n[0].content.length!==1||n[0].content[0]!==1)throw new y("PEMToCozeKey: unsupported EC private key version.",s.KeyInvalid);let r=null;for(let o of n.slice(2)){if(o.tag===Rt){let c=Ot(Z(ae(o.content,0),ye,"EC private key curve").content);if(t!==null&&t!==c)throw new x("PEMToCozeKey: EC private key curve mismatch.",s.AlgMismatch,{alg:c});t=c}o.tag===et&&(r=Z(ae(o.content,0),Re,"EC public key").content)}if(t===null)throw new y("PEMToCozeKey: EC private key curve not given.",s.KeyInvalid);let i=Z(n[1],ge,"EC private key").content;if(i.length>$(t))throw new y("PEMToCozeKey: incorrect private key size.",s.KeyInvalid,{field:"d"});let a=new Uint8Array($(t));a.set(i,a.length-i.length);let f={alg:t};if(r!==null){if(r[0]!==0)throw new y("PEMToCozeKey: unsupported EC public key padding.",s.KeyInvalid);f.x=Vt(t,r.slice(1))}else f.x=await F.PublicFromD(t,Gn(a));return f.d=m(a),f}function zt(e){let t=Te(Z(e,O,"algorithm identifier")),n=Z(t[0],ye,"algorithm").content;if(nt(n,Nt))return l.Ed25519;if(!nt(n,Pt))throw new x("PEMToCozeKey: unsupported key algorithm.",s.AlgUnsupported);if(t.length<2)throw new y("PEMToCozeKey: EC named curve not given.",s.KeyInvalid);return Ot(Z(t[1],ye,"named curve").content)}function Ot(e){for(let t in tt)if(nt(e,tt[t]))return t;throw new x("PEMToCozeKey: unsupported named curve.",s.AlgUnsupported)}function Vt(e,t){if(e!==l.Ed25519){if(t[0]!==4)throw new y("PEMToCozeKey: only uncompressed EC points are supported.",s.KeyInvalid,{field:"x"});t=t.slice(1)}if(t.length!==G(e))throw new y("PEMToCozeKey: incorrect public key size for "+e+".",s.KeyInvalid,{field:"x"});return m(t)}function Jn(e){let t=/-----BEGIN ([A-Z0-9 ]+)-----([\s\S]*?)-----END \1-----/g,n;for(;(n=t.exec(e))!==null;){if(n[1]==="EC PARAMETERS")continue;if(n[1]==="ENCRYPTED PRIVATE KEY"||n[2].includes("ENCRYPTED"))throw new y("PEMToCozeKey: encrypted PEM is not supported.",s.KeyInvalid,{field:"pem"});let i=n[2].replace(/\s+/g,"");try{var r=Uint8Array.from(atob(i),a=>a.charCodeAt(0))}catch{throw new y("PEMToCozeKey: invalid PEM base64.",s.KeyInvalid,{field:"pem"})}return{label:n[1],der:r}}throw new y("PEMToCozeKey: no PEM key found.",s.KeyInvalid,{field:"pem"})}function Ie(e,t){let r=btoa(String.fromCharCode(...t)).match(/.{1,64}/g);return`-----BEGIN ${e}-----
+${r.join(`
+`)}
+-----END ${e}-----
+`}function ae(e,t){if(t+2>e.length)throw new y("DER: unexpected end of input.",s.KeyInvalid);let n=e[t],r=e[t+1],i=t+2;if(r&128){let f=r&127;if(f===0||f>4)throw new y("DER: unsupported length.",s.KeyInvalid);r=0;for(let o=0;o<f;o++)r=r*256+e[i+o];i+=f}let a=i+r;if(a>e.length)throw new y("DER: length exceeds input.",s.KeyInvalid);return{tag:n,content:e.slice(i,a),end:a}}function Te(e){let t=[];for(let n=0;n<e.content.length;){let r=ae(e.content,n);t.push(r),n=r.end}return t}function Z(e,t,n){if(e===void 0||e.tag!==t)throw new y("DER: invalid "+n+".",s.KeyInvalid);return e}function v(e,...t){let n=[];for(let a of t)n.push(...a);let r=n.length,i=[];if(r<128)i.push(r);else{let a=[];for(;r>0;r>>=8)a.unshift(r&255);i.push(128|a.length,...a)}return new Uint8Array([e,...i,...n])}function nt(e,t){if(e.length!==t.length)return!1;for(let n=0;n<e.length;n++)if(e[n]!==t[n])return!1;return!0}function Gn(e){let t=0n;for(let n of e)t=(t<<8n)+BigInt(n);return t}var Mr=["alg","iat","tmb","typ"];async function zr(e,t,n,r){if(console.log(),e=M(e),t=M(t),ce(t))throw new y("SignCoze: Cannot sign with revoked key.",s.KeyRevoked);return!u(r)&&r.setStandard===!0?e.pay=await Lt(e.pay,t,r):(e.pay.alg=t.alg,e.pay.tmb=await H(t),e.pay.iat=Jt(r)),!u(r)&&r.normalizeUnicode===!0&&(e.pay=ee(e.pay)),u(n)||(e.pay=await ve(e.pay,n)),e.sig=await Fe(JSON.stringify(e.pay),t,r),e}async function Fe(e,t,n){let r=!0;try{JSON.parse(e)}catch{r=!1}if(r&&(Yt(e),!u(n)&&n.normalizeUnicode===!0&&(e=JSON.stringify(ee(JSON.parse(e))))),lt(t.alg,n),!u(n)&&n.deterministic===!0&&_(t.alg)==S.ECDSA){if(u(t.d))throw new y("SignPay: deterministic signing requires private component d.",s.KeyInvalid,{field:"d"});let i=await F.SignBuffer(await F.FromCozeKey(t),await J(e),!0);return Ye(t.alg,m(i))}return k.SignBufferB64(await k.FromCozeKey(t),await J(e))}async function Bt(e,t,n,r){if(e=M(e),t=M(t),ce(t))throw new y("SignCozeRaw: Cannot sign with revoked key.",s.KeyRevoked);if(!u(r)&&r.setStandard===!0)e.pay=await Lt(e.pay,t,r);else{if(!u(e.pay.alg)&&e.pay.alg!==t.alg)throw new x("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.pay.alg});if(!u(e.pay.tmb)&&e.pay.tmb!==t.tmb)throw new y("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"tmb"})}return!u(r)&&r.normalizeUnicode===!0&&(e.pay=ee(e.pay)),u(n)||(e.pay=await ve(e.pay,n)),e.sig=await Fe(JSON.stringify(e.pay),t,r),e}async function Lt(e,t,n){let r=await H(t);if(!u(e.alg)&&e.alg!==t.alg)throw new x("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.alg});if(!u(e.tmb)&&e.tmb!==r)throw new y("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"tmb"});let i=e.iat;if(i===void 0)i=Jt(n);else if(n.iat!==void 0&&n.iat!==i)throw new D(`SignCozeRaw: coze.pay.iat (${i}) mismatch with opts.iat (${n.iat}).`,s.IatInvalid,{field:"iat"});let a={alg:t.alg,iat:i,tmb:r};for(let[f,o]of Object.entries(e))f in a||(a[f]=o);return a}function Jt(e){if(u(e)||e.iat===void 0)return Math.round(Date.now()/1e3);if(!Number.isSafeInteger(e.iat)||e.iat<0)throw new D("Sign: opts.iat must be a non-negative integer.",s.IatInvalid,{field:"iat"});return e.iat}async function Or(e,t,n,r){if(e=M(e),n=M(n),ce(n))throw new y("SignCryptoKey: Cannot sign with revoked key.",s.KeyRevoked);if(t.type!=="private")throw new y("SignCryptoKey: CryptoKey must be private.",s.KeyInvalid);if(await k.algFromCryptoKey(t)!==n.alg)throw new x("SignCryptoKey: CryptoKey alg mismatch with cozeKey.alg.",s.AlgMismatch,{alg:n.alg});e.alg=n.alg,e.tmb=await H(n),e.iat=Math.round(Date.now()/1e3),u(r)||(e=await ve(e,r));let i={pay:e,sig:await k.SignString(t,JSON.stringify(e))};if(!await pe(JSON.stringify(e),n,i.sig))throw new y("SignCryptoKey: CryptoKey is not the private key of cozeKey.",s.KeyMismatch);return i}async function Kt(e,t,n,r){if(typeof n=="string"){let i;return typeof e=="string"&&(i=e,e=Pe(e)),$t({pay:e,sig:n},t,r,i)}return $t(e,t,n)}async function $t(e,t,n,r){e=M(e),t=M(t);let i=t!=null;if(i&&(t=await Qe(t,e.pay.tmb)),!u(e.key))t=await Zn(e,i?t:void 0);else if(!i)throw new y("VerifyCoze: no key given and coze has no embedded key.",s.KeyInvalid,{field:"key"});if(ce(t)&&(u(n)||n.allowRevoked!==!0))throw new y("VerifyCoze: Coze key is revoked.",s.KeyRevoked);if(!u(e.pay.alg)&&e.pay.alg!==t.alg)throw new x("VerifyCoze: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.pay.alg});if(!u(e.pay.tmb)&&e.pay.tmb!==await H(t))throw new y("VerifyCoze: Coze key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"tmb"});lt(t.alg,n),C(e.sig,"sig"),C(t.x,"x");let a=e.sig,f=e.pay;if(!u(n)&&(n.normalizeUnicode===!0&&(f=ee(f)),Zt(f,n),n.acceptDER===!0&&_(t.alg)==S.ECDSA&&it(C(a),t.alg)&&(a=rt(a,t.alg)),await Gt(t.alg,a,n)))return!1;r===void 0&&(r=JSON.stringify(f));let o=await pe(r,t,a);return o&&!u(n)&&jt(f,n),o}async function Gt(e,t,n){if(u(n)||n.requireLowS!==!0||_(e)!==S.ECDSA)return!1;let r=we(t);return r.byteLength===R(e)&&!await vt(e,r)}function lt(e,t){if(u(t)||u(t.hash))return;let n=B(e);if(t.hash!==n)throw new x(`Coze: hash not valid for alg: ${t.hash} is not ${e}'s hash ${n}.`,s.HashInvalid,{alg:e})}async function Zn(e,t){let n=e.key,r=await H(n);if(!u(e.pay.tmb)&&e.pay.tmb!==r)throw new y("VerifyCoze: coze.key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"key"});if(!u(e.pay.alg)&&e.pay.alg!==n.alg)throw new x("VerifyCoze: coze.key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:n.alg});if(t!==void 0){if(await H(t)!==r)throw new y("VerifyCoze: Coze key tmb mismatch with coze.key.",s.TmbMismatch,{field:"key"});return t}return{...n,tmb:r}}function jt(e,t){if(t.maxAge===void 0&&t.notBefore===void 0&&t.notAfter===void 0)return;let n=e.iat,r={field:"iat",verified:!0};if(!Number.isSafeInteger(n)||n<0)throw new N("VerifyCoze: pay.iat must be a non-negative integer when time options are set.",s.IatInvalid,r);let i=t.clockSkew===void 0?60:t.clockSkew,a=Date.now()/1e3;if(t.maxAge!==void 0){if(n+t.maxAge+i<a)throw new N(`VerifyCoze: coze expired: iat ${n} is older than maxAge ${t.maxAge}.`,s.Expired,r);if(n-i>a)throw new N(`VerifyCoze: coze not yet valid: iat ${n} is in the future.`,s.NotYetValid,r)}if(t.notBefore!==void 0&&n+i<t.notBefore)throw new N(`VerifyCoze: coze not yet valid: iat ${n} is before notBefore ${t.notBefore}.`,s.NotYetValid,r);if(t.notAfter!==void 0&&n-i>t.notAfter)throw new N(`VerifyCoze: coze expired: iat ${n} is after notAfter ${t.notAfter}.`,s.Expired,r)}function Zt(e,t){let n=Object.keys(e),r=[];if(Array.isArray(t.canon)){r=t.canon.flatMap(f=>typeof f=="string"?[f]:Object.keys(f));let a=n.filter(f=>!r.includes(f));if(a.length>0)throw new Q("VerifyCoze: pay has extra field(s) not in canon: "+a.join(", "),s.CanonExtra,{fields:a})}u(t.canonContains)||(r=r.concat(t.canonContains));let i=r.filter(a=>!n.includes(a));if(i.length>0)throw i=[...new Set(i)],new Q("VerifyCoze: pay missing field(s) required by canon: "+i.join(", "),s.CanonMissing,{fields:i})}async function Vr(e,t,n){let r={verified:!1,meta:null,checks:[]},i={},a=function(h,p,P){let j={name:h,status:p};u(P)||(j.message=P),r.checks.push(j),i[h]=p},f=(...h)=>h.every(p=>i[p]==="pass"),o=function(h,...p){a(h,"skip","Requires passing "+p.filter(P=>i[P]!=="pass").join(", ")+".")};u(n)&&(n={});try{e=M(e),e===null||typeof e!="object"||e.pay===null||typeof e.pay!="object"||Array.isArray(e.pay)?a("pay_parsed","fail","coze.pay must be an object."):a("pay_parsed","pass")}catch(h){a("pay_parsed","fail",h.message)}let c,d=!1;if(!f("pay_parsed"))o("key_found","pay_parsed");else try{t=M(t),t!=null&&t!==""?c=await Qe(t,e.pay.tmb):u(e.key)||(c=e.key,d=!0),u(c)?a("key_found","fail","No key given and coze has no embedded key."):a("key_found","pass",d?"Embedded key.":"")}catch(h){a("key_found","fail",h.message)}if(!f("pay_parsed"))o("meta","pay_parsed");else try{r.meta=await at(e,u(e.pay.alg)&&!u(c)?c.alg:void 0),a("meta","pass")}catch(h){a("meta","fail",h.message)}if(f("key_found")?u(e.pay.alg)?a("alg_matches","skip","pay has no alg."):e.pay.alg!==c.alg?a("alg_matches","fail",`pay.alg "${e.pay.alg}" is not the key's alg "${c.alg}".`):a("alg_matches","pass"):o("alg_matches","pay_parsed","key_found"),!f("key_found"))o("tmb_matches","pay_parsed","key_found");else try{let h=await H(c),p=[];!u(e.pay.tmb)&&e.pay.tmb!==h&&p.push(`pay.tmb "${e.pay.tmb}" is not the key's thumbprint "${h}".`),!d&&!u(e.key)&&await H(e.key)!==h&&p.push("Given key is not the embedded key."),p.length>0?a("tmb_matches","fail",p.join("  ")):a("tmb_matches","pass",u(e.pay.tmb)?"pay has no tmb.":"")}catch(h){a("tmb_matches","fail",h.message)}if(f("key_found")?ce(c)?n.allowRevoked===!0?a("not_revoked","pass","Key is revoked, allowed by opts.allowRevoked."):a("not_revoked","fail","Key is revoked."):a("not_revoked","pass"):o("not_revoked","pay_parsed","key_found"),u(n.hash))a("hash","skip","opts.hash not given.");else if(!f("key_found"))o("hash","pay_parsed","key_found");else try{lt(c.alg,n),a("hash","pass")}catch(h){a("hash","fail",h.message)}let g;if(!f("pay_parsed"))o("sig_b64ut","pay_parsed");else if(u(e.sig))a("sig_b64ut","fail","coze has no sig.");else try{C(e.sig,"sig"),g=e.sig,a("sig_b64ut","pass")}catch(h){a("sig_b64ut","fail",h.message)}let A=!u(c)&&!u(c.alg)?c.alg:f("pay_parsed")?e.pay.alg:void 0;if(!f("sig_b64ut"))o("sig_size","sig_b64ut");else try{n.acceptDER===!0&&_(A)==S.ECDSA&&it(C(g),A)&&(g=rt(g,A));let h=C(g).length;h!==R(A)?a("sig_size","fail",`sig is ${h} bytes, ${A} requires ${R(A)}.`):a("sig_size","pass")}catch(h){a("sig_size","fail",h.message)}let b=f("pay_parsed")?e.pay:void 0;if(!f("key_found","sig_size")||i.alg_matches==="fail")o("signature",...i.alg_matches==="fail"?["key_found","alg_matches","sig_size"]:["key_found","sig_size"]);else try{n.normalizeUnicode===!0&&(b=ee(b)),await Gt(c.alg,g,n)?a("signature","fail","High-S signature refused by opts.requireLowS."):await pe(JSON.stringify(b),c,g)?a("signature","pass"):a("signature","fail","Signature did not verify.")}catch(h){a("signature","fail",h.message)}if(u(n.canon)&&u(n.canonContains))a("canon","skip","opts.canon and opts.canonContains not given.");else if(!f("pay_parsed"))o("canon","pay_parsed");else try{Zt(b,n),a("canon","pass")}catch(h){a("canon","fail",h.message)}if(n.maxAge===void 0&&n.notBefore===void 0&&n.notAfter===void 0)a("iat_window","skip","Time options not given.");else if(!f("pay_parsed"))o("iat_window","pay_parsed");else try{jt(b,n),a("iat_window","pass")}catch(h){a("iat_window","fail",h.message)}return r.verified=f("signature")&&r.checks.every(h=>h.status!=="fail"),r}async function pe(e,t,n){return k.VerifyMsg(t.alg,await k.FromCozeKey(t,!0),e,n)}async function $r(e,t,n){if(ce(t))throw new y("SignDig: Cannot sign with revoked key.",s.KeyRevoked);let r=Xt("SignDig",e,t,n),i=await F.SignDigest(await F.FromCozeKey(t),r);return Ye(e,m(i))}async function Lr(e,t,n,r){let i=Xt("VerifyDig",e,t,n),a=we(r);return a.byteLength!==R(e)?!1:F.VerifyDigest(await F.FromCozeKey(t,!0),i,a)}function Xt(e,t,n,r){if(t!==n.alg)throw new x(`${e}: alg (${t}) mismatch with cozeKey.alg (${n.alg}).`,s.AlgMismatch,{alg:t});if(_(t)!==S.ECDSA)throw new x(`${e}: only ECDSA algs are supported.`,s.AlgUnsupported,{alg:t});if(r instanceof Uint8Array||(r.replace(/^0x/i,"").length===Y(t)*2?r=Oe(r):r=C(r)),r.length!==Y(t))throw new D(`${e}: incorrect digest size for ${t}: ${r.length} bytes, expected ${Y(t)}.`,s.DigSize,{alg:t});return r}async function at(e,t){if(e=M(e),u(e.pay))throw new N("Meta: coze.pay must exist.",s.PayMissing,{field:"pay"});let n={},r=t,i="";if(!u(t)&&typeof t=="object"&&(r=t.alg,i=t.tmb),u(e.pay.alg))u(r)||(n.alg=r);else{if(!u(r)&&r!==e.pay.alg)throw new x(`Meta: alg mismatch: coze.pay.alg (${e.pay.alg}) and parameter alg (${r}) do not match.`,s.AlgMismatch,{alg:r});n.alg=e.pay.alg}if(u(e.pay.iat)||(n.iat=e.pay.iat),u(e.pay.tmb)?u(i)||(n.tmb=i):n.tmb=e.pay.tmb,u(e.pay.typ)||(n.typ=e.pay.typ),n.can=await At(e.pay),u(n.alg)||(n.cad=await me(e.pay,B(n.alg))),u(e.sig)||(n.sig=e.sig),!u(n.alg)&&!u(e.sig)&&(n.czd=await me({cad:n.cad,sig:n.sig},B(n.alg))),!u(n.alg)&&Array.isArray(e.sigs)){n.sigs=[];for(let a of e.sigs)n.sigs.push({tmb:a.tmb,sig:a.sig,czd:await me({cad:n.cad,sig:a.sig},B(n.alg))})}return n}function Xn(e){e=M(e);let t={...e};return u(e.key)||(t.key=Dt(e.key)),!u(e.coze)&&typeof e.coze=="object"&&(t.coze=Xn(e.coze)),t}function Jr(e,t){if(typeof e=="string"){let n=e;if(e=Pe(n),JSON.stringify(e)!==n)throw new D("Attach: pay is not the compact serialization of pay, so sig is not of the coze's pay.  Verify with Verify(pay, cozeKey, sig) instead.",s.JSONInvalid,{field:"pay"})}return{pay:e,sig:t}}function Gr(e){if(e=M(e),u(e.pay))throw new N("Detach: coze.pay must exist.",s.PayMissing,{field:"pay"});return{payCompact:JSON.stringify(e.pay),sig:e.sig}}async function Yn(e,t){if(e=M(e),t=M(t),u(e)||u(e.pay)||u(t)||u(t.pay))throw new N("Equal: coze.pay must exist.",s.PayMissing,{field:"pay"});if(u(e.sig)!==u(t.sig))return!1;if(u(e.pay.alg)||e.pay.alg!==t.pay.alg)return e.sig===t.sig&&await Be(e.pay)===await Be(t.pay);let n=await at(e),r=await at(t);return u(e.sig)?n.cad===r.cad:n.czd===r.czd}async function jr(e,t){return e=M(e),t=M(t),Object.keys(e).sort().join()!==Object.keys(t).sort().join()||!u(e.key)&&await H(e.key)!==await H(t.key)?!1:Yn(e,t)}function Pe(e){let t=JSON.parse(e);return Yt(e),t}function Yt(e){let t=[];for(let n=0;n<e.length;n++){let r=t[t.length-1];switch(e[n]){case"{":t.push({names:new Set,expectName:!0});break;case"[":t.push(null);break;case"}":case"]":t.pop();break;case",":r&&(r.expectName=!0);break;case'"':{let i=n;for(n++;e[n]!=='"';n++)e[n]==="\\"&&n++;if(r&&r.expectName){let a=JSON.parse(e.slice(i,n+1));if(r.names.has(a))throw new D(`Coze: duplicate JSON field "${a}"`,s.DuplicateField,{field:a});r.names.add(a),r.expectName=!1}break}}}}var le={Coze:"coze",Key:"key",Pay:"pay",Array:"array",Unknown:"unknown"};function Zr(e){if(typeof e=="string")try{e=Pe(e)}catch(n){throw n instanceof SyntaxError?Wn(e,n):n}if(Array.isArray(e))return le.Array;if(typeof e!="object"||e===null)return le.Unknown;let t=n=>typeof n=="object"&&n!==null&&!Array.isArray(n);return t(e.coze)&&t(e.coze.pay)&&typeof e.coze.sig=="string"?le.Coze:t(e.pay)?typeof e.sig=="string"?le.Coze:le.Pay:e.sig!==void 0?le.Unknown:typeof e.alg=="string"&&(typeof e.x=="string"||typeof e.d=="string")?le.Key:le.Pay}function Wn(e,t){let n=(a,f)=>new D(`Detect: ${a} at position ${f}.`,s.JSONInvalid,{position:f});if(e.charCodeAt(0)===65279)return n("byte order mark (BOM)",0);let r=!1;for(let a=0;a<e.length;a++){let f=e[a];if(r){f==="\\"?a++:f==='"'&&(r=!1);continue}if(f==='"')r=!0;else if(f===","){let o=e.slice(a+1).search(/[^ \t\n\r]/);if(o!==-1&&(e[a+1+o]==="}"||e[a+1+o]==="]"))return n("trailing comma",a)}else if(/[\s\u200B-\u200D\u2060]/.test(f)&&!/[ \t\n\r]/.test(f)){let o=f.charCodeAt(0).toString(16).toUpperCase().padStart(4,"0");return n(`non JSON whitespace (U+${o})`,a)}}let i=/position (\d+)/.exec(t.message);return i!==null?n("invalid JSON",Number(i[1])):new D("Detect: invalid JSON: "+t.message,s.JSONInvalid)}function M(e){return typeof e=="string"?Pe(e):e}export{nr as AlgFromCOSE,tr as AlgFromJOSE,l as Algs,m as ArrayBufferTo64ut,Cr as AssertPublic,Jr as Attach,fe as B64Error,rn as B64Lenient,C as B64ToUint8Array,we as B64uToArrayBuffer,ln as B64utToHex,yn as COSEAlg,At as Canon,ve as Canonical,Fn as CanonicalHash,me as CanonicalHash64,Be as CanonicalS,Yt as CheckDuplicates,hr as ClearKeyCache,Ir as Correct,x as CozeAlgError,Q as CozeCanonError,D as CozeError,y as CozeKeyError,St as CozeKeyToJWK,Kr as CozeKeyToPEM,N as CozeVerifyError,k as CryptoKey,oe as Curve,ft as CurveHalfOrder,dn as CurveOID,De as CurveOrder,V as Curves,rt as DERToSig,$ as DSize,Gr as Detach,Zr as Detect,vr as Diagnose,W as Digest,dt as DigestFiles,ar as DigestPayField,lr as DigestPayFile,F as ECDSA,Yn as Equal,jr as EqualStrict,s as ErrCodes,Ce as FamAlgs,sn as Family,S as GenAlgs,_ as Genus,_e as HMAC,An as Hash,B as HashAlg,Y as HashSize,hn as HashStream,an as HexToB64ut,Oe as HexToUint8Array,le as InputTypes,it as IsDERSig,Er as IsPrivate,ce as IsRevoked,vt as IsSigLowS,cn as JOSEAlg,gn as JOSECrv,Dn as JWKToCozeKey,Qe as LookupKey,fr as MatchPayFile,at as Meta,mr as NewKey,pr as NewKeyFromPassword,zn as NewKeyFromSeed,ee as NormalizeUnicode,Br as PEMToCozeKey,Le as Params,Pe as ParseStrict,Mr as PayCanon,Dt as PublicKey,Tr as Revoke,J as SToArrayBuffer,Xn as ScrubCoze,R as SigSize,Ur as SigToDER,Ye as SigToLowS,zr as Sign,Bt as SignCozeRaw,Or as SignCryptoKey,$r as SignDig,Fe as SignPay,H as Thumbprint,Sr as ThumbprintMatch,Nn as TmbCanon,He as Uint8ArrayToHex,on as Use,se as Uses,Fr as Valid,Kt as Verify,Lr as VerifyDig,Vr as VerifyMeta,pe as VerifyPay,kr as VerifyRevoke,G as XSize,u as isEmpty};
 //# sourceMappingURL=coze.min.js.map
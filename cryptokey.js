@@ -1,15 +1,26 @@
 "use strict";
 
-import * as Coze from './coze.js';
+import * as Conv from './conversion.js';
 import * as Alg from './alg.js';
 import * as CZK from './key.js';
+import {
+	ECDSA
+} from './ecdsa.js';
 import {
 	isEmpty
-} from './coze.js';
+} from './conversion.js';
+import {
+	CozeAlgError,
+	CozeKeyError,
+	ErrCodes,
+} from './error.js';
 
 
 export {
 	CryptoKey,
+	ClearKeyCache,
+	CozeKeyToJWK,
+	JWKToCozeKey,
 	SigToLowS,
 	IsSigLowS,
 };
@@ -24,6 +35,19 @@ export {
 @typedef {import('./typedef.js').Msg}      Msg
 */
 
+// keyCache caches imported CryptoKeys by Coze key object.  Each entry holds
+// separate "sign" and "verify" CryptoKeys, each with the `alg`, `x`, and `d`
+// they were imported from so that mutating the Coze key invalidates the entry.
+// WeakMap so that cached keys are garbage collected with their Coze key.
+let keyCache = new WeakMap();
+
+/**
+ClearKeyCache clears all cached CryptoKeys.  See CryptoKey.FromCozeKey.
+@returns {void}
+*/
+function ClearKeyCache() {
+	keyCache = new WeakMap();
+}
 
 var CryptoKey = {
 	/**
@@ -37,27 +61,51 @@ var CryptoKey = {
 		if (isEmpty(alg)) {
 			alg = Alg.Algs.ES256;
 		}
-		// Javascript only supports ECDSA, and doesn't support ES192 or ES224.  See
+		// Javascript supports ECDSA, but doesn't support ES192 or ES224.  See
 		// https://developer.mozilla.org/en-US/docs/Web/API/EcdsaParams
+		// ES224 falls back to the Javascript ECDSA implementation. Ed25519 is
+		// supported only by newer browsers, in secure contexts.
 		switch (alg) {
+			case Alg.Algs.ES224:
+				return ECDSA.New(alg);
 			case Alg.Algs.ES256:
 			case Alg.Algs.ES384:
 			case Alg.Algs.ES512:
-				return await window.crypto.subtle.generateKey({
+				return await crypto.subtle.generateKey({
 						name: Alg.GenAlgs.ECDSA,
 						namedCurve: Alg.Curve(alg)
 					},
 					true,
 					["sign", "verify"]
 				);
+			case Alg.Algs.Ed25519:
+				try {
+					return await crypto.subtle.generateKey({
+							name: Alg.Algs.Ed25519,
+						},
+						true,
+						["sign", "verify"]
+					);
+				} catch (e) {
+					throw unsupportedErr("CryptoKey.New", alg, e);
+				}
 			default:
-				throw new Error("CryptoKey.New: Unsupported key algorithm:" + alg);
+				throw new CozeAlgError("CryptoKey.New: Unsupported key algorithm:" + alg, ErrCodes.AlgUnsupported, {
+					alg: alg
+				});
 		}
 	},
 
 	/**
 	FromCozeKey returns a Javascript CryptoKey from a Coze Key.  Only supports
-	ECDSA because of Crypto.subtle limitations.  Throws error on invalid keys.
+	ECDSA and Ed25519 because of Crypto.subtle limitations.  Since
+	SubtleCrypto does not implement P-224, ES224 keys are Javascript ECDSA keys
+	that are used in place of a CryptoKey.  (See `ecdsa.js`.)  Throws error on
+	invalid keys.
+
+	Imported keys are cached by Coze key object so that repeated operations with
+	the same key object do not import again.  The cache is invalidated if `alg`,
+	`x`, or `d` of the Coze key changes.  See ClearKeyCache.
 	https://developer.mozilla.org/en-US/docs/Web/API/SubtleCrypto/importKey#JSON_Web_Key
 	@param   {Key}        cozeKey          Coze key.
 	@param   {boolean}    [public=false]   Return only a public key.
@@ -65,40 +113,33 @@ var CryptoKey = {
 	@throws  {error}                Error, SyntaxError, DOMException, TypeError
 	*/
 	FromCozeKey: async function(cozeKey, onlyPublic) {
-		if (Alg.Genus(cozeKey.alg) != Alg.GenAlgs.ECDSA) {
-			throw new Error("CryptoKey.FromCozeKey: unsupported CryptoKey algorithm: " + cozeKey.alg);
+		let usage = (isEmpty(cozeKey.d) || onlyPublic) ? "verify" : "sign";
+		let entry = keyCache.get(cozeKey);
+		if (entry === undefined) {
+			entry = {};
+			keyCache.set(cozeKey, entry);
 		}
-
-		// Create a new JWK that can be used to create and "import" a CryptoKey
-		var jwk = {};
-		jwk.use = Alg.Uses.Sig;
-		jwk.crv = Alg.Curve(cozeKey.alg);
-		jwk.kty = Alg.FamAlgs.EC;
-
-		let half = Alg.XSize(cozeKey.alg) / 2;
-		let xyab = await Coze.B64ToUint8Array(cozeKey.x);
-		jwk.x = await Coze.ArrayBufferTo64ut(xyab.slice(0, half));
-		jwk.y = await Coze.ArrayBufferTo64ut(xyab.slice(half));
-
-		// Public CryptoKey "crypto.subtle.importKey" needs key use to be "verify"
-		// even though this doesn't exist in JWK RFC or IANA registry. (2021/05/12)
-		// Gawd help us.  Private CryptoKey needs key `use` to be "sign".
-		if (isEmpty(cozeKey.d) || onlyPublic) {
-			var signOrVerify = "verify";
-		} else {
-			signOrVerify = "sign";
-			jwk.d = cozeKey.d;
+		let cached = entry[usage];
+		if (cached !== undefined && cached.alg === cozeKey.alg && cached.x === cozeKey.x && cached.d === cozeKey.d) {
+			return cached.key;
 		}
 
-		return await crypto.subtle.importKey(
-			"jwk",
-			jwk, {
-				name: Alg.GenAlgs.ECDSA,
-				namedCurve: jwk.crv,
-			},
-			true,
-			[signOrVerify]
-		);
+		// The promise is cached so that concurrent calls import only once.
+		let key = importCozeKey(cozeKey, onlyPublic);
+		entry[usage] = {
+			alg: cozeKey.alg,
+			x: cozeKey.x,
+			d: cozeKey.d,
+			key: key,
+		};
+		try {
+			return await key;
+		} catch (e) {
+			if (entry[usage] !== undefined && entry[usage].key === key) {
+				delete entry[usage]; // Do not cache errors.
+			}
+			throw e;
+		}
 	},
 
 	/**
@@ -129,8 +170,8 @@ var CryptoKey = {
 	with the alg.  This is unlike JOSE which appears to use SHA-256 even for
 	keys that don't use that algorithm.
 	
-	This function currently only supports ECDSA (ES256. ES384, ES512) as
-	crypto.subtle only supports these ECDSA algorithms. From Cryptokey,
+	This function currently only supports ECDSA (ES256. ES384, ES512) and
+	Ed25519 as crypto.subtle only supports these algorithms. From Cryptokey,
 	`exported` key output should is in the following form:
 	
 	{
@@ -179,31 +220,17 @@ var CryptoKey = {
 	@throws  {error}
 	*/
 	ToCozeKey: async function(cryptoKey) {
-		let exported = await window.crypto.subtle.exportKey(
+		if (ECDSA.IsKey(cryptoKey)) {
+			let k = ECDSA.ToCozeKey(cryptoKey);
+			k.tmb = await CZK.Thumbprint(k);
+			return k;
+		}
+
+		let exported = await crypto.subtle.exportKey(
 			"jwk",
 			cryptoKey
 		);
-
-		var czk = {};
-		czk.alg = await CryptoKey.algFromCrv(exported.crv);
-		// Concatenate x and y, but concatenation is done at the byte level, so:
-		// unencode, concatenated, and encoded. 
-		let xui8 = Coze.B64ToUint8Array(exported.x);
-		let yui8 = Coze.B64ToUint8Array(exported.y);
-		var xyui8 = new Uint8Array([
-			...xui8,
-			...yui8,
-		]);
-		czk.x = Coze.ArrayBufferTo64ut(xyui8.buffer);
-
-		// Only private ECDSA keys have `d`.
-		if (exported.hasOwnProperty('d')) {
-			czk.d = exported.d;
-		}
-
-		czk.tmb = await CZK.Thumbprint(czk);
-		// console.log("exported: " + JSON.stringify(exported), "Coze Key: " + JSON.stringify(czk)); // Debugging
-		return czk;
+		return JWKToCozeKey(exported);
 	},
 
 	/**
@@ -218,18 +245,21 @@ var CryptoKey = {
 	@throws  {error}
 	*/
 	SignBuffer: async function(cryptoKey, arrayBuffer) {
-		let alg = await CryptoKey.algFromCrv(cryptoKey.algorithm.namedCurve);
-		let sig = await window.crypto.subtle.sign({
-				name: Alg.GenAlgs.ECDSA,
-				hash: {
-					name: Alg.HashAlg(alg)
-				},
-			},
-			cryptoKey,
-			arrayBuffer
-		);
+		let alg = await CryptoKey.algFromCryptoKey(cryptoKey);
+		if (ECDSA.IsKey(cryptoKey)) {
+			var sig = await ECDSA.SignBuffer(cryptoKey, arrayBuffer);
+		} else {
+			sig = await crypto.subtle.sign(
+				subtleParams(alg),
+				cryptoKey,
+				arrayBuffer
+			);
+		}
 
-		sig = sigToLowSArrayBuffer(alg, sig);
+		// Low-S only applies to ECDSA.  Ed25519 signatures are already canonical.
+		if (Alg.Genus(alg) == Alg.GenAlgs.ECDSA) {
+			sig = sigToLowSArrayBuffer(alg, sig);
+		}
 		return sig;
 	},
 
@@ -243,7 +273,7 @@ var CryptoKey = {
 	@returns {B64}
 	*/
 	SignBufferB64: async function(cryptoKey, arrayBuffer) {
-		return await Coze.ArrayBufferTo64ut(await CryptoKey.SignBuffer(cryptoKey, arrayBuffer));
+		return await Conv.ArrayBufferTo64ut(await CryptoKey.SignBuffer(cryptoKey, arrayBuffer));
 	},
 
 	/**
@@ -254,13 +284,15 @@ var CryptoKey = {
 	@returns {B64}
 	*/
 	SignString: async function(cryptoKey, utf8) {
-		return await CryptoKey.SignBufferB64(cryptoKey, await Coze.SToArrayBuffer(utf8));
+		return await CryptoKey.SignBufferB64(cryptoKey, await Conv.SToArrayBuffer(utf8));
 	},
 
 	/**
 	VerifyArrayBuffer verifies an ArrayBuffer msg with an ArrayBuffer sig and
 	Javascript CryptoKey.
 	Returns whether or not message is verified by the given key and signature.
+	For interoperability, ECDSA signatures are accepted in both low-S and
+	high-S form.  See VerifyOpts.requireLowS for refusing high-S.
 	@param   {Alg}         alg
 	@param   {CryptoKey}   cryptoKey           Javascript CryptoKey.
 	@param   {ArrayBuffer} sig                 Signature.
@@ -268,20 +300,14 @@ var CryptoKey = {
 	@returns {boolean}
 	*/
 	VerifyArrayBuffer: async function(alg, cryptoKey, msg, sig) {
-		// Currently, CozeJS is only ECDSA.  For ECDSA, only accept low-S
-		// signatures.  
-		if (!(await IsSigLowS(alg, sig))) {
-			return false;
+		if (ECDSA.IsKey(cryptoKey)) {
+			return ECDSA.VerifyBuffer(cryptoKey, msg, sig);
 		}
 
 		// Guarantee key is not private to appease Javascript 😔:
 		await CryptoKey.ToPublic(cryptoKey);
-		return await window.crypto.subtle.verify({
-				name: Alg.GenAlgs.ECDSA,
-				hash: {
-					name: await CryptoKey.GetSignHashAlgoFromCryptoKey(cryptoKey)
-				},
-			},
+		return await crypto.subtle.verify(
+			subtleParams(await CryptoKey.algFromCryptoKey(cryptoKey)),
 			cryptoKey,
 			sig,
 			msg);
@@ -297,7 +323,7 @@ var CryptoKey = {
 	@returns {boolean}
 	*/
 	VerifyMsg: async function(alg, cryptoKey, msg, sig) {
-		return CryptoKey.VerifyArrayBuffer(alg, cryptoKey, await Coze.SToArrayBuffer(msg), await Coze.B64uToArrayBuffer(sig));
+		return CryptoKey.VerifyArrayBuffer(alg, cryptoKey, await Conv.SToArrayBuffer(msg), await Conv.B64uToArrayBuffer(sig));
 	},
 
 	/**
@@ -324,11 +350,27 @@ var CryptoKey = {
 	@throws  {error}                Fails if alg is not supported.
 	*/
 	GetSignHashAlgoFromCryptoKey: async function(cryptoKey) {
-		return Alg.HashAlg(await CryptoKey.algFromCrv(cryptoKey.algorithm.namedCurve));
+		return Alg.HashAlg(await CryptoKey.algFromCryptoKey(cryptoKey));
 	},
 
 	/**
-	algFromCrv returns a SEAlg from the given curve.
+	algFromCryptoKey returns the Coze alg for the given CryptoKey.  ECDSA
+	CryptoKeys denote the curve while Ed25519 CryptoKeys denote the algorithm
+	name.
+	@param   {CryptoKey} cryptoKey
+	@returns {Alg}
+	@throws  {error}                Fails if alg is not supported.
+	*/
+	algFromCryptoKey: async function(cryptoKey) {
+		if (cryptoKey.algorithm.name === Alg.Algs.Ed25519) {
+			return Alg.Algs.Ed25519;
+		}
+		return CryptoKey.algFromCrv(cryptoKey.algorithm.namedCurve);
+	},
+
+	/**
+	algFromCrv returns a SEAlg from the given curve.  The JWK curve "Ed25519"
+	is also accepted.
 	Fails if curve is not supported.
 	@param   {Crv}     src    Curve type. E.g. "P-256".
 	@returns {Alg}
@@ -336,8 +378,11 @@ var CryptoKey = {
 	*/
 	algFromCrv: async function(crv) {
 		switch (crv) {
+			case Alg.Algs.Ed25519: // JWK "crv" for Ed25519 is "Ed25519".
+				var alg = Alg.Algs.Ed25519;
+				break;
 			case Alg.Curves.P224:
-				var alg = Alg.Algs.ES224;
+				alg = Alg.Algs.ES224;
 				break;
 			case Alg.Curves.P256:
 				alg = Alg.Algs.ES256
@@ -349,13 +394,297 @@ var CryptoKey = {
 				alg = Alg.Algs.ES512;
 				break;
 			default:
-				throw new Error("CryptoKey.ToCozeKey: Unsupported key algorithm.");
+				throw new CozeAlgError("CryptoKey.ToCozeKey: Unsupported key algorithm.", ErrCodes.AlgUnsupported, {
+					crv: crv
+				});
 		}
 		return alg;
 	}
 }; // End CryptoKey
 
 
+/**
+CozeKeyToJWK returns a JWK (RFC 7517) from a Coze key.  Supports ES256, ES384,
+ES512, and Ed25519 (RFC 8037).  For ECDSA, Coze's `x` is split into the JWK
+coordinates `x` and `y`, each of the full coordinate size (e.g. 66 bytes with
+leading zeros for ES512/P-521) as required by RFC 7518.  The JWK `kid` is not
+set since Coze's `kid` is a non-programmatic label and Coze identifies keys by
+`tmb`.  `d` is only set for private keys.
+@param   {Key}     cozeKey
+@returns {object}  JWK
+@throws  {error}
+*/
+function CozeKeyToJWK(cozeKey) {
+	if (isEmpty(cozeKey.x)) {
+		throw new CozeKeyError("CozeKeyToJWK: key x must be set.", ErrCodes.KeyInvalid, {
+			field: "x"
+		});
+	}
+	var jwk = {};
+	switch (cozeKey.alg) {
+		case Alg.Algs.Ed25519:
+			jwk.kty = "OKP";
+			jwk.crv = Alg.Algs.Ed25519;
+			jwk.alg = "EdDSA";
+			jwk.use = Alg.Uses.Sig;
+			jwk.x = cozeKey.x;
+			break;
+		case Alg.Algs.ES256:
+		case Alg.Algs.ES384:
+		case Alg.Algs.ES512: {
+			jwk.kty = Alg.FamAlgs.EC;
+			jwk.crv = Alg.Curve(cozeKey.alg);
+			jwk.alg = cozeKey.alg;
+			jwk.use = Alg.Uses.Sig;
+			let half = Alg.XSize(cozeKey.alg) / 2;
+			let xy = Conv.B64ToUint8Array(cozeKey.x);
+			if (xy.length !== half * 2) {
+				throw new CozeKeyError("CozeKeyToJWK: incorrect x size for " + cozeKey.alg + ".", ErrCodes.KeyInvalid, {
+					field: "x"
+				});
+			}
+			jwk.x = Conv.ArrayBufferTo64ut(xy.slice(0, half));
+			jwk.y = Conv.ArrayBufferTo64ut(xy.slice(half));
+			break;
+		}
+		default:
+			throw new CozeAlgError("CozeKeyToJWK: unsupported alg: " + cozeKey.alg, ErrCodes.AlgUnsupported, {
+				alg: cozeKey.alg
+			});
+	}
+	if (!isEmpty(cozeKey.d)) {
+		jwk.d = cozeKey.d;
+	}
+	return jwk;
+}
+
+/**
+JWKToCozeKey returns a Coze key with `tmb` from a JWK.  Supports the curves
+P-256, P-384, P-521, and Ed25519.  JWKs with unsupported `kty` or `crv`, a
+mismatched `alg`, or missing coordinates are refused.  ECDSA coordinates and
+`d` shorter than the curve's size (e.g. from libraries that strip leading zeros
+for P-521) are left padded with zeros.  JWK `kid` is ignored since Coze
+identifies keys by `tmb`.
+@param   {object}  jwk
+@returns {Key}
+@throws  {error}
+*/
+async function JWKToCozeKey(jwk) {
+	let alg;
+	switch (jwk.crv) {
+		case Alg.Algs.Ed25519:
+			if (jwk.kty !== "OKP") {
+				throw new CozeKeyError("JWKToCozeKey: kty must be OKP for Ed25519.", ErrCodes.KeyInvalid, {
+					field: "kty"
+				});
+			}
+			alg = Alg.Algs.Ed25519;
+			break;
+		case Alg.Curves.P256:
+		case Alg.Curves.P384:
+		case Alg.Curves.P521:
+			if (jwk.kty !== Alg.FamAlgs.EC) {
+				throw new CozeKeyError("JWKToCozeKey: kty must be EC for curve " + jwk.crv + ".", ErrCodes.KeyInvalid, {
+					field: "kty"
+				});
+			}
+			alg = await CryptoKey.algFromCrv(jwk.crv);
+			break;
+		default:
+			throw new CozeAlgError("JWKToCozeKey: unsupported crv: " + jwk.crv, ErrCodes.AlgUnsupported, {
+				alg: jwk.crv
+			});
+	}
+	if (!isEmpty(jwk.alg) && jwk.alg !== alg && !(alg === Alg.Algs.Ed25519 && jwk.alg === "EdDSA")) {
+		throw new CozeAlgError("JWKToCozeKey: JWK alg " + jwk.alg + " mismatch with crv " + jwk.crv + ".", ErrCodes.AlgMismatch, {
+			alg: jwk.alg
+		});
+	}
+
+	var czk = {
+		alg: alg,
+	};
+	if (isEmpty(jwk.x)) {
+		throw new CozeKeyError("JWKToCozeKey: JWK x must be set.", ErrCodes.KeyInvalid, {
+			field: "x"
+		});
+	}
+	if (alg === Alg.Algs.Ed25519) {
+		// Ed25519 `x` is the 32 byte public key and has no `y`.
+		if (Conv.B64ToUint8Array(jwk.x).length !== Alg.XSize(alg)) {
+			throw new CozeKeyError("JWKToCozeKey: incorrect x size for Ed25519.", ErrCodes.KeyInvalid, {
+				field: "x"
+			});
+		}
+		czk.x = jwk.x;
+		if (!isEmpty(jwk.d)) {
+			czk.d = jwk.d;
+		}
+	} else {
+		if (isEmpty(jwk.y)) {
+			throw new CozeKeyError("JWKToCozeKey: JWK y must be set.", ErrCodes.KeyInvalid, {
+				field: "y"
+			});
+		}
+		// Concatenate x and y, but concatenation is done at the byte level, so:
+		// unencode, concatenated, and encoded.
+		let half = Alg.XSize(alg) / 2;
+		czk.x = Conv.ArrayBufferTo64ut(concatBytes(
+			padBytes("x", half, Conv.B64ToUint8Array(jwk.x)),
+			padBytes("y", half, Conv.B64ToUint8Array(jwk.y)),
+		).buffer);
+		// Only private keys have `d`.
+		if (!isEmpty(jwk.d)) {
+			czk.d = Conv.ArrayBufferTo64ut(padBytes("d", Alg.DSize(alg), Conv.B64ToUint8Array(jwk.d)).buffer);
+		}
+	}
+
+	czk.tmb = await CZK.Thumbprint(czk);
+	return czk;
+}
+
+
+/**
+fromCozeKeyEd25519 returns a Javascript CryptoKey from an Ed25519 Coze key.
+Public keys are imported as "raw" and private keys as JWK since "raw" only
+supports public keys.
+@param   {Key}        cozeKey          Ed25519 Coze key.
+@param   {boolean}    [onlyPublic]     Return only a public key.
+@returns {CryptoKey}
+@throws  {error}                       Fails if Ed25519 isn't supported.
+*/
+async function fromCozeKeyEd25519(cozeKey, onlyPublic) {
+	let params = {
+		name: Alg.Algs.Ed25519
+	};
+	try {
+		if (isEmpty(cozeKey.d) || onlyPublic) {
+			return await crypto.subtle.importKey(
+				"raw",
+				Conv.B64ToUint8Array(cozeKey.x),
+				params,
+				true,
+				["verify"]
+			);
+		}
+		return await crypto.subtle.importKey("jwk",
+			CozeKeyToJWK(cozeKey),
+			params,
+			true,
+			["sign"]
+		);
+	} catch (e) {
+		throw unsupportedErr("CryptoKey.FromCozeKey", cozeKey.alg, e);
+	}
+}
+
+/**
+importCozeKey imports a Coze key as a CryptoKey (or Javascript ECDSA key for
+ES224) without caching.  See CryptoKey.FromCozeKey.
+@param   {Key}        cozeKey          Coze key.
+@param   {boolean}    [public=false]   Return only a public key.
+@returns {CryptoKey}
+@throws  {error}                Error, SyntaxError, DOMException, TypeError
+*/
+async function importCozeKey(cozeKey, onlyPublic) {
+	if (cozeKey.alg === Alg.Algs.Ed25519) {
+		return fromCozeKeyEd25519(cozeKey, onlyPublic);
+	}
+	if (cozeKey.alg === Alg.Algs.ES224) {
+		return ECDSA.FromCozeKey(cozeKey, onlyPublic);
+	}
+	if (Alg.Genus(cozeKey.alg) != Alg.GenAlgs.ECDSA) {
+		throw new CozeAlgError("CryptoKey.FromCozeKey: unsupported CryptoKey algorithm: " + cozeKey.alg, ErrCodes.AlgUnsupported, {
+			alg: cozeKey.alg
+		});
+	}
+
+	// Public CryptoKey "crypto.subtle.importKey" needs key usage to be "verify"
+	// and private CryptoKey needs key usage to be "sign".
+	let jwk = CozeKeyToJWK(cozeKey);
+	if (isEmpty(cozeKey.d) || onlyPublic) {
+		var signOrVerify = "verify";
+		delete jwk.d;
+	} else {
+		signOrVerify = "sign";
+	}
+
+	return await crypto.subtle.importKey(
+		"jwk",
+		jwk, {
+			name: Alg.GenAlgs.ECDSA,
+			namedCurve: jwk.crv,
+		},
+		true,
+		[signOrVerify]
+	);
+}
+
+/**
+padBytes left pads bytes with zeros to size.  Throws if bytes is longer than
+size.
+@param   {string}      name    Name of the field for errors.
+@param   {number}      size
+@param   {Uint8Array}  bytes
+@returns {Uint8Array}
+@throws  {error}
+*/
+function padBytes(name, size, bytes) {
+	if (bytes.length > size) {
+		throw new CozeKeyError("JWKToCozeKey: incorrect " + name + " size.", ErrCodes.KeyInvalid, {
+			field: name
+		});
+	}
+	let out = new Uint8Array(size);
+	out.set(bytes, size - bytes.length);
+	return out;
+}
+
+function concatBytes(a, b) {
+	let out = new Uint8Array(a.length + b.length);
+	out.set(a, 0);
+	out.set(b, a.length);
+	return out;
+}
+
+/**
+subtleParams returns the SubtleCrypto sign/verify algorithm parameters for
+the given alg.
+@param   {Alg}      alg
+@returns {object}
+*/
+function subtleParams(alg) {
+	if (alg === Alg.Algs.Ed25519) {
+		return {
+			name: Alg.Algs.Ed25519
+		};
+	}
+	return {
+		name: Alg.GenAlgs.ECDSA,
+		hash: {
+			name: Alg.HashAlg(alg)
+		},
+	};
+}
+
+/**
+unsupportedErr returns a descriptive error when the browser does not
+implement the alg.  Other errors, like bad keys, are returned as is.
+@param   {string}   fn     Name of the calling function.
+@param   {Alg}      alg
+@param   {error}    e      Error thrown by SubtleCrypto.
+@returns {error}
+*/
+function unsupportedErr(fn, alg, e) {
+	if (e instanceof DOMException && e.name === "NotSupportedError") {
+		return new CozeAlgError(fn + ": alg " + alg + " unsupported in this browser.", ErrCodes.AlgUnsupported, {
+			alg: alg
+		});
+	}
+	return e;
+}
+
+
 
 /** 
 Checks if S is a "low-S".  See the Coze docs on "Low-S"
@@ -366,7 +695,7 @@ Checks if S is a "low-S".  See the Coze docs on "Low-S"
 */
 function IsLowS(alg, s) {
 	if (typeof s !== "bigint") {
-		throw new Error("IsLowS: s is not of type bigint");
+		throw new TypeError("IsLowS: s is not of type bigint");
 	}
 	return Alg.CurveHalfOrder(alg) > s;
 }
@@ -381,7 +710,7 @@ package's "ToLowS" function.
 */
 function toLowS(alg, s) {
 	if (typeof s !== "bigint") {
-		throw new Error("toLowS: s is not of type bigint");
+		throw new TypeError("toLowS: s is not of type bigint");
 	}
 	if (!IsLowS(alg, s)) {
 		return Alg.CurveOrder(alg) - s;
@@ -398,9 +727,9 @@ on "low-S"
 @throws  {error}
 */
 async function SigToLowS(alg, sig) {
-	let ab = await Coze.B64uToArrayBuffer(sig);
+	let ab = await Conv.B64uToArrayBuffer(sig);
 	let lowSSigAB = await sigToLowSArrayBuffer(alg, ab);
-	 return Coze.ArrayBufferTo64ut(lowSSigAB);
+	 return Conv.ArrayBufferTo64ut(lowSSigAB);
 }
 
 /** SigIsLowS checks if S in sig is a "low-S".  See the Coze docs on "low-S"
@@ -0,0 +1,624 @@
+"use strict";
+
+import * as Alg from './alg.js';
+import * as Conv from './conversion.js';
+import * as CZK from './key.js';
+import {
+	ECDSA
+} from './ecdsa.js';
+import {
+	isEmpty
+} from './conversion.js';
+import {
+	CozeAlgError,
+	CozeKeyError,
+	CozeVerifyError,
+	ErrCodes,
+} from './error.js';
+
+export {
+	PEMToCozeKey,
+	CozeKeyToPEM,
+	SigToDER,
+	DERToSig,
+	IsDERSig,
+}
+
+/**
+@typedef {import('./typedef.js').Alg}      Alg
+@typedef {import('./typedef.js').Key}      Key
+@typedef {import('./typedef.js').Sig}      Sig
+*/
+
+// DER (ITU-T X.690) and PEM (RFC 7468) encoding for keys and ECDSA signatures.
+// Only the ASN.1 needed for SPKI (RFC 5480), PKCS #8 (RFC 5958), and SEC1 (RFC
+// 5915) EC keys, Ed25519 (RFC 8410) keys, and ECDSA-Sig-Value (RFC 3279) is
+// implemented.
+
+// DER tags.
+const tagInteger = 0x02;
+const tagBitString = 0x03;
+const tagOctetString = 0x04;
+const tagOID = 0x06;
+const tagSequence = 0x30;
+const tagContext0 = 0xa0;
+const tagContext1 = 0xa1;
+
+// DER encoded OIDs, without tag and length.
+const oidECPublicKey = [0x2a, 0x86, 0x48, 0xce, 0x3d, 0x02, 0x01]; // 1.2.840.10045.2.1
+const oidEd25519 = [0x2b, 0x65, 0x70]; // 1.3.101.112
+
+// curveOIDs are the DER encoded named curve OIDs by alg.
+const curveOIDs = {
+	"ES224": [0x2b, 0x81, 0x04, 0x00, 0x21], // 1.3.132.0.33
+	"ES256": [0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07], // 1.2.840.10045.3.1.7
+	"ES384": [0x2b, 0x81, 0x04, 0x00, 0x22], // 1.3.132.0.34
+	"ES512": [0x2b, 0x81, 0x04, 0x00, 0x23], // 1.3.132.0.35
+};
+
+/**
+PEMToCozeKey returns a Coze key with `tmb` from a PEM encoded key.  Supported
+PEM types are "PUBLIC KEY" (SPKI), "PRIVATE KEY" (unencrypted PKCS #8), and
+"EC PRIVATE KEY" (SEC1) for P-224, P-256, P-384, and P-521, and SPKI and PKCS
+#8 for Ed25519.  Base64 may be wrapped at any line length and other PEM blocks,
+like `openssl ecparam`'s "EC PARAMETERS", are skipped.  If a private key does
+not include the public key, `x` is calculated from `d`.
+@param   {string}  pem
+@returns {Key}
+@throws  {error}
+*/
+async function PEMToCozeKey(pem) {
+	let block = pemDecode(pem);
+	let czk;
+	switch (block.label) {
+		case "PUBLIC KEY":
+			czk = parseSPKI(block.der);
+			break;
+		case "PRIVATE KEY":
+			czk = await parsePKCS8(block.der);
+			break;
+		case "EC PRIVATE KEY":
+			czk = await parseSEC1(block.der, null);
+			break;
+		default:
+			throw new CozeKeyError("PEMToCozeKey: unsupported PEM type: " + block.label, ErrCodes.KeyInvalid, {
+				field: "pem"
+			});
+	}
+	czk.tmb = await CZK.Thumbprint(czk);
+	return czk;
+}
+
+/**
+CozeKeyToPEM returns a PEM encoded key from a Coze key.  By default, the public
+key is encoded as SPKI ("PUBLIC KEY").  If opts.private is true, the private
+key is encoded as PKCS #8 ("PRIVATE KEY"), and if opts.sec1 is also true, EC
+private keys are encoded as SEC1 ("EC PRIVATE KEY").  Private EC keys include
+the public key.  Base64 is wrapped at 64 characters.
+@param   {Key}      cozeKey
+@param   {object}   [opts]          {private:false, sec1:false}
+@returns {string}
+@throws  {error}
+*/
+function CozeKeyToPEM(cozeKey, opts) {
+	let priv = !isEmpty(opts) && opts.private === true;
+	let sec1 = !isEmpty(opts) && opts.sec1 === true;
+	if (isEmpty(cozeKey.x)) {
+		throw new CozeKeyError("CozeKeyToPEM: key x must be set.", ErrCodes.KeyInvalid, {
+			field: "x"
+		});
+	}
+	if (priv && isEmpty(cozeKey.d)) {
+		throw new CozeKeyError("CozeKeyToPEM: private key d must be set.", ErrCodes.KeyInvalid, {
+			field: "d"
+		});
+	}
+	let x = Conv.B64ToUint8Array(cozeKey.x);
+	if (x.length !== Alg.XSize(cozeKey.alg)) {
+		throw new CozeKeyError("CozeKeyToPEM: incorrect x size for " + cozeKey.alg + ".", ErrCodes.KeyInvalid, {
+			field: "x"
+		});
+	}
+
+	if (cozeKey.alg === Alg.Algs.Ed25519) {
+		if (sec1) {
+			throw new CozeAlgError("CozeKeyToPEM: SEC1 is only for EC keys.", ErrCodes.AlgUnsupported, {
+				alg: cozeKey.alg
+			});
+		}
+		let algID = tlv(tagSequence, tlv(tagOID, oidEd25519));
+		if (!priv) {
+			return pemEncode("PUBLIC KEY", tlv(tagSequence, algID, tlv(tagBitString, [0x00], x)));
+		}
+		let d = tlv(tagOctetString, Conv.B64ToUint8Array(cozeKey.d));
+		return pemEncode("PRIVATE KEY", tlv(tagSequence, tlv(tagInteger, [0x00]), algID, tlv(tagOctetString, d)));
+	}
+
+	let curveOID = curveOIDs[cozeKey.alg];
+	if (curveOID === undefined) {
+		throw new CozeAlgError("CozeKeyToPEM: unsupported alg: " + cozeKey.alg, ErrCodes.AlgUnsupported, {
+			alg: cozeKey.alg
+		});
+	}
+	let pub = tlv(tagBitString, [0x00, 0x04], x); // Uncompressed point.
+	let algID = tlv(tagSequence, tlv(tagOID, oidECPublicKey), tlv(tagOID, curveOID));
+	if (!priv) {
+		return pemEncode("PUBLIC KEY", tlv(tagSequence, algID, pub));
+	}
+
+	// ECPrivateKey (RFC 5915).  The curve parameters are omitted in PKCS #8
+	// since they are in the algorithm identifier.
+	let d = tlv(tagOctetString, Conv.B64ToUint8Array(cozeKey.d));
+	if (sec1) {
+		return pemEncode("EC PRIVATE KEY", tlv(tagSequence, tlv(tagInteger, [0x01]), d, tlv(tagContext0, tlv(tagOID, curveOID)), tlv(tagContext1, pub)));
+	}
+	let ecPriv = tlv(tagSequence, tlv(tagInteger, [0x01]), d, tlv(tagContext1, pub));
+	return pemEncode("PRIVATE KEY", tlv(tagSequence, tlv(tagInteger, [0x00]), algID, tlv(tagOctetString, ecPriv)));
+}
+
+
+/**
+SigToDER returns the DER encoded ECDSA signature (SEQUENCE of INTEGER r and
+INTEGER s, RFC 3279) of a Coze sig (fixed size r || s), as used by OpenSSL,
+Java, and many HSMs.  Leading zeros of r and s are removed and a 0x00 is
+prepended when the high bit is set, as DER requires.
+@param   {Sig}          sig   b64ut r || s.
+@param   {Alg}          alg   ECDSA alg.
+@returns {Uint8Array}
+@throws  {error}
+*/
+function SigToDER(sig, alg) {
+	let raw = Conv.B64ToUint8Array(sig);
+	if (Alg.Genus(alg) !== Alg.GenAlgs.ECDSA) {
+		throw new CozeAlgError("SigToDER: alg must be ECDSA: " + alg, ErrCodes.AlgUnsupported, {
+			alg: alg
+		});
+	}
+	if (raw.length !== Alg.SigSize(alg)) {
+		throw new CozeVerifyError(`SigToDER: incorrect sig size for ${alg}: ${raw.length} bytes, expected ${Alg.SigSize(alg)}.`, ErrCodes.SigInvalid, {
+			field: "sig"
+		});
+	}
+	let half = raw.length / 2;
+	return tlv(tagSequence, derInteger(raw.slice(0, half)), derInteger(raw.slice(half)));
+}
+
+/**
+DERToSig returns the Coze sig (b64ut fixed size r || s) from a DER encoded
+ECDSA signature.  r and s shorter than the curve size (e.g. ES512's 66 byte
+halves) are left padded with zeros.  DERToSig does not normalize S, so high-S
+signatures remain high-S.  See SigToLowS and VerifyOpts.requireLowS.
+@param   {Uint8Array|ArrayBuffer|B64}  der   DER bytes or b64ut DER.
+@param   {Alg}                         alg   ECDSA alg.
+@returns {Sig}
+@throws  {error}
+*/
+function DERToSig(der, alg) {
+	if (typeof der === "string") {
+		der = Conv.B64ToUint8Array(der);
+	}
+	der = new Uint8Array(der);
+	if (Alg.Genus(alg) !== Alg.GenAlgs.ECDSA) {
+		throw new CozeAlgError("DERToSig: alg must be ECDSA: " + alg, ErrCodes.AlgUnsupported, {
+			alg: alg
+		});
+	}
+	let seq, ints;
+	try {
+		seq = readTLV(der, 0);
+		ints = children(seq);
+	} catch (e) {
+		throw new CozeVerifyError("DERToSig: invalid DER signature: " + e.message, ErrCodes.SigInvalid, {
+			field: "sig"
+		});
+	}
+	if (seq.tag !== tagSequence || seq.end !== der.length || ints.length !== 2) {
+		throw new CozeVerifyError("DERToSig: invalid DER signature.", ErrCodes.SigInvalid, {
+			field: "sig"
+		});
+	}
+	let half = Alg.SigSize(alg) / 2;
+	let out = new Uint8Array(half * 2);
+	for (let i = 0; i < 2; i++) {
+		if (ints[i].tag !== tagInteger) {
+			throw new CozeVerifyError("DERToSig: invalid signature integer.", ErrCodes.SigInvalid, {
+				field: "sig"
+			});
+		}
+		let n = ints[i].content;
+		if (n.length === 0 || n[0] & 0x80) {
+			throw new CozeVerifyError("DERToSig: signature integers must be positive.", ErrCodes.SigInvalid, {
+				field: "sig"
+			});
+		}
+		let j = 0;
+		while (j < n.length - 1 && n[j] === 0x00) {
+			j++;
+		}
+		n = n.slice(j);
+		if (n.length > half) {
+			throw new CozeVerifyError("DERToSig: signature integer too large for " + alg + ".", ErrCodes.SigInvalid, {
+				field: "sig"
+			});
+		}
+		out.set(n, half * (i + 1) - n.length);
+	}
+	return Conv.ArrayBufferTo64ut(out);
+}
+
+/**
+IsDERSig returns whether sig looks like a DER encoded signature for alg
+instead of Coze's r || s:  the first byte is 0x30 (SEQUENCE) and the size is
+not alg's sig size.
+@param   {Uint8Array}  sig
+@param   {Alg}         alg
+@returns {boolean}
+*/
+function IsDERSig(sig, alg) {
+	return sig.length > 0 && sig[0] === tagSequence && sig.length !== Alg.SigSize(alg);
+}
+
+/**
+derInteger returns the DER INTEGER of the unsigned big endian bytes.
+@param   {Uint8Array}  n
+@returns {Uint8Array}
+*/
+function derInteger(n) {
+	let i = 0;
+	while (i < n.length - 1 && n[i] === 0x00) {
+		i++;
+	}
+	n = n.slice(i);
+	if (n[0] & 0x80) {
+		return tlv(tagInteger, [0x00], n);
+	}
+	return tlv(tagInteger, n);
+}
+
+
+///////////////////////////////////
+// Key parsing
+///////////////////////////////////
+
+/**
+parseSPKI returns a public Coze key (without tmb) from DER SPKI.
+@param   {Uint8Array}  der
+@returns {Key}
+@throws  {error}
+*/
+function parseSPKI(der) {
+	let spki = children(expect(readTLV(der, 0), tagSequence, "SPKI"));
+	let alg = algFromAlgID(spki[0]);
+	let pub = expect(spki[1], tagBitString, "SPKI public key");
+	if (pub.content[0] !== 0x00) {
+		throw new CozeKeyError("PEMToCozeKey: unsupported SPKI public key padding.", ErrCodes.KeyInvalid);
+	}
+	return {
+		alg: alg,
+		x: pointToX(alg, pub.content.slice(1)),
+	};
+}
+
+/**
+parsePKCS8 returns a private Coze key (without tmb) from DER PKCS #8.
+@param   {Uint8Array}  der
+@returns {Key}
+@throws  {error}
+*/
+async function parsePKCS8(der) {
+	let pk = children(expect(readTLV(der, 0), tagSequence, "PKCS #8"));
+	if (pk.length < 3) {
+		throw new CozeKeyError("PEMToCozeKey: invalid PKCS #8.", ErrCodes.KeyInvalid);
+	}
+	let alg = algFromAlgID(pk[1]);
+	let priv = expect(pk[2], tagOctetString, "PKCS #8 private key").content;
+	if (alg !== Alg.Algs.Ed25519) {
+		return parseSEC1(priv, alg);
+	}
+
+	// Ed25519 private key is an OCTET STRING in the OCTET STRING.  The public
+	// key is not always included, so it's derived using SubtleCrypto.
+	let d = expect(readTLV(priv, 0), tagOctetString, "Ed25519 private key").content;
+	if (d.length !== Alg.DSize(alg)) {
+		throw new CozeKeyError("PEMToCozeKey: incorrect Ed25519 private key size.", ErrCodes.KeyInvalid, {
+			field: "d"
+		});
+	}
+	let ck = await crypto.subtle.importKey("pkcs8", der, {
+		name: Alg.Algs.Ed25519
+	}, true, ["sign"]);
+	let jwk = await crypto.subtle.exportKey("jwk", ck);
+	return {
+		alg: alg,
+		x: jwk.x,
+		d: Conv.ArrayBufferTo64ut(d),
+	};
+}
+
+/**
+parseSEC1 returns a private Coze key (without tmb) from DER SEC1
+ECPrivateKey.  `alg` is from the PKCS #8 algorithm identifier, or null for
+SEC1 which must then include the curve parameters.
+@param   {Uint8Array}  der
+@param   {Alg|null}    alg
+@returns {Key}
+@throws  {error}
+*/
+async function parseSEC1(der, alg) {
+	let ec = children(expect(readTLV(der, 0), tagSequence, "EC private key"));
+	if (ec.length < 2 || ec[0].tag !== tagInteger || ec[0].content.length !== 1 || ec[0].content[0] !== 0x01) {
+		throw new CozeKeyError("PEMToCozeKey: unsupported EC private key version.", ErrCodes.KeyInvalid);
+	}
+	let pub = null;
+	for (let f of ec.slice(2)) {
+		if (f.tag === tagContext0) {
+			let curveAlg = algFromCurveOID(expect(readTLV(f.content, 0), tagOID, "EC private key curve").content);
+			if (alg !== null && alg !== curveAlg) {
+				throw new CozeAlgError("PEMToCozeKey: EC private key curve mismatch.", ErrCodes.AlgMismatch, {
+					alg: curveAlg
+				});
+			}
+			alg = curveAlg;
+		}
+		if (f.tag === tagContext1) {
+			pub = expect(readTLV(f.content, 0), tagBitString, "EC public key").content;
+		}
+	}
+	if (alg === null) {
+		throw new CozeKeyError("PEMToCozeKey: EC private key curve not given.", ErrCodes.KeyInvalid);
+	}
+
+	// RFC 5915 requires d to be the size of the curve order, but left pad for
+	// leniency.
+	let dBytes = expect(ec[1], tagOctetString, "EC private key").content;
+	if (dBytes.length > Alg.DSize(alg)) {
+		throw new CozeKeyError("PEMToCozeKey: incorrect private key size.", ErrCodes.KeyInvalid, {
+			field: "d"
+		});
+	}
+	let d = new Uint8Array(Alg.DSize(alg));
+	d.set(dBytes, d.length - dBytes.length);
+
+	let czk = {
+		alg: alg,
+	};
+	if (pub !== null) {
+		if (pub[0] !== 0x00) {
+			throw new CozeKeyError("PEMToCozeKey: unsupported EC public key padding.", ErrCodes.KeyInvalid);
+		}
+		czk.x = pointToX(alg, pub.slice(1));
+	} else {
+		czk.x = await ECDSA.PublicFromD(alg, bytesToBigInt(d));
+	}
+	czk.d = Conv.ArrayBufferTo64ut(d);
+	return czk;
+}
+
+/**
+algFromAlgID returns the alg from a DER AlgorithmIdentifier.
+@param   {object}  t   TLV.
+@returns {Alg}
+@throws  {error}
+*/
+function algFromAlgID(t) {
+	let id = children(expect(t, tagSequence, "algorithm identifier"));
+	let oid = expect(id[0], tagOID, "algorithm").content;
+	if (bytesEqual(oid, oidEd25519)) {
+		return Alg.Algs.Ed25519;
+	}
+	if (!bytesEqual(oid, oidECPublicKey)) {
+		throw new CozeAlgError("PEMToCozeKey: unsupported key algorithm.", ErrCodes.AlgUnsupported);
+	}
+	if (id.length < 2) {
+		throw new CozeKeyError("PEMToCozeKey: EC named curve not given.", ErrCodes.KeyInvalid);
+	}
+	return algFromCurveOID(expect(id[1], tagOID, "named curve").content);
+}
+
+/**
+algFromCurveOID returns the alg for a DER encoded named curve OID.
+@param   {Uint8Array}  oid
+@returns {Alg}
+@throws  {error}
+*/
+function algFromCurveOID(oid) {
+	for (let alg in curveOIDs) {
+		if (bytesEqual(oid, curveOIDs[alg])) {
+			return alg;
+		}
+	}
+	throw new CozeAlgError("PEMToCozeKey: unsupported named curve.", ErrCodes.AlgUnsupported);
+}
+
+/**
+pointToX returns Coze `x` from a public key.  For EC, the point must be
+uncompressed (0x04 || X || Y).
+@param   {Alg}         alg
+@param   {Uint8Array}  point
+@returns {string}      b64ut x
+@throws  {error}
+*/
+function pointToX(alg, point) {
+	if (alg !== Alg.Algs.Ed25519) {
+		if (point[0] !== 0x04) {
+			throw new CozeKeyError("PEMToCozeKey: only uncompressed EC points are supported.", ErrCodes.KeyInvalid, {
+				field: "x"
+			});
+		}
+		point = point.slice(1);
+	}
+	if (point.length !== Alg.XSize(alg)) {
+		throw new CozeKeyError("PEMToCozeKey: incorrect public key size for " + alg + ".", ErrCodes.KeyInvalid, {
+			field: "x"
+		});
+	}
+	return Conv.ArrayBufferTo64ut(point);
+}
+
+
+///////////////////////////////////
+// PEM and DER
+///////////////////////////////////
+
+/**
+pemDecode returns the label and DER bytes of the first key block in PEM.
+Blocks labeled "EC PARAMETERS" are skipped.  Encrypted PEM is refused.
+@param   {string}  pem
+@returns {object}  {label, der}
+@throws  {error}
+*/
+function pemDecode(pem) {
+	let re = /-----BEGIN ([A-Z0-9 ]+)-----([\s\S]*?)-----END \1-----/g;
+	let m;
+	while ((m = re.exec(pem)) !== null) {
+		if (m[1] === "EC PARAMETERS") {
+			continue;
+		}
+		if (m[1] === "ENCRYPTED PRIVATE KEY" || m[2].includes("ENCRYPTED")) {
+			throw new CozeKeyError("PEMToCozeKey: encrypted PEM is not supported.", ErrCodes.KeyInvalid, {
+				field: "pem"
+			});
+		}
+		let b64 = m[2].replace(/\s+/g, "");
+		try {
+			var der = Uint8Array.from(atob(b64), c => c.charCodeAt(0));
+		} catch (e) {
+			throw new CozeKeyError("PEMToCozeKey: invalid PEM base64.", ErrCodes.KeyInvalid, {
+				field: "pem"
+			});
+		}
+		return {
+			label: m[1],
+			der: der,
+		};
+	}
+	throw new CozeKeyError("PEMToCozeKey: no PEM key found.", ErrCodes.KeyInvalid, {
+		field: "pem"
+	});
+}
+
+/**
+pemEncode returns PEM with the given label and DER bytes.  Base64 is wrapped
+at 64 characters (RFC 7468).
+@param   {string}      label
+@param   {Uint8Array}  der
+@returns {string}
+*/
+function pemEncode(label, der) {
+	let b64 = btoa(String.fromCharCode(...der));
+	let lines = b64.match(/.{1,64}/g);
+	return `-----BEGIN ${label}-----\n${lines.join("\n")}\n-----END ${label}-----\n`;
+}
+
+/**
+readTLV reads a DER tag-length-value at offset.  Only single byte tags and
+definite lengths (as required by DER) are supported.
+@param   {Uint8Array}  der
+@param   {number}      off
+@returns {object}      {tag, content, end}
+@throws  {error}
+*/
+function readTLV(der, off) {
+	if (off + 2 > der.length) {
+		throw new CozeKeyError("DER: unexpected end of input.", ErrCodes.KeyInvalid);
+	}
+	let tag = der[off];
+	let len = der[off + 1];
+	let start = off + 2;
+	if (len & 0x80) {
+		let n = len & 0x7f;
+		if (n === 0 || n > 4) {
+			throw new CozeKeyError("DER: unsupported length.", ErrCodes.KeyInvalid);
+		}
+		len = 0;
+		for (let i = 0; i < n; i++) {
+			len = len * 256 + der[start + i];
+		}
+		start += n;
+	}
+	let end = start + len;
+	if (end > der.length) {
+		throw new CozeKeyError("DER: length exceeds input.", ErrCodes.KeyInvalid);
+	}
+	return {
+		tag: tag,
+		content: der.slice(start, end),
+		end: end,
+	};
+}
+
+/**
+children returns the TLVs in the content of a constructed TLV.
+@param   {object}    t    TLV.
+@returns {object[]}
+@throws  {error}
+*/
+function children(t) {
+	let out = [];
+	for (let off = 0; off < t.content.length;) {
+		let c = readTLV(t.content, off);
+		out.push(c);
+		off = c.end;
+	}
+	return out;
+}
+
+/**
+expect throws if t is not of the given tag.
+@param   {object}  t      TLV.
+@param   {number}  tag
+@param   {string}  name   Name for errors.
+@returns {object}         t
+@throws  {error}
+*/
+function expect(t, tag, name) {
+	if (t === undefined || t.tag !== tag) {
+		throw new CozeKeyError("DER: invalid " + name + ".", ErrCodes.KeyInvalid);
+	}
+	return t;
+}
+
+/**
+tlv returns DER bytes of the tag and the concatenated contents.
+@param   {number}               tag
+@param   {...(Uint8Array|Array)} contents
+@returns {Uint8Array}
+*/
+function tlv(tag, ...contents) {
+	let content = [];
+	for (let c of contents) {
+		content.push(...c);
+	}
+	let len = content.length;
+	let lenBytes = [];
+	if (len < 0x80) {
+		lenBytes.push(len);
+	} else {
+		let b = [];
+		for (; len > 0; len >>= 8) {
+			b.unshift(len & 0xff);
+		}
+		lenBytes.push(0x80 | b.length, ...b);
+	}
+	return new Uint8Array([tag, ...lenBytes, ...content]);
+}
+
+function bytesEqual(a, b) {
+	if (a.length !== b.length) {
+		return false;
+	}
+	for (let i = 0; i < a.length; i++) {
+		if (a[i] !== b[i]) {
+			return false;
+		}
+	}
+	return true;
+}
+
+function bytesToBigInt(bytes) {
+	let result = 0n;
+	for (let b of bytes) {
+		result = (result << 8n) + BigInt(b);
+	}
+	return result;
+}
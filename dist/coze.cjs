@@ -0,0 +1,4538 @@
+var __defProp = Object.defineProperty;
+var __getOwnPropDesc = Object.getOwnPropertyDescriptor;
+var __getOwnPropNames = Object.getOwnPropertyNames;
+var __hasOwnProp = Object.prototype.hasOwnProperty;
+var __export = (target, all) => {
+  for (var name in all)
+    __defProp(target, name, { get: all[name], enumerable: true });
+};
+var __copyProps = (to, from, except, desc) => {
+  if (from && typeof from === "object" || typeof from === "function") {
+    for (let key of __getOwnPropNames(from))
+      if (!__hasOwnProp.call(to, key) && key !== except)
+        __defProp(to, key, { get: () => from[key], enumerable: !(desc = __getOwnPropDesc(from, key)) || desc.enumerable });
+  }
+  return to;
+};
+var __toCommonJS = (mod2) => __copyProps(__defProp({}, "__esModule", { value: true }), mod2);
+
+// join.js
+var join_exports = {};
+__export(join_exports, {
+  AlgFromCOSE: () => AlgFromCOSE,
+  AlgFromJOSE: () => AlgFromJOSE,
+  Algs: () => Algs,
+  ArrayBufferTo64ut: () => ArrayBufferTo64ut,
+  AssertPublic: () => AssertPublic,
+  Attach: () => Attach,
+  B64Error: () => B64Error,
+  B64Lenient: () => B64Lenient,
+  B64ToUint8Array: () => B64ToUint8Array,
+  B64uToArrayBuffer: () => B64uToArrayBuffer,
+  B64utToHex: () => B64utToHex,
+  COSEAlg: () => COSEAlg,
+  Canon: () => Canon,
+  Canonical: () => Canonical,
+  CanonicalHash: () => CanonicalHash,
+  CanonicalHash64: () => CanonicalHash64,
+  CanonicalS: () => CanonicalS,
+  CheckDuplicates: () => CheckDuplicates,
+  ClearKeyCache: () => ClearKeyCache,
+  Correct: () => Correct,
+  CozeAlgError: () => CozeAlgError,
+  CozeCanonError: () => CozeCanonError,
+  CozeError: () => CozeError,
+  CozeKeyError: () => CozeKeyError,
+  CozeKeyToJWK: () => CozeKeyToJWK,
+  CozeKeyToPEM: () => CozeKeyToPEM,
+  CozeVerifyError: () => CozeVerifyError,
+  CryptoKey: () => CryptoKey,
+  Curve: () => Curve,
+  CurveHalfOrder: () => CurveHalfOrder,
+  CurveOID: () => CurveOID,
+  CurveOrder: () => CurveOrder,
+  Curves: () => Curves,
+  DERToSig: () => DERToSig,
+  DSize: () => DSize,
+  Detach: () => Detach,
+  Detect: () => Detect,
+  Diagnose: () => Diagnose,
+  Digest: () => Digest,
+  DigestFiles: () => DigestFiles,
+  DigestPayField: () => DigestPayField,
+  DigestPayFile: () => DigestPayFile,
+  ECDSA: () => ECDSA,
+  Equal: () => Equal,
+  EqualStrict: () => EqualStrict,
+  ErrCodes: () => ErrCodes,
+  FamAlgs: () => FamAlgs,
+  Family: () => Family,
+  GenAlgs: () => GenAlgs,
+  Genus: () => Genus,
+  HMAC: () => HMAC,
+  Hash: () => Hash,
+  HashAlg: () => HashAlg,
+  HashSize: () => HashSize,
+  HashStream: () => HashStream,
+  HexToB64ut: () => HexToB64ut,
+  HexToUint8Array: () => HexToUint8Array,
+  InputTypes: () => InputTypes,
+  IsDERSig: () => IsDERSig,
+  IsPrivate: () => IsPrivate,
+  IsRevoked: () => IsRevoked,
+  IsSigLowS: () => IsSigLowS,
+  JOSEAlg: () => JOSEAlg,
+  JOSECrv: () => JOSECrv,
+  JWKToCozeKey: () => JWKToCozeKey,
+  LookupKey: () => LookupKey,
+  MatchPayFile: () => MatchPayFile,
+  Meta: () => Meta,
+  NewKey: () => NewKey,
+  NewKeyFromPassword: () => NewKeyFromPassword,
+  NewKeyFromSeed: () => NewKeyFromSeed,
+  NormalizeUnicode: () => NormalizeUnicode,
+  PEMToCozeKey: () => PEMToCozeKey,
+  Params: () => Params,
+  ParseStrict: () => ParseStrict,
+  PayCanon: () => PayCanon,
+  PublicKey: () => PublicKey,
+  Revoke: () => Revoke,
+  SToArrayBuffer: () => SToArrayBuffer,
+  ScrubCoze: () => ScrubCoze,
+  SigSize: () => SigSize,
+  SigToDER: () => SigToDER,
+  SigToLowS: () => SigToLowS,
+  Sign: () => Sign,
+  SignCozeRaw: () => SignCozeRaw,
+  SignCryptoKey: () => SignCryptoKey,
+  SignDig: () => SignDig,
+  SignPay: () => SignPay,
+  Thumbprint: () => Thumbprint,
+  ThumbprintMatch: () => ThumbprintMatch,
+  TmbCanon: () => TmbCanon,
+  Uint8ArrayToHex: () => Uint8ArrayToHex,
+  Use: () => Use,
+  Uses: () => Uses,
+  Valid: () => Valid,
+  Verify: () => Verify,
+  VerifyDig: () => VerifyDig,
+  VerifyMeta: () => VerifyMeta,
+  VerifyPay: () => VerifyPay,
+  VerifyRevoke: () => VerifyRevoke,
+  XSize: () => XSize,
+  isEmpty: () => isEmpty
+});
+module.exports = __toCommonJS(join_exports);
+
+// node/crypto.js
+var import_node_crypto = require("node:crypto");
+var crypto = globalThis.crypto !== void 0 ? globalThis.crypto : import_node_crypto.webcrypto;
+
+// error.js
+var ErrCodes = {
+  AlgUnsupported: "ERR_ALG_UNSUPPORTED",
+  AlgMismatch: "ERR_ALG_MISMATCH",
+  TmbMismatch: "ERR_TMB_MISMATCH",
+  KeyInvalid: "ERR_KEY_INVALID",
+  KeyRevoked: "ERR_KEY_REVOKED",
+  KeyMismatch: "ERR_KEY_MISMATCH",
+  KeyNotFound: "ERR_KEY_NOT_FOUND",
+  SigInvalid: "ERR_SIG_INVALID",
+  CanonInvalid: "ERR_CANON_INVALID",
+  CanonMissing: "ERR_CANON_MISSING",
+  CanonExtra: "ERR_CANON_EXTRA",
+  PayMissing: "ERR_PAY_MISSING",
+  PrvMismatch: "ERR_PRV_MISMATCH",
+  ThresholdInvalid: "ERR_THRESHOLD_INVALID",
+  IatInvalid: "ERR_IAT_INVALID",
+  Expired: "ERR_EXPIRED",
+  NotYetValid: "ERR_NOT_YET_VALID",
+  DigSize: "ERR_DIG_SIZE",
+  HashInvalid: "ERR_HASH_INVALID",
+  DuplicateField: "ERR_DUPLICATE_FIELD",
+  JSONInvalid: "ERR_JSON_INVALID",
+  FieldReserved: "ERR_FIELD_RESERVED",
+  B64Invalid: "ERR_B64_INVALID",
+  HexInvalid: "ERR_HEX_INVALID",
+  QRCapacity: "ERR_QR_CAPACITY",
+  QRInvalid: "ERR_QR_INVALID",
+  KeystoreUnavailable: "ERR_KEYSTORE_UNAVAILABLE",
+  BrowserRequired: "ERR_BROWSER_REQUIRED"
+};
+var CozeError = class extends Error {
+  /**
+  @param {string}  message   Human readable message.
+  @param {string}  code      ErrCodes code.
+  @param {object}  [context] e.g. {field:"x"} or {alg:"ES256"}.
+  */
+  constructor(message, code, context) {
+    super(message);
+    this.name = "CozeError";
+    this.code = code;
+    if (context !== void 0) {
+      Object.assign(this, context);
+    }
+  }
+};
+var CozeKeyError = class extends CozeError {
+  constructor(message, code, context) {
+    super(message, code, context);
+    this.name = "CozeKeyError";
+  }
+};
+var CozeVerifyError = class extends CozeError {
+  constructor(message, code, context) {
+    super(message, code, context);
+    this.name = "CozeVerifyError";
+  }
+};
+var CozeCanonError = class extends CozeError {
+  constructor(message, code, context) {
+    super(message, code, context);
+    this.name = "CozeCanonError";
+  }
+};
+var CozeAlgError = class extends CozeError {
+  constructor(message, code, context) {
+    super(message, code, context);
+    this.name = "CozeAlgError";
+  }
+};
+
+// conversion.js
+async function SToArrayBuffer(string) {
+  return new TextEncoder().encode(string).buffer;
+}
+var B64Error = class extends CozeError {
+  constructor(message, field) {
+    super(message, ErrCodes.B64Invalid, {
+      field
+    });
+    this.name = "B64Error";
+  }
+};
+function B64uToArrayBuffer(string, field) {
+  return B64ToUint8Array(string, field).buffer;
+}
+function B64ToUint8Array(string, field) {
+  let f = isEmpty(field) ? "" : ` for field "${field}"`;
+  if (typeof string !== "string") {
+    throw new B64Error(`B64ToUint8Array: b64ut must be a string${f}.`, field);
+  }
+  if (!/^[A-Za-z0-9_-]*$/.test(string) || string.length % 4 === 1) {
+    throw new B64Error(`B64ToUint8Array: invalid b64ut${f}.`, field);
+  }
+  let bin = atob(string.replace(/-/g, "+").replace(/_/g, "/"));
+  let bytes = Uint8Array.from(bin, (c) => c.charCodeAt(0));
+  if (ArrayBufferTo64ut(bytes) !== string) {
+    throw new B64Error(`B64ToUint8Array: non-canonical b64ut${f}.`, field);
+  }
+  return bytes;
+}
+function B64Lenient(string) {
+  let s = string.replace(/\s/g, "").replace(/=+$/, "").replace(/-/g, "+").replace(/_/g, "/");
+  if (!/^[A-Za-z0-9+/]*$/.test(s) || s.length % 4 === 1) {
+    throw new B64Error("B64Lenient: invalid base64.");
+  }
+  return ArrayBufferTo64ut(Uint8Array.from(atob(s), (c) => c.charCodeAt(0)));
+}
+function ArrayBufferTo64ut(buffer) {
+  return btoa(String.fromCharCode.apply(null, new Uint8Array(buffer))).replace(/\+/g, "-").replace(/\//g, "_").replace(/=/g, "");
+}
+function HexToUint8Array(hex) {
+  hex = hex.replace(/^0x/i, "");
+  if (hex.length % 2 !== 0) {
+    throw new CozeError("HexToUint8Array: hex must have an even number of characters, got " + hex.length + ".", ErrCodes.HexInvalid);
+  }
+  if (!/^[0-9a-fA-F]*$/.test(hex)) {
+    throw new CozeError("HexToUint8Array: invalid hex character.", ErrCodes.HexInvalid);
+  }
+  let bytes = new Uint8Array(hex.length / 2);
+  for (let i = 0; i < bytes.length; i++) {
+    bytes[i] = parseInt(hex.substring(i * 2, i * 2 + 2), 16);
+  }
+  return bytes;
+}
+function Uint8ArrayToHex(bytes) {
+  return Array.from(new Uint8Array(bytes), (b) => b.toString(16).padStart(2, "0")).join("");
+}
+function HexToB64ut(hex) {
+  return ArrayBufferTo64ut(HexToUint8Array(hex));
+}
+function B64utToHex(b64) {
+  return Uint8ArrayToHex(B64ToUint8Array(b64));
+}
+function isEmpty(thing) {
+  if (typeof thing === "function") {
+    return false;
+  }
+  if (Array.isArray(thing)) {
+    if (thing.length == 0) {
+      return true;
+    }
+  }
+  if (thing === Object(thing)) {
+    if (Object.keys(thing).length === 0) {
+      return true;
+    }
+    return false;
+  }
+  if (!isBool(thing)) {
+    return true;
+  }
+  return false;
+}
+function isBool(bool) {
+  if (bool === false || bool === "false" || bool === void 0 || bool === "undefined" || bool === "" || bool === 0 || bool === "0" || bool === null || bool === "null" || bool === "NaN" || Number.isNaN(bool) || bool === Object(bool)) {
+    return false;
+  }
+  return true;
+}
+
+// alg.js
+var Algs = {
+  UnknownAlg: "UnknownAlg",
+  ES224: "ES224",
+  ES256: "ES256",
+  ES384: "ES384",
+  ES512: "ES512",
+  Ed25519: "Ed25519",
+  Ed25519ph: "Ed25519ph",
+  Ed448: "Ed448",
+  SHA224: "SHA-224",
+  SHA256: "SHA-256",
+  SHA384: "SHA-384",
+  SHA512: "SHA-512",
+  SHA3224: "SHA3-224",
+  SHA3256: "SHA3-256",
+  SHA3384: "SHA3-384",
+  SHA3512: "SHA3-512",
+  SHAKE128: "SHAKE128",
+  SHAKE256: "SHAKE256"
+};
+var FamAlgs = {
+  EC: "EC",
+  SHA: "SHA",
+  RSA: "RSA"
+};
+var GenAlgs = {
+  ECDSA: "ECDSA",
+  EdDSA: "EdDSA",
+  SHA2: "SHA2",
+  SHA3: "SHA3"
+};
+var Curves = {
+  P224: "P-224",
+  P256: "P-256",
+  P384: "P-384",
+  P521: "P-521",
+  Curve25519: "Curve25519",
+  Curve448: "Curve448"
+};
+var Uses = {
+  Sig: "sig",
+  Enc: "enc",
+  Hsh: "hsh"
+};
+function Params(alg) {
+  let p = {};
+  p.Name = alg;
+  p.Genus = Genus(alg);
+  p.Family = Family(alg);
+  p.Use = Use(alg);
+  p.Hash = HashAlg(alg);
+  p.HashSize = HashSize(alg);
+  p.HashSizeB64 = Math.ceil(4 * p.HashSize / 3);
+  try {
+    p.XSize = XSize(alg);
+    p.XSizeB64 = Math.ceil(4 * p.XSize / 3);
+    p.DSize = DSize(alg);
+    p.DSizeB64 = Math.ceil(4 * p.DSize / 3);
+    p.Curve = Curve(alg);
+    p.SigSize = SigSize(alg);
+    p.SigSizeB64 = Math.ceil(4 * p.SigSize / 3);
+    p.CurveOID = CurveOID(alg);
+    p.JOSECrv = JOSECrv(alg);
+  } catch (e) {
+  }
+  p.JOSEAlg = JOSEAlg(alg);
+  p.COSEAlg = COSEAlg(alg);
+  return p;
+}
+function Genus(alg) {
+  switch (alg) {
+    case Algs.ES224:
+    case Algs.ES256:
+    case Algs.ES384:
+    case Algs.ES512:
+      return GenAlgs.ECDSA;
+    case Algs.Ed25519:
+    case Algs.Ed25519ph:
+    case Algs.Ed448:
+      return GenAlgs.EdDSA;
+    case Algs.SHA224:
+    case Algs.SHA256:
+    case Algs.SHA384:
+    case Algs.SHA512:
+      return GenAlgs.SHA2;
+    case Algs.SHA3224:
+    case Algs.SHA3256:
+    case Algs.SHA3384:
+    case Algs.SHA3512:
+    case Algs.SHAKE128:
+    case Algs.SHAKE256:
+      return GenAlgs.SHA3;
+    default:
+      throw new CozeAlgError("alg.Genus: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+  }
+}
+function Family(alg) {
+  switch (alg) {
+    case Algs.ES224:
+    case Algs.ES256:
+    case Algs.ES384:
+    case Algs.ES512:
+    case Algs.Ed25519:
+    case Algs.Ed25519ph:
+    case Algs.Ed448:
+      return FamAlgs.EC;
+    case Algs.SHA224:
+    case Algs.SHA256:
+    case Algs.SHA384:
+    case Algs.SHA512:
+    case Algs.SHA3224:
+    case Algs.SHA3256:
+    case Algs.SHA3384:
+    case Algs.SHA3512:
+    case Algs.SHAKE128:
+    case Algs.SHAKE256:
+      return FamAlgs.SHA;
+    default:
+      throw new CozeAlgError("alg.Family:  unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+  }
+}
+function HashAlg(alg) {
+  switch (alg) {
+    case Algs.ES224:
+    case Algs.SHA224:
+      return Algs.SHA224;
+    case Algs.SHA256:
+    case Algs.ES256:
+      return Algs.SHA256;
+    case Algs.SHA384:
+    case Algs.ES384:
+      return Algs.SHA384;
+    case Algs.SHA512:
+    case Algs.ES512:
+    // P-521 is not ES512/SHA-512.  The curve != the alg/hash.
+    case Algs.Ed25519:
+    case Algs.Ed25519ph:
+      return Algs.SHA512;
+    case Algs.SHAKE128:
+      return Algs.SHAKE128;
+    case Algs.SHAKE256:
+    case Algs.Ed448:
+      return Algs.SHAKE256;
+    case Algs.SHA3224:
+      return Algs.SHA3224;
+    case Algs.SHA3256:
+      return Algs.SHA3256;
+    case Algs.SHA3384:
+      return Algs.SHA3384;
+    case Algs.SHA3512:
+      return Algs.SHA3512;
+    default:
+      throw new CozeAlgError("alg.HashAlg:  unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+  }
+}
+function HashSize(alg) {
+  switch (HashAlg(alg)) {
+    case Algs.SHA224:
+    case Algs.SHA3224:
+      return 28;
+    case Algs.SHA256:
+    case Algs.SHA3256:
+    case Algs.SHAKE128:
+      return 32;
+    case Algs.SHA384:
+    case Algs.SHA3384:
+      return 48;
+    case Algs.SHA512:
+    case Algs.SHA3512:
+    case Algs.SHAKE256:
+      return 64;
+    default:
+      throw new CozeAlgError("alg.HashSize: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+  }
+}
+function SigSize(alg) {
+  switch (alg) {
+    case Algs.ES224:
+      return 56;
+    case Algs.ES256:
+    case Algs.Ed25519:
+    case Algs.Ed25519ph:
+      return 64;
+    case Algs.ES384:
+      return 96;
+    case Algs.Ed448:
+      return 114;
+    case Algs.ES512:
+      return 132;
+    default:
+      throw new CozeAlgError("alg.SigSize: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+  }
+}
+function XSize(alg) {
+  switch (alg) {
+    case Algs.Ed25519:
+    case Algs.Ed25519ph:
+      return 32;
+    case Algs.ES224:
+      return 56;
+    case Algs.Ed448:
+      return 57;
+    case Algs.ES256:
+      return 64;
+    case Algs.ES384:
+      return 96;
+    case Algs.ES512:
+      return 132;
+    // X and Y are 66 bytes (Rounded up for P521)
+    default:
+      throw new CozeAlgError("alg.XSize: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+  }
+}
+function DSize(alg) {
+  switch (alg) {
+    case Algs.ES224:
+      return 28;
+    case Algs.ES256:
+    case Algs.Ed25519:
+    case Algs.Ed25519ph:
+      return 32;
+    case Algs.ES384:
+      return 48;
+    case Algs.Ed448:
+      return 57;
+    case Algs.ES512:
+      return 66;
+    default:
+      throw new CozeAlgError("alg.DSize: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+  }
+}
+function Curve(alg) {
+  switch (alg) {
+    default:
+      throw new CozeAlgError("alg.Curve: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+    case Algs.ES224:
+      return Curves.P224;
+    case Algs.ES256:
+      return Curves.P256;
+    case Algs.ES384:
+      return Curves.P384;
+    case Algs.ES512:
+      return Curves.P521;
+    case Algs.Ed25519:
+    case Algs.Ed25519ph:
+      return Curves.Curve25519;
+    case Algs.Ed448:
+      return Curves.Curve448;
+  }
+}
+function Use(alg) {
+  switch (Genus(alg)) {
+    default:
+      throw new CozeAlgError("alg.Use: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+    case GenAlgs.EdDSA:
+    case GenAlgs.ECDSA:
+      return Uses.Sig;
+    case GenAlgs.SHA2:
+    case GenAlgs.SHA3:
+      return Uses.Hsh;
+  }
+}
+var order = {
+  "ES224": BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFF16A2E0B8F03E13DD29455C5C2A3D"),
+  "ES256": BigInt("0xFFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551"),
+  "ES384": BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFC7634D81F4372DDF581A0DB248B0A77AECEC196ACCC52973"),
+  "ES512": BigInt("0x1FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFA51868783BF2F966B7FCC0148F709A5D03BB5C9B8899C47AEBB6FB71E91386409")
+};
+var halfOrder = {
+  "ES224": order["ES224"] >> BigInt(1),
+  "ES256": order["ES256"] >> BigInt(1),
+  "ES384": order["ES384"] >> BigInt(1),
+  "ES512": order["ES512"] >> BigInt(1)
+};
+function CurveOrder(alg) {
+  switch (alg) {
+    default:
+      throw new CozeAlgError("CurveOrder: unsupported curve: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+    case "ES224":
+    case "ES256":
+    case "ES384":
+    case "ES512":
+      return order[alg];
+  }
+}
+function CurveHalfOrder(alg) {
+  switch (alg) {
+    default:
+      throw new CozeAlgError("CurveHalfOrder: unsupported curve: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+    case "ES224":
+    case "ES256":
+    case "ES384":
+    case "ES512":
+      return halfOrder[alg];
+  }
+}
+function CurveOID(alg) {
+  switch (alg) {
+    default:
+      throw new CozeAlgError("alg.CurveOID: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+    case Algs.ES224:
+      return "1.3.132.0.33";
+    case Algs.ES256:
+      return "1.2.840.10045.3.1.7";
+    case Algs.ES384:
+      return "1.3.132.0.34";
+    case Algs.ES512:
+      return "1.3.132.0.35";
+    case Algs.Ed25519:
+    case Algs.Ed25519ph:
+      return "1.3.101.112";
+    case Algs.Ed448:
+      return "1.3.101.113";
+  }
+}
+function JOSEAlg(alg) {
+  switch (alg) {
+    case Algs.ES256:
+    case Algs.ES384:
+    case Algs.ES512:
+      return alg;
+    case Algs.Ed25519:
+    case Algs.Ed448:
+      return "EdDSA";
+  }
+  Genus(alg);
+  return "";
+}
+function JOSECrv(alg) {
+  switch (alg) {
+    default:
+      throw new CozeAlgError("alg.JOSECrv: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+        alg
+      });
+    case Algs.ES224:
+      return "";
+    case Algs.ES256:
+    case Algs.ES384:
+    case Algs.ES512:
+      return Curve(alg);
+    case Algs.Ed25519:
+    case Algs.Ed25519ph:
+      return "Ed25519";
+    case Algs.Ed448:
+      return "Ed448";
+  }
+}
+var coseAlgs = {
+  "ES256": -7,
+  "ES384": -35,
+  "ES512": -36,
+  "Ed25519": -19,
+  "Ed448": -53,
+  "SHA-256": -16,
+  "SHA-384": -43,
+  "SHA-512": -44,
+  "SHAKE128": -18,
+  "SHAKE256": -45
+};
+function COSEAlg(alg) {
+  Genus(alg);
+  let id = coseAlgs[alg];
+  if (id === void 0) {
+    return 0;
+  }
+  return id;
+}
+function AlgFromJOSE(name, crv) {
+  switch (name) {
+    case "ES256":
+    case "ES384":
+    case "ES512":
+    case "Ed25519":
+    case "Ed448":
+      return name;
+    case "EdDSA":
+      if (crv === "Ed25519" || crv === "Ed448") {
+        return crv;
+      }
+      throw new CozeAlgError("alg.AlgFromJOSE: EdDSA requires crv Ed25519 or Ed448, got: " + crv, ErrCodes.AlgUnsupported, {
+        alg: name
+      });
+    default:
+      throw new CozeAlgError("alg.AlgFromJOSE: unsupported JOSE alg: " + name, ErrCodes.AlgUnsupported, {
+        alg: name
+      });
+  }
+}
+function AlgFromCOSE(id) {
+  for (const alg in coseAlgs) {
+    if (coseAlgs[alg] === id) {
+      return alg;
+    }
+  }
+  throw new CozeAlgError("alg.AlgFromCOSE: unsupported COSE alg: " + id, ErrCodes.AlgUnsupported, {
+    alg: id
+  });
+}
+
+// hash.js
+async function Digest(hsh, buffer) {
+  if (isEmpty(hsh)) {
+    throw new CozeAlgError("Hash is not given", ErrCodes.AlgUnsupported);
+  }
+  if (hsh === Algs.SHA224) {
+    let h = newSHA256(true);
+    h.update(new Uint8Array(buffer));
+    return h.digest().buffer;
+  }
+  if (sha3Params[hsh] !== void 0) {
+    let h = newSHA3(hsh);
+    h.update(new Uint8Array(buffer));
+    return h.digest().buffer;
+  }
+  return crypto.subtle.digest(hsh, buffer);
+}
+async function HMAC(hsh, key, data) {
+  let blockSize = hsh === Algs.SHA384 || hsh === Algs.SHA512 ? 128 : 64;
+  if (key.length > blockSize) {
+    key = new Uint8Array(await Digest(hsh, key));
+  }
+  let ipad = new Uint8Array(blockSize + data.length);
+  let opad = new Uint8Array(blockSize);
+  for (let i = 0; i < blockSize; i++) {
+    let b = i < key.length ? key[i] : 0;
+    ipad[i] = b ^ 54;
+    opad[i] = b ^ 92;
+  }
+  ipad.set(data, blockSize);
+  let inner = new Uint8Array(await Digest(hsh, ipad));
+  let outer = new Uint8Array(blockSize + inner.length);
+  outer.set(opad);
+  outer.set(inner, blockSize);
+  return new Uint8Array(await Digest(hsh, outer));
+}
+async function Hash(alg, input) {
+  let hsh = HashAlg(alg);
+  let buffer;
+  if (typeof input === "string") {
+    buffer = await SToArrayBuffer(input);
+  } else if (input instanceof Uint8Array || input instanceof ArrayBuffer) {
+    buffer = input;
+  } else if (typeof Blob !== "undefined" && input instanceof Blob) {
+    buffer = await input.arrayBuffer();
+  } else {
+    throw new TypeError("Hash: input must be a string, Uint8Array, ArrayBuffer, or Blob.");
+  }
+  return ArrayBufferTo64ut(await Digest(hsh, buffer));
+}
+async function HashStream(alg, input, opts) {
+  let hsh = HashAlg(alg);
+  let h;
+  switch (hsh) {
+    case Algs.SHA224:
+    case Algs.SHA256:
+      h = newSHA256(hsh === Algs.SHA224);
+      break;
+    case Algs.SHA384:
+    case Algs.SHA512:
+      h = newSHA512(hsh === Algs.SHA384);
+      break;
+    case Algs.SHA3224:
+    case Algs.SHA3256:
+    case Algs.SHA3384:
+    case Algs.SHA3512:
+    case Algs.SHAKE128:
+    case Algs.SHAKE256:
+      h = newSHA3(hsh);
+      break;
+    default:
+      throw new CozeAlgError("HashStream: unsupported hashing algorithm: " + hsh, ErrCodes.AlgUnsupported, {
+        alg
+      });
+  }
+  if (isEmpty(opts)) {
+    opts = {};
+  }
+  let chunkSize = 4 * 1024 * 1024;
+  if (opts.chunkSize > 0) {
+    chunkSize = opts.chunkSize;
+  }
+  let abort = function() {
+    if (opts.signal !== void 0 && opts.signal.aborted) {
+      throw opts.signal.reason;
+    }
+  };
+  let n = 0;
+  let chunk = function(data) {
+    h.update(data);
+    n += data.length;
+    if (typeof opts.onProgress === "function") {
+      opts.onProgress(n);
+    }
+  };
+  if (typeof Blob !== "undefined" && input instanceof Blob) {
+    for (let off = 0; off < input.size; off += chunkSize) {
+      abort();
+      chunk(new Uint8Array(await input.slice(off, off + chunkSize).arrayBuffer()));
+    }
+  } else if (typeof ReadableStream !== "undefined" && input instanceof ReadableStream) {
+    let reader = input.getReader();
+    try {
+      for (; ; ) {
+        abort();
+        let r = await reader.read();
+        if (r.done) {
+          break;
+        }
+        chunk(r.value);
+      }
+    } catch (e) {
+      await reader.cancel(e);
+      throw e;
+    }
+  } else {
+    throw new TypeError("HashStream: input must be a Blob or ReadableStream.");
+  }
+  abort();
+  return ArrayBufferTo64ut(h.digest());
+}
+async function DigestPayField(pay, fieldName, file, alg, opts) {
+  pay[fieldName] = await Hash(alg, file);
+  if (isEmpty(opts)) {
+    return pay;
+  }
+  if (!isEmpty(opts.sizeField)) {
+    if (typeof file === "string") {
+      pay[opts.sizeField] = (await SToArrayBuffer(file)).byteLength;
+    } else if (typeof Blob !== "undefined" && file instanceof Blob) {
+      pay[opts.sizeField] = file.size;
+    } else {
+      pay[opts.sizeField] = file.byteLength;
+    }
+  }
+  if (!isEmpty(opts.nameField) && !isEmpty(file.name)) {
+    pay[opts.nameField] = file.name;
+  }
+  return pay;
+}
+async function DigestFiles(files, alg, opts) {
+  if (typeof Blob !== "undefined" && files instanceof Blob) {
+    files = [files];
+  }
+  files = Array.from(files);
+  if (isEmpty(opts)) {
+    opts = {};
+  }
+  let total = files.reduce((t, f) => t + f.size, 0);
+  let done = 0;
+  let digs = [];
+  for (const f of files) {
+    let dig = await HashStream(alg, f, {
+      chunkSize: opts.chunkSize,
+      signal: opts.signal,
+      onProgress: function(n) {
+        if (typeof opts.onProgress === "function") {
+          opts.onProgress(done + n, total);
+        }
+      }
+    });
+    done += f.size;
+    digs.push({
+      name: isEmpty(f.name) ? "" : f.name,
+      size: f.size,
+      dig
+    });
+  }
+  return digs;
+}
+async function DigestPayFile(pay, files, alg, opts) {
+  let digs = await DigestFiles(files, alg, opts);
+  pay.file = typeof Blob !== "undefined" && files instanceof Blob ? digs[0] : digs;
+  return pay;
+}
+async function MatchPayFile(pay, files, alg, opts) {
+  let entries = [];
+  if (Array.isArray(pay.file)) {
+    entries = pay.file;
+  } else if (typeof pay.file === "object" && pay.file !== null) {
+    entries = [pay.file];
+  }
+  let digs = await DigestFiles(files, alg, opts);
+  let results = digs.map((d) => ({
+    ...d,
+    match: entries.some((e) => e !== null && e.dig === d.dig && (e.size === void 0 || e.size === d.size))
+  }));
+  return {
+    match: results.length > 0 && results.every((r) => r.match),
+    files: results
+  };
+}
+var k256 = new Uint32Array([
+  1116352408,
+  1899447441,
+  3049323471,
+  3921009573,
+  961987163,
+  1508970993,
+  2453635748,
+  2870763221,
+  3624381080,
+  310598401,
+  607225278,
+  1426881987,
+  1925078388,
+  2162078206,
+  2614888103,
+  3248222580,
+  3835390401,
+  4022224774,
+  264347078,
+  604807628,
+  770255983,
+  1249150122,
+  1555081692,
+  1996064986,
+  2554220882,
+  2821834349,
+  2952996808,
+  3210313671,
+  3336571891,
+  3584528711,
+  113926993,
+  338241895,
+  666307205,
+  773529912,
+  1294757372,
+  1396182291,
+  1695183700,
+  1986661051,
+  2177026350,
+  2456956037,
+  2730485921,
+  2820302411,
+  3259730800,
+  3345764771,
+  3516065817,
+  3600352804,
+  4094571909,
+  275423344,
+  430227734,
+  506948616,
+  659060556,
+  883997877,
+  958139571,
+  1322822218,
+  1537002063,
+  1747873779,
+  1955562222,
+  2024104815,
+  2227730452,
+  2361852424,
+  2428436474,
+  2756734187,
+  3204031479,
+  3329325298
+]);
+var iv224 = [3238371032, 914150663, 812702999, 4144912697, 4290775857, 1750603025, 1694076839, 3204075428];
+var iv256 = [1779033703, 3144134277, 1013904242, 2773480762, 1359893119, 2600822924, 528734635, 1541459225];
+function newSHA256(is224) {
+  let h = new Uint32Array(is224 ? iv224 : iv256);
+  let w = new Uint32Array(64);
+  let block = new Uint8Array(64);
+  let blockLen = 0;
+  let total = 0;
+  let compress = function(b, off) {
+    for (let i = 0; i < 16; i++) {
+      w[i] = b[off + 4 * i] << 24 | b[off + 4 * i + 1] << 16 | b[off + 4 * i + 2] << 8 | b[off + 4 * i + 3];
+    }
+    for (let i = 16; i < 64; i++) {
+      let w15 = w[i - 15];
+      let w2 = w[i - 2];
+      let s0 = (w15 >>> 7 | w15 << 25) ^ (w15 >>> 18 | w15 << 14) ^ w15 >>> 3;
+      let s1 = (w2 >>> 17 | w2 << 15) ^ (w2 >>> 19 | w2 << 13) ^ w2 >>> 10;
+      w[i] = w[i - 16] + s0 + w[i - 7] + s1 | 0;
+    }
+    let a = h[0], b1 = h[1], c = h[2], d = h[3], e = h[4], f = h[5], g = h[6], hh = h[7];
+    for (let i = 0; i < 64; i++) {
+      let S1 = (e >>> 6 | e << 26) ^ (e >>> 11 | e << 21) ^ (e >>> 25 | e << 7);
+      let ch = e & f ^ ~e & g;
+      let t1 = hh + S1 + ch + k256[i] + w[i] | 0;
+      let S0 = (a >>> 2 | a << 30) ^ (a >>> 13 | a << 19) ^ (a >>> 22 | a << 10);
+      let maj = a & b1 ^ a & c ^ b1 & c;
+      let t2 = S0 + maj | 0;
+      hh = g;
+      g = f;
+      f = e;
+      e = d + t1 | 0;
+      d = c;
+      c = b1;
+      b1 = a;
+      a = t1 + t2 | 0;
+    }
+    h[0] += a;
+    h[1] += b1;
+    h[2] += c;
+    h[3] += d;
+    h[4] += e;
+    h[5] += f;
+    h[6] += g;
+    h[7] += hh;
+  };
+  return {
+    update: function(data) {
+      total += data.length;
+      let i = 0;
+      if (blockLen > 0) {
+        while (blockLen < 64 && i < data.length) {
+          block[blockLen++] = data[i++];
+        }
+        if (blockLen < 64) {
+          return;
+        }
+        compress(block, 0);
+        blockLen = 0;
+      }
+      for (; i + 64 <= data.length; i += 64) {
+        compress(data, i);
+      }
+      while (i < data.length) {
+        block[blockLen++] = data[i++];
+      }
+    },
+    digest: function() {
+      let bits = total * 8;
+      block[blockLen++] = 128;
+      if (blockLen > 56) {
+        block.fill(0, blockLen);
+        compress(block, 0);
+        blockLen = 0;
+      }
+      block.fill(0, blockLen);
+      let view = new DataView(block.buffer);
+      view.setUint32(56, Math.floor(bits / 4294967296));
+      view.setUint32(60, bits >>> 0);
+      compress(block, 0);
+      let out = new Uint8Array(32);
+      let outView = new DataView(out.buffer);
+      for (let i = 0; i < 8; i++) {
+        outView.setUint32(4 * i, h[i]);
+      }
+      return is224 ? out.slice(0, 28) : out;
+    }
+  };
+}
+var k512 = new Uint32Array([
+  1116352408,
+  3609767458,
+  1899447441,
+  602891725,
+  3049323471,
+  3964484399,
+  3921009573,
+  2173295548,
+  961987163,
+  4081628472,
+  1508970993,
+  3053834265,
+  2453635748,
+  2937671579,
+  2870763221,
+  3664609560,
+  3624381080,
+  2734883394,
+  310598401,
+  1164996542,
+  607225278,
+  1323610764,
+  1426881987,
+  3590304994,
+  1925078388,
+  4068182383,
+  2162078206,
+  991336113,
+  2614888103,
+  633803317,
+  3248222580,
+  3479774868,
+  3835390401,
+  2666613458,
+  4022224774,
+  944711139,
+  264347078,
+  2341262773,
+  604807628,
+  2007800933,
+  770255983,
+  1495990901,
+  1249150122,
+  1856431235,
+  1555081692,
+  3175218132,
+  1996064986,
+  2198950837,
+  2554220882,
+  3999719339,
+  2821834349,
+  766784016,
+  2952996808,
+  2566594879,
+  3210313671,
+  3203337956,
+  3336571891,
+  1034457026,
+  3584528711,
+  2466948901,
+  113926993,
+  3758326383,
+  338241895,
+  168717936,
+  666307205,
+  1188179964,
+  773529912,
+  1546045734,
+  1294757372,
+  1522805485,
+  1396182291,
+  2643833823,
+  1695183700,
+  2343527390,
+  1986661051,
+  1014477480,
+  2177026350,
+  1206759142,
+  2456956037,
+  344077627,
+  2730485921,
+  1290863460,
+  2820302411,
+  3158454273,
+  3259730800,
+  3505952657,
+  3345764771,
+  106217008,
+  3516065817,
+  3606008344,
+  3600352804,
+  1432725776,
+  4094571909,
+  1467031594,
+  275423344,
+  851169720,
+  430227734,
+  3100823752,
+  506948616,
+  1363258195,
+  659060556,
+  3750685593,
+  883997877,
+  3785050280,
+  958139571,
+  3318307427,
+  1322822218,
+  3812723403,
+  1537002063,
+  2003034995,
+  1747873779,
+  3602036899,
+  1955562222,
+  1575990012,
+  2024104815,
+  1125592928,
+  2227730452,
+  2716904306,
+  2361852424,
+  442776044,
+  2428436474,
+  593698344,
+  2756734187,
+  3733110249,
+  3204031479,
+  2999351573,
+  3329325298,
+  3815920427,
+  3391569614,
+  3928383900,
+  3515267271,
+  566280711,
+  3940187606,
+  3454069534,
+  4118630271,
+  4000239992,
+  116418474,
+  1914138554,
+  174292421,
+  2731055270,
+  289380356,
+  3203993006,
+  460393269,
+  320620315,
+  685471733,
+  587496836,
+  852142971,
+  1086792851,
+  1017036298,
+  365543100,
+  1126000580,
+  2618297676,
+  1288033470,
+  3409855158,
+  1501505948,
+  4234509866,
+  1607167915,
+  987167468,
+  1816402316,
+  1246189591
+]);
+var iv384 = [
+  3418070365,
+  3238371032,
+  1654270250,
+  914150663,
+  2438529370,
+  812702999,
+  355462360,
+  4144912697,
+  1731405415,
+  4290775857,
+  2394180231,
+  1750603025,
+  3675008525,
+  1694076839,
+  1203062813,
+  3204075428
+];
+var iv512 = [
+  1779033703,
+  4089235720,
+  3144134277,
+  2227873595,
+  1013904242,
+  4271175723,
+  2773480762,
+  1595750129,
+  1359893119,
+  2917565137,
+  2600822924,
+  725511199,
+  528734635,
+  4215389547,
+  1541459225,
+  327033209
+];
+function newSHA512(is384) {
+  let h = new Uint32Array(is384 ? iv384 : iv512);
+  let wh = new Int32Array(80);
+  let wl = new Int32Array(80);
+  let block = new Uint8Array(128);
+  let blockLen = 0;
+  let total = 0;
+  let compress = function(b, off) {
+    for (let i = 0; i < 16; i++) {
+      let j = off + 8 * i;
+      wh[i] = b[j] << 24 | b[j + 1] << 16 | b[j + 2] << 8 | b[j + 3];
+      wl[i] = b[j + 4] << 24 | b[j + 5] << 16 | b[j + 6] << 8 | b[j + 7];
+    }
+    for (let i = 16; i < 80; i++) {
+      let xh = wh[i - 15];
+      let xl = wl[i - 15];
+      let s0h = (xh >>> 1 | xl << 31) ^ (xh >>> 8 | xl << 24) ^ xh >>> 7;
+      let s0l = (xl >>> 1 | xh << 31) ^ (xl >>> 8 | xh << 24) ^ (xl >>> 7 | xh << 25);
+      xh = wh[i - 2];
+      xl = wl[i - 2];
+      let s1h = (xh >>> 19 | xl << 13) ^ (xl >>> 29 | xh << 3) ^ xh >>> 6;
+      let s1l = (xl >>> 19 | xh << 13) ^ (xh >>> 29 | xl << 3) ^ (xl >>> 6 | xh << 26);
+      let lo = (wl[i - 16] >>> 0) + (s0l >>> 0) + (wl[i - 7] >>> 0) + (s1l >>> 0);
+      wh[i] = wh[i - 16] + s0h + wh[i - 7] + s1h + Math.floor(lo / 4294967296);
+      wl[i] = lo;
+    }
+    let ah = h[0], al = h[1], bh = h[2], bl = h[3], ch = h[4], cl = h[5], dh = h[6], dl = h[7];
+    let eh = h[8], el = h[9], fh = h[10], fl = h[11], gh = h[12], gl = h[13], hh = h[14], hl = h[15];
+    for (let i = 0; i < 80; i++) {
+      let S1h = (eh >>> 14 | el << 18) ^ (eh >>> 18 | el << 14) ^ (el >>> 9 | eh << 23);
+      let S1l = (el >>> 14 | eh << 18) ^ (el >>> 18 | eh << 14) ^ (eh >>> 9 | el << 23);
+      let chh = eh & fh ^ ~eh & gh;
+      let chl = el & fl ^ ~el & gl;
+      let lo = (hl >>> 0) + (S1l >>> 0) + (chl >>> 0) + k512[2 * i + 1] + (wl[i] >>> 0);
+      let t1h = hh + S1h + chh + k512[2 * i] + wh[i] + Math.floor(lo / 4294967296) | 0;
+      let t1l = lo >>> 0;
+      let S0h = (ah >>> 28 | al << 4) ^ (al >>> 2 | ah << 30) ^ (al >>> 7 | ah << 25);
+      let S0l = (al >>> 28 | ah << 4) ^ (ah >>> 2 | al << 30) ^ (ah >>> 7 | al << 25);
+      let majh = ah & bh ^ ah & ch ^ bh & ch;
+      let majl = al & bl ^ al & cl ^ bl & cl;
+      lo = (S0l >>> 0) + (majl >>> 0);
+      let t2h = S0h + majh + Math.floor(lo / 4294967296) | 0;
+      let t2l = lo >>> 0;
+      hh = gh;
+      hl = gl;
+      gh = fh;
+      gl = fl;
+      fh = eh;
+      fl = el;
+      lo = (dl >>> 0) + t1l;
+      eh = dh + t1h + Math.floor(lo / 4294967296) | 0;
+      el = lo >>> 0;
+      dh = ch;
+      dl = cl;
+      ch = bh;
+      cl = bl;
+      bh = ah;
+      bl = al;
+      lo = t1l + t2l;
+      ah = t1h + t2h + Math.floor(lo / 4294967296) | 0;
+      al = lo >>> 0;
+    }
+    let add = function(i, xh, xl) {
+      let lo = h[i + 1] + (xl >>> 0);
+      h[i] = h[i] + xh + Math.floor(lo / 4294967296);
+      h[i + 1] = lo;
+    };
+    add(0, ah, al);
+    add(2, bh, bl);
+    add(4, ch, cl);
+    add(6, dh, dl);
+    add(8, eh, el);
+    add(10, fh, fl);
+    add(12, gh, gl);
+    add(14, hh, hl);
+  };
+  return {
+    update: function(data) {
+      total += data.length;
+      let i = 0;
+      if (blockLen > 0) {
+        while (blockLen < 128 && i < data.length) {
+          block[blockLen++] = data[i++];
+        }
+        if (blockLen < 128) {
+          return;
+        }
+        compress(block, 0);
+        blockLen = 0;
+      }
+      for (; i + 128 <= data.length; i += 128) {
+        compress(data, i);
+      }
+      while (i < data.length) {
+        block[blockLen++] = data[i++];
+      }
+    },
+    digest: function() {
+      let bits = total * 8;
+      block[blockLen++] = 128;
+      if (blockLen > 112) {
+        block.fill(0, blockLen);
+        compress(block, 0);
+        blockLen = 0;
+      }
+      block.fill(0, blockLen);
+      let view = new DataView(block.buffer);
+      view.setUint32(120, Math.floor(bits / 4294967296));
+      view.setUint32(124, bits >>> 0);
+      compress(block, 0);
+      let out = new Uint8Array(64);
+      let outView = new DataView(out.buffer);
+      for (let i = 0; i < 16; i++) {
+        outView.setUint32(4 * i, h[i]);
+      }
+      return is384 ? out.slice(0, 48) : out;
+    }
+  };
+}
+var keccakRC = new Uint32Array([
+  1,
+  0,
+  32898,
+  0,
+  32906,
+  2147483648,
+  2147516416,
+  2147483648,
+  32907,
+  0,
+  2147483649,
+  0,
+  2147516545,
+  2147483648,
+  32777,
+  2147483648,
+  138,
+  0,
+  136,
+  0,
+  2147516425,
+  0,
+  2147483658,
+  0,
+  2147516555,
+  0,
+  139,
+  2147483648,
+  32905,
+  2147483648,
+  32771,
+  2147483648,
+  32770,
+  2147483648,
+  128,
+  2147483648,
+  32778,
+  0,
+  2147483658,
+  2147483648,
+  2147516545,
+  2147483648,
+  32896,
+  2147483648,
+  2147483649,
+  0,
+  2147516424,
+  2147483648
+]);
+var keccakRho = [0, 1, 62, 28, 27, 36, 44, 6, 55, 20, 3, 10, 43, 25, 39, 41, 45, 15, 21, 8, 18, 2, 61, 56, 14];
+var sha3Params = {
+  "SHA3-224": [144, 28, 6],
+  "SHA3-256": [136, 32, 6],
+  "SHA3-384": [104, 48, 6],
+  "SHA3-512": [72, 64, 6],
+  "SHAKE128": [168, 32, 31],
+  "SHAKE256": [136, 64, 31]
+};
+function keccakF(s) {
+  let c = new Uint32Array(10);
+  let b = new Uint32Array(50);
+  for (let round = 0; round < 24; round++) {
+    for (let x = 0; x < 5; x++) {
+      c[2 * x] = s[2 * x] ^ s[2 * x + 10] ^ s[2 * x + 20] ^ s[2 * x + 30] ^ s[2 * x + 40];
+      c[2 * x + 1] = s[2 * x + 1] ^ s[2 * x + 11] ^ s[2 * x + 21] ^ s[2 * x + 31] ^ s[2 * x + 41];
+    }
+    for (let x = 0; x < 5; x++) {
+      let x1 = 2 * ((x + 1) % 5);
+      let x4 = 2 * ((x + 4) % 5);
+      let dl = c[x4] ^ (c[x1] << 1 | c[x1 + 1] >>> 31);
+      let dh = c[x4 + 1] ^ (c[x1 + 1] << 1 | c[x1] >>> 31);
+      for (let y = 0; y < 25; y += 5) {
+        s[2 * (x + y)] ^= dl;
+        s[2 * (x + y) + 1] ^= dh;
+      }
+    }
+    for (let x = 0; x < 5; x++) {
+      for (let y = 0; y < 5; y++) {
+        let i = x + 5 * y;
+        let lo = s[2 * i];
+        let hi = s[2 * i + 1];
+        let n = keccakRho[i];
+        if (n >= 32) {
+          [lo, hi] = [hi, lo];
+          n -= 32;
+        }
+        let j = 2 * (y + 5 * ((2 * x + 3 * y) % 5));
+        if (n === 0) {
+          b[j] = lo;
+          b[j + 1] = hi;
+        } else {
+          b[j] = lo << n | hi >>> 32 - n;
+          b[j + 1] = hi << n | lo >>> 32 - n;
+        }
+      }
+    }
+    for (let y = 0; y < 25; y += 5) {
+      for (let x = 0; x < 5; x++) {
+        let i = 2 * (x + y);
+        let i1 = 2 * ((x + 1) % 5 + y);
+        let i2 = 2 * ((x + 2) % 5 + y);
+        s[i] = b[i] ^ ~b[i1] & b[i2];
+        s[i + 1] = b[i + 1] ^ ~b[i1 + 1] & b[i2 + 1];
+      }
+    }
+    s[0] ^= keccakRC[2 * round];
+    s[1] ^= keccakRC[2 * round + 1];
+  }
+}
+function newSHA3(hsh) {
+  let [rate, size, ds] = sha3Params[hsh];
+  let s = new Uint32Array(50);
+  let block = new Uint8Array(rate);
+  let blockLen = 0;
+  let absorb = function() {
+    let v = new DataView(block.buffer);
+    for (let i = 0; i < rate / 4; i++) {
+      s[i] ^= v.getUint32(4 * i, true);
+    }
+    keccakF(s);
+    blockLen = 0;
+  };
+  return {
+    update: function(data) {
+      for (let i = 0; i < data.length; i++) {
+        block[blockLen++] = data[i];
+        if (blockLen === rate) {
+          absorb();
+        }
+      }
+    },
+    digest: function() {
+      block.fill(0, blockLen);
+      block[blockLen] ^= ds;
+      block[rate - 1] ^= 128;
+      absorb();
+      let out = new Uint8Array(size);
+      for (let off = 0; off < size; off += rate) {
+        if (off > 0) {
+          keccakF(s);
+        }
+        let v = new DataView(new ArrayBuffer(rate));
+        for (let i = 0; i < rate / 4; i++) {
+          v.setUint32(4 * i, s[i], true);
+        }
+        out.set(new Uint8Array(v.buffer, 0, Math.min(rate, size - off)), off);
+      }
+      return out;
+    }
+  };
+}
+
+// canon.js
+function Canon(obj) {
+  return Object.keys(obj);
+}
+async function Canonical(object, can) {
+  if (isEmpty(can)) {
+    return object;
+  }
+  return canonical(object, can);
+}
+function canonical(value, can) {
+  if (Array.isArray(value)) {
+    return value.map((v) => canonical(v, can));
+  }
+  if (value === null || typeof value !== "object") {
+    return value;
+  }
+  let obj = {};
+  for (const [f, sub] of canonFields(can)) {
+    if (sub === null || value[f] === void 0) {
+      obj[f] = value[f];
+    } else {
+      obj[f] = canonical(value[f], sub);
+    }
+  }
+  return obj;
+}
+function canonFields(can) {
+  let fields = [];
+  if (Array.isArray(can)) {
+    for (const e of can) {
+      if (typeof e === "string") {
+        fields.push([e, null]);
+      } else if (e !== null && typeof e === "object" && !Array.isArray(e)) {
+        for (const [f, sub] of Object.entries(e)) {
+          fields.push([f, sub]);
+        }
+      } else {
+        throw new CozeCanonError("Canonical: invalid canon element: " + JSON.stringify(e), ErrCodes.CanonInvalid);
+      }
+    }
+  } else if (can !== null && typeof can === "object") {
+    for (const [f, sub] of Object.entries(can)) {
+      fields.push([f, sub !== null && typeof sub === "object" ? sub : null]);
+    }
+  } else {
+    throw new CozeCanonError("Canonical: canon must be an array or object.", ErrCodes.CanonInvalid);
+  }
+  let names = new Set(fields.map((f) => f[0]));
+  if (names.size !== fields.length) {
+    throw new CozeCanonError("Canonical: Canon cannot have duplicate fields.", ErrCodes.CanonInvalid);
+  }
+  return fields;
+}
+async function CanonicalS(obj, can, opts) {
+  if (!isEmpty(opts) && opts.normalizeUnicode === true) {
+    obj = NormalizeUnicode(obj);
+  }
+  return JSON.stringify(await Canonical(obj, can));
+}
+function NormalizeUnicode(value) {
+  if (typeof value === "string") {
+    return value.normalize("NFC");
+  }
+  if (Array.isArray(value)) {
+    return value.map(NormalizeUnicode);
+  }
+  if (value !== null && typeof value === "object") {
+    let obj = {};
+    for (const k of Object.keys(value)) {
+      obj[k] = NormalizeUnicode(value[k]);
+    }
+    return obj;
+  }
+  return value;
+}
+async function CanonicalHash(input, hash, can, opts) {
+  if (isEmpty(hash)) {
+    throw new CozeAlgError("Hash is not given", ErrCodes.AlgUnsupported);
+  }
+  if (input instanceof Uint8Array || input instanceof ArrayBuffer) {
+    if (isEmpty(can) && isEmpty(opts)) {
+      return await Digest(hash, input);
+    }
+    input = JSON.parse(new TextDecoder().decode(input));
+  }
+  return await Digest(hash, await SToArrayBuffer(await CanonicalS(input, can, opts)));
+}
+async function CanonicalHash64(obj, hash, can, opts) {
+  return await ArrayBufferTo64ut(await CanonicalHash(obj, hash, can, opts));
+}
+
+// ecdsa.js
+var curves = {
+  "ES224": {
+    p: BigInt("0xffffffffffffffffffffffffffffffff000000000000000000000001"),
+    b: BigInt("0xb4050a850c04b3abf54132565044b0b7d7bfd8ba270b39432355ffb4"),
+    gx: BigInt("0xb70e0cbd6bb4bf7f321390b94a03c1d356c21122343280d6115c1d21"),
+    gy: BigInt("0xbd376388b5f723fb4c22dfe6cd4375a05a07476444d5819985007e34")
+  },
+  "ES256": {
+    p: BigInt("0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),
+    b: BigInt("0x5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),
+    gx: BigInt("0x6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),
+    gy: BigInt("0x4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5")
+  },
+  "ES384": {
+    p: BigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff"),
+    b: BigInt("0xb3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef"),
+    gx: BigInt("0xaa87ca22be8b05378eb1c71ef320ad746e1d3b628ba79b9859f741e082542a385502f25dbf55296c3a545e3872760ab7"),
+    gy: BigInt("0x3617de4a96262c6f5d9e98bf9292dc29f8f41dbd289a147ce9da3113b5f0b8c00a60b1ce1d7e819d7a431d7c90ea0e5f")
+  },
+  "ES512": {
+    p: (1n << 521n) - 1n,
+    b: BigInt("0x0051953eb9618e1c9a1f929a21a0b68540eea2da725b99b315f3b8b489918ef109e156193951ec7e937b1652c0bd3bb1bf073573df883d2c34f1ef451fd46b503f00"),
+    gx: BigInt("0x00c6858e06b70404e9cd9e3ecb662395b4429c648139053fb521f828af606b4d3dbaa14b5e77efe75928fe1dc127a2ffa8de3348b3c1856a429bf97e7e31c2e5bd66"),
+    gy: BigInt("0x011839296a789a3bc0045c8a5fb42c7d1bd998f54449579b446817afbd17273e662c97ee72995ef42640c550b9013fad0761353c7086a272c24088be94769fd16650")
+  }
+};
+var ECDSA = {
+  /**
+  New returns a Javascript ECDSA key pair in the form of CryptoKeyPair.  The
+  keys are not SubtleCrypto CryptoKeys.  See `FromCozeKey`.
+  @param   {Alg}     alg
+  @returns {object}  {privateKey, publicKey}
+  @throws  {error}
+  */
+  New: async function(alg) {
+    let d = randomScalar(alg);
+    let cozeKey = {
+      alg,
+      d: ArrayBufferTo64ut(bigIntToBytes(DSize(alg), d)),
+      x: await ECDSA.PublicFromD(alg, d)
+    };
+    return {
+      privateKey: await ECDSA.FromCozeKey(cozeKey),
+      publicKey: await ECDSA.FromCozeKey(cozeKey, true)
+    };
+  },
+  /**
+  FromCozeKey returns a Javascript ECDSA key from a Coze key. The returned
+  object mimics a CryptoKey (`type`, `algorithm`, `usages`) so that it may be
+  used in place of a SubtleCrypto CryptoKey by the functions in `cryptokey.js`.
+  @param   {Key}        cozeKey
+  @param   {boolean}    [onlyPublic=false]   Return only a public key.
+  @returns {object}
+  @throws  {error}      Fails on invalid keys.
+  */
+  FromCozeKey: async function(cozeKey, onlyPublic) {
+    let c = curve(cozeKey.alg);
+    let xy = B64ToUint8Array(cozeKey.x);
+    if (xy.length !== XSize(cozeKey.alg)) {
+      throw new CozeKeyError("ECDSA.FromCozeKey: incorrect x size.", ErrCodes.KeyInvalid, {
+        field: "x"
+      });
+    }
+    let half = XSize(cozeKey.alg) / 2;
+    let point = {
+      x: bytesToBigInt(xy.slice(0, half)),
+      y: bytesToBigInt(xy.slice(half))
+    };
+    if (!onCurve(c, point)) {
+      throw new CozeKeyError("ECDSA.FromCozeKey: the key is not on the curve.", ErrCodes.KeyInvalid, {
+        field: "x"
+      });
+    }
+    let key = {
+      type: "public",
+      extractable: true,
+      algorithm: {
+        name: GenAlgs.ECDSA,
+        namedCurve: Curve(cozeKey.alg)
+      },
+      usages: ["verify"],
+      ecdsa: {
+        alg: cozeKey.alg,
+        point
+      }
+    };
+    if (!isEmpty(cozeKey.d) && !onlyPublic) {
+      let d = bytesToBigInt(B64ToUint8Array(cozeKey.d));
+      if (d <= 0n || d >= c.n) {
+        throw new CozeKeyError("ECDSA.FromCozeKey: invalid private key.", ErrCodes.KeyInvalid, {
+          field: "d"
+        });
+      }
+      key.type = "private";
+      key.usages = ["sign"];
+      key.ecdsa.d = d;
+    }
+    return key;
+  },
+  /**
+  IsKey reports whether the given key is a Javascript ECDSA key.
+  @param   {CryptoKey|object}  key
+  @returns {boolean}
+  */
+  IsKey: function(key) {
+    return typeof key === "object" && key !== null && typeof key.ecdsa === "object";
+  },
+  /**
+  ToCozeKey returns a Coze key, without `tmb`, from a Javascript ECDSA key.
+  @param   {object}   key
+  @returns {Key}
+  */
+  ToCozeKey: function(key) {
+    let alg = key.ecdsa.alg;
+    let size = XSize(alg) / 2;
+    let czk = {
+      alg,
+      x: ArrayBufferTo64ut(concat(bigIntToBytes(size, key.ecdsa.point.x), bigIntToBytes(size, key.ecdsa.point.y)))
+    };
+    if (key.ecdsa.d !== void 0) {
+      czk.d = ArrayBufferTo64ut(bigIntToBytes(DSize(alg), key.ecdsa.d));
+    }
+    return czk;
+  },
+  /**
+  PublicFromD calculates the b64ut `x` (x || y) from the private scalar `d`.
+  @param   {Alg}             alg
+  @param   {BigInt}          d
+  @returns {string}
+  @throws  {error}
+  */
+  PublicFromD: async function(alg, d) {
+    let c = curve(alg);
+    let p = toAffine(c, scalarMult(c, d, {
+      x: c.gx,
+      y: c.gy,
+      z: 1n
+    }));
+    let size = XSize(alg) / 2;
+    return ArrayBufferTo64ut(concat(bigIntToBytes(size, p.x), bigIntToBytes(size, p.y)));
+  },
+  /**
+  KeyFromSeed derives a private Coze key, without `tmb`, from seed.  The
+  private scalar `d` is derived with HKDF (RFC 5869) using alg's hashing
+  algorithm, salt "Coze NewKeyFromSeed", and info alg || counter, where
+  counter is a single byte starting at 0.  Each candidate is DSize bytes with
+  bits beyond the order's bit length discarded, and is rejected if not in
+  [1, n-1], in which case counter is incremented.  This gives a uniformly
+  distributed `d` that is the same in every implementation.
+  @param   {Alg}          alg
+  @param   {Uint8Array}   seed
+  @returns {Key}
+  @throws  {error}
+  */
+  KeyFromSeed: async function(alg, seed) {
+    let c = curve(alg);
+    let hsh = HashAlg(alg);
+    let size = Math.ceil(c.nBits / 8);
+    let excess = BigInt(size * 8 - c.nBits);
+    let prk = await HMAC(hsh, new TextEncoder().encode("Coze NewKeyFromSeed"), seed);
+    for (let counter = 0; counter < 256; counter++) {
+      let info = concat(new TextEncoder().encode(alg), new Uint8Array([counter]));
+      let d = bytesToBigInt(await hkdfExpand(hsh, prk, info, size)) >> excess;
+      if (d > 0n && d < c.n) {
+        return {
+          alg,
+          d: ArrayBufferTo64ut(bigIntToBytes(DSize(alg), d)),
+          x: await ECDSA.PublicFromD(alg, d)
+        };
+      }
+    }
+    throw new CozeKeyError("ECDSA.KeyFromSeed: no valid scalar derived.", ErrCodes.KeyInvalid, {
+      field: "seed"
+    });
+  },
+  /**
+  SignBuffer hashes the message using alg's hashing algorithm and signs the
+  digest.  Returns the signature (r || s) as an ArrayBuffer.
+  @param   {object}        key              Private Javascript ECDSA key.
+  @param   {ArrayBuffer}   buffer           Message.
+  @param   {boolean}       [deterministic]  Use RFC 6979 nonces.
+  @returns {ArrayBuffer}
+  @throws  {error}
+  */
+  SignBuffer: async function(key, buffer, deterministic) {
+    let alg = key.ecdsa.alg;
+    let dig = await Digest(HashAlg(alg), buffer);
+    return ECDSA.SignDigest(key, new Uint8Array(dig), deterministic);
+  },
+  /**
+  SignDigest signs a digest.  The digest is not hashed again.  Returns the
+  signature (r || s) as an ArrayBuffer.  If deterministic, the nonce `k` is
+  derived from the private key and the digest as specified by RFC 6979 using
+  alg's hashing algorithm, otherwise `k` is random.
+  @param   {object}      key              Private Javascript ECDSA key.
+  @param   {Uint8Array}  digest
+  @param   {boolean}     [deterministic]  Use RFC 6979 nonces.
+  @returns {ArrayBuffer}
+  @throws  {error}
+  */
+  SignDigest: async function(key, digest, deterministic) {
+    if (key.type !== "private") {
+      throw new CozeKeyError("ECDSA.SignDigest: key must be private.", ErrCodes.KeyInvalid, {
+        field: "d"
+      });
+    }
+    let alg = key.ecdsa.alg;
+    let c = curve(alg);
+    let e = bits2int(c, digest);
+    let nonce = null;
+    if (deterministic === true) {
+      nonce = await rfc6979(alg, key.ecdsa.d, digest);
+    }
+    for (; ; ) {
+      let k = nonce === null ? randomScalar(alg) : await nonce.next();
+      let r = mod(toAffine(c, scalarMult(c, k, {
+        x: c.gx,
+        y: c.gy,
+        z: 1n
+      })).x, c.n);
+      if (r === 0n) {
+        continue;
+      }
+      let s = mod(modInv(k, c.n) * (e + r * key.ecdsa.d), c.n);
+      if (s === 0n) {
+        continue;
+      }
+      let half = SigSize(alg) / 2;
+      return concat(bigIntToBytes(half, r), bigIntToBytes(half, s)).buffer;
+    }
+  },
+  /**
+  VerifyBuffer hashes the message using alg's hashing algorithm and verifies
+  the signature over the digest.
+  @param   {object}        key      Javascript ECDSA key.
+  @param   {ArrayBuffer}   buffer   Message.
+  @param   {ArrayBuffer}   sig      Signature (r || s).
+  @returns {boolean}
+  */
+  VerifyBuffer: async function(key, buffer, sig) {
+    let dig = await Digest(HashAlg(key.ecdsa.alg), buffer);
+    return ECDSA.VerifyDigest(key, new Uint8Array(dig), sig);
+  },
+  /**
+  VerifyDigest verifies a signature over a digest.  The digest is not hashed
+  again.
+  @param   {object}        key      Javascript ECDSA key.
+  @param   {Uint8Array}    digest
+  @param   {ArrayBuffer}   sig      Signature (r || s).
+  @returns {boolean}
+  */
+  VerifyDigest: async function(key, digest, sig) {
+    let alg = key.ecdsa.alg;
+    let c = curve(alg);
+    sig = new Uint8Array(sig);
+    if (sig.length !== SigSize(alg)) {
+      return false;
+    }
+    let half = SigSize(alg) / 2;
+    let r = bytesToBigInt(sig.slice(0, half));
+    let s = bytesToBigInt(sig.slice(half));
+    if (r <= 0n || r >= c.n || s <= 0n || s >= c.n) {
+      return false;
+    }
+    let e = bits2int(c, digest);
+    let w = modInv(s, c.n);
+    let u1 = scalarMult(c, mod(e * w, c.n), {
+      x: c.gx,
+      y: c.gy,
+      z: 1n
+    });
+    let u2 = scalarMult(c, mod(r * w, c.n), {
+      x: key.ecdsa.point.x,
+      y: key.ecdsa.point.y,
+      z: 1n
+    });
+    let p = pointAdd(c, u1, u2);
+    if (p.z === 0n) {
+      return false;
+    }
+    return mod(toAffine(c, p).x, c.n) === r;
+  }
+};
+function curve(alg) {
+  let c = curves[alg];
+  if (c === void 0) {
+    throw new CozeAlgError("ECDSA: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+      alg
+    });
+  }
+  if (c.n === void 0) {
+    c.n = CurveOrder(alg);
+    c.nBits = c.n.toString(2).length;
+  }
+  return c;
+}
+function randomScalar(alg) {
+  let c = curve(alg);
+  let size = Math.ceil(c.nBits / 8);
+  let excess = BigInt(size * 8 - c.nBits);
+  for (; ; ) {
+    let k = bytesToBigInt(crypto.getRandomValues(new Uint8Array(size))) >> excess;
+    if (k > 0n && k < c.n) {
+      return k;
+    }
+  }
+}
+async function rfc6979(alg, d, digest) {
+  let c = curve(alg);
+  let hsh = HashAlg(alg);
+  let rlen = Math.ceil(c.nBits / 8);
+  let hlen = HashSize(alg);
+  let hmac = (key, ...data) => HMAC(hsh, key, concatAll(data));
+  let x = bigIntToBytes(rlen, d);
+  let h = bigIntToBytes(rlen, mod(bits2int(c, digest), c.n));
+  let v = new Uint8Array(hlen).fill(1);
+  let k = new Uint8Array(hlen);
+  k = await hmac(k, v, [0], x, h);
+  v = await hmac(k, v);
+  k = await hmac(k, v, [1], x, h);
+  v = await hmac(k, v);
+  let started = false;
+  return {
+    next: async function() {
+      for (; ; ) {
+        if (started) {
+          k = await hmac(k, v, [0]);
+          v = await hmac(k, v);
+        }
+        started = true;
+        let t = new Uint8Array(0);
+        while (t.length < rlen) {
+          v = await hmac(k, v);
+          t = concat(t, v);
+        }
+        let candidate = bits2int(c, t);
+        if (candidate > 0n && candidate < c.n) {
+          return candidate;
+        }
+      }
+    }
+  };
+}
+function bits2int(c, digest) {
+  let e = bytesToBigInt(digest);
+  let bits = digest.length * 8;
+  if (bits > c.nBits) {
+    e >>= BigInt(bits - c.nBits);
+  }
+  return e;
+}
+function mod(a, m) {
+  let r = a % m;
+  return r < 0n ? r + m : r;
+}
+function modInv(a, m) {
+  let [oldR, r] = [mod(a, m), m];
+  let [oldS, s] = [1n, 0n];
+  while (r !== 0n) {
+    let q = oldR / r;
+    [oldR, r] = [r, oldR - q * r];
+    [oldS, s] = [s, oldS - q * s];
+  }
+  if (oldR !== 1n) {
+    throw new CozeKeyError("ECDSA: no modular inverse.", ErrCodes.KeyInvalid);
+  }
+  return mod(oldS, m);
+}
+function onCurve(c, pt) {
+  if (pt.x < 0n || pt.x >= c.p || pt.y < 0n || pt.y >= c.p) {
+    return false;
+  }
+  return mod(pt.y * pt.y - (pt.x * pt.x * pt.x - 3n * pt.x + c.b), c.p) === 0n;
+}
+function toAffine(c, pt) {
+  let zInv = modInv(pt.z, c.p);
+  let zInv2 = mod(zInv * zInv, c.p);
+  return {
+    x: mod(pt.x * zInv2, c.p),
+    y: mod(pt.y * zInv2 * zInv, c.p)
+  };
+}
+function pointDouble(c, pt) {
+  if (pt.z === 0n || pt.y === 0n) {
+    return {
+      x: 0n,
+      y: 1n,
+      z: 0n
+    };
+  }
+  let p = c.p;
+  let delta = mod(pt.z * pt.z, p);
+  let gamma = mod(pt.y * pt.y, p);
+  let beta = mod(pt.x * gamma, p);
+  let alpha = mod(3n * (pt.x - delta) * (pt.x + delta), p);
+  let x = mod(alpha * alpha - 8n * beta, p);
+  let z = mod((pt.y + pt.z) * (pt.y + pt.z) - gamma - delta, p);
+  let y = mod(alpha * (4n * beta - x) - 8n * gamma * gamma, p);
+  return {
+    x,
+    y,
+    z
+  };
+}
+function pointAdd(c, p1, p2) {
+  if (p1.z === 0n) {
+    return p2;
+  }
+  if (p2.z === 0n) {
+    return p1;
+  }
+  let p = c.p;
+  let z1z1 = mod(p1.z * p1.z, p);
+  let z2z2 = mod(p2.z * p2.z, p);
+  let u1 = mod(p1.x * z2z2, p);
+  let u2 = mod(p2.x * z1z1, p);
+  let s1 = mod(p1.y * p2.z * z2z2, p);
+  let s2 = mod(p2.y * p1.z * z1z1, p);
+  let h = mod(u2 - u1, p);
+  let r = mod(2n * (s2 - s1), p);
+  if (h === 0n) {
+    if (r === 0n) {
+      return pointDouble(c, p1);
+    }
+    return {
+      x: 0n,
+      y: 1n,
+      z: 0n
+    };
+  }
+  let i = mod(4n * h * h, p);
+  let j = mod(h * i, p);
+  let v = mod(u1 * i, p);
+  let x = mod(r * r - j - 2n * v, p);
+  let y = mod(r * (v - x) - 2n * s1 * j, p);
+  let z = mod(((p1.z + p2.z) * (p1.z + p2.z) - z1z1 - z2z2) * h, p);
+  return {
+    x,
+    y,
+    z
+  };
+}
+function scalarMult(c, k, pt) {
+  let result = {
+    x: 0n,
+    y: 1n,
+    z: 0n
+  };
+  for (let i = BigInt(k.toString(2).length - 1); i >= 0n; i--) {
+    result = pointDouble(c, result);
+    if (k >> i & 1n) {
+      result = pointAdd(c, result, pt);
+    }
+  }
+  return result;
+}
+async function hkdfExpand(hsh, prk, info, length) {
+  let out = new Uint8Array(0);
+  let t = new Uint8Array(0);
+  for (let i = 1; out.length < length; i++) {
+    t = await HMAC(hsh, prk, concatAll([t, info, [i]]));
+    out = concat(out, t);
+  }
+  return out.slice(0, length);
+}
+function bytesToBigInt(bytes) {
+  let result = 0n;
+  for (let b of bytes) {
+    result = (result << 8n) + BigInt(b);
+  }
+  return result;
+}
+function bigIntToBytes(size, n) {
+  let out = new Uint8Array(size);
+  for (let i = size - 1; i >= 0; i--) {
+    out[i] = Number(n & 0xffn);
+    n >>= 8n;
+  }
+  return out;
+}
+function concat(a, b) {
+  let out = new Uint8Array(a.length + b.length);
+  out.set(a, 0);
+  out.set(b, a.length);
+  return out;
+}
+function concatAll(arrays) {
+  let out = new Uint8Array(0);
+  for (let a of arrays) {
+    out = concat(out, new Uint8Array(a));
+  }
+  return out;
+}
+
+// cryptokey.js
+var keyCache = /* @__PURE__ */ new WeakMap();
+function ClearKeyCache() {
+  keyCache = /* @__PURE__ */ new WeakMap();
+}
+var CryptoKey = {
+  /**
+  New returns a ECDSA CryptoKeyPair. 
+  https://developer.mozilla.org/en-US/docs/Web/API/CryptoKeyPair
+  @param  {Alg}           [alg=ES256] - Alg of the key to generate. (e.g. "ES256")
+  @return {CryptoKeyPair}
+  @throws {error}         Error, SyntaxError, DOMException, TypeError
+  */
+  New: async function(alg) {
+    if (isEmpty(alg)) {
+      alg = Algs.ES256;
+    }
+    switch (alg) {
+      case Algs.ES224:
+        return ECDSA.New(alg);
+      case Algs.ES256:
+      case Algs.ES384:
+      case Algs.ES512:
+        return await crypto.subtle.generateKey(
+          {
+            name: GenAlgs.ECDSA,
+            namedCurve: Curve(alg)
+          },
+          true,
+          ["sign", "verify"]
+        );
+      case Algs.Ed25519:
+        try {
+          return await crypto.subtle.generateKey(
+            {
+              name: Algs.Ed25519
+            },
+            true,
+            ["sign", "verify"]
+          );
+        } catch (e) {
+          throw unsupportedErr("CryptoKey.New", alg, e);
+        }
+      default:
+        throw new CozeAlgError("CryptoKey.New: Unsupported key algorithm:" + alg, ErrCodes.AlgUnsupported, {
+          alg
+        });
+    }
+  },
+  /**
+  	FromCozeKey returns a Javascript CryptoKey from a Coze Key.  Only supports
+  	ECDSA and Ed25519 because of Crypto.subtle limitations.  Since
+  	SubtleCrypto does not implement P-224, ES224 keys are Javascript ECDSA keys
+  	that are used in place of a CryptoKey.  (See `ecdsa.js`.)  Throws error on
+  	invalid keys.
+  
+  	Imported keys are cached by Coze key object so that repeated operations with
+  	the same key object do not import again.  The cache is invalidated if `alg`,
+  	`x`, or `d` of the Coze key changes.  See ClearKeyCache.
+  	https://developer.mozilla.org/en-US/docs/Web/API/SubtleCrypto/importKey#JSON_Web_Key
+  	@param   {Key}        cozeKey          Coze key.
+  	@param   {boolean}    [public=false]   Return only a public key.
+  	@returns {CryptoKey}
+  	@throws  {error}                Error, SyntaxError, DOMException, TypeError
+  	*/
+  FromCozeKey: async function(cozeKey, onlyPublic) {
+    let usage = isEmpty(cozeKey.d) || onlyPublic ? "verify" : "sign";
+    let entry = keyCache.get(cozeKey);
+    if (entry === void 0) {
+      entry = {};
+      keyCache.set(cozeKey, entry);
+    }
+    let cached = entry[usage];
+    if (cached !== void 0 && cached.alg === cozeKey.alg && cached.x === cozeKey.x && cached.d === cozeKey.d) {
+      return cached.key;
+    }
+    let key = importCozeKey(cozeKey, onlyPublic);
+    entry[usage] = {
+      alg: cozeKey.alg,
+      x: cozeKey.x,
+      d: cozeKey.d,
+      key
+    };
+    try {
+      return await key;
+    } catch (e) {
+      if (entry[usage] !== void 0 && entry[usage].key === key) {
+        delete entry[usage];
+      }
+      throw e;
+    }
+  },
+  /**
+  ToPublic accepts a Javascript CryptoKey and modifies the key to remove
+  any private components.
+  @param   {CryptoKey} cryptoKey
+  @returns {void}
+  */
+  ToPublic: async function(cryptoKey) {
+    delete cryptoKey.d;
+    cryptoKey.key_ops = ["verify"];
+  },
+  /**
+  CryptoKeyToCozeKey returns a Coze Key from Javascript's "CryptoKey" type.
+  (https://developer.mozilla.org/en-US/docs/Web/API/CryptoKey) Coze keys are
+  similiar to JOSE JWK's but has a few significant differences.
+  See the Coze docs for more on these differences.
+  
+  - Coze Byte-to-string values are always b64ut, "RFC 4648 base64 URI Safe
+  Truncated".
+  - Coze keys also use the field `alg` to denote everything about the key:
+  it's use, hashing algorithm, curve, family, signature size, private
+  component size, public component size, etc...
+  - A Coze key's Thumbprint's hashing algorithm must always be in alignment
+  with the alg.  This is unlike JOSE which appears to use SHA-256 even for
+  keys that don't use that algorithm.
+  
+  This function currently only supports ECDSA (ES256. ES384, ES512) and
+  Ed25519 as crypto.subtle only supports these algorithms. From Cryptokey,
+  `exported` key output should is in the following form:
+  
+  {
+  "crv": "P-256",
+  "d": "GwJgQIcbB29IfWO46QZwansE5XVVOg_CfafcpGk3K9I",
+  "key_ops": [
+  "sign",
+  "verify"
+  ],
+  "kty": "EC",
+  "x": "bMgUwXPLFR5WPERFIdUR8f6J9znFlM4fL-TaYr7YNSo",
+  "y": "vuU0bE-JafF1zEW_MbL-oaO0eGltDeMHIfc_bxkdCHU",
+  "use": "sig"
+  }
+  
+  Some aspects of the Javascript exported key are in conflict with JOSE. The
+  `delete`s below are for reference of how out of alignment the Javascript
+  representation is from JOSE.  If for some reason a JOSE representation is
+  required, the deletes are suggested.
+  
+  `delete exported.key_ops;`
+  
+  According to RFC 7517 Section 4.3, "use" is mutually exclusive with
+  key_ops.
+  
+  `delete exported["ext"];`
+  
+  `ext` is define by the Web Cryptography API and does not appear in the
+  core JOSE RFC's.  It stands for "extractable".  Since the key is already
+  "extracted" we don't care, and we're not going to burden downstream with
+  it.  However, this may need to be added again later if the key is further
+  manipulated by SubtleCrypto. 
+  
+  Coze does not use "crv", "kty", or "use" and instead relies solely on
+  "alg". Since alg is not given, it's assumed from `crv` while `kty`is
+  ignored.
+  
+  Why are we exporting to JWK?
+  1. There's no access to the key fields without exporting. (The
+  browser hides the information from Javascript.)
+  2. The exporting formats are limited.  
+  3. Can't export to "raw" because "raw" appears to only work on public
+  keys.  This may be a private key.
+  @param   {CryptoKey}   cryptoKey 
+  @returns {Key}
+  @throws  {error}
+  */
+  ToCozeKey: async function(cryptoKey) {
+    if (ECDSA.IsKey(cryptoKey)) {
+      let k = ECDSA.ToCozeKey(cryptoKey);
+      k.tmb = await Thumbprint(k);
+      return k;
+    }
+    let exported = await crypto.subtle.exportKey(
+      "jwk",
+      cryptoKey
+    );
+    return JWKToCozeKey(exported);
+  },
+  /**
+  Uses a Javascript `CryptoKey` to sign a array buffer.  Returns array buffer
+  bytes of the signature. Returns empty buffer on error.The signing algorithm's
+  hashing algorithm is used for the digest of the payload.  Coze uses UTF-8.
+  https://developer.mozilla.org/en-US/docs/Web/API/SubtleCrypto/importKey#JSON_Web_Key
+  https://developer.mozilla.org/en-US/docs/Web/JavaScript/Reference/Global_Objects/ArrayBuffer
+  @param   {CryptoKey}      cryptoKey
+  @param   {ArrayBuffer}    payloadBuffer
+  @returns {ArrayBuffer}
+  @throws  {error}
+  */
+  SignBuffer: async function(cryptoKey, arrayBuffer) {
+    let alg = await CryptoKey.algFromCryptoKey(cryptoKey);
+    if (ECDSA.IsKey(cryptoKey)) {
+      var sig = await ECDSA.SignBuffer(cryptoKey, arrayBuffer);
+    } else {
+      sig = await crypto.subtle.sign(
+        subtleParams(alg),
+        cryptoKey,
+        arrayBuffer
+      );
+    }
+    if (Genus(alg) == GenAlgs.ECDSA) {
+      sig = sigToLowSArrayBuffer(alg, sig);
+    }
+    return sig;
+  },
+  /**
+  SignBufferB64 signs a buffer with a CryptoKey and returns the b64ut
+  signature. The input is hashed before it's signed.
+  Coze uses UTF-8.
+  @param   {CryptoKey}   cryptoKey       Private CryptoKey
+  @param   {ArrayBuffer} arrayBuffer     ArrayBuffer to sign.
+  @returns {B64}
+  */
+  SignBufferB64: async function(cryptoKey, arrayBuffer) {
+    return await ArrayBufferTo64ut(await CryptoKey.SignBuffer(cryptoKey, arrayBuffer));
+  },
+  /**
+  SignString signs a string and returns the b64ut signature.
+  Coze uses UTF-8.
+  @param   {CryptoKey} cryptoKey      Private key used for signing.
+  @param   {string}    utf8           String to sign.
+  @returns {B64}
+  */
+  SignString: async function(cryptoKey, utf8) {
+    return await CryptoKey.SignBufferB64(cryptoKey, await SToArrayBuffer(utf8));
+  },
+  /**
+  VerifyArrayBuffer verifies an ArrayBuffer msg with an ArrayBuffer sig and
+  Javascript CryptoKey.
+  Returns whether or not message is verified by the given key and signature.
+  For interoperability, ECDSA signatures are accepted in both low-S and
+  high-S form.  See VerifyOpts.requireLowS for refusing high-S.
+  @param   {Alg}         alg
+  @param   {CryptoKey}   cryptoKey           Javascript CryptoKey.
+  @param   {ArrayBuffer} sig                 Signature.
+  @param   {ArrayBuffer} msg                 Message.
+  @returns {boolean}
+  */
+  VerifyArrayBuffer: async function(alg, cryptoKey, msg, sig) {
+    if (ECDSA.IsKey(cryptoKey)) {
+      return ECDSA.VerifyBuffer(cryptoKey, msg, sig);
+    }
+    await CryptoKey.ToPublic(cryptoKey);
+    return await crypto.subtle.verify(
+      subtleParams(await CryptoKey.algFromCryptoKey(cryptoKey)),
+      cryptoKey,
+      sig,
+      msg
+    );
+  },
+  /**
+  VerifyMsg uses a public key to verify a string msg with a b64ut sig.
+  Returns whether or not the signature is valid.
+  @param   {Alg}        alg
+  @param   {CryptoKey}  cryptoKey         Javascript CryptoKey.
+  @param   {Msg}        msg               String that was signed.
+  @param   {Sig}        sig               B64 signature.
+  @returns {boolean}
+  */
+  VerifyMsg: async function(alg, cryptoKey, msg, sig) {
+    return CryptoKey.VerifyArrayBuffer(alg, cryptoKey, await SToArrayBuffer(msg), await B64uToArrayBuffer(sig));
+  },
+  /**
+  	GetSignHashAlgoFromCryptoKey gets the signing hashing algorithm from the
+  	CryptoKey.
+  	Returns the name of the hashing algorithm. E.g. "SHA-256".
+  
+  	Javascript's CryptoKey explicitly requires a signing hashing algorithm, but
+  	the CryptoKey itself may not explicitly contain that information. For
+  	example, a ES256 key will have the curve (P-256) and the general key type
+  	(ECDSA), but the hashing algo is not explicitly stated (SHA-256), nor is
+  	the algorithm explicitly stated (ES256).
+  
+  	However, for some CryptoKeys, the hashing algorithm is explicitly stated.
+  	For example, "RsaHashedKeyGenParams" has the field "hash" which explicitly
+  	denotes what hashing algorithm was used.  As of 2021/05/26,
+  	"EcKeyGenParams" has no such field, so it must be assumed that certain
+  	hashing algorithms are paired with certain curves.
+  
+  	The purpose of this function is to return the correct hashing digest for
+  	all CryptoKeys regardless of their form.
+  	@param   {CryptoKey} CryptoKey  CryptoKey Javascript object.
+  	@returns {Hsh}
+  	@throws  {error}                Fails if alg is not supported.
+  	*/
+  GetSignHashAlgoFromCryptoKey: async function(cryptoKey) {
+    return HashAlg(await CryptoKey.algFromCryptoKey(cryptoKey));
+  },
+  /**
+  algFromCryptoKey returns the Coze alg for the given CryptoKey.  ECDSA
+  CryptoKeys denote the curve while Ed25519 CryptoKeys denote the algorithm
+  name.
+  @param   {CryptoKey} cryptoKey
+  @returns {Alg}
+  @throws  {error}                Fails if alg is not supported.
+  */
+  algFromCryptoKey: async function(cryptoKey) {
+    if (cryptoKey.algorithm.name === Algs.Ed25519) {
+      return Algs.Ed25519;
+    }
+    return CryptoKey.algFromCrv(cryptoKey.algorithm.namedCurve);
+  },
+  /**
+  algFromCrv returns a SEAlg from the given curve.  The JWK curve "Ed25519"
+  is also accepted.
+  Fails if curve is not supported.
+  @param   {Crv}     src    Curve type. E.g. "P-256".
+  @returns {Alg}
+  @throws  {error}
+  */
+  algFromCrv: async function(crv) {
+    switch (crv) {
+      case Algs.Ed25519:
+        var alg = Algs.Ed25519;
+        break;
+      case Curves.P224:
+        alg = Algs.ES224;
+        break;
+      case Curves.P256:
+        alg = Algs.ES256;
+        break;
+      case Curves.P384:
+        alg = Algs.ES384;
+        break;
+      case Curves.P521:
+        alg = Algs.ES512;
+        break;
+      default:
+        throw new CozeAlgError("CryptoKey.ToCozeKey: Unsupported key algorithm.", ErrCodes.AlgUnsupported, {
+          crv
+        });
+    }
+    return alg;
+  }
+};
+function CozeKeyToJWK(cozeKey) {
+  if (isEmpty(cozeKey.x)) {
+    throw new CozeKeyError("CozeKeyToJWK: key x must be set.", ErrCodes.KeyInvalid, {
+      field: "x"
+    });
+  }
+  var jwk = {};
+  switch (cozeKey.alg) {
+    case Algs.Ed25519:
+      jwk.kty = "OKP";
+      jwk.crv = Algs.Ed25519;
+      jwk.alg = "EdDSA";
+      jwk.use = Uses.Sig;
+      jwk.x = cozeKey.x;
+      break;
+    case Algs.ES256:
+    case Algs.ES384:
+    case Algs.ES512: {
+      jwk.kty = FamAlgs.EC;
+      jwk.crv = Curve(cozeKey.alg);
+      jwk.alg = cozeKey.alg;
+      jwk.use = Uses.Sig;
+      let half = XSize(cozeKey.alg) / 2;
+      let xy = B64ToUint8Array(cozeKey.x);
+      if (xy.length !== half * 2) {
+        throw new CozeKeyError("CozeKeyToJWK: incorrect x size for " + cozeKey.alg + ".", ErrCodes.KeyInvalid, {
+          field: "x"
+        });
+      }
+      jwk.x = ArrayBufferTo64ut(xy.slice(0, half));
+      jwk.y = ArrayBufferTo64ut(xy.slice(half));
+      break;
+    }
+    default:
+      throw new CozeAlgError("CozeKeyToJWK: unsupported alg: " + cozeKey.alg, ErrCodes.AlgUnsupported, {
+        alg: cozeKey.alg
+      });
+  }
+  if (!isEmpty(cozeKey.d)) {
+    jwk.d = cozeKey.d;
+  }
+  return jwk;
+}
+async function JWKToCozeKey(jwk) {
+  let alg;
+  switch (jwk.crv) {
+    case Algs.Ed25519:
+      if (jwk.kty !== "OKP") {
+        throw new CozeKeyError("JWKToCozeKey: kty must be OKP for Ed25519.", ErrCodes.KeyInvalid, {
+          field: "kty"
+        });
+      }
+      alg = Algs.Ed25519;
+      break;
+    case Curves.P256:
+    case Curves.P384:
+    case Curves.P521:
+      if (jwk.kty !== FamAlgs.EC) {
+        throw new CozeKeyError("JWKToCozeKey: kty must be EC for curve " + jwk.crv + ".", ErrCodes.KeyInvalid, {
+          field: "kty"
+        });
+      }
+      alg = await CryptoKey.algFromCrv(jwk.crv);
+      break;
+    default:
+      throw new CozeAlgError("JWKToCozeKey: unsupported crv: " + jwk.crv, ErrCodes.AlgUnsupported, {
+        alg: jwk.crv
+      });
+  }
+  if (!isEmpty(jwk.alg) && jwk.alg !== alg && !(alg === Algs.Ed25519 && jwk.alg === "EdDSA")) {
+    throw new CozeAlgError("JWKToCozeKey: JWK alg " + jwk.alg + " mismatch with crv " + jwk.crv + ".", ErrCodes.AlgMismatch, {
+      alg: jwk.alg
+    });
+  }
+  var czk = {
+    alg
+  };
+  if (isEmpty(jwk.x)) {
+    throw new CozeKeyError("JWKToCozeKey: JWK x must be set.", ErrCodes.KeyInvalid, {
+      field: "x"
+    });
+  }
+  if (alg === Algs.Ed25519) {
+    if (B64ToUint8Array(jwk.x).length !== XSize(alg)) {
+      throw new CozeKeyError("JWKToCozeKey: incorrect x size for Ed25519.", ErrCodes.KeyInvalid, {
+        field: "x"
+      });
+    }
+    czk.x = jwk.x;
+    if (!isEmpty(jwk.d)) {
+      czk.d = jwk.d;
+    }
+  } else {
+    if (isEmpty(jwk.y)) {
+      throw new CozeKeyError("JWKToCozeKey: JWK y must be set.", ErrCodes.KeyInvalid, {
+        field: "y"
+      });
+    }
+    let half = XSize(alg) / 2;
+    czk.x = ArrayBufferTo64ut(concatBytes(
+      padBytes("x", half, B64ToUint8Array(jwk.x)),
+      padBytes("y", half, B64ToUint8Array(jwk.y))
+    ).buffer);
+    if (!isEmpty(jwk.d)) {
+      czk.d = ArrayBufferTo64ut(padBytes("d", DSize(alg), B64ToUint8Array(jwk.d)).buffer);
+    }
+  }
+  czk.tmb = await Thumbprint(czk);
+  return czk;
+}
+async function fromCozeKeyEd25519(cozeKey, onlyPublic) {
+  let params = {
+    name: Algs.Ed25519
+  };
+  try {
+    if (isEmpty(cozeKey.d) || onlyPublic) {
+      return await crypto.subtle.importKey(
+        "raw",
+        B64ToUint8Array(cozeKey.x),
+        params,
+        true,
+        ["verify"]
+      );
+    }
+    return await crypto.subtle.importKey(
+      "jwk",
+      CozeKeyToJWK(cozeKey),
+      params,
+      true,
+      ["sign"]
+    );
+  } catch (e) {
+    throw unsupportedErr("CryptoKey.FromCozeKey", cozeKey.alg, e);
+  }
+}
+async function importCozeKey(cozeKey, onlyPublic) {
+  if (cozeKey.alg === Algs.Ed25519) {
+    return fromCozeKeyEd25519(cozeKey, onlyPublic);
+  }
+  if (cozeKey.alg === Algs.ES224) {
+    return ECDSA.FromCozeKey(cozeKey, onlyPublic);
+  }
+  if (Genus(cozeKey.alg) != GenAlgs.ECDSA) {
+    throw new CozeAlgError("CryptoKey.FromCozeKey: unsupported CryptoKey algorithm: " + cozeKey.alg, ErrCodes.AlgUnsupported, {
+      alg: cozeKey.alg
+    });
+  }
+  let jwk = CozeKeyToJWK(cozeKey);
+  if (isEmpty(cozeKey.d) || onlyPublic) {
+    var signOrVerify = "verify";
+    delete jwk.d;
+  } else {
+    signOrVerify = "sign";
+  }
+  return await crypto.subtle.importKey(
+    "jwk",
+    jwk,
+    {
+      name: GenAlgs.ECDSA,
+      namedCurve: jwk.crv
+    },
+    true,
+    [signOrVerify]
+  );
+}
+function padBytes(name, size, bytes) {
+  if (bytes.length > size) {
+    throw new CozeKeyError("JWKToCozeKey: incorrect " + name + " size.", ErrCodes.KeyInvalid, {
+      field: name
+    });
+  }
+  let out = new Uint8Array(size);
+  out.set(bytes, size - bytes.length);
+  return out;
+}
+function concatBytes(a, b) {
+  let out = new Uint8Array(a.length + b.length);
+  out.set(a, 0);
+  out.set(b, a.length);
+  return out;
+}
+function subtleParams(alg) {
+  if (alg === Algs.Ed25519) {
+    return {
+      name: Algs.Ed25519
+    };
+  }
+  return {
+    name: GenAlgs.ECDSA,
+    hash: {
+      name: HashAlg(alg)
+    }
+  };
+}
+function unsupportedErr(fn, alg, e) {
+  if (e instanceof DOMException && e.name === "NotSupportedError") {
+    return new CozeAlgError(fn + ": alg " + alg + " unsupported in this browser.", ErrCodes.AlgUnsupported, {
+      alg
+    });
+  }
+  return e;
+}
+function IsLowS(alg, s) {
+  if (typeof s !== "bigint") {
+    throw new TypeError("IsLowS: s is not of type bigint");
+  }
+  return CurveHalfOrder(alg) > s;
+}
+function toLowS(alg, s) {
+  if (typeof s !== "bigint") {
+    throw new TypeError("toLowS: s is not of type bigint");
+  }
+  if (!IsLowS(alg, s)) {
+    return CurveOrder(alg) - s;
+  }
+  return s;
+}
+async function SigToLowS(alg, sig) {
+  let ab = await B64uToArrayBuffer(sig);
+  let lowSSigAB = await sigToLowSArrayBuffer(alg, ab);
+  return ArrayBufferTo64ut(lowSSigAB);
+}
+async function IsSigLowS(alg, sig) {
+  let bigIntS = await sigToS(alg, sig);
+  return IsLowS(alg, bigIntS);
+}
+function sigToS(alg, sig) {
+  let half = SigSize(alg) / 2;
+  let s = sig.slice(half);
+  return arrayBufferToBigInt(s);
+}
+async function sigToLowSArrayBuffer(alg, sig) {
+  let half = SigSize(alg) / 2;
+  let r = sig.slice(0, half);
+  let s = sig.slice(half);
+  let bigIntS = arrayBufferToBigInt(s);
+  let bigIntNormS = toLowS(alg, bigIntS);
+  let normS = bigIntToArrayBuffer(SigSize(alg) / 2, bigIntNormS);
+  var tmp = new Uint8Array(r.byteLength + normS.byteLength);
+  tmp.set(new Uint8Array(r), 0);
+  tmp.set(new Uint8Array(normS), r.byteLength);
+  sig = tmp.buffer;
+  return sig;
+}
+function arrayBufferToBigInt(buffer) {
+  let result = 0n;
+  let a = new Uint8Array(buffer);
+  for (let i = 0; i < a.length; i++) {
+    result = (result << 8n) + BigInt(a[i]);
+  }
+  return result;
+}
+function bigIntToArrayBuffer(size, bigInt) {
+  const buffer = new ArrayBuffer(size);
+  const view = new DataView(buffer);
+  do {
+    size--;
+    view.setUint8(size, Number(bigInt & BigInt(255)));
+    bigInt >>= 8n;
+  } while (size > 0);
+  return buffer;
+}
+
+// key.js
+var TmbCanon = ["alg", "x"];
+var PublicFields = ["alg", "iat", "kid", "tmb", "typ", "rvk", "x"];
+async function NewKey(alg) {
+  if (isEmpty(alg)) {
+    alg = Algs.ES256;
+  }
+  if (Genus(alg) == GenAlgs.ECDSA || alg == Algs.Ed25519) {
+    var keyPair = await CryptoKey.New(alg);
+  } else {
+    throw new CozeAlgError("Coze.NewKey: only ECDSA algs and Ed25519 are currently supported.", ErrCodes.AlgUnsupported, {
+      alg
+    });
+  }
+  let k = await CryptoKey.ToCozeKey(keyPair.privateKey);
+  k.iat = Math.floor(Date.now() / 1e3);
+  k.tmb = await Thumbprint(k);
+  k.kid = "My Cyphr.me Key.";
+  return k;
+}
+var tmbCache = /* @__PURE__ */ new WeakMap();
+var ed25519PKCS8Prefix = [48, 46, 2, 1, 0, 48, 5, 6, 3, 43, 101, 112, 4, 34, 4, 32];
+async function ed25519X(d) {
+  let pkcs8 = new Uint8Array(ed25519PKCS8Prefix.length + d.length);
+  pkcs8.set(ed25519PKCS8Prefix);
+  pkcs8.set(d, ed25519PKCS8Prefix.length);
+  let ck = await crypto.subtle.importKey("pkcs8", pkcs8, {
+    name: Algs.Ed25519
+  }, true, ["sign"]);
+  return (await crypto.subtle.exportKey("jwk", ck)).x;
+}
+async function NewKeyFromSeed(alg, seed) {
+  if (!(seed instanceof Uint8Array)) {
+    throw new TypeError("Coze.NewKeyFromSeed: seed must be a Uint8Array.");
+  }
+  let k;
+  if (Genus(alg) == GenAlgs.ECDSA) {
+    let min = HashSize(alg) / 2;
+    if (seed.length < min) {
+      throw new CozeKeyError(`Coze.NewKeyFromSeed: seed must be at least ${min} bytes for ${alg}.`, ErrCodes.KeyInvalid, {
+        field: "seed"
+      });
+    }
+    k = await ECDSA.KeyFromSeed(alg, seed);
+  } else if (alg == Algs.Ed25519) {
+    if (seed.length !== DSize(alg)) {
+      throw new CozeKeyError("Coze.NewKeyFromSeed: Ed25519 seed must be 32 bytes.", ErrCodes.KeyInvalid, {
+        field: "seed"
+      });
+    }
+    k = {
+      alg,
+      d: ArrayBufferTo64ut(seed),
+      x: await ed25519X(seed)
+    };
+  } else {
+    throw new CozeAlgError("Coze.NewKeyFromSeed: only ECDSA algs and Ed25519 are currently supported.", ErrCodes.AlgUnsupported, {
+      alg
+    });
+  }
+  k.tmb = await Thumbprint(k);
+  return k;
+}
+var PasswordIterations = 21e4;
+async function NewKeyFromPassword(alg, password, salt, opts) {
+  if (typeof salt === "string") {
+    salt = new TextEncoder().encode(salt);
+  }
+  if (!(salt instanceof Uint8Array) || salt.length < 16) {
+    throw new CozeKeyError("Coze.NewKeyFromPassword: salt must be at least 16 bytes.", ErrCodes.KeyInvalid, {
+      field: "salt"
+    });
+  }
+  let iterations = PasswordIterations;
+  if (!isEmpty(opts) && opts.iterations !== void 0) {
+    iterations = opts.iterations;
+  }
+  if (!Number.isSafeInteger(iterations) || iterations < 1) {
+    throw new CozeKeyError("Coze.NewKeyFromPassword: iterations must be a positive integer.", ErrCodes.KeyInvalid, {
+      field: "iterations"
+    });
+  }
+  let size = DSize(Algs.Ed25519);
+  if (Genus(alg) == GenAlgs.ECDSA) {
+    size = HashSize(alg);
+  }
+  let pw = await crypto.subtle.importKey("raw", new TextEncoder().encode(password.normalize("NFC")), "PBKDF2", false, ["deriveBits"]);
+  let seed = await crypto.subtle.deriveBits({
+    name: "PBKDF2",
+    hash: Algs.SHA512,
+    salt,
+    iterations
+  }, pw, size * 8);
+  return {
+    key: await NewKeyFromSeed(alg, new Uint8Array(seed)),
+    params: {
+      alg,
+      kdf: "PBKDF2",
+      hash: Algs.SHA512,
+      iterations,
+      salt: ArrayBufferTo64ut(salt)
+    }
+  };
+}
+async function Thumbprint(cozeKey) {
+  if (isEmpty(cozeKey.alg) || isEmpty(cozeKey.x)) {
+    throw new CozeKeyError("Coze.Thumbprint: alg or x is empty.", ErrCodes.KeyInvalid, {
+      field: isEmpty(cozeKey.alg) ? "alg" : "x"
+    });
+  }
+  let cached = tmbCache.get(cozeKey);
+  if (cached !== void 0 && cached.alg === cozeKey.alg && cached.x === cozeKey.x) {
+    return cached.tmb;
+  }
+  let tmb = await CanonicalHash64(cozeKey, await HashAlg(cozeKey.alg), TmbCanon);
+  tmbCache.set(cozeKey, {
+    alg: cozeKey.alg,
+    x: cozeKey.x,
+    tmb
+  });
+  return tmb;
+}
+async function ThumbprintMatch(cozeKey) {
+  let tmb = await Thumbprint(cozeKey);
+  if (tmb !== cozeKey.tmb) {
+    throw new CozeKeyError("Coze.ThumbprintMatch: key.tmb does not match the calculated thumbprint.", ErrCodes.TmbMismatch, {
+      field: "tmb"
+    });
+  }
+  return tmb;
+}
+function PublicKey(cozeKey) {
+  let pub = {};
+  for (const [k, v] of Object.entries(cozeKey)) {
+    if (PublicFields.includes(k)) {
+      pub[k] = v;
+    }
+  }
+  return pub;
+}
+function IsPrivate(cozeKey) {
+  return privateField(cozeKey) !== void 0;
+}
+function AssertPublic(cozeKey) {
+  let f = privateField(cozeKey);
+  if (f !== void 0) {
+    throw new CozeKeyError(`AssertPublic: key has private component "${f}".`, ErrCodes.KeyInvalid, {
+      field: f
+    });
+  }
+}
+function privateField(cozeKey) {
+  if (cozeKey === null || typeof cozeKey !== "object") {
+    return void 0;
+  }
+  return Object.keys(cozeKey).find((f) => f.toLowerCase() === "d");
+}
+async function Diagnose(ck) {
+  let checks = [];
+  let status = {};
+  let add = function(name, s, message) {
+    let c = {
+      name,
+      status: s
+    };
+    if (!isEmpty(message)) {
+      c.message = message;
+    }
+    checks.push(c);
+    status[name] = s;
+  };
+  let passed = (...names) => names.every((n) => status[n] === "pass");
+  let skipAfter = function(name, ...deps) {
+    add(name, "skip", "Requires passing " + deps.filter((n) => status[n] !== "pass").join(", ") + ".");
+  };
+  let bytes = {};
+  if (typeof ck !== "object" || ck === null) {
+    ck = {};
+  }
+  let p;
+  try {
+    p = Params(ck.alg);
+    if (p.Use !== Uses.Sig) {
+      add("alg_known", "fail", `alg "${ck.alg}" is not a signing alg.`);
+    } else {
+      add("alg_known", "pass");
+    }
+  } catch (e) {
+    add("alg_known", "fail", `alg "${ck.alg}" is not supported.`);
+  }
+  let bad = [];
+  for (const f of ["x", "d", "tmb"]) {
+    if (!isEmpty(ck[f])) {
+      try {
+        bytes[f] = B64ToUint8Array(ck[f], f);
+      } catch (e) {
+        bad.push(f);
+      }
+    }
+  }
+  if (isEmpty(ck.x) && isEmpty(ck.d) && isEmpty(ck.tmb)) {
+    add("b64ut", "fail", "At least one of x, d, and tmb must be set.");
+  } else if (bad.length > 0) {
+    add("b64ut", "fail", "Not strict b64ut: " + bad.join(", ") + ".");
+  } else {
+    add("b64ut", "pass");
+  }
+  for (const [name, f, size] of [["x_length", "x", "XSize"], ["d_length", "d", "DSize"]]) {
+    if (isEmpty(ck[f])) {
+      add(name, "skip", `No ${f}.`);
+    } else if (!passed("alg_known", "b64ut")) {
+      skipAfter(name, "alg_known", "b64ut");
+    } else if (bytes[f].length !== p[size]) {
+      add(name, "fail", `${f} is ${bytes[f].length} bytes, ${ck.alg} requires ${p[size]}.`);
+    } else {
+      add(name, "pass");
+    }
+    if (name === "x_length") {
+      if (isEmpty(ck.x)) {
+        add("y_length", "skip", "No x.");
+      } else if (!passed("alg_known", "b64ut")) {
+        skipAfter("y_length", "alg_known", "b64ut");
+      } else if (p.Genus !== GenAlgs.ECDSA) {
+        add("y_length", "skip", `${ck.alg} has no Y coordinate.`);
+      } else if (bytes.x.length === p.XSize / 2) {
+        add("y_length", "fail", "x is only the X coordinate.  Coze x is X || Y.");
+      } else if (bytes.x.length !== p.XSize) {
+        skipAfter("y_length", "x_length");
+      } else {
+        add("y_length", "pass");
+      }
+    }
+  }
+  if (isEmpty(ck.tmb)) {
+    add("tmb_matches", "skip", "No tmb.");
+  } else if (!passed("alg_known", "b64ut")) {
+    skipAfter("tmb_matches", "alg_known", "b64ut");
+  } else if (isEmpty(ck.x)) {
+    if (bytes.tmb.length !== p.HashSize) {
+      add("tmb_matches", "fail", `tmb is ${bytes.tmb.length} bytes, ${ck.alg} requires ${p.HashSize}.`);
+    } else {
+      add("tmb_matches", "pass");
+    }
+  } else if (!passed("x_length")) {
+    skipAfter("tmb_matches", "x_length");
+  } else if (await Thumbprint(ck) !== ck.tmb) {
+    add("tmb_matches", "fail", "tmb does not match the thumbprint of alg and x.");
+  } else {
+    add("tmb_matches", "pass");
+  }
+  if (isEmpty(ck.d) || isEmpty(ck.x)) {
+    add("d_derives_x", "skip", "Requires d and x.");
+  } else if (!passed("x_length", "d_length")) {
+    skipAfter("d_derives_x", "x_length", "d_length");
+  } else {
+    try {
+      let x;
+      if (p.Genus === GenAlgs.ECDSA) {
+        x = await ECDSA.PublicFromD(ck.alg, BigInt("0x" + Uint8ArrayToHex(bytes.d)));
+      } else if (ck.alg === Algs.Ed25519) {
+        x = await ed25519X(bytes.d);
+      }
+      if (x === void 0) {
+        add("d_derives_x", "skip", `Deriving x is not supported for ${ck.alg}.`);
+      } else if (x !== ck.x) {
+        add("d_derives_x", "fail", "x is not the public key of d.");
+      } else {
+        add("d_derives_x", "pass");
+      }
+    } catch (e) {
+      add("d_derives_x", "fail", "d is invalid: " + e.message);
+    }
+  }
+  if (isEmpty(ck.d) || isEmpty(ck.x)) {
+    add("sign_verify_roundtrip", "skip", "Requires d and x.");
+  } else if (status.d_derives_x === "fail" || !passed("x_length", "d_length")) {
+    skipAfter("sign_verify_roundtrip", "x_length", "d_length", "d_derives_x");
+  } else {
+    try {
+      let msg = "Coze Diagnose";
+      let sig = await SignPay(msg, ck);
+      if (await VerifyPay(msg, ck, sig)) {
+        add("sign_verify_roundtrip", "pass");
+      } else {
+        add("sign_verify_roundtrip", "fail", "Signature by d did not verify with x.");
+      }
+    } catch (e) {
+      add("sign_verify_roundtrip", "fail", e.message);
+    }
+  }
+  return {
+    ok: checks.every((c) => c.status !== "fail"),
+    checks
+  };
+}
+async function Valid(privateCozeKey) {
+  if (isEmpty(privateCozeKey.d)) {
+    console.error("Coze key missing `d`");
+    return false;
+  }
+  try {
+    let msg = `7AtyaCHO2BAG06z0W1tOQlZFWbhxGgqej4k9-HWP3DE-zshRbrE-69DIfgY704_FDYez7h_rEI1WQVKhv5Hd5Q`;
+    let sig = await SignPay(msg, privateCozeKey);
+    return VerifyPay(msg, privateCozeKey, sig);
+  } catch (e) {
+    return false;
+  }
+}
+async function Correct(ck) {
+  if (typeof ck !== "object") {
+    console.error("Correct: CozeKey must be passed in as an object.");
+    return false;
+  }
+  if (isEmpty(ck.alg)) {
+    console.error("Correct: Alg must be set");
+    return false;
+  }
+  let p = Params(ck.alg);
+  let isTmbEmpty = isEmpty(ck.tmb);
+  let isXEmpty = isEmpty(ck.x);
+  let isDEmpty = isEmpty(ck.d);
+  if (isTmbEmpty && isXEmpty && isDEmpty) {
+    console.error("Correct: At least one of [x, tmb, d] must be set");
+    return false;
+  }
+  for (const f of ["x", "d", "tmb"]) {
+    if (!isEmpty(ck[f])) {
+      try {
+        B64ToUint8Array(ck[f], f);
+      } catch (e) {
+        console.error("Correct: " + e.message);
+        return false;
+      }
+    }
+  }
+  if (isXEmpty && isDEmpty) {
+    if (isTmbEmpty || ck.tmb.length !== p.HashSizeB64) {
+      console.error("Correct: Incorrect `tmb` size: ", ck.tmb.length);
+      return false;
+    }
+    return true;
+  }
+  if (!isXEmpty && ck.x.length !== p.XSizeB64) {
+    console.error("Correct: Incorrect x size: ", ck.x.length);
+    return false;
+  }
+  if (!isTmbEmpty && !isXEmpty) {
+    let t = await Thumbprint(ck);
+    if (ck.tmb !== t) {
+      console.error("Correct: Incorrect given `tmb`: ", ck.tmb);
+      return false;
+    }
+  }
+  if (!isDEmpty && !isXEmpty) {
+    let cryptoKey = await CryptoKey.FromCozeKey(ck);
+    let mldBuffer = await SToArrayBuffer("Test Signing");
+    let sig = await CryptoKey.SignBuffer(cryptoKey, mldBuffer);
+    let pubKey = await CryptoKey.FromCozeKey(ck, true);
+    let result = await CryptoKey.VerifyArrayBuffer(ck.alg, pubKey, mldBuffer, sig);
+    if (!result) {
+      console.error("Correct: private key invalid.");
+      return false;
+    }
+  }
+  return true;
+}
+async function Revoke(cozeKey, opts) {
+  if (isEmpty(cozeKey)) {
+    throw new CozeKeyError("CozeKey.Revoke: Private key not set.  Cannot sign message", ErrCodes.KeyInvalid, {
+      field: "d"
+    });
+  }
+  if (typeof opts === "string") {
+    opts = {
+      msg: opts
+    };
+  }
+  if (isEmpty(opts)) {
+    opts = {};
+  }
+  for (const f of ["alg", "iat", "tmb", "rvk"]) {
+    if (f in opts) {
+      throw new CozeError(`CozeKey.Revoke: "${f}" is set by Revoke and may not be given.`, ErrCodes.FieldReserved, {
+        field: f
+      });
+    }
+  }
+  var coze = {};
+  coze.pay = {};
+  if (!isEmpty(opts.typ)) {
+    coze.pay.typ = opts.typ;
+  }
+  coze.pay.rvk = Math.round(Date.now() / 1e3);
+  if (!isEmpty(opts.msg)) {
+    coze.pay.msg = opts.msg;
+  }
+  for (const [k, v] of Object.entries(opts)) {
+    if (k !== "typ" && k !== "msg") {
+      coze.pay[k] = v;
+    }
+  }
+  let prevRvk = cozeKey.rvk;
+  delete cozeKey.rvk;
+  try {
+    coze = await SignCozeRaw(coze, cozeKey, null, {
+      setStandard: true
+    });
+  } catch (e) {
+    if (prevRvk !== void 0) {
+      cozeKey.rvk = prevRvk;
+    }
+    throw e;
+  }
+  if (prevRvk !== void 0) {
+    cozeKey.rvk = prevRvk;
+  } else {
+    cozeKey.rvk = coze.pay.rvk;
+  }
+  return coze;
+}
+function IsRevoked(cozeKey) {
+  let rvk = cozeKey.rvk;
+  if (rvk === void 0 || rvk === null) {
+    return false;
+  }
+  if (typeof rvk === "number" && Number.isInteger(rvk)) {
+    return rvk > 0;
+  }
+  return true;
+}
+async function VerifyRevoke(coze, cozeKey) {
+  let rvk = coze.pay.rvk;
+  if (!Number.isSafeInteger(rvk) || rvk <= 0) {
+    return false;
+  }
+  if (coze.pay.tmb !== await Thumbprint(cozeKey)) {
+    return false;
+  }
+  if (Number.isInteger(cozeKey.rvk) && cozeKey.rvk > 0 && rvk < cozeKey.rvk) {
+    return false;
+  }
+  return Verify(coze, cozeKey, {
+    allowRevoked: true
+  });
+}
+async function LookupKey(keyring, tmb) {
+  let isMap = keyring instanceof Map;
+  if (!isMap && !Array.isArray(keyring)) {
+    return keyring;
+  }
+  if (isEmpty(tmb)) {
+    throw new CozeKeyError("LookupKey: no key for tmb: tmb is empty.", ErrCodes.KeyNotFound, {
+      field: "tmb"
+    });
+  }
+  let candidates = keyring;
+  if (isMap) {
+    candidates = keyring.has(tmb) ? [keyring.get(tmb)] : [];
+  }
+  for (const k of candidates) {
+    if (isEmpty(k)) {
+      continue;
+    }
+    let t;
+    try {
+      t = await Thumbprint(k);
+    } catch (e) {
+      continue;
+    }
+    if (t === tmb) {
+      return k;
+    }
+  }
+  throw new CozeKeyError(`LookupKey: no key for tmb ${tmb}.`, ErrCodes.KeyNotFound, {
+    field: "tmb",
+    tmb
+  });
+}
+
+// der.js
+var tagInteger = 2;
+var tagBitString = 3;
+var tagOctetString = 4;
+var tagOID = 6;
+var tagSequence = 48;
+var tagContext0 = 160;
+var tagContext1 = 161;
+var oidECPublicKey = [42, 134, 72, 206, 61, 2, 1];
+var oidEd25519 = [43, 101, 112];
+var curveOIDs = {
+  "ES224": [43, 129, 4, 0, 33],
+  // 1.3.132.0.33
+  "ES256": [42, 134, 72, 206, 61, 3, 1, 7],
+  // 1.2.840.10045.3.1.7
+  "ES384": [43, 129, 4, 0, 34],
+  // 1.3.132.0.34
+  "ES512": [43, 129, 4, 0, 35]
+  // 1.3.132.0.35
+};
+async function PEMToCozeKey(pem) {
+  let block = pemDecode(pem);
+  let czk;
+  switch (block.label) {
+    case "PUBLIC KEY":
+      czk = parseSPKI(block.der);
+      break;
+    case "PRIVATE KEY":
+      czk = await parsePKCS8(block.der);
+      break;
+    case "EC PRIVATE KEY":
+      czk = await parseSEC1(block.der, null);
+      break;
+    default:
+      throw new CozeKeyError("PEMToCozeKey: unsupported PEM type: " + block.label, ErrCodes.KeyInvalid, {
+        field: "pem"
+      });
+  }
+  czk.tmb = await Thumbprint(czk);
+  return czk;
+}
+function CozeKeyToPEM(cozeKey, opts) {
+  let priv = !isEmpty(opts) && opts.private === true;
+  let sec1 = !isEmpty(opts) && opts.sec1 === true;
+  if (isEmpty(cozeKey.x)) {
+    throw new CozeKeyError("CozeKeyToPEM: key x must be set.", ErrCodes.KeyInvalid, {
+      field: "x"
+    });
+  }
+  if (priv && isEmpty(cozeKey.d)) {
+    throw new CozeKeyError("CozeKeyToPEM: private key d must be set.", ErrCodes.KeyInvalid, {
+      field: "d"
+    });
+  }
+  let x = B64ToUint8Array(cozeKey.x);
+  if (x.length !== XSize(cozeKey.alg)) {
+    throw new CozeKeyError("CozeKeyToPEM: incorrect x size for " + cozeKey.alg + ".", ErrCodes.KeyInvalid, {
+      field: "x"
+    });
+  }
+  if (cozeKey.alg === Algs.Ed25519) {
+    if (sec1) {
+      throw new CozeAlgError("CozeKeyToPEM: SEC1 is only for EC keys.", ErrCodes.AlgUnsupported, {
+        alg: cozeKey.alg
+      });
+    }
+    let algID2 = tlv(tagSequence, tlv(tagOID, oidEd25519));
+    if (!priv) {
+      return pemEncode("PUBLIC KEY", tlv(tagSequence, algID2, tlv(tagBitString, [0], x)));
+    }
+    let d2 = tlv(tagOctetString, B64ToUint8Array(cozeKey.d));
+    return pemEncode("PRIVATE KEY", tlv(tagSequence, tlv(tagInteger, [0]), algID2, tlv(tagOctetString, d2)));
+  }
+  let curveOID = curveOIDs[cozeKey.alg];
+  if (curveOID === void 0) {
+    throw new CozeAlgError("CozeKeyToPEM: unsupported alg: " + cozeKey.alg, ErrCodes.AlgUnsupported, {
+      alg: cozeKey.alg
+    });
+  }
+  let pub = tlv(tagBitString, [0, 4], x);
+  let algID = tlv(tagSequence, tlv(tagOID, oidECPublicKey), tlv(tagOID, curveOID));
+  if (!priv) {
+    return pemEncode("PUBLIC KEY", tlv(tagSequence, algID, pub));
+  }
+  let d = tlv(tagOctetString, B64ToUint8Array(cozeKey.d));
+  if (sec1) {
+    return pemEncode("EC PRIVATE KEY", tlv(tagSequence, tlv(tagInteger, [1]), d, tlv(tagContext0, tlv(tagOID, curveOID)), tlv(tagContext1, pub)));
+  }
+  let ecPriv = tlv(tagSequence, tlv(tagInteger, [1]), d, tlv(tagContext1, pub));
+  return pemEncode("PRIVATE KEY", tlv(tagSequence, tlv(tagInteger, [0]), algID, tlv(tagOctetString, ecPriv)));
+}
+function SigToDER(sig, alg) {
+  let raw = B64ToUint8Array(sig);
+  if (Genus(alg) !== GenAlgs.ECDSA) {
+    throw new CozeAlgError("SigToDER: alg must be ECDSA: " + alg, ErrCodes.AlgUnsupported, {
+      alg
+    });
+  }
+  if (raw.length !== SigSize(alg)) {
+    throw new CozeVerifyError(`SigToDER: incorrect sig size for ${alg}: ${raw.length} bytes, expected ${SigSize(alg)}.`, ErrCodes.SigInvalid, {
+      field: "sig"
+    });
+  }
+  let half = raw.length / 2;
+  return tlv(tagSequence, derInteger(raw.slice(0, half)), derInteger(raw.slice(half)));
+}
+function DERToSig(der, alg) {
+  if (typeof der === "string") {
+    der = B64ToUint8Array(der);
+  }
+  der = new Uint8Array(der);
+  if (Genus(alg) !== GenAlgs.ECDSA) {
+    throw new CozeAlgError("DERToSig: alg must be ECDSA: " + alg, ErrCodes.AlgUnsupported, {
+      alg
+    });
+  }
+  let seq, ints;
+  try {
+    seq = readTLV(der, 0);
+    ints = children(seq);
+  } catch (e) {
+    throw new CozeVerifyError("DERToSig: invalid DER signature: " + e.message, ErrCodes.SigInvalid, {
+      field: "sig"
+    });
+  }
+  if (seq.tag !== tagSequence || seq.end !== der.length || ints.length !== 2) {
+    throw new CozeVerifyError("DERToSig: invalid DER signature.", ErrCodes.SigInvalid, {
+      field: "sig"
+    });
+  }
+  let half = SigSize(alg) / 2;
+  let out = new Uint8Array(half * 2);
+  for (let i = 0; i < 2; i++) {
+    if (ints[i].tag !== tagInteger) {
+      throw new CozeVerifyError("DERToSig: invalid signature integer.", ErrCodes.SigInvalid, {
+        field: "sig"
+      });
+    }
+    let n = ints[i].content;
+    if (n.length === 0 || n[0] & 128) {
+      throw new CozeVerifyError("DERToSig: signature integers must be positive.", ErrCodes.SigInvalid, {
+        field: "sig"
+      });
+    }
+    let j = 0;
+    while (j < n.length - 1 && n[j] === 0) {
+      j++;
+    }
+    n = n.slice(j);
+    if (n.length > half) {
+      throw new CozeVerifyError("DERToSig: signature integer too large for " + alg + ".", ErrCodes.SigInvalid, {
+        field: "sig"
+      });
+    }
+    out.set(n, half * (i + 1) - n.length);
+  }
+  return ArrayBufferTo64ut(out);
+}
+function IsDERSig(sig, alg) {
+  return sig.length > 0 && sig[0] === tagSequence && sig.length !== SigSize(alg);
+}
+function derInteger(n) {
+  let i = 0;
+  while (i < n.length - 1 && n[i] === 0) {
+    i++;
+  }
+  n = n.slice(i);
+  if (n[0] & 128) {
+    return tlv(tagInteger, [0], n);
+  }
+  return tlv(tagInteger, n);
+}
+function parseSPKI(der) {
+  let spki = children(expect(readTLV(der, 0), tagSequence, "SPKI"));
+  let alg = algFromAlgID(spki[0]);
+  let pub = expect(spki[1], tagBitString, "SPKI public key");
+  if (pub.content[0] !== 0) {
+    throw new CozeKeyError("PEMToCozeKey: unsupported SPKI public key padding.", ErrCodes.KeyInvalid);
+  }
+  return {
+    alg,
+    x: pointToX(alg, pub.content.slice(1))
+  };
+}
+async function parsePKCS8(der) {
+  let pk = children(expect(readTLV(der, 0), tagSequence, "PKCS #8"));
+  if (pk.length < 3) {
+    throw new CozeKeyError("PEMToCozeKey: invalid PKCS #8.", ErrCodes.KeyInvalid);
+  }
+  let alg = algFromAlgID(pk[1]);
+  let priv = expect(pk[2], tagOctetString, "PKCS #8 private key").content;
+  if (alg !== Algs.Ed25519) {
+    return parseSEC1(priv, alg);
+  }
+  let d = expect(readTLV(priv, 0), tagOctetString, "Ed25519 private key").content;
+  if (d.length !== DSize(alg)) {
+    throw new CozeKeyError("PEMToCozeKey: incorrect Ed25519 private key size.", ErrCodes.KeyInvalid, {
+      field: "d"
+    });
+  }
+  let ck = await crypto.subtle.importKey("pkcs8", der, {
+    name: Algs.Ed25519
+  }, true, ["sign"]);
+  let jwk = await crypto.subtle.exportKey("jwk", ck);
+  return {
+    alg,
+    x: jwk.x,
+    d: ArrayBufferTo64ut(d)
+  };
+}
+async function parseSEC1(der, alg) {
+  let ec = children(expect(readTLV(der, 0), tagSequence, "EC private key"));
+  if (ec.length < 2 || ec[0].tag !== tagInteger || ec[0].content.length !== 1 || ec[0].content[0] !== 1) {
+    throw new CozeKeyError("PEMToCozeKey: unsupported EC private key version.", ErrCodes.KeyInvalid);
+  }
+  let pub = null;
+  for (let f of ec.slice(2)) {
+    if (f.tag === tagContext0) {
+      let curveAlg = algFromCurveOID(expect(readTLV(f.content, 0), tagOID, "EC private key curve").content);
+      if (alg !== null && alg !== curveAlg) {
+        throw new CozeAlgError("PEMToCozeKey: EC private key curve mismatch.", ErrCodes.AlgMismatch, {
+          alg: curveAlg
+        });
+      }
+      alg = curveAlg;
+    }
+    if (f.tag === tagContext1) {
+      pub = expect(readTLV(f.content, 0), tagBitString, "EC public key").content;
+    }
+  }
+  if (alg === null) {
+    throw new CozeKeyError("PEMToCozeKey: EC private key curve not given.", ErrCodes.KeyInvalid);
+  }
+  let dBytes = expect(ec[1], tagOctetString, "EC private key").content;
+  if (dBytes.length > DSize(alg)) {
+    throw new CozeKeyError("PEMToCozeKey: incorrect private key size.", ErrCodes.KeyInvalid, {
+      field: "d"
+    });
+  }
+  let d = new Uint8Array(DSize(alg));
+  d.set(dBytes, d.length - dBytes.length);
+  let czk = {
+    alg
+  };
+  if (pub !== null) {
+    if (pub[0] !== 0) {
+      throw new CozeKeyError("PEMToCozeKey: unsupported EC public key padding.", ErrCodes.KeyInvalid);
+    }
+    czk.x = pointToX(alg, pub.slice(1));
+  } else {
+    czk.x = await ECDSA.PublicFromD(alg, bytesToBigInt2(d));
+  }
+  czk.d = ArrayBufferTo64ut(d);
+  return czk;
+}
+function algFromAlgID(t) {
+  let id = children(expect(t, tagSequence, "algorithm identifier"));
+  let oid = expect(id[0], tagOID, "algorithm").content;
+  if (bytesEqual(oid, oidEd25519)) {
+    return Algs.Ed25519;
+  }
+  if (!bytesEqual(oid, oidECPublicKey)) {
+    throw new CozeAlgError("PEMToCozeKey: unsupported key algorithm.", ErrCodes.AlgUnsupported);
+  }
+  if (id.length < 2) {
+    throw new CozeKeyError("PEMToCozeKey: EC named curve not given.", ErrCodes.KeyInvalid);
+  }
+  return algFromCurveOID(expect(id[1], tagOID, "named curve").content);
+}
+function algFromCurveOID(oid) {
+  for (let alg in curveOIDs) {
+    if (bytesEqual(oid, curveOIDs[alg])) {
+      return alg;
+    }
+  }
+  throw new CozeAlgError("PEMToCozeKey: unsupported named curve.", ErrCodes.AlgUnsupported);
+}
+function pointToX(alg, point) {
+  if (alg !== Algs.Ed25519) {
+    if (point[0] !== 4) {
+      throw new CozeKeyError("PEMToCozeKey: only uncompressed EC points are supported.", ErrCodes.KeyInvalid, {
+        field: "x"
+      });
+    }
+    point = point.slice(1);
+  }
+  if (point.length !== XSize(alg)) {
+    throw new CozeKeyError("PEMToCozeKey: incorrect public key size for " + alg + ".", ErrCodes.KeyInvalid, {
+      field: "x"
+    });
+  }
+  return ArrayBufferTo64ut(point);
+}
+function pemDecode(pem) {
+  let re = /-----BEGIN ([A-Z0-9 ]+)-----([\s\S]*?)-----END \1-----/g;
+  let m;
+  while ((m = re.exec(pem)) !== null) {
+    if (m[1] === "EC PARAMETERS") {
+      continue;
+    }
+    if (m[1] === "ENCRYPTED PRIVATE KEY" || m[2].includes("ENCRYPTED")) {
+      throw new CozeKeyError("PEMToCozeKey: encrypted PEM is not supported.", ErrCodes.KeyInvalid, {
+        field: "pem"
+      });
+    }
+    let b64 = m[2].replace(/\s+/g, "");
+    try {
+      var der = Uint8Array.from(atob(b64), (c) => c.charCodeAt(0));
+    } catch (e) {
+      throw new CozeKeyError("PEMToCozeKey: invalid PEM base64.", ErrCodes.KeyInvalid, {
+        field: "pem"
+      });
+    }
+    return {
+      label: m[1],
+      der
+    };
+  }
+  throw new CozeKeyError("PEMToCozeKey: no PEM key found.", ErrCodes.KeyInvalid, {
+    field: "pem"
+  });
+}
+function pemEncode(label, der) {
+  let b64 = btoa(String.fromCharCode(...der));
+  let lines = b64.match(/.{1,64}/g);
+  return `-----BEGIN ${label}-----
+${lines.join("\n")}
+-----END ${label}-----
+`;
+}
+function readTLV(der, off) {
+  if (off + 2 > der.length) {
+    throw new CozeKeyError("DER: unexpected end of input.", ErrCodes.KeyInvalid);
+  }
+  let tag = der[off];
+  let len = der[off + 1];
+  let start = off + 2;
+  if (len & 128) {
+    let n = len & 127;
+    if (n === 0 || n > 4) {
+      throw new CozeKeyError("DER: unsupported length.", ErrCodes.KeyInvalid);
+    }
+    len = 0;
+    for (let i = 0; i < n; i++) {
+      len = len * 256 + der[start + i];
+    }
+    start += n;
+  }
+  let end = start + len;
+  if (end > der.length) {
+    throw new CozeKeyError("DER: length exceeds input.", ErrCodes.KeyInvalid);
+  }
+  return {
+    tag,
+    content: der.slice(start, end),
+    end
+  };
+}
+function children(t) {
+  let out = [];
+  for (let off = 0; off < t.content.length; ) {
+    let c = readTLV(t.content, off);
+    out.push(c);
+    off = c.end;
+  }
+  return out;
+}
+function expect(t, tag, name) {
+  if (t === void 0 || t.tag !== tag) {
+    throw new CozeKeyError("DER: invalid " + name + ".", ErrCodes.KeyInvalid);
+  }
+  return t;
+}
+function tlv(tag, ...contents) {
+  let content = [];
+  for (let c of contents) {
+    content.push(...c);
+  }
+  let len = content.length;
+  let lenBytes = [];
+  if (len < 128) {
+    lenBytes.push(len);
+  } else {
+    let b = [];
+    for (; len > 0; len >>= 8) {
+      b.unshift(len & 255);
+    }
+    lenBytes.push(128 | b.length, ...b);
+  }
+  return new Uint8Array([tag, ...lenBytes, ...content]);
+}
+function bytesEqual(a, b) {
+  if (a.length !== b.length) {
+    return false;
+  }
+  for (let i = 0; i < a.length; i++) {
+    if (a[i] !== b[i]) {
+      return false;
+    }
+  }
+  return true;
+}
+function bytesToBigInt2(bytes) {
+  let result = 0n;
+  for (let b of bytes) {
+    result = (result << 8n) + BigInt(b);
+  }
+  return result;
+}
+
+// coze.js
+var PayCanon = ["alg", "iat", "tmb", "typ"];
+async function Sign(coze, cozeKey, canon, opts) {
+  console.log();
+  coze = fromJSON(coze);
+  cozeKey = fromJSON(cozeKey);
+  if (IsRevoked(cozeKey)) {
+    throw new CozeKeyError("SignCoze: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
+  }
+  if (!isEmpty(opts) && opts.setStandard === true) {
+    coze.pay = await setStandard(coze.pay, cozeKey, opts);
+  } else {
+    coze.pay.alg = cozeKey.alg;
+    coze.pay.tmb = await Thumbprint(cozeKey);
+    coze.pay.iat = iatFromOpts(opts);
+  }
+  if (!isEmpty(opts) && opts.normalizeUnicode === true) {
+    coze.pay = NormalizeUnicode(coze.pay);
+  }
+  if (!isEmpty(canon)) {
+    coze.pay = await Canonical(coze.pay, canon);
+  }
+  coze.sig = await SignPay(JSON.stringify(coze.pay), cozeKey, opts);
+  return coze;
+}
+async function SignPay(pay, cozeKey, opts) {
+  let isJSON = true;
+  try {
+    JSON.parse(pay);
+  } catch (e) {
+    isJSON = false;
+  }
+  if (isJSON) {
+    CheckDuplicates(pay);
+    if (!isEmpty(opts) && opts.normalizeUnicode === true) {
+      pay = JSON.stringify(NormalizeUnicode(JSON.parse(pay)));
+    }
+  }
+  checkHash(cozeKey.alg, opts);
+  if (!isEmpty(opts) && opts.deterministic === true && Genus(cozeKey.alg) == GenAlgs.ECDSA) {
+    if (isEmpty(cozeKey.d)) {
+      throw new CozeKeyError("SignPay: deterministic signing requires private component d.", ErrCodes.KeyInvalid, {
+        field: "d"
+      });
+    }
+    let sig = await ECDSA.SignBuffer(await ECDSA.FromCozeKey(cozeKey), await SToArrayBuffer(pay), true);
+    return SigToLowS(cozeKey.alg, ArrayBufferTo64ut(sig));
+  }
+  return CryptoKey.SignBufferB64(
+    await CryptoKey.FromCozeKey(cozeKey),
+    await SToArrayBuffer(pay)
+  );
+}
+async function SignCozeRaw(coze, cozeKey, canon, opts) {
+  coze = fromJSON(coze);
+  cozeKey = fromJSON(cozeKey);
+  if (IsRevoked(cozeKey)) {
+    throw new CozeKeyError("SignCozeRaw: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
+  }
+  if (!isEmpty(opts) && opts.setStandard === true) {
+    coze.pay = await setStandard(coze.pay, cozeKey, opts);
+  } else {
+    if (!isEmpty(coze.pay.alg) && coze.pay.alg !== cozeKey.alg) {
+      throw new CozeAlgError("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.", ErrCodes.AlgMismatch, {
+        alg: coze.pay.alg
+      });
+    }
+    if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== cozeKey.tmb) {
+      throw new CozeKeyError("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.", ErrCodes.TmbMismatch, {
+        field: "tmb"
+      });
+    }
+  }
+  if (!isEmpty(opts) && opts.normalizeUnicode === true) {
+    coze.pay = NormalizeUnicode(coze.pay);
+  }
+  if (!isEmpty(canon)) {
+    coze.pay = await Canonical(coze.pay, canon);
+  }
+  coze.sig = await SignPay(JSON.stringify(coze.pay), cozeKey, opts);
+  return coze;
+}
+async function setStandard(pay, cozeKey, opts) {
+  let tmb = await Thumbprint(cozeKey);
+  if (!isEmpty(pay.alg) && pay.alg !== cozeKey.alg) {
+    throw new CozeAlgError("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.", ErrCodes.AlgMismatch, {
+      alg: pay.alg
+    });
+  }
+  if (!isEmpty(pay.tmb) && pay.tmb !== tmb) {
+    throw new CozeKeyError("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.", ErrCodes.TmbMismatch, {
+      field: "tmb"
+    });
+  }
+  let iat = pay.iat;
+  if (iat === void 0) {
+    iat = iatFromOpts(opts);
+  } else if (opts.iat !== void 0 && opts.iat !== iat) {
+    throw new CozeError(`SignCozeRaw: coze.pay.iat (${iat}) mismatch with opts.iat (${opts.iat}).`, ErrCodes.IatInvalid, {
+      field: "iat"
+    });
+  }
+  let std = {
+    alg: cozeKey.alg,
+    iat,
+    tmb
+  };
+  for (const [k, v] of Object.entries(pay)) {
+    if (!(k in std)) {
+      std[k] = v;
+    }
+  }
+  return std;
+}
+function iatFromOpts(opts) {
+  if (isEmpty(opts) || opts.iat === void 0) {
+    return Math.round(Date.now() / 1e3);
+  }
+  if (!Number.isSafeInteger(opts.iat) || opts.iat < 0) {
+    throw new CozeError("Sign: opts.iat must be a non-negative integer.", ErrCodes.IatInvalid, {
+      field: "iat"
+    });
+  }
+  return opts.iat;
+}
+async function SignCryptoKey(pay, cryptoKey, cozeKey, canon) {
+  pay = fromJSON(pay);
+  cozeKey = fromJSON(cozeKey);
+  if (IsRevoked(cozeKey)) {
+    throw new CozeKeyError("SignCryptoKey: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
+  }
+  if (cryptoKey.type !== "private") {
+    throw new CozeKeyError("SignCryptoKey: CryptoKey must be private.", ErrCodes.KeyInvalid);
+  }
+  if (await CryptoKey.algFromCryptoKey(cryptoKey) !== cozeKey.alg) {
+    throw new CozeAlgError("SignCryptoKey: CryptoKey alg mismatch with cozeKey.alg.", ErrCodes.AlgMismatch, {
+      alg: cozeKey.alg
+    });
+  }
+  pay.alg = cozeKey.alg;
+  pay.tmb = await Thumbprint(cozeKey);
+  pay.iat = Math.round(Date.now() / 1e3);
+  if (!isEmpty(canon)) {
+    pay = await Canonical(pay, canon);
+  }
+  let coze = {
+    pay,
+    sig: await CryptoKey.SignString(cryptoKey, JSON.stringify(pay))
+  };
+  if (!await VerifyPay(JSON.stringify(pay), cozeKey, coze.sig)) {
+    throw new CozeKeyError("SignCryptoKey: CryptoKey is not the private key of cozeKey.", ErrCodes.KeyMismatch);
+  }
+  return coze;
+}
+async function Verify(coze, cozeKey, opts, detachedOpts) {
+  if (typeof opts === "string") {
+    let payBytes;
+    if (typeof coze === "string") {
+      payBytes = coze;
+      coze = ParseStrict(coze);
+    }
+    return verify({
+      pay: coze,
+      sig: opts
+    }, cozeKey, detachedOpts, payBytes);
+  }
+  return verify(coze, cozeKey, opts);
+}
+async function verify(coze, cozeKey, opts, payBytes) {
+  coze = fromJSON(coze);
+  cozeKey = fromJSON(cozeKey);
+  let given = cozeKey !== void 0 && cozeKey !== null;
+  if (given) {
+    cozeKey = await LookupKey(cozeKey, coze.pay.tmb);
+  }
+  if (!isEmpty(coze.key)) {
+    cozeKey = await embeddedKey(coze, given ? cozeKey : void 0);
+  } else if (!given) {
+    throw new CozeKeyError("VerifyCoze: no key given and coze has no embedded key.", ErrCodes.KeyInvalid, {
+      field: "key"
+    });
+  }
+  if (IsRevoked(cozeKey) && (isEmpty(opts) || opts.allowRevoked !== true)) {
+    throw new CozeKeyError("VerifyCoze: Coze key is revoked.", ErrCodes.KeyRevoked);
+  }
+  if (!isEmpty(coze.pay.alg) && coze.pay.alg !== cozeKey.alg) {
+    throw new CozeAlgError("VerifyCoze: Coze key alg mismatch with coze.pay.alg.", ErrCodes.AlgMismatch, {
+      alg: coze.pay.alg
+    });
+  }
+  if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== await Thumbprint(cozeKey)) {
+    throw new CozeKeyError("VerifyCoze: Coze key tmb mismatch with coze.pay.tmb.", ErrCodes.TmbMismatch, {
+      field: "tmb"
+    });
+  }
+  checkHash(cozeKey.alg, opts);
+  B64ToUint8Array(coze.sig, "sig");
+  B64ToUint8Array(cozeKey.x, "x");
+  let sig = coze.sig;
+  let pay = coze.pay;
+  if (!isEmpty(opts)) {
+    if (opts.normalizeUnicode === true) {
+      pay = NormalizeUnicode(pay);
+    }
+    checkCanon(pay, opts);
+    if (opts.acceptDER === true && Genus(cozeKey.alg) == GenAlgs.ECDSA && IsDERSig(B64ToUint8Array(sig), cozeKey.alg)) {
+      sig = DERToSig(sig, cozeKey.alg);
+    }
+    if (await isHighS(cozeKey.alg, sig, opts)) {
+      return false;
+    }
+  }
+  if (payBytes === void 0) {
+    payBytes = JSON.stringify(pay);
+  }
+  let verified = await VerifyPay(payBytes, cozeKey, sig);
+  if (verified && !isEmpty(opts)) {
+    checkTime(pay, opts);
+  }
+  return verified;
+}
+async function isHighS(alg, sig, opts) {
+  if (isEmpty(opts) || opts.requireLowS !== true || Genus(alg) !== GenAlgs.ECDSA) {
+    return false;
+  }
+  let ab = B64uToArrayBuffer(sig);
+  return ab.byteLength === SigSize(alg) && !await IsSigLowS(alg, ab);
+}
+function checkHash(alg, opts) {
+  if (isEmpty(opts) || isEmpty(opts.hash)) {
+    return;
+  }
+  let hsh = HashAlg(alg);
+  if (opts.hash !== hsh) {
+    throw new CozeAlgError(`Coze: hash not valid for alg: ${opts.hash} is not ${alg}'s hash ${hsh}.`, ErrCodes.HashInvalid, {
+      alg
+    });
+  }
+}
+async function embeddedKey(coze, cozeKey) {
+  let key = coze.key;
+  let tmb = await Thumbprint(key);
+  if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== tmb) {
+    throw new CozeKeyError("VerifyCoze: coze.key tmb mismatch with coze.pay.tmb.", ErrCodes.TmbMismatch, {
+      field: "key"
+    });
+  }
+  if (!isEmpty(coze.pay.alg) && coze.pay.alg !== key.alg) {
+    throw new CozeAlgError("VerifyCoze: coze.key alg mismatch with coze.pay.alg.", ErrCodes.AlgMismatch, {
+      alg: key.alg
+    });
+  }
+  if (cozeKey !== void 0) {
+    if (await Thumbprint(cozeKey) !== tmb) {
+      throw new CozeKeyError("VerifyCoze: Coze key tmb mismatch with coze.key.", ErrCodes.TmbMismatch, {
+        field: "key"
+      });
+    }
+    return cozeKey;
+  }
+  return {
+    ...key,
+    tmb
+  };
+}
+function checkTime(pay, opts) {
+  if (opts.maxAge === void 0 && opts.notBefore === void 0 && opts.notAfter === void 0) {
+    return;
+  }
+  let iat = pay.iat;
+  let ctx = {
+    field: "iat",
+    verified: true
+  };
+  if (!Number.isSafeInteger(iat) || iat < 0) {
+    throw new CozeVerifyError("VerifyCoze: pay.iat must be a non-negative integer when time options are set.", ErrCodes.IatInvalid, ctx);
+  }
+  let skew = opts.clockSkew === void 0 ? 60 : opts.clockSkew;
+  let now = Date.now() / 1e3;
+  if (opts.maxAge !== void 0) {
+    if (iat + opts.maxAge + skew < now) {
+      throw new CozeVerifyError(`VerifyCoze: coze expired: iat ${iat} is older than maxAge ${opts.maxAge}.`, ErrCodes.Expired, ctx);
+    }
+    if (iat - skew > now) {
+      throw new CozeVerifyError(`VerifyCoze: coze not yet valid: iat ${iat} is in the future.`, ErrCodes.NotYetValid, ctx);
+    }
+  }
+  if (opts.notBefore !== void 0 && iat + skew < opts.notBefore) {
+    throw new CozeVerifyError(`VerifyCoze: coze not yet valid: iat ${iat} is before notBefore ${opts.notBefore}.`, ErrCodes.NotYetValid, ctx);
+  }
+  if (opts.notAfter !== void 0 && iat - skew > opts.notAfter) {
+    throw new CozeVerifyError(`VerifyCoze: coze expired: iat ${iat} is after notAfter ${opts.notAfter}.`, ErrCodes.Expired, ctx);
+  }
+}
+function checkCanon(pay, opts) {
+  let fields = Object.keys(pay);
+  let required = [];
+  if (Array.isArray(opts.canon)) {
+    required = opts.canon.flatMap((e) => typeof e === "string" ? [e] : Object.keys(e));
+    let extra = fields.filter((f) => !required.includes(f));
+    if (extra.length > 0) {
+      throw new CozeCanonError("VerifyCoze: pay has extra field(s) not in canon: " + extra.join(", "), ErrCodes.CanonExtra, {
+        fields: extra
+      });
+    }
+  }
+  if (!isEmpty(opts.canonContains)) {
+    required = required.concat(opts.canonContains);
+  }
+  let missing = required.filter((f) => !fields.includes(f));
+  if (missing.length > 0) {
+    missing = [...new Set(missing)];
+    throw new CozeCanonError("VerifyCoze: pay missing field(s) required by canon: " + missing.join(", "), ErrCodes.CanonMissing, {
+      fields: missing
+    });
+  }
+}
+async function VerifyMeta(coze, cozeKey, opts) {
+  let report = {
+    verified: false,
+    meta: null,
+    checks: []
+  };
+  let status = {};
+  let add = function(name, s, message) {
+    let c = {
+      name,
+      status: s
+    };
+    if (!isEmpty(message)) {
+      c.message = message;
+    }
+    report.checks.push(c);
+    status[name] = s;
+  };
+  let passed = (...names) => names.every((n) => status[n] === "pass");
+  let skipAfter = function(name, ...deps) {
+    add(name, "skip", "Requires passing " + deps.filter((n) => status[n] !== "pass").join(", ") + ".");
+  };
+  if (isEmpty(opts)) {
+    opts = {};
+  }
+  try {
+    coze = fromJSON(coze);
+    if (coze === null || typeof coze !== "object" || coze.pay === null || typeof coze.pay !== "object" || Array.isArray(coze.pay)) {
+      add("pay_parsed", "fail", "coze.pay must be an object.");
+    } else {
+      add("pay_parsed", "pass");
+    }
+  } catch (e) {
+    add("pay_parsed", "fail", e.message);
+  }
+  let key;
+  let embedded = false;
+  if (!passed("pay_parsed")) {
+    skipAfter("key_found", "pay_parsed");
+  } else {
+    try {
+      cozeKey = fromJSON(cozeKey);
+      if (cozeKey !== void 0 && cozeKey !== null && cozeKey !== "") {
+        key = await LookupKey(cozeKey, coze.pay.tmb);
+      } else if (!isEmpty(coze.key)) {
+        key = coze.key;
+        embedded = true;
+      }
+      if (isEmpty(key)) {
+        add("key_found", "fail", "No key given and coze has no embedded key.");
+      } else {
+        add("key_found", "pass", embedded ? "Embedded key." : "");
+      }
+    } catch (e) {
+      add("key_found", "fail", e.message);
+    }
+  }
+  if (!passed("pay_parsed")) {
+    skipAfter("meta", "pay_parsed");
+  } else {
+    try {
+      report.meta = await Meta(coze, isEmpty(coze.pay.alg) && !isEmpty(key) ? key.alg : void 0);
+      add("meta", "pass");
+    } catch (e) {
+      add("meta", "fail", e.message);
+    }
+  }
+  if (!passed("key_found")) {
+    skipAfter("alg_matches", "pay_parsed", "key_found");
+  } else if (isEmpty(coze.pay.alg)) {
+    add("alg_matches", "skip", "pay has no alg.");
+  } else if (coze.pay.alg !== key.alg) {
+    add("alg_matches", "fail", `pay.alg "${coze.pay.alg}" is not the key's alg "${key.alg}".`);
+  } else {
+    add("alg_matches", "pass");
+  }
+  if (!passed("key_found")) {
+    skipAfter("tmb_matches", "pay_parsed", "key_found");
+  } else {
+    try {
+      let tmb = await Thumbprint(key);
+      let fail = [];
+      if (!isEmpty(coze.pay.tmb) && coze.pay.tmb !== tmb) {
+        fail.push(`pay.tmb "${coze.pay.tmb}" is not the key's thumbprint "${tmb}".`);
+      }
+      if (!embedded && !isEmpty(coze.key) && await Thumbprint(coze.key) !== tmb) {
+        fail.push("Given key is not the embedded key.");
+      }
+      if (fail.length > 0) {
+        add("tmb_matches", "fail", fail.join("  "));
+      } else {
+        add("tmb_matches", "pass", isEmpty(coze.pay.tmb) ? "pay has no tmb." : "");
+      }
+    } catch (e) {
+      add("tmb_matches", "fail", e.message);
+    }
+  }
+  if (!passed("key_found")) {
+    skipAfter("not_revoked", "pay_parsed", "key_found");
+  } else if (!IsRevoked(key)) {
+    add("not_revoked", "pass");
+  } else if (opts.allowRevoked === true) {
+    add("not_revoked", "pass", "Key is revoked, allowed by opts.allowRevoked.");
+  } else {
+    add("not_revoked", "fail", "Key is revoked.");
+  }
+  if (isEmpty(opts.hash)) {
+    add("hash", "skip", "opts.hash not given.");
+  } else if (!passed("key_found")) {
+    skipAfter("hash", "pay_parsed", "key_found");
+  } else {
+    try {
+      checkHash(key.alg, opts);
+      add("hash", "pass");
+    } catch (e) {
+      add("hash", "fail", e.message);
+    }
+  }
+  let sig;
+  if (!passed("pay_parsed")) {
+    skipAfter("sig_b64ut", "pay_parsed");
+  } else if (isEmpty(coze.sig)) {
+    add("sig_b64ut", "fail", "coze has no sig.");
+  } else {
+    try {
+      B64ToUint8Array(coze.sig, "sig");
+      sig = coze.sig;
+      add("sig_b64ut", "pass");
+    } catch (e) {
+      add("sig_b64ut", "fail", e.message);
+    }
+  }
+  let alg = !isEmpty(key) && !isEmpty(key.alg) ? key.alg : passed("pay_parsed") ? coze.pay.alg : void 0;
+  if (!passed("sig_b64ut")) {
+    skipAfter("sig_size", "sig_b64ut");
+  } else {
+    try {
+      if (opts.acceptDER === true && Genus(alg) == GenAlgs.ECDSA && IsDERSig(B64ToUint8Array(sig), alg)) {
+        sig = DERToSig(sig, alg);
+      }
+      let size = B64ToUint8Array(sig).length;
+      if (size !== SigSize(alg)) {
+        add("sig_size", "fail", `sig is ${size} bytes, ${alg} requires ${SigSize(alg)}.`);
+      } else {
+        add("sig_size", "pass");
+      }
+    } catch (e) {
+      add("sig_size", "fail", e.message);
+    }
+  }
+  let pay = passed("pay_parsed") ? coze.pay : void 0;
+  if (!passed("key_found", "sig_size") || status.alg_matches === "fail") {
+    skipAfter("signature", ...status.alg_matches === "fail" ? ["key_found", "alg_matches", "sig_size"] : ["key_found", "sig_size"]);
+  } else {
+    try {
+      if (opts.normalizeUnicode === true) {
+        pay = NormalizeUnicode(pay);
+      }
+      if (await isHighS(key.alg, sig, opts)) {
+        add("signature", "fail", "High-S signature refused by opts.requireLowS.");
+      } else if (await VerifyPay(JSON.stringify(pay), key, sig)) {
+        add("signature", "pass");
+      } else {
+        add("signature", "fail", "Signature did not verify.");
+      }
+    } catch (e) {
+      add("signature", "fail", e.message);
+    }
+  }
+  if (isEmpty(opts.canon) && isEmpty(opts.canonContains)) {
+    add("canon", "skip", "opts.canon and opts.canonContains not given.");
+  } else if (!passed("pay_parsed")) {
+    skipAfter("canon", "pay_parsed");
+  } else {
+    try {
+      checkCanon(pay, opts);
+      add("canon", "pass");
+    } catch (e) {
+      add("canon", "fail", e.message);
+    }
+  }
+  if (opts.maxAge === void 0 && opts.notBefore === void 0 && opts.notAfter === void 0) {
+    add("iat_window", "skip", "Time options not given.");
+  } else if (!passed("pay_parsed")) {
+    skipAfter("iat_window", "pay_parsed");
+  } else {
+    try {
+      checkTime(pay, opts);
+      add("iat_window", "pass");
+    } catch (e) {
+      add("iat_window", "fail", e.message);
+    }
+  }
+  report.verified = passed("signature") && report.checks.every((c) => c.status !== "fail");
+  return report;
+}
+async function VerifyPay(pay, cozekey, sig) {
+  return CryptoKey.VerifyMsg(
+    cozekey.alg,
+    await CryptoKey.FromCozeKey(cozekey, true),
+    pay,
+    sig
+  );
+}
+async function SignDig(alg, cozeKey, dig) {
+  if (IsRevoked(cozeKey)) {
+    throw new CozeKeyError("SignDig: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
+  }
+  let digest = digToUint8Array("SignDig", alg, cozeKey, dig);
+  let sig = await ECDSA.SignDigest(await ECDSA.FromCozeKey(cozeKey), digest);
+  return SigToLowS(alg, ArrayBufferTo64ut(sig));
+}
+async function VerifyDig(alg, cozeKey, dig, sig) {
+  let digest = digToUint8Array("VerifyDig", alg, cozeKey, dig);
+  let sigAB = B64uToArrayBuffer(sig);
+  if (sigAB.byteLength !== SigSize(alg)) {
+    return false;
+  }
+  return ECDSA.VerifyDigest(await ECDSA.FromCozeKey(cozeKey, true), digest, sigAB);
+}
+function digToUint8Array(fn, alg, cozeKey, dig) {
+  if (alg !== cozeKey.alg) {
+    throw new CozeAlgError(`${fn}: alg (${alg}) mismatch with cozeKey.alg (${cozeKey.alg}).`, ErrCodes.AlgMismatch, {
+      alg
+    });
+  }
+  if (Genus(alg) !== GenAlgs.ECDSA) {
+    throw new CozeAlgError(`${fn}: only ECDSA algs are supported.`, ErrCodes.AlgUnsupported, {
+      alg
+    });
+  }
+  if (!(dig instanceof Uint8Array)) {
+    if (dig.replace(/^0x/i, "").length === HashSize(alg) * 2) {
+      dig = HexToUint8Array(dig);
+    } else {
+      dig = B64ToUint8Array(dig);
+    }
+  }
+  if (dig.length !== HashSize(alg)) {
+    throw new CozeError(`${fn}: incorrect digest size for ${alg}: ${dig.length} bytes, expected ${HashSize(alg)}.`, ErrCodes.DigSize, {
+      alg
+    });
+  }
+  return dig;
+}
+async function Meta(coze, key) {
+  coze = fromJSON(coze);
+  if (isEmpty(coze.pay)) {
+    throw new CozeVerifyError("Meta: coze.pay must exist.", ErrCodes.PayMissing, {
+      field: "pay"
+    });
+  }
+  let meta = {};
+  let alg = key;
+  let tmb = "";
+  if (!isEmpty(key) && typeof key === "object") {
+    alg = key.alg;
+    tmb = key.tmb;
+  }
+  if (!isEmpty(coze.pay.alg)) {
+    if (!isEmpty(alg) && alg !== coze.pay.alg) {
+      throw new CozeAlgError(`Meta: alg mismatch: coze.pay.alg (${coze.pay.alg}) and parameter alg (${alg}) do not match.`, ErrCodes.AlgMismatch, {
+        alg
+      });
+    }
+    meta.alg = coze.pay.alg;
+  } else if (!isEmpty(alg)) {
+    meta.alg = alg;
+  }
+  if (!isEmpty(coze.pay.iat)) {
+    meta.iat = coze.pay.iat;
+  }
+  if (!isEmpty(coze.pay.tmb)) {
+    meta.tmb = coze.pay.tmb;
+  } else if (!isEmpty(tmb)) {
+    meta.tmb = tmb;
+  }
+  if (!isEmpty(coze.pay.typ)) {
+    meta.typ = coze.pay.typ;
+  }
+  meta.can = await Canon(coze.pay);
+  if (!isEmpty(meta.alg)) {
+    meta.cad = await CanonicalHash64(coze.pay, HashAlg(meta.alg));
+  }
+  if (!isEmpty(coze.sig)) {
+    meta.sig = coze.sig;
+  }
+  if (!isEmpty(meta.alg) && !isEmpty(coze.sig)) {
+    meta.czd = await CanonicalHash64({
+      cad: meta.cad,
+      sig: meta.sig
+    }, HashAlg(meta.alg));
+  }
+  if (!isEmpty(meta.alg) && Array.isArray(coze.sigs)) {
+    meta.sigs = [];
+    for (const s of coze.sigs) {
+      meta.sigs.push({
+        tmb: s.tmb,
+        sig: s.sig,
+        czd: await CanonicalHash64({
+          cad: meta.cad,
+          sig: s.sig
+        }, HashAlg(meta.alg))
+      });
+    }
+  }
+  return meta;
+}
+function ScrubCoze(coze) {
+  coze = fromJSON(coze);
+  let scrubbed = {
+    ...coze
+  };
+  if (!isEmpty(coze.key)) {
+    scrubbed.key = PublicKey(coze.key);
+  }
+  if (!isEmpty(coze.coze) && typeof coze.coze === "object") {
+    scrubbed.coze = ScrubCoze(coze.coze);
+  }
+  return scrubbed;
+}
+function Attach(pay, sig) {
+  if (typeof pay === "string") {
+    let s = pay;
+    pay = ParseStrict(s);
+    if (JSON.stringify(pay) !== s) {
+      throw new CozeError("Attach: pay is not the compact serialization of pay, so sig is not of the coze's pay.  Verify with Verify(pay, cozeKey, sig) instead.", ErrCodes.JSONInvalid, {
+        field: "pay"
+      });
+    }
+  }
+  return {
+    pay,
+    sig
+  };
+}
+function Detach(coze) {
+  coze = fromJSON(coze);
+  if (isEmpty(coze.pay)) {
+    throw new CozeVerifyError("Detach: coze.pay must exist.", ErrCodes.PayMissing, {
+      field: "pay"
+    });
+  }
+  return {
+    payCompact: JSON.stringify(coze.pay),
+    sig: coze.sig
+  };
+}
+async function Equal(cozeA, cozeB) {
+  cozeA = fromJSON(cozeA);
+  cozeB = fromJSON(cozeB);
+  if (isEmpty(cozeA) || isEmpty(cozeA.pay) || isEmpty(cozeB) || isEmpty(cozeB.pay)) {
+    throw new CozeVerifyError("Equal: coze.pay must exist.", ErrCodes.PayMissing, {
+      field: "pay"
+    });
+  }
+  if (isEmpty(cozeA.sig) !== isEmpty(cozeB.sig)) {
+    return false;
+  }
+  if (isEmpty(cozeA.pay.alg) || cozeA.pay.alg !== cozeB.pay.alg) {
+    return cozeA.sig === cozeB.sig && await CanonicalS(cozeA.pay) === await CanonicalS(cozeB.pay);
+  }
+  let a = await Meta(cozeA);
+  let b = await Meta(cozeB);
+  if (!isEmpty(cozeA.sig)) {
+    return a.czd === b.czd;
+  }
+  return a.cad === b.cad;
+}
+async function EqualStrict(cozeA, cozeB) {
+  cozeA = fromJSON(cozeA);
+  cozeB = fromJSON(cozeB);
+  if (Object.keys(cozeA).sort().join() !== Object.keys(cozeB).sort().join()) {
+    return false;
+  }
+  if (!isEmpty(cozeA.key) && await Thumbprint(cozeA.key) !== await Thumbprint(cozeB.key)) {
+    return false;
+  }
+  return Equal(cozeA, cozeB);
+}
+function ParseStrict(json) {
+  let parsed = JSON.parse(json);
+  CheckDuplicates(json);
+  return parsed;
+}
+function CheckDuplicates(json) {
+  let stack = [];
+  for (let i = 0; i < json.length; i++) {
+    let top = stack[stack.length - 1];
+    switch (json[i]) {
+      case "{":
+        stack.push({
+          names: /* @__PURE__ */ new Set(),
+          expectName: true
+        });
+        break;
+      case "[":
+        stack.push(null);
+        break;
+      case "}":
+      case "]":
+        stack.pop();
+        break;
+      case ",":
+        if (top) {
+          top.expectName = true;
+        }
+        break;
+      case '"': {
+        let start = i;
+        for (i++; json[i] !== '"'; i++) {
+          if (json[i] === "\\") {
+            i++;
+          }
+        }
+        if (top && top.expectName) {
+          let name = JSON.parse(json.slice(start, i + 1));
+          if (top.names.has(name)) {
+            throw new CozeError(`Coze: duplicate JSON field "${name}"`, ErrCodes.DuplicateField, {
+              field: name
+            });
+          }
+          top.names.add(name);
+          top.expectName = false;
+        }
+        break;
+      }
+    }
+  }
+}
+var InputTypes = {
+  Coze: "coze",
+  Key: "key",
+  Pay: "pay",
+  Array: "array",
+  Unknown: "unknown"
+};
+function Detect(input) {
+  if (typeof input === "string") {
+    try {
+      input = ParseStrict(input);
+    } catch (e) {
+      if (e instanceof SyntaxError) {
+        throw jsonError(input, e);
+      }
+      throw e;
+    }
+  }
+  if (Array.isArray(input)) {
+    return InputTypes.Array;
+  }
+  if (typeof input !== "object" || input === null) {
+    return InputTypes.Unknown;
+  }
+  let isObject = (v) => typeof v === "object" && v !== null && !Array.isArray(v);
+  if (isObject(input.coze) && isObject(input.coze.pay) && typeof input.coze.sig === "string") {
+    return InputTypes.Coze;
+  }
+  if (isObject(input.pay)) {
+    return typeof input.sig === "string" ? InputTypes.Coze : InputTypes.Pay;
+  }
+  if (input.sig !== void 0) {
+    return InputTypes.Unknown;
+  }
+  if (typeof input.alg === "string" && (typeof input.x === "string" || typeof input.d === "string")) {
+    return InputTypes.Key;
+  }
+  return InputTypes.Pay;
+}
+function jsonError(json, e) {
+  let err = (msg, position) => new CozeError(`Detect: ${msg} at position ${position}.`, ErrCodes.JSONInvalid, {
+    position
+  });
+  if (json.charCodeAt(0) === 65279) {
+    return err("byte order mark (BOM)", 0);
+  }
+  let inString = false;
+  for (let i = 0; i < json.length; i++) {
+    let c = json[i];
+    if (inString) {
+      if (c === "\\") {
+        i++;
+      } else if (c === '"') {
+        inString = false;
+      }
+      continue;
+    }
+    if (c === '"') {
+      inString = true;
+    } else if (c === ",") {
+      let next = json.slice(i + 1).search(/[^ \t\n\r]/);
+      if (next !== -1 && (json[i + 1 + next] === "}" || json[i + 1 + next] === "]")) {
+        return err("trailing comma", i);
+      }
+    } else if (/[\s\u200B-\u200D\u2060]/.test(c) && !/[ \t\n\r]/.test(c)) {
+      let code = c.charCodeAt(0).toString(16).toUpperCase().padStart(4, "0");
+      return err(`non JSON whitespace (U+${code})`, i);
+    }
+  }
+  let m = /position (\d+)/.exec(e.message);
+  if (m !== null) {
+    return err("invalid JSON", Number(m[1]));
+  }
+  return new CozeError("Detect: invalid JSON: " + e.message, ErrCodes.JSONInvalid);
+}
+function fromJSON(thing) {
+  if (typeof thing === "string") {
+    return ParseStrict(thing);
+  }
+  return thing;
+}
+// Annotate the CommonJS export names for ESM import in node:
+0 && (module.exports = {
+  AlgFromCOSE,
+  AlgFromJOSE,
+  Algs,
+  ArrayBufferTo64ut,
+  AssertPublic,
+  Attach,
+  B64Error,
+  B64Lenient,
+  B64ToUint8Array,
+  B64uToArrayBuffer,
+  B64utToHex,
+  COSEAlg,
+  Canon,
+  Canonical,
+  CanonicalHash,
+  CanonicalHash64,
+  CanonicalS,
+  CheckDuplicates,
+  ClearKeyCache,
+  Correct,
+  CozeAlgError,
+  CozeCanonError,
+  CozeError,
+  CozeKeyError,
+  CozeKeyToJWK,
+  CozeKeyToPEM,
+  CozeVerifyError,
+  CryptoKey,
+  Curve,
+  CurveHalfOrder,
+  CurveOID,
+  CurveOrder,
+  Curves,
+  DERToSig,
+  DSize,
+  Detach,
+  Detect,
+  Diagnose,
+  Digest,
+  DigestFiles,
+  DigestPayField,
+  DigestPayFile,
+  ECDSA,
+  Equal,
+  EqualStrict,
+  ErrCodes,
+  FamAlgs,
+  Family,
+  GenAlgs,
+  Genus,
+  HMAC,
+  Hash,
+  HashAlg,
+  HashSize,
+  HashStream,
+  HexToB64ut,
+  HexToUint8Array,
+  InputTypes,
+  IsDERSig,
+  IsPrivate,
+  IsRevoked,
+  IsSigLowS,
+  JOSEAlg,
+  JOSECrv,
+  JWKToCozeKey,
+  LookupKey,
+  MatchPayFile,
+  Meta,
+  NewKey,
+  NewKeyFromPassword,
+  NewKeyFromSeed,
+  NormalizeUnicode,
+  PEMToCozeKey,
+  Params,
+  ParseStrict,
+  PayCanon,
+  PublicKey,
+  Revoke,
+  SToArrayBuffer,
+  ScrubCoze,
+  SigSize,
+  SigToDER,
+  SigToLowS,
+  Sign,
+  SignCozeRaw,
+  SignCryptoKey,
+  SignDig,
+  SignPay,
+  Thumbprint,
+  ThumbprintMatch,
+  TmbCanon,
+  Uint8ArrayToHex,
+  Use,
+  Uses,
+  Valid,
+  Verify,
+  VerifyDig,
+  VerifyMeta,
+  VerifyPay,
+  VerifyRevoke,
+  XSize,
+  isEmpty
+});
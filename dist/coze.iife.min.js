@@ -0,0 +1,6 @@
+var Coze=(()=>{var Ze=Object.defineProperty;var Sn=Object.getOwnPropertyDescriptor;var En=Object.getOwnPropertyNames;var Cn=Object.prototype.hasOwnProperty;var vn=(e,t)=>{for(var n in t)Ze(e,n,{get:t[n],enumerable:!0})},Fn=(e,t,n,r)=>{if(t&&typeof t=="object"||typeof t=="function")for(let i of En(t))!Cn.call(e,i)&&i!==n&&Ze(e,i,{get:()=>t[i],enumerable:!(r=Sn(t,i))||r.enumerable});return e};var In=e=>Fn(Ze({},"__esModule",{value:!0}),e);var Kr={};vn(Kr,{AlgFromCOSE:()=>Dn,AlgFromJOSE:()=>Hn,Algs:()=>l,ArrayBufferTo64ut:()=>w,AssertPublic:()=>fr,Attach:()=>kr,B64Error:()=>ie,B64Lenient:()=>xt,B64ToUint8Array:()=>C,B64uToArrayBuffer:()=>de,B64utToHex:()=>bt,COSEAlg:()=>vt,Canon:()=>et,Canonical:()=>Ee,CanonicalHash:()=>Kt,CanonicalHash64:()=>ce,CanonicalS:()=>He,CheckDuplicates:()=>ht,ClearKeyCache:()=>Zn,Correct:()=>ur,CozeAlgError:()=>x,CozeCanonError:()=>q,CozeError:()=>D,CozeKeyError:()=>y,CozeKeyToJWK:()=>at,CozeKeyToPEM:()=>Ar,CozeVerifyError:()=>P,CryptoKey:()=>k,Curve:()=>le,CurveHalfOrder:()=>We,CurveOID:()=>St,CurveOrder:()=>Te,Curves:()=>O,DERToSig:()=>Ve,DSize:()=>V,Detach:()=>Hr,Detect:()=>_r,Diagnose:()=>sr,Digest:()=>Y,DigestFiles:()=>qe,DigestPayField:()=>_n,DigestPayFile:()=>Bn,ECDSA:()=>v,Equal:()=>An,EqualStrict:()=>Dr,ErrCodes:()=>s,FamAlgs:()=>pe,Family:()=>mt,GenAlgs:()=>p,Genus:()=>_,HMAC:()=>ke,Hash:()=>kt,HashAlg:()=>B,HashSize:()=>X,HashStream:()=>Ht,HexToB64ut:()=>wt,HexToUint8Array:()=>Ue,InputTypes:()=>te,IsDERSig:()=>$e,IsPrivate:()=>lr,IsRevoked:()=>se,IsSigLowS:()=>lt,JOSEAlg:()=>Et,JOSECrv:()=>Ct,JWKToCozeKey:()=>Ot,LookupKey:()=>ze,MatchPayFile:()=>Kn,Meta:()=>Le,NewKey:()=>nr,NewKeyFromPassword:()=>ir,NewKeyFromSeed:()=>Yt,NormalizeUnicode:()=>Q,PEMToCozeKey:()=>yr,Params:()=>Re,ParseStrict:()=>Be,PayCanon:()=>Sr,PublicKey:()=>ot,Revoke:()=>dr,SToArrayBuffer:()=>L,ScrubCoze:()=>yn,SigSize:()=>K,SigToDER:()=>hr,SigToLowS:()=>Me,Sign:()=>Er,SignCozeRaw:()=>ut,SignCryptoKey:()=>Cr,SignDig:()=>Ir,SignPay:()=>Ce,Thumbprint:()=>H,ThumbprintMatch:()=>ar,TmbCanon:()=>Zt,Uint8ArrayToHex:()=>Fe,Use:()=>pt,Uses:()=>ae,Valid:()=>or,Verify:()=>dt,VerifyDig:()=>Tr,VerifyMeta:()=>Fr,VerifyPay:()=>Ae,VerifyRevoke:()=>cr,XSize:()=>J,isEmpty:()=>u});var s={AlgUnsupported:"ERR_ALG_UNSUPPORTED",AlgMismatch:"ERR_ALG_MISMATCH",TmbMismatch:"ERR_TMB_MISMATCH",KeyInvalid:"ERR_KEY_INVALID",KeyRevoked:"ERR_KEY_REVOKED",KeyMismatch:"ERR_KEY_MISMATCH",KeyNotFound:"ERR_KEY_NOT_FOUND",SigInvalid:"ERR_SIG_INVALID",CanonInvalid:"ERR_CANON_INVALID",CanonMissing:"ERR_CANON_MISSING",CanonExtra:"ERR_CANON_EXTRA",PayMissing:"ERR_PAY_MISSING",PrvMismatch:"ERR_PRV_MISMATCH",ThresholdInvalid:"ERR_THRESHOLD_INVALID",IatInvalid:"ERR_IAT_INVALID",Expired:"ERR_EXPIRED",NotYetValid:"ERR_NOT_YET_VALID",DigSize:"ERR_DIG_SIZE",HashInvalid:"ERR_HASH_INVALID",DuplicateField:"ERR_DUPLICATE_FIELD",JSONInvalid:"ERR_JSON_INVALID",FieldReserved:"ERR_FIELD_RESERVED",B64Invalid:"ERR_B64_INVALID",HexInvalid:"ERR_HEX_INVALID",QRCapacity:"ERR_QR_CAPACITY",QRInvalid:"ERR_QR_INVALID",KeystoreUnavailable:"ERR_KEYSTORE_UNAVAILABLE",BrowserRequired:"ERR_BROWSER_REQUIRED"},D=class extends Error{constructor(t,n,r){super(t),this.name="CozeError",this.code=n,r!==void 0&&Object.assign(this,r)}},y=class extends D{constructor(t,n,r){super(t,n,r),this.name="CozeKeyError"}},P=class extends D{constructor(t,n,r){super(t,n,r),this.name="CozeVerifyError"}},q=class extends D{constructor(t,n,r){super(t,n,r),this.name="CozeCanonError"}},x=class extends D{constructor(t,n,r){super(t,n,r),this.name="CozeAlgError"}};async function L(e){return new TextEncoder().encode(e).buffer}var ie=class extends D{constructor(t,n){super(t,s.B64Invalid,{field:n}),this.name="B64Error"}};function de(e,t){return C(e,t).buffer}function C(e,t){let n=u(t)?"":` for field "${t}"`;if(typeof e!="string")throw new ie(`B64ToUint8Array: b64ut must be a string${n}.`,t);if(!/^[A-Za-z0-9_-]*$/.test(e)||e.length%4===1)throw new ie(`B64ToUint8Array: invalid b64ut${n}.`,t);let r=atob(e.replace(/-/g,"+").replace(/_/g,"/")),i=Uint8Array.from(r,a=>a.charCodeAt(0));if(w(i)!==e)throw new ie(`B64ToUint8Array: non-canonical b64ut${n}.`,t);return i}function xt(e){let t=e.replace(/\s/g,"").replace(/=+$/,"").replace(/-/g,"+").replace(/_/g,"/");if(!/^[A-Za-z0-9+/]*$/.test(t)||t.length%4===1)throw new ie("B64Lenient: invalid base64.");return w(Uint8Array.from(atob(t),n=>n.charCodeAt(0)))}function w(e){return btoa(String.fromCharCode.apply(null,new Uint8Array(e))).replace(/\+/g,"-").replace(/\//g,"_").replace(/=/g,"")}function Ue(e){if(e=e.replace(/^0x/i,""),e.length%2!==0)throw new D("HexToUint8Array: hex must have an even number of characters, got "+e.length+".",s.HexInvalid);if(!/^[0-9a-fA-F]*$/.test(e))throw new D("HexToUint8Array: invalid hex character.",s.HexInvalid);let t=new Uint8Array(e.length/2);for(let n=0;n<t.length;n++)t[n]=parseInt(e.substring(n*2,n*2+2),16);return t}function Fe(e){return Array.from(new Uint8Array(e),t=>t.toString(16).padStart(2,"0")).join("")}function wt(e){return w(Ue(e))}function bt(e){return Fe(C(e))}function u(e){return typeof e=="function"?!1:Array.isArray(e)&&e.length==0?!0:e===Object(e)?Object.keys(e).length===0:!Tn(e)}function Tn(e){return!(e===!1||e==="false"||e===void 0||e==="undefined"||e===""||e===0||e==="0"||e===null||e==="null"||e==="NaN"||Number.isNaN(e)||e===Object(e))}var l={UnknownAlg:"UnknownAlg",ES224:"ES224",ES256:"ES256",ES384:"ES384",ES512:"ES512",Ed25519:"Ed25519",Ed25519ph:"Ed25519ph",Ed448:"Ed448",SHA224:"SHA-224",SHA256:"SHA-256",SHA384:"SHA-384",SHA512:"SHA-512",SHA3224:"SHA3-224",SHA3256:"SHA3-256",SHA3384:"SHA3-384",SHA3512:"SHA3-512",SHAKE128:"SHAKE128",SHAKE256:"SHAKE256"},pe={EC:"EC",SHA:"SHA",RSA:"RSA"},p={ECDSA:"ECDSA",EdDSA:"EdDSA",SHA2:"SHA2",SHA3:"SHA3"},O={P224:"P-224",P256:"P-256",P384:"P-384",P521:"P-521",Curve25519:"Curve25519",Curve448:"Curve448"},ae={Sig:"sig",Enc:"enc",Hsh:"hsh"};function Re(e){let t={};t.Name=e,t.Genus=_(e),t.Family=mt(e),t.Use=pt(e),t.Hash=B(e),t.HashSize=X(e),t.HashSizeB64=Math.ceil(4*t.HashSize/3);try{t.XSize=J(e),t.XSizeB64=Math.ceil(4*t.XSize/3),t.DSize=V(e),t.DSizeB64=Math.ceil(4*t.DSize/3),t.Curve=le(e),t.SigSize=K(e),t.SigSizeB64=Math.ceil(4*t.SigSize/3),t.CurveOID=St(e),t.JOSECrv=Ct(e)}catch{}return t.JOSEAlg=Et(e),t.COSEAlg=vt(e),t}function _(e){switch(e){case l.ES224:case l.ES256:case l.ES384:case l.ES512:return p.ECDSA;case l.Ed25519:case l.Ed25519ph:case l.Ed448:return p.EdDSA;case l.SHA224:case l.SHA256:case l.SHA384:case l.SHA512:return p.SHA2;case l.SHA3224:case l.SHA3256:case l.SHA3384:case l.SHA3512:case l.SHAKE128:case l.SHAKE256:return p.SHA3;default:throw new x("alg.Genus: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function mt(e){switch(e){case l.ES224:case l.ES256:case l.ES384:case l.ES512:case l.Ed25519:case l.Ed25519ph:case l.Ed448:return pe.EC;case l.SHA224:case l.SHA256:case l.SHA384:case l.SHA512:case l.SHA3224:case l.SHA3256:case l.SHA3384:case l.SHA3512:case l.SHAKE128:case l.SHAKE256:return pe.SHA;default:throw new x("alg.Family:  unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function B(e){switch(e){case l.ES224:case l.SHA224:return l.SHA224;case l.SHA256:case l.ES256:return l.SHA256;case l.SHA384:case l.ES384:return l.SHA384;case l.SHA512:case l.ES512:case l.Ed25519:case l.Ed25519ph:return l.SHA512;case l.SHAKE128:return l.SHAKE128;case l.SHAKE256:case l.Ed448:return l.SHAKE256;case l.SHA3224:return l.SHA3224;case l.SHA3256:return l.SHA3256;case l.SHA3384:return l.SHA3384;case l.SHA3512:return l.SHA3512;default:throw new x("alg.HashAlg:  unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function X(e){switch(B(e)){case l.SHA224:case l.SHA3224:return 28;case l.SHA256:case l.SHA3256:case l.SHAKE128:return 32;case l.SHA384:case l.SHA3384:return 48;case l.SHA512:case l.SHA3512:case l.SHAKE256:return 64;default:throw new x("alg.HashSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function K(e){switch(e){case l.ES224:return 56;case l.ES256:case l.Ed25519:case l.Ed25519ph:return 64;case l.ES384:return 96;case l.Ed448:return 114;case l.ES512:return 132;default:throw new x("alg.SigSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function J(e){switch(e){case l.Ed25519:case l.Ed25519ph:return 32;case l.ES224:return 56;case l.Ed448:return 57;case l.ES256:return 64;case l.ES384:return 96;case l.ES512:return 132;default:throw new x("alg.XSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function V(e){switch(e){case l.ES224:return 28;case l.ES256:case l.Ed25519:case l.Ed25519ph:return 32;case l.ES384:return 48;case l.Ed448:return 57;case l.ES512:return 66;default:throw new x("alg.DSize: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e})}}function le(e){switch(e){default:throw new x("alg.Curve: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case l.ES224:return O.P224;case l.ES256:return O.P256;case l.ES384:return O.P384;case l.ES512:return O.P521;case l.Ed25519:case l.Ed25519ph:return O.Curve25519;case l.Ed448:return O.Curve448}}function pt(e){switch(_(e)){default:throw new x("alg.Use: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case p.EdDSA:case p.ECDSA:return ae.Sig;case p.SHA2:case p.SHA3:return ae.Hsh}}var Ie={ES224:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFF16A2E0B8F03E13DD29455C5C2A3D"),ES256:BigInt("0xFFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551"),ES384:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFC7634D81F4372DDF581A0DB248B0A77AECEC196ACCC52973"),ES512:BigInt("0x1FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFA51868783BF2F966B7FCC0148F709A5D03BB5C9B8899C47AEBB6FB71E91386409")},kn={ES224:Ie.ES224>>BigInt(1),ES256:Ie.ES256>>BigInt(1),ES384:Ie.ES384>>BigInt(1),ES512:Ie.ES512>>BigInt(1)};function Te(e){switch(e){default:throw new x("CurveOrder: unsupported curve: "+e,s.AlgUnsupported,{alg:e});case"ES224":case"ES256":case"ES384":case"ES512":return Ie[e]}}function We(e){switch(e){default:throw new x("CurveHalfOrder: unsupported curve: "+e,s.AlgUnsupported,{alg:e});case"ES224":case"ES256":case"ES384":case"ES512":return kn[e]}}function St(e){switch(e){default:throw new x("alg.CurveOID: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case l.ES224:return"1.3.132.0.33";case l.ES256:return"1.2.840.10045.3.1.7";case l.ES384:return"1.3.132.0.34";case l.ES512:return"1.3.132.0.35";case l.Ed25519:case l.Ed25519ph:return"1.3.101.112";case l.Ed448:return"1.3.101.113"}}function Et(e){switch(e){case l.ES256:case l.ES384:case l.ES512:return e;case l.Ed25519:case l.Ed448:return"EdDSA"}return _(e),""}function Ct(e){switch(e){default:throw new x("alg.JOSECrv: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});case l.ES224:return"";case l.ES256:case l.ES384:case l.ES512:return le(e);case l.Ed25519:case l.Ed25519ph:return"Ed25519";case l.Ed448:return"Ed448"}}var Ye={ES256:-7,ES384:-35,ES512:-36,Ed25519:-19,Ed448:-53,"SHA-256":-16,"SHA-384":-43,"SHA-512":-44,SHAKE128:-18,SHAKE256:-45};function vt(e){_(e);let t=Ye[e];return t===void 0?0:t}function Hn(e,t){switch(e){case"ES256":case"ES384":case"ES512":case"Ed25519":case"Ed448":return e;case"EdDSA":if(t==="Ed25519"||t==="Ed448")return t;throw new x("alg.AlgFromJOSE: EdDSA requires crv Ed25519 or Ed448, got: "+t,s.AlgUnsupported,{alg:e});default:throw new x("alg.AlgFromJOSE: unsupported JOSE alg: "+e,s.AlgUnsupported,{alg:e})}}function Dn(e){for(let t in Ye)if(Ye[t]===e)return t;throw new x("alg.AlgFromCOSE: unsupported COSE alg: "+e,s.AlgUnsupported,{alg:e})}async function Y(e,t){if(u(e))throw new x("Hash is not given",s.AlgUnsupported);if(e===l.SHA224){let n=Dt(!0);return n.update(new Uint8Array(t)),n.digest().buffer}if(_t[e]!==void 0){let n=Bt(e);return n.update(new Uint8Array(t)),n.digest().buffer}return crypto.subtle.digest(e,t)}async function ke(e,t,n){let r=e===l.SHA384||e===l.SHA512?128:64;t.length>r&&(t=new Uint8Array(await Y(e,t)));let i=new Uint8Array(r+n.length),a=new Uint8Array(r);for(let c=0;c<r;c++){let d=c<t.length?t[c]:0;i[c]=d^54,a[c]=d^92}i.set(n,r);let f=new Uint8Array(await Y(e,i)),o=new Uint8Array(r+f.length);return o.set(a),o.set(f,r),new Uint8Array(await Y(e,o))}async function kt(e,t){let n=B(e),r;if(typeof t=="string")r=await L(t);else if(t instanceof Uint8Array||t instanceof ArrayBuffer)r=t;else if(typeof Blob<"u"&&t instanceof Blob)r=await t.arrayBuffer();else throw new TypeError("Hash: input must be a string, Uint8Array, ArrayBuffer, or Blob.");return w(await Y(n,r))}async function Ht(e,t,n){let r=B(e),i;switch(r){case l.SHA224:case l.SHA256:i=Dt(r===l.SHA224);break;case l.SHA384:case l.SHA512:i=zn(r===l.SHA384);break;case l.SHA3224:case l.SHA3256:case l.SHA3384:case l.SHA3512:case l.SHAKE128:case l.SHAKE256:i=Bt(r);break;default:throw new x("HashStream: unsupported hashing algorithm: "+r,s.AlgUnsupported,{alg:e})}u(n)&&(n={});let a=4*1024*1024;n.chunkSize>0&&(a=n.chunkSize);let f=function(){if(n.signal!==void 0&&n.signal.aborted)throw n.signal.reason},o=0,c=function(d){i.update(d),o+=d.length,typeof n.onProgress=="function"&&n.onProgress(o)};if(typeof Blob<"u"&&t instanceof Blob)for(let d=0;d<t.size;d+=a)f(),c(new Uint8Array(await t.slice(d,d+a).arrayBuffer()));else if(typeof ReadableStream<"u"&&t instanceof ReadableStream){let d=t.getReader();try{for(;;){f();let g=await d.read();if(g.done)break;c(g.value)}}catch(g){throw await d.cancel(g),g}}else throw new TypeError("HashStream: input must be a Blob or ReadableStream.");return f(),w(i.digest())}async function _n(e,t,n,r,i){return e[t]=await kt(r,n),u(i)||(u(i.sizeField)||(typeof n=="string"?e[i.sizeField]=(await L(n)).byteLength:typeof Blob<"u"&&n instanceof Blob?e[i.sizeField]=n.size:e[i.sizeField]=n.byteLength),!u(i.nameField)&&!u(n.name)&&(e[i.nameField]=n.name)),e}async function qe(e,t,n){typeof Blob<"u"&&e instanceof Blob&&(e=[e]),e=Array.from(e),u(n)&&(n={});let r=e.reduce((f,o)=>f+o.size,0),i=0,a=[];for(let f of e){let o=await Ht(t,f,{chunkSize:n.chunkSize,signal:n.signal,onProgress:function(c){typeof n.onProgress=="function"&&n.onProgress(i+c,r)}});i+=f.size,a.push({name:u(f.name)?"":f.name,size:f.size,dig:o})}return a}async function Bn(e,t,n,r){let i=await qe(t,n,r);return e.file=typeof Blob<"u"&&t instanceof Blob?i[0]:i,e}async function Kn(e,t,n,r){let i=[];Array.isArray(e.file)?i=e.file:typeof e.file=="object"&&e.file!==null&&(i=[e.file]);let f=(await qe(t,n,r)).map(o=>({...o,match:i.some(c=>c!==null&&c.dig===o.dig&&(c.size===void 0||c.size===o.size))}));return{match:f.length>0&&f.every(o=>o.match),files:f}}var Un=new Uint32Array([1116352408,1899447441,3049323471,3921009573,961987163,1508970993,2453635748,2870763221,3624381080,310598401,607225278,1426881987,1925078388,2162078206,2614888103,3248222580,3835390401,4022224774,264347078,604807628,770255983,1249150122,1555081692,1996064986,2554220882,2821834349,2952996808,3210313671,3336571891,3584528711,113926993,338241895,666307205,773529912,1294757372,1396182291,1695183700,1986661051,2177026350,2456956037,2730485921,2820302411,3259730800,3345764771,3516065817,3600352804,4094571909,275423344,430227734,506948616,659060556,883997877,958139571,1322822218,1537002063,1747873779,1955562222,2024104815,2227730452,2361852424,2428436474,2756734187,3204031479,3329325298]),Rn=[3238371032,914150663,812702999,4144912697,4290775857,1750603025,1694076839,3204075428],Pn=[1779033703,3144134277,1013904242,2773480762,1359893119,2600822924,528734635,1541459225];function Dt(e){let t=new Uint32Array(e?Rn:Pn),n=new Uint32Array(64),r=new Uint8Array(64),i=0,a=0,f=function(o,c){for(let I=0;I<16;I++)n[I]=o[c+4*I]<<24|o[c+4*I+1]<<16|o[c+4*I+2]<<8|o[c+4*I+3];for(let I=16;I<64;I++){let U=n[I-15],R=n[I-2],ee=(U>>>7|U<<25)^(U>>>18|U<<14)^U>>>3,ne=(R>>>17|R<<15)^(R>>>19|R<<13)^R>>>10;n[I]=n[I-16]+ee+n[I-7]+ne|0}let d=t[0],g=t[1],A=t[2],m=t[3],h=t[4],S=t[5],N=t[6],j=t[7];for(let I=0;I<64;I++){let U=(h>>>6|h<<26)^(h>>>11|h<<21)^(h>>>25|h<<7),R=h&S^~h&N,ee=j+U+R+Un[I]+n[I]|0,ne=(d>>>2|d<<30)^(d>>>13|d<<19)^(d>>>22|d<<10),we=d&g^d&A^g&A,be=ne+we|0;j=N,N=S,S=h,h=m+ee|0,m=A,A=g,g=d,d=ee+be|0}t[0]+=d,t[1]+=g,t[2]+=A,t[3]+=m,t[4]+=h,t[5]+=S,t[6]+=N,t[7]+=j};return{update:function(o){a+=o.length;let c=0;if(i>0){for(;i<64&&c<o.length;)r[i++]=o[c++];if(i<64)return;f(r,0),i=0}for(;c+64<=o.length;c+=64)f(o,c);for(;c<o.length;)r[i++]=o[c++]},digest:function(){let o=a*8;r[i++]=128,i>56&&(r.fill(0,i),f(r,0),i=0),r.fill(0,i);let c=new DataView(r.buffer);c.setUint32(56,Math.floor(o/4294967296)),c.setUint32(60,o>>>0),f(r,0);let d=new Uint8Array(32),g=new DataView(d.buffer);for(let A=0;A<8;A++)g.setUint32(4*A,t[A]);return e?d.slice(0,28):d}}}var Ft=new Uint32Array([1116352408,3609767458,1899447441,602891725,3049323471,3964484399,3921009573,2173295548,961987163,4081628472,1508970993,3053834265,2453635748,2937671579,2870763221,3664609560,3624381080,2734883394,310598401,1164996542,607225278,1323610764,1426881987,3590304994,1925078388,4068182383,2162078206,991336113,2614888103,633803317,3248222580,3479774868,3835390401,2666613458,4022224774,944711139,264347078,2341262773,604807628,2007800933,770255983,1495990901,1249150122,1856431235,1555081692,3175218132,1996064986,2198950837,2554220882,3999719339,2821834349,766784016,2952996808,2566594879,3210313671,3203337956,3336571891,1034457026,3584528711,2466948901,113926993,3758326383,338241895,168717936,666307205,1188179964,773529912,1546045734,1294757372,1522805485,1396182291,2643833823,1695183700,2343527390,1986661051,1014477480,2177026350,1206759142,2456956037,344077627,2730485921,1290863460,2820302411,3158454273,3259730800,3505952657,3345764771,106217008,3516065817,3606008344,3600352804,1432725776,4094571909,1467031594,275423344,851169720,430227734,3100823752,506948616,1363258195,659060556,3750685593,883997877,3785050280,958139571,3318307427,1322822218,3812723403,1537002063,2003034995,1747873779,3602036899,1955562222,1575990012,2024104815,1125592928,2227730452,2716904306,2361852424,442776044,2428436474,593698344,2756734187,3733110249,3204031479,2999351573,3329325298,3815920427,3391569614,3928383900,3515267271,566280711,3940187606,3454069534,4118630271,4000239992,116418474,1914138554,174292421,2731055270,289380356,3203993006,460393269,320620315,685471733,587496836,852142971,1086792851,1017036298,365543100,1126000580,2618297676,1288033470,3409855158,1501505948,4234509866,1607167915,987167468,1816402316,1246189591]),Nn=[3418070365,3238371032,1654270250,914150663,2438529370,812702999,355462360,4144912697,1731405415,4290775857,2394180231,1750603025,3675008525,1694076839,1203062813,3204075428],Mn=[1779033703,4089235720,3144134277,2227873595,1013904242,4271175723,2773480762,1595750129,1359893119,2917565137,2600822924,725511199,528734635,4215389547,1541459225,327033209];function zn(e){let t=new Uint32Array(e?Nn:Mn),n=new Int32Array(80),r=new Int32Array(80),i=new Uint8Array(128),a=0,f=0,o=function(c,d){for(let E=0;E<16;E++){let T=d+8*E;n[E]=c[T]<<24|c[T+1]<<16|c[T+2]<<8|c[T+3],r[E]=c[T+4]<<24|c[T+5]<<16|c[T+6]<<8|c[T+7]}for(let E=16;E<80;E++){let T=n[E-15],z=r[E-15],me=(T>>>1|z<<31)^(T>>>8|z<<24)^T>>>7,je=(z>>>1|T<<31)^(z>>>8|T<<24)^(z>>>7|T<<25);T=n[E-2],z=r[E-2];let G=(T>>>19|z<<13)^(z>>>29|T<<3)^T>>>6,Ke=(z>>>19|T<<13)^(T>>>29|z<<3)^(z>>>6|T<<26),ve=(r[E-16]>>>0)+(je>>>0)+(r[E-7]>>>0)+(Ke>>>0);n[E]=n[E-16]+me+n[E-7]+G+Math.floor(ve/4294967296),r[E]=ve}let g=t[0],A=t[1],m=t[2],h=t[3],S=t[4],N=t[5],j=t[6],I=t[7],U=t[8],R=t[9],ee=t[10],ne=t[11],we=t[12],be=t[13],Je=t[14],Ge=t[15];for(let E=0;E<80;E++){let T=(U>>>14|R<<18)^(U>>>18|R<<14)^(R>>>9|U<<23),z=(R>>>14|U<<18)^(R>>>18|U<<14)^(U>>>9|R<<23),me=U&ee^~U&we,je=R&ne^~R&be,G=(Ge>>>0)+(z>>>0)+(je>>>0)+Ft[2*E+1]+(r[E]>>>0),Ke=Je+T+me+Ft[2*E]+n[E]+Math.floor(G/4294967296)|0,ve=G>>>0,hn=(g>>>28|A<<4)^(A>>>2|g<<30)^(A>>>7|g<<25),xn=(A>>>28|g<<4)^(g>>>2|A<<30)^(g>>>7|A<<25),wn=g&m^g&S^m&S,bn=A&h^A&N^h&N;G=(xn>>>0)+(bn>>>0);let mn=hn+wn+Math.floor(G/4294967296)|0,pn=G>>>0;Je=we,Ge=be,we=ee,be=ne,ee=U,ne=R,G=(I>>>0)+ve,U=j+Ke+Math.floor(G/4294967296)|0,R=G>>>0,j=S,I=N,S=m,N=h,m=g,h=A,G=ve+pn,g=Ke+mn+Math.floor(G/4294967296)|0,A=G>>>0}let re=function(E,T,z){let me=t[E+1]+(z>>>0);t[E]=t[E]+T+Math.floor(me/4294967296),t[E+1]=me};re(0,g,A),re(2,m,h),re(4,S,N),re(6,j,I),re(8,U,R),re(10,ee,ne),re(12,we,be),re(14,Je,Ge)};return{update:function(c){f+=c.length;let d=0;if(a>0){for(;a<128&&d<c.length;)i[a++]=c[d++];if(a<128)return;o(i,0),a=0}for(;d+128<=c.length;d+=128)o(c,d);for(;d<c.length;)i[a++]=c[d++]},digest:function(){let c=f*8;i[a++]=128,a>112&&(i.fill(0,a),o(i,0),a=0),i.fill(0,a);let d=new DataView(i.buffer);d.setUint32(120,Math.floor(c/4294967296)),d.setUint32(124,c>>>0),o(i,0);let g=new Uint8Array(64),A=new DataView(g.buffer);for(let m=0;m<16;m++)A.setUint32(4*m,t[m]);return e?g.slice(0,48):g}}}var It=new Uint32Array([1,0,32898,0,32906,2147483648,2147516416,2147483648,32907,0,2147483649,0,2147516545,2147483648,32777,2147483648,138,0,136,0,2147516425,0,2147483658,0,2147516555,0,139,2147483648,32905,2147483648,32771,2147483648,32770,2147483648,128,2147483648,32778,0,2147483658,2147483648,2147516545,2147483648,32896,2147483648,2147483649,0,2147516424,2147483648]),On=[0,1,62,28,27,36,44,6,55,20,3,10,43,25,39,41,45,15,21,8,18,2,61,56,14],_t={"SHA3-224":[144,28,6],"SHA3-256":[136,32,6],"SHA3-384":[104,48,6],"SHA3-512":[72,64,6],SHAKE128:[168,32,31],SHAKE256:[136,64,31]};function Tt(e){let t=new Uint32Array(10),n=new Uint32Array(50);for(let r=0;r<24;r++){for(let i=0;i<5;i++)t[2*i]=e[2*i]^e[2*i+10]^e[2*i+20]^e[2*i+30]^e[2*i+40],t[2*i+1]=e[2*i+1]^e[2*i+11]^e[2*i+21]^e[2*i+31]^e[2*i+41];for(let i=0;i<5;i++){let a=2*((i+1)%5),f=2*((i+4)%5),o=t[f]^(t[a]<<1|t[a+1]>>>31),c=t[f+1]^(t[a+1]<<1|t[a]>>>31);for(let d=0;d<25;d+=5)e[2*(i+d)]^=o,e[2*(i+d)+1]^=c}for(let i=0;i<5;i++)for(let a=0;a<5;a++){let f=i+5*a,o=e[2*f],c=e[2*f+1],d=On[f];d>=32&&([o,c]=[c,o],d-=32);let g=2*(a+5*((2*i+3*a)%5));d===0?(n[g]=o,n[g+1]=c):(n[g]=o<<d|c>>>32-d,n[g+1]=c<<d|o>>>32-d)}for(let i=0;i<25;i+=5)for(let a=0;a<5;a++){let f=2*(a+i),o=2*((a+1)%5+i),c=2*((a+2)%5+i);e[f]=n[f]^~n[o]&n[c],e[f+1]=n[f+1]^~n[o+1]&n[c+1]}e[0]^=It[2*r],e[1]^=It[2*r+1]}}function Bt(e){let[t,n,r]=_t[e],i=new Uint32Array(50),a=new Uint8Array(t),f=0,o=function(){let c=new DataView(a.buffer);for(let d=0;d<t/4;d++)i[d]^=c.getUint32(4*d,!0);Tt(i),f=0};return{update:function(c){for(let d=0;d<c.length;d++)a[f++]=c[d],f===t&&o()},digest:function(){a.fill(0,f),a[f]^=r,a[t-1]^=128,o();let c=new Uint8Array(n);for(let d=0;d<n;d+=t){d>0&&Tt(i);let g=new DataView(new ArrayBuffer(t));for(let A=0;A<t/4;A++)g.setUint32(4*A,i[A],!0);c.set(new Uint8Array(g.buffer,0,Math.min(t,n-d)),d)}return c}}}function et(e){return Object.keys(e)}async function Ee(e,t){return u(t)?e:Qe(e,t)}function Qe(e,t){if(Array.isArray(e))return e.map(r=>Qe(r,t));if(e===null||typeof e!="object")return e;let n={};for(let[r,i]of $n(t))i===null||e[r]===void 0?n[r]=e[r]:n[r]=Qe(e[r],i);return n}function $n(e){let t=[];if(Array.isArray(e))for(let r of e)if(typeof r=="string")t.push([r,null]);else if(r!==null&&typeof r=="object"&&!Array.isArray(r))for(let[i,a]of Object.entries(r))t.push([i,a]);else throw new q("Canonical: invalid canon element: "+JSON.stringify(r),s.CanonInvalid);else if(e!==null&&typeof e=="object")for(let[r,i]of Object.entries(e))t.push([r,i!==null&&typeof i=="object"?i:null]);else throw new q("Canonical: canon must be an array or object.",s.CanonInvalid);if(new Set(t.map(r=>r[0])).size!==t.length)throw new q("Canonical: Canon cannot have duplicate fields.",s.CanonInvalid);return t}async function He(e,t,n){return!u(n)&&n.normalizeUnicode===!0&&(e=Q(e)),JSON.stringify(await Ee(e,t))}function Q(e){if(typeof e=="string")return e.normalize("NFC");if(Array.isArray(e))return e.map(Q);if(e!==null&&typeof e=="object"){let t={};for(let n of Object.keys(e))t[n]=Q(e[n]);return t}return e}async function Kt(e,t,n,r){if(u(t))throw new x("Hash is not given",s.AlgUnsupported);if(e instanceof Uint8Array||e instanceof ArrayBuffer){if(u(n)&&u(r))return await Y(t,e);e=JSON.parse(new TextDecoder().decode(e))}return await Y(t,await L(await He(e,n,r)))}async function ce(e,t,n,r){return await w(await Kt(e,t,n,r))}var Ln={ES224:{p:BigInt("0xffffffffffffffffffffffffffffffff000000000000000000000001"),b:BigInt("0xb4050a850c04b3abf54132565044b0b7d7bfd8ba270b39432355ffb4"),gx:BigInt("0xb70e0cbd6bb4bf7f321390b94a03c1d356c21122343280d6115c1d21"),gy:BigInt("0xbd376388b5f723fb4c22dfe6cd4375a05a07476444d5819985007e34")},ES256:{p:BigInt("0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),b:BigInt("0x5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),gx:BigInt("0x6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),gy:BigInt("0x4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5")},ES384:{p:BigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff"),b:BigInt("0xb3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef"),gx:BigInt("0xaa87ca22be8b05378eb1c71ef320ad746e1d3b628ba79b9859f741e082542a385502f25dbf55296c3a545e3872760ab7"),gy:BigInt("0x3617de4a96262c6f5d9e98bf9292dc29f8f41dbd289a147ce9da3113b5f0b8c00a60b1ce1d7e819d7a431d7c90ea0e5f")},ES512:{p:(1n<<521n)-1n,b:BigInt("0x0051953eb9618e1c9a1f929a21a0b68540eea2da725b99b315f3b8b489918ef109e156193951ec7e937b1652c0bd3bb1bf073573df883d2c34f1ef451fd46b503f00"),gx:BigInt("0x00c6858e06b70404e9cd9e3ecb662395b4429c648139053fb521f828af606b4d3dbaa14b5e77efe75928fe1dc127a2ffa8de3348b3c1856a429bf97e7e31c2e5bd66"),gy:BigInt("0x011839296a789a3bc0045c8a5fb42c7d1bd998f54449579b446817afbd17273e662c97ee72995ef42640c550b9013fad0761353c7086a272c24088be94769fd16650")}},v={New:async function(e){let t=Rt(e),n={alg:e,d:w(W(V(e),t)),x:await v.PublicFromD(e,t)};return{privateKey:await v.FromCozeKey(n),publicKey:await v.FromCozeKey(n,!0)}},FromCozeKey:async function(e,t){let n=ge(e.alg),r=C(e.x);if(r.length!==J(e.alg))throw new y("ECDSA.FromCozeKey: incorrect x size.",s.KeyInvalid,{field:"x"});let i=J(e.alg)/2,a={x:fe(r.slice(0,i)),y:fe(r.slice(i))};if(!Gn(n,a))throw new y("ECDSA.FromCozeKey: the key is not on the curve.",s.KeyInvalid,{field:"x"});let f={type:"public",extractable:!0,algorithm:{name:p.ECDSA,namedCurve:le(e.alg)},usages:["verify"],ecdsa:{alg:e.alg,point:a}};if(!u(e.d)&&!t){let o=fe(C(e.d));if(o<=0n||o>=n.n)throw new y("ECDSA.FromCozeKey: invalid private key.",s.KeyInvalid,{field:"d"});f.type="private",f.usages=["sign"],f.ecdsa.d=o}return f},IsKey:function(e){return typeof e=="object"&&e!==null&&typeof e.ecdsa=="object"},ToCozeKey:function(e){let t=e.ecdsa.alg,n=J(t)/2,r={alg:t,x:w(ye(W(n,e.ecdsa.point.x),W(n,e.ecdsa.point.y)))};return e.ecdsa.d!==void 0&&(r.d=w(W(V(t),e.ecdsa.d))),r},PublicFromD:async function(e,t){let n=ge(e),r=tt(n,Pe(n,t,{x:n.gx,y:n.gy,z:1n})),i=J(e)/2;return w(ye(W(i,r.x),W(i,r.y)))},KeyFromSeed:async function(e,t){let n=ge(e),r=B(e),i=Math.ceil(n.nBits/8),a=BigInt(i*8-n.nBits),f=await ke(r,new TextEncoder().encode("Coze NewKeyFromSeed"),t);for(let o=0;o<256;o++){let c=ye(new TextEncoder().encode(e),new Uint8Array([o])),d=fe(await jn(r,f,c,i))>>a;if(d>0n&&d<n.n)return{alg:e,d:w(W(V(e),d)),x:await v.PublicFromD(e,d)}}throw new y("ECDSA.KeyFromSeed: no valid scalar derived.",s.KeyInvalid,{field:"seed"})},SignBuffer:async function(e,t,n){let r=e.ecdsa.alg,i=await Y(B(r),t);return v.SignDigest(e,new Uint8Array(i),n)},SignDigest:async function(e,t,n){if(e.type!=="private")throw new y("ECDSA.SignDigest: key must be private.",s.KeyInvalid,{field:"d"});let r=e.ecdsa.alg,i=ge(r),a=Ne(i,t),f=null;for(n===!0&&(f=await Jn(r,e.ecdsa.d,t));;){let o=f===null?Rt(r):await f.next(),c=b(tt(i,Pe(i,o,{x:i.gx,y:i.gy,z:1n})).x,i.n);if(c===0n)continue;let d=b(nt(o,i.n)*(a+c*e.ecdsa.d),i.n);if(d===0n)continue;let g=K(r)/2;return ye(W(g,c),W(g,d)).buffer}},VerifyBuffer:async function(e,t,n){let r=await Y(B(e.ecdsa.alg),t);return v.VerifyDigest(e,new Uint8Array(r),n)},VerifyDigest:async function(e,t,n){let r=e.ecdsa.alg,i=ge(r);if(n=new Uint8Array(n),n.length!==K(r))return!1;let a=K(r)/2,f=fe(n.slice(0,a)),o=fe(n.slice(a));if(f<=0n||f>=i.n||o<=0n||o>=i.n)return!1;let c=Ne(i,t),d=nt(o,i.n),g=Pe(i,b(c*d,i.n),{x:i.gx,y:i.gy,z:1n}),A=Pe(i,b(f*d,i.n),{x:e.ecdsa.point.x,y:e.ecdsa.point.y,z:1n}),m=Nt(i,g,A);return m.z===0n?!1:b(tt(i,m).x,i.n)===f}};function ge(e){let t=Ln[e];if(t===void 0)throw new x("ECDSA: unsupported algorithm: "+e,s.AlgUnsupported,{alg:e});return t.n===void 0&&(t.n=Te(e),t.nBits=t.n.toString(2).length),t}function Rt(e){let t=ge(e),n=Math.ceil(t.nBits/8),r=BigInt(n*8-t.nBits);for(;;){let i=fe(crypto.getRandomValues(new Uint8Array(n)))>>r;if(i>0n&&i<t.n)return i}}async function Jn(e,t,n){let r=ge(e),i=B(e),a=Math.ceil(r.nBits/8),f=X(e),o=(h,...S)=>ke(i,h,Mt(S)),c=W(a,t),d=W(a,b(Ne(r,n),r.n)),g=new Uint8Array(f).fill(1),A=new Uint8Array(f);A=await o(A,g,[0],c,d),g=await o(A,g),A=await o(A,g,[1],c,d),g=await o(A,g);let m=!1;return{next:async function(){for(;;){m&&(A=await o(A,g,[0]),g=await o(A,g)),m=!0;let h=new Uint8Array(0);for(;h.length<a;)g=await o(A,g),h=ye(h,g);let S=Ne(r,h);if(S>0n&&S<r.n)return S}}}}function Ne(e,t){let n=fe(t),r=t.length*8;return r>e.nBits&&(n>>=BigInt(r-e.nBits)),n}function b(e,t){let n=e%t;return n<0n?n+t:n}function nt(e,t){let[n,r]=[b(e,t),t],[i,a]=[1n,0n];for(;r!==0n;){let f=n/r;[n,r]=[r,n-f*r],[i,a]=[a,i-f*a]}if(n!==1n)throw new y("ECDSA: no modular inverse.",s.KeyInvalid);return b(i,t)}function Gn(e,t){return t.x<0n||t.x>=e.p||t.y<0n||t.y>=e.p?!1:b(t.y*t.y-(t.x*t.x*t.x-3n*t.x+e.b),e.p)===0n}function tt(e,t){let n=nt(t.z,e.p),r=b(n*n,e.p);return{x:b(t.x*r,e.p),y:b(t.y*r*n,e.p)}}function Pt(e,t){if(t.z===0n||t.y===0n)return{x:0n,y:1n,z:0n};let n=e.p,r=b(t.z*t.z,n),i=b(t.y*t.y,n),a=b(t.x*i,n),f=b(3n*(t.x-r)*(t.x+r),n),o=b(f*f-8n*a,n),c=b((t.y+t.z)*(t.y+t.z)-i-r,n),d=b(f*(4n*a-o)-8n*i*i,n);return{x:o,y:d,z:c}}function Nt(e,t,n){if(t.z===0n)return n;if(n.z===0n)return t;let r=e.p,i=b(t.z*t.z,r),a=b(n.z*n.z,r),f=b(t.x*a,r),o=b(n.x*i,r),c=b(t.y*n.z*a,r),d=b(n.y*t.z*i,r),g=b(o-f,r),A=b(2n*(d-c),r);if(g===0n)return A===0n?Pt(e,t):{x:0n,y:1n,z:0n};let m=b(4n*g*g,r),h=b(g*m,r),S=b(f*m,r),N=b(A*A-h-2n*S,r),j=b(A*(S-N)-2n*c*h,r),I=b(((t.z+n.z)*(t.z+n.z)-i-a)*g,r);return{x:N,y:j,z:I}}function Pe(e,t,n){let r={x:0n,y:1n,z:0n};for(let i=BigInt(t.toString(2).length-1);i>=0n;i--)r=Pt(e,r),t>>i&1n&&(r=Nt(e,r,n));return r}async function jn(e,t,n,r){let i=new Uint8Array(0),a=new Uint8Array(0);for(let f=1;i.length<r;f++)a=await ke(e,t,Mt([a,n,[f]])),i=ye(i,a);return i.slice(0,r)}function fe(e){let t=0n;for(let n of e)t=(t<<8n)+BigInt(n);return t}function W(e,t){let n=new Uint8Array(e);for(let r=e-1;r>=0;r--)n[r]=Number(t&0xffn),t>>=8n;return n}function ye(e,t){let n=new Uint8Array(e.length+t.length);return n.set(e,0),n.set(t,e.length),n}function Mt(e){let t=new Uint8Array(0);for(let n of e)t=ye(t,new Uint8Array(n));return t}var it=new WeakMap;function Zn(){it=new WeakMap}var k={New:async function(e){switch(u(e)&&(e=l.ES256),e){case l.ES224:return v.New(e);case l.ES256:case l.ES384:case l.ES512:return await crypto.subtle.generateKey({name:p.ECDSA,namedCurve:le(e)},!0,["sign","verify"]);case l.Ed25519:try{return await crypto.subtle.generateKey({name:l.Ed25519},!0,["sign","verify"])}catch(t){throw Vt("CryptoKey.New",e,t)}default:throw new x("CryptoKey.New: Unsupported key algorithm:"+e,s.AlgUnsupported,{alg:e})}},FromCozeKey:async function(e,t){let n=u(e.d)||t?"verify":"sign",r=it.get(e);r===void 0&&(r={},it.set(e,r));let i=r[n];if(i!==void 0&&i.alg===e.alg&&i.x===e.x&&i.d===e.d)return i.key;let a=Yn(e,t);r[n]={alg:e.alg,x:e.x,d:e.d,key:a};try{return await a}catch(f){throw r[n]!==void 0&&r[n].key===a&&delete r[n],f}},ToPublic:async function(e){delete e.d,e.key_ops=["verify"]},ToCozeKey:async function(e){if(v.IsKey(e)){let n=v.ToCozeKey(e);return n.tmb=await H(n),n}let t=await crypto.subtle.exportKey("jwk",e);return Ot(t)},SignBuffer:async function(e,t){let n=await k.algFromCryptoKey(e);if(v.IsKey(e))var r=await v.SignBuffer(e,t);else r=await crypto.subtle.sign(zt(n),e,t);return _(n)==p.ECDSA&&(r=Lt(n,r)),r},SignBufferB64:async function(e,t){return await w(await k.SignBuffer(e,t))},SignString:async function(e,t){return await k.SignBufferB64(e,await L(t))},VerifyArrayBuffer:async function(e,t,n,r){return v.IsKey(t)?v.VerifyBuffer(t,n,r):(await k.ToPublic(t),await crypto.subtle.verify(zt(await k.algFromCryptoKey(t)),t,r,n))},VerifyMsg:async function(e,t,n,r){return k.VerifyArrayBuffer(e,t,await L(n),await de(r))},GetSignHashAlgoFromCryptoKey:async function(e){return B(await k.algFromCryptoKey(e))},algFromCryptoKey:async function(e){return e.algorithm.name===l.Ed25519?l.Ed25519:k.algFromCrv(e.algorithm.namedCurve)},algFromCrv:async function(e){switch(e){case l.Ed25519:var t=l.Ed25519;break;case O.P224:t=l.ES224;break;case O.P256:t=l.ES256;break;case O.P384:t=l.ES384;break;case O.P521:t=l.ES512;break;default:throw new x("CryptoKey.ToCozeKey: Unsupported key algorithm.",s.AlgUnsupported,{crv:e})}return t}};function at(e){if(u(e.x))throw new y("CozeKeyToJWK: key x must be set.",s.KeyInvalid,{field:"x"});var t={};switch(e.alg){case l.Ed25519:t.kty="OKP",t.crv=l.Ed25519,t.alg="EdDSA",t.use=ae.Sig,t.x=e.x;break;case l.ES256:case l.ES384:case l.ES512:{t.kty=pe.EC,t.crv=le(e.alg),t.alg=e.alg,t.use=ae.Sig;let n=J(e.alg)/2,r=C(e.x);if(r.length!==n*2)throw new y("CozeKeyToJWK: incorrect x size for "+e.alg+".",s.KeyInvalid,{field:"x"});t.x=w(r.slice(0,n)),t.y=w(r.slice(n));break}default:throw new x("CozeKeyToJWK: unsupported alg: "+e.alg,s.AlgUnsupported,{alg:e.alg})}return u(e.d)||(t.d=e.d),t}async function Ot(e){let t;switch(e.crv){case l.Ed25519:if(e.kty!=="OKP")throw new y("JWKToCozeKey: kty must be OKP for Ed25519.",s.KeyInvalid,{field:"kty"});t=l.Ed25519;break;case O.P256:case O.P384:case O.P521:if(e.kty!==pe.EC)throw new y("JWKToCozeKey: kty must be EC for curve "+e.crv+".",s.KeyInvalid,{field:"kty"});t=await k.algFromCrv(e.crv);break;default:throw new x("JWKToCozeKey: unsupported crv: "+e.crv,s.AlgUnsupported,{alg:e.crv})}if(!u(e.alg)&&e.alg!==t&&!(t===l.Ed25519&&e.alg==="EdDSA"))throw new x("JWKToCozeKey: JWK alg "+e.alg+" mismatch with crv "+e.crv+".",s.AlgMismatch,{alg:e.alg});var n={alg:t};if(u(e.x))throw new y("JWKToCozeKey: JWK x must be set.",s.KeyInvalid,{field:"x"});if(t===l.Ed25519){if(C(e.x).length!==J(t))throw new y("JWKToCozeKey: incorrect x size for Ed25519.",s.KeyInvalid,{field:"x"});n.x=e.x,u(e.d)||(n.d=e.d)}else{if(u(e.y))throw new y("JWKToCozeKey: JWK y must be set.",s.KeyInvalid,{field:"y"});let r=J(t)/2;n.x=w(Wn(rt("x",r,C(e.x)),rt("y",r,C(e.y))).buffer),u(e.d)||(n.d=w(rt("d",V(t),C(e.d)).buffer))}return n.tmb=await H(n),n}async function Xn(e,t){let n={name:l.Ed25519};try{return u(e.d)||t?await crypto.subtle.importKey("raw",C(e.x),n,!0,["verify"]):await crypto.subtle.importKey("jwk",at(e),n,!0,["sign"])}catch(r){throw Vt("CryptoKey.FromCozeKey",e.alg,r)}}async function Yn(e,t){if(e.alg===l.Ed25519)return Xn(e,t);if(e.alg===l.ES224)return v.FromCozeKey(e,t);if(_(e.alg)!=p.ECDSA)throw new x("CryptoKey.FromCozeKey: unsupported CryptoKey algorithm: "+e.alg,s.AlgUnsupported,{alg:e.alg});let n=at(e);if(u(e.d)||t){var r="verify";delete n.d}else r="sign";return await crypto.subtle.importKey("jwk",n,{name:p.ECDSA,namedCurve:n.crv},!0,[r])}function rt(e,t,n){if(n.length>t)throw new y("JWKToCozeKey: incorrect "+e+" size.",s.KeyInvalid,{field:e});let r=new Uint8Array(t);return r.set(n,t-n.length),r}function Wn(e,t){let n=new Uint8Array(e.length+t.length);return n.set(e,0),n.set(t,e.length),n}function zt(e){return e===l.Ed25519?{name:l.Ed25519}:{name:p.ECDSA,hash:{name:B(e)}}}function Vt(e,t,n){return n instanceof DOMException&&n.name==="NotSupportedError"?new x(e+": alg "+t+" unsupported in this browser.",s.AlgUnsupported,{alg:t}):n}function $t(e,t){if(typeof t!="bigint")throw new TypeError("IsLowS: s is not of type bigint");return We(e)>t}function qn(e,t){if(typeof t!="bigint")throw new TypeError("toLowS: s is not of type bigint");return $t(e,t)?t:Te(e)-t}async function Me(e,t){let n=await de(t),r=await Lt(e,n);return w(r)}async function lt(e,t){let n=await Qn(e,t);return $t(e,n)}function Qn(e,t){let n=K(e)/2,r=t.slice(n);return Jt(r)}async function Lt(e,t){let n=K(e)/2,r=t.slice(0,n),i=t.slice(n),a=Jt(i),f=qn(e,a),o=er(K(e)/2,f);var c=new Uint8Array(r.byteLength+o.byteLength);return c.set(new Uint8Array(r),0),c.set(new Uint8Array(o),r.byteLength),t=c.buffer,t}function Jt(e){let t=0n,n=new Uint8Array(e);for(let r=0;r<n.length;r++)t=(t<<8n)+BigInt(n[r]);return t}function er(e,t){let n=new ArrayBuffer(e),r=new DataView(n);do e--,r.setUint8(e,Number(t&BigInt(255))),t>>=8n;while(e>0);return n}var Zt=["alg","x"],tr=["alg","iat","kid","tmb","typ","rvk","x"];async function nr(e){if(u(e)&&(e=l.ES256),_(e)==p.ECDSA||e==l.Ed25519)var t=await k.New(e);else throw new x("Coze.NewKey: only ECDSA algs and Ed25519 are currently supported.",s.AlgUnsupported,{alg:e});let n=await k.ToCozeKey(t.privateKey);return n.iat=Math.floor(Date.now()/1e3),n.tmb=await H(n),n.kid="My Cyphr.me Key.",n}var jt=new WeakMap,st=[48,46,2,1,0,48,5,6,3,43,101,112,4,34,4,32];async function Xt(e){let t=new Uint8Array(st.length+e.length);t.set(st),t.set(e,st.length);let n=await crypto.subtle.importKey("pkcs8",t,{name:l.Ed25519},!0,["sign"]);return(await crypto.subtle.exportKey("jwk",n)).x}async function Yt(e,t){if(!(t instanceof Uint8Array))throw new TypeError("Coze.NewKeyFromSeed: seed must be a Uint8Array.");let n;if(_(e)==p.ECDSA){let r=X(e)/2;if(t.length<r)throw new y(`Coze.NewKeyFromSeed: seed must be at least ${r} bytes for ${e}.`,s.KeyInvalid,{field:"seed"});n=await v.KeyFromSeed(e,t)}else if(e==l.Ed25519){if(t.length!==V(e))throw new y("Coze.NewKeyFromSeed: Ed25519 seed must be 32 bytes.",s.KeyInvalid,{field:"seed"});n={alg:e,d:w(t),x:await Xt(t)}}else throw new x("Coze.NewKeyFromSeed: only ECDSA algs and Ed25519 are currently supported.",s.AlgUnsupported,{alg:e});return n.tmb=await H(n),n}var rr=21e4;async function ir(e,t,n,r){if(typeof n=="string"&&(n=new TextEncoder().encode(n)),!(n instanceof Uint8Array)||n.length<16)throw new y("Coze.NewKeyFromPassword: salt must be at least 16 bytes.",s.KeyInvalid,{field:"salt"});let i=rr;if(!u(r)&&r.iterations!==void 0&&(i=r.iterations),!Number.isSafeInteger(i)||i<1)throw new y("Coze.NewKeyFromPassword: iterations must be a positive integer.",s.KeyInvalid,{field:"iterations"});let a=V(l.Ed25519);_(e)==p.ECDSA&&(a=X(e));let f=await crypto.subtle.importKey("raw",new TextEncoder().encode(t.normalize("NFC")),"PBKDF2",!1,["deriveBits"]),o=await crypto.subtle.deriveBits({name:"PBKDF2",hash:l.SHA512,salt:n,iterations:i},f,a*8);return{key:await Yt(e,new Uint8Array(o)),params:{alg:e,kdf:"PBKDF2",hash:l.SHA512,iterations:i,salt:w(n)}}}async function H(e){if(u(e.alg)||u(e.x))throw new y("Coze.Thumbprint: alg or x is empty.",s.KeyInvalid,{field:u(e.alg)?"alg":"x"});let t=jt.get(e);if(t!==void 0&&t.alg===e.alg&&t.x===e.x)return t.tmb;let n=await ce(e,await B(e.alg),Zt);return jt.set(e,{alg:e.alg,x:e.x,tmb:n}),n}async function ar(e){let t=await H(e);if(t!==e.tmb)throw new y("Coze.ThumbprintMatch: key.tmb does not match the calculated thumbprint.",s.TmbMismatch,{field:"tmb"});return t}function ot(e){let t={};for(let[n,r]of Object.entries(e))tr.includes(n)&&(t[n]=r);return t}function lr(e){return Wt(e)!==void 0}function fr(e){let t=Wt(e);if(t!==void 0)throw new y(`AssertPublic: key has private component "${t}".`,s.KeyInvalid,{field:t})}function Wt(e){if(!(e===null||typeof e!="object"))return Object.keys(e).find(t=>t.toLowerCase()==="d")}async function sr(e){let t=[],n={},r=function(d,g,A){let m={name:d,status:g};u(A)||(m.message=A),t.push(m),n[d]=g},i=(...d)=>d.every(g=>n[g]==="pass"),a=function(d,...g){r(d,"skip","Requires passing "+g.filter(A=>n[A]!=="pass").join(", ")+".")},f={};(typeof e!="object"||e===null)&&(e={});let o;try{o=Re(e.alg),o.Use!==ae.Sig?r("alg_known","fail",`alg "${e.alg}" is not a signing alg.`):r("alg_known","pass")}catch{r("alg_known","fail",`alg "${e.alg}" is not supported.`)}let c=[];for(let d of["x","d","tmb"])if(!u(e[d]))try{f[d]=C(e[d],d)}catch{c.push(d)}u(e.x)&&u(e.d)&&u(e.tmb)?r("b64ut","fail","At least one of x, d, and tmb must be set."):c.length>0?r("b64ut","fail","Not strict b64ut: "+c.join(", ")+"."):r("b64ut","pass");for(let[d,g,A]of[["x_length","x","XSize"],["d_length","d","DSize"]])u(e[g])?r(d,"skip",`No ${g}.`):i("alg_known","b64ut")?f[g].length!==o[A]?r(d,"fail",`${g} is ${f[g].length} bytes, ${e.alg} requires ${o[A]}.`):r(d,"pass"):a(d,"alg_known","b64ut"),d==="x_length"&&(u(e.x)?r("y_length","skip","No x."):i("alg_known","b64ut")?o.Genus!==p.ECDSA?r("y_length","skip",`${e.alg} has no Y coordinate.`):f.x.length===o.XSize/2?r("y_length","fail","x is only the X coordinate.  Coze x is X || Y."):f.x.length!==o.XSize?a("y_length","x_length"):r("y_length","pass"):a("y_length","alg_known","b64ut"));if(u(e.tmb)?r("tmb_matches","skip","No tmb."):i("alg_known","b64ut")?u(e.x)?f.tmb.length!==o.HashSize?r("tmb_matches","fail",`tmb is ${f.tmb.length} bytes, ${e.alg} requires ${o.HashSize}.`):r("tmb_matches","pass"):i("x_length")?await H(e)!==e.tmb?r("tmb_matches","fail","tmb does not match the thumbprint of alg and x."):r("tmb_matches","pass"):a("tmb_matches","x_length"):a("tmb_matches","alg_known","b64ut"),u(e.d)||u(e.x))r("d_derives_x","skip","Requires d and x.");else if(!i("x_length","d_length"))a("d_derives_x","x_length","d_length");else try{let d;o.Genus===p.ECDSA?d=await v.PublicFromD(e.alg,BigInt("0x"+Fe(f.d))):e.alg===l.Ed25519&&(d=await Xt(f.d)),d===void 0?r("d_derives_x","skip",`Deriving x is not supported for ${e.alg}.`):d!==e.x?r("d_derives_x","fail","x is not the public key of d."):r("d_derives_x","pass")}catch(d){r("d_derives_x","fail","d is invalid: "+d.message)}if(u(e.d)||u(e.x))r("sign_verify_roundtrip","skip","Requires d and x.");else if(n.d_derives_x==="fail"||!i("x_length","d_length"))a("sign_verify_roundtrip","x_length","d_length","d_derives_x");else try{let d="Coze Diagnose",g=await Ce(d,e);await Ae(d,e,g)?r("sign_verify_roundtrip","pass"):r("sign_verify_roundtrip","fail","Signature by d did not verify with x.")}catch(d){r("sign_verify_roundtrip","fail",d.message)}return{ok:t.every(d=>d.status!=="fail"),checks:t}}async function or(e){if(u(e.d))return console.error("Coze key missing `d`"),!1;try{let t="7AtyaCHO2BAG06z0W1tOQlZFWbhxGgqej4k9-HWP3DE-zshRbrE-69DIfgY704_FDYez7h_rEI1WQVKhv5Hd5Q",n=await Ce(t,e);return Ae(t,e,n)}catch{return!1}}async function ur(e){if(typeof e!="object")return console.error("Correct: CozeKey must be passed in as an object."),!1;if(u(e.alg))return console.error("Correct: Alg must be set"),!1;let t=Re(e.alg),n=u(e.tmb),r=u(e.x),i=u(e.d);if(n&&r&&i)return console.error("Correct: At least one of [x, tmb, d] must be set"),!1;for(let a of["x","d","tmb"])if(!u(e[a]))try{C(e[a],a)}catch(f){return console.error("Correct: "+f.message),!1}if(r&&i)return n||e.tmb.length!==t.HashSizeB64?(console.error("Correct: Incorrect `tmb` size: ",e.tmb.length),!1):!0;if(!r&&e.x.length!==t.XSizeB64)return console.error("Correct: Incorrect x size: ",e.x.length),!1;if(!n&&!r){let a=await H(e);if(e.tmb!==a)return console.error("Correct: Incorrect given `tmb`: ",e.tmb),!1}if(!i&&!r){let a=await k.FromCozeKey(e),f=await L("Test Signing"),o=await k.SignBuffer(a,f),c=await k.FromCozeKey(e,!0);if(!await k.VerifyArrayBuffer(e.alg,c,f,o))return console.error("Correct: private key invalid."),!1}return!0}async function dr(e,t){if(u(e))throw new y("CozeKey.Revoke: Private key not set.  Cannot sign message",s.KeyInvalid,{field:"d"});typeof t=="string"&&(t={msg:t}),u(t)&&(t={});for(let i of["alg","iat","tmb","rvk"])if(i in t)throw new D(`CozeKey.Revoke: "${i}" is set by Revoke and may not be given.`,s.FieldReserved,{field:i});var n={};n.pay={},u(t.typ)||(n.pay.typ=t.typ),n.pay.rvk=Math.round(Date.now()/1e3),u(t.msg)||(n.pay.msg=t.msg);for(let[i,a]of Object.entries(t))i!=="typ"&&i!=="msg"&&(n.pay[i]=a);let r=e.rvk;delete e.rvk;try{n=await ut(n,e,null,{setStandard:!0})}catch(i){throw r!==void 0&&(e.rvk=r),i}return r!==void 0?e.rvk=r:e.rvk=n.pay.rvk,n}function se(e){let t=e.rvk;return t==null?!1:typeof t=="number"&&Number.isInteger(t)?t>0:!0}async function cr(e,t){let n=e.pay.rvk;return!Number.isSafeInteger(n)||n<=0||e.pay.tmb!==await H(t)||Number.isInteger(t.rvk)&&t.rvk>0&&n<t.rvk?!1:dt(e,t,{allowRevoked:!0})}async function ze(e,t){let n=e instanceof Map;if(!n&&!Array.isArray(e))return e;if(u(t))throw new y("LookupKey: no key for tmb: tmb is empty.",s.KeyNotFound,{field:"tmb"});let r=e;n&&(r=e.has(t)?[e.get(t)]:[]);for(let i of r){if(u(i))continue;let a;try{a=await H(i)}catch{continue}if(a===t)return i}throw new y(`LookupKey: no key for tmb ${t}.`,s.KeyNotFound,{field:"tmb",tmb:t})}var oe=2,Oe=3,he=4,xe=6,$=48,Qt=160,ct=161,en=[42,134,72,206,61,2,1],tn=[43,101,112],gt={ES224:[43,129,4,0,33],ES256:[42,134,72,206,61,3,1,7],ES384:[43,129,4,0,34],ES512:[43,129,4,0,35]};async function yr(e){let t=br(e),n;switch(t.label){case"PUBLIC KEY":n=xr(t.der);break;case"PRIVATE KEY":n=await wr(t.der);break;case"EC PRIVATE KEY":n=await nn(t.der,null);break;default:throw new y("PEMToCozeKey: unsupported PEM type: "+t.label,s.KeyInvalid,{field:"pem"})}return n.tmb=await H(n),n}function Ar(e,t){let n=!u(t)&&t.private===!0,r=!u(t)&&t.sec1===!0;if(u(e.x))throw new y("CozeKeyToPEM: key x must be set.",s.KeyInvalid,{field:"x"});if(n&&u(e.d))throw new y("CozeKeyToPEM: private key d must be set.",s.KeyInvalid,{field:"d"});let i=C(e.x);if(i.length!==J(e.alg))throw new y("CozeKeyToPEM: incorrect x size for "+e.alg+".",s.KeyInvalid,{field:"x"});if(e.alg===l.Ed25519){if(r)throw new x("CozeKeyToPEM: SEC1 is only for EC keys.",s.AlgUnsupported,{alg:e.alg});let g=F($,F(xe,tn));if(!n)return De("PUBLIC KEY",F($,g,F(Oe,[0],i)));let A=F(he,C(e.d));return De("PRIVATE KEY",F($,F(oe,[0]),g,F(he,A)))}let a=gt[e.alg];if(a===void 0)throw new x("CozeKeyToPEM: unsupported alg: "+e.alg,s.AlgUnsupported,{alg:e.alg});let f=F(Oe,[0,4],i),o=F($,F(xe,en),F(xe,a));if(!n)return De("PUBLIC KEY",F($,o,f));let c=F(he,C(e.d));if(r)return De("EC PRIVATE KEY",F($,F(oe,[1]),c,F(Qt,F(xe,a)),F(ct,f)));let d=F($,F(oe,[1]),c,F(ct,f));return De("PRIVATE KEY",F($,F(oe,[0]),o,F(he,d)))}function hr(e,t){let n=C(e);if(_(t)!==p.ECDSA)throw new x("SigToDER: alg must be ECDSA: "+t,s.AlgUnsupported,{alg:t});if(n.length!==K(t))throw new P(`SigToDER: incorrect sig size for ${t}: ${n.length} bytes, expected ${K(t)}.`,s.SigInvalid,{field:"sig"});let r=n.length/2;return F($,qt(n.slice(0,r)),qt(n.slice(r)))}function Ve(e,t){if(typeof e=="string"&&(e=C(e)),e=new Uint8Array(e),_(t)!==p.ECDSA)throw new x("DERToSig: alg must be ECDSA: "+t,s.AlgUnsupported,{alg:t});let n,r;try{n=ue(e,0),r=_e(n)}catch(f){throw new P("DERToSig: invalid DER signature: "+f.message,s.SigInvalid,{field:"sig"})}if(n.tag!==$||n.end!==e.length||r.length!==2)throw new P("DERToSig: invalid DER signature.",s.SigInvalid,{field:"sig"});let i=K(t)/2,a=new Uint8Array(i*2);for(let f=0;f<2;f++){if(r[f].tag!==oe)throw new P("DERToSig: invalid signature integer.",s.SigInvalid,{field:"sig"});let o=r[f].content;if(o.length===0||o[0]&128)throw new P("DERToSig: signature integers must be positive.",s.SigInvalid,{field:"sig"});let c=0;for(;c<o.length-1&&o[c]===0;)c++;if(o=o.slice(c),o.length>i)throw new P("DERToSig: signature integer too large for "+t+".",s.SigInvalid,{field:"sig"});a.set(o,i*(f+1)-o.length)}return w(a)}function $e(e,t){return e.length>0&&e[0]===$&&e.length!==K(t)}function qt(e){let t=0;for(;t<e.length-1&&e[t]===0;)t++;return e=e.slice(t),e[0]&128?F(oe,[0],e):F(oe,e)}function xr(e){let t=_e(Z(ue(e,0),$,"SPKI")),n=rn(t[0]),r=Z(t[1],Oe,"SPKI public key");if(r.content[0]!==0)throw new y("PEMToCozeKey: unsupported SPKI public key padding.",s.KeyInvalid);return{alg:n,x:ln(n,r.content.slice(1))}}async function wr(e){let t=_e(Z(ue(e,0),$,"PKCS #8"));if(t.length<3)throw new y("PEMToCozeKey: invalid PKCS #8.",s.KeyInvalid);let n=rn(t[1]),r=Z(t[2],he,"PKCS #8 private key").content;if(n!==l.Ed25519)return nn(r,n);let i=Z(ue(r,0),he,"Ed25519 private key").content;if(i.length!==V(n))throw new y("PEMToCozeKey: incorrect Ed25519 private key size.",s.KeyInvalid,{field:"d"});let a=await crypto.subtle.importKey("pkcs8",e,{name:l.Ed25519},!0,["sign"]),f=await crypto.subtle.exportKey("jwk",a);return{alg:n,x:f.x,d:w(i)}}async function nn(e,t){let n=_e(Z(ue(e,0),$,"EC private key"));if(n.length<2||n[0].tag!==oe||n[0].content.length!==1||n[0].content[0]!==1)throw new y("PEMToCozeKey: unsupported EC private key version.",s.KeyInvalid);let r=null;for(let o of n.slice(2)){if(o.tag===Qt){let c=an(Z(ue(o.content,0),xe,"EC private key curve").content);if(t!==null&&t!==c)throw new x("PEMToCozeKey: EC private key curve mismatch.",s.AlgMismatch,{alg:c});t=c}o.tag===ct&&(r=Z(ue(o.content,0),Oe,"EC public key").content)}if(t===null)throw new y("PEMToCozeKey: EC private key curve not given.",s.KeyInvalid);let i=Z(n[1],he,"EC private key").content;if(i.length>V(t))throw new y("PEMToCozeKey: incorrect private key size.",s.KeyInvalid,{field:"d"});let a=new Uint8Array(V(t));a.set(i,a.length-i.length);let f={alg:t};if(r!==null){if(r[0]!==0)throw new y("PEMToCozeKey: unsupported EC public key padding.",s.KeyInvalid);f.x=ln(t,r.slice(1))}else f.x=await v.PublicFromD(t,mr(a));return f.d=w(a),f}function rn(e){let t=_e(Z(e,$,"algorithm identifier")),n=Z(t[0],xe,"algorithm").content;if(yt(n,tn))return l.Ed25519;if(!yt(n,en))throw new x("PEMToCozeKey: unsupported key algorithm.",s.AlgUnsupported);if(t.length<2)throw new y("PEMToCozeKey: EC named curve not given.",s.KeyInvalid);return an(Z(t[1],xe,"named curve").content)}function an(e){for(let t in gt)if(yt(e,gt[t]))return t;throw new x("PEMToCozeKey: unsupported named curve.",s.AlgUnsupported)}function ln(e,t){if(e!==l.Ed25519){if(t[0]!==4)throw new y("PEMToCozeKey: only uncompressed EC points are supported.",s.KeyInvalid,{field:"x"});t=t.slice(1)}if(t.length!==J(e))throw new y("PEMToCozeKey: incorrect public key size for "+e+".",s.KeyInvalid,{field:"x"});return w(t)}function br(e){let t=/-----BEGIN ([A-Z0-9 ]+)-----([\s\S]*?)-----END \1-----/g,n;for(;(n=t.exec(e))!==null;){if(n[1]==="EC PARAMETERS")continue;if(n[1]==="ENCRYPTED PRIVATE KEY"||n[2].includes("ENCRYPTED"))throw new y("PEMToCozeKey: encrypted PEM is not supported.",s.KeyInvalid,{field:"pem"});let i=n[2].replace(/\s+/g,"");try{var r=Uint8Array.from(atob(i),a=>a.charCodeAt(0))}catch{throw new y("PEMToCozeKey: invalid PEM base64.",s.KeyInvalid,{field:"pem"})}return{label:n[1],der:r}}throw new y("PEMToCozeKey: no PEM key found.",s.KeyInvalid,{field:"pem"})}function De(e,t){let r=btoa(String.fromCharCode(...t)).match(/.{1,64}/g);return`-----BEGIN ${e}-----
+${r.join(`
+`)}
+-----END ${e}-----
+`}function ue(e,t){if(t+2>e.length)throw new y("DER: unexpected end of input.",s.KeyInvalid);let n=e[t],r=e[t+1],i=t+2;if(r&128){let f=r&127;if(f===0||f>4)throw new y("DER: unsupported length.",s.KeyInvalid);r=0;for(let o=0;o<f;o++)r=r*256+e[i+o];i+=f}let a=i+r;if(a>e.length)throw new y("DER: length exceeds input.",s.KeyInvalid);return{tag:n,content:e.slice(i,a),end:a}}function _e(e){let t=[];for(let n=0;n<e.content.length;){let r=ue(e.content,n);t.push(r),n=r.end}return t}function Z(e,t,n){if(e===void 0||e.tag!==t)throw new y("DER: invalid "+n+".",s.KeyInvalid);return e}function F(e,...t){let n=[];for(let a of t)n.push(...a);let r=n.length,i=[];if(r<128)i.push(r);else{let a=[];for(;r>0;r>>=8)a.unshift(r&255);i.push(128|a.length,...a)}return new Uint8Array([e,...i,...n])}function yt(e,t){if(e.length!==t.length)return!1;for(let n=0;n<e.length;n++)if(e[n]!==t[n])return!1;return!0}function mr(e){let t=0n;for(let n of e)t=(t<<8n)+BigInt(n);return t}var Sr=["alg","iat","tmb","typ"];async function Er(e,t,n,r){if(console.log(),e=M(e),t=M(t),se(t))throw new y("SignCoze: Cannot sign with revoked key.",s.KeyRevoked);return!u(r)&&r.setStandard===!0?e.pay=await sn(e.pay,t,r):(e.pay.alg=t.alg,e.pay.tmb=await H(t),e.pay.iat=on(r)),!u(r)&&r.normalizeUnicode===!0&&(e.pay=Q(e.pay)),u(n)||(e.pay=await Ee(e.pay,n)),e.sig=await Ce(JSON.stringify(e.pay),t,r),e}async function Ce(e,t,n){let r=!0;try{JSON.parse(e)}catch{r=!1}if(r&&(ht(e),!u(n)&&n.normalizeUnicode===!0&&(e=JSON.stringify(Q(JSON.parse(e))))),At(t.alg,n),!u(n)&&n.deterministic===!0&&_(t.alg)==p.ECDSA){if(u(t.d))throw new y("SignPay: deterministic signing requires private component d.",s.KeyInvalid,{field:"d"});let i=await v.SignBuffer(await v.FromCozeKey(t),await L(e),!0);return Me(t.alg,w(i))}return k.SignBufferB64(await k.FromCozeKey(t),await L(e))}async function ut(e,t,n,r){if(e=M(e),t=M(t),se(t))throw new y("SignCozeRaw: Cannot sign with revoked key.",s.KeyRevoked);if(!u(r)&&r.setStandard===!0)e.pay=await sn(e.pay,t,r);else{if(!u(e.pay.alg)&&e.pay.alg!==t.alg)throw new x("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.pay.alg});if(!u(e.pay.tmb)&&e.pay.tmb!==t.tmb)throw new y("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"tmb"})}return!u(r)&&r.normalizeUnicode===!0&&(e.pay=Q(e.pay)),u(n)||(e.pay=await Ee(e.pay,n)),e.sig=await Ce(JSON.stringify(e.pay),t,r),e}async function sn(e,t,n){let r=await H(t);if(!u(e.alg)&&e.alg!==t.alg)throw new x("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.alg});if(!u(e.tmb)&&e.tmb!==r)throw new y("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"tmb"});let i=e.iat;if(i===void 0)i=on(n);else if(n.iat!==void 0&&n.iat!==i)throw new D(`SignCozeRaw: coze.pay.iat (${i}) mismatch with opts.iat (${n.iat}).`,s.IatInvalid,{field:"iat"});let a={alg:t.alg,iat:i,tmb:r};for(let[f,o]of Object.entries(e))f in a||(a[f]=o);return a}function on(e){if(u(e)||e.iat===void 0)return Math.round(Date.now()/1e3);if(!Number.isSafeInteger(e.iat)||e.iat<0)throw new D("Sign: opts.iat must be a non-negative integer.",s.IatInvalid,{field:"iat"});return e.iat}async function Cr(e,t,n,r){if(e=M(e),n=M(n),se(n))throw new y("SignCryptoKey: Cannot sign with revoked key.",s.KeyRevoked);if(t.type!=="private")throw new y("SignCryptoKey: CryptoKey must be private.",s.KeyInvalid);if(await k.algFromCryptoKey(t)!==n.alg)throw new x("SignCryptoKey: CryptoKey alg mismatch with cozeKey.alg.",s.AlgMismatch,{alg:n.alg});e.alg=n.alg,e.tmb=await H(n),e.iat=Math.round(Date.now()/1e3),u(r)||(e=await Ee(e,r));let i={pay:e,sig:await k.SignString(t,JSON.stringify(e))};if(!await Ae(JSON.stringify(e),n,i.sig))throw new y("SignCryptoKey: CryptoKey is not the private key of cozeKey.",s.KeyMismatch);return i}async function dt(e,t,n,r){if(typeof n=="string"){let i;return typeof e=="string"&&(i=e,e=Be(e)),fn({pay:e,sig:n},t,r,i)}return fn(e,t,n)}async function fn(e,t,n,r){e=M(e),t=M(t);let i=t!=null;if(i&&(t=await ze(t,e.pay.tmb)),!u(e.key))t=await vr(e,i?t:void 0);else if(!i)throw new y("VerifyCoze: no key given and coze has no embedded key.",s.KeyInvalid,{field:"key"});if(se(t)&&(u(n)||n.allowRevoked!==!0))throw new y("VerifyCoze: Coze key is revoked.",s.KeyRevoked);if(!u(e.pay.alg)&&e.pay.alg!==t.alg)throw new x("VerifyCoze: Coze key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:e.pay.alg});if(!u(e.pay.tmb)&&e.pay.tmb!==await H(t))throw new y("VerifyCoze: Coze key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"tmb"});At(t.alg,n),C(e.sig,"sig"),C(t.x,"x");let a=e.sig,f=e.pay;if(!u(n)&&(n.normalizeUnicode===!0&&(f=Q(f)),cn(f,n),n.acceptDER===!0&&_(t.alg)==p.ECDSA&&$e(C(a),t.alg)&&(a=Ve(a,t.alg)),await un(t.alg,a,n)))return!1;r===void 0&&(r=JSON.stringify(f));let o=await Ae(r,t,a);return o&&!u(n)&&dn(f,n),o}async function un(e,t,n){if(u(n)||n.requireLowS!==!0||_(e)!==p.ECDSA)return!1;let r=de(t);return r.byteLength===K(e)&&!await lt(e,r)}function At(e,t){if(u(t)||u(t.hash))return;let n=B(e);if(t.hash!==n)throw new x(`Coze: hash not valid for alg: ${t.hash} is not ${e}'s hash ${n}.`,s.HashInvalid,{alg:e})}async function vr(e,t){let n=e.key,r=await H(n);if(!u(e.pay.tmb)&&e.pay.tmb!==r)throw new y("VerifyCoze: coze.key tmb mismatch with coze.pay.tmb.",s.TmbMismatch,{field:"key"});if(!u(e.pay.alg)&&e.pay.alg!==n.alg)throw new x("VerifyCoze: coze.key alg mismatch with coze.pay.alg.",s.AlgMismatch,{alg:n.alg});if(t!==void 0){if(await H(t)!==r)throw new y("VerifyCoze: Coze key tmb mismatch with coze.key.",s.TmbMismatch,{field:"key"});return t}return{...n,tmb:r}}function dn(e,t){if(t.maxAge===void 0&&t.notBefore===void 0&&t.notAfter===void 0)return;let n=e.iat,r={field:"iat",verified:!0};if(!Number.isSafeInteger(n)||n<0)throw new P("VerifyCoze: pay.iat must be a non-negative integer when time options are set.",s.IatInvalid,r);let i=t.clockSkew===void 0?60:t.clockSkew,a=Date.now()/1e3;if(t.maxAge!==void 0){if(n+t.maxAge+i<a)throw new P(`VerifyCoze: coze expired: iat ${n} is older than maxAge ${t.maxAge}.`,s.Expired,r);if(n-i>a)throw new P(`VerifyCoze: coze not yet valid: iat ${n} is in the future.`,s.NotYetValid,r)}if(t.notBefore!==void 0&&n+i<t.notBefore)throw new P(`VerifyCoze: coze not yet valid: iat ${n} is before notBefore ${t.notBefore}.`,s.NotYetValid,r);if(t.notAfter!==void 0&&n-i>t.notAfter)throw new P(`VerifyCoze: coze expired: iat ${n} is after notAfter ${t.notAfter}.`,s.Expired,r)}function cn(e,t){let n=Object.keys(e),r=[];if(Array.isArray(t.canon)){r=t.canon.flatMap(f=>typeof f=="string"?[f]:Object.keys(f));let a=n.filter(f=>!r.includes(f));if(a.length>0)throw new q("VerifyCoze: pay has extra field(s) not in canon: "+a.join(", "),s.CanonExtra,{fields:a})}u(t.canonContains)||(r=r.concat(t.canonContains));let i=r.filter(a=>!n.includes(a));if(i.length>0)throw i=[...new Set(i)],new q("VerifyCoze: pay missing field(s) required by canon: "+i.join(", "),s.CanonMissing,{fields:i})}async function Fr(e,t,n){let r={verified:!1,meta:null,checks:[]},i={},a=function(h,S,N){let j={name:h,status:S};u(N)||(j.message=N),r.checks.push(j),i[h]=S},f=(...h)=>h.every(S=>i[S]==="pass"),o=function(h,...S){a(h,"skip","Requires passing "+S.filter(N=>i[N]!=="pass").join(", ")+".")};u(n)&&(n={});try{e=M(e),e===null||typeof e!="object"||e.pay===null||typeof e.pay!="object"||Array.isArray(e.pay)?a("pay_parsed","fail","coze.pay must be an object."):a("pay_parsed","pass")}catch(h){a("pay_parsed","fail",h.message)}let c,d=!1;if(!f("pay_parsed"))o("key_found","pay_parsed");else try{t=M(t),t!=null&&t!==""?c=await ze(t,e.pay.tmb):u(e.key)||(c=e.key,d=!0),u(c)?a("key_found","fail","No key given and coze has no embedded key."):a("key_found","pass",d?"Embedded key.":"")}catch(h){a("key_found","fail",h.message)}if(!f("pay_parsed"))o("meta","pay_parsed");else try{r.meta=await Le(e,u(e.pay.alg)&&!u(c)?c.alg:void 0),a("meta","pass")}catch(h){a("meta","fail",h.message)}if(f("key_found")?u(e.pay.alg)?a("alg_matches","skip","pay has no alg."):e.pay.alg!==c.alg?a("alg_matches","fail",`pay.alg "${e.pay.alg}" is not the key's alg "${c.alg}".`):a("alg_matches","pass"):o("alg_matches","pay_parsed","key_found"),!f("key_found"))o("tmb_matches","pay_parsed","key_found");else try{let h=await H(c),S=[];!u(e.pay.tmb)&&e.pay.tmb!==h&&S.push(`pay.tmb "${e.pay.tmb}" is not the key's thumbprint "${h}".`),!d&&!u(e.key)&&await H(e.key)!==h&&S.push("Given key is not the embedded key."),S.length>0?a("tmb_matches","fail",S.join("  ")):a("tmb_matches","pass",u(e.pay.tmb)?"pay has no tmb.":"")}catch(h){a("tmb_matches","fail",h.message)}if(f("key_found")?se(c)?n.allowRevoked===!0?a("not_revoked","pass","Key is revoked, allowed by opts.allowRevoked."):a("not_revoked","fail","Key is revoked."):a("not_revoked","pass"):o("not_revoked","pay_parsed","key_found"),u(n.hash))a("hash","skip","opts.hash not given.");else if(!f("key_found"))o("hash","pay_parsed","key_found");else try{At(c.alg,n),a("hash","pass")}catch(h){a("hash","fail",h.message)}let g;if(!f("pay_parsed"))o("sig_b64ut","pay_parsed");else if(u(e.sig))a("sig_b64ut","fail","coze has no sig.");else try{C(e.sig,"sig"),g=e.sig,a("sig_b64ut","pass")}catch(h){a("sig_b64ut","fail",h.message)}let A=!u(c)&&!u(c.alg)?c.alg:f("pay_parsed")?e.pay.alg:void 0;if(!f("sig_b64ut"))o("sig_size","sig_b64ut");else try{n.acceptDER===!0&&_(A)==p.ECDSA&&$e(C(g),A)&&(g=Ve(g,A));let h=C(g).length;h!==K(A)?a("sig_size","fail",`sig is ${h} bytes, ${A} requires ${K(A)}.`):a("sig_size","pass")}catch(h){a("sig_size","fail",h.message)}let m=f("pay_parsed")?e.pay:void 0;if(!f("key_found","sig_size")||i.alg_matches==="fail")o("signature",...i.alg_matches==="fail"?["key_found","alg_matches","sig_size"]:["key_found","sig_size"]);else try{n.normalizeUnicode===!0&&(m=Q(m)),await un(c.alg,g,n)?a("signature","fail","High-S signature refused by opts.requireLowS."):await Ae(JSON.stringify(m),c,g)?a("signature","pass"):a("signature","fail","Signature did not verify.")}catch(h){a("signature","fail",h.message)}if(u(n.canon)&&u(n.canonContains))a("canon","skip","opts.canon and opts.canonContains not given.");else if(!f("pay_parsed"))o("canon","pay_parsed");else try{cn(m,n),a("canon","pass")}catch(h){a("canon","fail",h.message)}if(n.maxAge===void 0&&n.notBefore===void 0&&n.notAfter===void 0)a("iat_window","skip","Time options not given.");else if(!f("pay_parsed"))o("iat_window","pay_parsed");else try{dn(m,n),a("iat_window","pass")}catch(h){a("iat_window","fail",h.message)}return r.verified=f("signature")&&r.checks.every(h=>h.status!=="fail"),r}async function Ae(e,t,n){return k.VerifyMsg(t.alg,await k.FromCozeKey(t,!0),e,n)}async function Ir(e,t,n){if(se(t))throw new y("SignDig: Cannot sign with revoked key.",s.KeyRevoked);let r=gn("SignDig",e,t,n),i=await v.SignDigest(await v.FromCozeKey(t),r);return Me(e,w(i))}async function Tr(e,t,n,r){let i=gn("VerifyDig",e,t,n),a=de(r);return a.byteLength!==K(e)?!1:v.VerifyDigest(await v.FromCozeKey(t,!0),i,a)}function gn(e,t,n,r){if(t!==n.alg)throw new x(`${e}: alg (${t}) mismatch with cozeKey.alg (${n.alg}).`,s.AlgMismatch,{alg:t});if(_(t)!==p.ECDSA)throw new x(`${e}: only ECDSA algs are supported.`,s.AlgUnsupported,{alg:t});if(r instanceof Uint8Array||(r.replace(/^0x/i,"").length===X(t)*2?r=Ue(r):r=C(r)),r.length!==X(t))throw new D(`${e}: incorrect digest size for ${t}: ${r.length} bytes, expected ${X(t)}.`,s.DigSize,{alg:t});return r}async function Le(e,t){if(e=M(e),u(e.pay))throw new P("Meta: coze.pay must exist.",s.PayMissing,{field:"pay"});let n={},r=t,i="";if(!u(t)&&typeof t=="object"&&(r=t.alg,i=t.tmb),u(e.pay.alg))u(r)||(n.alg=r);else{if(!u(r)&&r!==e.pay.alg)throw new x(`Meta: alg mismatch: coze.pay.alg (${e.pay.alg}) and parameter alg (${r}) do not match.`,s.AlgMismatch,{alg:r});n.alg=e.pay.alg}if(u(e.pay.iat)||(n.iat=e.pay.iat),u(e.pay.tmb)?u(i)||(n.tmb=i):n.tmb=e.pay.tmb,u(e.pay.typ)||(n.typ=e.pay.typ),n.can=await et(e.pay),u(n.alg)||(n.cad=await ce(e.pay,B(n.alg))),u(e.sig)||(n.sig=e.sig),!u(n.alg)&&!u(e.sig)&&(n.czd=await ce({cad:n.cad,sig:n.sig},B(n.alg))),!u(n.alg)&&Array.isArray(e.sigs)){n.sigs=[];for(let a of e.sigs)n.sigs.push({tmb:a.tmb,sig:a.sig,czd:await ce({cad:n.cad,sig:a.sig},B(n.alg))})}return n}function yn(e){e=M(e);let t={...e};return u(e.key)||(t.key=ot(e.key)),!u(e.coze)&&typeof e.coze=="object"&&(t.coze=yn(e.coze)),t}function kr(e,t){if(typeof e=="string"){let n=e;if(e=Be(n),JSON.stringify(e)!==n)throw new D("Attach: pay is not the compact serialization of pay, so sig is not of the coze's pay.  Verify with Verify(pay, cozeKey, sig) instead.",s.JSONInvalid,{field:"pay"})}return{pay:e,sig:t}}function Hr(e){if(e=M(e),u(e.pay))throw new P("Detach: coze.pay must exist.",s.PayMissing,{field:"pay"});return{payCompact:JSON.stringify(e.pay),sig:e.sig}}async function An(e,t){if(e=M(e),t=M(t),u(e)||u(e.pay)||u(t)||u(t.pay))throw new P("Equal: coze.pay must exist.",s.PayMissing,{field:"pay"});if(u(e.sig)!==u(t.sig))return!1;if(u(e.pay.alg)||e.pay.alg!==t.pay.alg)return e.sig===t.sig&&await He(e.pay)===await He(t.pay);let n=await Le(e),r=await Le(t);return u(e.sig)?n.cad===r.cad:n.czd===r.czd}async function Dr(e,t){return e=M(e),t=M(t),Object.keys(e).sort().join()!==Object.keys(t).sort().join()||!u(e.key)&&await H(e.key)!==await H(t.key)?!1:An(e,t)}function Be(e){let t=JSON.parse(e);return ht(e),t}function ht(e){let t=[];for(let n=0;n<e.length;n++){let r=t[t.length-1];switch(e[n]){case"{":t.push({names:new Set,expectName:!0});break;case"[":t.push(null);break;case"}":case"]":t.pop();break;case",":r&&(r.expectName=!0);break;case'"':{let i=n;for(n++;e[n]!=='"';n++)e[n]==="\\"&&n++;if(r&&r.expectName){let a=JSON.parse(e.slice(i,n+1));if(r.names.has(a))throw new D(`Coze: duplicate JSON field "${a}"`,s.DuplicateField,{field:a});r.names.add(a),r.expectName=!1}break}}}}var te={Coze:"coze",Key:"key",Pay:"pay",Array:"array",Unknown:"unknown"};function _r(e){if(typeof e=="string")try{e=Be(e)}catch(n){throw n instanceof SyntaxError?Br(e,n):n}if(Array.isArray(e))return te.Array;if(typeof e!="object"||e===null)return te.Unknown;let t=n=>typeof n=="object"&&n!==null&&!Array.isArray(n);return t(e.coze)&&t(e.coze.pay)&&typeof e.coze.sig=="string"?te.Coze:t(e.pay)?typeof e.sig=="string"?te.Coze:te.Pay:e.sig!==void 0?te.Unknown:typeof e.alg=="string"&&(typeof e.x=="string"||typeof e.d=="string")?te.Key:te.Pay}function Br(e,t){let n=(a,f)=>new D(`Detect: ${a} at position ${f}.`,s.JSONInvalid,{position:f});if(e.charCodeAt(0)===65279)return n("byte order mark (BOM)",0);let r=!1;for(let a=0;a<e.length;a++){let f=e[a];if(r){f==="\\"?a++:f==='"'&&(r=!1);continue}if(f==='"')r=!0;else if(f===","){let o=e.slice(a+1).search(/[^ \t\n\r]/);if(o!==-1&&(e[a+1+o]==="}"||e[a+1+o]==="]"))return n("trailing comma",a)}else if(/[\s\u200B-\u200D\u2060]/.test(f)&&!/[ \t\n\r]/.test(f)){let o=f.charCodeAt(0).toString(16).toUpperCase().padStart(4,"0");return n(`non JSON whitespace (U+${o})`,a)}}let i=/position (\d+)/.exec(t.message);return i!==null?n("invalid JSON",Number(i[1])):new D("Detect: invalid JSON: "+t.message,s.JSONInvalid)}function M(e){return typeof e=="string"?Be(e):e}return In(Kr);})();
+//# sourceMappingURL=coze.iife.min.js.map
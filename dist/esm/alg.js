@@ -0,0 +1 @@
+import{CozeAlgError as s,ErrCodes as t}from"./error.js";const e={UnknownAlg:"UnknownAlg",ES224:"ES224",ES256:"ES256",ES384:"ES384",ES512:"ES512",Ed25519:"Ed25519",Ed25519ph:"Ed25519ph",Ed448:"Ed448",SHA224:"SHA-224",SHA256:"SHA-256",SHA384:"SHA-384",SHA512:"SHA-512",SHA3224:"SHA3-224",SHA3256:"SHA3-256",SHA3384:"SHA3-384",SHA3512:"SHA3-512",SHAKE128:"SHAKE128",SHAKE256:"SHAKE256"},u={EC:"EC",SHA:"SHA",RSA:"RSA"},F={ECDSA:"ECDSA",EdDSA:"EdDSA",SHA2:"SHA2",SHA3:"SHA3"},c={P224:"P-224",P256:"P-256",P384:"P-384",P521:"P-521",Curve25519:"Curve25519",Curve448:"Curve448"},a={Sig:"sig",Enc:"enc",Hsh:"hsh"};function B(r){let S={};S.Name=r,S.Genus=n(r),S.Family=H(r),S.Use=g(r),S.Hash=A(r),S.HashSize=i(r),S.HashSizeB64=Math.ceil(4*S.HashSize/3);try{S.XSize=h(r),S.XSizeB64=Math.ceil(4*S.XSize/3),S.DSize=l(r),S.DSizeB64=Math.ceil(4*S.DSize/3),S.Curve=d(r),S.SigSize=p(r),S.SigSizeB64=Math.ceil(4*S.SigSize/3),S.CurveOID=C(r),S.JOSECrv=f(r)}catch{}return S.JOSEAlg=w(r),S.COSEAlg=D(r),S}function n(r){switch(r){case e.ES224:case e.ES256:case e.ES384:case e.ES512:return F.ECDSA;case e.Ed25519:case e.Ed25519ph:case e.Ed448:return F.EdDSA;case e.SHA224:case e.SHA256:case e.SHA384:case e.SHA512:return F.SHA2;case e.SHA3224:case e.SHA3256:case e.SHA3384:case e.SHA3512:case e.SHAKE128:case e.SHAKE256:return F.SHA3;default:throw new s("alg.Genus: unsupported algorithm: "+r,t.AlgUnsupported,{alg:r})}}function H(r){switch(r){case e.ES224:case e.ES256:case e.ES384:case e.ES512:case e.Ed25519:case e.Ed25519ph:case e.Ed448:return u.EC;case e.SHA224:case e.SHA256:case e.SHA384:case e.SHA512:case e.SHA3224:case e.SHA3256:case e.SHA3384:case e.SHA3512:case e.SHAKE128:case e.SHAKE256:return u.SHA;default:throw new s("alg.Family:  unsupported algorithm: "+r,t.AlgUnsupported,{alg:r})}}function A(r){switch(r){case e.ES224:case e.SHA224:return e.SHA224;case e.SHA256:case e.ES256:return e.SHA256;case e.SHA384:case e.ES384:return e.SHA384;case e.SHA512:case e.ES512:case e.Ed25519:case e.Ed25519ph:return e.SHA512;case e.SHAKE128:return e.SHAKE128;case e.SHAKE256:case e.Ed448:return e.SHAKE256;case e.SHA3224:return e.SHA3224;case e.SHA3256:return e.SHA3256;case e.SHA3384:return e.SHA3384;case e.SHA3512:return e.SHA3512;default:throw new s("alg.HashAlg:  unsupported algorithm: "+r,t.AlgUnsupported,{alg:r})}}function i(r){switch(A(r)){case e.SHA224:case e.SHA3224:return 28;case e.SHA256:case e.SHA3256:case e.SHAKE128:return 32;case e.SHA384:case e.SHA3384:return 48;case e.SHA512:case e.SHA3512:case e.SHAKE256:return 64;default:throw new s("alg.HashSize: unsupported algorithm: "+r,t.AlgUnsupported,{alg:r})}}function p(r){switch(r){case e.ES224:return 56;case e.ES256:case e.Ed25519:case e.Ed25519ph:return 64;case e.ES384:return 96;case e.Ed448:return 114;case e.ES512:return 132;default:throw new s("alg.SigSize: unsupported algorithm: "+r,t.AlgUnsupported,{alg:r})}}function h(r){switch(r){case e.Ed25519:case e.Ed25519ph:return 32;case e.ES224:return 56;case e.Ed448:return 57;case e.ES256:return 64;case e.ES384:return 96;case e.ES512:return 132;default:throw new s("alg.XSize: unsupported algorithm: "+r,t.AlgUnsupported,{alg:r})}}function l(r){switch(r){case e.ES224:return 28;case e.ES256:case e.Ed25519:case e.Ed25519ph:return 32;case e.ES384:return 48;case e.Ed448:return 57;case e.ES512:return 66;default:throw new s("alg.DSize: unsupported algorithm: "+r,t.AlgUnsupported,{alg:r})}}function d(r){switch(r){default:throw new s("alg.Curve: unsupported algorithm: "+r,t.AlgUnsupported,{alg:r});case e.ES224:return c.P224;case e.ES256:return c.P256;case e.ES384:return c.P384;case e.ES512:return c.P521;case e.Ed25519:case e.Ed25519ph:return c.Curve25519;case e.Ed448:return c.Curve448}}function g(r){switch(n(r)){default:throw new s("alg.Use: unsupported algorithm: "+r,t.AlgUnsupported,{alg:r});case F.EdDSA:case F.ECDSA:return a.Sig;case F.SHA2:case F.SHA3:return a.Hsh}}const E={ES224:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFF16A2E0B8F03E13DD29455C5C2A3D"),ES256:BigInt("0xFFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551"),ES384:BigInt("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFC7634D81F4372DDF581A0DB248B0A77AECEC196ACCC52973"),ES512:BigInt("0x1FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFA51868783BF2F966B7FCC0148F709A5D03BB5C9B8899C47AEBB6FB71E91386409")},O={ES224:E.ES224>>BigInt(1),ES256:E.ES256>>BigInt(1),ES384:E.ES384>>BigInt(1),ES512:E.ES512>>BigInt(1)};function U(r){switch(r){default:throw new s("CurveOrder: unsupported curve: "+r,t.AlgUnsupported,{alg:r});case"ES224":case"ES256":case"ES384":case"ES512":return E[r]}}function z(r){switch(r){default:throw new s("CurveHalfOrder: unsupported curve: "+r,t.AlgUnsupported,{alg:r});case"ES224":case"ES256":case"ES384":case"ES512":return O[r]}}function C(r){switch(r){default:throw new s("alg.CurveOID: unsupported algorithm: "+r,t.AlgUnsupported,{alg:r});case e.ES224:return"1.3.132.0.33";case e.ES256:return"1.2.840.10045.3.1.7";case e.ES384:return"1.3.132.0.34";case e.ES512:return"1.3.132.0.35";case e.Ed25519:case e.Ed25519ph:return"1.3.101.112";case e.Ed448:return"1.3.101.113"}}function w(r){switch(r){case e.ES256:case e.ES384:case e.ES512:return r;case e.Ed25519:case e.Ed448:return"EdDSA"}return n(r),""}function f(r){switch(r){default:throw new s("alg.JOSECrv: unsupported algorithm: "+r,t.AlgUnsupported,{alg:r});case e.ES224:return"";case e.ES256:case e.ES384:case e.ES512:return d(r);case e.Ed25519:case e.Ed25519ph:return"Ed25519";case e.Ed448:return"Ed448"}}const o={ES256:-7,ES384:-35,ES512:-36,Ed25519:-19,Ed448:-53,"SHA-256":-16,"SHA-384":-43,"SHA-512":-44,SHAKE128:-18,SHAKE256:-45};function D(r){n(r);let S=o[r];return S===void 0?0:S}function m(r,S){switch(r){case"ES256":case"ES384":case"ES512":case"Ed25519":case"Ed448":return r;case"EdDSA":if(S==="Ed25519"||S==="Ed448")return S;throw new s("alg.AlgFromJOSE: EdDSA requires crv Ed25519 or Ed448, got: "+S,t.AlgUnsupported,{alg:r});default:throw new s("alg.AlgFromJOSE: unsupported JOSE alg: "+r,t.AlgUnsupported,{alg:r})}}function v(r){for(const S in o)if(o[S]===r)return S;throw new s("alg.AlgFromCOSE: unsupported COSE alg: "+r,t.AlgUnsupported,{alg:r})}export{v as AlgFromCOSE,m as AlgFromJOSE,e as Algs,D as COSEAlg,d as Curve,z as CurveHalfOrder,C as CurveOID,U as CurveOrder,c as Curves,l as DSize,u as FamAlgs,H as Family,F as GenAlgs,n as Genus,A as HashAlg,i as HashSize,w as JOSEAlg,f as JOSECrv,B as Params,p as SigSize,g as Use,a as Uses,h as XSize};
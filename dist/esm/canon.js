@@ -0,0 +1 @@
+import{isEmpty as i,SToArrayBuffer as w,ArrayBufferTo64ut as C}from"./conversion.js";import{Digest as l}from"./hash.js";import{CozeCanonError as s,CozeAlgError as p,ErrCodes as f}from"./error.js";function A(n){return Object.keys(n)}async function y(n,e){return i(e)?n:c(n,e)}function c(n,e){if(Array.isArray(n))return n.map(r=>c(r,e));if(n===null||typeof n!="object")return n;let o={};for(const[r,t]of b(e))t===null||n[r]===void 0?o[r]=n[r]:o[r]=c(n[r],t);return o}function b(n){let e=[];if(Array.isArray(n))for(const r of n)if(typeof r=="string")e.push([r,null]);else if(r!==null&&typeof r=="object"&&!Array.isArray(r))for(const[t,m]of Object.entries(r))e.push([t,m]);else throw new s("Canonical: invalid canon element: "+JSON.stringify(r),f.CanonInvalid);else if(n!==null&&typeof n=="object")for(const[r,t]of Object.entries(n))e.push([r,t!==null&&typeof t=="object"?t:null]);else throw new s("Canonical: canon must be an array or object.",f.CanonInvalid);if(new Set(e.map(r=>r[0])).size!==e.length)throw new s("Canonical: Canon cannot have duplicate fields.",f.CanonInvalid);return e}async function u(n,e,o){return!i(o)&&o.normalizeUnicode===!0&&(n=a(n)),JSON.stringify(await y(n,e))}function a(n){if(typeof n=="string")return n.normalize("NFC");if(Array.isArray(n))return n.map(a);if(n!==null&&typeof n=="object"){let e={};for(const o of Object.keys(n))e[o]=a(n[o]);return e}return n}async function d(n,e,o,r){if(i(e))throw new p("Hash is not given",f.AlgUnsupported);if(n instanceof Uint8Array||n instanceof ArrayBuffer){if(i(o)&&i(r))return await l(e,n);n=JSON.parse(new TextDecoder().decode(n))}return await l(e,await w(await u(n,o,r)))}async function g(n,e,o,r){return await C(await d(n,e,o,r))}export{A as Canon,y as Canonical,d as CanonicalHash,g as CanonicalHash64,u as CanonicalS,a as NormalizeUnicode};
@@ -0,0 +1 @@
+import{CozeError as u,ErrCodes as i}from"./error.js";async function p(r){return new TextEncoder().encode(r).buffer}class n extends u{constructor(e,t){super(e,i.B64Invalid,{field:t}),this.name="B64Error"}}function d(r,e){return f(r,e).buffer}function f(r,e){let t=l(e)?"":` for field "${e}"`;if(typeof r!="string")throw new n(`B64ToUint8Array: b64ut must be a string${t}.`,e);if(!/^[A-Za-z0-9_-]*$/.test(r)||r.length%4===1)throw new n(`B64ToUint8Array: invalid b64ut${t}.`,e);let y=atob(r.replace(/-/g,"+").replace(/_/g,"/")),o=Uint8Array.from(y,A=>A.charCodeAt(0));if(a(o)!==r)throw new n(`B64ToUint8Array: non-canonical b64ut${t}.`,e);return o}function w(r){let e=r.replace(/\s/g,"").replace(/=+$/,"").replace(/-/g,"+").replace(/_/g,"/");if(!/^[A-Za-z0-9+/]*$/.test(e)||e.length%4===1)throw new n("B64Lenient: invalid base64.");return a(Uint8Array.from(atob(e),t=>t.charCodeAt(0)))}function a(r){return btoa(String.fromCharCode.apply(null,new Uint8Array(r))).replace(/\+/g,"-").replace(/\//g,"_").replace(/=/g,"")}function c(r){if(r=r.replace(/^0x/i,""),r.length%2!==0)throw new u("HexToUint8Array: hex must have an even number of characters, got "+r.length+".",i.HexInvalid);if(!/^[0-9a-fA-F]*$/.test(r))throw new u("HexToUint8Array: invalid hex character.",i.HexInvalid);let e=new Uint8Array(r.length/2);for(let t=0;t<e.length;t++)e[t]=parseInt(r.substring(t*2,t*2+2),16);return e}function s(r){return Array.from(new Uint8Array(r),e=>e.toString(16).padStart(2,"0")).join("")}function B(r){return a(c(r))}function T(r){return s(f(r))}function l(r){return typeof r=="function"?!1:Array.isArray(r)&&r.length==0?!0:r===Object(r)?Object.keys(r).length===0:!U(r)}function U(r){return!(r===!1||r==="false"||r===void 0||r==="undefined"||r===""||r===0||r==="0"||r===null||r==="null"||r==="NaN"||Number.isNaN(r)||r===Object(r))}export{a as ArrayBufferTo64ut,n as B64Error,w as B64Lenient,f as B64ToUint8Array,d as B64uToArrayBuffer,T as B64utToHex,B as HexToB64ut,c as HexToUint8Array,p as SToArrayBuffer,s as Uint8ArrayToHex,l as isEmpty};
@@ -0,0 +1 @@
+import*as h from"./canon.js";import*as d from"./alg.js";import*as u from"./key.js";import*as b from"./cryptokey.js";import{ECDSA as N}from"./ecdsa.js";import*as $ from"./der.js";import{SToArrayBuffer as M,B64uToArrayBuffer as V,B64ToUint8Array as C,ArrayBufferTo64ut as I,B64Lenient as K,B64Error as z,HexToUint8Array as F,Uint8ArrayToHex as ee,HexToB64ut as ie,B64utToHex as ae,isEmpty as r}from"./conversion.js";import{CozeError as v,CozeKeyError as w,CozeVerifyError as c,CozeCanonError as G,CozeAlgError as k,ErrCodes as l}from"./error.js";const te=["alg","iat","tmb","typ"];async function ne(e,i,a,t){if(console.log(),e=o(e),i=o(i),u.IsRevoked(i))throw new w("SignCoze: Cannot sign with revoked key.",l.KeyRevoked);return!r(t)&&t.setStandard===!0?e.pay=await j(e.pay,i,t):(e.pay.alg=i.alg,e.pay.tmb=await u.Thumbprint(i),e.pay.iat=q(t)),!r(t)&&t.normalizeUnicode===!0&&(e.pay=h.NormalizeUnicode(e.pay)),r(a)||(e.pay=await h.Canonical(e.pay,a)),e.sig=await J(JSON.stringify(e.pay),i,t),e}async function J(e,i,a){let t=!0;try{JSON.parse(e)}catch{t=!1}if(t&&(H(e),!r(a)&&a.normalizeUnicode===!0&&(e=JSON.stringify(h.NormalizeUnicode(JSON.parse(e))))),U(i.alg,a),!r(a)&&a.deterministic===!0&&d.Genus(i.alg)==d.GenAlgs.ECDSA){if(r(i.d))throw new w("SignPay: deterministic signing requires private component d.",l.KeyInvalid,{field:"d"});let s=await N.SignBuffer(await N.FromCozeKey(i),await M(e),!0);return b.SigToLowS(i.alg,I(s))}return b.CryptoKey.SignBufferB64(await b.CryptoKey.FromCozeKey(i),await M(e))}async function re(e,i,a,t){if(e=o(e),i=o(i),u.IsRevoked(i))throw new w("SignCozeRaw: Cannot sign with revoked key.",l.KeyRevoked);if(!r(t)&&t.setStandard===!0)e.pay=await j(e.pay,i,t);else{if(!r(e.pay.alg)&&e.pay.alg!==i.alg)throw new k("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.",l.AlgMismatch,{alg:e.pay.alg});if(!r(e.pay.tmb)&&e.pay.tmb!==i.tmb)throw new w("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.",l.TmbMismatch,{field:"tmb"})}return!r(t)&&t.normalizeUnicode===!0&&(e.pay=h.NormalizeUnicode(e.pay)),r(a)||(e.pay=await h.Canonical(e.pay,a)),e.sig=await J(JSON.stringify(e.pay),i,t),e}async function j(e,i,a){let t=await u.Thumbprint(i);if(!r(e.alg)&&e.alg!==i.alg)throw new k("SignCozeRaw: Coze key alg mismatch with coze.pay.alg.",l.AlgMismatch,{alg:e.alg});if(!r(e.tmb)&&e.tmb!==t)throw new w("SignCozeRaw: Coze key tmb mismatch with coze.pay.tmb.",l.TmbMismatch,{field:"tmb"});let s=e.iat;if(s===void 0)s=q(a);else if(a.iat!==void 0&&a.iat!==s)throw new v(`SignCozeRaw: coze.pay.iat (${s}) mismatch with opts.iat (${a.iat}).`,l.IatInvalid,{field:"iat"});let n={alg:i.alg,iat:s,tmb:t};for(const[f,y]of Object.entries(e))f in n||(n[f]=y);return n}function q(e){if(r(e)||e.iat===void 0)return Math.round(Date.now()/1e3);if(!Number.isSafeInteger(e.iat)||e.iat<0)throw new v("Sign: opts.iat must be a non-negative integer.",l.IatInvalid,{field:"iat"});return e.iat}async function se(e,i,a,t){if(e=o(e),a=o(a),u.IsRevoked(a))throw new w("SignCryptoKey: Cannot sign with revoked key.",l.KeyRevoked);if(i.type!=="private")throw new w("SignCryptoKey: CryptoKey must be private.",l.KeyInvalid);if(await b.CryptoKey.algFromCryptoKey(i)!==a.alg)throw new k("SignCryptoKey: CryptoKey alg mismatch with cozeKey.alg.",l.AlgMismatch,{alg:a.alg});e.alg=a.alg,e.tmb=await u.Thumbprint(a),e.iat=Math.round(Date.now()/1e3),r(t)||(e=await h.Canonical(e,t));let s={pay:e,sig:await b.CryptoKey.SignString(i,JSON.stringify(e))};if(!await T(JSON.stringify(e),a,s.sig))throw new w("SignCryptoKey: CryptoKey is not the private key of cozeKey.",l.KeyMismatch);return s}async function fe(e,i,a,t){if(typeof a=="string"){let s;return typeof e=="string"&&(s=e,e=D(e)),L({pay:e,sig:a},i,t,s)}return L(e,i,a)}async function L(e,i,a,t){e=o(e),i=o(i);let s=i!=null;if(s&&(i=await u.LookupKey(i,e.pay.tmb)),!r(e.key))i=await le(e,s?i:void 0);else if(!s)throw new w("VerifyCoze: no key given and coze has no embedded key.",l.KeyInvalid,{field:"key"});if(u.IsRevoked(i)&&(r(a)||a.allowRevoked!==!0))throw new w("VerifyCoze: Coze key is revoked.",l.KeyRevoked);if(!r(e.pay.alg)&&e.pay.alg!==i.alg)throw new k("VerifyCoze: Coze key alg mismatch with coze.pay.alg.",l.AlgMismatch,{alg:e.pay.alg});if(!r(e.pay.tmb)&&e.pay.tmb!==await u.Thumbprint(i))throw new w("VerifyCoze: Coze key tmb mismatch with coze.pay.tmb.",l.TmbMismatch,{field:"tmb"});U(i.alg,a),C(e.sig,"sig"),C(i.x,"x");let n=e.sig,f=e.pay;if(!r(a)&&(a.normalizeUnicode===!0&&(f=h.NormalizeUnicode(f)),Z(f,a),a.acceptDER===!0&&d.Genus(i.alg)==d.GenAlgs.ECDSA&&$.IsDERSig(C(n),i.alg)&&(n=$.DERToSig(n,i.alg)),await B(i.alg,n,a)))return!1;t===void 0&&(t=JSON.stringify(f));let y=await T(t,i,n);return y&&!r(a)&&Y(f,a),y}async function B(e,i,a){if(r(a)||a.requireLowS!==!0||d.Genus(e)!==d.GenAlgs.ECDSA)return!1;let t=V(i);return t.byteLength===d.SigSize(e)&&!await b.IsSigLowS(e,t)}function U(e,i){if(r(i)||r(i.hash))return;let a=d.HashAlg(e);if(i.hash!==a)throw new k(`Coze: hash not valid for alg: ${i.hash} is not ${e}'s hash ${a}.`,l.HashInvalid,{alg:e})}async function le(e,i){let a=e.key,t=await u.Thumbprint(a);if(!r(e.pay.tmb)&&e.pay.tmb!==t)throw new w("VerifyCoze: coze.key tmb mismatch with coze.pay.tmb.",l.TmbMismatch,{field:"key"});if(!r(e.pay.alg)&&e.pay.alg!==a.alg)throw new k("VerifyCoze: coze.key alg mismatch with coze.pay.alg.",l.AlgMismatch,{alg:a.alg});if(i!==void 0){if(await u.Thumbprint(i)!==t)throw new w("VerifyCoze: Coze key tmb mismatch with coze.key.",l.TmbMismatch,{field:"key"});return i}return{...a,tmb:t}}function Y(e,i){if(i.maxAge===void 0&&i.notBefore===void 0&&i.notAfter===void 0)return;let a=e.iat,t={field:"iat",verified:!0};if(!Number.isSafeInteger(a)||a<0)throw new c("VerifyCoze: pay.iat must be a non-negative integer when time options are set.",l.IatInvalid,t);let s=i.clockSkew===void 0?60:i.clockSkew,n=Date.now()/1e3;if(i.maxAge!==void 0){if(a+i.maxAge+s<n)throw new c(`VerifyCoze: coze expired: iat ${a} is older than maxAge ${i.maxAge}.`,l.Expired,t);if(a-s>n)throw new c(`VerifyCoze: coze not yet valid: iat ${a} is in the future.`,l.NotYetValid,t)}if(i.notBefore!==void 0&&a+s<i.notBefore)throw new c(`VerifyCoze: coze not yet valid: iat ${a} is before notBefore ${i.notBefore}.`,l.NotYetValid,t);if(i.notAfter!==void 0&&a-s>i.notAfter)throw new c(`VerifyCoze: coze expired: iat ${a} is after notAfter ${i.notAfter}.`,l.Expired,t)}function Z(e,i){let a=Object.keys(e),t=[];if(Array.isArray(i.canon)){t=i.canon.flatMap(f=>typeof f=="string"?[f]:Object.keys(f));let n=a.filter(f=>!t.includes(f));if(n.length>0)throw new G("VerifyCoze: pay has extra field(s) not in canon: "+n.join(", "),l.CanonExtra,{fields:n})}r(i.canonContains)||(t=t.concat(i.canonContains));let s=t.filter(n=>!a.includes(n));if(s.length>0)throw s=[...new Set(s)],new G("VerifyCoze: pay missing field(s) required by canon: "+s.join(", "),l.CanonMissing,{fields:s})}async function ge(e,i,a){let t={verified:!1,meta:null,checks:[]},s={},n=function(g,p,E){let P={name:g,status:p};r(E)||(P.message=E),t.checks.push(P),s[g]=p},f=(...g)=>g.every(p=>s[p]==="pass"),y=function(g,...p){n(g,"skip","Requires passing "+p.filter(E=>s[E]!=="pass").join(", ")+".")};r(a)&&(a={});try{e=o(e),e===null||typeof e!="object"||e.pay===null||typeof e.pay!="object"||Array.isArray(e.pay)?n("pay_parsed","fail","coze.pay must be an object."):n("pay_parsed","pass")}catch(g){n("pay_parsed","fail",g.message)}let m,R=!1;if(!f("pay_parsed"))y("key_found","pay_parsed");else try{i=o(i),i!=null&&i!==""?m=await u.LookupKey(i,e.pay.tmb):r(e.key)||(m=e.key,R=!0),r(m)?n("key_found","fail","No key given and coze has no embedded key."):n("key_found","pass",R?"Embedded key.":"")}catch(g){n("key_found","fail",g.message)}if(!f("pay_parsed"))y("meta","pay_parsed");else try{t.meta=await O(e,r(e.pay.alg)&&!r(m)?m.alg:void 0),n("meta","pass")}catch(g){n("meta","fail",g.message)}if(f("key_found")?r(e.pay.alg)?n("alg_matches","skip","pay has no alg."):e.pay.alg!==m.alg?n("alg_matches","fail",`pay.alg "${e.pay.alg}" is not the key's alg "${m.alg}".`):n("alg_matches","pass"):y("alg_matches","pay_parsed","key_found"),!f("key_found"))y("tmb_matches","pay_parsed","key_found");else try{let g=await u.Thumbprint(m),p=[];!r(e.pay.tmb)&&e.pay.tmb!==g&&p.push(`pay.tmb "${e.pay.tmb}" is not the key's thumbprint "${g}".`),!R&&!r(e.key)&&await u.Thumbprint(e.key)!==g&&p.push("Given key is not the embedded key."),p.length>0?n("tmb_matches","fail",p.join("  ")):n("tmb_matches","pass",r(e.pay.tmb)?"pay has no tmb.":"")}catch(g){n("tmb_matches","fail",g.message)}if(f("key_found")?u.IsRevoked(m)?a.allowRevoked===!0?n("not_revoked","pass","Key is revoked, allowed by opts.allowRevoked."):n("not_revoked","fail","Key is revoked."):n("not_revoked","pass"):y("not_revoked","pay_parsed","key_found"),r(a.hash))n("hash","skip","opts.hash not given.");else if(!f("key_found"))y("hash","pay_parsed","key_found");else try{U(m.alg,a),n("hash","pass")}catch(g){n("hash","fail",g.message)}let _;if(!f("pay_parsed"))y("sig_b64ut","pay_parsed");else if(r(e.sig))n("sig_b64ut","fail","coze has no sig.");else try{C(e.sig,"sig"),_=e.sig,n("sig_b64ut","pass")}catch(g){n("sig_b64ut","fail",g.message)}let A=!r(m)&&!r(m.alg)?m.alg:f("pay_parsed")?e.pay.alg:void 0;if(!f("sig_b64ut"))y("sig_size","sig_b64ut");else try{a.acceptDER===!0&&d.Genus(A)==d.GenAlgs.ECDSA&&$.IsDERSig(C(_),A)&&(_=$.DERToSig(_,A));let g=C(_).length;g!==d.SigSize(A)?n("sig_size","fail",`sig is ${g} bytes, ${A} requires ${d.SigSize(A)}.`):n("sig_size","pass")}catch(g){n("sig_size","fail",g.message)}let x=f("pay_parsed")?e.pay:void 0;if(!f("key_found","sig_size")||s.alg_matches==="fail")y("signature",...s.alg_matches==="fail"?["key_found","alg_matches","sig_size"]:["key_found","sig_size"]);else try{a.normalizeUnicode===!0&&(x=h.NormalizeUnicode(x)),await B(m.alg,_,a)?n("signature","fail","High-S signature refused by opts.requireLowS."):await T(JSON.stringify(x),m,_)?n("signature","pass"):n("signature","fail","Signature did not verify.")}catch(g){n("signature","fail",g.message)}if(r(a.canon)&&r(a.canonContains))n("canon","skip","opts.canon and opts.canonContains not given.");else if(!f("pay_parsed"))y("canon","pay_parsed");else try{Z(x,a),n("canon","pass")}catch(g){n("canon","fail",g.message)}if(a.maxAge===void 0&&a.notBefore===void 0&&a.notAfter===void 0)n("iat_window","skip","Time options not given.");else if(!f("pay_parsed"))y("iat_window","pay_parsed");else try{Y(x,a),n("iat_window","pass")}catch(g){n("iat_window","fail",g.message)}return t.verified=f("signature")&&t.checks.every(g=>g.status!=="fail"),t}async function T(e,i,a){return b.CryptoKey.VerifyMsg(i.alg,await b.CryptoKey.FromCozeKey(i,!0),e,a)}async function ye(e,i,a){if(u.IsRevoked(i))throw new w("SignDig: Cannot sign with revoked key.",l.KeyRevoked);let t=Q("SignDig",e,i,a),s=await N.SignDigest(await N.FromCozeKey(i),t);return b.SigToLowS(e,I(s))}async function de(e,i,a,t){let s=Q("VerifyDig",e,i,a),n=V(t);return n.byteLength!==d.SigSize(e)?!1:N.VerifyDigest(await N.FromCozeKey(i,!0),s,n)}function Q(e,i,a,t){if(i!==a.alg)throw new k(`${e}: alg (${i}) mismatch with cozeKey.alg (${a.alg}).`,l.AlgMismatch,{alg:i});if(d.Genus(i)!==d.GenAlgs.ECDSA)throw new k(`${e}: only ECDSA algs are supported.`,l.AlgUnsupported,{alg:i});if(t instanceof Uint8Array||(t.replace(/^0x/i,"").length===d.HashSize(i)*2?t=F(t):t=C(t)),t.length!==d.HashSize(i))throw new v(`${e}: incorrect digest size for ${i}: ${t.length} bytes, expected ${d.HashSize(i)}.`,l.DigSize,{alg:i});return t}async function O(e,i){if(e=o(e),r(e.pay))throw new c("Meta: coze.pay must exist.",l.PayMissing,{field:"pay"});let a={},t=i,s="";if(!r(i)&&typeof i=="object"&&(t=i.alg,s=i.tmb),r(e.pay.alg))r(t)||(a.alg=t);else{if(!r(t)&&t!==e.pay.alg)throw new k(`Meta: alg mismatch: coze.pay.alg (${e.pay.alg}) and parameter alg (${t}) do not match.`,l.AlgMismatch,{alg:t});a.alg=e.pay.alg}if(r(e.pay.iat)||(a.iat=e.pay.iat),r(e.pay.tmb)?r(s)||(a.tmb=s):a.tmb=e.pay.tmb,r(e.pay.typ)||(a.typ=e.pay.typ),a.can=await h.Canon(e.pay),r(a.alg)||(a.cad=await h.CanonicalHash64(e.pay,d.HashAlg(a.alg))),r(e.sig)||(a.sig=e.sig),!r(a.alg)&&!r(e.sig)&&(a.czd=await h.CanonicalHash64({cad:a.cad,sig:a.sig},d.HashAlg(a.alg))),!r(a.alg)&&Array.isArray(e.sigs)){a.sigs=[];for(const n of e.sigs)a.sigs.push({tmb:n.tmb,sig:n.sig,czd:await h.CanonicalHash64({cad:a.cad,sig:n.sig},d.HashAlg(a.alg))})}return a}function W(e){e=o(e);let i={...e};return r(e.key)||(i.key=u.PublicKey(e.key)),!r(e.coze)&&typeof e.coze=="object"&&(i.coze=W(e.coze)),i}function ue(e,i){if(typeof e=="string"){let a=e;if(e=D(a),JSON.stringify(e)!==a)throw new v("Attach: pay is not the compact serialization of pay, so sig is not of the coze's pay.  Verify with Verify(pay, cozeKey, sig) instead.",l.JSONInvalid,{field:"pay"})}return{pay:e,sig:i}}function oe(e){if(e=o(e),r(e.pay))throw new c("Detach: coze.pay must exist.",l.PayMissing,{field:"pay"});return{payCompact:JSON.stringify(e.pay),sig:e.sig}}async function X(e,i){if(e=o(e),i=o(i),r(e)||r(e.pay)||r(i)||r(i.pay))throw new c("Equal: coze.pay must exist.",l.PayMissing,{field:"pay"});if(r(e.sig)!==r(i.sig))return!1;if(r(e.pay.alg)||e.pay.alg!==i.pay.alg)return e.sig===i.sig&&await h.CanonicalS(e.pay)===await h.CanonicalS(i.pay);let a=await O(e),t=await O(i);return r(e.sig)?a.cad===t.cad:a.czd===t.czd}async function me(e,i){return e=o(e),i=o(i),Object.keys(e).sort().join()!==Object.keys(i).sort().join()||!r(e.key)&&await u.Thumbprint(e.key)!==await u.Thumbprint(i.key)?!1:X(e,i)}function D(e){let i=JSON.parse(e);return H(e),i}function H(e){let i=[];for(let a=0;a<e.length;a++){let t=i[i.length-1];switch(e[a]){case"{":i.push({names:new Set,expectName:!0});break;case"[":i.push(null);break;case"}":case"]":i.pop();break;case",":t&&(t.expectName=!0);break;case'"':{let s=a;for(a++;e[a]!=='"';a++)e[a]==="\\"&&a++;if(t&&t.expectName){let n=JSON.parse(e.slice(s,a+1));if(t.names.has(n))throw new v(`Coze: duplicate JSON field "${n}"`,l.DuplicateField,{field:n});t.names.add(n),t.expectName=!1}break}}}}const S={Coze:"coze",Key:"key",Pay:"pay",Array:"array",Unknown:"unknown"};function he(e){if(typeof e=="string")try{e=D(e)}catch(a){throw a instanceof SyntaxError?we(e,a):a}if(Array.isArray(e))return S.Array;if(typeof e!="object"||e===null)return S.Unknown;let i=a=>typeof a=="object"&&a!==null&&!Array.isArray(a);return i(e.coze)&&i(e.coze.pay)&&typeof e.coze.sig=="string"?S.Coze:i(e.pay)?typeof e.sig=="string"?S.Coze:S.Pay:e.sig!==void 0?S.Unknown:typeof e.alg=="string"&&(typeof e.x=="string"||typeof e.d=="string")?S.Key:S.Pay}function we(e,i){let a=(n,f)=>new v(`Detect: ${n} at position ${f}.`,l.JSONInvalid,{position:f});if(e.charCodeAt(0)===65279)return a("byte order mark (BOM)",0);let t=!1;for(let n=0;n<e.length;n++){let f=e[n];if(t){f==="\\"?n++:f==='"'&&(t=!1);continue}if(f==='"')t=!0;else if(f===","){let y=e.slice(n+1).search(/[^ \t\n\r]/);if(y!==-1&&(e[n+1+y]==="}"||e[n+1+y]==="]"))return a("trailing comma",n)}else if(/[\s\u200B-\u200D\u2060]/.test(f)&&!/[ \t\n\r]/.test(f)){let y=f.charCodeAt(0).toString(16).toUpperCase().padStart(4,"0");return a(`non JSON whitespace (U+${y})`,n)}}let s=/position (\d+)/.exec(i.message);return s!==null?a("invalid JSON",Number(s[1])):new v("Detect: invalid JSON: "+i.message,l.JSONInvalid)}function o(e){return typeof e=="string"?D(e):e}export{I as ArrayBufferTo64ut,ue as Attach,z as B64Error,K as B64Lenient,C as B64ToUint8Array,V as B64uToArrayBuffer,ae as B64utToHex,H as CheckDuplicates,oe as Detach,he as Detect,X as Equal,me as EqualStrict,ie as HexToB64ut,F as HexToUint8Array,S as InputTypes,O as Meta,D as ParseStrict,te as PayCanon,M as SToArrayBuffer,W as ScrubCoze,ne as Sign,re as SignCozeRaw,se as SignCryptoKey,ye as SignDig,J as SignPay,ee as Uint8ArrayToHex,fe as Verify,de as VerifyDig,ge as VerifyMeta,T as VerifyPay,r as isEmpty};
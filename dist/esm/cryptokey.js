@@ -0,0 +1 @@
+import*as i from"./conversion.js";import*as n from"./alg.js";import*as h from"./key.js";import{ECDSA as l}from"./ecdsa.js";import{isEmpty as u}from"./conversion.js";import{CozeAlgError as y,CozeKeyError as f,ErrCodes as o}from"./error.js";let w=new WeakMap;function x(){w=new WeakMap}var s={New:async function(e){switch(u(e)&&(e=n.Algs.ES256),e){case n.Algs.ES224:return l.New(e);case n.Algs.ES256:case n.Algs.ES384:case n.Algs.ES512:return await crypto.subtle.generateKey({name:n.GenAlgs.ECDSA,namedCurve:n.Curve(e)},!0,["sign","verify"]);case n.Algs.Ed25519:try{return await crypto.subtle.generateKey({name:n.Algs.Ed25519},!0,["sign","verify"])}catch(r){throw E("CryptoKey.New",e,r)}default:throw new y("CryptoKey.New: Unsupported key algorithm:"+e,o.AlgUnsupported,{alg:e})}},FromCozeKey:async function(e,r){let t=u(e.d)||r?"verify":"sign",a=w.get(e);a===void 0&&(a={},w.set(e,a));let g=a[t];if(g!==void 0&&g.alg===e.alg&&g.x===e.x&&g.d===e.d)return g.key;let d=U(e,r);a[t]={alg:e.alg,x:e.x,d:e.d,key:d};try{return await d}catch(A){throw a[t]!==void 0&&a[t].key===d&&delete a[t],A}},ToPublic:async function(e){delete e.d,e.key_ops=["verify"]},ToCozeKey:async function(e){if(l.IsKey(e)){let t=l.ToCozeKey(e);return t.tmb=await h.Thumbprint(t),t}let r=await crypto.subtle.exportKey("jwk",e);return S(r)},SignBuffer:async function(e,r){let t=await s.algFromCryptoKey(e);if(l.IsKey(e))var a=await l.SignBuffer(e,r);else a=await crypto.subtle.sign(v(t),e,r);return n.Genus(t)==n.GenAlgs.ECDSA&&(a=b(t,a)),a},SignBufferB64:async function(e,r){return await i.ArrayBufferTo64ut(await s.SignBuffer(e,r))},SignString:async function(e,r){return await s.SignBufferB64(e,await i.SToArrayBuffer(r))},VerifyArrayBuffer:async function(e,r,t,a){return l.IsKey(r)?l.VerifyBuffer(r,t,a):(await s.ToPublic(r),await crypto.subtle.verify(v(await s.algFromCryptoKey(r)),r,a,t))},VerifyMsg:async function(e,r,t,a){return s.VerifyArrayBuffer(e,r,await i.SToArrayBuffer(t),await i.B64uToArrayBuffer(a))},GetSignHashAlgoFromCryptoKey:async function(e){return n.HashAlg(await s.algFromCryptoKey(e))},algFromCryptoKey:async function(e){return e.algorithm.name===n.Algs.Ed25519?n.Algs.Ed25519:s.algFromCrv(e.algorithm.namedCurve)},algFromCrv:async function(e){switch(e){case n.Algs.Ed25519:var r=n.Algs.Ed25519;break;case n.Curves.P224:r=n.Algs.ES224;break;case n.Curves.P256:r=n.Algs.ES256;break;case n.Curves.P384:r=n.Algs.ES384;break;case n.Curves.P521:r=n.Algs.ES512;break;default:throw new y("CryptoKey.ToCozeKey: Unsupported key algorithm.",o.AlgUnsupported,{crv:e})}return r}};function C(e){if(u(e.x))throw new f("CozeKeyToJWK: key x must be set.",o.KeyInvalid,{field:"x"});var r={};switch(e.alg){case n.Algs.Ed25519:r.kty="OKP",r.crv=n.Algs.Ed25519,r.alg="EdDSA",r.use=n.Uses.Sig,r.x=e.x;break;case n.Algs.ES256:case n.Algs.ES384:case n.Algs.ES512:{r.kty=n.FamAlgs.EC,r.crv=n.Curve(e.alg),r.alg=e.alg,r.use=n.Uses.Sig;let t=n.XSize(e.alg)/2,a=i.B64ToUint8Array(e.x);if(a.length!==t*2)throw new f("CozeKeyToJWK: incorrect x size for "+e.alg+".",o.KeyInvalid,{field:"x"});r.x=i.ArrayBufferTo64ut(a.slice(0,t)),r.y=i.ArrayBufferTo64ut(a.slice(t));break}default:throw new y("CozeKeyToJWK: unsupported alg: "+e.alg,o.AlgUnsupported,{alg:e.alg})}return u(e.d)||(r.d=e.d),r}async function S(e){let r;switch(e.crv){case n.Algs.Ed25519:if(e.kty!=="OKP")throw new f("JWKToCozeKey: kty must be OKP for Ed25519.",o.KeyInvalid,{field:"kty"});r=n.Algs.Ed25519;break;case n.Curves.P256:case n.Curves.P384:case n.Curves.P521:if(e.kty!==n.FamAlgs.EC)throw new f("JWKToCozeKey: kty must be EC for curve "+e.crv+".",o.KeyInvalid,{field:"kty"});r=await s.algFromCrv(e.crv);break;default:throw new y("JWKToCozeKey: unsupported crv: "+e.crv,o.AlgUnsupported,{alg:e.crv})}if(!u(e.alg)&&e.alg!==r&&!(r===n.Algs.Ed25519&&e.alg==="EdDSA"))throw new y("JWKToCozeKey: JWK alg "+e.alg+" mismatch with crv "+e.crv+".",o.AlgMismatch,{alg:e.alg});var t={alg:r};if(u(e.x))throw new f("JWKToCozeKey: JWK x must be set.",o.KeyInvalid,{field:"x"});if(r===n.Algs.Ed25519){if(i.B64ToUint8Array(e.x).length!==n.XSize(r))throw new f("JWKToCozeKey: incorrect x size for Ed25519.",o.KeyInvalid,{field:"x"});t.x=e.x,u(e.d)||(t.d=e.d)}else{if(u(e.y))throw new f("JWKToCozeKey: JWK y must be set.",o.KeyInvalid,{field:"y"});let a=n.XSize(r)/2;t.x=i.ArrayBufferTo64ut(I(p("x",a,i.B64ToUint8Array(e.x)),p("y",a,i.B64ToUint8Array(e.y))).buffer),u(e.d)||(t.d=i.ArrayBufferTo64ut(p("d",n.DSize(r),i.B64ToUint8Array(e.d)).buffer))}return t.tmb=await h.Thumbprint(t),t}async function K(e,r){let t={name:n.Algs.Ed25519};try{return u(e.d)||r?await crypto.subtle.importKey("raw",i.B64ToUint8Array(e.x),t,!0,["verify"]):await crypto.subtle.importKey("jwk",C(e),t,!0,["sign"])}catch(a){throw E("CryptoKey.FromCozeKey",e.alg,a)}}async function U(e,r){if(e.alg===n.Algs.Ed25519)return K(e,r);if(e.alg===n.Algs.ES224)return l.FromCozeKey(e,r);if(n.Genus(e.alg)!=n.GenAlgs.ECDSA)throw new y("CryptoKey.FromCozeKey: unsupported CryptoKey algorithm: "+e.alg,o.AlgUnsupported,{alg:e.alg});let t=C(e);if(u(e.d)||r){var a="verify";delete t.d}else a="sign";return await crypto.subtle.importKey("jwk",t,{name:n.GenAlgs.ECDSA,namedCurve:t.crv},!0,[a])}function p(e,r,t){if(t.length>r)throw new f("JWKToCozeKey: incorrect "+e+" size.",o.KeyInvalid,{field:e});let a=new Uint8Array(r);return a.set(t,r-t.length),a}function I(e,r){let t=new Uint8Array(e.length+r.length);return t.set(e,0),t.set(r,e.length),t}function v(e){return e===n.Algs.Ed25519?{name:n.Algs.Ed25519}:{name:n.GenAlgs.ECDSA,hash:{name:n.HashAlg(e)}}}function E(e,r,t){return t instanceof DOMException&&t.name==="NotSupportedError"?new y(e+": alg "+r+" unsupported in this browser.",o.AlgUnsupported,{alg:r}):t}function T(e,r){if(typeof r!="bigint")throw new TypeError("IsLowS: s is not of type bigint");return n.CurveHalfOrder(e)>r}function W(e,r){if(typeof r!="bigint")throw new TypeError("toLowS: s is not of type bigint");return T(e,r)?r:n.CurveOrder(e)-r}async function J(e,r){let t=await i.B64uToArrayBuffer(r),a=await b(e,t);return i.ArrayBufferTo64ut(a)}async function F(e,r){let t=await k(e,r);return T(e,t)}function k(e,r){let t=n.SigSize(e)/2,a=r.slice(t);return B(a)}async function b(e,r){let t=n.SigSize(e)/2,a=r.slice(0,t),g=r.slice(t),d=B(g),A=W(e,d),m=D(n.SigSize(e)/2,A);var c=new Uint8Array(a.byteLength+m.byteLength);return c.set(new Uint8Array(a),0),c.set(new Uint8Array(m),a.byteLength),r=c.buffer,r}function B(e){let r=0n,t=new Uint8Array(e);for(let a=0;a<t.length;a++)r=(r<<8n)+BigInt(t[a]);return r}function D(e,r){const t=new ArrayBuffer(e),a=new DataView(t);do e--,a.setUint8(e,Number(r&BigInt(255))),r>>=8n;while(e>0);return t}export{x as ClearKeyCache,C as CozeKeyToJWK,s as CryptoKey,F as IsSigLowS,S as JWKToCozeKey,J as SigToLowS};
@@ -0,0 +1,5 @@
+import*as g from"./alg.js";import*as p from"./conversion.js";import*as N from"./key.js";import{ECDSA as $}from"./ecdsa.js";import{isEmpty as S}from"./conversion.js";import{CozeAlgError as w,CozeKeyError as s,CozeVerifyError as v,ErrCodes as r}from"./error.js";const y=2,I=3,x=4,C=6,d=48,D=160,P=161,k=[42,134,72,206,61,2,1],z=[43,101,112],A={ES224:[43,129,4,0,33],ES256:[42,134,72,206,61,3,1,7],ES384:[43,129,4,0,34],ES512:[43,129,4,0,35]};async function O(t){let e=H(t),n;switch(e.label){case"PUBLIC KEY":n=X(e.der);break;case"PRIVATE KEY":n=await Z(e.der);break;case"EC PRIVATE KEY":n=await R(e.der,null);break;default:throw new s("PEMToCozeKey: unsupported PEM type: "+e.label,r.KeyInvalid,{field:"pem"})}return n.tmb=await N.Thumbprint(n),n}function j(t,e){let n=!S(e)&&e.private===!0,i=!S(e)&&e.sec1===!0;if(S(t.x))throw new s("CozeKeyToPEM: key x must be set.",r.KeyInvalid,{field:"x"});if(n&&S(t.d))throw new s("CozeKeyToPEM: private key d must be set.",r.KeyInvalid,{field:"d"});let a=p.B64ToUint8Array(t.x);if(a.length!==g.XSize(t.alg))throw new s("CozeKeyToPEM: incorrect x size for "+t.alg+".",r.KeyInvalid,{field:"x"});if(t.alg===g.Algs.Ed25519){if(i)throw new w("CozeKeyToPEM: SEC1 is only for EC keys.",r.AlgUnsupported,{alg:t.alg});let b=o(d,o(C,z));if(!n)return K("PUBLIC KEY",o(d,b,o(I,[0],a)));let G=o(x,p.B64ToUint8Array(t.d));return K("PRIVATE KEY",o(d,o(y,[0]),b,o(x,G)))}let l=A[t.alg];if(l===void 0)throw new w("CozeKeyToPEM: unsupported alg: "+t.alg,r.AlgUnsupported,{alg:t.alg});let u=o(I,[0,4],a),f=o(d,o(C,k),o(C,l));if(!n)return K("PUBLIC KEY",o(d,f,u));let E=o(x,p.B64ToUint8Array(t.d));if(i)return K("EC PRIVATE KEY",o(d,o(y,[1]),E,o(D,o(C,l)),o(P,u)));let V=o(d,o(y,[1]),E,o(P,u));return K("PRIVATE KEY",o(d,o(y,[0]),f,o(x,V)))}function L(t,e){let n=p.B64ToUint8Array(t);if(g.Genus(e)!==g.GenAlgs.ECDSA)throw new w("SigToDER: alg must be ECDSA: "+e,r.AlgUnsupported,{alg:e});if(n.length!==g.SigSize(e))throw new v(`SigToDER: incorrect sig size for ${e}: ${n.length} bytes, expected ${g.SigSize(e)}.`,r.SigInvalid,{field:"sig"});let i=n.length/2;return o(d,M(n.slice(0,i)),M(n.slice(i)))}function q(t,e){if(typeof t=="string"&&(t=p.B64ToUint8Array(t)),t=new Uint8Array(t),g.Genus(e)!==g.GenAlgs.ECDSA)throw new w("DERToSig: alg must be ECDSA: "+e,r.AlgUnsupported,{alg:e});let n,i;try{n=h(t,0),i=T(n)}catch(u){throw new v("DERToSig: invalid DER signature: "+u.message,r.SigInvalid,{field:"sig"})}if(n.tag!==d||n.end!==t.length||i.length!==2)throw new v("DERToSig: invalid DER signature.",r.SigInvalid,{field:"sig"});let a=g.SigSize(e)/2,l=new Uint8Array(a*2);for(let u=0;u<2;u++){if(i[u].tag!==y)throw new v("DERToSig: invalid signature integer.",r.SigInvalid,{field:"sig"});let f=i[u].content;if(f.length===0||f[0]&128)throw new v("DERToSig: signature integers must be positive.",r.SigInvalid,{field:"sig"});let E=0;for(;E<f.length-1&&f[E]===0;)E++;if(f=f.slice(E),f.length>a)throw new v("DERToSig: signature integer too large for "+e+".",r.SigInvalid,{field:"sig"});l.set(f,a*(u+1)-f.length)}return p.ArrayBufferTo64ut(l)}function F(t,e){return t.length>0&&t[0]===d&&t.length!==g.SigSize(e)}function M(t){let e=0;for(;e<t.length-1&&t[e]===0;)e++;return t=t.slice(e),t[0]&128?o(y,[0],t):o(y,t)}function X(t){let e=T(c(h(t,0),d,"SPKI")),n=B(e[0]),i=c(e[1],I,"SPKI public key");if(i.content[0]!==0)throw new s("PEMToCozeKey: unsupported SPKI public key padding.",r.KeyInvalid);return{alg:n,x:Y(n,i.content.slice(1))}}async function Z(t){let e=T(c(h(t,0),d,"PKCS #8"));if(e.length<3)throw new s("PEMToCozeKey: invalid PKCS #8.",r.KeyInvalid);let n=B(e[1]),i=c(e[2],x,"PKCS #8 private key").content;if(n!==g.Algs.Ed25519)return R(i,n);let a=c(h(i,0),x,"Ed25519 private key").content;if(a.length!==g.DSize(n))throw new s("PEMToCozeKey: incorrect Ed25519 private key size.",r.KeyInvalid,{field:"d"});let l=await crypto.subtle.importKey("pkcs8",t,{name:g.Algs.Ed25519},!0,["sign"]),u=await crypto.subtle.exportKey("jwk",l);return{alg:n,x:u.x,d:p.ArrayBufferTo64ut(a)}}async function R(t,e){let n=T(c(h(t,0),d,"EC private key"));if(n.length<2||n[0].tag!==y||n[0].content.length!==1||n[0].content[0]!==1)throw new s("PEMToCozeKey: unsupported EC private key version.",r.KeyInvalid);let i=null;for(let f of n.slice(2)){if(f.tag===D){let E=U(c(h(f.content,0),C,"EC private key curve").content);if(e!==null&&e!==E)throw new w("PEMToCozeKey: EC private key curve mismatch.",r.AlgMismatch,{alg:E});e=E}f.tag===P&&(i=c(h(f.content,0),I,"EC public key").content)}if(e===null)throw new s("PEMToCozeKey: EC private key curve not given.",r.KeyInvalid);let a=c(n[1],x,"EC private key").content;if(a.length>g.DSize(e))throw new s("PEMToCozeKey: incorrect private key size.",r.KeyInvalid,{field:"d"});let l=new Uint8Array(g.DSize(e));l.set(a,l.length-a.length);let u={alg:e};if(i!==null){if(i[0]!==0)throw new s("PEMToCozeKey: unsupported EC public key padding.",r.KeyInvalid);u.x=Y(e,i.slice(1))}else u.x=await $.PublicFromD(e,J(l));return u.d=p.ArrayBufferTo64ut(l),u}function B(t){let e=T(c(t,d,"algorithm identifier")),n=c(e[0],C,"algorithm").content;if(m(n,z))return g.Algs.Ed25519;if(!m(n,k))throw new w("PEMToCozeKey: unsupported key algorithm.",r.AlgUnsupported);if(e.length<2)throw new s("PEMToCozeKey: EC named curve not given.",r.KeyInvalid);return U(c(e[1],C,"named curve").content)}function U(t){for(let e in A)if(m(t,A[e]))return e;throw new w("PEMToCozeKey: unsupported named curve.",r.AlgUnsupported)}function Y(t,e){if(t!==g.Algs.Ed25519){if(e[0]!==4)throw new s("PEMToCozeKey: only uncompressed EC points are supported.",r.KeyInvalid,{field:"x"});e=e.slice(1)}if(e.length!==g.XSize(t))throw new s("PEMToCozeKey: incorrect public key size for "+t+".",r.KeyInvalid,{field:"x"});return p.ArrayBufferTo64ut(e)}function H(t){let e=/-----BEGIN ([A-Z0-9 ]+)-----([\s\S]*?)-----END \1-----/g,n;for(;(n=e.exec(t))!==null;){if(n[1]==="EC PARAMETERS")continue;if(n[1]==="ENCRYPTED PRIVATE KEY"||n[2].includes("ENCRYPTED"))throw new s("PEMToCozeKey: encrypted PEM is not supported.",r.KeyInvalid,{field:"pem"});let a=n[2].replace(/\s+/g,"");try{var i=Uint8Array.from(atob(a),l=>l.charCodeAt(0))}catch{throw new s("PEMToCozeKey: invalid PEM base64.",r.KeyInvalid,{field:"pem"})}return{label:n[1],der:i}}throw new s("PEMToCozeKey: no PEM key found.",r.KeyInvalid,{field:"pem"})}function K(t,e){let i=btoa(String.fromCharCode(...e)).match(/.{1,64}/g);return`-----BEGIN ${t}-----
+${i.join(`
+`)}
+-----END ${t}-----
+`}function h(t,e){if(e+2>t.length)throw new s("DER: unexpected end of input.",r.KeyInvalid);let n=t[e],i=t[e+1],a=e+2;if(i&128){let u=i&127;if(u===0||u>4)throw new s("DER: unsupported length.",r.KeyInvalid);i=0;for(let f=0;f<u;f++)i=i*256+t[a+f];a+=u}let l=a+i;if(l>t.length)throw new s("DER: length exceeds input.",r.KeyInvalid);return{tag:n,content:t.slice(a,l),end:l}}function T(t){let e=[];for(let n=0;n<t.content.length;){let i=h(t.content,n);e.push(i),n=i.end}return e}function c(t,e,n){if(t===void 0||t.tag!==e)throw new s("DER: invalid "+n+".",r.KeyInvalid);return t}function o(t,...e){let n=[];for(let l of e)n.push(...l);let i=n.length,a=[];if(i<128)a.push(i);else{let l=[];for(;i>0;i>>=8)l.unshift(i&255);a.push(128|l.length,...l)}return new Uint8Array([t,...a,...n])}function m(t,e){if(t.length!==e.length)return!1;for(let n=0;n<t.length;n++)if(t[n]!==e[n])return!1;return!0}function J(t){let e=0n;for(let n of t)e=(e<<8n)+BigInt(n);return e}export{j as CozeKeyToPEM,q as DERToSig,F as IsDERSig,O as PEMToCozeKey,L as SigToDER};
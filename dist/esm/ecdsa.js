@@ -0,0 +1 @@
+import*as d from"./alg.js";import*as w from"./conversion.js";import*as v from"./hash.js";import{CozeAlgError as X,CozeKeyError as I,ErrCodes as A}from"./error.js";const j={ES224:{p:BigInt("0xffffffffffffffffffffffffffffffff000000000000000000000001"),b:BigInt("0xb4050a850c04b3abf54132565044b0b7d7bfd8ba270b39432355ffb4"),gx:BigInt("0xb70e0cbd6bb4bf7f321390b94a03c1d356c21122343280d6115c1d21"),gy:BigInt("0xbd376388b5f723fb4c22dfe6cd4375a05a07476444d5819985007e34")},ES256:{p:BigInt("0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),b:BigInt("0x5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),gx:BigInt("0x6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),gy:BigInt("0x4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5")},ES384:{p:BigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff"),b:BigInt("0xb3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef"),gx:BigInt("0xaa87ca22be8b05378eb1c71ef320ad746e1d3b628ba79b9859f741e082542a385502f25dbf55296c3a545e3872760ab7"),gy:BigInt("0x3617de4a96262c6f5d9e98bf9292dc29f8f41dbd289a147ce9da3113b5f0b8c00a60b1ce1d7e819d7a431d7c90ea0e5f")},ES512:{p:(1n<<521n)-1n,b:BigInt("0x0051953eb9618e1c9a1f929a21a0b68540eea2da725b99b315f3b8b489918ef109e156193951ec7e937b1652c0bd3bb1bf073573df883d2c34f1ef451fd46b503f00"),gx:BigInt("0x00c6858e06b70404e9cd9e3ecb662395b4429c648139053fb521f828af606b4d3dbaa14b5e77efe75928fe1dc127a2ffa8de3348b3c1856a429bf97e7e31c2e5bd66"),gy:BigInt("0x011839296a789a3bc0045c8a5fb42c7d1bd998f54449579b446817afbd17273e662c97ee72995ef42640c550b9013fad0761353c7086a272c24088be94769fd16650")}};var B={New:async function(n){let e=F(n),t={alg:n,d:w.ArrayBufferTo64ut(y(d.DSize(n),e)),x:await B.PublicFromD(n,e)};return{privateKey:await B.FromCozeKey(t),publicKey:await B.FromCozeKey(t,!0)}},FromCozeKey:async function(n,e){let t=S(n.alg),f=w.B64ToUint8Array(n.x);if(f.length!==d.XSize(n.alg))throw new I("ECDSA.FromCozeKey: incorrect x size.",A.KeyInvalid,{field:"x"});let r=d.XSize(n.alg)/2,l={x:h(f.slice(0,r)),y:h(f.slice(r))};if(!P(t,l))throw new I("ECDSA.FromCozeKey: the key is not on the curve.",A.KeyInvalid,{field:"x"});let a={type:"public",extractable:!0,algorithm:{name:d.GenAlgs.ECDSA,namedCurve:d.Curve(n.alg)},usages:["verify"],ecdsa:{alg:n.alg,point:l}};if(!w.isEmpty(n.d)&&!e){let c=h(w.B64ToUint8Array(n.d));if(c<=0n||c>=t.n)throw new I("ECDSA.FromCozeKey: invalid private key.",A.KeyInvalid,{field:"d"});a.type="private",a.usages=["sign"],a.ecdsa.d=c}return a},IsKey:function(n){return typeof n=="object"&&n!==null&&typeof n.ecdsa=="object"},ToCozeKey:function(n){let e=n.ecdsa.alg,t=d.XSize(e)/2,f={alg:e,x:w.ArrayBufferTo64ut(m(y(t,n.ecdsa.point.x),y(t,n.ecdsa.point.y)))};return n.ecdsa.d!==void 0&&(f.d=w.ArrayBufferTo64ut(y(d.DSize(e),n.ecdsa.d))),f},PublicFromD:async function(n,e){let t=S(n),f=U(t,D(t,e,{x:t.gx,y:t.gy,z:1n})),r=d.XSize(n)/2;return w.ArrayBufferTo64ut(m(y(r,f.x),y(r,f.y)))},KeyFromSeed:async function(n,e){let t=S(n),f=d.HashAlg(n),r=Math.ceil(t.nBits/8),l=BigInt(r*8-t.nBits),a=await v.HMAC(f,new TextEncoder().encode("Coze NewKeyFromSeed"),e);for(let c=0;c<256;c++){let s=m(new TextEncoder().encode(n),new Uint8Array([c])),u=h(await R(f,a,s,r))>>l;if(u>0n&&u<t.n)return{alg:n,d:w.ArrayBufferTo64ut(y(d.DSize(n),u)),x:await B.PublicFromD(n,u)}}throw new I("ECDSA.KeyFromSeed: no valid scalar derived.",A.KeyInvalid,{field:"seed"})},SignBuffer:async function(n,e,t){let f=n.ecdsa.alg,r=await v.Digest(d.HashAlg(f),e);return B.SignDigest(n,new Uint8Array(r),t)},SignDigest:async function(n,e,t){if(n.type!=="private")throw new I("ECDSA.SignDigest: key must be private.",A.KeyInvalid,{field:"d"});let f=n.ecdsa.alg,r=S(f),l=C(r,e),a=null;for(t===!0&&(a=await N(f,n.ecdsa.d,e));;){let c=a===null?F(f):await a.next(),s=i(U(r,D(r,c,{x:r.gx,y:r.gy,z:1n})).x,r.n);if(s===0n)continue;let u=i(E(c,r.n)*(l+s*n.ecdsa.d),r.n);if(u===0n)continue;let o=d.SigSize(f)/2;return m(y(o,s),y(o,u)).buffer}},VerifyBuffer:async function(n,e,t){let f=await v.Digest(d.HashAlg(n.ecdsa.alg),e);return B.VerifyDigest(n,new Uint8Array(f),t)},VerifyDigest:async function(n,e,t){let f=n.ecdsa.alg,r=S(f);if(t=new Uint8Array(t),t.length!==d.SigSize(f))return!1;let l=d.SigSize(f)/2,a=h(t.slice(0,l)),c=h(t.slice(l));if(a<=0n||a>=r.n||c<=0n||c>=r.n)return!1;let s=C(r,e),u=E(c,r.n),o=D(r,i(s*u,r.n),{x:r.gx,y:r.gy,z:1n}),b=D(r,i(a*u,r.n),{x:n.ecdsa.point.x,y:n.ecdsa.point.y,z:1n}),x=p(r,o,b);return x.z===0n?!1:i(U(r,x).x,r.n)===a}};function S(n){let e=j[n];if(e===void 0)throw new X("ECDSA: unsupported algorithm: "+n,A.AlgUnsupported,{alg:n});return e.n===void 0&&(e.n=d.CurveOrder(n),e.nBits=e.n.toString(2).length),e}function F(n){let e=S(n),t=Math.ceil(e.nBits/8),f=BigInt(t*8-e.nBits);for(;;){let r=h(crypto.getRandomValues(new Uint8Array(t)))>>f;if(r>0n&&r<e.n)return r}}async function N(n,e,t){let f=S(n),r=d.HashAlg(n),l=Math.ceil(f.nBits/8),a=d.HashSize(n),c=(g,...z)=>v.HMAC(r,g,H(z)),s=y(l,e),u=y(l,i(C(f,t),f.n)),o=new Uint8Array(a).fill(1),b=new Uint8Array(a);b=await c(b,o,[0],s,u),o=await c(b,o),b=await c(b,o,[1],s,u),o=await c(b,o);let x=!1;return{next:async function(){for(;;){x&&(b=await c(b,o,[0]),o=await c(b,o)),x=!0;let g=new Uint8Array(0);for(;g.length<l;)o=await c(b,o),g=m(g,o);let z=C(f,g);if(z>0n&&z<f.n)return z}}}}function C(n,e){let t=h(e),f=e.length*8;return f>n.nBits&&(t>>=BigInt(f-n.nBits)),t}function i(n,e){let t=n%e;return t<0n?t+e:t}function E(n,e){let[t,f]=[i(n,e),e],[r,l]=[1n,0n];for(;f!==0n;){let a=t/f;[t,f]=[f,t-a*f],[r,l]=[l,r-a*l]}if(t!==1n)throw new I("ECDSA: no modular inverse.",A.KeyInvalid);return i(r,e)}function P(n,e){return e.x<0n||e.x>=n.p||e.y<0n||e.y>=n.p?!1:i(e.y*e.y-(e.x*e.x*e.x-3n*e.x+n.b),n.p)===0n}function U(n,e){let t=E(e.z,n.p),f=i(t*t,n.p);return{x:i(e.x*f,n.p),y:i(e.y*f*t,n.p)}}function T(n,e){if(e.z===0n||e.y===0n)return{x:0n,y:1n,z:0n};let t=n.p,f=i(e.z*e.z,t),r=i(e.y*e.y,t),l=i(e.x*r,t),a=i(3n*(e.x-f)*(e.x+f),t),c=i(a*a-8n*l,t),s=i((e.y+e.z)*(e.y+e.z)-r-f,t),u=i(a*(4n*l-c)-8n*r*r,t);return{x:c,y:u,z:s}}function p(n,e,t){if(e.z===0n)return t;if(t.z===0n)return e;let f=n.p,r=i(e.z*e.z,f),l=i(t.z*t.z,f),a=i(e.x*l,f),c=i(t.x*r,f),s=i(e.y*t.z*l,f),u=i(t.y*e.z*r,f),o=i(c-a,f),b=i(2n*(u-s),f);if(o===0n)return b===0n?T(n,e):{x:0n,y:1n,z:0n};let x=i(4n*o*o,f),g=i(o*x,f),z=i(a*x,f),K=i(b*b-g-2n*z,f),M=i(b*(z-K)-2n*s*g,f),V=i(((e.z+t.z)*(e.z+t.z)-r-l)*o,f);return{x:K,y:M,z:V}}function D(n,e,t){let f={x:0n,y:1n,z:0n};for(let r=BigInt(e.toString(2).length-1);r>=0n;r--)f=T(n,f),e>>r&1n&&(f=p(n,f,t));return f}async function R(n,e,t,f){let r=new Uint8Array(0),l=new Uint8Array(0);for(let a=1;r.length<f;a++)l=await v.HMAC(n,e,H([l,t,[a]])),r=m(r,l);return r.slice(0,f)}function h(n){let e=0n;for(let t of n)e=(e<<8n)+BigInt(t);return e}function y(n,e){let t=new Uint8Array(n);for(let f=n-1;f>=0;f--)t[f]=Number(e&0xffn),e>>=8n;return t}function m(n,e){let t=new Uint8Array(n.length+e.length);return t.set(n,0),t.set(e,n.length),t}function H(n){let e=new Uint8Array(0);for(let t of n)e=m(e,new Uint8Array(t));return e}export{B as ECDSA};
@@ -0,0 +1 @@
+const s={AlgUnsupported:"ERR_ALG_UNSUPPORTED",AlgMismatch:"ERR_ALG_MISMATCH",TmbMismatch:"ERR_TMB_MISMATCH",KeyInvalid:"ERR_KEY_INVALID",KeyRevoked:"ERR_KEY_REVOKED",KeyMismatch:"ERR_KEY_MISMATCH",KeyNotFound:"ERR_KEY_NOT_FOUND",SigInvalid:"ERR_SIG_INVALID",CanonInvalid:"ERR_CANON_INVALID",CanonMissing:"ERR_CANON_MISSING",CanonExtra:"ERR_CANON_EXTRA",PayMissing:"ERR_PAY_MISSING",PrvMismatch:"ERR_PRV_MISMATCH",ThresholdInvalid:"ERR_THRESHOLD_INVALID",IatInvalid:"ERR_IAT_INVALID",Expired:"ERR_EXPIRED",NotYetValid:"ERR_NOT_YET_VALID",DigSize:"ERR_DIG_SIZE",HashInvalid:"ERR_HASH_INVALID",DuplicateField:"ERR_DUPLICATE_FIELD",JSONInvalid:"ERR_JSON_INVALID",FieldReserved:"ERR_FIELD_RESERVED",B64Invalid:"ERR_B64_INVALID",HexInvalid:"ERR_HEX_INVALID",QRCapacity:"ERR_QR_CAPACITY",QRInvalid:"ERR_QR_INVALID",KeystoreUnavailable:"ERR_KEYSTORE_UNAVAILABLE",BrowserRequired:"ERR_BROWSER_REQUIRED"};class r extends Error{constructor(E,e,R){super(E),this.name="CozeError",this.code=e,R!==void 0&&Object.assign(this,R)}}class I extends r{constructor(E,e,R){super(E,e,R),this.name="CozeKeyError"}}class o extends r{constructor(E,e,R){super(E,e,R),this.name="CozeVerifyError"}}class n extends r{constructor(E,e,R){super(E,e,R),this.name="CozeCanonError"}}class i extends r{constructor(E,e,R){super(E,e,R),this.name="CozeAlgError"}}export{i as CozeAlgError,n as CozeCanonError,r as CozeError,I as CozeKeyError,o as CozeVerifyError,s as ErrCodes};
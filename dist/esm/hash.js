@@ -0,0 +1 @@
+import*as g from"./alg.js";import{isEmpty as v,SToArrayBuffer as T,ArrayBufferTo64ut as I}from"./conversion.js";import{CozeAlgError as q,ErrCodes as G}from"./error.js";async function V(l,e){if(v(l))throw new q("Hash is not given",G.AlgUnsupported);if(l===g.Algs.SHA224){let t=O(!0);return t.update(new Uint8Array(e)),t.digest().buffer}if(X[l]!==void 0){let t=Z(l);return t.update(new Uint8Array(e)),t.digest().buffer}return crypto.subtle.digest(l,e)}async function a0(l,e,t){let c=l===g.Algs.SHA384||l===g.Algs.SHA512?128:64;e.length>c&&(e=new Uint8Array(await V(l,e)));let x=new Uint8Array(c+t.length),r=new Uint8Array(c);for(let a=0;a<c;a++){let f=a<e.length?e[a]:0;x[a]=f^54,r[a]=f^92}x.set(t,c);let i=new Uint8Array(await V(l,x)),n=new Uint8Array(c+i.length);return n.set(r),n.set(i,c),new Uint8Array(await V(l,n))}async function J(l,e){let t=g.HashAlg(l),c;if(typeof e=="string")c=await T(e);else if(e instanceof Uint8Array||e instanceof ArrayBuffer)c=e;else if(typeof Blob<"u"&&e instanceof Blob)c=await e.arrayBuffer();else throw new TypeError("Hash: input must be a string, Uint8Array, ArrayBuffer, or Blob.");return I(await V(t,c))}async function N(l,e,t){let c=g.HashAlg(l),x;switch(c){case g.Algs.SHA224:case g.Algs.SHA256:x=O(c===g.Algs.SHA224);break;case g.Algs.SHA384:case g.Algs.SHA512:x=d0(c===g.Algs.SHA384);break;case g.Algs.SHA3224:case g.Algs.SHA3256:case g.Algs.SHA3384:case g.Algs.SHA3512:case g.Algs.SHAKE128:case g.Algs.SHAKE256:x=Z(c);break;default:throw new q("HashStream: unsupported hashing algorithm: "+c,G.AlgUnsupported,{alg:l})}v(t)&&(t={});let r=4*1024*1024;t.chunkSize>0&&(r=t.chunkSize);let i=function(){if(t.signal!==void 0&&t.signal.aborted)throw t.signal.reason},n=0,a=function(f){x.update(f),n+=f.length,typeof t.onProgress=="function"&&t.onProgress(n)};if(typeof Blob<"u"&&e instanceof Blob)for(let f=0;f<e.size;f+=r)i(),a(new Uint8Array(await e.slice(f,f+r).arrayBuffer()));else if(typeof ReadableStream<"u"&&e instanceof ReadableStream){let f=e.getReader();try{for(;;){i();let b=await f.read();if(b.done)break;a(b.value)}}catch(b){throw await f.cancel(b),b}}else throw new TypeError("HashStream: input must be a Blob or ReadableStream.");return i(),I(x.digest())}async function f0(l,e,t,c,x){return l[e]=await J(c,t),v(x)||(v(x.sizeField)||(typeof t=="string"?l[x.sizeField]=(await T(t)).byteLength:typeof Blob<"u"&&t instanceof Blob?l[x.sizeField]=t.size:l[x.sizeField]=t.byteLength),!v(x.nameField)&&!v(t.name)&&(l[x.nameField]=t.name)),l}async function K(l,e,t){typeof Blob<"u"&&l instanceof Blob&&(l=[l]),l=Array.from(l),v(t)&&(t={});let c=l.reduce((i,n)=>i+n.size,0),x=0,r=[];for(const i of l){let n=await N(e,i,{chunkSize:t.chunkSize,signal:t.signal,onProgress:function(a){typeof t.onProgress=="function"&&t.onProgress(x+a,c)}});x+=i.size,r.push({name:v(i.name)?"":i.name,size:i.size,dig:n})}return r}async function l0(l,e,t,c){let x=await K(e,t,c);return l.file=typeof Blob<"u"&&e instanceof Blob?x[0]:x,l}async function c0(l,e,t,c){let x=[];Array.isArray(l.file)?x=l.file:typeof l.file=="object"&&l.file!==null&&(x=[l.file]);let i=(await K(e,t,c)).map(n=>({...n,match:x.some(a=>a!==null&&a.dig===n.dig&&(a.size===void 0||a.size===n.size))}));return{match:i.length>0&&i.every(n=>n.match),files:i}}const n0=new Uint32Array([1116352408,1899447441,3049323471,3921009573,961987163,1508970993,2453635748,2870763221,3624381080,310598401,607225278,1426881987,1925078388,2162078206,2614888103,3248222580,3835390401,4022224774,264347078,604807628,770255983,1249150122,1555081692,1996064986,2554220882,2821834349,2952996808,3210313671,3336571891,3584528711,113926993,338241895,666307205,773529912,1294757372,1396182291,1695183700,1986661051,2177026350,2456956037,2730485921,2820302411,3259730800,3345764771,3516065817,3600352804,4094571909,275423344,430227734,506948616,659060556,883997877,958139571,1322822218,1537002063,1747873779,1955562222,2024104815,2227730452,2361852424,2428436474,2756734187,3204031479,3329325298]),r0=[3238371032,914150663,812702999,4144912697,4290775857,1750603025,1694076839,3204075428],i0=[1779033703,3144134277,1013904242,2773480762,1359893119,2600822924,528734635,1541459225];function O(l){let e=new Uint32Array(l?r0:i0),t=new Uint32Array(64),c=new Uint8Array(64),x=0,r=0,i=function(n,a){for(let u=0;u<16;u++)t[u]=n[a+4*u]<<24|n[a+4*u+1]<<16|n[a+4*u+2]<<8|n[a+4*u+3];for(let u=16;u<64;u++){let A=t[u-15],h=t[u-2],k=(A>>>7|A<<25)^(A>>>18|A<<14)^A>>>3,z=(h>>>17|h<<15)^(h>>>19|h<<13)^h>>>10;t[u]=t[u-16]+k+t[u-7]+z|0}let f=e[0],b=e[1],d=e[2],S=e[3],w=e[4],m=e[5],H=e[6],F=e[7];for(let u=0;u<64;u++){let A=(w>>>6|w<<26)^(w>>>11|w<<21)^(w>>>25|w<<7),h=w&m^~w&H,k=F+A+h+n0[u]+t[u]|0,z=(f>>>2|f<<30)^(f>>>13|f<<19)^(f>>>22|f<<10),M=f&b^f&d^b&d,D=z+M|0;F=H,H=m,m=w,w=S+k|0,S=d,d=b,b=f,f=k+D|0}e[0]+=f,e[1]+=b,e[2]+=d,e[3]+=S,e[4]+=w,e[5]+=m,e[6]+=H,e[7]+=F};return{update:function(n){r+=n.length;let a=0;if(x>0){for(;x<64&&a<n.length;)c[x++]=n[a++];if(x<64)return;i(c,0),x=0}for(;a+64<=n.length;a+=64)i(n,a);for(;a<n.length;)c[x++]=n[a++]},digest:function(){let n=r*8;c[x++]=128,x>56&&(c.fill(0,x),i(c,0),x=0),c.fill(0,x);let a=new DataView(c.buffer);a.setUint32(56,Math.floor(n/4294967296)),a.setUint32(60,n>>>0),i(c,0);let f=new Uint8Array(32),b=new DataView(f.buffer);for(let d=0;d<8;d++)b.setUint32(4*d,e[d]);return l?f.slice(0,28):f}}}const Q=new Uint32Array([1116352408,3609767458,1899447441,602891725,3049323471,3964484399,3921009573,2173295548,961987163,4081628472,1508970993,3053834265,2453635748,2937671579,2870763221,3664609560,3624381080,2734883394,310598401,1164996542,607225278,1323610764,1426881987,3590304994,1925078388,4068182383,2162078206,991336113,2614888103,633803317,3248222580,3479774868,3835390401,2666613458,4022224774,944711139,264347078,2341262773,604807628,2007800933,770255983,1495990901,1249150122,1856431235,1555081692,3175218132,1996064986,2198950837,2554220882,3999719339,2821834349,766784016,2952996808,2566594879,3210313671,3203337956,3336571891,1034457026,3584528711,2466948901,113926993,3758326383,338241895,168717936,666307205,1188179964,773529912,1546045734,1294757372,1522805485,1396182291,2643833823,1695183700,2343527390,1986661051,1014477480,2177026350,1206759142,2456956037,344077627,2730485921,1290863460,2820302411,3158454273,3259730800,3505952657,3345764771,106217008,3516065817,3606008344,3600352804,1432725776,4094571909,1467031594,275423344,851169720,430227734,3100823752,506948616,1363258195,659060556,3750685593,883997877,3785050280,958139571,3318307427,1322822218,3812723403,1537002063,2003034995,1747873779,3602036899,1955562222,1575990012,2024104815,1125592928,2227730452,2716904306,2361852424,442776044,2428436474,593698344,2756734187,3733110249,3204031479,2999351573,3329325298,3815920427,3391569614,3928383900,3515267271,566280711,3940187606,3454069534,4118630271,4000239992,116418474,1914138554,174292421,2731055270,289380356,3203993006,460393269,320620315,685471733,587496836,852142971,1086792851,1017036298,365543100,1126000580,2618297676,1288033470,3409855158,1501505948,4234509866,1607167915,987167468,1816402316,1246189591]),b0=[3418070365,3238371032,1654270250,914150663,2438529370,812702999,355462360,4144912697,1731405415,4290775857,2394180231,1750603025,3675008525,1694076839,1203062813,3204075428],o0=[1779033703,4089235720,3144134277,2227873595,1013904242,4271175723,2773480762,1595750129,1359893119,2917565137,2600822924,725511199,528734635,4215389547,1541459225,327033209];function d0(l){let e=new Uint32Array(l?b0:o0),t=new Int32Array(80),c=new Int32Array(80),x=new Uint8Array(128),r=0,i=0,n=function(a,f){for(let o=0;o<16;o++){let s=f+8*o;t[o]=a[s]<<24|a[s+1]<<16|a[s+2]<<8|a[s+3],c[o]=a[s+4]<<24|a[s+5]<<16|a[s+6]<<8|a[s+7]}for(let o=16;o<80;o++){let s=t[o-15],y=c[o-15],E=(s>>>1|y<<31)^(s>>>8|y<<24)^s>>>7,C=(y>>>1|s<<31)^(y>>>8|s<<24)^(y>>>7|s<<25);s=t[o-2],y=c[o-2];let U=(s>>>19|y<<13)^(y>>>29|s<<3)^s>>>6,j=(y>>>19|s<<13)^(s>>>29|y<<3)^(y>>>6|s<<26),P=(c[o-16]>>>0)+(C>>>0)+(c[o-7]>>>0)+(j>>>0);t[o]=t[o-16]+E+t[o-7]+U+Math.floor(P/4294967296),c[o]=P}let b=e[0],d=e[1],S=e[2],w=e[3],m=e[4],H=e[5],F=e[6],u=e[7],A=e[8],h=e[9],k=e[10],z=e[11],M=e[12],D=e[13],R=e[14],L=e[15];for(let o=0;o<80;o++){let s=(A>>>14|h<<18)^(A>>>18|h<<14)^(h>>>9|A<<23),y=(h>>>14|A<<18)^(h>>>18|A<<14)^(A>>>9|h<<23),E=A&k^~A&M,C=h&z^~h&D,U=(L>>>0)+(y>>>0)+(C>>>0)+Q[2*o+1]+(c[o]>>>0),j=R+s+E+Q[2*o]+t[o]+Math.floor(U/4294967296)|0,P=U>>>0,_=(b>>>28|d<<4)^(d>>>2|b<<30)^(d>>>7|b<<25),$=(d>>>28|b<<4)^(b>>>2|d<<30)^(b>>>7|d<<25),p=b&S^b&m^S&m,e0=d&w^d&H^w&H;U=($>>>0)+(e0>>>0);let x0=_+p+Math.floor(U/4294967296)|0,t0=U>>>0;R=M,L=D,M=k,D=z,k=A,z=h,U=(u>>>0)+P,A=F+j+Math.floor(U/4294967296)|0,h=U>>>0,F=m,u=H,m=S,H=w,S=b,w=d,U=P+t0,b=j+x0+Math.floor(U/4294967296)|0,d=U>>>0}let B=function(o,s,y){let E=e[o+1]+(y>>>0);e[o]=e[o]+s+Math.floor(E/4294967296),e[o+1]=E};B(0,b,d),B(2,S,w),B(4,m,H),B(6,F,u),B(8,A,h),B(10,k,z),B(12,M,D),B(14,R,L)};return{update:function(a){i+=a.length;let f=0;if(r>0){for(;r<128&&f<a.length;)x[r++]=a[f++];if(r<128)return;n(x,0),r=0}for(;f+128<=a.length;f+=128)n(a,f);for(;f<a.length;)x[r++]=a[f++]},digest:function(){let a=i*8;x[r++]=128,r>112&&(x.fill(0,r),n(x,0),r=0),x.fill(0,r);let f=new DataView(x.buffer);f.setUint32(120,Math.floor(a/4294967296)),f.setUint32(124,a>>>0),n(x,0);let b=new Uint8Array(64),d=new DataView(b.buffer);for(let S=0;S<16;S++)d.setUint32(4*S,e[S]);return l?b.slice(0,48):b}}}const W=new Uint32Array([1,0,32898,0,32906,2147483648,2147516416,2147483648,32907,0,2147483649,0,2147516545,2147483648,32777,2147483648,138,0,136,0,2147516425,0,2147483658,0,2147516555,0,139,2147483648,32905,2147483648,32771,2147483648,32770,2147483648,128,2147483648,32778,0,2147483658,2147483648,2147516545,2147483648,32896,2147483648,2147483649,0,2147516424,2147483648]),s0=[0,1,62,28,27,36,44,6,55,20,3,10,43,25,39,41,45,15,21,8,18,2,61,56,14],X={"SHA3-224":[144,28,6],"SHA3-256":[136,32,6],"SHA3-384":[104,48,6],"SHA3-512":[72,64,6],SHAKE128:[168,32,31],SHAKE256:[136,64,31]};function Y(l){let e=new Uint32Array(10),t=new Uint32Array(50);for(let c=0;c<24;c++){for(let x=0;x<5;x++)e[2*x]=l[2*x]^l[2*x+10]^l[2*x+20]^l[2*x+30]^l[2*x+40],e[2*x+1]=l[2*x+1]^l[2*x+11]^l[2*x+21]^l[2*x+31]^l[2*x+41];for(let x=0;x<5;x++){let r=2*((x+1)%5),i=2*((x+4)%5),n=e[i]^(e[r]<<1|e[r+1]>>>31),a=e[i+1]^(e[r+1]<<1|e[r]>>>31);for(let f=0;f<25;f+=5)l[2*(x+f)]^=n,l[2*(x+f)+1]^=a}for(let x=0;x<5;x++)for(let r=0;r<5;r++){let i=x+5*r,n=l[2*i],a=l[2*i+1],f=s0[i];f>=32&&([n,a]=[a,n],f-=32);let b=2*(r+5*((2*x+3*r)%5));f===0?(t[b]=n,t[b+1]=a):(t[b]=n<<f|a>>>32-f,t[b+1]=a<<f|n>>>32-f)}for(let x=0;x<25;x+=5)for(let r=0;r<5;r++){let i=2*(r+x),n=2*((r+1)%5+x),a=2*((r+2)%5+x);l[i]=t[i]^~t[n]&t[a],l[i+1]=t[i+1]^~t[n+1]&t[a+1]}l[0]^=W[2*c],l[1]^=W[2*c+1]}}function Z(l){let[e,t,c]=X[l],x=new Uint32Array(50),r=new Uint8Array(e),i=0,n=function(){let a=new DataView(r.buffer);for(let f=0;f<e/4;f++)x[f]^=a.getUint32(4*f,!0);Y(x),i=0};return{update:function(a){for(let f=0;f<a.length;f++)r[i++]=a[f],i===e&&n()},digest:function(){r.fill(0,i),r[i]^=c,r[e-1]^=128,n();let a=new Uint8Array(t);for(let f=0;f<t;f+=e){f>0&&Y(x);let b=new DataView(new ArrayBuffer(e));for(let d=0;d<e/4;d++)b.setUint32(4*d,x[d],!0);a.set(new Uint8Array(b.buffer,0,Math.min(e,t-f)),f)}return a}}}export{V as Digest,K as DigestFiles,f0 as DigestPayField,l0 as DigestPayFile,a0 as HMAC,J as Hash,N as HashStream,c0 as MatchPayFile};
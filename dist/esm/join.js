@@ -0,0 +1 @@
+export*from"./canon.js";export*from"./alg.js";export*from"./coze.js";export*from"./conversion.js";export*from"./key.js";export*from"./cryptokey.js";export*from"./ecdsa.js";export*from"./hash.js";export*from"./der.js";export*from"./error.js";
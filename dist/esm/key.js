@@ -0,0 +1 @@
+import*as p from"./cryptokey.js";import*as D from"./canon.js";import*as m from"./coze.js";import*as o from"./alg.js";import{isEmpty as a}from"./conversion.js";import{ECDSA as v}from"./ecdsa.js";import{CozeError as F,CozeAlgError as _,CozeKeyError as g,ErrCodes as y}from"./error.js";const C=["alg","x"],P=["alg","iat","kid","tmb","typ","rvk","x"];async function N(e){if(a(e)&&(e=o.Algs.ES256),o.Genus(e)==o.GenAlgs.ECDSA||e==o.Algs.Ed25519)var t=await p.CryptoKey.New(e);else throw new _("Coze.NewKey: only ECDSA algs and Ed25519 are currently supported.",y.AlgUnsupported,{alg:e});let r=await p.CryptoKey.ToCozeKey(t.privateKey);return r.iat=Math.floor(Date.now()/1e3),r.tmb=await h(r),r.kid="My Cyphr.me Key.",r}const A=new WeakMap,x=[48,46,2,1,0,48,5,6,3,43,101,112,4,34,4,32];async function S(e){let t=new Uint8Array(x.length+e.length);t.set(x),t.set(e,x.length);let r=await crypto.subtle.importKey("pkcs8",t,{name:o.Algs.Ed25519},!0,["sign"]);return(await crypto.subtle.exportKey("jwk",r)).x}async function E(e,t){if(!(t instanceof Uint8Array))throw new TypeError("Coze.NewKeyFromSeed: seed must be a Uint8Array.");let r;if(o.Genus(e)==o.GenAlgs.ECDSA){let i=o.HashSize(e)/2;if(t.length<i)throw new g(`Coze.NewKeyFromSeed: seed must be at least ${i} bytes for ${e}.`,y.KeyInvalid,{field:"seed"});r=await v.KeyFromSeed(e,t)}else if(e==o.Algs.Ed25519){if(t.length!==o.DSize(e))throw new g("Coze.NewKeyFromSeed: Ed25519 seed must be 32 bytes.",y.KeyInvalid,{field:"seed"});r={alg:e,d:m.ArrayBufferTo64ut(t),x:await S(t)}}else throw new _("Coze.NewKeyFromSeed: only ECDSA algs and Ed25519 are currently supported.",y.AlgUnsupported,{alg:e});return r.tmb=await h(r),r}const k=21e4;async function I(e,t,r,i){if(typeof r=="string"&&(r=new TextEncoder().encode(r)),!(r instanceof Uint8Array)||r.length<16)throw new g("Coze.NewKeyFromPassword: salt must be at least 16 bytes.",y.KeyInvalid,{field:"salt"});let s=k;if(!a(i)&&i.iterations!==void 0&&(s=i.iterations),!Number.isSafeInteger(s)||s<1)throw new g("Coze.NewKeyFromPassword: iterations must be a positive integer.",y.KeyInvalid,{field:"iterations"});let l=o.DSize(o.Algs.Ed25519);o.Genus(e)==o.GenAlgs.ECDSA&&(l=o.HashSize(e));let f=await crypto.subtle.importKey("raw",new TextEncoder().encode(t.normalize("NFC")),"PBKDF2",!1,["deriveBits"]),d=await crypto.subtle.deriveBits({name:"PBKDF2",hash:o.Algs.SHA512,salt:r,iterations:s},f,l*8);return{key:await E(e,new Uint8Array(d)),params:{alg:e,kdf:"PBKDF2",hash:o.Algs.SHA512,iterations:s,salt:m.ArrayBufferTo64ut(r)}}}async function h(e){if(a(e.alg)||a(e.x))throw new g("Coze.Thumbprint: alg or x is empty.",y.KeyInvalid,{field:a(e.alg)?"alg":"x"});let t=A.get(e);if(t!==void 0&&t.alg===e.alg&&t.x===e.x)return t.tmb;let r=await D.CanonicalHash64(e,await o.HashAlg(e.alg),C);return A.set(e,{alg:e.alg,x:e.x,tmb:r}),r}async function T(e){let t=await h(e);if(t!==e.tmb)throw new g("Coze.ThumbprintMatch: key.tmb does not match the calculated thumbprint.",y.TmbMismatch,{field:"tmb"});return t}function z(e){let t={};for(const[r,i]of Object.entries(e))P.includes(r)&&(t[r]=i);return t}function B(e){return K(e)!==void 0}function $(e){let t=K(e);if(t!==void 0)throw new g(`AssertPublic: key has private component "${t}".`,y.KeyInvalid,{field:t})}function K(e){if(!(e===null||typeof e!="object"))return Object.keys(e).find(t=>t.toLowerCase()==="d")}async function R(e){let t=[],r={},i=function(n,u,b){let c={name:n,status:u};a(b)||(c.message=b),t.push(c),r[n]=u},s=(...n)=>n.every(u=>r[u]==="pass"),l=function(n,...u){i(n,"skip","Requires passing "+u.filter(b=>r[b]!=="pass").join(", ")+".")},f={};(typeof e!="object"||e===null)&&(e={});let d;try{d=o.Params(e.alg),d.Use!==o.Uses.Sig?i("alg_known","fail",`alg "${e.alg}" is not a signing alg.`):i("alg_known","pass")}catch{i("alg_known","fail",`alg "${e.alg}" is not supported.`)}let w=[];for(const n of["x","d","tmb"])if(!a(e[n]))try{f[n]=m.B64ToUint8Array(e[n],n)}catch{w.push(n)}a(e.x)&&a(e.d)&&a(e.tmb)?i("b64ut","fail","At least one of x, d, and tmb must be set."):w.length>0?i("b64ut","fail","Not strict b64ut: "+w.join(", ")+"."):i("b64ut","pass");for(const[n,u,b]of[["x_length","x","XSize"],["d_length","d","DSize"]])a(e[u])?i(n,"skip",`No ${u}.`):s("alg_known","b64ut")?f[u].length!==d[b]?i(n,"fail",`${u} is ${f[u].length} bytes, ${e.alg} requires ${d[b]}.`):i(n,"pass"):l(n,"alg_known","b64ut"),n==="x_length"&&(a(e.x)?i("y_length","skip","No x."):s("alg_known","b64ut")?d.Genus!==o.GenAlgs.ECDSA?i("y_length","skip",`${e.alg} has no Y coordinate.`):f.x.length===d.XSize/2?i("y_length","fail","x is only the X coordinate.  Coze x is X || Y."):f.x.length!==d.XSize?l("y_length","x_length"):i("y_length","pass"):l("y_length","alg_known","b64ut"));if(a(e.tmb)?i("tmb_matches","skip","No tmb."):s("alg_known","b64ut")?a(e.x)?f.tmb.length!==d.HashSize?i("tmb_matches","fail",`tmb is ${f.tmb.length} bytes, ${e.alg} requires ${d.HashSize}.`):i("tmb_matches","pass"):s("x_length")?await h(e)!==e.tmb?i("tmb_matches","fail","tmb does not match the thumbprint of alg and x."):i("tmb_matches","pass"):l("tmb_matches","x_length"):l("tmb_matches","alg_known","b64ut"),a(e.d)||a(e.x))i("d_derives_x","skip","Requires d and x.");else if(!s("x_length","d_length"))l("d_derives_x","x_length","d_length");else try{let n;d.Genus===o.GenAlgs.ECDSA?n=await v.PublicFromD(e.alg,BigInt("0x"+m.Uint8ArrayToHex(f.d))):e.alg===o.Algs.Ed25519&&(n=await S(f.d)),n===void 0?i("d_derives_x","skip",`Deriving x is not supported for ${e.alg}.`):n!==e.x?i("d_derives_x","fail","x is not the public key of d."):i("d_derives_x","pass")}catch(n){i("d_derives_x","fail","d is invalid: "+n.message)}if(a(e.d)||a(e.x))i("sign_verify_roundtrip","skip","Requires d and x.");else if(r.d_derives_x==="fail"||!s("x_length","d_length"))l("sign_verify_roundtrip","x_length","d_length","d_derives_x");else try{let n="Coze Diagnose",u=await m.SignPay(n,e);await m.VerifyPay(n,e,u)?i("sign_verify_roundtrip","pass"):i("sign_verify_roundtrip","fail","Signature by d did not verify with x.")}catch(n){i("sign_verify_roundtrip","fail",n.message)}return{ok:t.every(n=>n.status!=="fail"),checks:t}}async function H(e){if(a(e.d))return console.error("Coze key missing `d`"),!1;try{let t="7AtyaCHO2BAG06z0W1tOQlZFWbhxGgqej4k9-HWP3DE-zshRbrE-69DIfgY704_FDYez7h_rEI1WQVKhv5Hd5Q",r=await m.SignPay(t,e);return m.VerifyPay(t,e,r)}catch{return!1}}async function G(e){if(typeof e!="object")return console.error("Correct: CozeKey must be passed in as an object."),!1;if(a(e.alg))return console.error("Correct: Alg must be set"),!1;let t=o.Params(e.alg),r=a(e.tmb),i=a(e.x),s=a(e.d);if(r&&i&&s)return console.error("Correct: At least one of [x, tmb, d] must be set"),!1;for(const l of["x","d","tmb"])if(!a(e[l]))try{m.B64ToUint8Array(e[l],l)}catch(f){return console.error("Correct: "+f.message),!1}if(i&&s)return r||e.tmb.length!==t.HashSizeB64?(console.error("Correct: Incorrect `tmb` size: ",e.tmb.length),!1):!0;if(!i&&e.x.length!==t.XSizeB64)return console.error("Correct: Incorrect x size: ",e.x.length),!1;if(!r&&!i){let l=await h(e);if(e.tmb!==l)return console.error("Correct: Incorrect given `tmb`: ",e.tmb),!1}if(!s&&!i){let l=await p.CryptoKey.FromCozeKey(e),f=await m.SToArrayBuffer("Test Signing"),d=await p.CryptoKey.SignBuffer(l,f),w=await p.CryptoKey.FromCozeKey(e,!0);if(!await p.CryptoKey.VerifyArrayBuffer(e.alg,w,f,d))return console.error("Correct: private key invalid."),!1}return!0}async function U(e,t){if(a(e))throw new g("CozeKey.Revoke: Private key not set.  Cannot sign message",y.KeyInvalid,{field:"d"});typeof t=="string"&&(t={msg:t}),a(t)&&(t={});for(const s of["alg","iat","tmb","rvk"])if(s in t)throw new F(`CozeKey.Revoke: "${s}" is set by Revoke and may not be given.`,y.FieldReserved,{field:s});var r={};r.pay={},a(t.typ)||(r.pay.typ=t.typ),r.pay.rvk=Math.round(Date.now()/1e3),a(t.msg)||(r.pay.msg=t.msg);for(const[s,l]of Object.entries(t))s!=="typ"&&s!=="msg"&&(r.pay[s]=l);let i=e.rvk;delete e.rvk;try{r=await m.SignCozeRaw(r,e,null,{setStandard:!0})}catch(s){throw i!==void 0&&(e.rvk=i),s}return i!==void 0?e.rvk=i:e.rvk=r.pay.rvk,r}function j(e){let t=e.rvk;return t==null?!1:typeof t=="number"&&Number.isInteger(t)?t>0:!0}async function M(e,t){let r=e.pay.rvk;return!Number.isSafeInteger(r)||r<=0||e.pay.tmb!==await h(t)||Number.isInteger(t.rvk)&&t.rvk>0&&r<t.rvk?!1:m.Verify(e,t,{allowRevoked:!0})}async function X(e,t){let r=e instanceof Map;if(!r&&!Array.isArray(e))return e;if(a(t))throw new g("LookupKey: no key for tmb: tmb is empty.",y.KeyNotFound,{field:"tmb"});let i=e;r&&(i=e.has(t)?[e.get(t)]:[]);for(const s of i){if(a(s))continue;let l;try{l=await h(s)}catch{continue}if(l===t)return s}throw new g(`LookupKey: no key for tmb ${t}.`,y.KeyNotFound,{field:"tmb",tmb:t})}export{$ as AssertPublic,G as Correct,R as Diagnose,B as IsPrivate,j as IsRevoked,X as LookupKey,N as NewKey,I as NewKeyFromPassword,E as NewKeyFromSeed,z as PublicKey,U as Revoke,h as Thumbprint,T as ThumbprintMatch,C as TmbCanon,H as Valid,M as VerifyRevoke};
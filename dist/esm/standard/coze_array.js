@@ -0,0 +1 @@
+import{isEmpty as o,Meta as b,ParseStrict as C,SignPay as M,Verify as z}from"../coze.js";import{IsRevoked as k,LookupKey as S,Thumbprint as p}from"../key.js";import{CryptoKey as A}from"../cryptokey.js";import{Canonical as I,NormalizeUnicode as O}from"../canon.js";import{HashAlg as P}from"../alg.js";import{CozeError as c,CozeAlgError as v,CozeCanonError as x,CozeKeyError as g,CozeVerifyError as d,ErrCodes as f}from"../error.js";async function E(i,t,a){if(o(a)&&(a={}),k(t))throw new g("SignCozeArray: Cannot sign with revoked key.",f.KeyRevoked);if(a.iat!==void 0&&(!Number.isSafeInteger(a.iat)||a.iat<0))throw new c("SignCozeArray: opts.iat must be a non-negative integer.",f.IatInvalid,{field:"iat"});if(o(t.d))throw new g("SignCozeArray: cozeKey must be private.",f.KeyInvalid,{field:"d"});let e=P(t.alg);if(!o(a.hash)&&a.hash!==e)throw new v(`SignCozeArray: hash not valid for alg: ${a.hash} is not ${t.alg}'s hash ${e}.`,f.HashInvalid,{alg:t.alg});let n=await p(t),l=null;return a.deterministic!==!0&&(l=await A.FromCozeKey(t)),Promise.all(i.map(r=>V(r,t,l,n,a)))}async function V(i,t,a,e,n){let l={coze:null,error:null};try{let r=typeof i=="string"?C(i):{...i};if(!o(r.alg)&&r.alg!==t.alg)throw new v("SignCozeArray: Coze key alg mismatch with pay.alg.",f.AlgMismatch,{alg:r.alg});if(!o(r.tmb)&&r.tmb!==e)throw new g("SignCozeArray: Coze key tmb mismatch with pay.tmb.",f.TmbMismatch,{field:"tmb"});let y=r.iat;if(y===void 0)y=n.iat!==void 0?n.iat:Math.round(Date.now()/1e3);else if(n.iat!==void 0&&n.iat!==y)throw new c(`SignCozeArray: pay.iat (${y}) mismatch with opts.iat (${n.iat}).`,f.IatInvalid,{field:"iat"});let u={alg:t.alg,iat:y,tmb:e};for(const[h,m]of Object.entries(r))h in u||(u[h]=m);if(r=u,n.normalizeUnicode===!0&&(r=O(r)),!o(n.canon)){let m=(Array.isArray(n.canon)?n.canon.flatMap(s=>typeof s=="string"?[s]:Object.keys(s)):Object.keys(n.canon)).filter(s=>r[s]===void 0);if(m.length>0)throw new x("SignCozeArray: pay missing field(s) required by canon: "+m.join(", "),f.CanonMissing,{fields:m});r=await I(r,n.canon)}let w;a===null?w=await M(JSON.stringify(r),t,n):w=await A.SignString(a,JSON.stringify(r)),l.coze={pay:r,sig:w}}catch(r){l.error=r}return l}async function $(i,t){return Array.isArray(i)?Promise.all(i.map(a=>j(a,t))):z(i,t)}async function j(i,t){let a={czd:"",tmb:"",verified:!1,error:null};try{if(typeof i=="string"&&(i=C(i)),o(i))throw new d("VerifyCozeArray: coze is empty.",f.PayMissing,{field:"pay"});if(o(i.coze)||(i=i.coze),o(i.pay))throw new d("VerifyCozeArray: coze.pay must exist.",f.PayMissing,{field:"pay"});if(o(i.sig))throw new d("VerifyCozeArray: coze.sig must exist.",f.SigInvalid,{field:"sig"});if(o(i.pay.tmb)||(a.tmb=i.pay.tmb),t==null){if(o(i.key))throw new g("VerifyCozeArray: no key given and coze has no embedded key.",f.KeyInvalid,{field:"key"});return a.tmb=await p(i.key),a.czd=(await b(i,i.key.alg)).czd,a.verified=await z(i),a}let e=await S(t,i.pay.tmb);a.tmb=await p(e),a.czd=(await b(i,e.alg)).czd,a.verified=await z(i,e)}catch(e){a.error=e}return a}async function N(i,t){let a=await Promise.all(i.map(n=>H(n,t))),e={count:a.length,failed:0,tmb:{},typ:{},iatMin:null,iatMax:null};for(const n of a){if(n.error!==null){e.failed++;continue}let l=n.meta;o(l.tmb)||(e.tmb[l.tmb]=(e.tmb[l.tmb]||0)+1),o(l.typ)||(e.typ[l.typ]=(e.typ[l.typ]||0)+1),typeof l.iat=="number"&&((e.iatMin===null||l.iat<e.iatMin)&&(e.iatMin=l.iat),(e.iatMax===null||l.iat>e.iatMax)&&(e.iatMax=l.iat))}return{results:a,summary:e}}async function H(i,t){let a={meta:null,error:null};try{if(typeof i=="string"&&(i=C(i)),o(i))throw new d("MetaArray: coze is empty.",f.PayMissing,{field:"pay"});o(i.coze)||(i=i.coze),a.meta=await b(i,t)}catch(e){a.error=e}return a}export{N as MetaArray,E as SignCozeArray,$ as VerifyCozeArray};
@@ -0,0 +1 @@
+import{isEmpty as f,Meta as c,ParseStrict as g,Sign as u,Verify as v}from"../coze.js";import{LookupKey as m}from"../key.js";import{CozeVerifyError as s,ErrCodes as o}from"../error.js";const y="";async function w(a,p,t,i){let r={...a};return delete r.prv,f(t)||(r.prv=(await c(t)).czd),u({pay:r},p,null,i)}async function C(a,p,t){let i={verified:!1,czds:[],index:-1,reason:"",error:null},r=y;if(!f(t)&&t.prv!==void 0&&(r=t.prv),a.length===0)return i.index=0,i.error=new s("VerifyChain: chain is empty.",o.PayMissing,{field:"pay"}),i.reason=i.error.message,i;for(let n=0;n<a.length;n++)try{let e=a[n];if(typeof e=="string"&&(e=g(e)),f(e.coze)||(e=e.coze),f(e.pay))throw new s(`VerifyChain: coze ${n} has no pay.`,o.PayMissing,{field:"pay"});let l=await m(p,e.pay.tmb);if(!await v(e,l))throw new s(`VerifyChain: coze ${n} signature is invalid.`,o.SigInvalid,{field:"sig"});let d=e.pay.prv===void 0?y:e.pay.prv;if(d!==r){let h=r===y?"the chain genesis":`"${r}"`;throw new s(`VerifyChain: coze ${n} prv "${d}" is not ${h}.`,o.PrvMismatch,{field:"prv"})}r=(await c(e,l.alg)).czd,i.czds.push(r)}catch(e){return i.index=n,i.reason=e.message,i.error=e,i}return i.verified=!0,i}export{y as ChainGenesis,w as SignChained,C as VerifyChain};
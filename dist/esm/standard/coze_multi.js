@@ -0,0 +1 @@
+import{isEmpty as s,SignPay as w,VerifyPay as b}from"../coze.js";import{Thumbprint as o,IsRevoked as p}from"../key.js";import{CozeError as v,CozeKeyError as A,CozeAlgError as S,ErrCodes as h}from"../error.js";async function k(t,e){if(p(e))throw new A("SignAdd: Cannot sign with revoked key.",h.KeyRevoked);if(!s(t.pay.alg)&&t.pay.alg!==e.alg)throw new S("SignAdd: Coze key alg mismatch with coze.pay.alg.",h.AlgMismatch,{alg:t.pay.alg});let n=await o(e),r=await w(JSON.stringify(t.pay),e);Array.isArray(t.sigs)||(t.sigs=[]);let a=t.sigs.find(l=>l.tmb===n);return a!==void 0?a.sig=r:t.sigs.push({tmb:n,sig:r}),t}async function C(t,e,n){let r=e.length;if(!s(n)&&n.threshold!==void 0&&(r=n.threshold),!Number.isInteger(r)||r<1||r>e.length)throw new v(`VerifyMulti: threshold must be an integer from 1 to ${e.length}.`,h.ThresholdInvalid,{field:"threshold"});let a=new Map;for(const i of e)a.set(await o(i),i);let l=Array.isArray(t.sigs)?[...t.sigs]:[];!s(t.sig)&&!s(t.pay.tmb)&&l.push({tmb:t.pay.tmb,sig:t.sig});let u=JSON.stringify(t.pay),g=new Set,f=new Set,m=new Set;for(const i of l){let d=a.get(i.tmb);if(d===void 0){m.add(i.tmb);continue}let y=!1;if(!p(d)&&(s(t.pay.alg)||t.pay.alg===d.alg))try{y=await b(u,d,i.sig)}catch{y=!1}y?g.add(i.tmb):f.add(i.tmb)}for(const i of f)g.delete(i);return{verified:g.size>=r,valid:[...g],invalid:[...f],unknown:[...m]}}export{k as SignAdd,C as VerifyMulti};
@@ -0,0 +1 @@
+import{CozeError as n,ErrCodes as d}from"../error.js";function i(r,t){if(typeof document>"u")throw new n("DownloadJSON: requires a browser.",d.BrowserRequired);let o=URL.createObjectURL(new Blob([JSON.stringify(r,null,"	")],{type:"application/json"})),e=document.createElement("a");e.href=o,e.download=t,document.body.appendChild(e),e.click(),e.remove(),setTimeout(()=>URL.revokeObjectURL(o),1e3)}export{i as DownloadJSON};
@@ -0,0 +1 @@
+import{isEmpty as a}from"../coze.js";import{CryptoKey as d}from"../cryptokey.js";import{CozeError as K,CozeKeyError as s,ErrCodes as o}from"../error.js";const w="coze",y="keys";let c=null;class i extends K{constructor(e){super(e,o.KeystoreUnavailable),this.name="KeystoreUnavailableError"}}async function f(t,e){if(a(t))throw new s("StoreKey: name must be set.",o.KeyInvalid,{field:"name"});let r={name:t};if(!a(e.privateKey)||!a(e.publicKey)){if(a(e.publicKey))throw new s("StoreKey: CryptoKeyPair must have publicKey.",o.KeyInvalid,{field:"publicKey"});r.cozeKey=await d.ToCozeKey(e.publicKey),r.cryptoKeyPair=e}else{if(a(e.alg)||a(e.x))throw new s("StoreKey: Coze key must have alg and x.",o.KeyInvalid,{field:"x"});r.cozeKey=e}let u=await l("readwrite");return await n(u.put(r)),r}async function p(t){let e=await l("readonly"),r=await n(e.get(t));return r===void 0?null:r}async function m(){let t=await l("readonly");return(await n(t.getAll())).map(r=>({name:r.name,alg:r.cozeKey.alg,tmb:r.cozeKey.tmb,cryptoKey:!a(r.cryptoKeyPair)}))}async function b(t){let e=await l("readwrite");await n(e.delete(t))}async function v(){if(typeof indexedDB>"u"||indexedDB===null)throw new i("Keystore: IndexedDB is unavailable.");try{var t=indexedDB.open(w,1)}catch(e){throw new i("Keystore: IndexedDB is unavailable: "+e)}t.onupgradeneeded=()=>{t.result.createObjectStore(y,{keyPath:"name"})};try{return await n(t)}catch(e){throw new i("Keystore: IndexedDB is unavailable: "+e)}}async function l(t){return c===null&&(c=await v()),c.transaction(y,t).objectStore(y)}function n(t){return new Promise((e,r)=>{t.onsuccess=()=>e(t.result),t.onerror=()=>r(t.error)})}export{b as DeleteKey,i as KeystoreUnavailableError,m as ListKeys,p as LoadKey,f as StoreKey};
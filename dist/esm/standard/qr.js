@@ -0,0 +1 @@
+import{ParseStrict as B}from"../coze.js";import{CozeError as y,ErrCodes as k}from"../error.js";const M={L:{ordinal:0,bits:1},M:{ordinal:1,bits:0},Q:{ordinal:2,bits:3},H:{ordinal:3,bits:2}},D=[[-1,7,10,15,20,26,18,20,24,30,18,20,24,26,30,22,24,28,30,28,28,28,28,30,30,26,28,30,30,30,30,30,30,30,30,30,30,30,30,30,30],[-1,10,16,26,18,24,16,18,22,22,26,30,22,22,24,24,28,28,26,26,26,26,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28,28],[-1,13,22,18,26,18,24,18,22,20,24,28,26,24,20,30,24,28,28,26,30,28,30,30,30,30,28,30,30,30,30,30,30,30,30,30,30,30,30,30,30],[-1,17,28,22,16,22,28,26,26,24,28,24,28,22,24,24,30,28,28,26,28,30,24,30,30,30,30,30,30,30,30,30,30,30,30,30,30,30,30,30,30]],S=[[-1,1,1,1,1,1,2,2,2,2,4,4,4,4,4,6,6,6,6,7,8,8,9,9,10,12,12,12,13,14,15,16,17,18,19,19,20,21,22,24,25],[-1,1,1,1,2,2,4,4,4,5,5,5,8,9,9,10,10,11,13,14,16,17,17,18,20,21,23,25,26,28,29,31,33,35,37,38,40,43,45,47,49],[-1,1,1,2,2,4,4,6,6,8,8,8,10,12,16,12,17,16,18,21,20,23,23,25,27,29,34,34,35,38,40,43,45,48,51,53,56,59,62,65,68],[-1,1,1,2,4,4,4,5,6,8,8,11,11,16,16,18,16,19,21,25,25,25,34,30,32,35,37,40,42,45,48,51,54,57,60,63,66,70,74,77,81]];function N(t,e,l){l==null&&(l={});let n=v(t),o=I(n,l),r=l.scale>0?l.scale:4,a=l.margin>=0?l.margin:4,i=o.length+a*2;if(typeof e.getContext=="function"){e.width=i*r,e.height=i*r;let d=e.getContext("2d");d.fillStyle="#FFFFFF",d.fillRect(0,0,e.width,e.height),d.fillStyle="#000000";for(let c=0;c<o.length;c++)for(let w=0;w<o.length;w++)o[c][w]&&d.fillRect((w+a)*r,(c+a)*r,r,r);return n}let f=[];for(let d=0;d<o.length;d++)for(let c=0;c<o.length;c++)o[d][c]&&f.push(`M${c+a},${d+a}h1v1h-1z`);return e.setAttribute("viewBox",`0 0 ${i} ${i}`),e.setAttribute("shape-rendering","crispEdges"),e.innerHTML=`<rect width="100%" height="100%" fill="#FFFFFF"/><path d="${f.join("")}" fill="#000000"/>`,n}function v(t){if(typeof t=="string"&&(t=B(t)),typeof t.pay!="object"||t.pay===null)return JSON.stringify(t);let e={pay:t.pay};return t.key!==void 0&&(e.key=t.key),t.sig!==void 0&&(e.sig=t.sig),JSON.stringify(e)}function I(t,e){let l="M";e!=null&&e.errorCorrection!==void 0&&(l=e.errorCorrection);let n=M[l];if(n===void 0)throw new y(`QR: invalid error correction "${l}".  Must be L, M, Q, or H.`,k.QRInvalid);let o=new TextEncoder().encode(t),r=1;for(;r<=40&&!(o.length<=$(r,n));r++);if(r>40){let s=$(40,n);throw new y(`QR: payload is ${o.length} bytes, exceeding the ${s} byte maximum for error correction ${l}.`,k.QRCapacity,{size:o.length,max:s})}let a=[],i=(s,x)=>{for(let b=x-1;b>=0;b--)a.push(s>>>b&1)};i(4,4),i(o.length,A(r));for(const s of o)i(s,8);let f=j(r,n)*8;i(0,Math.min(4,f-a.length)),i(0,(8-a.length%8)%8);for(let s=236;a.length<f;s^=253)i(s,8);let d=new Uint8Array(a.length/8);for(let s=0;s<a.length;s++)d[s>>>3]|=a[s]<<7-(s&7);let c=T(r);Z(c,Y(d,r,n));let w=null;for(let s=0;s<8;s++){C(c,s),Q(c,n,s);let x=_(c.modules);(w===null||x<w.penalty)&&(w={mask:s,penalty:x}),C(c,s)}return C(c,w.mask),Q(c,n,w.mask),c.modules}async function J(t){let e;if(typeof BarcodeDetector<"u"){let l=await new BarcodeDetector({formats:["qr_code"]}).detect(t);if(l.length===0)throw new y("QRToCoze: no QR code found.",k.QRInvalid);e=l[0].rawValue}else e=O(G(t));return B(V(e))}function V(t){if(t=t.trim(),t.startsWith("{")||t.startsWith("["))return t;try{t=decodeURIComponent(t)}catch{}let e=t.search(/[{[]/),l=Math.max(t.lastIndexOf("}"),t.lastIndexOf("]"));if(e===-1||l<e)throw new y("QRToCoze: QR code does not contain JSON.",k.QRInvalid);return t.slice(e,l+1)}function G(t){if(t.data!==void 0&&t.width>0)return t;if(typeof t.getContext=="function")return t.getContext("2d").getImageData(0,0,t.width,t.height);let e=t.videoWidth||t.naturalWidth||t.width,l=t.videoHeight||t.naturalHeight||t.height,n=document.createElement("canvas");n.width=e,n.height=l;let o=n.getContext("2d");return o.drawImage(t,0,0),o.getImageData(0,0,e,l)}function T(t){let e=t*4+17,l={version:t,size:e,modules:Array.from({length:e},()=>new Array(e).fill(!1)),isFunction:Array.from({length:e},()=>new Array(e).fill(!1))},n=(r,a,i)=>{l.modules[a][r]=i,l.isFunction[a][r]=!0};for(let r=0;r<e;r++)n(6,r,r%2===0),n(r,6,r%2===0);for(const[r,a]of[[3,3],[e-4,3],[3,e-4]])for(let i=-4;i<=4;i++)for(let f=-4;f<=4;f++){let d=r+f,c=a+i;if(d>=0&&d<e&&c>=0&&c<e){let w=Math.max(Math.abs(f),Math.abs(i));n(d,c,w!==2&&w!==4)}}let o=X(t);for(let r=0;r<o.length;r++)for(let a=0;a<o.length;a++)if(!(r===0&&a===0||r===0&&a===o.length-1||r===o.length-1&&a===0))for(let i=-2;i<=2;i++)for(let f=-2;f<=2;f++)n(o[r]+f,o[a]+i,Math.max(Math.abs(f),Math.abs(i))!==1);if(l.set=n,Q(l,M.L,0),t>=7){let r=t;for(let i=0;i<12;i++)r=r<<1^(r>>>11)*7973;let a=t<<12|r;for(let i=0;i<18;i++){let f=(a>>>i&1)!==0,d=e-11+i%3,c=Math.floor(i/3);n(d,c,f),n(c,d,f)}}return l}function z(t,e){let l=t.bits<<3|e,n=l;for(let o=0;o<10;o++)n=n<<1^(n>>>9)*1335;return(l<<10|n)^21522}function E(t){let e=[],l=[];for(let n=0;n<15;n++)n<6?e.push([8,n]):n<8?e.push([8,n+1]):n===8?e.push([7,8]):e.push([14-n,8]),l.push(n<8?[t-1-n,8]:[8,t-15+n]);return[e,l]}function Q(t,e,l){let n=z(e,l);for(const o of E(t.size))for(let r=0;r<15;r++)t.set(o[r][0],o[r][1],(n>>>r&1)!==0);t.set(8,t.size-8,!0)}function X(t){if(t===1)return[];let e=Math.floor(t/7)+2,l=Math.floor((t*8+e*3+5)/(e*4-4))*2,n=[6];for(let o=t*4+10;n.length<e;o-=l)n.splice(1,0,o);return n}function F(t){let e=(16*t+128)*t+64;if(t>=2){let l=Math.floor(t/7)+2;e-=(25*l-10)*l-55,t>=7&&(e-=36)}return e}function j(t,e){return Math.floor(F(t)/8)-D[e.ordinal][t]*S[e.ordinal][t]}function A(t){return t<10?8:16}function $(t,e){return Math.floor((j(t,e)*8-4-A(t))/8)}function Y(t,e,l){let n=W(e,l),o=P(n.eccLen),r=[];for(let i=0,f=0;i<n.numBlocks;i++){let d=n.shortDataLen+(i<n.numShort?0:1),c=Array.from(t.slice(f,f+d));f+=d,r.push({data:c,ecc:U(c,o)})}let a=[];for(let i=0;i<=n.shortDataLen;i++)for(const f of r)i<f.data.length&&a.push(f.data[i]);for(let i=0;i<n.eccLen;i++)for(const f of r)a.push(f.ecc[i]);return Uint8Array.from(a)}function W(t,e){let l=S[e.ordinal][t],n=D[e.ordinal][t],o=Math.floor(F(t)/8);return{numBlocks:l,eccLen:n,numShort:l-o%l,shortDataLen:Math.floor(o/l)-n}}function H(t){let e=[];for(let l=t.size-1;l>=1;l-=2){l===6&&(l=5);let n=(l+1&2)===0;for(let o=0;o<t.size;o++)for(let r=0;r<2;r++){let a=l-r,i=n?t.size-1-o:o;t.isFunction[i][a]||e.push([a,i])}}return e}function Z(t,e){let l=H(t);for(let n=0;n<e.length*8;n++)t.modules[l[n][1]][l[n][0]]=(e[n>>>3]>>>7-(n&7)&1)!==0}function C(t,e){for(let l=0;l<t.size;l++)for(let n=0;n<t.size;n++){let o;switch(e){case 0:o=(n+l)%2===0;break;case 1:o=l%2===0;break;case 2:o=n%3===0;break;case 3:o=(n+l)%3===0;break;case 4:o=(Math.floor(n/3)+Math.floor(l/2))%2===0;break;case 5:o=n*l%2+n*l%3===0;break;case 6:o=(n*l%2+n*l%3)%2===0;break;case 7:o=((n+l)%2+n*l%3)%2===0;break}!t.isFunction[l][n]&&o&&(t.modules[l][n]=!t.modules[l][n])}}function _(t){let e=t.length,l=0,n=0,o=[];for(let a=0;a<e;a++)o.push(t[a].map(i=>i?"1":"0").join("")),o.push(t.map(i=>i[a]?"1":"0").join(""));for(const a of o){for(const f of a.match(/0{5,}|1{5,}/g)||[])l+=f.length-2;let i="0000"+a+"0000";for(let f=i.indexOf("1011101");f!==-1;f=i.indexOf("1011101",f+1))(i.startsWith("0000",f-4)||i.startsWith("0000",f+7))&&(l+=40)}for(let a=0;a<e;a++)for(let i=0;i<e;i++)if(t[a][i]&&n++,i<e-1&&a<e-1){let f=t[a][i];f===t[a][i+1]&&f===t[a+1][i]&&f===t[a+1][i+1]&&(l+=3)}let r=e*e;return l+=(Math.ceil(Math.abs(n*20-r*10)/r)-1)*10,l}function L(t,e){let l=0;for(let n=7;n>=0;n--)l=l<<1^(l>>>7)*285,l^=(e>>>n&1)*t;return l}function P(t){let e=new Array(t).fill(0);e[t-1]=1;let l=1;for(let n=0;n<t;n++){for(let o=0;o<t;o++)e[o]=L(e[o],l),o+1<t&&(e[o]^=e[o+1]);l=L(l,2)}return e}function U(t,e){let l=new Array(e.length).fill(0);for(const n of t){let o=n^l.shift();l.push(0);for(let r=0;r<e.length;r++)l[r]^=L(e[r],o)}return l}function O(t){let e=t.width,l=t.height,n=new Float32Array(e*l),o=255,r=0;for(let p=0;p<e*l;p++){let g=(t.data[p*4]*299+t.data[p*4+1]*587+t.data[p*4+2]*114)/1e3;n[p]=g,o=Math.min(o,g),r=Math.max(r,g)}let a=(o+r)/2,i=(p,g)=>n[Math.floor(g)*e+Math.floor(p)]<a,f=()=>new y("QRToCoze: no QR code found.",k.QRInvalid),d=-1,c=-1;for(let p=0;p<l&&c===-1;p++)for(let g=0;g<e;g++)if(i(g,p)){d=g,c=p;break}if(c===-1)throw f();let w=0;for(;d+w<e&&i(d+w,c);)w++;let s=w/7,x=c+s/2,b=e-1;for(;b>d&&!i(b,x);)b--;let h=Math.round((b-d+1)/s),u=(h-17)/4;if(!Number.isInteger(u)||u<1||u>40||(s=(b-d+1)/h,c+h*s>l))throw f();let m=T(u);for(let p=0;p<h;p++)for(let g=0;g<h;g++)m.modules[p][g]=i(d+(g+.5)*s,c+(p+.5)*s);return K(m)}function K(t){let e=h=>new y("QRToCoze: "+h,k.QRInvalid),l=null;for(const h of E(t.size)){let u=0;for(let m=0;m<15;m++)u|=(t.modules[h[m][1]][h[m][0]]?1:0)<<m;for(const m of Object.keys(M))for(let p=0;p<8;p++){let g=z(M[m],p)^u,R=0;for(;g!==0;g&=g-1)R++;(l===null||R<l.dist)&&(l={ecl:M[m],mask:p,dist:R})}}if(l.dist>3)throw e("unreadable format.");C(t,l.mask);let n=H(t),o=new Uint8Array(Math.floor(F(t.version)/8));for(let h=0;h<o.length*8;h++)t.modules[n[h][1]][n[h][0]]&&(o[h>>>3]|=1<<7-(h&7));let r=W(t.version,l.ecl),a=[];for(let h=0;h<r.numBlocks;h++)a.push([]);let i=0;for(let h=0;h<=r.shortDataLen;h++)for(let u=0;u<r.numBlocks;u++)(h<r.shortDataLen||u>=r.numShort)&&a[u].push(o[i++]);let f=P(r.eccLen),d=[],c=i;for(let h=0;h<r.numBlocks;h++){let u=[];for(let m=0;m<r.eccLen;m++)u.push(o[c+m*r.numBlocks+h]);if(U(a[h],f).some((m,p)=>m!==u[p]))throw e("damaged QR code.");d.push(...a[h])}let w=0,s=h=>{let u=0;for(let m=0;m<h;m++,w++)u=u<<1|d[w>>>3]>>>7-(w&7)&1;return u},x="0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:",b=[];for(;w+4<=d.length*8;){let h=s(4);if(h===0)break;if(h===4){let u=s(A(t.version));for(let m=0;m<u;m++)b.push(s(8))}else if(h===2){let u=s(t.version<10?9:t.version<27?11:13);for(;u>=2;u-=2){let m=s(11);b.push(x.charCodeAt(Math.floor(m/45)),x.charCodeAt(m%45))}u===1&&b.push(x.charCodeAt(s(6)))}else if(h===1){let u=s(t.version<10?10:t.version<27?12:14);for(;u>=3;u-=3)b.push(...new TextEncoder().encode(String(s(10)).padStart(3,"0")));u>0&&b.push(...new TextEncoder().encode(String(s(u===2?7:4)).padStart(u,"0")))}else if(h===7)s(8);else throw e("unsupported QR mode "+h+".")}return new TextDecoder().decode(Uint8Array.from(b))}export{N as CozeToQR,I as QRMatrix,v as QRText,J as QRToCoze};
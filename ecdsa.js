@@ -0,0 +1,590 @@
+"use strict";
+
+import * as Alg from './alg.js';
+import * as Conv from './conversion.js';
+import * as Hash from './hash.js';
+import {
+	CozeAlgError,
+	CozeKeyError,
+	ErrCodes,
+} from './error.js';
+
+export {
+	ECDSA,
+}
+
+/**
+@typedef {import('./typedef.js').Alg}      Alg
+@typedef {import('./typedef.js').Key}      Key
+@typedef {import('./typedef.js').Hsh}      Hsh
+*/
+
+// ECDSA in Javascript.  SubtleCrypto does not implement P-224, so ES224 uses
+// this implementation.  Curve parameters are from FIPS 186-4 Appendix D.1.2
+// (SEC 2).  All curves have `a = -3`.
+//
+// ⚠️ Javascript is not constant time.  See the README.
+const curves = {
+	"ES224": {
+		p: BigInt("0xffffffffffffffffffffffffffffffff000000000000000000000001"),
+		b: BigInt("0xb4050a850c04b3abf54132565044b0b7d7bfd8ba270b39432355ffb4"),
+		gx: BigInt("0xb70e0cbd6bb4bf7f321390b94a03c1d356c21122343280d6115c1d21"),
+		gy: BigInt("0xbd376388b5f723fb4c22dfe6cd4375a05a07476444d5819985007e34"),
+	},
+	"ES256": {
+		p: BigInt("0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),
+		b: BigInt("0x5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),
+		gx: BigInt("0x6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),
+		gy: BigInt("0x4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5"),
+	},
+	"ES384": {
+		p: BigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff"),
+		b: BigInt("0xb3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef"),
+		gx: BigInt("0xaa87ca22be8b05378eb1c71ef320ad746e1d3b628ba79b9859f741e082542a385502f25dbf55296c3a545e3872760ab7"),
+		gy: BigInt("0x3617de4a96262c6f5d9e98bf9292dc29f8f41dbd289a147ce9da3113b5f0b8c00a60b1ce1d7e819d7a431d7c90ea0e5f"),
+	},
+	"ES512": {
+		p: (1n << 521n) - 1n,
+		b: BigInt("0x0051953eb9618e1c9a1f929a21a0b68540eea2da725b99b315f3b8b489918ef109e156193951ec7e937b1652c0bd3bb1bf073573df883d2c34f1ef451fd46b503f00"),
+		gx: BigInt("0x00c6858e06b70404e9cd9e3ecb662395b4429c648139053fb521f828af606b4d3dbaa14b5e77efe75928fe1dc127a2ffa8de3348b3c1856a429bf97e7e31c2e5bd66"),
+		gy: BigInt("0x011839296a789a3bc0045c8a5fb42c7d1bd998f54449579b446817afbd17273e662c97ee72995ef42640c550b9013fad0761353c7086a272c24088be94769fd16650"),
+	},
+}
+
+var ECDSA = {
+	/**
+	New returns a Javascript ECDSA key pair in the form of CryptoKeyPair.  The
+	keys are not SubtleCrypto CryptoKeys.  See `FromCozeKey`.
+	@param   {Alg}     alg
+	@returns {object}  {privateKey, publicKey}
+	@throws  {error}
+	*/
+	New: async function(alg) {
+		let d = randomScalar(alg);
+		let cozeKey = {
+			alg: alg,
+			d: Conv.ArrayBufferTo64ut(bigIntToBytes(Alg.DSize(alg), d)),
+			x: await ECDSA.PublicFromD(alg, d),
+		};
+		return {
+			privateKey: await ECDSA.FromCozeKey(cozeKey),
+			publicKey: await ECDSA.FromCozeKey(cozeKey, true),
+		};
+	},
+
+	/**
+	FromCozeKey returns a Javascript ECDSA key from a Coze key. The returned
+	object mimics a CryptoKey (`type`, `algorithm`, `usages`) so that it may be
+	used in place of a SubtleCrypto CryptoKey by the functions in `cryptokey.js`.
+	@param   {Key}        cozeKey
+	@param   {boolean}    [onlyPublic=false]   Return only a public key.
+	@returns {object}
+	@throws  {error}      Fails on invalid keys.
+	*/
+	FromCozeKey: async function(cozeKey, onlyPublic) {
+		let c = curve(cozeKey.alg);
+		let xy = Conv.B64ToUint8Array(cozeKey.x);
+		if (xy.length !== Alg.XSize(cozeKey.alg)) {
+			throw new CozeKeyError("ECDSA.FromCozeKey: incorrect x size.", ErrCodes.KeyInvalid, {
+				field: "x"
+			});
+		}
+		let half = Alg.XSize(cozeKey.alg) / 2;
+		let point = {
+			x: bytesToBigInt(xy.slice(0, half)),
+			y: bytesToBigInt(xy.slice(half)),
+		};
+		if (!onCurve(c, point)) {
+			throw new CozeKeyError("ECDSA.FromCozeKey: the key is not on the curve.", ErrCodes.KeyInvalid, {
+				field: "x"
+			});
+		}
+
+		let key = {
+			type: "public",
+			extractable: true,
+			algorithm: {
+				name: Alg.GenAlgs.ECDSA,
+				namedCurve: Alg.Curve(cozeKey.alg),
+			},
+			usages: ["verify"],
+			ecdsa: {
+				alg: cozeKey.alg,
+				point: point,
+			},
+		};
+		if (!Conv.isEmpty(cozeKey.d) && !onlyPublic) {
+			let d = bytesToBigInt(Conv.B64ToUint8Array(cozeKey.d));
+			if (d <= 0n || d >= c.n) {
+				throw new CozeKeyError("ECDSA.FromCozeKey: invalid private key.", ErrCodes.KeyInvalid, {
+					field: "d"
+				});
+			}
+			key.type = "private";
+			key.usages = ["sign"];
+			key.ecdsa.d = d;
+		}
+		return key;
+	},
+
+	/**
+	IsKey reports whether the given key is a Javascript ECDSA key.
+	@param   {CryptoKey|object}  key
+	@returns {boolean}
+	*/
+	IsKey: function(key) {
+		return typeof key === "object" && key !== null && typeof key.ecdsa === "object";
+	},
+
+	/**
+	ToCozeKey returns a Coze key, without `tmb`, from a Javascript ECDSA key.
+	@param   {object}   key
+	@returns {Key}
+	*/
+	ToCozeKey: function(key) {
+		let alg = key.ecdsa.alg;
+		let size = Alg.XSize(alg) / 2;
+		let czk = {
+			alg: alg,
+			x: Conv.ArrayBufferTo64ut(concat(bigIntToBytes(size, key.ecdsa.point.x), bigIntToBytes(size, key.ecdsa.point.y))),
+		};
+		if (key.ecdsa.d !== undefined) {
+			czk.d = Conv.ArrayBufferTo64ut(bigIntToBytes(Alg.DSize(alg), key.ecdsa.d));
+		}
+		return czk;
+	},
+
+	/**
+	PublicFromD calculates the b64ut `x` (x || y) from the private scalar `d`.
+	@param   {Alg}             alg
+	@param   {BigInt}          d
+	@returns {string}
+	@throws  {error}
+	*/
+	PublicFromD: async function(alg, d) {
+		let c = curve(alg);
+		let p = toAffine(c, scalarMult(c, d, {
+			x: c.gx,
+			y: c.gy,
+			z: 1n,
+		}));
+		let size = Alg.XSize(alg) / 2;
+		return Conv.ArrayBufferTo64ut(concat(bigIntToBytes(size, p.x), bigIntToBytes(size, p.y)));
+	},
+
+	/**
+	KeyFromSeed derives a private Coze key, without `tmb`, from seed.  The
+	private scalar `d` is derived with HKDF (RFC 5869) using alg's hashing
+	algorithm, salt "Coze NewKeyFromSeed", and info alg || counter, where
+	counter is a single byte starting at 0.  Each candidate is DSize bytes with
+	bits beyond the order's bit length discarded, and is rejected if not in
+	[1, n-1], in which case counter is incremented.  This gives a uniformly
+	distributed `d` that is the same in every implementation.
+	@param   {Alg}          alg
+	@param   {Uint8Array}   seed
+	@returns {Key}
+	@throws  {error}
+	*/
+	KeyFromSeed: async function(alg, seed) {
+		let c = curve(alg);
+		let hsh = Alg.HashAlg(alg);
+		let size = Math.ceil(c.nBits / 8);
+		let excess = BigInt(size * 8 - c.nBits);
+		let prk = await Hash.HMAC(hsh, new TextEncoder().encode("Coze NewKeyFromSeed"), seed);
+		for (let counter = 0; counter < 256; counter++) {
+			let info = concat(new TextEncoder().encode(alg), new Uint8Array([counter]));
+			let d = bytesToBigInt(await hkdfExpand(hsh, prk, info, size)) >> excess;
+			if (d > 0n && d < c.n) {
+				return {
+					alg: alg,
+					d: Conv.ArrayBufferTo64ut(bigIntToBytes(Alg.DSize(alg), d)),
+					x: await ECDSA.PublicFromD(alg, d),
+				};
+			}
+		}
+		throw new CozeKeyError("ECDSA.KeyFromSeed: no valid scalar derived.", ErrCodes.KeyInvalid, {
+			field: "seed"
+		}); // Practically impossible.
+	},
+
+	/**
+	SignBuffer hashes the message using alg's hashing algorithm and signs the
+	digest.  Returns the signature (r || s) as an ArrayBuffer.
+	@param   {object}        key              Private Javascript ECDSA key.
+	@param   {ArrayBuffer}   buffer           Message.
+	@param   {boolean}       [deterministic]  Use RFC 6979 nonces.
+	@returns {ArrayBuffer}
+	@throws  {error}
+	*/
+	SignBuffer: async function(key, buffer, deterministic) {
+		let alg = key.ecdsa.alg;
+		let dig = await Hash.Digest(Alg.HashAlg(alg), buffer);
+		return ECDSA.SignDigest(key, new Uint8Array(dig), deterministic);
+	},
+
+	/**
+	SignDigest signs a digest.  The digest is not hashed again.  Returns the
+	signature (r || s) as an ArrayBuffer.  If deterministic, the nonce `k` is
+	derived from the private key and the digest as specified by RFC 6979 using
+	alg's hashing algorithm, otherwise `k` is random.
+	@param   {object}      key              Private Javascript ECDSA key.
+	@param   {Uint8Array}  digest
+	@param   {boolean}     [deterministic]  Use RFC 6979 nonces.
+	@returns {ArrayBuffer}
+	@throws  {error}
+	*/
+	SignDigest: async function(key, digest, deterministic) {
+		if (key.type !== "private") {
+			throw new CozeKeyError("ECDSA.SignDigest: key must be private.", ErrCodes.KeyInvalid, {
+				field: "d"
+			});
+		}
+		let alg = key.ecdsa.alg;
+		let c = curve(alg);
+		let e = bits2int(c, digest);
+		let nonce = null;
+		if (deterministic === true) {
+			nonce = await rfc6979(alg, key.ecdsa.d, digest);
+		}
+		for (;;) {
+			let k = nonce === null ? randomScalar(alg) : await nonce.next();
+			let r = mod(toAffine(c, scalarMult(c, k, {
+				x: c.gx,
+				y: c.gy,
+				z: 1n,
+			})).x, c.n);
+			if (r === 0n) {
+				continue;
+			}
+			let s = mod(modInv(k, c.n) * (e + r * key.ecdsa.d), c.n);
+			if (s === 0n) {
+				continue;
+			}
+			let half = Alg.SigSize(alg) / 2;
+			return concat(bigIntToBytes(half, r), bigIntToBytes(half, s)).buffer;
+		}
+	},
+
+	/**
+	VerifyBuffer hashes the message using alg's hashing algorithm and verifies
+	the signature over the digest.
+	@param   {object}        key      Javascript ECDSA key.
+	@param   {ArrayBuffer}   buffer   Message.
+	@param   {ArrayBuffer}   sig      Signature (r || s).
+	@returns {boolean}
+	*/
+	VerifyBuffer: async function(key, buffer, sig) {
+		let dig = await Hash.Digest(Alg.HashAlg(key.ecdsa.alg), buffer);
+		return ECDSA.VerifyDigest(key, new Uint8Array(dig), sig);
+	},
+
+	/**
+	VerifyDigest verifies a signature over a digest.  The digest is not hashed
+	again.
+	@param   {object}        key      Javascript ECDSA key.
+	@param   {Uint8Array}    digest
+	@param   {ArrayBuffer}   sig      Signature (r || s).
+	@returns {boolean}
+	*/
+	VerifyDigest: async function(key, digest, sig) {
+		let alg = key.ecdsa.alg;
+		let c = curve(alg);
+		sig = new Uint8Array(sig);
+		if (sig.length !== Alg.SigSize(alg)) {
+			return false;
+		}
+		let half = Alg.SigSize(alg) / 2;
+		let r = bytesToBigInt(sig.slice(0, half));
+		let s = bytesToBigInt(sig.slice(half));
+		if (r <= 0n || r >= c.n || s <= 0n || s >= c.n) {
+			return false;
+		}
+		let e = bits2int(c, digest);
+		let w = modInv(s, c.n);
+		let u1 = scalarMult(c, mod(e * w, c.n), {
+			x: c.gx,
+			y: c.gy,
+			z: 1n,
+		});
+		let u2 = scalarMult(c, mod(r * w, c.n), {
+			x: key.ecdsa.point.x,
+			y: key.ecdsa.point.y,
+			z: 1n,
+		});
+		let p = pointAdd(c, u1, u2);
+		if (p.z === 0n) {
+			return false;
+		}
+		return mod(toAffine(c, p).x, c.n) === r;
+	},
+};
+
+
+/**
+curve returns the curve parameters for alg, including the order `n`.
+@param   {Alg}     alg
+@returns {object}
+@throws  {error}
+*/
+function curve(alg) {
+	let c = curves[alg];
+	if (c === undefined) {
+		throw new CozeAlgError("ECDSA: unsupported algorithm: " + alg, ErrCodes.AlgUnsupported, {
+			alg: alg
+		});
+	}
+	if (c.n === undefined) {
+		c.n = Alg.CurveOrder(alg);
+		c.nBits = c.n.toString(2).length;
+	}
+	return c;
+}
+
+/**
+randomScalar returns a random scalar in [1, n-1] using rejection sampling.
+@param   {Alg}     alg
+@returns {BigInt}
+*/
+function randomScalar(alg) {
+	let c = curve(alg);
+	let size = Math.ceil(c.nBits / 8);
+	let excess = BigInt(size * 8 - c.nBits);
+	for (;;) {
+		let k = bytesToBigInt(crypto.getRandomValues(new Uint8Array(size))) >> excess;
+		if (k > 0n && k < c.n) {
+			return k;
+		}
+	}
+}
+
+/**
+rfc6979 returns a deterministic nonce generator as specified by RFC 6979
+section 3.2.  `next()` returns the next candidate `k` in [1, n-1].  Calling
+`next()` again (when r or s is 0) continues the generation as in step h.3.
+@param   {Alg}         alg
+@param   {BigInt}      d        Private key.
+@param   {Uint8Array}  digest   Message digest (h1).
+@returns {object}
+*/
+async function rfc6979(alg, d, digest) {
+	let c = curve(alg);
+	let hsh = Alg.HashAlg(alg);
+	let rlen = Math.ceil(c.nBits / 8);
+	let hlen = Alg.HashSize(alg);
+	let hmac = (key, ...data) => Hash.HMAC(hsh, key, concatAll(data));
+
+	let x = bigIntToBytes(rlen, d);
+	let h = bigIntToBytes(rlen, mod(bits2int(c, digest), c.n)); // bits2octets
+	let v = new Uint8Array(hlen).fill(0x01);
+	let k = new Uint8Array(hlen);
+	k = await hmac(k, v, [0x00], x, h);
+	v = await hmac(k, v);
+	k = await hmac(k, v, [0x01], x, h);
+	v = await hmac(k, v);
+
+	let started = false;
+	return {
+		next: async function() {
+			for (;;) {
+				if (started) {
+					k = await hmac(k, v, [0x00]);
+					v = await hmac(k, v);
+				}
+				started = true;
+				let t = new Uint8Array(0);
+				while (t.length < rlen) {
+					v = await hmac(k, v);
+					t = concat(t, v);
+				}
+				let candidate = bits2int(c, t);
+				if (candidate > 0n && candidate < c.n) {
+					return candidate;
+				}
+			}
+		},
+	};
+}
+
+/**
+bits2int converts a digest to an integer using the leftmost bits of the
+digest as specified by FIPS 186-4 section 6.4.
+@param   {object}      c       Curve.
+@param   {Uint8Array}  digest
+@returns {BigInt}
+*/
+function bits2int(c, digest) {
+	let e = bytesToBigInt(digest);
+	let bits = digest.length * 8;
+	if (bits > c.nBits) {
+		e >>= BigInt(bits - c.nBits);
+	}
+	return e;
+}
+
+function mod(a, m) {
+	let r = a % m;
+	return r < 0n ? r + m : r;
+}
+
+// modInv returns the modular inverse using the extended Euclidean algorithm.
+function modInv(a, m) {
+	let [oldR, r] = [mod(a, m), m];
+	let [oldS, s] = [1n, 0n];
+	while (r !== 0n) {
+		let q = oldR / r;
+		[oldR, r] = [r, oldR - q * r];
+		[oldS, s] = [s, oldS - q * s];
+	}
+	if (oldR !== 1n) {
+		throw new CozeKeyError("ECDSA: no modular inverse.", ErrCodes.KeyInvalid);
+	}
+	return mod(oldS, m);
+}
+
+function onCurve(c, pt) {
+	if (pt.x < 0n || pt.x >= c.p || pt.y < 0n || pt.y >= c.p) {
+		return false;
+	}
+	// y^2 = x^3 - 3x + b
+	return mod(pt.y * pt.y - (pt.x * pt.x * pt.x - 3n * pt.x + c.b), c.p) === 0n;
+}
+
+// Points are in Jacobian coordinates.  The point at infinity has z = 0.
+function toAffine(c, pt) {
+	let zInv = modInv(pt.z, c.p);
+	let zInv2 = mod(zInv * zInv, c.p);
+	return {
+		x: mod(pt.x * zInv2, c.p),
+		y: mod(pt.y * zInv2 * zInv, c.p),
+	};
+}
+
+// pointDouble uses "dbl-2001-b" for a = -3.
+function pointDouble(c, pt) {
+	if (pt.z === 0n || pt.y === 0n) {
+		return {
+			x: 0n,
+			y: 1n,
+			z: 0n,
+		};
+	}
+	let p = c.p;
+	let delta = mod(pt.z * pt.z, p);
+	let gamma = mod(pt.y * pt.y, p);
+	let beta = mod(pt.x * gamma, p);
+	let alpha = mod(3n * (pt.x - delta) * (pt.x + delta), p);
+	let x = mod(alpha * alpha - 8n * beta, p);
+	let z = mod((pt.y + pt.z) * (pt.y + pt.z) - gamma - delta, p);
+	let y = mod(alpha * (4n * beta - x) - 8n * gamma * gamma, p);
+	return {
+		x: x,
+		y: y,
+		z: z,
+	};
+}
+
+// pointAdd uses "add-2007-bl".
+function pointAdd(c, p1, p2) {
+	if (p1.z === 0n) {
+		return p2;
+	}
+	if (p2.z === 0n) {
+		return p1;
+	}
+	let p = c.p;
+	let z1z1 = mod(p1.z * p1.z, p);
+	let z2z2 = mod(p2.z * p2.z, p);
+	let u1 = mod(p1.x * z2z2, p);
+	let u2 = mod(p2.x * z1z1, p);
+	let s1 = mod(p1.y * p2.z * z2z2, p);
+	let s2 = mod(p2.y * p1.z * z1z1, p);
+	let h = mod(u2 - u1, p);
+	let r = mod(2n * (s2 - s1), p);
+	if (h === 0n) {
+		if (r === 0n) {
+			return pointDouble(c, p1);
+		}
+		return {
+			x: 0n,
+			y: 1n,
+			z: 0n,
+		};
+	}
+	let i = mod(4n * h * h, p);
+	let j = mod(h * i, p);
+	let v = mod(u1 * i, p);
+	let x = mod(r * r - j - 2n * v, p);
+	let y = mod(r * (v - x) - 2n * s1 * j, p);
+	let z = mod(((p1.z + p2.z) * (p1.z + p2.z) - z1z1 - z2z2) * h, p);
+	return {
+		x: x,
+		y: y,
+		z: z,
+	};
+}
+
+// scalarMult uses double-and-add.
+function scalarMult(c, k, pt) {
+	let result = {
+		x: 0n,
+		y: 1n,
+		z: 0n,
+	};
+	for (let i = BigInt(k.toString(2).length - 1); i >= 0n; i--) {
+		result = pointDouble(c, result);
+		if ((k >> i) & 1n) {
+			result = pointAdd(c, result, pt);
+		}
+	}
+	return result;
+}
+
+/**
+hkdfExpand is HKDF-Expand (RFC 5869 section 2.3).
+@param   {Hsh}          hsh
+@param   {Uint8Array}   prk
+@param   {Uint8Array}   info
+@param   {number}       length   Output bytes.
+@returns {Uint8Array}
+*/
+async function hkdfExpand(hsh, prk, info, length) {
+	let out = new Uint8Array(0);
+	let t = new Uint8Array(0);
+	for (let i = 1; out.length < length; i++) {
+		t = await Hash.HMAC(hsh, prk, concatAll([t, info, [i]]));
+		out = concat(out, t);
+	}
+	return out.slice(0, length);
+}
+
+function bytesToBigInt(bytes) {
+	let result = 0n;
+	for (let b of bytes) {
+		result = (result << 8n) + BigInt(b);
+	}
+	return result;
+}
+
+function bigIntToBytes(size, n) {
+	let out = new Uint8Array(size);
+	for (let i = size - 1; i >= 0; i--) {
+		out[i] = Number(n & 0xffn);
+		n >>= 8n;
+	}
+	return out;
+}
+
+function concat(a, b) {
+	let out = new Uint8Array(a.length + b.length);
+	out.set(a, 0);
+	out.set(b, a.length);
+	return out;
+}
+
+function concatAll(arrays) {
+	let out = new Uint8Array(0);
+	for (let a of arrays) {
+		out = concat(out, new Uint8Array(a));
+	}
+	return out;
+}
@@ -0,0 +1,128 @@
+"use strict";
+
+export {
+	CozeError,
+	CozeKeyError,
+	CozeVerifyError,
+	CozeCanonError,
+	CozeAlgError,
+	ErrCodes,
+}
+
+/**
+ErrCodes are the stable error codes of Coze errors.  Codes, not messages,
+should be used for programmatic error handling as messages may change.
+
+- ERR_ALG_UNSUPPORTED:  alg is not supported.
+- ERR_ALG_MISMATCH:     Given alg does not match the key's or pay's alg.
+- ERR_TMB_MISMATCH:     Given tmb does not match the key's thumbprint.
+- ERR_KEY_INVALID:      Key is malformed or missing a required component.
+- ERR_KEY_REVOKED:      Key is revoked.
+- ERR_KEY_MISMATCH:     Keys are not a pair.
+- ERR_KEY_NOT_FOUND:    Keyring has no key for pay.tmb.
+- ERR_SIG_INVALID:      Signature is malformed or did not verify.
+- ERR_CANON_INVALID:    Canon is malformed.
+- ERR_CANON_MISSING:    Pay is missing field(s) required by canon.
+- ERR_CANON_EXTRA:      Pay has field(s) not in canon.
+- ERR_PAY_MISSING:      Coze has no pay.
+- ERR_PRV_MISMATCH:     pay.prv is not the czd of the previous coze in a chain.
+- ERR_THRESHOLD_INVALID: Multi-signature threshold is not an integer from 1 to
+                        the number of keys.
+- ERR_IAT_INVALID:      iat is not a non-negative integer, or mismatches.
+- ERR_EXPIRED:          iat is older than allowed by Verify's time options.
+- ERR_NOT_YET_VALID:    iat is newer than allowed by Verify's time options.
+- ERR_DIG_SIZE:         Digest is the wrong size for alg.
+- ERR_HASH_INVALID:     Hashing algorithm is not valid for alg.
+- ERR_DUPLICATE_FIELD:  JSON has a duplicate field name.
+- ERR_JSON_INVALID:     JSON is invalid.  `position` is set when known.
+- ERR_FIELD_RESERVED:   Field may not be given since it is set by Coze.
+- ERR_B64_INVALID:      Invalid or non-canonical b64ut.
+- ERR_HEX_INVALID:      Invalid hex.
+- ERR_QR_CAPACITY:      Too large for a QR code.  `size` and `max` are set.
+- ERR_QR_INVALID:       QR code could not be read, or QR option is invalid.
+- ERR_KEYSTORE_UNAVAILABLE: IndexedDB is unavailable for the keystore.
+- ERR_BROWSER_REQUIRED: Function requires a browser, e.g. `document`.
+*/
+const ErrCodes = {
+	AlgUnsupported: "ERR_ALG_UNSUPPORTED",
+	AlgMismatch: "ERR_ALG_MISMATCH",
+	TmbMismatch: "ERR_TMB_MISMATCH",
+	KeyInvalid: "ERR_KEY_INVALID",
+	KeyRevoked: "ERR_KEY_REVOKED",
+	KeyMismatch: "ERR_KEY_MISMATCH",
+	KeyNotFound: "ERR_KEY_NOT_FOUND",
+	SigInvalid: "ERR_SIG_INVALID",
+	CanonInvalid: "ERR_CANON_INVALID",
+	CanonMissing: "ERR_CANON_MISSING",
+	CanonExtra: "ERR_CANON_EXTRA",
+	PayMissing: "ERR_PAY_MISSING",
+	PrvMismatch: "ERR_PRV_MISMATCH",
+	ThresholdInvalid: "ERR_THRESHOLD_INVALID",
+	IatInvalid: "ERR_IAT_INVALID",
+	Expired: "ERR_EXPIRED",
+	NotYetValid: "ERR_NOT_YET_VALID",
+	DigSize: "ERR_DIG_SIZE",
+	HashInvalid: "ERR_HASH_INVALID",
+	DuplicateField: "ERR_DUPLICATE_FIELD",
+	JSONInvalid: "ERR_JSON_INVALID",
+	FieldReserved: "ERR_FIELD_RESERVED",
+	B64Invalid: "ERR_B64_INVALID",
+	HexInvalid: "ERR_HEX_INVALID",
+	QRCapacity: "ERR_QR_CAPACITY",
+	QRInvalid: "ERR_QR_INVALID",
+	KeystoreUnavailable: "ERR_KEYSTORE_UNAVAILABLE",
+	BrowserRequired: "ERR_BROWSER_REQUIRED",
+};
+
+/**
+CozeError is the base class of Coze errors.  `code` is one of ErrCodes.
+Context relevant to the error, like `field` or `alg`, is set on the error.
+*/
+class CozeError extends Error {
+	/**
+	@param {string}  message   Human readable message.
+	@param {string}  code      ErrCodes code.
+	@param {object}  [context] e.g. {field:"x"} or {alg:"ES256"}.
+	*/
+	constructor(message, code, context) {
+		super(message);
+		// Names are set explicitly since minification renames classes.
+		this.name = "CozeError";
+		this.code = code;
+		if (context !== undefined) {
+			Object.assign(this, context);
+		}
+	}
+}
+
+// CozeKeyError is for malformed, revoked, or mismatched keys.
+class CozeKeyError extends CozeError {
+	constructor(message, code, context) {
+		super(message, code, context);
+		this.name = "CozeKeyError";
+	}
+}
+
+// CozeVerifyError is for cozies that are malformed or fail verification checks.
+class CozeVerifyError extends CozeError {
+	constructor(message, code, context) {
+		super(message, code, context);
+		this.name = "CozeVerifyError";
+	}
+}
+
+// CozeCanonError is for malformed canons and pays not matching a canon.
+class CozeCanonError extends CozeError {
+	constructor(message, code, context) {
+		super(message, code, context);
+		this.name = "CozeCanonError";
+	}
+}
+
+// CozeAlgError is for unsupported or mismatched algs.
+class CozeAlgError extends CozeError {
+	constructor(message, code, context) {
+		super(message, code, context);
+		this.name = "CozeAlgError";
+	}
+}
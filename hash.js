@@ -0,0 +1,804 @@
+"use strict";
+
+import * as Alg from './alg.js';
+import {
+	isEmpty,
+	SToArrayBuffer,
+	ArrayBufferTo64ut,
+} from './conversion.js';
+
+import {
+	CozeAlgError,
+	ErrCodes,
+} from './error.js';
+
+export {
+	Digest,
+	HMAC,
+	Hash,
+	HashStream,
+	DigestPayField,
+	DigestFiles,
+	DigestPayFile,
+	MatchPayFile,
+}
+
+/**
+@typedef {import('./typedef.js').Hsh}     Hsh
+@typedef {import('./typedef.js').Alg}     Alg
+@typedef {import('./typedef.js').B64}     B64
+@typedef {import('./typedef.js').Pay}     Pay
+@typedef {import('./typedef.js').Dig}     Dig
+*/
+
+/**
+FileDig is the digest of a file as set in `pay.file` by DigestPayFile.
+@typedef  {object}  FileDig
+@property {string}  name   File name, or "" for Blobs without a name.
+@property {number}  size   Size in bytes.
+@property {Dig}     dig
+*/
+
+/**
+FileMatch is the result of MatchPayFile for a single file.  match is true if
+an entry of `pay.file` has the file's dig and, if the entry has a size, the
+file's size.
+@typedef  {object}   FileMatch
+@property {string}   name
+@property {number}   size
+@property {Dig}      dig
+@property {boolean}  match
+*/
+
+/**
+FilesOpts are the options for DigestFiles, DigestPayFile, and MatchPayFile.
+Files are hashed with HashStream.
+
+- chunkSize:   See HashStream.
+- onProgress:  Called with the bytes processed of all files and the total
+               bytes of all files.
+- signal:      AbortSignal.  See HashStream.
+@typedef  {object}       FilesOpts
+@property {number}       [chunkSize]
+@property {function(number, number)} [onProgress]
+@property {AbortSignal}  [signal]
+*/
+
+/**
+Digest returns the digest of the given bytes using hashing algorithm `hsh`.
+SubtleCrypto is used for all hashing algorithms it supports.  SubtleCrypto
+does not support SHA-224, SHA-3, or SHAKE, so they are implemented in
+Javascript.  SHAKE128 and SHAKE256 output Coze's HashSize, 32 and 64 bytes.
+@param   {Hsh}          hsh     Hashing algorithm, e.g. "SHA-256".
+@param   {ArrayBuffer|Uint8Array} buffer  Bytes to hash.
+@returns {ArrayBuffer}
+@throws  {error}                Fails on empty or unsupported hsh.
+*/
+async function Digest(hsh, buffer) {
+	if (isEmpty(hsh)) {
+		throw new CozeAlgError("Hash is not given", ErrCodes.AlgUnsupported);
+	}
+	if (hsh === Alg.Algs.SHA224) {
+		let h = newSHA256(true);
+		h.update(new Uint8Array(buffer));
+		return h.digest().buffer;
+	}
+	if (sha3Params[hsh] !== undefined) {
+		let h = newSHA3(hsh);
+		h.update(new Uint8Array(buffer));
+		return h.digest().buffer;
+	}
+	return crypto.subtle.digest(hsh, buffer);
+}
+
+/**
+HMAC returns the HMAC (RFC 2104) of data using hashing algorithm `hsh`.  HMAC is
+built on Digest so that all hashing algorithms supported by Digest, including
+SHA-224, are supported.
+@param   {Hsh}          hsh     Hashing algorithm, e.g. "SHA-256".
+@param   {Uint8Array}   key
+@param   {Uint8Array}   data
+@returns {Uint8Array}
+@throws  {error}                Fails on empty or unsupported hsh.
+*/
+async function HMAC(hsh, key, data) {
+	let blockSize = (hsh === Alg.Algs.SHA384 || hsh === Alg.Algs.SHA512) ? 128 : 64;
+	if (key.length > blockSize) {
+		key = new Uint8Array(await Digest(hsh, key));
+	}
+	let ipad = new Uint8Array(blockSize + data.length);
+	let opad = new Uint8Array(blockSize);
+	for (let i = 0; i < blockSize; i++) {
+		let b = i < key.length ? key[i] : 0;
+		ipad[i] = b ^ 0x36;
+		opad[i] = b ^ 0x5c;
+	}
+	ipad.set(data, blockSize);
+	let inner = new Uint8Array(await Digest(hsh, ipad));
+	let outer = new Uint8Array(blockSize + inner.length);
+	outer.set(opad);
+	outer.set(inner, blockSize);
+	return new Uint8Array(await Digest(hsh, outer));
+}
+
+
+/**
+Hash returns the b64ut digest of input using the hashing algorithm of alg (See
+Alg.HashAlg), e.g. SHA-256 for "ES256" or "SHA-256".  input may be a string
+(hashed as UTF-8), Uint8Array, ArrayBuffer, Blob, or File.  Blobs and Files
+are read with arrayBuffer() and are not converted to strings.  Empty input
+results in the digest of the empty message.
+@param   {Alg}     alg     Alg or hashing algorithm, e.g. "ES256" or "SHA-256".
+@param   {string|Uint8Array|ArrayBuffer|Blob} input
+@returns {B64}
+@throws  {error}           Fails on unsupported alg or input type.
+*/
+async function Hash(alg, input) {
+	let hsh = Alg.HashAlg(alg);
+	let buffer;
+	if (typeof input === "string") {
+		buffer = await SToArrayBuffer(input);
+	} else if (input instanceof Uint8Array || input instanceof ArrayBuffer) {
+		buffer = input;
+	} else if (typeof Blob !== "undefined" && input instanceof Blob) {
+		buffer = await input.arrayBuffer();
+	} else {
+		throw new TypeError("Hash: input must be a string, Uint8Array, ArrayBuffer, or Blob.");
+	}
+	return ArrayBufferTo64ut(await Digest(hsh, buffer));
+}
+
+/**
+HashStream returns the b64ut digest of a Blob, File, or ReadableStream of
+Uint8Array chunks, hashed incrementally so that very large files are never
+entirely in memory.  SubtleCrypto cannot digest incrementally, so hashing is
+done in Javascript.  All Coze hashing algorithms (SHA-2, SHA-3, and SHAKE) are
+supported.  The digest is identical to Hash for the same input.
+
+- chunkSize:   Bytes read from a Blob at a time.  Default 4 MiB.  Streams are
+               hashed in the chunks they produce.
+- onProgress:  Called with the total bytes processed after each chunk.
+- signal:      AbortSignal.  On abort, reading stops before the next chunk and
+               signal.reason is thrown.
+@param   {Alg}     alg     Alg or hashing algorithm, e.g. "ES256" or "SHA-256".
+@param   {Blob|ReadableStream} input
+@param   {{chunkSize: number, onProgress: function(number), signal: AbortSignal}} [opts]
+@returns {B64}
+@throws  {error}           Fails on unsupported alg, input type, or abort.
+*/
+async function HashStream(alg, input, opts) {
+	let hsh = Alg.HashAlg(alg);
+	let h;
+	switch (hsh) {
+		case Alg.Algs.SHA224:
+		case Alg.Algs.SHA256:
+			h = newSHA256(hsh === Alg.Algs.SHA224);
+			break;
+		case Alg.Algs.SHA384:
+		case Alg.Algs.SHA512:
+			h = newSHA512(hsh === Alg.Algs.SHA384);
+			break;
+		case Alg.Algs.SHA3224:
+		case Alg.Algs.SHA3256:
+		case Alg.Algs.SHA3384:
+		case Alg.Algs.SHA3512:
+		case Alg.Algs.SHAKE128:
+		case Alg.Algs.SHAKE256:
+			h = newSHA3(hsh);
+			break;
+		default:
+			throw new CozeAlgError("HashStream: unsupported hashing algorithm: " + hsh, ErrCodes.AlgUnsupported, {
+				alg: alg
+			});
+	}
+	if (isEmpty(opts)) {
+		opts = {};
+	}
+	let chunkSize = 4 * 1024 * 1024;
+	if (opts.chunkSize > 0) {
+		chunkSize = opts.chunkSize;
+	}
+	let abort = function() {
+		if (opts.signal !== undefined && opts.signal.aborted) {
+			throw opts.signal.reason;
+		}
+	};
+	let n = 0;
+	let chunk = function(data) {
+		h.update(data);
+		n += data.length;
+		if (typeof opts.onProgress === "function") {
+			opts.onProgress(n);
+		}
+	};
+
+	if (typeof Blob !== "undefined" && input instanceof Blob) {
+		for (let off = 0; off < input.size; off += chunkSize) {
+			abort();
+			chunk(new Uint8Array(await input.slice(off, off + chunkSize).arrayBuffer()));
+		}
+	} else if (typeof ReadableStream !== "undefined" && input instanceof ReadableStream) {
+		let reader = input.getReader();
+		try {
+			for (;;) {
+				abort();
+				let r = await reader.read();
+				if (r.done) {
+					break;
+				}
+				chunk(r.value);
+			}
+		} catch (e) {
+			await reader.cancel(e);
+			throw e;
+		}
+	} else {
+		throw new TypeError("HashStream: input must be a Blob or ReadableStream.");
+	}
+	abort();
+	return ArrayBufferTo64ut(h.digest());
+}
+
+/**
+DigestPayField sets pay[fieldName] to the b64ut digest (See Hash) of file, for
+the common pattern of hashing a file and signing its digest.  If given,
+opts.sizeField and opts.nameField are field names set to the file's size in
+bytes and name (Files only), e.g. `{sizeField:"size", nameField:"file_name"}`.
+Returns the same, but updated, pay.
+@param   {Pay}     pay
+@param   {string}  fieldName  e.g. "file_dig".
+@param   {string|Uint8Array|ArrayBuffer|Blob} file
+@param   {Alg}     alg        Alg or hashing algorithm, e.g. "ES256" or "SHA-256".
+@param   {{sizeField: string, nameField: string}} [opts]
+@returns {Pay}
+@throws  {error}
+*/
+async function DigestPayField(pay, fieldName, file, alg, opts) {
+	pay[fieldName] = await Hash(alg, file);
+	if (isEmpty(opts)) {
+		return pay;
+	}
+	if (!isEmpty(opts.sizeField)) {
+		if (typeof file === "string") {
+			pay[opts.sizeField] = (await SToArrayBuffer(file)).byteLength;
+		} else if (typeof Blob !== "undefined" && file instanceof Blob) {
+			pay[opts.sizeField] = file.size;
+		} else {
+			pay[opts.sizeField] = file.byteLength;
+		}
+	}
+	if (!isEmpty(opts.nameField) && !isEmpty(file.name)) {
+		pay[opts.nameField] = file.name;
+	}
+	return pay;
+}
+
+
+/**
+DigestFiles returns the FileDig of each file, hashed in order with HashStream
+so that large files work.  files may be a single File or Blob, an array, or a
+FileList.
+@param   {Blob|Blob[]|FileList}  files
+@param   {Alg}        alg     Alg or hashing algorithm, e.g. "ES256" or "SHA-256".
+@param   {FilesOpts}  [opts]
+@returns {FileDig[]}
+@throws  {error}              Fails on unsupported alg, input type, or abort.
+*/
+async function DigestFiles(files, alg, opts) {
+	if (typeof Blob !== "undefined" && files instanceof Blob) {
+		files = [files];
+	}
+	files = Array.from(files);
+	if (isEmpty(opts)) {
+		opts = {};
+	}
+	let total = files.reduce((t, f) => t + f.size, 0);
+	let done = 0;
+	let digs = [];
+	for (const f of files) {
+		let dig = await HashStream(alg, f, {
+			chunkSize: opts.chunkSize,
+			signal: opts.signal,
+			onProgress: function(n) {
+				if (typeof opts.onProgress === "function") {
+					opts.onProgress(done + n, total);
+				}
+			},
+		});
+		done += f.size;
+		digs.push({
+			name: isEmpty(f.name) ? "" : f.name,
+			size: f.size,
+			dig: dig,
+		});
+	}
+	return digs;
+}
+
+/**
+DigestPayFile sets `pay.file` to the FileDig (`{name, size, dig}`) of file,
+for "proving I had this file".  If files is an array or FileList, `pay.file`
+is an array of FileDig, even for a single file.  An existing `pay.file` is
+replaced.  Returns the same, but updated, pay.  See MatchPayFile.
+@param   {Pay}        pay
+@param   {Blob|Blob[]|FileList}  files
+@param   {Alg}        alg     Alg or hashing algorithm, e.g. "ES256" or "SHA-256".
+@param   {FilesOpts}  [opts]
+@returns {Pay}
+@throws  {error}
+*/
+async function DigestPayFile(pay, files, alg, opts) {
+	let digs = await DigestFiles(files, alg, opts);
+	pay.file = (typeof Blob !== "undefined" && files instanceof Blob) ? digs[0] : digs;
+	return pay;
+}
+
+/**
+MatchPayFile compares local files against `pay.file` as set by DigestPayFile.
+Each file matches if any entry of `pay.file` has its digest (and size, if the
+entry has a size).  Names are not compared since files may be renamed.  match
+is true if all files match.  For a signed coze, first verify the coze, and
+use alg of the coze's pay.
+@param   {Pay}        pay
+@param   {Blob|Blob[]|FileList}  files
+@param   {Alg}        alg     Alg or hashing algorithm, e.g. "ES256" or "SHA-256".
+@param   {FilesOpts}  [opts]
+@returns {{match: boolean, files: FileMatch[]}}
+@throws  {error}
+*/
+async function MatchPayFile(pay, files, alg, opts) {
+	let entries = [];
+	if (Array.isArray(pay.file)) {
+		entries = pay.file;
+	} else if (typeof pay.file === "object" && pay.file !== null) {
+		entries = [pay.file];
+	}
+	let digs = await DigestFiles(files, alg, opts);
+	let results = digs.map(d => ({
+		...d,
+		match: entries.some(e => e !== null && e.dig === d.dig && (e.size === undefined || e.size === d.size)),
+	}));
+	return {
+		match: results.length > 0 && results.every(r => r.match),
+		files: results,
+	};
+}
+
+///////////////////////////////////
+// SHA-224/SHA-256 (FIPS 180-4)
+///////////////////////////////////
+
+const k256 = new Uint32Array([
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+]);
+
+const iv224 = [0xc1059ed8, 0x367cd507, 0x3070dd17, 0xf70e5939, 0xffc00b31, 0x68581511, 0x64f98fa7, 0xbefa4fa4];
+const iv256 = [0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19];
+
+/**
+newSHA256 returns an incremental SHA-256 (or SHA-224) hasher with the
+methods `update(Uint8Array)` and `digest()`.  `digest()` returns a Uint8Array
+and may only be called once.
+@param   {boolean}  is224   Use SHA-224 instead of SHA-256.
+@returns {object}
+*/
+function newSHA256(is224) {
+	let h = new Uint32Array(is224 ? iv224 : iv256);
+	let w = new Uint32Array(64);
+	let block = new Uint8Array(64);
+	let blockLen = 0;
+	let total = 0; // Bytes processed.
+
+	let compress = function(b, off) {
+		for (let i = 0; i < 16; i++) {
+			w[i] = (b[off + 4 * i] << 24) | (b[off + 4 * i + 1] << 16) | (b[off + 4 * i + 2] << 8) | b[off + 4 * i + 3];
+		}
+		for (let i = 16; i < 64; i++) {
+			let w15 = w[i - 15];
+			let w2 = w[i - 2];
+			let s0 = ((w15 >>> 7) | (w15 << 25)) ^ ((w15 >>> 18) | (w15 << 14)) ^ (w15 >>> 3);
+			let s1 = ((w2 >>> 17) | (w2 << 15)) ^ ((w2 >>> 19) | (w2 << 13)) ^ (w2 >>> 10);
+			w[i] = (w[i - 16] + s0 + w[i - 7] + s1) | 0;
+		}
+		let a = h[0], b1 = h[1], c = h[2], d = h[3], e = h[4], f = h[5], g = h[6], hh = h[7];
+		for (let i = 0; i < 64; i++) {
+			let S1 = ((e >>> 6) | (e << 26)) ^ ((e >>> 11) | (e << 21)) ^ ((e >>> 25) | (e << 7));
+			let ch = (e & f) ^ (~e & g);
+			let t1 = (hh + S1 + ch + k256[i] + w[i]) | 0;
+			let S0 = ((a >>> 2) | (a << 30)) ^ ((a >>> 13) | (a << 19)) ^ ((a >>> 22) | (a << 10));
+			let maj = (a & b1) ^ (a & c) ^ (b1 & c);
+			let t2 = (S0 + maj) | 0;
+			hh = g;
+			g = f;
+			f = e;
+			e = (d + t1) | 0;
+			d = c;
+			c = b1;
+			b1 = a;
+			a = (t1 + t2) | 0;
+		}
+		h[0] += a;
+		h[1] += b1;
+		h[2] += c;
+		h[3] += d;
+		h[4] += e;
+		h[5] += f;
+		h[6] += g;
+		h[7] += hh;
+	};
+
+	return {
+		update: function(data) {
+			total += data.length;
+			let i = 0;
+			if (blockLen > 0) {
+				while (blockLen < 64 && i < data.length) {
+					block[blockLen++] = data[i++];
+				}
+				if (blockLen < 64) {
+					return;
+				}
+				compress(block, 0);
+				blockLen = 0;
+			}
+			for (; i + 64 <= data.length; i += 64) {
+				compress(data, i);
+			}
+			while (i < data.length) {
+				block[blockLen++] = data[i++];
+			}
+		},
+		digest: function() {
+			let bits = total * 8;
+			block[blockLen++] = 0x80;
+			if (blockLen > 56) {
+				block.fill(0, blockLen);
+				compress(block, 0);
+				blockLen = 0;
+			}
+			block.fill(0, blockLen);
+			// Message length in bits, big endian.  Javascript numbers are safe up to
+			// 2^53, which is plenty.
+			let view = new DataView(block.buffer);
+			view.setUint32(56, Math.floor(bits / 0x100000000));
+			view.setUint32(60, bits >>> 0);
+			compress(block, 0);
+
+			let out = new Uint8Array(32);
+			let outView = new DataView(out.buffer);
+			for (let i = 0; i < 8; i++) {
+				outView.setUint32(4 * i, h[i]);
+			}
+			return is224 ? out.slice(0, 28) : out;
+		},
+	};
+}
+
+
+///////////////////////////////////
+// SHA-384/SHA-512 (FIPS 180-4)
+///////////////////////////////////
+
+// 64 bit words are stored as [high, low] pairs of 32 bit words.
+const k512 = new Uint32Array([
+	0x428a2f98, 0xd728ae22, 0x71374491, 0x23ef65cd, 0xb5c0fbcf, 0xec4d3b2f, 0xe9b5dba5, 0x8189dbbc,
+	0x3956c25b, 0xf348b538, 0x59f111f1, 0xb605d019, 0x923f82a4, 0xaf194f9b, 0xab1c5ed5, 0xda6d8118,
+	0xd807aa98, 0xa3030242, 0x12835b01, 0x45706fbe, 0x243185be, 0x4ee4b28c, 0x550c7dc3, 0xd5ffb4e2,
+	0x72be5d74, 0xf27b896f, 0x80deb1fe, 0x3b1696b1, 0x9bdc06a7, 0x25c71235, 0xc19bf174, 0xcf692694,
+	0xe49b69c1, 0x9ef14ad2, 0xefbe4786, 0x384f25e3, 0x0fc19dc6, 0x8b8cd5b5, 0x240ca1cc, 0x77ac9c65,
+	0x2de92c6f, 0x592b0275, 0x4a7484aa, 0x6ea6e483, 0x5cb0a9dc, 0xbd41fbd4, 0x76f988da, 0x831153b5,
+	0x983e5152, 0xee66dfab, 0xa831c66d, 0x2db43210, 0xb00327c8, 0x98fb213f, 0xbf597fc7, 0xbeef0ee4,
+	0xc6e00bf3, 0x3da88fc2, 0xd5a79147, 0x930aa725, 0x06ca6351, 0xe003826f, 0x14292967, 0x0a0e6e70,
+	0x27b70a85, 0x46d22ffc, 0x2e1b2138, 0x5c26c926, 0x4d2c6dfc, 0x5ac42aed, 0x53380d13, 0x9d95b3df,
+	0x650a7354, 0x8baf63de, 0x766a0abb, 0x3c77b2a8, 0x81c2c92e, 0x47edaee6, 0x92722c85, 0x1482353b,
+	0xa2bfe8a1, 0x4cf10364, 0xa81a664b, 0xbc423001, 0xc24b8b70, 0xd0f89791, 0xc76c51a3, 0x0654be30,
+	0xd192e819, 0xd6ef5218, 0xd6990624, 0x5565a910, 0xf40e3585, 0x5771202a, 0x106aa070, 0x32bbd1b8,
+	0x19a4c116, 0xb8d2d0c8, 0x1e376c08, 0x5141ab53, 0x2748774c, 0xdf8eeb99, 0x34b0bcb5, 0xe19b48a8,
+	0x391c0cb3, 0xc5c95a63, 0x4ed8aa4a, 0xe3418acb, 0x5b9cca4f, 0x7763e373, 0x682e6ff3, 0xd6b2b8a3,
+	0x748f82ee, 0x5defb2fc, 0x78a5636f, 0x43172f60, 0x84c87814, 0xa1f0ab72, 0x8cc70208, 0x1a6439ec,
+	0x90befffa, 0x23631e28, 0xa4506ceb, 0xde82bde9, 0xbef9a3f7, 0xb2c67915, 0xc67178f2, 0xe372532b,
+	0xca273ece, 0xea26619c, 0xd186b8c7, 0x21c0c207, 0xeada7dd6, 0xcde0eb1e, 0xf57d4f7f, 0xee6ed178,
+	0x06f067aa, 0x72176fba, 0x0a637dc5, 0xa2c898a6, 0x113f9804, 0xbef90dae, 0x1b710b35, 0x131c471b,
+	0x28db77f5, 0x23047d84, 0x32caab7b, 0x40c72493, 0x3c9ebe0a, 0x15c9bebc, 0x431d67c4, 0x9c100d4c,
+	0x4cc5d4be, 0xcb3e42b6, 0x597f299c, 0xfc657e2a, 0x5fcb6fab, 0x3ad6faec, 0x6c44198c, 0x4a475817,
+]);
+
+const iv384 = [
+	0xcbbb9d5d, 0xc1059ed8, 0x629a292a, 0x367cd507, 0x9159015a, 0x3070dd17, 0x152fecd8, 0xf70e5939,
+	0x67332667, 0xffc00b31, 0x8eb44a87, 0x68581511, 0xdb0c2e0d, 0x64f98fa7, 0x47b5481d, 0xbefa4fa4,
+];
+const iv512 = [
+	0x6a09e667, 0xf3bcc908, 0xbb67ae85, 0x84caa73b, 0x3c6ef372, 0xfe94f82b, 0xa54ff53a, 0x5f1d36f1,
+	0x510e527f, 0xade682d1, 0x9b05688c, 0x2b3e6c1f, 0x1f83d9ab, 0xfb41bd6b, 0x5be0cd19, 0x137e2179,
+];
+
+/**
+newSHA512 returns an incremental SHA-512 (or SHA-384) hasher with the
+methods `update(Uint8Array)` and `digest()`, like newSHA256.  Javascript has
+no 64 bit integers (BigInt is too slow), so 64 bit words are computed as high
+and low 32 bit halves.
+@param   {boolean}  is384   Use SHA-384 instead of SHA-512.
+@returns {object}
+*/
+function newSHA512(is384) {
+	let h = new Uint32Array(is384 ? iv384 : iv512);
+	let wh = new Int32Array(80);
+	let wl = new Int32Array(80);
+	let block = new Uint8Array(128);
+	let blockLen = 0;
+	let total = 0; // Bytes processed.
+
+	let compress = function(b, off) {
+		for (let i = 0; i < 16; i++) {
+			let j = off + 8 * i;
+			wh[i] = (b[j] << 24) | (b[j + 1] << 16) | (b[j + 2] << 8) | b[j + 3];
+			wl[i] = (b[j + 4] << 24) | (b[j + 5] << 16) | (b[j + 6] << 8) | b[j + 7];
+		}
+		for (let i = 16; i < 80; i++) {
+			// σ0 = rotr1 ^ rotr8 ^ shr7
+			let xh = wh[i - 15];
+			let xl = wl[i - 15];
+			let s0h = ((xh >>> 1) | (xl << 31)) ^ ((xh >>> 8) | (xl << 24)) ^ (xh >>> 7);
+			let s0l = ((xl >>> 1) | (xh << 31)) ^ ((xl >>> 8) | (xh << 24)) ^ ((xl >>> 7) | (xh << 25));
+			// σ1 = rotr19 ^ rotr61 ^ shr6
+			xh = wh[i - 2];
+			xl = wl[i - 2];
+			let s1h = ((xh >>> 19) | (xl << 13)) ^ ((xl >>> 29) | (xh << 3)) ^ (xh >>> 6);
+			let s1l = ((xl >>> 19) | (xh << 13)) ^ ((xh >>> 29) | (xl << 3)) ^ ((xl >>> 6) | (xh << 26));
+
+			let lo = (wl[i - 16] >>> 0) + (s0l >>> 0) + (wl[i - 7] >>> 0) + (s1l >>> 0);
+			wh[i] = wh[i - 16] + s0h + wh[i - 7] + s1h + Math.floor(lo / 0x100000000);
+			wl[i] = lo;
+		}
+
+		let ah = h[0], al = h[1], bh = h[2], bl = h[3], ch = h[4], cl = h[5], dh = h[6], dl = h[7];
+		let eh = h[8], el = h[9], fh = h[10], fl = h[11], gh = h[12], gl = h[13], hh = h[14], hl = h[15];
+		for (let i = 0; i < 80; i++) {
+			// Σ1 = rotr14 ^ rotr18 ^ rotr41
+			let S1h = ((eh >>> 14) | (el << 18)) ^ ((eh >>> 18) | (el << 14)) ^ ((el >>> 9) | (eh << 23));
+			let S1l = ((el >>> 14) | (eh << 18)) ^ ((el >>> 18) | (eh << 14)) ^ ((eh >>> 9) | (el << 23));
+			let chh = (eh & fh) ^ (~eh & gh);
+			let chl = (el & fl) ^ (~el & gl);
+			let lo = (hl >>> 0) + (S1l >>> 0) + (chl >>> 0) + k512[2 * i + 1] + (wl[i] >>> 0);
+			let t1h = (hh + S1h + chh + k512[2 * i] + wh[i] + Math.floor(lo / 0x100000000)) | 0;
+			let t1l = lo >>> 0;
+
+			// Σ0 = rotr28 ^ rotr34 ^ rotr39
+			let S0h = ((ah >>> 28) | (al << 4)) ^ ((al >>> 2) | (ah << 30)) ^ ((al >>> 7) | (ah << 25));
+			let S0l = ((al >>> 28) | (ah << 4)) ^ ((ah >>> 2) | (al << 30)) ^ ((ah >>> 7) | (al << 25));
+			let majh = (ah & bh) ^ (ah & ch) ^ (bh & ch);
+			let majl = (al & bl) ^ (al & cl) ^ (bl & cl);
+			lo = (S0l >>> 0) + (majl >>> 0);
+			let t2h = (S0h + majh + Math.floor(lo / 0x100000000)) | 0;
+			let t2l = lo >>> 0;
+
+			hh = gh;
+			hl = gl;
+			gh = fh;
+			gl = fl;
+			fh = eh;
+			fl = el;
+			lo = (dl >>> 0) + t1l;
+			eh = (dh + t1h + Math.floor(lo / 0x100000000)) | 0;
+			el = lo >>> 0;
+			dh = ch;
+			dl = cl;
+			ch = bh;
+			cl = bl;
+			bh = ah;
+			bl = al;
+			lo = t1l + t2l;
+			ah = (t1h + t2h + Math.floor(lo / 0x100000000)) | 0;
+			al = lo >>> 0;
+		}
+
+		let add = function(i, xh, xl) {
+			let lo = h[i + 1] + (xl >>> 0);
+			h[i] = h[i] + xh + Math.floor(lo / 0x100000000);
+			h[i + 1] = lo;
+		};
+		add(0, ah, al);
+		add(2, bh, bl);
+		add(4, ch, cl);
+		add(6, dh, dl);
+		add(8, eh, el);
+		add(10, fh, fl);
+		add(12, gh, gl);
+		add(14, hh, hl);
+	};
+
+	return {
+		update: function(data) {
+			total += data.length;
+			let i = 0;
+			if (blockLen > 0) {
+				while (blockLen < 128 && i < data.length) {
+					block[blockLen++] = data[i++];
+				}
+				if (blockLen < 128) {
+					return;
+				}
+				compress(block, 0);
+				blockLen = 0;
+			}
+			for (; i + 128 <= data.length; i += 128) {
+				compress(data, i);
+			}
+			while (i < data.length) {
+				block[blockLen++] = data[i++];
+			}
+		},
+		digest: function() {
+			let bits = total * 8;
+			block[blockLen++] = 0x80;
+			if (blockLen > 112) {
+				block.fill(0, blockLen);
+				compress(block, 0);
+				blockLen = 0;
+			}
+			block.fill(0, blockLen);
+			// 128 bit message length in bits, big endian.  The high 64 bits are zero.
+			let view = new DataView(block.buffer);
+			view.setUint32(120, Math.floor(bits / 0x100000000));
+			view.setUint32(124, bits >>> 0);
+			compress(block, 0);
+
+			let out = new Uint8Array(64);
+			let outView = new DataView(out.buffer);
+			for (let i = 0; i < 16; i++) {
+				outView.setUint32(4 * i, h[i]);
+			}
+			return is384 ? out.slice(0, 48) : out;
+		},
+	};
+}
+
+
+///////////////////////////////////
+// SHA-3 and SHAKE (FIPS 202)
+///////////////////////////////////
+
+// Keccak round constants as [low, high] pairs of 32 bit words.
+const keccakRC = new Uint32Array([
+	0x00000001, 0x00000000, 0x00008082, 0x00000000, 0x0000808a, 0x80000000, 0x80008000, 0x80000000,
+	0x0000808b, 0x00000000, 0x80000001, 0x00000000, 0x80008081, 0x80000000, 0x00008009, 0x80000000,
+	0x0000008a, 0x00000000, 0x00000088, 0x00000000, 0x80008009, 0x00000000, 0x8000000a, 0x00000000,
+	0x8000808b, 0x00000000, 0x0000008b, 0x80000000, 0x00008089, 0x80000000, 0x00008003, 0x80000000,
+	0x00008002, 0x80000000, 0x00000080, 0x80000000, 0x0000800a, 0x00000000, 0x8000000a, 0x80000000,
+	0x80008081, 0x80000000, 0x00008080, 0x80000000, 0x80000001, 0x00000000, 0x80008008, 0x80000000,
+]);
+
+// Keccak rotation offsets by lane index x + 5y.
+const keccakRho = [0, 1, 62, 28, 27, 36, 44, 6, 55, 20, 3, 10, 43, 25, 39, 41, 45, 15, 21, 8, 18, 2, 61, 56, 14];
+
+// sha3Params are [rate in bytes, digest size in bytes, domain separation byte].
+// SHAKE output sizes are Coze's HashSize.
+const sha3Params = {
+	"SHA3-224": [144, 28, 0x06],
+	"SHA3-256": [136, 32, 0x06],
+	"SHA3-384": [104, 48, 0x06],
+	"SHA3-512": [72, 64, 0x06],
+	"SHAKE128": [168, 32, 0x1f],
+	"SHAKE256": [136, 64, 0x1f],
+};
+
+/**
+keccakF applies the Keccak-f[1600] permutation to state.  Each of the 25 64
+bit lanes is stored as a [low, high] pair of 32 bit words.
+@param   {Uint32Array}  s   State, 50 words.
+@returns {void}
+*/
+function keccakF(s) {
+	let c = new Uint32Array(10);
+	let b = new Uint32Array(50);
+	for (let round = 0; round < 24; round++) {
+		// θ
+		for (let x = 0; x < 5; x++) {
+			c[2 * x] = s[2 * x] ^ s[2 * x + 10] ^ s[2 * x + 20] ^ s[2 * x + 30] ^ s[2 * x + 40];
+			c[2 * x + 1] = s[2 * x + 1] ^ s[2 * x + 11] ^ s[2 * x + 21] ^ s[2 * x + 31] ^ s[2 * x + 41];
+		}
+		for (let x = 0; x < 5; x++) {
+			let x1 = 2 * ((x + 1) % 5);
+			let x4 = 2 * ((x + 4) % 5);
+			let dl = c[x4] ^ ((c[x1] << 1) | (c[x1 + 1] >>> 31));
+			let dh = c[x4 + 1] ^ ((c[x1 + 1] << 1) | (c[x1] >>> 31));
+			for (let y = 0; y < 25; y += 5) {
+				s[2 * (x + y)] ^= dl;
+				s[2 * (x + y) + 1] ^= dh;
+			}
+		}
+		// ρ and π
+		for (let x = 0; x < 5; x++) {
+			for (let y = 0; y < 5; y++) {
+				let i = x + 5 * y;
+				let lo = s[2 * i];
+				let hi = s[2 * i + 1];
+				let n = keccakRho[i];
+				if (n >= 32) {
+					[lo, hi] = [hi, lo];
+					n -= 32;
+				}
+				let j = 2 * (y + 5 * ((2 * x + 3 * y) % 5));
+				if (n === 0) {
+					b[j] = lo;
+					b[j + 1] = hi;
+				} else {
+					b[j] = (lo << n) | (hi >>> (32 - n));
+					b[j + 1] = (hi << n) | (lo >>> (32 - n));
+				}
+			}
+		}
+		// χ
+		for (let y = 0; y < 25; y += 5) {
+			for (let x = 0; x < 5; x++) {
+				let i = 2 * (x + y);
+				let i1 = 2 * ((x + 1) % 5 + y);
+				let i2 = 2 * ((x + 2) % 5 + y);
+				s[i] = b[i] ^ (~b[i1] & b[i2]);
+				s[i + 1] = b[i + 1] ^ (~b[i1 + 1] & b[i2 + 1]);
+			}
+		}
+		// ι
+		s[0] ^= keccakRC[2 * round];
+		s[1] ^= keccakRC[2 * round + 1];
+	}
+}
+
+/**
+newSHA3 returns an incremental SHA-3 or SHAKE hasher with the methods
+`update(Uint8Array)` and `digest()`, like newSHA256.
+@param   {Hsh}      hsh   e.g. "SHA3-256" or "SHAKE128".
+@returns {object}
+*/
+function newSHA3(hsh) {
+	let [rate, size, ds] = sha3Params[hsh];
+	let s = new Uint32Array(50);
+	let block = new Uint8Array(rate);
+	let blockLen = 0;
+
+	let absorb = function() {
+		let v = new DataView(block.buffer);
+		for (let i = 0; i < rate / 4; i++) {
+			s[i] ^= v.getUint32(4 * i, true); // Lanes are little endian.
+		}
+		keccakF(s);
+		blockLen = 0;
+	};
+
+	return {
+		update: function(data) {
+			for (let i = 0; i < data.length; i++) {
+				block[blockLen++] = data[i];
+				if (blockLen === rate) {
+					absorb();
+				}
+			}
+		},
+		digest: function() {
+			block.fill(0, blockLen);
+			block[blockLen] ^= ds;
+			block[rate - 1] ^= 0x80;
+			absorb();
+
+			let out = new Uint8Array(size);
+			for (let off = 0; off < size; off += rate) {
+				if (off > 0) {
+					keccakF(s);
+				}
+				let v = new DataView(new ArrayBuffer(rate));
+				for (let i = 0; i < rate / 4; i++) {
+					v.setUint32(4 * i, s[i], true);
+				}
+				out.set(new Uint8Array(v.buffer, 0, Math.min(rate, size - off)), off);
+			}
+			return out;
+		},
+	};
+}
@@ -22,5 +22,10 @@
 export * from './canon.js';
 export * from './alg.js';
 export * from './coze.js';
+export * from './conversion.js';
 export * from './key.js';
-export * from './cryptokey.js';
\ No newline at end of file
+export * from './cryptokey.js';
+export * from './ecdsa.js';
+export * from './hash.js';
+export * from './der.js';
+export * from './error.js';
\ No newline at end of file
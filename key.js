@@ -6,15 +6,33 @@ import * as Coze from './coze.js';
 import * as Alg from './alg.js';
 import {
 	isEmpty
-} from './coze.js';
+} from './conversion.js';
+import {
+	ECDSA
+} from './ecdsa.js';
+import {
+	CozeError,
+	CozeAlgError,
+	CozeKeyError,
+	ErrCodes,
+} from './error.js';
 
 export {
 	NewKey,
+	NewKeyFromSeed,
+	NewKeyFromPassword,
 	Correct,
+	Diagnose,
 	Valid,
 	Thumbprint,
+	ThumbprintMatch,
+	PublicKey,
+	IsPrivate,
+	AssertPublic,
 	Revoke,
 	IsRevoked,
+	VerifyRevoke,
+	LookupKey,
 
 	// RecalcX,
 
@@ -27,11 +45,18 @@ export {
 @typedef {import('./typedef.js').Use}  Use
 @typedef {import('./typedef.js').Sig}  Sig
 @typedef {import('./typedef.js').Key}  Key
+@typedef {import('./typedef.js').RevokeOpts}  RevokeOpts
+@typedef {import('./typedef.js').Keyring}  Keyring
+@typedef {import('./typedef.js').B64}  B64
  */
 
 // Coze key Thumbprint Canons.
 const TmbCanon = ["alg", "x"];
 
+// PublicFields are the fields of a Coze key kept by PublicKey.  Coze's x is
+// X || Y for ECDSA, so there is no separate y.
+const PublicFields = ["alg", "iat", "kid", "tmb", "typ", "rvk", "x"];
+
 /**
 NewKey returns a new Coze key.
 If no alg is given, the returned key will be an 'ES256' key.
@@ -42,10 +67,12 @@ async function NewKey(alg) {
 	if (isEmpty(alg)) {
 		alg = Alg.Algs.ES256;
 	}
-	if (Alg.Genus(alg) == Alg.GenAlgs.ECDSA) {
+	if (Alg.Genus(alg) == Alg.GenAlgs.ECDSA || alg == Alg.Algs.Ed25519) {
 		var keyPair = await CTK.CryptoKey.New(alg);
 	} else {
-		throw new Error("Coze.NewKey: only ECDSA algs are currently supported.");
+		throw new CozeAlgError("Coze.NewKey: only ECDSA algs and Ed25519 are currently supported.", ErrCodes.AlgUnsupported, {
+			alg: alg
+		});
 	}
 
 	let k = await CTK.CryptoKey.ToCozeKey(keyPair.privateKey);
@@ -56,20 +83,446 @@ async function NewKey(alg) {
 	return k;
 }
 
+// tmbCache caches thumbprints by Coze key object.  Each entry holds the `alg`
+// and `x` it was calculated from, so that a mutated key is thumbprinted again.
+// WeakMap so that entries are garbage collected with their Coze key.
+const tmbCache = new WeakMap();
+
+// ed25519PKCS8Prefix is the DER PKCS #8 prefix of an Ed25519 private key
+// (RFC 8410), followed by the 32 byte seed.
+const ed25519PKCS8Prefix = [0x30, 0x2e, 0x02, 0x01, 0x00, 0x30, 0x05, 0x06, 0x03, 0x2b, 0x65, 0x70, 0x04, 0x22, 0x04, 0x20];
+
+/**
+ed25519X returns the b64ut public key `x` of the Ed25519 private key (seed) `d`
+using SubtleCrypto.
+@param   {Uint8Array}  d
+@returns {B64}
+@throws  {error}
+ */
+async function ed25519X(d) {
+	let pkcs8 = new Uint8Array(ed25519PKCS8Prefix.length + d.length);
+	pkcs8.set(ed25519PKCS8Prefix);
+	pkcs8.set(d, ed25519PKCS8Prefix.length);
+	let ck = await crypto.subtle.importKey("pkcs8", pkcs8, {
+		name: Alg.Algs.Ed25519
+	}, true, ["sign"]);
+	return (await crypto.subtle.exportKey("jwk", ck)).x;
+}
+
+/**
+NewKeyFromSeed deterministically derives a private Coze key from seed, so the
+same seed always results in the same key (and tmb) in every implementation,
+e.g. for backups or reproducible test fixtures.  For ECDSA, `d` is derived
+from seed using HKDF with rejection sampling (See ECDSA.KeyFromSeed) and seed
+must be at least half of alg's hash size, e.g. 16 bytes for ES256, which is
+the curve's security level.  For Ed25519, seed is the standard 32 byte
+private key (RFC 8032) and must be exactly 32 bytes.  `iat` and `kid` are not
+set so that the derived key is always identical.
+
+⚠️ The key is only as secret as the seed.  Generating, storing, and protecting
+the seed is the caller's responsibility.
+@param   {Alg}          alg
+@param   {Uint8Array}   seed
+@returns {Key}
+@throws  {error}
+ */
+async function NewKeyFromSeed(alg, seed) {
+	if (!(seed instanceof Uint8Array)) {
+		throw new TypeError("Coze.NewKeyFromSeed: seed must be a Uint8Array.");
+	}
+	let k;
+	if (Alg.Genus(alg) == Alg.GenAlgs.ECDSA) {
+		let min = Alg.HashSize(alg) / 2;
+		if (seed.length < min) {
+			throw new CozeKeyError(`Coze.NewKeyFromSeed: seed must be at least ${min} bytes for ${alg}.`, ErrCodes.KeyInvalid, {
+				field: "seed"
+			});
+		}
+		k = await ECDSA.KeyFromSeed(alg, seed);
+	} else if (alg == Alg.Algs.Ed25519) {
+		if (seed.length !== Alg.DSize(alg)) {
+			throw new CozeKeyError("Coze.NewKeyFromSeed: Ed25519 seed must be 32 bytes.", ErrCodes.KeyInvalid, {
+				field: "seed"
+			});
+		}
+		k = {
+			alg: alg,
+			d: Coze.ArrayBufferTo64ut(seed),
+			x: await ed25519X(seed),
+		};
+	} else {
+		throw new CozeAlgError("Coze.NewKeyFromSeed: only ECDSA algs and Ed25519 are currently supported.", ErrCodes.AlgUnsupported, {
+			alg: alg
+		});
+	}
+	k.tmb = await Thumbprint(k);
+	return k;
+}
+
+// PasswordIterations is the default PBKDF2-SHA-512 iteration count for
+// NewKeyFromPassword, as recommended by OWASP (2023).
+const PasswordIterations = 210000;
+
+/**
+PasswordParams are the parameters used by NewKeyFromPassword.  They are not
+secret and should be stored, e.g. alongside the public key, so that the key
+may be regenerated from the password.
+@typedef  {object}  PasswordParams
+@property {Alg}     alg
+@property {string}  kdf         "PBKDF2"
+@property {string}  hash        "SHA-512"
+@property {number}  iterations
+@property {B64}     salt
+*/
+
+/**
+NewKeyFromPassword derives a private Coze key from a password by using
+PBKDF2-SHA-512 (SubtleCrypto) to derive a seed for NewKeyFromSeed.  The same
+password, salt, and iterations always result in the same key (and tmb).
+Returns the key and the PasswordParams used.
+
+password is normalized to Unicode NFC before UTF-8 encoding so that the same
+password entered on different systems results in the same key.  salt is
+required, should be random and unique to the key, and must be at least 16
+bytes.  A string salt is UTF-8 encoded.  opts.iterations defaults to 210,000.
+The seed is 32 bytes for Ed25519 and the alg's hash size for ECDSA.
+
+PBKDF2 is intentionally slow and does not report progress.  At the default
+iterations derivation takes around a tenth of a second on desktops and may
+take several seconds on mobile devices, so UIs should show that work is in
+progress before calling.
+@param   {Alg}                 alg
+@param   {string}              password
+@param   {Uint8Array|string}   salt
+@param   {{iterations: number}} [opts]
+@returns {{key: Key, params: PasswordParams}}
+@throws  {error}
+ */
+async function NewKeyFromPassword(alg, password, salt, opts) {
+	if (typeof salt === "string") {
+		salt = new TextEncoder().encode(salt);
+	}
+	if (!(salt instanceof Uint8Array) || salt.length < 16) {
+		throw new CozeKeyError("Coze.NewKeyFromPassword: salt must be at least 16 bytes.", ErrCodes.KeyInvalid, {
+			field: "salt"
+		});
+	}
+	let iterations = PasswordIterations;
+	if (!isEmpty(opts) && opts.iterations !== undefined) {
+		iterations = opts.iterations;
+	}
+	if (!Number.isSafeInteger(iterations) || iterations < 1) {
+		throw new CozeKeyError("Coze.NewKeyFromPassword: iterations must be a positive integer.", ErrCodes.KeyInvalid, {
+			field: "iterations"
+		});
+	}
+	let size = Alg.DSize(Alg.Algs.Ed25519);
+	if (Alg.Genus(alg) == Alg.GenAlgs.ECDSA) {
+		size = Alg.HashSize(alg);
+	}
+	let pw = await crypto.subtle.importKey("raw", new TextEncoder().encode(password.normalize("NFC")), "PBKDF2", false, ["deriveBits"]);
+	let seed = await crypto.subtle.deriveBits({
+		name: "PBKDF2",
+		hash: Alg.Algs.SHA512,
+		salt: salt,
+		iterations: iterations,
+	}, pw, size * 8);
+	return {
+		key: await NewKeyFromSeed(alg, new Uint8Array(seed)),
+		params: {
+			alg: alg,
+			kdf: "PBKDF2",
+			hash: Alg.Algs.SHA512,
+			iterations: iterations,
+			salt: Coze.ArrayBufferTo64ut(salt),
+		},
+	};
+}
+
 /**
 Thumbprint calculates and returns a B64 Coze key thumbprint. Fails on empty
-'alg' or 'x'.
+'alg' or 'x'.  Thumbprints are cached by Coze key object and recalculated if
+`alg` or `x` changes.  The key's `tmb` field is not used.
 @param   {Key}   cozeKey
 @returns {Tmb}
 @throws  {error}
  */
 async function Thumbprint(cozeKey) {
 	if (isEmpty(cozeKey.alg) || isEmpty(cozeKey.x)) {
-		throw new Error("Coze.Thumbprint: alg or x is empty.");
+		throw new CozeKeyError("Coze.Thumbprint: alg or x is empty.", ErrCodes.KeyInvalid, {
+			field: isEmpty(cozeKey.alg) ? "alg" : "x"
+		});
+	}
+	let cached = tmbCache.get(cozeKey);
+	if (cached !== undefined && cached.alg === cozeKey.alg && cached.x === cozeKey.x) {
+		return cached.tmb;
 	}
-	return Can.CanonicalHash64(cozeKey, await Alg.HashAlg(cozeKey.alg), TmbCanon);
+	let tmb = await Can.CanonicalHash64(cozeKey, await Alg.HashAlg(cozeKey.alg), TmbCanon);
+	tmbCache.set(cozeKey, {
+		alg: cozeKey.alg,
+		x: cozeKey.x,
+		tmb: tmb
+	});
+	return tmb;
 };
 
+/**
+ThumbprintMatch throws CozeKeyError with code ERR_TMB_MISMATCH if the
+calculated thumbprint of cozeKey does not match cozeKey.tmb.  Returns the
+thumbprint.
+@param   {Key}   cozeKey
+@returns {Tmb}
+@throws  {error}
+ */
+async function ThumbprintMatch(cozeKey) {
+	let tmb = await Thumbprint(cozeKey);
+	if (tmb !== cozeKey.tmb) {
+		throw new CozeKeyError("Coze.ThumbprintMatch: key.tmb does not match the calculated thumbprint.", ErrCodes.TmbMismatch, {
+			field: "tmb"
+		});
+	}
+	return tmb;
+}
+
+/**
+PublicKey returns a new public Coze key with only the public fields of cozeKey
+(alg, iat, kid, tmb, typ, rvk, and x).  Any other field, including `d` and
+unknown fields that may be private, is omitted.  The given key is not
+modified.
+@param   {Key}  cozeKey
+@returns {Key}  Public Coze key.
+ */
+function PublicKey(cozeKey) {
+	let pub = {};
+	for (const [k, v] of Object.entries(cozeKey)) {
+		if (PublicFields.includes(k)) {
+			pub[k] = v;
+		}
+	}
+	return pub;
+}
+
+/**
+IsPrivate returns whether cozeKey has private material, i.e. a `d` field in
+any casing (e.g. "D"), even if empty.  Use before logging or sharing a key.
+@param   {Key}      cozeKey
+@returns {boolean}
+ */
+function IsPrivate(cozeKey) {
+	return privateField(cozeKey) !== undefined;
+}
+
+/**
+AssertPublic throws CozeKeyError with code ERR_KEY_INVALID if cozeKey has
+private material (See IsPrivate).
+@param   {Key}   cozeKey
+@returns {void}
+@throws  {error}
+ */
+function AssertPublic(cozeKey) {
+	let f = privateField(cozeKey);
+	if (f !== undefined) {
+		throw new CozeKeyError(`AssertPublic: key has private component "${f}".`, ErrCodes.KeyInvalid, {
+			field: f
+		});
+	}
+}
+
+/**
+privateField returns the name of the private field of cozeKey, or undefined.
+@param   {Key}               cozeKey
+@returns {string|undefined}
+ */
+function privateField(cozeKey) {
+	if (cozeKey === null || typeof cozeKey !== "object") {
+		return undefined;
+	}
+	return Object.keys(cozeKey).find(f => f.toLowerCase() === "d");
+}
+
+/**
+KeyCheck is the result of a single Diagnose check.
+
+- name:     Check name, e.g. "x_length".
+- status:   "pass", "fail", or "skip".  Checks are skipped when they do not
+            apply to the key or a check they depend on did not pass.
+- message:  Human readable reason for "fail" and "skip".
+@typedef  {object}  KeyCheck
+@property {string}  name
+@property {string}  status
+@property {string}  [message]
+*/
+
+/**
+Diagnose checks a Coze key like Correct, but instead of a boolean returns a
+report of every check so that the reason a key is incorrect is known.  `ok` is
+true if no check failed.  Diagnose does not throw or log.  Checks, in order:
+
+- alg_known:              alg is a supported signing alg.
+- b64ut:                  x, d, and tmb, if present, are strict b64ut.
+- x_length:               x is the size for alg.
+- y_length:               For ECDSA, x includes Y (x is X || Y).
+- d_length:               d is the size for alg.
+- tmb_matches:            tmb matches the thumbprint of alg and x, or for tmb
+                          only keys, tmb is the size for alg.
+- d_derives_x:            x is the public key of d.
+- sign_verify_roundtrip:  A signature by d is verified by x.
+@param   {Key}  ck
+@returns {{ok: boolean, checks: KeyCheck[]}}
+ */
+async function Diagnose(ck) {
+	/** @type {KeyCheck[]} */
+	let checks = [];
+	let status = {};
+	let add = function(name, s, message) {
+		let c = {
+			name: name,
+			status: s
+		};
+		if (!isEmpty(message)) {
+			c.message = message;
+		}
+		checks.push(c);
+		status[name] = s;
+	};
+	let passed = (...names) => names.every(n => status[n] === "pass");
+	let skipAfter = function(name, ...deps) {
+		add(name, "skip", "Requires passing " + deps.filter(n => status[n] !== "pass").join(", ") + ".");
+	};
+	let bytes = {};
+	if (typeof ck !== "object" || ck === null) {
+		ck = {};
+	}
+
+	// alg_known
+	let p;
+	try {
+		p = Alg.Params(ck.alg);
+		if (p.Use !== Alg.Uses.Sig) {
+			add("alg_known", "fail", `alg "${ck.alg}" is not a signing alg.`);
+		} else {
+			add("alg_known", "pass");
+		}
+	} catch (e) {
+		add("alg_known", "fail", `alg "${ck.alg}" is not supported.`);
+	}
+
+	// b64ut
+	let bad = [];
+	for (const f of ["x", "d", "tmb"]) {
+		if (!isEmpty(ck[f])) {
+			try {
+				bytes[f] = Coze.B64ToUint8Array(ck[f], f);
+			} catch (e) {
+				bad.push(f);
+			}
+		}
+	}
+	if (isEmpty(ck.x) && isEmpty(ck.d) && isEmpty(ck.tmb)) {
+		add("b64ut", "fail", "At least one of x, d, and tmb must be set.");
+	} else if (bad.length > 0) {
+		add("b64ut", "fail", "Not strict b64ut: " + bad.join(", ") + ".");
+	} else {
+		add("b64ut", "pass");
+	}
+
+	// x_length, y_length, d_length
+	for (const [name, f, size] of [["x_length", "x", "XSize"], ["d_length", "d", "DSize"]]) {
+		if (isEmpty(ck[f])) {
+			add(name, "skip", `No ${f}.`);
+		} else if (!passed("alg_known", "b64ut")) {
+			skipAfter(name, "alg_known", "b64ut");
+		} else if (bytes[f].length !== p[size]) {
+			add(name, "fail", `${f} is ${bytes[f].length} bytes, ${ck.alg} requires ${p[size]}.`);
+		} else {
+			add(name, "pass");
+		}
+		if (name === "x_length") {
+			if (isEmpty(ck.x)) {
+				add("y_length", "skip", "No x.");
+			} else if (!passed("alg_known", "b64ut")) {
+				skipAfter("y_length", "alg_known", "b64ut");
+			} else if (p.Genus !== Alg.GenAlgs.ECDSA) {
+				add("y_length", "skip", `${ck.alg} has no Y coordinate.`);
+			} else if (bytes.x.length === p.XSize / 2) {
+				add("y_length", "fail", "x is only the X coordinate.  Coze x is X || Y.");
+			} else if (bytes.x.length !== p.XSize) {
+				skipAfter("y_length", "x_length");
+			} else {
+				add("y_length", "pass");
+			}
+		}
+	}
+
+	// tmb_matches
+	if (isEmpty(ck.tmb)) {
+		add("tmb_matches", "skip", "No tmb.");
+	} else if (!passed("alg_known", "b64ut")) {
+		skipAfter("tmb_matches", "alg_known", "b64ut");
+	} else if (isEmpty(ck.x)) {
+		if (bytes.tmb.length !== p.HashSize) {
+			add("tmb_matches", "fail", `tmb is ${bytes.tmb.length} bytes, ${ck.alg} requires ${p.HashSize}.`);
+		} else {
+			add("tmb_matches", "pass");
+		}
+	} else if (!passed("x_length")) {
+		skipAfter("tmb_matches", "x_length");
+	} else if (await Thumbprint(ck) !== ck.tmb) {
+		add("tmb_matches", "fail", "tmb does not match the thumbprint of alg and x.");
+	} else {
+		add("tmb_matches", "pass");
+	}
+
+	// d_derives_x
+	if (isEmpty(ck.d) || isEmpty(ck.x)) {
+		add("d_derives_x", "skip", "Requires d and x.");
+	} else if (!passed("x_length", "d_length")) {
+		skipAfter("d_derives_x", "x_length", "d_length");
+	} else {
+		try {
+			let x;
+			if (p.Genus === Alg.GenAlgs.ECDSA) {
+				x = await ECDSA.PublicFromD(ck.alg, BigInt("0x" + Coze.Uint8ArrayToHex(bytes.d)));
+			} else if (ck.alg === Alg.Algs.Ed25519) {
+				x = await ed25519X(bytes.d);
+			}
+			if (x === undefined) {
+				add("d_derives_x", "skip", `Deriving x is not supported for ${ck.alg}.`);
+			} else if (x !== ck.x) {
+				add("d_derives_x", "fail", "x is not the public key of d.");
+			} else {
+				add("d_derives_x", "pass");
+			}
+		} catch (e) {
+			add("d_derives_x", "fail", "d is invalid: " + e.message);
+		}
+	}
+
+	// sign_verify_roundtrip
+	if (isEmpty(ck.d) || isEmpty(ck.x)) {
+		add("sign_verify_roundtrip", "skip", "Requires d and x.");
+	} else if (status.d_derives_x === "fail" || !passed("x_length", "d_length")) {
+		skipAfter("sign_verify_roundtrip", "x_length", "d_length", "d_derives_x");
+	} else {
+		try {
+			let msg = "Coze Diagnose";
+			let sig = await Coze.SignPay(msg, ck);
+			if (await Coze.VerifyPay(msg, ck, sig)) {
+				add("sign_verify_roundtrip", "pass");
+			} else {
+				add("sign_verify_roundtrip", "fail", "Signature by d did not verify with x.");
+			}
+		} catch (e) {
+			add("sign_verify_roundtrip", "fail", e.message);
+		}
+	}
+
+	return {
+		ok: checks.every(c => c.status !== "fail"),
+		checks: checks,
+	};
+}
+
 /**
 Valid returns true only for a valid private Coze key.
 @param   {Key}      privateCozeKey  Private Coze key.
@@ -105,10 +558,13 @@ thoroughly before use.
 
 Correct:
 
-1. Checks the length of `x` and/or `tmb` against `alg`.
+1. Checks that `x`, `d`, and `tmb` are strict b64ut, and checks the length of
+`x` and/or `tmb` against `alg`.
 2. If `x` and `tmb` are present, verifies correct `tmb`.
 3. If `d` is present, verifies correct `tmb` and `x` if present, and verifies
 the key by verifying a generated signature.
+
+Use Diagnose to find out why a key is not correct.
 @param   {Key}     ck
 @returns {boolean}
  */
@@ -134,6 +590,17 @@ async function Correct(ck) {
 		return false;
 	}
 
+	for (const f of ["x", "d", "tmb"]) {
+		if (!isEmpty(ck[f])) {
+			try {
+				Coze.B64ToUint8Array(ck[f], f);
+			} catch (e) {
+				console.error("Correct: " + e.message);
+				return false;
+			}
+		}
+	}
+
 	// tmb only key
 	if (isXEmpty && isDEmpty) {
 		if (isTmbEmpty || ck.tmb.length !== p.HashSizeB64) {
@@ -208,29 +675,66 @@ async function Correct(ck) {
 
 /**
 Revoke generates a self revoke message and sets the input key as revoked.
-'rvk' will be set on given cozeKey.
-@param   {Key}       cozeKey  Private Coze key.
-@param   {string}    [msg]    Optional, human readable non programmatic reason for revoking the key.
-@returns {Coze}               Signed revoke Coze.
-@throws  {error}              Fails if cryptoKeyPrivate is nil or invalid.
+'rvk' will be set on given cozeKey.  The revoke pay is in the order `alg`,
+`iat`, `tmb`, `typ`, `rvk`, `msg`, followed by other fields from opts in the
+given order.  `alg`, `iat`, `tmb`, and `rvk` are always set by Revoke, and
+giving them in opts is an error (ERR_FIELD_RESERVED).
+@param   {Key}               cozeKey  Private Coze key.
+@param   {RevokeOpts|string} [opts]   Revoke options, or, as before, msg.
+@returns {Coze}                       Signed revoke Coze.
+@throws  {error}                      Fails if cryptoKeyPrivate is nil or invalid.
  */
-async function Revoke(cozeKey, msg) {
+async function Revoke(cozeKey, opts) {
 	if (isEmpty(cozeKey)) {
-		throw new Error("CozeKey.Revoke: Private key not set.  Cannot sign message");
+		throw new CozeKeyError("CozeKey.Revoke: Private key not set.  Cannot sign message", ErrCodes.KeyInvalid, {
+			field: "d"
+		});
+	}
+	if (typeof opts === "string") {
+		opts = {
+			msg: opts
+		};
+	}
+	if (isEmpty(opts)) {
+		opts = {};
+	}
+	for (const f of ["alg", "iat", "tmb", "rvk"]) {
+		if (f in opts) {
+			throw new CozeError(`CozeKey.Revoke: "${f}" is set by Revoke and may not be given.`, ErrCodes.FieldReserved, {
+				field: f
+			});
+		}
 	}
 
 	var coze = {};
 	coze.pay = {};
-	if (!isEmpty(msg)) { // Optional revoke message. 
-		coze.pay.msg = msg;
+	if (!isEmpty(opts.typ)) {
+		coze.pay.typ = opts.typ;
 	}
 	coze.pay.rvk = Math.round((Date.now() / 1000)); // Javascript's Date converted to Unix time.
+	if (!isEmpty(opts.msg)) { // Optional revoke message. 
+		coze.pay.msg = opts.msg;
+	}
+	for (const [k, v] of Object.entries(opts)) {
+		if (k !== "typ" && k !== "msg") {
+			coze.pay[k] = v;
+		}
+	}
 
 	// SignCoze does not allow revoked keys to sign messages.  Temporarily remove
 	// key.revoke and then set back afterward, otherwise set key with new revoke. 
 	let prevRvk = cozeKey.rvk;
 	delete cozeKey.rvk;
-	coze = await Coze.Sign(coze, cozeKey);
+	try {
+		coze = await Coze.SignCozeRaw(coze, cozeKey, null, {
+			setStandard: true
+		});
+	} catch (e) {
+		if (prevRvk !== undefined) {
+			cozeKey.rvk = prevRvk;
+		}
+		throw e;
+	}
 	if (prevRvk !== undefined) {
 		cozeKey.rvk = prevRvk;
 	} else {
@@ -241,19 +745,101 @@ async function Revoke(cozeKey, msg) {
 };
 
 /**
-IsRevoked returns true if a key or a coze is marked as revoked. `rvk` should
-be an integer Unix timestamp, however this function also checks for the
-string "true" as well as the bool `true`.
+IsRevoked returns true if a key or a pay is marked as revoked.  `rvk` should be
+an integer Unix timestamp greater than 0.
+
+Like Go, `rvk` that is absent, 0, or negative is not revoked, and any positive
+integer, including values larger than the maximum Coze integer (2^53 - 1), is
+revoked.  Go refuses to decode keys with other `rvk` values (e.g. strings,
+fractions, or bools), and since such a key cannot be trusted, IsRevoked
+considers it revoked.
 
 Messages self-revoking keys must have `rvk` with an integer value greater
-than 0.  
-@param   {Key|Coze}       cozeKey  Coze key or coze object.
-@param   {string}         [msg]    Optional reason for revoking the key.
+than 0.  See VerifyRevoke.
+@param   {Key|Pay}        cozeKey  Coze key or pay.
 @returns {boolean}
  */
 function IsRevoked(cozeKey) {
-	if (isEmpty(cozeKey.rvk) || !(parseInt(cozeKey.rvk) > 0)) {
+	let rvk = cozeKey.rvk;
+	if (rvk === undefined || rvk === null) {
 		return false;
 	}
+	if (typeof rvk === "number" && Number.isInteger(rvk)) {
+		return rvk > 0;
+	}
 	return true;
-};
\ No newline at end of file
+};
+
+/**
+VerifyRevoke returns true if coze is a valid self-revoke of cozeKey: coze is
+signed by cozeKey (pay.tmb matches cozeKey's thumbprint), pay.rvk is a positive
+integer, pay.rvk is no earlier than cozeKey.rvk if the key claims a revoke
+time, and the signature is valid.  Unlike Verify, VerifyRevoke permits cozeKey
+to be revoked.
+@param   {Coze}     coze
+@param   {Key}      cozeKey   Public Coze key.
+@returns {boolean}
+@throws  {error}              Fails on malformed coze or key.
+ */
+async function VerifyRevoke(coze, cozeKey) {
+	let rvk = coze.pay.rvk;
+	if (!Number.isSafeInteger(rvk) || rvk <= 0) {
+		return false;
+	}
+	if (coze.pay.tmb !== await Thumbprint(cozeKey)) {
+		return false;
+	}
+	if (Number.isInteger(cozeKey.rvk) && cozeKey.rvk > 0 && rvk < cozeKey.rvk) {
+		return false;
+	}
+	return Coze.Verify(coze, cozeKey, {
+		allowRevoked: true
+	});
+}
+
+/**
+LookupKey returns the key from keyring with the thumbprint tmb.  keyring may
+be an array of Coze keys or a Map of tmb to key.  Keys are matched by their
+calculated thumbprint and not by their declared `tmb`, which may be wrong or
+absent, and a Map entry whose key does not thumbprint to its tmb is not a
+match.  If keyring is a single Coze key, it is returned as is so that callers
+may accept either a key or a keyring.  If no key matches, or tmb is empty,
+CozeKeyError is thrown with code ERR_KEY_NOT_FOUND.
+@param   {Key|Keyring}  keyring
+@param   {Tmb}          tmb
+@returns {Key}
+@throws  {error}
+ */
+async function LookupKey(keyring, tmb) {
+	let isMap = keyring instanceof Map;
+	if (!isMap && !Array.isArray(keyring)) {
+		return keyring;
+	}
+	if (isEmpty(tmb)) {
+		throw new CozeKeyError("LookupKey: no key for tmb: tmb is empty.", ErrCodes.KeyNotFound, {
+			field: "tmb"
+		});
+	}
+	let candidates = keyring;
+	if (isMap) {
+		candidates = keyring.has(tmb) ? [keyring.get(tmb)] : [];
+	}
+	for (const k of candidates) {
+		if (isEmpty(k)) {
+			continue;
+		}
+		let t;
+		try {
+			t = await Thumbprint(k);
+		} catch (e) {
+			continue; // Malformed keys match nothing.
+		}
+		if (t === tmb) {
+			return k;
+		}
+	}
+	throw new CozeKeyError(`LookupKey: no key for tmb ${tmb}.`, ErrCodes.KeyNotFound, {
+		field: "tmb",
+		tmb: tmb
+	});
+}
@@ -0,0 +1,15 @@
+"use strict";
+
+// crypto.js is injected into the Node builds (See BUILD.sh) so that `crypto` is
+// the Web Crypto API on Node versions without `globalThis.crypto` (Node 18 and
+// older).  The browser builds and the ES modules use `globalThis.crypto`, which
+// the Node ES module entries set with `webcrypto.js`.
+import {
+	webcrypto
+} from 'node:crypto';
+
+const crypto = globalThis.crypto !== undefined ? globalThis.crypto : webcrypto;
+
+export {
+	crypto,
+}
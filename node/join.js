@@ -0,0 +1,5 @@
+// Node ES module entry for Coze core.  Installs the Web Crypto shim (See
+// `webcrypto.js`) and exports the ES module build, `dist/esm`, which keeps
+// module boundaries so that bundlers may tree shake.
+import './webcrypto.js';
+export * from '../dist/esm/join.js';
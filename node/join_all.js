@@ -0,0 +1,3 @@
+// Node ES module entry for Coze all (core and standard).  See `join.js`.
+import './webcrypto.js';
+export * from '../dist/esm/all/join_all.js';
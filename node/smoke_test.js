@@ -0,0 +1,60 @@
+"use strict";
+
+// Node smoke test.  Signs and verifies with the ES module build
+// (`node/join.js`, which uses `dist/esm`), the source ES modules (`join.js`),
+// and the CommonJS build (`dist/coze.cjs`), for both Coze core and Coze all.
+// See BUILD.sh.  Works on Node 18, which has no `globalThis.crypto`.  Run from
+// the repo root:
+//
+// ```
+// node node/smoke_test.js
+// ```
+import {
+	createRequire
+} from 'node:module';
+import * as ESM from './join.js';
+import * as ESMAll from './join_all.js';
+import * as Source from '../join.js';
+
+const require = createRequire(import.meta.url);
+
+async function smoke(name, Coze) {
+	for (const alg of [Coze.Algs.ES224, Coze.Algs.ES256, Coze.Algs.Ed25519]) {
+		let key = await Coze.NewKey(alg);
+		let coze = await Coze.Sign({
+			pay: {
+				msg: "Coze Rocks",
+				typ: "cyphr.me/msg",
+			}
+		}, key);
+		let pub = {...key};
+		delete pub.d;
+		if (!(await Coze.Verify(coze, pub))) {
+			throw new Error(`${name}: ${alg} did not verify.`);
+		}
+		coze.pay.msg = "Coze Rolls";
+		if (await Coze.Verify(coze, pub)) {
+			throw new Error(`${name}: ${alg} verified a modified pay.`);
+		}
+	}
+	console.log(`✅ ${name}`);
+}
+
+let failed = false;
+for (const [name, load] of [
+		["ESM", () => ESM],
+		["ESM all", () => ESMAll],
+		["Source", () => Source],
+		["CJS", () => require('../dist/coze.cjs')],
+		["CJS all", () => require('../dist/coze_all.cjs')],
+	]) {
+	try {
+		await smoke(name, load());
+	} catch (e) {
+		failed = true;
+		console.log(`❌ ${name}: ${e.message}`);
+	}
+}
+if (failed) {
+	process.exit(1);
+}
@@ -0,0 +1,14 @@
+"use strict";
+
+// webcrypto.js sets `globalThis.crypto` to `node:crypto`'s `webcrypto` on Node
+// versions without `globalThis.crypto` (Node 18 and older).  It is imported for
+// its side effect by the Node ES module entries, `node/join.js` and
+// `node/join_all.js`, before Coze.  The CommonJS builds use `node/crypto.js`
+// instead (See BUILD.sh).
+import {
+	webcrypto
+} from 'node:crypto';
+
+if (globalThis.crypto === undefined) {
+	globalThis.crypto = webcrypto;
+}
@@ -2,79 +2,403 @@
 
 import {
 	isEmpty,
+	Meta,
+	ParseStrict,
+	SignPay,
 	Verify
 } from '../coze.js';
+import {
+	IsRevoked,
+	LookupKey,
+	Thumbprint,
+} from '../key.js';
+import {
+	CryptoKey,
+} from '../cryptokey.js';
+import {
+	Canonical,
+	NormalizeUnicode,
+} from '../canon.js';
+import {
+	HashAlg,
+} from '../alg.js';
+import {
+	CozeError,
+	CozeAlgError,
+	CozeCanonError,
+	CozeKeyError,
+	CozeVerifyError,
+	ErrCodes,
+} from '../error.js';
 
 export {
-	VerifyCozeArray
+	SignCozeArray,
+	VerifyCozeArray,
+	MetaArray,
 }
 /**
 @typedef {import('../typedef.js').Coze}  Coze
+@typedef {import('../typedef.js').Key}   Key
+@typedef {import('../typedef.js').Czd}   Czd
+@typedef {import('../typedef.js').Tmb}   Tmb
+@typedef {import('../typedef.js').Keyring}  Keyring
+@typedef {import('../typedef.js').Meta}  Meta
+@typedef {import('../typedef.js').Alg}   Alg
+@typedef {import('../typedef.js').Iat}   Iat
+@typedef {import('../typedef.js').Pay}   Pay
+@typedef {import('../typedef.js').NestedCan}  NestedCan
+@typedef {import('../typedef.js').SignOpts}  SignOpts
+*/
+
+/**
+SignArrayOpts are the options for SignCozeArray.  Like SignOpts, but with canon
+for each pay.  opts.iat sets the same iat for all pays, e.g. for reproducible
+test data, otherwise each pay has the time it was signed.
+@typedef  {SignOpts & {canon: (NestedCan|undefined)}}  SignArrayOpts
+*/
+
+/**
+SignedCoze - Signing result for a single pay in an array of pays.  coze is
+null if the pay could not be signed, in which case error is set.
+@typedef  {object}       SignedCoze
+@property {Coze|null}    coze
+@property {Error|null}   error
+*/
+
+/**
+SignCozeArray signs an array of pays with one private Coze key and returns an
+array of "SignedCoze" results in input order.  Like Sign, `alg`, `iat`, and
+`tmb` are set first and in that order, and set values are not replaced and
+must match, but the key is checked, imported, and thumbprinted once for all
+pays, and all pays are signed concurrently.  A pay that fails (e.g. JSON with
+duplicate fields or a mismatched alg) does not abort the batch and is instead
+reported with its error.  Pays missing fields of opts.canon fail with ERR_CANON_MISSING,
+instead of the fields being omitted.  Pays may be objects or JSON strings and
+are not modified.
+@param  {Array<Pay|string>}  pays
+@param  {Key}                cozeKey   A private Coze key.
+@param  {SignArrayOpts}      [opts]
+@return {SignedCoze[]}
+@throws {error}              Fails on revoked or public key, opts.iat, or opts.hash.
+*/
+async function SignCozeArray(pays, cozeKey, opts) {
+	if (isEmpty(opts)) {
+		opts = {};
+	}
+	if (IsRevoked(cozeKey)) {
+		throw new CozeKeyError("SignCozeArray: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
+	}
+	if (opts.iat !== undefined && (!Number.isSafeInteger(opts.iat) || opts.iat < 0)) {
+		throw new CozeError("SignCozeArray: opts.iat must be a non-negative integer.", ErrCodes.IatInvalid, {
+			field: "iat"
+		});
+	}
+	if (isEmpty(cozeKey.d)) {
+		throw new CozeKeyError("SignCozeArray: cozeKey must be private.", ErrCodes.KeyInvalid, {
+			field: "d"
+		});
+	}
+	let hsh = HashAlg(cozeKey.alg);
+	if (!isEmpty(opts.hash) && opts.hash !== hsh) {
+		throw new CozeAlgError(`SignCozeArray: hash not valid for alg: ${opts.hash} is not ${cozeKey.alg}'s hash ${hsh}.`, ErrCodes.HashInvalid, {
+			alg: cozeKey.alg
+		});
+	}
+	let tmb = await Thumbprint(cozeKey);
+	// Deterministic signatures use `d` from Javascript and not a CryptoKey.
+	let cryptoKey = null;
+	if (opts.deterministic !== true) {
+		cryptoKey = await CryptoKey.FromCozeKey(cozeKey);
+	}
+	return Promise.all(pays.map(p => signOne(p, cozeKey, cryptoKey, tmb, opts)));
+}
+
+/**
+signOne signs a single pay from an array and returns its SignedCoze result.
+Errors are captured and never thrown.
+@param  {Pay|string}        p
+@param  {Key}               cozeKey
+@param  {CryptoKey|null}    cryptoKey   If null, SignPay is used.
+@param  {Tmb}               tmb
+@param  {SignArrayOpts}     opts
+@return {SignedCoze}
 */
+async function signOne(p, cozeKey, cryptoKey, tmb, opts) {
+	/** @type {SignedCoze} */
+	let r = {
+		coze: null,
+		error: null,
+	};
+	try {
+		let pay = typeof p === "string" ? ParseStrict(p) : {
+			...p
+		};
+		// Like Sign, set values are not replaced and must match.
+		if (!isEmpty(pay.alg) && pay.alg !== cozeKey.alg) {
+			throw new CozeAlgError("SignCozeArray: Coze key alg mismatch with pay.alg.", ErrCodes.AlgMismatch, {
+				alg: pay.alg
+			});
+		}
+		if (!isEmpty(pay.tmb) && pay.tmb !== tmb) {
+			throw new CozeKeyError("SignCozeArray: Coze key tmb mismatch with pay.tmb.", ErrCodes.TmbMismatch, {
+				field: "tmb"
+			});
+		}
+		let iat = pay.iat;
+		if (iat === undefined) {
+			iat = opts.iat !== undefined ? opts.iat : Math.round((Date.now() / 1000));
+		} else if (opts.iat !== undefined && opts.iat !== iat) {
+			throw new CozeError(`SignCozeArray: pay.iat (${iat}) mismatch with opts.iat (${opts.iat}).`, ErrCodes.IatInvalid, {
+				field: "iat"
+			});
+		}
+		let std = {
+			alg: cozeKey.alg,
+			iat: iat,
+			tmb: tmb,
+		};
+		for (const [k, v] of Object.entries(pay)) {
+			if (!(k in std)) {
+				std[k] = v;
+			}
+		}
+		pay = std;
+		if (opts.normalizeUnicode === true) {
+			pay = NormalizeUnicode(pay);
+		}
+		if (!isEmpty(opts.canon)) {
+			let names = Array.isArray(opts.canon) ? opts.canon.flatMap(e => typeof e === "string" ? [e] : Object.keys(e)) : Object.keys(opts.canon);
+			let missing = names.filter(f => pay[f] === undefined);
+			if (missing.length > 0) {
+				throw new CozeCanonError("SignCozeArray: pay missing field(s) required by canon: " + missing.join(", "), ErrCodes.CanonMissing, {
+					fields: missing
+				});
+			}
+			pay = await Canonical(pay, opts.canon);
+		}
+		let sig;
+		if (cryptoKey === null) {
+			sig = await SignPay(JSON.stringify(pay), cozeKey, opts);
+		} else {
+			sig = await CryptoKey.SignString(cryptoKey, JSON.stringify(pay));
+		}
+		r.coze = {
+			pay: pay,
+			sig: sig
+		};
+	} catch (e) {
+		r.error = e;
+	}
+	return r;
+}
 
 /**
-VerifiedArray - Used when verifying array of cozies.
+VerifiedCoze - Verification result for a single coze in an array of cozies.
 
-- VerifiedAll:     Indicates if whole array was verified. False on error or
-                   if anything was not verified.
-- VerifiedCount:   Number of objects verified.
-- FailedCount:     Number of objects that failed verification.
-- FailedCozies:    Objects that failed verification.
-- FailedPositions: Position in input array of all failed cozies.  
-@typedef  {object}    VerifiedCozeArray
-@property {boolean}   VerifiedAll
-@property {number}    VerifiedCount
-@property {number}    FailedCount
-@property {Coze[]}    FailedCozies
-@property {Number[]}  FailedPositions
+- czd:       Coze digest.  Empty if it could not be calculated.
+- tmb:       Calculated thumbprint of the key used for verification, or
+             pay.tmb if no key was found.
+- verified:  Whether or not the coze was verified.
+- error:     Error encountered while verifying, or null.
+@typedef  {object}       VerifiedCoze
+@property {Czd}          czd
+@property {Tmb}          tmb
+@property {boolean}      verified
+@property {Error|null}   error
 */
 
 /**
-VerifyCozeArray verifies an array of `coze`s and returns a single
-"VerifiedArray" object.  If a coze has a key, it is ignored, the given
-cozeKey is always used.  Assumes that object has no duplicate fields since
-this is disallowed in Javascript.
-@param  {coze[]}           coze       Array of Coze objects.
-@param  {Key}              cozeKey    Javascript object. Coze Key.
-@return {VerifiedArray}
+VerifyCozeArray verifies an array of `coze`s and returns an array of
+"VerifiedCoze" results in input order.  All verifications are started
+concurrently.  A malformed coze (e.g. missing sig or pay, or invalid JSON) does
+not abort the batch and is instead reported as not verified with the error
+attached.  Array elements may be Coze objects, encapsulated cozies
+(`{"coze":{...}}`), or JSON strings of either.  As in Verify, a coze's embedded
+key must match the given key, otherwise it is not verified.  If cozeKey is not
+given, each coze is verified with its embedded key, which is checked as in
+Verify, and a coze without one is reported with code ERR_KEY_INVALID.  cozeKey
+may be a keyring (See LookupKey) so that cozies signed by different keys are
+verified in one call, each with the key matching its pay.tmb.  A coze without
+a matching key is reported with code ERR_KEY_NOT_FOUND.  If `coze` is not an
+array, the result of Verify() is returned.
+@param  {Coze[]}           coze       Array of Coze objects.
+@param  {Key|Keyring}      [cozeKey]  Coze Key or keyring.
+@return {VerifiedCoze[]}
 @throws {error}
 */
 async function VerifyCozeArray(coze, cozeKey) {
 	if (!Array.isArray(coze)) {
-		return Verify(coze, cozeKey)
+		return Verify(coze, cozeKey);
 	}
+	return Promise.all(coze.map(c => verifyOne(c, cozeKey)));
+};
 
-	/** @type {VerifiedCozeArray} */
-	var v = {
-		VerifiedAll: false,
-		VerifiedCount: 0,
-		FailedCount: 0,
-		FailedCozies: [],
-		FailedPosition:[],
+/**
+verifyOne verifies a single coze from an array and returns its VerifiedCoze
+result.  Errors are captured and never thrown.
+@param  {Coze|string}   c          Coze, encapsulated coze, or JSON string.
+@param  {Key|Keyring}   cozeKey    Coze Key or keyring.
+@return {VerifiedCoze}
+*/
+async function verifyOne(c, cozeKey) {
+	/** @type {VerifiedCoze} */
+	let v = {
+		czd: "",
+		tmb: "",
+		verified: false,
+		error: null,
 	};
-
-	let i = 0;
-	let copy = [...coze]; // Copy so original isn't modified.
-	for (let c of copy) {
-
+	try {
+		if (typeof c === "string") {
+			c = ParseStrict(c);
+		}
+		if (isEmpty(c)) {
+			throw new CozeVerifyError("VerifyCozeArray: coze is empty.", ErrCodes.PayMissing, {
+				field: "pay"
+			});
+		}
 		if (!isEmpty(c.coze)) { // "coze" encapsulated?
 			c = c.coze;
 		}
-
-		let valid = await Verify(c, cozeKey);
-		if (valid) {
-			v.VerifiedCount++;
-		} else {
-			v.FailedCount++;
-			v.FailedCozies.push(c);
-			v.FailedPosition.push(i);
+		if (isEmpty(c.pay)) {
+			throw new CozeVerifyError("VerifyCozeArray: coze.pay must exist.", ErrCodes.PayMissing, {
+				field: "pay"
+			});
 		}
-		i++;
+		if (isEmpty(c.sig)) {
+			throw new CozeVerifyError("VerifyCozeArray: coze.sig must exist.", ErrCodes.SigInvalid, {
+				field: "sig"
+			});
+		}
+		if (!isEmpty(c.pay.tmb)) {
+			v.tmb = c.pay.tmb;
+		}
+		if (cozeKey === undefined || cozeKey === null) { // Embedded key, checked by Verify.
+			if (isEmpty(c.key)) {
+				throw new CozeKeyError("VerifyCozeArray: no key given and coze has no embedded key.", ErrCodes.KeyInvalid, {
+					field: "key"
+				});
+			}
+			v.tmb = await Thumbprint(c.key);
+			v.czd = (await Meta(c, c.key.alg)).czd;
+			v.verified = await Verify(c);
+			return v;
+		}
+		let key = await LookupKey(cozeKey, c.pay.tmb);
+		v.tmb = await Thumbprint(key);
+		v.czd = (await Meta(c, key.alg)).czd;
+		v.verified = await Verify(c, key);
+	} catch (e) {
+		v.error = e;
 	}
+	return v;
+}
 
-	if (v.FailedCount == 0 && v.VerifiedCount > 1) {
-		v.VerifiedAll = true;
+/**
+MetaResult - Meta for a single coze in an array of cozies.  meta is null if it
+could not be calculated, in which case error is set.
+@typedef  {object}       MetaResult
+@property {Meta|null}    meta
+@property {Error|null}   error
+*/
+
+/**
+MetaSummary - Aggregate of MetaArray results.
+
+- count:   Number of cozies.
+- failed:  Number of cozies for which Meta failed.
+- tmb:     Count per pay.tmb.
+- typ:     Count per pay.typ.
+- iatMin:  Earliest pay.iat, or null if no coze has iat.
+- iatMax:  Latest pay.iat, or null if no coze has iat.
+@typedef  {object}                 MetaSummary
+@property {number}                 count
+@property {number}                 failed
+@property {Object<string,number>}  tmb
+@property {Object<string,number>}  typ
+@property {Iat|null}               iatMin
+@property {Iat|null}               iatMax
+*/
+
+/**
+MetaArray calculates Meta for an array of cozies and returns the results in
+input order along with a summary, e.g. for auditing an export of cozies.  No
+key is needed, but key, an alg or Coze key, may be given for cozies without
+pay.alg (See Meta).  All calculations are started concurrently.  Like
+VerifyCozeArray, elements may be Coze objects, encapsulated cozies, or JSON
+strings of either, and a malformed coze does not abort the run and is instead
+reported with its error.
+@param  {Coze[]}    cozies
+@param  {Alg|Key}   [key]
+@return {{results: MetaResult[], summary: MetaSummary}}
+@throws {error}
+*/
+async function MetaArray(cozies, key) {
+	let results = await Promise.all(cozies.map(c => metaOne(c, key)));
+	/** @type {MetaSummary} */
+	let summary = {
+		count: results.length,
+		failed: 0,
+		tmb: {},
+		typ: {},
+		iatMin: null,
+		iatMax: null,
+	};
+	for (const r of results) {
+		if (r.error !== null) {
+			summary.failed++;
+			continue;
+		}
+		let m = r.meta;
+		if (!isEmpty(m.tmb)) {
+			summary.tmb[m.tmb] = (summary.tmb[m.tmb] || 0) + 1;
+		}
+		if (!isEmpty(m.typ)) {
+			summary.typ[m.typ] = (summary.typ[m.typ] || 0) + 1;
+		}
+		if (typeof m.iat === "number") {
+			if (summary.iatMin === null || m.iat < summary.iatMin) {
+				summary.iatMin = m.iat;
+			}
+			if (summary.iatMax === null || m.iat > summary.iatMax) {
+				summary.iatMax = m.iat;
+			}
+		}
 	}
+	return {
+		results: results,
+		summary: summary
+	};
+}
 
-	return v;
-};
\ No newline at end of file
+/**
+metaOne calculates Meta for a single coze from an array and returns its
+MetaResult.  Errors are captured and never thrown.
+@param  {Coze|string}   c      Coze, encapsulated coze, or JSON string.
+@param  {Alg|Key}       [key]
+@return {MetaResult}
+*/
+async function metaOne(c, key) {
+	/** @type {MetaResult} */
+	let r = {
+		meta: null,
+		error: null,
+	};
+	try {
+		if (typeof c === "string") {
+			c = ParseStrict(c);
+		}
+		if (isEmpty(c)) {
+			throw new CozeVerifyError("MetaArray: coze is empty.", ErrCodes.PayMissing, {
+				field: "pay"
+			});
+		}
+		if (!isEmpty(c.coze)) { // "coze" encapsulated?
+			c = c.coze;
+		}
+		r.meta = await Meta(c, key);
+	} catch (e) {
+		r.error = e;
+	}
+	return r;
+}
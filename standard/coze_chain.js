@@ -0,0 +1,166 @@
+"use strict";
+
+import {
+	isEmpty,
+	Meta,
+	ParseStrict,
+	Sign,
+	Verify,
+} from '../coze.js';
+import {
+	LookupKey,
+} from '../key.js';
+import {
+	CozeVerifyError,
+	ErrCodes,
+} from '../error.js';
+
+export {
+	ChainGenesis,
+	SignChained,
+	VerifyChain,
+}
+
+/**
+@typedef {import('../typedef.js').Coze}     Coze
+@typedef {import('../typedef.js').Pay}      Pay
+@typedef {import('../typedef.js').Key}      Key
+@typedef {import('../typedef.js').Keyring}  Keyring
+@typedef {import('../typedef.js').Czd}      Czd
+@typedef {import('../typedef.js').SignOpts} SignOpts
+*/
+
+/**
+ChainGenesis is the `prv` of the first coze of a chain.  The first coze may
+instead omit `prv`.
+*/
+const ChainGenesis = "";
+
+/**
+ChainOpts are the options for VerifyChain.
+
+- prv:  Expected `prv` of the first coze, for verifying a chain that continues
+        from a known czd.  Defaults to ChainGenesis.
+@typedef  {object}  ChainOpts
+@property {Czd}     [prv]
+*/
+
+/**
+ChainResult is the result of VerifyChain.
+
+- verified:  All cozies verified and are linked.
+- czds:      czd of each coze up to the first break.
+- index:     Index of the first break, or -1.
+- reason:    Reason of the first break, or "".
+- error:     Error of the first break, or null.  ERR_PRV_MISMATCH for links that
+             do not match, otherwise the error from verifying the coze.
+@typedef  {object}      ChainResult
+@property {boolean}     verified
+@property {Czd[]}       czds
+@property {number}      index
+@property {string}      reason
+@property {Error|null}  error
+*/
+
+/**
+SignChained signs pay with cozeKey, linked to previousCoze by setting
+`pay.prv` to the czd of previousCoze, so that cozies form an append only
+chain.  If previousCoze is not given, pay is the first of the chain and `prv`
+is omitted.  Like Sign, `alg`, `tmb`, and `iat` are set.  pay is not modified.
+@param   {Pay}        pay
+@param   {Key}        cozeKey        Private Coze key.
+@param   {Coze}       [previousCoze]
+@param   {SignOpts}   [opts]         See Sign.
+@returns {Coze}
+@throws  {error}
+*/
+async function SignChained(pay, cozeKey, previousCoze, opts) {
+	let p = {
+		...pay
+	};
+	delete p.prv;
+	if (!isEmpty(previousCoze)) {
+		p.prv = (await Meta(previousCoze)).czd;
+	}
+	return Sign({
+		pay: p
+	}, cozeKey, null, opts);
+}
+
+/**
+VerifyChain verifies a chain of cozies, in order, as created by SignChained.
+Each coze must verify and its `prv` must be the czd of the previous coze,
+which is calculated and not taken from the given coze, so that reordering,
+omitting, or substituting a coze breaks the chain.  The first coze must omit
+`prv` or have `prv` ChainGenesis, unless opts.prv is given.  Verification
+stops at the first break, which is reported with its index and reason.  An
+empty chain is not verified.
+
+cozeKey may be a keyring (See LookupKey) for chains signed by several keys.
+Elements may be Coze objects, encapsulated cozies, or JSON strings of either.
+@param  {Array<Coze|string>}  cozies
+@param  {Key|Keyring}         cozeKey
+@param  {ChainOpts}           [opts]
+@return {ChainResult}
+*/
+async function VerifyChain(cozies, cozeKey, opts) {
+	/** @type {ChainResult} */
+	let r = {
+		verified: false,
+		czds: [],
+		index: -1,
+		reason: "",
+		error: null,
+	};
+	let prv = ChainGenesis;
+	if (!isEmpty(opts) && opts.prv !== undefined) {
+		prv = opts.prv;
+	}
+	if (cozies.length === 0) {
+		r.index = 0;
+		r.error = new CozeVerifyError("VerifyChain: chain is empty.", ErrCodes.PayMissing, {
+			field: "pay"
+		});
+		r.reason = r.error.message;
+		return r;
+	}
+
+	for (let i = 0; i < cozies.length; i++) {
+		try {
+			let c = cozies[i];
+			if (typeof c === "string") {
+				c = ParseStrict(c);
+			}
+			if (!isEmpty(c.coze)) { // "coze" encapsulated?
+				c = c.coze;
+			}
+			if (isEmpty(c.pay)) {
+				throw new CozeVerifyError(`VerifyChain: coze ${i} has no pay.`, ErrCodes.PayMissing, {
+					field: "pay"
+				});
+			}
+			let key = await LookupKey(cozeKey, c.pay.tmb);
+			if (!await Verify(c, key)) {
+				throw new CozeVerifyError(`VerifyChain: coze ${i} signature is invalid.`, ErrCodes.SigInvalid, {
+					field: "sig"
+				});
+			}
+			let got = c.pay.prv === undefined ? ChainGenesis : c.pay.prv;
+			if (got !== prv) {
+				let want = prv === ChainGenesis ? "the chain genesis" : `"${prv}"`;
+				throw new CozeVerifyError(`VerifyChain: coze ${i} prv "${got}" is not ${want}.`, ErrCodes.PrvMismatch, {
+					field: "prv"
+				});
+			}
+			prv = (await Meta(c, key.alg)).czd;
+			r.czds.push(prv);
+		} catch (e) {
+			r.index = i;
+			r.reason = e.message;
+			r.error = e;
+			return r;
+		}
+	}
+	r.verified = true;
+	return r;
+}
@@ -0,0 +1,167 @@
+"use strict";
+
+import {
+	isEmpty,
+	SignPay,
+	VerifyPay,
+} from '../coze.js';
+import {
+	Thumbprint,
+	IsRevoked,
+} from '../key.js';
+import {
+	CozeError,
+	CozeKeyError,
+	CozeAlgError,
+	ErrCodes,
+} from '../error.js';
+
+export {
+	SignAdd,
+	VerifyMulti,
+}
+
+/**
+@typedef {import('../typedef.js').Coze}  Coze
+@typedef {import('../typedef.js').Key}   Key
+@typedef {import('../typedef.js').Tmb}   Tmb
+@typedef {import('../typedef.js').Sig}   Sig
+*/
+
+/**
+MultiSig is a signature entry in a coze's `sigs` array.
+@typedef  {object}  MultiSig
+@property {Tmb}     tmb
+@property {Sig}     sig
+*/
+
+/**
+MultiOpts are the options for VerifyMulti.
+
+- threshold:  Number of distinct tmbs that must verify, an integer from 1 to the
+              number of given keys.  Defaults to the number of given keys.
+@typedef  {object}  MultiOpts
+@property {number}  [threshold]
+*/
+
+/**
+MultiResult is the result of VerifyMulti.
+
+- verified:  At least threshold distinct tmbs verified.
+- valid:     tmbs with a valid signature.
+- invalid:   tmbs with a matching key but an invalid signature.
+- unknown:   tmbs without a matching key.  Not an error by itself.
+@typedef  {object}   MultiResult
+@property {boolean}  verified
+@property {Tmb[]}    valid
+@property {Tmb[]}    invalid
+@property {Tmb[]}    unknown
+*/
+
+/**
+SignAdd signs coze.pay with cozeKey and adds `{tmb, sig}` to coze.sigs,
+creating `sigs` if needed, so that multiple parties may sign the identical
+pay.  pay and the single signature field `sig` are not altered.  If cozeKey has
+already signed, its entry is replaced so that a tmb appears only once.  If set,
+pay.alg must match cozeKey.alg.
+@param   {Coze}   coze
+@param   {Key}    cozeKey   Private Coze key.
+@returns {Coze}             The same coze, with updated `sigs`.
+@throws  {error}            Fails on revoked key or alg mismatch.
+*/
+async function SignAdd(coze, cozeKey) {
+	if (IsRevoked(cozeKey)) {
+		throw new CozeKeyError("SignAdd: Cannot sign with revoked key.", ErrCodes.KeyRevoked);
+	}
+	if (!isEmpty(coze.pay.alg) && coze.pay.alg !== cozeKey.alg) {
+		throw new CozeAlgError("SignAdd: Coze key alg mismatch with coze.pay.alg.", ErrCodes.AlgMismatch, {
+			alg: coze.pay.alg
+		});
+	}
+	let tmb = await Thumbprint(cozeKey);
+	let sig = await SignPay(JSON.stringify(coze.pay), cozeKey);
+	if (!Array.isArray(coze.sigs)) {
+		coze.sigs = [];
+	}
+	let entry = coze.sigs.find(s => s.tmb === tmb);
+	if (entry !== undefined) {
+		entry.sig = sig;
+	} else {
+		coze.sigs.push({
+			tmb: tmb,
+			sig: sig
+		});
+	}
+	return coze;
+}
+
+/**
+VerifyMulti verifies the signatures of a multi-signature coze against keys,
+matched by their calculated thumbprint and not by their declared tmb.  The
+single signature field `sig`, if present, is verified as an entry with tmb
+`pay.tmb`.  Duplicate tmbs count once and a tmb is valid only if all of its
+signatures are valid.  Revoked keys and keys with an alg
+other than pay.alg do not verify.  Unknown tmbs are reported but do not fail
+verification unless threshold cannot be met.
+@param   {Coze}        coze
+@param   {Key[]}       keys     Public Coze keys.
+@param   {MultiOpts}   [opts]
+@returns {MultiResult}
+@throws  {error}             Fails on invalid threshold or keys.
+*/
+async function VerifyMulti(coze, keys, opts) {
+	let threshold = keys.length;
+	if (!isEmpty(opts) && opts.threshold !== undefined) {
+		threshold = opts.threshold;
+	}
+	if (!Number.isInteger(threshold) || threshold < 1 || threshold > keys.length) {
+		throw new CozeError(`VerifyMulti: threshold must be an integer from 1 to ${keys.length}.`, ErrCodes.ThresholdInvalid, {
+			field: "threshold"
+		});
+	}
+	let byTmb = new Map();
+	for (const k of keys) {
+		byTmb.set(await Thumbprint(k), k);
+	}
+	let entries = Array.isArray(coze.sigs) ? [...coze.sigs] : [];
+	if (!isEmpty(coze.sig) && !isEmpty(coze.pay.tmb)) {
+		entries.push({
+			tmb: coze.pay.tmb,
+			sig: coze.sig
+		});
+	}
+
+	let pay = JSON.stringify(coze.pay);
+	let valid = new Set();
+	let invalid = new Set();
+	let unknown = new Set();
+	for (const e of entries) {
+		let key = byTmb.get(e.tmb);
+		if (key === undefined) {
+			unknown.add(e.tmb);
+			continue;
+		}
+		let ok = false;
+		if (!IsRevoked(key) && (isEmpty(coze.pay.alg) || coze.pay.alg === key.alg)) {
+			try {
+				ok = await VerifyPay(pay, key, e.sig);
+			} catch (err) {
+				ok = false;
+			}
+		}
+		if (ok) {
+			valid.add(e.tmb);
+		} else {
+			invalid.add(e.tmb);
+		}
+	}
+	for (const t of invalid) {
+		valid.delete(t);
+	}
+	return {
+		verified: valid.size >= threshold,
+		valid: [...valid],
+		invalid: [...invalid],
+		unknown: [...unknown],
+	};
+}
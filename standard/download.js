@@ -0,0 +1,35 @@
+"use strict";
+
+import {
+	CozeError,
+	ErrCodes,
+} from '../error.js';
+
+export {
+	DownloadJSON,
+}
+
+/**
+DownloadJSON has the browser download obj as a JSON file named filename.  The
+file is created in the browser and is not sent anywhere.
+@param   {any}     obj
+@param   {string}  filename   E.g. "coze_key_cLj8vsYt.json".
+@returns {void}
+@throws  {error}              Fails outside of a browser.
+*/
+function DownloadJSON(obj, filename) {
+	if (typeof document === "undefined") {
+		throw new CozeError("DownloadJSON: requires a browser.", ErrCodes.BrowserRequired);
+	}
+	let url = URL.createObjectURL(new Blob([JSON.stringify(obj, null, "\t")], {
+		type: "application/json"
+	}));
+	let a = document.createElement("a");
+	a.href = url;
+	a.download = filename;
+	document.body.appendChild(a);
+	a.click();
+	a.remove();
+	// Revoked later since some browsers start the download asynchronously.
+	setTimeout(() => URL.revokeObjectURL(url), 1000);
+}
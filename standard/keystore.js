@@ -0,0 +1,206 @@
+"use strict";
+
+import {
+	isEmpty,
+} from '../coze.js';
+import {
+	CryptoKey,
+} from '../cryptokey.js';
+import {
+	CozeError,
+	CozeKeyError,
+	ErrCodes,
+} from '../error.js';
+
+export {
+	StoreKey,
+	LoadKey,
+	ListKeys,
+	DeleteKey,
+	KeystoreUnavailableError,
+}
+
+/**
+@typedef {import('../typedef.js').Key}  Key
+@typedef {import('../typedef.js').Alg}  Alg
+@typedef {import('../typedef.js').Tmb}  Tmb
+*/
+
+/**
+StoredKey is a key stored in the keystore.
+
+- name:           Name given to StoreKey.
+- cozeKey:        Coze key.  For CryptoKey pairs, this is the public Coze key
+                  with `tmb`.  Usable by Verify, and when `d` is present, by
+                  Sign.
+- cryptoKeyPair:  Only for stored CryptoKey pairs.  The private CryptoKey may
+                  be non-extractable and is usable by SignCryptoKey.
+@typedef  {object}          StoredKey
+@property {string}          name
+@property {Key}             cozeKey
+@property {CryptoKeyPair}   [cryptoKeyPair]
+*/
+
+/**
+StoredKeyInfo is the public information of a stored key returned by ListKeys.
+@typedef  {object}   StoredKeyInfo
+@property {string}   name
+@property {Alg}      alg
+@property {Tmb}      tmb
+@property {boolean}  cryptoKey   Whether a CryptoKey pair is stored.
+*/
+
+// IndexedDB database and object store names.
+const dbName = "coze";
+const storeName = "keys";
+
+// db is the opened database, opened on first use.
+let db = null;
+
+/**
+KeystoreUnavailableError is thrown when IndexedDB is unavailable, such as in
+some private browsing modes or outside of a browser.  Its code is
+ERR_KEYSTORE_UNAVAILABLE.
+*/
+class KeystoreUnavailableError extends CozeError {
+	constructor(message) {
+		super(message, ErrCodes.KeystoreUnavailable);
+		this.name = "KeystoreUnavailableError";
+	}
+}
+
+/**
+StoreKey stores a Coze key or a CryptoKeyPair under `name`, replacing any key
+previously stored under `name`.  CryptoKeys are stored using structured clone,
+so non-extractable CryptoKeys remain non-extractable.  For CryptoKey pairs, the
+public Coze key is calculated and stored alongside the pair.
+@param   {string}             name
+@param   {Key|CryptoKeyPair}  key    Coze key or CryptoKeyPair.
+@returns {StoredKey}
+@throws  {error}                     KeystoreUnavailableError if no IndexedDB.
+*/
+async function StoreKey(name, key) {
+	if (isEmpty(name)) {
+		throw new CozeKeyError("StoreKey: name must be set.", ErrCodes.KeyInvalid, {
+			field: "name"
+		});
+	}
+	/** @type {StoredKey} */
+	let sk = {
+		name: name,
+	};
+	if (!isEmpty(key.privateKey) || !isEmpty(key.publicKey)) {
+		if (isEmpty(key.publicKey)) {
+			throw new CozeKeyError("StoreKey: CryptoKeyPair must have publicKey.", ErrCodes.KeyInvalid, {
+				field: "publicKey"
+			});
+		}
+		sk.cozeKey = await CryptoKey.ToCozeKey(key.publicKey);
+		sk.cryptoKeyPair = key;
+	} else {
+		if (isEmpty(key.alg) || isEmpty(key.x)) {
+			throw new CozeKeyError("StoreKey: Coze key must have alg and x.", ErrCodes.KeyInvalid, {
+				field: "x"
+			});
+		}
+		sk.cozeKey = key;
+	}
+	let store = await tx("readwrite");
+	await request(store.put(sk));
+	return sk;
+}
+
+/**
+LoadKey returns the key stored under `name`, or null if there is none.
+@param   {string}     name
+@returns {StoredKey|null}
+@throws  {error}
+*/
+async function LoadKey(name) {
+	let store = await tx("readonly");
+	let sk = await request(store.get(name));
+	if (sk === undefined) {
+		return null;
+	}
+	return sk;
+}
+
+/**
+ListKeys returns the public information of all stored keys.
+@returns {StoredKeyInfo[]}
+@throws  {error}
+*/
+async function ListKeys() {
+	let store = await tx("readonly");
+	let all = await request(store.getAll());
+	return all.map(sk => ({
+		name: sk.name,
+		alg: sk.cozeKey.alg,
+		tmb: sk.cozeKey.tmb,
+		cryptoKey: !isEmpty(sk.cryptoKeyPair),
+	}));
+}
+
+/**
+DeleteKey deletes the key stored under `name`.  Deleting a name that is not
+stored is not an error.
+@param   {string}  name
+@returns {void}
+@throws  {error}
+*/
+async function DeleteKey(name) {
+	let store = await tx("readwrite");
+	await request(store.delete(name));
+}
+
+/**
+openDB opens the keystore database, creating it if needed.
+@returns {IDBDatabase}
+@throws  {error}        KeystoreUnavailableError
+*/
+async function openDB() {
+	if (typeof indexedDB === "undefined" || indexedDB === null) {
+		throw new KeystoreUnavailableError("Keystore: IndexedDB is unavailable.");
+	}
+	try {
+		var req = indexedDB.open(dbName, 1);
+	} catch (e) {
+		throw new KeystoreUnavailableError("Keystore: IndexedDB is unavailable: " + e);
+	}
+	req.onupgradeneeded = () => {
+		req.result.createObjectStore(storeName, {
+			keyPath: "name"
+		});
+	};
+	try {
+		return await request(req);
+	} catch (e) {
+		// E.g. Firefox private browsing throws InvalidStateError on open.
+		throw new KeystoreUnavailableError("Keystore: IndexedDB is unavailable: " + e);
+	}
+}
+
+/**
+tx returns the key object store in a new transaction.
+@param   {string}          mode   "readonly" or "readwrite".
+@returns {IDBObjectStore}
+@throws  {error}
+*/
+async function tx(mode) {
+	if (db === null) {
+		db = await openDB();
+	}
+	return db.transaction(storeName, mode).objectStore(storeName);
+}
+
+/**
+request wraps an IDBRequest in a promise.
+@param   {IDBRequest}  req
+@returns {any}                Request result.
+*/
+function request(req) {
+	return new Promise((resolve, reject) => {
+		req.onsuccess = () => resolve(req.result);
+		req.onerror = () => reject(req.error);
+	});
+}
@@ -0,0 +1,947 @@
+"use strict";
+
+import {
+	ParseStrict,
+} from '../coze.js';
+import {
+	CozeError,
+	ErrCodes,
+} from '../error.js';
+
+export {
+	CozeToQR,
+	QRToCoze,
+	QRMatrix,
+	QRText,
+}
+
+/**
+@typedef {import('../typedef.js').Coze}  Coze
+@typedef {import('../typedef.js').Key}   Key
+*/
+
+/**
+QROpts are the options for CozeToQR and QRMatrix.
+
+- errorCorrection:  "L", "M", "Q", or "H".  Default "M".  Higher levels
+                    tolerate more damage but hold less.
+- scale:            Pixels per module for canvas targets.  Default 4.
+- margin:           Quiet zone in modules.  Default 4, the minimum of the QR
+                    spec.
+@typedef  {object}  QROpts
+@property {string}  [errorCorrection]
+@property {number}  [scale]
+@property {number}  [margin]
+*/
+
+// QR Code Model 2 (ISO/IEC 18004), byte mode only.  Byte mode holds any UTF-8,
+// and compact JSON is mostly not numeric or alphanumeric, so other modes would
+// not make cozies noticeably smaller.  Structure follows Project Nayuki's QR
+// Code generator (MIT).
+
+// Error correction levels.  `bits` are the format bits.
+const eccLevels = {
+	L: {
+		ordinal: 0,
+		bits: 1
+	},
+	M: {
+		ordinal: 1,
+		bits: 0
+	},
+	Q: {
+		ordinal: 2,
+		bits: 3
+	},
+	H: {
+		ordinal: 3,
+		bits: 2
+	},
+};
+
+// Error correction codewords per block and number of blocks, indexed by
+// [ecc ordinal][version].  Index 0 is padding.
+const eccCodewordsPerBlock = [
+	[-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30],
+	[-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28],
+	[-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30],
+	[-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30],
+];
+const eccBlocks = [
+	[-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25],
+	[-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49],
+	[-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68],
+	[-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81],
+];
+
+/**
+CozeToQR draws a coze or key as a QR code on a canvas or SVG element.  The
+compact serialization is encoded (See QRText) so that the QR code is as small
+as possible.  For a canvas, the canvas is resized to fit.  Returns the encoded
+text.
+@param   {Coze|Key|string}  cozeOrKey
+@param   {HTMLCanvasElement|SVGSVGElement}  target
+@param   {QROpts}     [opts]
+@returns {string}
+@throws  {error}      ERR_QR_CAPACITY if too large for a QR code.
+*/
+function CozeToQR(cozeOrKey, target, opts) {
+	if (opts === undefined || opts === null) {
+		opts = {};
+	}
+	let text = QRText(cozeOrKey);
+	let matrix = QRMatrix(text, opts);
+	let scale = opts.scale > 0 ? opts.scale : 4;
+	let margin = opts.margin >= 0 ? opts.margin : 4;
+	let size = matrix.length + margin * 2;
+
+	if (typeof target.getContext === "function") {
+		target.width = size * scale;
+		target.height = size * scale;
+		let ctx = target.getContext("2d");
+		ctx.fillStyle = "#FFFFFF";
+		ctx.fillRect(0, 0, target.width, target.height);
+		ctx.fillStyle = "#000000";
+		for (let y = 0; y < matrix.length; y++) {
+			for (let x = 0; x < matrix.length; x++) {
+				if (matrix[y][x]) {
+					ctx.fillRect((x + margin) * scale, (y + margin) * scale, scale, scale);
+				}
+			}
+		}
+		return text;
+	}
+
+	// SVG
+	let path = [];
+	for (let y = 0; y < matrix.length; y++) {
+		for (let x = 0; x < matrix.length; x++) {
+			if (matrix[y][x]) {
+				path.push(`M${x + margin},${y + margin}h1v1h-1z`);
+			}
+		}
+	}
+	target.setAttribute("viewBox", `0 0 ${size} ${size}`);
+	target.setAttribute("shape-rendering", "crispEdges");
+	target.innerHTML = `<rect width="100%" height="100%" fill="#FFFFFF"/><path d="${path.join("")}" fill="#000000"/>`;
+	return text;
+}
+
+/**
+QRText returns the compact serialization of a coze or key for QR codes.  For
+cozies, only `pay`, `key`, and `sig` are included since `can`, `cad`, and
+`czd` may be calculated from them.  pay is not reordered since the signature
+is over the given order.
+@param   {Coze|Key|string}  cozeOrKey
+@returns {string}
+*/
+function QRText(cozeOrKey) {
+	if (typeof cozeOrKey === "string") {
+		cozeOrKey = ParseStrict(cozeOrKey);
+	}
+	if (typeof cozeOrKey.pay !== "object" || cozeOrKey.pay === null) {
+		return JSON.stringify(cozeOrKey);
+	}
+	let compact = {
+		pay: cozeOrKey.pay
+	};
+	if (cozeOrKey.key !== undefined) {
+		compact.key = cozeOrKey.key;
+	}
+	if (cozeOrKey.sig !== undefined) {
+		compact.sig = cozeOrKey.sig;
+	}
+	return JSON.stringify(compact);
+}
+
+/**
+QRMatrix returns the modules of the QR code for text as rows of booleans,
+where true is dark, without a quiet zone.  The smallest version (size) for
+the error correction level is used.
+@param   {string}    text
+@param   {QROpts}    [opts]
+@returns {boolean[][]}
+@throws  {error}     ERR_QR_CAPACITY if text is too large for a QR code.
+*/
+function QRMatrix(text, opts) {
+	let level = "M";
+	if (opts !== undefined && opts !== null && opts.errorCorrection !== undefined) {
+		level = opts.errorCorrection;
+	}
+	let ecl = eccLevels[level];
+	if (ecl === undefined) {
+		throw new CozeError(`QR: invalid error correction "${level}".  Must be L, M, Q, or H.`, ErrCodes.QRInvalid);
+	}
+	let data = new TextEncoder().encode(text);
+
+	let version = 1;
+	for (; version <= 40; version++) {
+		if (data.length <= byteCapacity(version, ecl)) {
+			break;
+		}
+	}
+	if (version > 40) {
+		let max = byteCapacity(40, ecl);
+		throw new CozeError(`QR: payload is ${data.length} bytes, exceeding the ${max} byte maximum for error correction ${level}.`, ErrCodes.QRCapacity, {
+			size: data.length,
+			max: max,
+		});
+	}
+
+	// Segment: mode, count, data, terminator, and padding.
+	let bits = [];
+	let put = (val, len) => {
+		for (let i = len - 1; i >= 0; i--) {
+			bits.push((val >>> i) & 1);
+		}
+	};
+	put(0x4, 4);
+	put(data.length, countBits(version));
+	for (const b of data) {
+		put(b, 8);
+	}
+	let capacity = numDataCodewords(version, ecl) * 8;
+	put(0, Math.min(4, capacity - bits.length));
+	put(0, (8 - bits.length % 8) % 8);
+	for (let pad = 0xEC; bits.length < capacity; pad ^= 0xEC ^ 0x11) {
+		put(pad, 8);
+	}
+	let codewords = new Uint8Array(bits.length / 8);
+	for (let i = 0; i < bits.length; i++) {
+		codewords[i >>> 3] |= bits[i] << (7 - (i & 7));
+	}
+
+	let qr = newQR(version);
+	drawCodewords(qr, addEcc(codewords, version, ecl));
+
+	// Use the mask with the lowest penalty.
+	let best = null;
+	for (let mask = 0; mask < 8; mask++) {
+		applyMask(qr, mask);
+		drawFormatBits(qr, ecl, mask);
+		let p = penalty(qr.modules);
+		if (best === null || p < best.penalty) {
+			best = {
+				mask: mask,
+				penalty: p
+			};
+		}
+		applyMask(qr, mask); // Undo, since XOR.
+	}
+	applyMask(qr, best.mask);
+	drawFormatBits(qr, ecl, best.mask);
+	return qr.modules;
+}
+
+/**
+QRToCoze decodes a QR code of a coze or key.  source may be an image, video,
+canvas, ImageBitmap, or ImageData.  BarcodeDetector is used when the browser
+supports it.  Otherwise, a built in decoder is used, which only decodes clean,
+upright QR codes like screenshots and QR codes drawn by CozeToQR, and not
+camera images.  Whitespace and URL wrappers (e.g.
+`https://example.com/#{"pay":...}`) are removed and the JSON is parsed with
+ParseStrict.  The result should then be verified as any other coze.
+@param   {HTMLImageElement|HTMLVideoElement|HTMLCanvasElement|ImageBitmap|ImageData} source
+@returns {Promise<Coze|Key>}
+@throws  {error}      Fails if no QR code is found or if it is not JSON.
+*/
+async function QRToCoze(source) {
+	let text;
+	if (typeof BarcodeDetector !== "undefined") {
+		let codes = await new BarcodeDetector({
+			formats: ["qr_code"]
+		}).detect(source);
+		if (codes.length === 0) {
+			throw new CozeError("QRToCoze: no QR code found.", ErrCodes.QRInvalid);
+		}
+		text = codes[0].rawValue;
+	} else {
+		text = decodeImage(imageData(source));
+	}
+	return ParseStrict(unwrap(text));
+}
+
+/**
+unwrap removes whitespace and URL wrappers around JSON in text.
+@param   {string}  text
+@returns {string}
+ */
+function unwrap(text) {
+	text = text.trim();
+	if (text.startsWith("{") || text.startsWith("[")) {
+		return text;
+	}
+	try {
+		text = decodeURIComponent(text);
+	} catch (e) {
+		// Not URI encoded.
+	}
+	let start = text.search(/[{[]/);
+	let end = Math.max(text.lastIndexOf("}"), text.lastIndexOf("]"));
+	if (start === -1 || end < start) {
+		throw new CozeError("QRToCoze: QR code does not contain JSON.", ErrCodes.QRInvalid);
+	}
+	return text.slice(start, end + 1);
+}
+
+/**
+imageData returns the ImageData of source, drawing it on a canvas if needed.
+@param   {any}        source
+@returns {ImageData}
+ */
+function imageData(source) {
+	if (source.data !== undefined && source.width > 0) {
+		return source; // ImageData, or an object like it.
+	}
+	if (typeof source.getContext === "function") {
+		return source.getContext("2d").getImageData(0, 0, source.width, source.height);
+	}
+	let width = source.videoWidth || source.naturalWidth || source.width;
+	let height = source.videoHeight || source.naturalHeight || source.height;
+	let canvas = document.createElement("canvas");
+	canvas.width = width;
+	canvas.height = height;
+	let ctx = canvas.getContext("2d");
+	ctx.drawImage(source, 0, 0);
+	return ctx.getImageData(0, 0, width, height);
+}
+
+///////////////////////////////////
+// Construction
+///////////////////////////////////
+
+/**
+newQR returns a QR code of version with function patterns drawn.  `modules`
+are rows of dark (true) modules and `isFunction` marks function modules.
+@param   {number}  version
+@returns {{version: number, size: number, modules: boolean[][], isFunction: boolean[][]}}
+ */
+function newQR(version) {
+	let size = version * 4 + 17;
+	let qr = {
+		version: version,
+		size: size,
+		modules: Array.from({
+			length: size
+		}, () => new Array(size).fill(false)),
+		isFunction: Array.from({
+			length: size
+		}, () => new Array(size).fill(false)),
+	};
+	let set = (x, y, dark) => {
+		qr.modules[y][x] = dark;
+		qr.isFunction[y][x] = true;
+	};
+
+	// Timing
+	for (let i = 0; i < size; i++) {
+		set(6, i, i % 2 === 0);
+		set(i, 6, i % 2 === 0);
+	}
+	// Finders, with separators.
+	for (const [cx, cy] of [[3, 3], [size - 4, 3], [3, size - 4]]) {
+		for (let dy = -4; dy <= 4; dy++) {
+			for (let dx = -4; dx <= 4; dx++) {
+				let x = cx + dx;
+				let y = cy + dy;
+				if (x >= 0 && x < size && y >= 0 && y < size) {
+					let dist = Math.max(Math.abs(dx), Math.abs(dy));
+					set(x, y, dist !== 2 && dist !== 4);
+				}
+			}
+		}
+	}
+	// Alignment, except where overlapping finders.
+	let pos = alignmentPositions(version);
+	for (let i = 0; i < pos.length; i++) {
+		for (let j = 0; j < pos.length; j++) {
+			if ((i === 0 && j === 0) || (i === 0 && j === pos.length - 1) || (i === pos.length - 1 && j === 0)) {
+				continue;
+			}
+			for (let dy = -2; dy <= 2; dy++) {
+				for (let dx = -2; dx <= 2; dx++) {
+					set(pos[i] + dx, pos[j] + dy, Math.max(Math.abs(dx), Math.abs(dy)) !== 1);
+				}
+			}
+		}
+	}
+	// Reserve format bits.  Drawn after masking.
+	qr.set = set;
+	drawFormatBits(qr, eccLevels.L, 0);
+	// Version
+	if (version >= 7) {
+		let rem = version;
+		for (let i = 0; i < 12; i++) {
+			rem = (rem << 1) ^ ((rem >>> 11) * 0x1F25);
+		}
+		let bits = version << 12 | rem;
+		for (let i = 0; i < 18; i++) {
+			let dark = ((bits >>> i) & 1) !== 0;
+			let a = size - 11 + i % 3;
+			let b = Math.floor(i / 3);
+			set(a, b, dark);
+			set(b, a, dark);
+		}
+	}
+	return qr;
+}
+
+/**
+formatBits returns the 15 format bits, with BCH error correction and mask.
+@param   {object}  ecl
+@param   {number}  mask
+@returns {number}
+ */
+function formatBits(ecl, mask) {
+	let data = ecl.bits << 3 | mask;
+	let rem = data;
+	for (let i = 0; i < 10; i++) {
+		rem = (rem << 1) ^ ((rem >>> 9) * 0x537);
+	}
+	return (data << 10 | rem) ^ 0x5412;
+}
+
+/**
+formatPositions returns the [x, y] of bit i (0 to 14) of both copies of the
+format bits.
+@param   {number}  size
+@returns {Array<Array<number[]>>}  [copy1, copy2], each 15 [x, y].
+ */
+function formatPositions(size) {
+	let a = [];
+	let b = [];
+	for (let i = 0; i < 15; i++) {
+		if (i < 6) {
+			a.push([8, i]);
+		} else if (i < 8) {
+			a.push([8, i + 1]);
+		} else if (i === 8) {
+			a.push([7, 8]);
+		} else {
+			a.push([14 - i, 8]);
+		}
+		b.push(i < 8 ? [size - 1 - i, 8] : [8, size - 15 + i]);
+	}
+	return [a, b];
+}
+
+/**
+drawFormatBits draws the format bits for ecl and mask, and the dark module.
+@param   {object}  qr
+@param   {object}  ecl
+@param   {number}  mask
+@returns {void}
+ */
+function drawFormatBits(qr, ecl, mask) {
+	let bits = formatBits(ecl, mask);
+	for (const copy of formatPositions(qr.size)) {
+		for (let i = 0; i < 15; i++) {
+			qr.set(copy[i][0], copy[i][1], ((bits >>> i) & 1) !== 0);
+		}
+	}
+	qr.set(8, qr.size - 8, true);
+}
+
+/**
+alignmentPositions returns the alignment pattern center coordinates.
+@param   {number}    version
+@returns {number[]}
+ */
+function alignmentPositions(version) {
+	if (version === 1) {
+		return [];
+	}
+	let num = Math.floor(version / 7) + 2;
+	let step = Math.floor((version * 8 + num * 3 + 5) / (num * 4 - 4)) * 2;
+	let result = [6];
+	for (let pos = version * 4 + 10; result.length < num; pos -= step) {
+		result.splice(1, 0, pos);
+	}
+	return result;
+}
+
+/**
+rawModules returns the number of data and error correction modules.
+@param   {number}  version
+@returns {number}
+ */
+function rawModules(version) {
+	let result = (16 * version + 128) * version + 64;
+	if (version >= 2) {
+		let num = Math.floor(version / 7) + 2;
+		result -= (25 * num - 10) * num - 55;
+		if (version >= 7) {
+			result -= 36;
+		}
+	}
+	return result;
+}
+
+/**
+numDataCodewords returns the number of data codewords for version and ecl.
+@param   {number}  version
+@param   {object}  ecl
+@returns {number}
+ */
+function numDataCodewords(version, ecl) {
+	return Math.floor(rawModules(version) / 8) - eccCodewordsPerBlock[ecl.ordinal][version] * eccBlocks[ecl.ordinal][version];
+}
+
+/**
+countBits returns the size of the byte mode character count.
+@param   {number}  version
+@returns {number}
+ */
+function countBits(version) {
+	return version < 10 ? 8 : 16;
+}
+
+/**
+byteCapacity returns the maximum bytes in byte mode for version and ecl.
+@param   {number}  version
+@param   {object}  ecl
+@returns {number}
+ */
+function byteCapacity(version, ecl) {
+	return Math.floor((numDataCodewords(version, ecl) * 8 - 4 - countBits(version)) / 8);
+}
+
+/**
+addEcc splits data into blocks, adds Reed-Solomon error correction to each,
+and interleaves the blocks.
+@param   {Uint8Array}  data
+@param   {number}      version
+@param   {object}      ecl
+@returns {Uint8Array}
+ */
+function addEcc(data, version, ecl) {
+	let l = blockLayout(version, ecl);
+	let divisor = rsDivisor(l.eccLen);
+	let blocks = [];
+	for (let i = 0, k = 0; i < l.numBlocks; i++) {
+		let len = l.shortDataLen + (i < l.numShort ? 0 : 1);
+		let dat = Array.from(data.slice(k, k + len));
+		k += len;
+		blocks.push({
+			data: dat,
+			ecc: rsRemainder(dat, divisor)
+		});
+	}
+	let result = [];
+	for (let i = 0; i <= l.shortDataLen; i++) {
+		for (const b of blocks) {
+			if (i < b.data.length) {
+				result.push(b.data[i]);
+			}
+		}
+	}
+	for (let i = 0; i < l.eccLen; i++) {
+		for (const b of blocks) {
+			result.push(b.ecc[i]);
+		}
+	}
+	return Uint8Array.from(result);
+}
+
+/**
+blockLayout returns the block structure for version and ecl.  Long blocks
+have one more data codeword than short blocks and come last.
+@param   {number}  version
+@param   {object}  ecl
+@returns {{numBlocks: number, eccLen: number, numShort: number, shortDataLen: number}}
+ */
+function blockLayout(version, ecl) {
+	let numBlocks = eccBlocks[ecl.ordinal][version];
+	let eccLen = eccCodewordsPerBlock[ecl.ordinal][version];
+	let raw = Math.floor(rawModules(version) / 8);
+	return {
+		numBlocks: numBlocks,
+		eccLen: eccLen,
+		numShort: numBlocks - raw % numBlocks,
+		shortDataLen: Math.floor(raw / numBlocks) - eccLen,
+	};
+}
+
+/**
+codewordPositions returns the [x, y] of each data bit in placement order,
+zigzagging in column pairs from the bottom right.
+@param   {object}  qr
+@returns {Array<number[]>}
+ */
+function codewordPositions(qr) {
+	let pos = [];
+	for (let right = qr.size - 1; right >= 1; right -= 2) {
+		if (right === 6) {
+			right = 5; // Skip the vertical timing column.
+		}
+		let upward = ((right + 1) & 2) === 0;
+		for (let v = 0; v < qr.size; v++) {
+			for (let j = 0; j < 2; j++) {
+				let x = right - j;
+				let y = upward ? qr.size - 1 - v : v;
+				if (!qr.isFunction[y][x]) {
+					pos.push([x, y]);
+				}
+			}
+		}
+	}
+	return pos;
+}
+
+/**
+drawCodewords draws codewords on the data modules.  Remainder modules are
+left light.
+@param   {object}      qr
+@param   {Uint8Array}  codewords
+@returns {void}
+ */
+function drawCodewords(qr, codewords) {
+	let pos = codewordPositions(qr);
+	for (let i = 0; i < codewords.length * 8; i++) {
+		qr.modules[pos[i][1]][pos[i][0]] = ((codewords[i >>> 3] >>> (7 - (i & 7))) & 1) !== 0;
+	}
+}
+
+/**
+applyMask XORs mask with the data modules.
+@param   {object}  qr
+@param   {number}  mask   0 to 7.
+@returns {void}
+ */
+function applyMask(qr, mask) {
+	for (let y = 0; y < qr.size; y++) {
+		for (let x = 0; x < qr.size; x++) {
+			let invert;
+			switch (mask) {
+				case 0:
+					invert = (x + y) % 2 === 0;
+					break;
+				case 1:
+					invert = y % 2 === 0;
+					break;
+				case 2:
+					invert = x % 3 === 0;
+					break;
+				case 3:
+					invert = (x + y) % 3 === 0;
+					break;
+				case 4:
+					invert = (Math.floor(x / 3) + Math.floor(y / 2)) % 2 === 0;
+					break;
+				case 5:
+					invert = x * y % 2 + x * y % 3 === 0;
+					break;
+				case 6:
+					invert = (x * y % 2 + x * y % 3) % 2 === 0;
+					break;
+				case 7:
+					invert = ((x + y) % 2 + x * y % 3) % 2 === 0;
+					break;
+			}
+			if (!qr.isFunction[y][x] && invert) {
+				qr.modules[y][x] = !qr.modules[y][x];
+			}
+		}
+	}
+}
+
+/**
+penalty returns the mask penalty score of modules (ISO/IEC 18004 7.8.3).
+@param   {boolean[][]}  modules
+@returns {number}
+ */
+function penalty(modules) {
+	let size = modules.length;
+	let score = 0;
+	let dark = 0;
+	let lines = [];
+	for (let i = 0; i < size; i++) {
+		lines.push(modules[i].map(m => m ? "1" : "0").join(""));
+		lines.push(modules.map(row => row[i] ? "1" : "0").join(""));
+	}
+	for (const line of lines) {
+		// Runs of 5 or more of the same color.
+		for (const run of line.match(/0{5,}|1{5,}/g) || []) {
+			score += run.length - 2;
+		}
+		// Finder like patterns, with 4 light modules (or the edge) on a side.
+		let padded = "0000" + line + "0000";
+		for (let i = padded.indexOf("1011101"); i !== -1; i = padded.indexOf("1011101", i + 1)) {
+			if (padded.startsWith("0000", i - 4) || padded.startsWith("0000", i + 7)) {
+				score += 40;
+			}
+		}
+	}
+	for (let y = 0; y < size; y++) {
+		for (let x = 0; x < size; x++) {
+			if (modules[y][x]) {
+				dark++;
+			}
+			// 2x2 blocks of the same color.
+			if (x < size - 1 && y < size - 1) {
+				let c = modules[y][x];
+				if (c === modules[y][x + 1] && c === modules[y + 1][x] && c === modules[y + 1][x + 1]) {
+					score += 3;
+				}
+			}
+		}
+	}
+	// Balance of dark and light modules.
+	let total = size * size;
+	score += (Math.ceil(Math.abs(dark * 20 - total * 10) / total) - 1) * 10;
+	return score;
+}
+
+///////////////////////////////////
+// Reed-Solomon over GF(2^8), polynomial 0x11D.
+///////////////////////////////////
+
+/**
+gfMul multiplies x and y in GF(2^8).
+@param   {number}  x
+@param   {number}  y
+@returns {number}
+ */
+function gfMul(x, y) {
+	let z = 0;
+	for (let i = 7; i >= 0; i--) {
+		z = (z << 1) ^ ((z >>> 7) * 0x11D);
+		z ^= ((y >>> i) & 1) * x;
+	}
+	return z;
+}
+
+/**
+rsDivisor returns the Reed-Solomon generator polynomial of degree, highest
+coefficient first, excluding the leading 1.
+@param   {number}    degree
+@returns {number[]}
+ */
+function rsDivisor(degree) {
+	let result = new Array(degree).fill(0);
+	result[degree - 1] = 1;
+	let root = 1;
+	for (let i = 0; i < degree; i++) {
+		for (let j = 0; j < degree; j++) {
+			result[j] = gfMul(result[j], root);
+			if (j + 1 < degree) {
+				result[j] ^= result[j + 1];
+			}
+		}
+		root = gfMul(root, 0x02);
+	}
+	return result;
+}
+
+/**
+rsRemainder returns the Reed-Solomon error correction codewords of data.
+@param   {number[]}  data
+@param   {number[]}  divisor
+@returns {number[]}
+ */
+function rsRemainder(data, divisor) {
+	let result = new Array(divisor.length).fill(0);
+	for (const b of data) {
+		let factor = b ^ result.shift();
+		result.push(0);
+		for (let i = 0; i < divisor.length; i++) {
+			result[i] ^= gfMul(divisor[i], factor);
+		}
+	}
+	return result;
+}
+
+///////////////////////////////////
+// Decoding
+///////////////////////////////////
+
+/**
+decodeImage decodes a clean, upright QR code surrounded by a light quiet zone.
+The module grid is found from the top left finder pattern and the width of
+the code.
+@param   {ImageData}  img
+@returns {string}
+@throws  {error}
+ */
+function decodeImage(img) {
+	let w = img.width;
+	let h = img.height;
+	let lum = new Float32Array(w * h);
+	let min = 255;
+	let max = 0;
+	for (let i = 0; i < w * h; i++) {
+		let l = (img.data[i * 4] * 299 + img.data[i * 4 + 1] * 587 + img.data[i * 4 + 2] * 114) / 1000;
+		lum[i] = l;
+		min = Math.min(min, l);
+		max = Math.max(max, l);
+	}
+	let threshold = (min + max) / 2;
+	let dark = (x, y) => lum[Math.floor(y) * w + Math.floor(x)] < threshold;
+
+	let notFound = () => new CozeError("QRToCoze: no QR code found.", ErrCodes.QRInvalid);
+	let x0 = -1;
+	let y0 = -1;
+	for (let y = 0; y < h && y0 === -1; y++) {
+		for (let x = 0; x < w; x++) {
+			if (dark(x, y)) {
+				x0 = x;
+				y0 = y;
+				break;
+			}
+		}
+	}
+	if (y0 === -1) {
+		throw notFound();
+	}
+	// Top left finder is 7 modules wide.
+	let run = 0;
+	while (x0 + run < w && dark(x0 + run, y0)) {
+		run++;
+	}
+	let module = run / 7;
+	// Right edge of the top right finder.
+	let row = y0 + module / 2;
+	let x1 = w - 1;
+	while (x1 > x0 && !dark(x1, row)) {
+		x1--;
+	}
+	let size = Math.round((x1 - x0 + 1) / module);
+	let version = (size - 17) / 4;
+	if (!Number.isInteger(version) || version < 1 || version > 40) {
+		throw notFound();
+	}
+	module = (x1 - x0 + 1) / size;
+	if (y0 + size * module > h) {
+		throw notFound();
+	}
+
+	let qr = newQR(version);
+	for (let y = 0; y < size; y++) {
+		for (let x = 0; x < size; x++) {
+			qr.modules[y][x] = dark(x0 + (x + 0.5) * module, y0 + (y + 0.5) * module);
+		}
+	}
+	return decodeMatrix(qr);
+}
+
+/**
+decodeMatrix decodes the sampled modules of qr.  Error correction is only
+checked, not applied, so damaged codes fail.
+@param   {object}  qr    See newQR.
+@returns {string}
+@throws  {error}
+ */
+function decodeMatrix(qr) {
+	let invalid = (msg) => new CozeError("QRToCoze: " + msg, ErrCodes.QRInvalid);
+
+	// Format, from either copy, tolerating up to 3 bit errors.
+	let best = null;
+	for (const copy of formatPositions(qr.size)) {
+		let read = 0;
+		for (let i = 0; i < 15; i++) {
+			read |= (qr.modules[copy[i][1]][copy[i][0]] ? 1 : 0) << i;
+		}
+		for (const level of Object.keys(eccLevels)) {
+			for (let mask = 0; mask < 8; mask++) {
+				let diff = formatBits(eccLevels[level], mask) ^ read;
+				let dist = 0;
+				for (; diff !== 0; diff &= diff - 1) {
+					dist++;
+				}
+				if (best === null || dist < best.dist) {
+					best = {
+						ecl: eccLevels[level],
+						mask: mask,
+						dist: dist
+					};
+				}
+			}
+		}
+	}
+	if (best.dist > 3) {
+		throw invalid("unreadable format.");
+	}
+	applyMask(qr, best.mask);
+
+	let pos = codewordPositions(qr);
+	let raw = new Uint8Array(Math.floor(rawModules(qr.version) / 8));
+	for (let i = 0; i < raw.length * 8; i++) {
+		if (qr.modules[pos[i][1]][pos[i][0]]) {
+			raw[i >>> 3] |= 1 << (7 - (i & 7));
+		}
+	}
+
+	// De-interleave and check error correction.
+	let l = blockLayout(qr.version, best.ecl);
+	let blocks = [];
+	for (let i = 0; i < l.numBlocks; i++) {
+		blocks.push([]);
+	}
+	let k = 0;
+	for (let i = 0; i <= l.shortDataLen; i++) {
+		for (let j = 0; j < l.numBlocks; j++) {
+			if (i < l.shortDataLen || j >= l.numShort) {
+				blocks[j].push(raw[k++]);
+			}
+		}
+	}
+	let divisor = rsDivisor(l.eccLen);
+	let data = [];
+	let eccStart = k;
+	for (let j = 0; j < l.numBlocks; j++) {
+		let ecc = [];
+		for (let i = 0; i < l.eccLen; i++) {
+			ecc.push(raw[eccStart + i * l.numBlocks + j]);
+		}
+		if (rsRemainder(blocks[j], divisor).some((b, i) => b !== ecc[i])) {
+			throw invalid("damaged QR code.");
+		}
+		data.push(...blocks[j]);
+	}
+
+	// Segments
+	let bit = 0;
+	let read = (len) => {
+		let v = 0;
+		for (let i = 0; i < len; i++, bit++) {
+			v = (v << 1) | ((data[bit >>> 3] >>> (7 - (bit & 7))) & 1);
+		}
+		return v;
+	};
+	let alnum = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:";
+	let bytes = [];
+	while (bit + 4 <= data.length * 8) {
+		let mode = read(4);
+		if (mode === 0) {
+			break;
+		}
+		if (mode === 0x4) {
+			let n = read(countBits(qr.version));
+			for (let i = 0; i < n; i++) {
+				bytes.push(read(8));
+			}
+		} else if (mode === 0x2) {
+			let n = read(qr.version < 10 ? 9 : qr.version < 27 ? 11 : 13);
+			for (; n >= 2; n -= 2) {
+				let v = read(11);
+				bytes.push(alnum.charCodeAt(Math.floor(v / 45)), alnum.charCodeAt(v % 45));
+			}
+			if (n === 1) {
+				bytes.push(alnum.charCodeAt(read(6)));
+			}
+		} else if (mode === 0x1) {
+			let n = read(qr.version < 10 ? 10 : qr.version < 27 ? 12 : 14);
+			for (; n >= 3; n -= 3) {
+				bytes.push(...new TextEncoder().encode(String(read(10)).padStart(3, "0")));
+			}
+			if (n > 0) {
+				bytes.push(...new TextEncoder().encode(String(read(n === 2 ? 7 : 4)).padStart(n, "0")));
+			}
+		} else if (mode === 0x7) {
+			read(8); // ECI designator.  UTF-8 is assumed.
+		} else {
+			throw invalid("unsupported QR mode " + mode + ".");
+		}
+	}
+	return new TextDecoder().decode(Uint8Array.from(bytes));
+}
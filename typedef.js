@@ -14,10 +14,15 @@ https://github.com/Cyphrme/Coze#readme
 `can` is defined as type string[] and not type object.  Some canon related
 functions may permit a canon as type object and those functions should
 explicitly document that usage. For those functions, if Can is object, only the
-first level keys should be used and nested keys should be ignored.  
+first level keys should be used and nested keys should be ignored.  The
+exception is Canonical (and CanonicalS/CanonicalHash), which also accepts a
+nested canon.  See Canonical.
 
 In Coze, the message is always a "pay".  "pay" is then hashed and the resulting
 digest is signed.  
+
+The Typescript declarations in `types/` must agree with these typedefs.  When
+changing a typedef or a function signature, update `types/coze.d.ts` as well.
 */
 
 
@@ -25,7 +30,7 @@ digest is signed.
 @typedef {string}     B64    b64ut (RFC 4648 base64 url truncated)
 @typedef {B64}        Dig    A digest encoded as b64ut.
 
-@typedef {string}     Alg    Algorithm in use, e.g. "ES256".
+@typedef {string}     Alg    Algorithm in use, e.g. "ES256".  One of Algs.
 @typedef {number}     Iat    "Issued at" Unix time, e.g. 1623132000.
 @typedef {Dig}        Tmb    Thumbprint, e.g. "cLj8vsYtMBwYkzoFVZHBZo6SNL8wSdCIjCKAwXNuhOk"
 @typedef {string}     Typ    Type,  e.g. "cyphr.me/msg"
@@ -35,6 +40,7 @@ digest is signed.
 @typedef {string}     Kid    Non-programmatic key identifier, e.g. "Zami's Majuscule Key."
 
 @typedef {string[]}   Can    Canon, e.g. ["alg","iat","msg","tmb","typ"].  
+@typedef {Array<string|Object<string,NestedCan>>|Object<string,any>} NestedCan  Nested canon, e.g. ["alg",{"img":["dig","id"]},"tmb"].  
 @typedef {Dig}        Cad    "Canonical digest" of `pay`, e.g. "Ie3xL77AsiCcb4r0pbnZJqMcfSBqg5Lk0npNJyJ9BC4"
 @typedef {Dig}        Czd    "Coze digest" of `coze`, e.g. "TnRe4DRuGJlw280u3pGhMDOIYM7ii7J8_PhNuSScsIU"
 
@@ -42,6 +48,7 @@ digest is signed.
 
 @typedef {Key}        SK     Private Coze key, an object containing private component `d`.
 @typedef {Key}        PK     Public Coze key, an object containing `x` and not containing `d`.
+@typedef {Key[]|Map<Tmb,Key>} Keyring  Set of Coze keys matched by tmb.  See LookupKey.
 
 @typedef {string}     Gen    Gen is the genus for an Alg (Level 1), e.g. "SHA2", "ECDSA".
 @typedef {string}     Fam    Fam is the family for an Alg (Level 2), e.g. "SHA", "EC".
@@ -61,6 +68,95 @@ digest is signed.
 @property {Cad}    cad
 @property {Sig}    [sig]
 @property {Czd}    [czd]
+@property {{tmb: B64, sig: Sig, czd: Czd}[]} [sigs]  Per signer czd for multi-signature cozies.
+*/
+
+
+/**
+SignOpts are the options for Sign, SignCozeRaw, and SignPay.
+
+- deterministic:  Use deterministic ECDSA (RFC 6979) instead of SubtleCrypto's
+                  randomized ECDSA.  Signing the same pay with the same key
+                  always results in the same sig (and czd).  Requires `d`.  The
+                  signature is computed in Javascript and is slower.  Ignored
+                  for Ed25519, which is always deterministic.
+- normalizeUnicode:  Normalize all string values (not keys) in pay to Unicode
+                  NFC before signing, so that visually identical text has the
+                  same cad.  pay is updated with the normalized values.  Off by
+                  default, as the Coze spec signs pay as given.
+- setStandard:    For Sign and SignCozeRaw, populate `alg` and `tmb` from the
+                  key and `iat` as the current time if not set in pay.  Set
+                  values are not overwritten and must match.  Standard fields are put
+                  first in pay in canonical order (alg, iat, tmb).
+- iat:            Use this iat instead of the current time.  Useful for
+                  reproducible tests.
+- hash:           Hashing algorithm the caller expects.  Must be the hash of
+                  the key's alg (See HashAlg), otherwise ERR_HASH_INVALID is
+                  thrown, e.g. SHA-3 is not valid for ES256.
+@typedef  {object}   SignOpts
+@property {boolean}  [deterministic]
+@property {boolean}  [normalizeUnicode]
+@property {boolean}  [setStandard]
+@property {Iat}      [iat]
+@property {Hsh}      [hash]
+*/
+
+
+/**
+VerifyOpts are the options for Verify.
+
+- canon:          Pay's fields must be exactly the fields in canon.  Order of
+                  canon is ignored.
+- canonContains:  Pay must contain all the fields in canonContains.  Extra
+                  fields are permitted.
+- acceptDER:      If sig is a DER encoded ECDSA signature, convert it to
+                  Coze's r || s before verifying.  Off by default.
+- normalizeUnicode:  Normalize string values in pay to Unicode NFC before
+                  verifying.  For cozies signed with normalizeUnicode whose pay
+                  may have been denormalized since.  Off by default.
+- maxAge:         pay.iat must be no older than maxAge seconds.
+- notBefore:      pay.iat must not be before this Unix time.
+- notAfter:       pay.iat must not be after this Unix time.
+- clockSkew:      Seconds of tolerance for the time options.  Default 60.
+- allowRevoked:   Verify with a revoked key instead of throwing
+                  ERR_KEY_REVOKED.  For forensic use.
+- hash:           Hashing algorithm the caller expects.  See SignOpts.
+- requireLowS:    Refuse high-S ECDSA signatures.  By default both low-S and
+                  high-S are accepted for interoperability.  Sign always
+                  produces low-S.
+@typedef  {object}   VerifyOpts
+@property {Can}      [canon]
+@property {Can}      [canonContains]
+@property {boolean}  [acceptDER]
+@property {boolean}  [normalizeUnicode]
+@property {number}   [maxAge]
+@property {Iat}      [notBefore]
+@property {Iat}      [notAfter]
+@property {number}   [clockSkew]
+@property {boolean}  [allowRevoked]
+@property {Hsh}      [hash]
+@property {boolean}  [requireLowS]
+*/
+
+/**
+RevokeOpts are the options for Revoke.  Fields other than `msg` and `typ` are
+also added to the revoke pay.  `alg`, `iat`, `tmb`, and `rvk` are set by
+Revoke and may not be given.
+
+- msg:   Human readable, non programmatic reason for revoking the key.
+- typ:   Revoke `typ`, e.g. "cyphr.me/key/revoke".
+@typedef  {object}   RevokeOpts
+@property {string}   [msg]
+@property {Typ}      [typ]
+*/
+
+/**
+CanonOpts are the options for CanonicalS, CanonicalHash, and CanonicalHash64.
+
+- normalizeUnicode:  Normalize all string values (not keys) to Unicode NFC
+                     before serialization.  Off by default.
+@typedef  {object}   CanonOpts
+@property {boolean}  [normalizeUnicode]
 */
 
 
@@ -68,18 +164,20 @@ digest is signed.
 Coze is a signed coze object.  See Go implementation docs (Cyphrme/Coze).
 
 - pay:   The `pay`.  See docs on Pay for more.
-- sig:   The B64 signature.
+- sig:   The B64 signature.  Absent for cozies not yet signed.
 - cad:   Canonical digest of `pay`.  E.g.  LSgWE4vEfyxJZUTFaRaB2JdEclORdZcm4UVH9D8vVto
 - can:   The canon of pay.    E.g.  ["alg", "iat", "msg", "tmb", "typ"]
 - czd:   "Coze digest" over `{"cad":...,"sig":...}`.
 - key:   Coze Key used to sign `coze`.
+- sigs:  Additional signatures over the same pay as `{tmb, sig}`.  See SignAdd.
 @typedef  {object}  Coze
 @property {Pay}     pay
-@property {Sig}     sig
+@property {Sig}     [sig]
 @property {Cad}     [cad]
 @property {Can}     [can]
 @property {Czd}     [czd]
 @property {Key}     [key]
+@property {{tmb: B64, sig: Sig}[]} [sigs]
 */
 
 
@@ -90,11 +188,15 @@ Pay contains the standard `Coze.Pay` fields.  See Go implementation docs (Cyphrm
 - iat:    Unix time of signing. E.g. 1623132000.
 - tmb:    Signing thumbprint    E.g. cLj8vsYtMBwYkzoFVZHBZo6SNL8wSdCIjCKAwXNuhOk
 - typ:    Type.                 E.g. "cyphr.me/msg/create".
+- rvk:    Unix time of key revocation, for revoke cozies.  E.g. 1623132000.
+
+All fields are optional and other fields are permitted.
 @typedef  {object} Pay
-@property {Alg}    alg
-@property {Iat}    iat
-@property {Tmb}    tmb
-@property {Typ}    typ
+@property {Alg}    [alg]
+@property {Iat}    [iat]
+@property {Tmb}    [tmb]
+@property {Typ}    [typ]
+@property {Iat}    [rvk]
 */
 
 
@@ -114,14 +216,18 @@ given `alg`. See Go implementation docs (Cyphrme/Coze).
 e.g. "bNstg4_H3m3SlROufwRSEgibLrBuRq9114OvdapcpVA"
 
 -x:   ECDSA public "x" component in b64ut. Required for ECDSA public Coze keys.
+For ECDSA, x is X || Y.  There is no separate "y".
 e.g. "2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"
+
+-rvk: Unix time of key revocation.  See Revoke.
 @typedef  {object} Key
 @property {Alg}    alg
-@property {Kid}    kid
-@property {Iat}    iat
-@property {B64}    tmb
+@property {Kid}    [kid]
+@property {Iat}    [iat]
+@property {Tmb}    [tmb]
 @property {B64}    [d]
 @property {B64}    [x]
+@property {Iat}    [rvk]
 */
 
 
@@ -140,6 +246,10 @@ for the hash alg "SHA-256" Curve's value is "" and XSize is 0.
 -DSize:    Size in bytes of `d`.              E.g. "32" for ES256
 -Curve:    Curve is the elliptic curve.       E.g. "P-256".
 -Use:      Algorithm use.                     E.g. "sig".
+-CurveOID: Dotted curve OID.                  E.g. "1.2.840.10045.3.1.7".
+-JOSEAlg:  JOSE "alg", or "" if none.         E.g. "ES256", "EdDSA".
+-JOSECrv:  JOSE "crv", or "" if none.         E.g. "P-256", "Ed25519".
+-COSEAlg:  COSE alg identifier, or 0 if none. E.g. -7 for "ES256".
 @typedef  {object}    Params
 @property {string}    Name
 @property {Gen}       Genus
@@ -155,5 +265,9 @@ for the hash alg "SHA-256" Curve's value is "" and XSize is 0.
 @property {Crv}       Curve
 @property {number}    SigSize
 @property {number}    SigSizeB64
+@property {string}    CurveOID
+@property {string}    JOSEAlg
+@property {string}    JOSECrv
+@property {number}    COSEAlg
 */
 
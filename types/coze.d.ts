@@ -0,0 +1,513 @@
+// Type definitions for Coze JS core (`join.js`, `coze.min.js`).
+//
+// These declarations are maintained by hand and must agree with the JSDoc in
+// the source, `typedef.js` in particular.  When changing a function signature
+// or typedef, update this file as well.  The browser (DOM) lib is required for
+// CryptoKey, Blob, ReadableStream, and AbortSignal.
+//
+// See `coze_all.d.ts` for Coze standard (`join_all.js`, `coze_all.min.js`).
+
+// Primitives
+
+/** b64ut (RFC 4648 base64 url truncated). */
+export type B64 = string;
+/** A digest encoded as b64ut. */
+export type Dig = B64;
+/** Signing algorithm. */
+export type SigAlg = "ES224" | "ES256" | "ES384" | "ES512" | "Ed25519" | "Ed25519ph" | "Ed448";
+/** Hashing algorithm that results in a digest, e.g. "SHA-256". */
+export type Hsh = "SHA-224" | "SHA-256" | "SHA-384" | "SHA-512" | "SHA3-224" | "SHA3-256" | "SHA3-384" | "SHA3-512" | "SHAKE128" | "SHAKE256";
+/** Algorithm in use, e.g. "ES256". */
+export type Alg = SigAlg | Hsh;
+/** Genus for an Alg (Level 1), e.g. "SHA2", "ECDSA". */
+export type Gen = "ECDSA" | "EdDSA" | "SHA2" | "SHA3";
+/** Family for an Alg (Level 2), e.g. "SHA", "EC". */
+export type Fam = "EC" | "SHA" | "RSA";
+/** (Elliptic) curve used for Alg, e.g. "P-256". */
+export type Crv = "P-224" | "P-256" | "P-384" | "P-521" | "Curve25519" | "Curve448";
+/** Use for Alg, e.g. "sig". */
+export type Use = "sig" | "enc" | "hsh";
+/** "Issued at" Unix time, e.g. 1623132000. */
+export type Iat = number;
+/** Thumbprint, e.g. "cLj8vsYtMBwYkzoFVZHBZo6SNL8wSdCIjCKAwXNuhOk". */
+export type Tmb = Dig;
+/** Type, e.g. "cyphr.me/msg". */
+export type Typ = string;
+/** A cryptographic signature. */
+export type Sig = B64;
+/** Non-programmatic key identifier, e.g. "Zami's Majuscule Key.". */
+export type Kid = string;
+/** Canon, e.g. ["alg","iat","msg","tmb","typ"]. */
+export type Can = string[];
+/** Nested canon, e.g. ["alg",{"img":["dig","id"]},"tmb"]. */
+export type NestedCan = Array<string | { [field: string]: NestedCan }> | { [field: string]: unknown };
+/** "Canonical digest" of `pay`. */
+export type Cad = Dig;
+/** "Coze digest" of `coze`. */
+export type Czd = Dig;
+/** A not-hashed, non-digest, "raw" message. */
+export type Msg = string;
+
+// Objects
+
+/**
+Key holds a cryptographic key, with the minimum required fields for the given
+`alg`.  ECDSA `x` is X || Y; Coze has no separate `y`.  Public keys have `x`
+and not `d`.
+*/
+export interface Key {
+	alg: Alg;
+	iat?: Iat;
+	kid?: Kid;
+	tmb?: Tmb;
+	d?: B64;
+	x?: B64;
+	rvk?: Iat;
+	[field: string]: unknown;
+}
+
+/** Private Coze key, containing `d`. */
+export type SK = Key & { d: B64 };
+/** Public Coze key, containing `x` and not `d`. */
+export type PK = Key & { x: B64; d?: undefined };
+/** Set of Coze keys matched by tmb.  See LookupKey. */
+export type Keyring = Key[] | Map<Tmb, Key>;
+
+/** Pay contains the standard `Coze.Pay` fields.  Other fields are permitted. */
+export interface Pay {
+	alg?: Alg;
+	iat?: Iat;
+	tmb?: Tmb;
+	typ?: Typ;
+	rvk?: Iat;
+	[field: string]: unknown;
+}
+
+/** MultiSig is a signature entry in a coze's `sigs` array.  See SignAdd. */
+export interface MultiSig {
+	tmb: Tmb;
+	sig: Sig;
+}
+
+/** Coze is a coze object.  `sig` is absent for cozies not yet signed. */
+export interface Coze {
+	pay: Pay;
+	sig?: Sig;
+	cad?: Cad;
+	can?: Can;
+	czd?: Czd;
+	key?: Key;
+	sigs?: MultiSig[];
+}
+
+/** Meta is the result of Meta. */
+export interface Meta {
+	alg: Alg;
+	iat?: Iat;
+	tmb?: Tmb;
+	typ?: Typ;
+	can: Can;
+	cad: Cad;
+	sig?: Sig;
+	czd?: Czd;
+	sigs?: Array<MultiSig & { czd: Czd }>;
+}
+
+/** Params holds all relevant values for an `alg`.  See Params. */
+export interface Params {
+	Name: Alg;
+	Genus: Gen;
+	Family: Fam;
+	Use: Use;
+	Hash: Hsh;
+	HashSize: number;
+	HashSizeB64: number;
+	XSize: number;
+	XSizeB64: number;
+	DSize: number;
+	DSizeB64: number;
+	Curve: Crv | "";
+	SigSize: number;
+	SigSizeB64: number;
+	CurveOID: string;
+	JOSEAlg: string;
+	JOSECrv: string;
+	COSEAlg: number;
+}
+
+// Options
+
+/** SignOpts are the options for Sign, SignCozeRaw, and SignPay. */
+export interface SignOpts {
+	deterministic?: boolean;
+	normalizeUnicode?: boolean;
+	setStandard?: boolean;
+	iat?: Iat;
+	hash?: Hsh;
+}
+
+/** VerifyOpts are the options for Verify. */
+export interface VerifyOpts {
+	canon?: Can;
+	canonContains?: Can;
+	acceptDER?: boolean;
+	normalizeUnicode?: boolean;
+	maxAge?: number;
+	notBefore?: Iat;
+	notAfter?: Iat;
+	clockSkew?: number;
+	allowRevoked?: boolean;
+	hash?: Hsh;
+	requireLowS?: boolean;
+}
+
+/** VerifyCheck is the result of a single VerifyMeta check. */
+export interface VerifyCheck {
+	name: "pay_parsed" | "key_found" | "meta" | "alg_matches" | "tmb_matches" | "not_revoked" | "hash" | "sig_b64ut" | "sig_size" | "signature" | "canon" | "iat_window";
+	status: "pass" | "fail" | "skip";
+	message?: string;
+}
+
+/** VerifyReport is the result of VerifyMeta. */
+export interface VerifyReport {
+	verified: boolean;
+	meta: Meta | null;
+	checks: VerifyCheck[];
+}
+
+/** RevokeOpts are the options for Revoke.  Other fields are added to pay. */
+export interface RevokeOpts {
+	msg?: string;
+	typ?: Typ;
+	[field: string]: unknown;
+}
+
+/** CanonOpts are the options for CanonicalS, CanonicalHash, and CanonicalHash64. */
+export interface CanonOpts {
+	normalizeUnicode?: boolean;
+}
+
+/** HashStreamOpts are the options for HashStream. */
+export interface HashStreamOpts {
+	chunkSize?: number;
+	onProgress?: (bytes: number) => void;
+	signal?: AbortSignal;
+}
+
+/** DigestPayFieldOpts are the options for DigestPayField. */
+export interface DigestPayFieldOpts {
+	sizeField?: string;
+	nameField?: string;
+}
+
+/** FilesOpts are the options for DigestFiles, DigestPayFile, and MatchPayFile. */
+export interface FilesOpts {
+	chunkSize?: number;
+	onProgress?: (bytes: number, total: number) => void;
+	signal?: AbortSignal;
+}
+
+/** FileDig is the digest of a file as set in `pay.file` by DigestPayFile. */
+export interface FileDig {
+	name: string;
+	size: number;
+	dig: Dig;
+}
+
+/** FileMatch is the result of MatchPayFile for a single file. */
+export interface FileMatch extends FileDig {
+	match: boolean;
+}
+
+/** PasswordParams are the parameters used by NewKeyFromPassword. */
+export interface PasswordParams {
+	alg: SigAlg;
+	kdf: "PBKDF2";
+	hash: "SHA-512";
+	iterations: number;
+	salt: B64;
+}
+
+/** KeyCheck is the result of a single Diagnose check. */
+export interface KeyCheck {
+	name: "alg_known" | "b64ut" | "x_length" | "y_length" | "d_length" | "tmb_matches" | "d_derives_x" | "sign_verify_roundtrip";
+	status: "pass" | "fail" | "skip";
+	message?: string;
+}
+
+/** PEMOpts are the options for CozeKeyToPEM. */
+export interface PEMOpts {
+	private?: boolean;
+	sec1?: boolean;
+}
+
+// canon.js
+
+export declare function Canon(obj: object): Can;
+export declare function Canonical(object: object, can?: NestedCan): Promise<{ [field: string]: unknown }>;
+export declare function CanonicalS(obj: object, can?: NestedCan, opts?: CanonOpts): Promise<string>;
+export declare function CanonicalHash(input: object | Uint8Array | ArrayBuffer, hash: Hsh, can?: NestedCan, opts?: CanonOpts): Promise<ArrayBuffer>;
+export declare function CanonicalHash64(input: object | Uint8Array | ArrayBuffer, hash: Hsh, can?: NestedCan, opts?: CanonOpts): Promise<Dig>;
+export declare function NormalizeUnicode<T>(value: T): T;
+
+// alg.js
+
+export declare const Algs: {
+	readonly UnknownAlg: "UnknownAlg";
+	readonly ES224: "ES224";
+	readonly ES256: "ES256";
+	readonly ES384: "ES384";
+	readonly ES512: "ES512";
+	readonly Ed25519: "Ed25519";
+	readonly Ed25519ph: "Ed25519ph";
+	readonly Ed448: "Ed448";
+	readonly SHA224: "SHA-224";
+	readonly SHA256: "SHA-256";
+	readonly SHA384: "SHA-384";
+	readonly SHA512: "SHA-512";
+	readonly SHA3224: "SHA3-224";
+	readonly SHA3256: "SHA3-256";
+	readonly SHA3384: "SHA3-384";
+	readonly SHA3512: "SHA3-512";
+	readonly SHAKE128: "SHAKE128";
+	readonly SHAKE256: "SHAKE256";
+};
+export declare const FamAlgs: {
+	readonly EC: "EC";
+	readonly SHA: "SHA";
+	readonly RSA: "RSA";
+};
+export declare const GenAlgs: {
+	readonly ECDSA: "ECDSA";
+	readonly EdDSA: "EdDSA";
+	readonly SHA2: "SHA2";
+	readonly SHA3: "SHA3";
+};
+export declare const Curves: {
+	readonly P224: "P-224";
+	readonly P256: "P-256";
+	readonly P384: "P-384";
+	readonly P521: "P-521";
+	readonly Curve25519: "Curve25519";
+	readonly Curve448: "Curve448";
+};
+export declare const Uses: {
+	readonly Sig: "sig";
+	readonly Enc: "enc";
+	readonly Hsh: "hsh";
+};
+
+export declare function Params(alg: Alg): Params;
+export declare function Curve(alg: Alg): Crv | "";
+export declare function Family(alg: Alg): Fam;
+export declare function Genus(alg: Alg): Gen;
+export declare function HashAlg(alg: Alg): Hsh;
+export declare function HashSize(alg: Alg): number;
+export declare function SigSize(alg: Alg): number;
+export declare function XSize(alg: Alg): number;
+export declare function DSize(alg: Alg): number;
+export declare function Use(alg: Alg): Use;
+export declare function CurveOrder(alg: SigAlg): bigint;
+export declare function CurveHalfOrder(alg: SigAlg): bigint;
+export declare function CurveOID(alg: Alg): string;
+export declare function JOSEAlg(alg: Alg): string;
+export declare function JOSECrv(alg: Alg): string;
+export declare function COSEAlg(alg: Alg): number;
+export declare function AlgFromJOSE(name: string, crv?: string): Alg;
+export declare function AlgFromCOSE(id: number): Alg;
+
+// coze.js
+
+export declare const PayCanon: Can;
+
+export declare function Sign(coze: Coze | string, cozeKey: Key | string, canon?: Can, opts?: SignOpts): Promise<Coze>;
+export declare function SignPay(pay: Pay | string, cozeKey: Key, opts?: SignOpts): Promise<Sig>;
+export declare function SignCozeRaw(coze: Coze | string, cozeKey: Key | string, canon?: Can, opts?: SignOpts): Promise<Coze>;
+export declare function SignCryptoKey(pay: Pay | string, cryptoKey: CryptoKey | ECDSAKey, cozeKey: Key, canon?: Can): Promise<Coze>;
+export declare function Verify(coze: Coze | string, cozeKey?: Key | Keyring | string, opts?: VerifyOpts): Promise<boolean>;
+export declare function Verify(pay: Pay | string, cozeKey: Key | Keyring | string | undefined, sig: Sig, opts?: VerifyOpts): Promise<boolean>;
+export declare function VerifyMeta(coze: Coze | string, cozeKey?: Key | Keyring | string, opts?: VerifyOpts): Promise<VerifyReport>;
+export declare function VerifyPay(pay: Pay | string, cozeKey: Key, sig: Sig): Promise<boolean>;
+export declare function SignDig(alg: SigAlg, cozeKey: Key, dig: Dig | Uint8Array): Promise<Sig>;
+export declare function VerifyDig(alg: SigAlg, cozeKey: Key, dig: Dig | Uint8Array, sig: Sig): Promise<boolean>;
+export declare function Meta(coze: Coze | string, key?: Alg | Key): Promise<Meta>;
+export declare function ScrubCoze(coze: Coze | string): Coze;
+export declare function Attach(pay: Pay | string, sig: Sig): Coze;
+export declare function Detach(coze: Coze | string): { payCompact: string; sig: Sig };
+export declare function Equal(cozeA: Coze | string, cozeB: Coze | string): Promise<boolean>;
+export declare function EqualStrict(cozeA: Coze | string, cozeB: Coze | string): Promise<boolean>;
+
+export declare function ParseStrict<T = unknown>(json: string): T;
+export declare function CheckDuplicates(json: string): void;
+
+export declare const InputTypes: {
+	readonly Coze: "coze";
+	readonly Key: "key";
+	readonly Pay: "pay";
+	readonly Array: "array";
+	readonly Unknown: "unknown";
+};
+/** InputType is one of InputTypes.  See Detect. */
+export type InputType = typeof InputTypes[keyof typeof InputTypes];
+export declare function Detect(input: unknown): InputType;
+
+// conversion.js
+
+export declare function SToArrayBuffer(string: string): Promise<ArrayBuffer>;
+export declare function B64uToArrayBuffer(string: B64, field?: string): ArrayBuffer;
+export declare function B64ToUint8Array(string: B64, field?: string): Uint8Array;
+export declare function ArrayBufferTo64ut(buffer: ArrayBuffer | Uint8Array): B64;
+export declare function B64Lenient(string: string): B64;
+export declare function HexToUint8Array(hex: string): Uint8Array;
+export declare function Uint8ArrayToHex(bytes: Uint8Array | ArrayBuffer): string;
+export declare function HexToB64ut(hex: string): B64;
+export declare function B64utToHex(b64: B64): string;
+
+export declare function isEmpty(thing: unknown): boolean;
+
+// key.js
+
+export declare const TmbCanon: Can;
+
+export declare function NewKey(alg?: SigAlg): Promise<Key>;
+export declare function NewKeyFromSeed(alg: SigAlg, seed: Uint8Array): Promise<Key>;
+export declare function NewKeyFromPassword(alg: SigAlg, password: string, salt: Uint8Array | string, opts?: { iterations?: number }): Promise<{ key: Key; params: PasswordParams }>;
+export declare function Correct(ck: Key): Promise<boolean>;
+export declare function Diagnose(ck: Key): Promise<{ ok: boolean; checks: KeyCheck[] }>;
+export declare function Valid(privateCozeKey: Key): Promise<boolean>;
+export declare function Thumbprint(cozeKey: Key): Promise<Tmb>;
+export declare function ThumbprintMatch(cozeKey: Key): Promise<Tmb>;
+export declare function PublicKey(cozeKey: Key): Key;
+export declare function IsPrivate(cozeKey: Key): boolean;
+export declare function AssertPublic(cozeKey: Key): void;
+export declare function Revoke(cozeKey: Key, opts?: RevokeOpts | string): Promise<Coze>;
+export declare function IsRevoked(cozeKey: Key | Pay): boolean;
+export declare function VerifyRevoke(coze: Coze, cozeKey: Key): Promise<boolean>;
+export declare function LookupKey(keyring: Key | Keyring, tmb: Tmb): Promise<Key>;
+
+// cryptokey.js
+
+export declare const CryptoKey: {
+	New(alg?: SigAlg): Promise<CryptoKeyPair | { privateKey: ECDSAKey; publicKey: ECDSAKey }>;
+	FromCozeKey(cozeKey: Key, onlyPublic?: boolean): Promise<CryptoKey | ECDSAKey>;
+	ToPublic(cryptoKey: JsonWebKey): Promise<void>;
+	ToCozeKey(cryptoKey: CryptoKey | ECDSAKey): Promise<Key>;
+	SignBuffer(cryptoKey: CryptoKey | ECDSAKey, arrayBuffer: ArrayBuffer): Promise<ArrayBuffer>;
+	SignBufferB64(cryptoKey: CryptoKey | ECDSAKey, arrayBuffer: ArrayBuffer): Promise<B64>;
+	SignString(cryptoKey: CryptoKey | ECDSAKey, utf8: string): Promise<B64>;
+	VerifyArrayBuffer(alg: SigAlg, cryptoKey: CryptoKey | ECDSAKey, msg: ArrayBuffer, sig: ArrayBuffer): Promise<boolean>;
+	VerifyMsg(alg: SigAlg, cryptoKey: CryptoKey | ECDSAKey, msg: Msg, sig: Sig): Promise<boolean>;
+	GetSignHashAlgoFromCryptoKey(cryptoKey: CryptoKey | ECDSAKey): Promise<Hsh>;
+	algFromCryptoKey(cryptoKey: CryptoKey | ECDSAKey): Promise<SigAlg>;
+	algFromCrv(crv: Crv | "Ed25519"): Promise<SigAlg>;
+};
+
+export declare function ClearKeyCache(): void;
+export declare function CozeKeyToJWK(cozeKey: Key): JsonWebKey;
+export declare function JWKToCozeKey(jwk: JsonWebKey): Promise<Key>;
+export declare function SigToLowS(alg: SigAlg, sig: Sig): Promise<Sig>;
+export declare function IsSigLowS(alg: SigAlg, sig: Sig): Promise<boolean>;
+
+// ecdsa.js
+
+/**
+ECDSAKey is a Javascript ECDSA key, used in place of a SubtleCrypto CryptoKey
+for curves SubtleCrypto does not implement (ES224).
+*/
+export interface ECDSAKey {
+	type: "public" | "private";
+	extractable: boolean;
+	algorithm: { name: "ECDSA"; namedCurve: Crv };
+	usages: Array<"sign" | "verify">;
+	ecdsa: {
+		alg: SigAlg;
+		point: { x: bigint; y: bigint };
+		d?: bigint;
+	};
+}
+
+export declare const ECDSA: {
+	New(alg: SigAlg): Promise<{ privateKey: ECDSAKey; publicKey: ECDSAKey }>;
+	FromCozeKey(cozeKey: Key, onlyPublic?: boolean): Promise<ECDSAKey>;
+	IsKey(key: unknown): key is ECDSAKey;
+	ToCozeKey(key: ECDSAKey): Key;
+	PublicFromD(alg: SigAlg, d: bigint): Promise<B64>;
+	KeyFromSeed(alg: SigAlg, seed: Uint8Array): Promise<Key>;
+	SignBuffer(key: ECDSAKey, buffer: ArrayBuffer, deterministic?: boolean): Promise<ArrayBuffer>;
+	SignDigest(key: ECDSAKey, digest: Uint8Array, deterministic?: boolean): Promise<ArrayBuffer>;
+	VerifyBuffer(key: ECDSAKey, buffer: ArrayBuffer, sig: ArrayBuffer): Promise<boolean>;
+	VerifyDigest(key: ECDSAKey, digest: Uint8Array, sig: ArrayBuffer): Promise<boolean>;
+};
+
+// hash.js
+
+export declare function Digest(hsh: Hsh, buffer: ArrayBuffer | Uint8Array): Promise<ArrayBuffer>;
+export declare function HMAC(hsh: Hsh, key: Uint8Array, data: Uint8Array): Promise<Uint8Array>;
+export declare function Hash(alg: Alg, input: string | Uint8Array | ArrayBuffer | Blob): Promise<B64>;
+export declare function HashStream(alg: Alg, input: Blob | ReadableStream<Uint8Array>, opts?: HashStreamOpts): Promise<B64>;
+export declare function DigestFiles(files: Blob | Blob[] | FileList, alg: Alg, opts?: FilesOpts): Promise<FileDig[]>;
+export declare function DigestPayFile(pay: Pay, files: Blob | Blob[] | FileList, alg: Alg, opts?: FilesOpts): Promise<Pay>;
+export declare function MatchPayFile(pay: Pay, files: Blob | Blob[] | FileList, alg: Alg, opts?: FilesOpts): Promise<{ match: boolean; files: FileMatch[] }>;
+export declare function DigestPayField(pay: Pay, fieldName: string, file: string | Uint8Array | ArrayBuffer | Blob, alg: Alg, opts?: DigestPayFieldOpts): Promise<Pay>;
+
+// der.js
+
+export declare function PEMToCozeKey(pem: string): Promise<Key>;
+export declare function CozeKeyToPEM(cozeKey: Key, opts?: PEMOpts): string;
+export declare function SigToDER(sig: Sig, alg: SigAlg): Uint8Array;
+export declare function DERToSig(der: Uint8Array | ArrayBuffer | B64, alg: SigAlg): Sig;
+export declare function IsDERSig(sig: Uint8Array, alg: SigAlg): boolean;
+
+// error.js
+
+export declare const ErrCodes: {
+	readonly AlgUnsupported: "ERR_ALG_UNSUPPORTED";
+	readonly AlgMismatch: "ERR_ALG_MISMATCH";
+	readonly TmbMismatch: "ERR_TMB_MISMATCH";
+	readonly KeyInvalid: "ERR_KEY_INVALID";
+	readonly KeyRevoked: "ERR_KEY_REVOKED";
+	readonly KeyMismatch: "ERR_KEY_MISMATCH";
+	readonly KeyNotFound: "ERR_KEY_NOT_FOUND";
+	readonly SigInvalid: "ERR_SIG_INVALID";
+	readonly CanonInvalid: "ERR_CANON_INVALID";
+	readonly CanonMissing: "ERR_CANON_MISSING";
+	readonly CanonExtra: "ERR_CANON_EXTRA";
+	readonly PayMissing: "ERR_PAY_MISSING";
+	readonly PrvMismatch: "ERR_PRV_MISMATCH";
+	readonly ThresholdInvalid: "ERR_THRESHOLD_INVALID";
+	readonly IatInvalid: "ERR_IAT_INVALID";
+	readonly Expired: "ERR_EXPIRED";
+	readonly NotYetValid: "ERR_NOT_YET_VALID";
+	readonly DigSize: "ERR_DIG_SIZE";
+	readonly HashInvalid: "ERR_HASH_INVALID";
+	readonly DuplicateField: "ERR_DUPLICATE_FIELD";
+	readonly JSONInvalid: "ERR_JSON_INVALID";
+	readonly FieldReserved: "ERR_FIELD_RESERVED";
+	readonly B64Invalid: "ERR_B64_INVALID";
+	readonly HexInvalid: "ERR_HEX_INVALID";
+	readonly QRCapacity: "ERR_QR_CAPACITY";
+	readonly QRInvalid: "ERR_QR_INVALID";
+	readonly KeystoreUnavailable: "ERR_KEYSTORE_UNAVAILABLE";
+	readonly BrowserRequired: "ERR_BROWSER_REQUIRED";
+};
+/** ErrCode is one of ErrCodes. */
+export type ErrCode = typeof ErrCodes[keyof typeof ErrCodes];
+
+/** CozeError is the base class of Coze errors.  Context, like `field`, is set on the error. */
+export declare class CozeError extends Error {
+	constructor(message: string, code: string, context?: { [field: string]: unknown });
+	/** One of ErrCodes, or a code of Coze standard, e.g. "ERR_KEYSTORE_UNAVAILABLE". */
+	code: ErrCode | string;
+	field?: string;
+	alg?: string;
+	tmb?: Tmb;
+	position?: number;
+	[field: string]: unknown;
+}
+export declare class CozeKeyError extends CozeError {}
+export declare class CozeVerifyError extends CozeError {}
+export declare class CozeCanonError extends CozeError {}
+export declare class CozeAlgError extends CozeError {}
+export declare class B64Error extends CozeError {
+	constructor(message: string, field?: string);
+}
@@ -0,0 +1,138 @@
+// Type definitions for Coze JS standard (`join_all.js`, `coze_all.min.js`).
+// Coze standard is Coze core plus the functions below.  See `coze.d.ts`.
+
+import { Alg, Coze, CozeError, Czd, Iat, Key, Keyring, Meta, MultiSig, NestedCan, Pay, SigAlg, SignOpts, Tmb } from "./coze";
+
+export * from "./coze";
+
+// standard/coze_array.js
+
+/** EncapsulatedCoze is a coze under the field `coze`. */
+export interface EncapsulatedCoze {
+	coze: Coze;
+}
+
+/** SignArrayOpts are the options for SignCozeArray. */
+export interface SignArrayOpts extends SignOpts {
+	canon?: NestedCan;
+}
+
+/** SignedCoze is the signing result for a single pay in an array.  coze is null on error. */
+export interface SignedCoze {
+	coze: Coze | null;
+	error: Error | null;
+}
+
+/** VerifiedCoze is the verification result for a single coze in an array. */
+export interface VerifiedCoze {
+	czd: Czd;
+	tmb: Tmb;
+	verified: boolean;
+	error: Error | null;
+}
+
+/** MetaResult is the Meta of a single coze in an array.  meta is null on error. */
+export interface MetaResult {
+	meta: Meta | null;
+	error: Error | null;
+}
+
+/** MetaSummary is the aggregate of MetaArray results. */
+export interface MetaSummary {
+	count: number;
+	failed: number;
+	tmb: { [tmb: string]: number };
+	typ: { [typ: string]: number };
+	iatMin: Iat | null;
+	iatMax: Iat | null;
+}
+
+export declare function SignCozeArray(pays: Array<Pay | string>, cozeKey: Key, opts?: SignArrayOpts): Promise<SignedCoze[]>;
+export declare function VerifyCozeArray(coze: Array<Coze | EncapsulatedCoze | string>, cozeKey?: Key | Keyring): Promise<VerifiedCoze[]>;
+export declare function VerifyCozeArray(coze: Coze | string, cozeKey?: Key | Keyring): Promise<boolean>;
+export declare function MetaArray(cozies: Array<Coze | EncapsulatedCoze | string>, key?: Alg | Key): Promise<{ results: MetaResult[]; summary: MetaSummary }>;
+
+// standard/coze_chain.js
+
+/** ChainGenesis is the `prv` of the first coze of a chain. */
+export declare const ChainGenesis: "";
+
+/** ChainOpts are the options for VerifyChain. */
+export interface ChainOpts {
+	prv?: Czd;
+}
+
+/** ChainResult is the result of VerifyChain.  index is -1 if not broken. */
+export interface ChainResult {
+	verified: boolean;
+	czds: Czd[];
+	index: number;
+	reason: string;
+	error: Error | null;
+}
+
+export declare function SignChained(pay: Pay, cozeKey: Key, previousCoze?: Coze | null, opts?: SignOpts): Promise<Coze>;
+export declare function VerifyChain(cozies: Array<Coze | EncapsulatedCoze | string>, cozeKey: Key | Keyring, opts?: ChainOpts): Promise<ChainResult>;
+
+// standard/coze_multi.js
+
+/** MultiOpts are the options for VerifyMulti. */
+export interface MultiOpts {
+	threshold?: number;
+}
+
+/** MultiResult is the result of VerifyMulti. */
+export interface MultiResult {
+	verified: boolean;
+	valid: Tmb[];
+	invalid: Tmb[];
+	unknown: Tmb[];
+}
+
+export declare function SignAdd(coze: Coze, cozeKey: Key): Promise<Coze & { sigs: MultiSig[] }>;
+export declare function VerifyMulti(coze: Coze, keys: Key[], opts?: MultiOpts): Promise<MultiResult>;
+
+// standard/download.js
+
+export declare function DownloadJSON(obj: unknown, filename: string): void;
+
+// standard/keystore.js
+
+/** StoredKey is a key stored in the keystore. */
+export interface StoredKey {
+	name: string;
+	cozeKey: Key;
+	cryptoKeyPair?: CryptoKeyPair;
+}
+
+/** StoredKeyInfo is the public information of a stored key. */
+export interface StoredKeyInfo {
+	name: string;
+	alg: SigAlg;
+	tmb: Tmb;
+	cryptoKey: boolean;
+}
+
+export declare function StoreKey(name: string, key: Key | CryptoKeyPair): Promise<StoredKey>;
+export declare function LoadKey(name: string): Promise<StoredKey | null>;
+export declare function ListKeys(): Promise<StoredKeyInfo[]>;
+export declare function DeleteKey(name: string): Promise<void>;
+
+/** KeystoreUnavailableError is thrown when IndexedDB is not available. */
+export declare class KeystoreUnavailableError extends CozeError {
+	constructor(message: string);
+}
+
+// standard/qr.js
+
+/** QROpts are the options for CozeToQR and QRMatrix. */
+export interface QROpts {
+	errorCorrection?: "L" | "M" | "Q" | "H";
+	scale?: number;
+	margin?: number;
+}
+
+export declare function CozeToQR(cozeOrKey: Coze | Key | string, target: HTMLCanvasElement | SVGSVGElement, opts?: QROpts): string;
+export declare function QRToCoze(source: HTMLImageElement | HTMLVideoElement | HTMLCanvasElement | ImageBitmap | ImageData): Promise<Coze | Key>;
+export declare function QRMatrix(text: string, opts?: QROpts): boolean[][];
+export declare function QRText(cozeOrKey: Coze | Key | string): string;
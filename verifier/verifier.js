@@ -1,11 +1,52 @@
 "use strict";
 
-import * as Coze from './coze.min.js';
+import * as Coze from './coze_all.min.js';
 var InputMsg;
 var InputKey;
 var OutMsg;
 var AlgSelect;
 var RvkMsg;
+var KeyReport;
+var VerifyReport;
+
+// Shown key.  `d` is redacted unless ShowPrivate is checked.
+var KeyOut;
+var ShowPrivate;
+var ShownKey = null;
+var ShownWarning = "";
+var RememberKey;
+
+// ReportIcons are the icons of check statuses in reports.
+const ReportIcons = {
+	pass: "✅",
+	fail: "❌",
+	skip: "⏭️"
+};
+
+// Keystore name for the remembered key.
+const RememberedKeyName = "verifier";
+
+// File drop.  DropAbort is the AbortController of the hashing in progress.
+var DropProgress;
+var DropCancelBtn;
+var DropMsg;
+var DropAbort = null;
+
+// QR.  ScanStream is the camera stream while scanning.
+var QRCanvas;
+var QRMsg;
+var ScanVideo;
+var ScanBtn;
+var ScanStream = null;
+
+// Generate Key.  GenKeySaved is whether the private generated key has been
+// downloaded since it was generated or changed.
+var GenAlgSelect;
+var GenTmb;
+var GenPublicKey;
+var GenOut;
+var GenCozeKey = null;
+var GenKeySaved = true;
 
 // Metas
 var MetaAlg;
@@ -15,8 +56,10 @@ var MetaIats;
 var MetaTyp;
 var MetaCan;
 var MetaCad;
+var MetaCadHex;
 var MetaSig;
 var MetaCzd;
+var MetaCzdHex;
 
 // DOM load
 document.addEventListener('DOMContentLoaded', () => {
@@ -31,6 +74,12 @@ document.addEventListener('DOMContentLoaded', () => {
 	OutMsg = document.getElementById('OutMsg');
 	AlgSelect = document.getElementById('AlgSelect');
 	RvkMsg = document.getElementById('RvkMsg');
+	KeyReport = document.getElementById('KeyReport');
+	VerifyReport = document.getElementById('VerifyReport');
+	KeyOut = document.getElementById('KeyOut');
+	ShowPrivate = document.getElementById('ShowPrivate');
+	ShowPrivate.addEventListener('change', () => ShowKey(ShownKey, ShownWarning));
+	RememberKey = document.getElementById('RememberKey');
 
 	// Meta
 	MetaAlg = document.querySelector("#MetaAlg");
@@ -40,8 +89,10 @@ document.addEventListener('DOMContentLoaded', () => {
 	MetaTyp = document.querySelector("#MetaTyp");
 	MetaCan = document.querySelector("#MetaCan");
 	MetaCad = document.querySelector("#MetaCad");
+	MetaCadHex = document.querySelector("#MetaCadHex");
 	MetaSig = document.querySelector("#MetaSig");
 	MetaCzd = document.querySelector("#MetaCzd");
+	MetaCzdHex = document.querySelector("#MetaCzdHex");
 
 	// Set event listeners for buttons.
 	document.getElementById('VerifyBtn').addEventListener('click', Verify);
@@ -49,8 +100,99 @@ document.addEventListener('DOMContentLoaded', () => {
 	document.getElementById('GenRandKeyBtn').addEventListener('click', GenKey);
 	document.getElementById('ClearBtn').addEventListener('click', ClearAll);
 	document.getElementById('CopyBtn').addEventListener('click', Copy);
+	RememberKey.addEventListener('change', Remember);
+	InputKey.addEventListener('change', Remember);
+
+	// File drop
+	DropProgress = document.getElementById('DropProgress');
+	DropCancelBtn = document.getElementById('DropCancelBtn');
+	DropMsg = document.getElementById('DropMsg');
+	let dropZone = document.getElementById('DropZone');
+	dropZone.addEventListener('dragover', (e) => {
+		e.preventDefault();
+		dropZone.classList.add('dragover');
+	});
+	dropZone.addEventListener('dragleave', () => dropZone.classList.remove('dragover'));
+	dropZone.addEventListener('drop', (e) => {
+		e.preventDefault();
+		dropZone.classList.remove('dragover');
+		DigestDropped(e.dataTransfer.files);
+	});
+	DropCancelBtn.addEventListener('click', () => {
+		if (DropAbort !== null) {
+			DropAbort.abort();
+		}
+	});
+
+	// QR
+	QRCanvas = document.getElementById('QRCanvas');
+	QRMsg = document.getElementById('QRMsg');
+	ScanVideo = document.getElementById('ScanVideo');
+	ScanBtn = document.getElementById('ScanBtn');
+	ScanBtn.addEventListener('click', ToggleScan);
+	document.getElementById('QRFile').addEventListener('change', async (e) => {
+		if (e.target.files.length > 0) {
+			await ScanQR(await createImageBitmap(e.target.files[0]));
+			e.target.value = "";
+		}
+	});
+
+	// Generate Key
+	GenAlgSelect = document.getElementById('GenAlgSelect');
+	GenTmb = document.getElementById('GenTmb');
+	GenPublicKey = document.getElementById('GenPublicKey');
+	GenOut = document.getElementById('GenOut');
+	document.getElementById('GenKeyBtn').addEventListener('click', GenerateKey);
+	document.getElementById('DownloadKeyBtn').addEventListener('click', () => DownloadGenKey(false));
+	document.getElementById('DownloadPublicKeyBtn').addEventListener('click', () => DownloadGenKey(true));
+	document.getElementById('SignExampleBtn').addEventListener('click', SignExample);
+	document.getElementById('RevokeKeyBtn').addEventListener('click', RevokeGenKey);
+	window.addEventListener('beforeunload', (e) => {
+		if (!GenKeySaved) {
+			e.preventDefault();
+			e.returnValue = ""; // Required by some browsers.
+		}
+	});
+
+	LoadRemembered();
 });
 
+// LoadRemembered loads the remembered key, if any, into InputKey.
+async function LoadRemembered() {
+	try {
+		let sk = await Coze.LoadKey(RememberedKeyName);
+		if (sk !== null) {
+			InputKey.value = JSON.stringify(sk.cozeKey, null, " ");
+			RememberKey.checked = true;
+		}
+	} catch (e) {
+		if (e instanceof Coze.KeystoreUnavailableError) {
+			RememberKey.disabled = true;
+			RememberKey.parentElement.title = e.message;
+			return;
+		}
+		OutMsg.innerText = "❌ Error loading remembered key - " + e;
+	}
+}
+
+// Remember stores the key in InputKey if "Remember this key" is checked, and
+// deletes the remembered key otherwise.
+async function Remember() {
+	try {
+		if (!RememberKey.checked) {
+			await Coze.DeleteKey(RememberedKeyName);
+			return;
+		}
+		await Coze.StoreKey(RememberedKeyName, Coze.ParseStrict(InputKey.value));
+	} catch (e) {
+		if (e instanceof Coze.KeystoreUnavailableError) {
+			RememberKey.checked = false;
+			RememberKey.disabled = true;
+		}
+		OutMsg.innerText = "❌ Error remembering key - " + e;
+	}
+}
+
 function Copy(){
     // Select the text.
     var selection = window.getSelection();
@@ -65,31 +207,73 @@ function Copy(){
 
 
 
+// Verify acts on the type of the input message (See Coze.Detect): cozies are
+// verified, keys are checked, pays are shown with their cad and can, and arrays
+// are batch verified.
 async function Verify() {
 	Reset();
 	console.log(InputMsg.value, InputKey.value);
 
 	try {
-		var coze = JSON.parse(InputMsg.value);
+		var type = Coze.Detect(InputMsg.value);
+		var coze = Coze.ParseStrict(InputMsg.value);
 	} catch (e) {
-		OutMsg.innerText = "❌ Error parsing coze - " + e;
+		OutMsg.innerText = "❌ Error parsing message - " + e.message;
 		return;
 	}
 
+	switch (type) {
+		case Coze.InputTypes.Key:
+			return CheckKey(coze);
+		case Coze.InputTypes.Pay:
+			return ShowPay(coze);
+		case Coze.InputTypes.Array:
+			return VerifyArray(coze);
+		case Coze.InputTypes.Unknown:
+			OutMsg.innerText = "❌ Message is not a coze, pay, key, or array of cozies.";
+			return;
+	}
+	if (coze.pay === undefined) {
+		coze = coze.coze; // Encapsulated coze.
+	}
+
 	try {
-		var key = JSON.parse(InputKey.value);
-		var verified = await Coze.Verify(coze, key);
+		// Without a given key, the key embedded in the coze, if any, is used.
+		var key = coze.key;
+		var source = "embedded key";
+		if (InputKey.value.trim() !== "") {
+			key = Coze.ParseStrict(InputKey.value);
+			source = Coze.isEmpty(coze.key) ? "given key" : "given key, matches embedded key";
+		}
+		// Revoked keys are verified and shown as revoked below.
+		var verified = await Coze.Verify(coze, key, {
+			allowRevoked: true
+		});
 
 		if (Coze.IsRevoked(key)) {
 			RvkMsg.innerText = "⚠️ Key is revoked since " + new Date(key.rvk * 1000).toLocaleString()
 		}
 
+		if (Coze.IsPrivate(coze.key)) {
+			ShowKey(coze.key, "⚠️ The embedded key is a private key.  Remove it, e.g. with ScrubCoze, before sharing this coze.");
+		}
 		if (verified) {
-			OutMsg.innerText = "✅ Verified";
+			OutMsg.innerText = "✅ Verified (" + source + ")";
 			Meta(coze, key);
+			ShowQR(coze);
+			return;
+		}
+	} catch (e) {
+		if (e.code === Coze.ErrCodes.TmbMismatch || e.code === Coze.ErrCodes.AlgMismatch) {
+			OutMsg.innerText = "❌ " + e.message;
+		}
+		if (e.code === Coze.ErrCodes.DuplicateField) {
+			OutMsg.innerText = "❌ Error parsing key - " + e;
 			return;
 		}
-	} catch (e) {}
+	}
+	await ShowVerifyReport(coze, key);
+	await ShowKeyReport(key);
 	// Still show meta on Coze even if key is bad or signature failed.  Generate
 	// key with alg from select for contextual cozies (such as the empty coze).  
 	let AlgFromSelectKey = {
@@ -98,12 +282,198 @@ async function Verify() {
 	Meta(coze, AlgFromSelectKey);
 }
 
+// ShowQR shows a QR code of a verified coze.  An embedded private key is
+// never encoded.
+function ShowQR(coze) {
+	try {
+		Coze.CozeToQR(Coze.ScrubCoze(coze), QRCanvas);
+		QRCanvas.hidden = false;
+	} catch (e) {
+		QRMsg.textContent = "No QR code - " + e.message;
+	}
+}
+
+// ToggleScan starts or stops scanning QR codes with the camera.  Frames are
+// decoded until a QR code of JSON is found, which is then verified.
+async function ToggleScan() {
+	if (ScanStream !== null) {
+		StopScan();
+		return;
+	}
+	try {
+		ScanStream = await navigator.mediaDevices.getUserMedia({
+			video: {
+				facingMode: "environment"
+			}
+		});
+	} catch (e) {
+		QRMsg.textContent = "❌ Camera unavailable - " + e.message;
+		return;
+	}
+	if (typeof BarcodeDetector === "undefined") {
+		QRMsg.textContent = "⚠️ This browser has no BarcodeDetector, so camera scanning may not find QR codes.  Try a QR image instead.";
+	}
+	ScanVideo.srcObject = ScanStream;
+	ScanVideo.hidden = false;
+	ScanBtn.textContent = "🛑 Stop scan";
+	await ScanVideo.play();
+	while (ScanStream !== null) {
+		try {
+			let found = await Coze.QRToCoze(ScanVideo);
+			StopScan();
+			InputMsg.value = JSON.stringify(found, null, 2);
+			await Verify();
+			return;
+		} catch (e) {
+			// No (readable) QR code in this frame.
+		}
+		await new Promise(r => setTimeout(r, 250));
+	}
+}
+
+function StopScan() {
+	if (ScanStream !== null) {
+		ScanStream.getTracks().forEach(t => t.stop());
+		ScanStream = null;
+	}
+	ScanVideo.hidden = true;
+	ScanBtn.textContent = "📷 Scan QR";
+}
+
+// ScanQR decodes a QR code image into InputMsg and verifies it.
+async function ScanQR(image) {
+	try {
+		let found = await Coze.QRToCoze(image);
+		InputMsg.value = JSON.stringify(found, null, 2);
+	} catch (e) {
+		Reset();
+		QRMsg.textContent = "❌ " + e.message;
+		return;
+	}
+	await Verify();
+}
+
+// ShowKey shows key in KeyOut.  Private keys are shown with `d` redacted and a
+// warning unless ShowPrivate is checked.
+function ShowKey(key, warning) {
+	ShownKey = key;
+	ShownWarning = warning;
+	let label = document.getElementById('ShowPrivateLabel');
+	if (key === null) {
+		KeyOut.textContent = "";
+		label.hidden = true;
+		return;
+	}
+	let shown = key;
+	let msg = Coze.isEmpty(warning) ? "" : warning + "\n";
+	label.hidden = !Coze.IsPrivate(key);
+	if (Coze.IsPrivate(key) && !ShowPrivate.checked) {
+		shown = Coze.PublicKey(key);
+		msg += "⚠️ Private key, d is redacted.  Never share a private key.\n";
+	}
+	KeyOut.textContent = msg + JSON.stringify(shown, null, " ");
+}
+
+// ShowVerifyReport shows the report of VerifyMeta for a coze that did not
+// verify, so that the failing stage is shown instead of only "invalid".
+async function ShowVerifyReport(coze, key) {
+	let report = await Coze.VerifyMeta(coze, key, {
+		allowRevoked: true
+	});
+	let failed = report.checks.find(c => c.status === "fail");
+	if (failed !== undefined) {
+		OutMsg.innerText = "❌ Invalid - " + failed.name + ": " + failed.message;
+	}
+	VerifyReport.textContent = "Verification report:\n" + report.checks.map(c =>
+		ReportIcons[c.status] + " " + c.name + (c.message === undefined ? "" : " - " + c.message)
+	).join("\n");
+}
+
+// ShowKeyReport shows the diagnostics report of a bad key.  Nothing is shown
+// for correct keys since the failure is then not from the key.
+async function ShowKeyReport(key) {
+	if (Coze.isEmpty(key) || typeof key !== "object") {
+		return;
+	}
+	let report = await Coze.Diagnose(key);
+	if (report.ok) {
+		return;
+	}
+	KeyReport.textContent = "Key diagnostics:\n" + report.checks.map(c =>
+		ReportIcons[c.status] + " " + c.name + (c.message === undefined ? "" : " - " + c.message)
+	).join("\n");
+}
+
+// CheckKey checks a key given as the message and shows its alg and tmb.
+async function CheckKey(key) {
+	try {
+		if (!(await Coze.Correct(key))) {
+			throw new Error("key is not correct.");
+		}
+		let tmb = await Coze.Thumbprint(key);
+		if (!Coze.isEmpty(key.tmb) && key.tmb !== tmb) {
+			throw new Error("tmb does not match the thumbprint of alg and x.");
+		}
+		OutMsg.innerText = "✅ Correct " + (Coze.IsPrivate(key) ? "private" : "public") + " key";
+		ShowKey(key);
+		if (Coze.IsRevoked(key)) {
+			RvkMsg.innerText = "⚠️ Key is revoked since " + new Date(key.rvk * 1000).toLocaleString()
+		}
+		MetaAlg.textContent = key.alg;
+		MetaTmb.textContent = tmb;
+		if ('iat' in key) {
+			MetaIat.textContent = key.iat;
+			MetaIats.textContent = "(" + new Date(key.iat * 1000).toLocaleString() + ")";
+		}
+	} catch (e) {
+		OutMsg.innerText = "❌ Invalid key - " + e.message;
+		await ShowKeyReport(key);
+	}
+}
+
+// ShowPay shows the cad and can of a pay, or of a coze without sig, which
+// needs a sig to verify or a key to sign.
+async function ShowPay(input) {
+	let coze = input.pay === undefined ? {
+		pay: input
+	} : input;
+	let alg = AlgSelect.value;
+	if (!Coze.isEmpty(coze.pay.alg)) {
+		alg = coze.pay.alg;
+	}
+	OutMsg.innerText = "ℹ️ Pay without sig.  Add a sig to verify, or a key to sign.";
+	try {
+		await Meta(coze, {
+			alg: alg
+		});
+	} catch (e) {
+		OutMsg.innerText = "❌ Error: " + e.message;
+	}
+}
+
+// VerifyArray verifies an array of cozies, each with the given key, or if no
+// key is given, each with its embedded key.
+async function VerifyArray(cozies) {
+	let results;
+	try {
+		let key = InputKey.value.trim() === "" ? undefined : Coze.ParseStrict(InputKey.value);
+		results = await Coze.VerifyCozeArray(cozies, key);
+	} catch (e) {
+		OutMsg.innerText = "❌ Error: " + e.message;
+		return;
+	}
+	let valid = results.filter(r => r.verified).length;
+	OutMsg.innerText = (valid === results.length ? "✅" : "❌") + ` Verified ${valid} of ${results.length}\n` + results.map((r, i) =>
+		`${i}: ` + (r.verified ? "✅ " + r.czd : "❌ " + (r.error === null ? "Invalid" : r.error.message))
+	).join("\n");
+}
+
 async function Sign() {
 	Reset();
 	console.log(InputMsg.value, InputKey.value);
 
 	try {
-		var cozeKey = JSON.parse(InputKey.value);
+		var cozeKey = Coze.ParseStrict(InputKey.value);
 	} catch (e) {
 		console.log();
 		OutMsg.innerText = "❌ Error parsing key - " + e;
@@ -111,8 +481,12 @@ async function Sign() {
 	}
 
 	try {
-		var coze = JSON.parse(InputMsg.value);
+		var coze = Coze.ParseStrict(InputMsg.value);
 	} catch (e) {
+		if (!(e instanceof SyntaxError)) {
+			OutMsg.innerText = "❌ Error parsing coze - " + e;
+			return;
+		}
 		// Assume string on JSON parse error. 
 		let pay = {
 			msg: InputMsg.value,
@@ -148,6 +522,7 @@ async function Sign() {
 	} catch (e) {
 		console.log();
 		OutMsg.innerText = "❌ Error: " + e;
+		await ShowKeyReport(cozeKey);
 		return;
 	}
 
@@ -172,6 +547,151 @@ async function GenKey() {
 
 	InputKey.value = JSON.stringify(newKey, null, " ");
 	console.log(newKey);
+	Remember();
+}
+
+// DigestDropped digests dropped files.  In "insert" mode, `pay.file` of the
+// message is set (See Coze.DigestPayFile), a single file as an object and
+// multiple files as an array.  In "compare" mode, the files are compared with
+// `pay.file` of the coze in the message (See Coze.MatchPayFile).
+async function DigestDropped(fileList) {
+	if (fileList.length === 0 || DropAbort !== null) {
+		return;
+	}
+	let files = fileList.length === 1 ? fileList[0] : fileList;
+	let compare = document.querySelector('input[name="DropMode"]:checked').value === "compare";
+	DropMsg.textContent = "";
+
+	// The message may be empty, a pay, or a coze.
+	let msg = {
+		pay: {}
+	};
+	let pay = msg.pay;
+	try {
+		if (InputMsg.value.trim() !== "") {
+			msg = Coze.ParseStrict(InputMsg.value);
+			pay = msg;
+			if (Coze.Detect(msg) === Coze.InputTypes.Coze && msg.pay === undefined) {
+				pay = msg.coze.pay; // Encapsulated coze.
+			} else if (typeof msg.pay === "object" && msg.pay !== null) {
+				pay = msg.pay;
+			}
+		} else if (compare) {
+			throw new Error("paste a coze with pay.file to compare.");
+		}
+		if (typeof pay !== "object" || pay === null || Array.isArray(pay)) {
+			throw new Error("message must be a JSON pay or coze.");
+		}
+	} catch (e) {
+		DropMsg.textContent = "❌ Error parsing message - " + e.message;
+		return;
+	}
+	let alg = Coze.isEmpty(pay.alg) ? AlgSelect.value : pay.alg;
+
+	DropAbort = new AbortController();
+	DropProgress.value = 0;
+	DropProgress.hidden = false;
+	DropCancelBtn.hidden = false;
+	let opts = {
+		signal: DropAbort.signal,
+		onProgress: (n, total) => {
+			DropProgress.value = total === 0 ? 1 : n / total;
+		},
+	};
+	try {
+		if (compare) {
+			let r = await Coze.MatchPayFile(pay, files, alg, opts);
+			DropMsg.textContent = (r.match ? "✅ Files match pay.file" : "❌ Files do not match pay.file") + "\n" +
+				r.files.map(f => (f.match ? "✅ " : "❌ ") + f.name + " " + f.dig).join("\n");
+			if (r.match) {
+				DropMsg.textContent += "\nVerify the coze to check its signature.";
+			}
+			return;
+		}
+		await Coze.DigestPayFile(pay, files, alg, opts);
+		InputMsg.value = JSON.stringify(msg, null, " ");
+		DropMsg.textContent = "✅ Digested with " + Coze.HashAlg(alg) + ".  Sign to sign the file digest.";
+	} catch (e) {
+		DropMsg.textContent = (DropAbort.signal.aborted ? "🛑 Canceled" : "❌ Error: " + e.message);
+	} finally {
+		DropAbort = null;
+		DropProgress.hidden = true;
+		DropCancelBtn.hidden = true;
+	}
+}
+
+// GenerateKey generates a key with the alg of GenAlgSelect and shows the
+// public key and tmb.
+async function GenerateKey() {
+	if (!GenKeySaved && !confirm("The generated key has not been downloaded.  Generate a new key anyway?")) {
+		return;
+	}
+	try {
+		GenCozeKey = await Coze.NewKey(GenAlgSelect.value);
+	} catch (e) {
+		GenOut.textContent = "❌ Error: " + e;
+		return;
+	}
+	GenKeySaved = false;
+	for (const id of ['DownloadKeyBtn', 'DownloadPublicKeyBtn', 'SignExampleBtn', 'RevokeKeyBtn']) {
+		document.getElementById(id).disabled = false;
+	}
+	GenOut.textContent = "";
+	ShowGenKey();
+}
+
+// ShowGenKey shows the tmb and public key of the generated key.
+function ShowGenKey() {
+	GenTmb.textContent = "tmb: " + GenCozeKey.tmb;
+	GenPublicKey.textContent = JSON.stringify(Coze.PublicKey(GenCozeKey), null, " ");
+}
+
+// DownloadGenKey downloads the generated key, or only the public key, as
+// `coze_key_<tmb8>.json` or `coze_key_<tmb8>_public.json`.
+function DownloadGenKey(onlyPublic) {
+	let name = "coze_key_" + GenCozeKey.tmb.substring(0, 8);
+	if (onlyPublic) {
+		Coze.DownloadJSON(Coze.PublicKey(GenCozeKey), name + "_public.json");
+		return;
+	}
+	Coze.DownloadJSON(GenCozeKey, name + ".json");
+	GenKeySaved = true;
+}
+
+// SignExample signs an example pay with the generated key and puts the coze
+// and public key into the inputs for verification.
+async function SignExample() {
+	try {
+		let coze = await Coze.Sign({
+			pay: {
+				msg: "Coze Rocks",
+				typ: "cyphr.me/msg",
+			}
+		}, GenCozeKey);
+		GenOut.textContent = JSON.stringify(coze, null, " ");
+		InputMsg.value = JSON.stringify(coze, null, " ");
+		InputKey.value = JSON.stringify(Coze.PublicKey(GenCozeKey), null, " ");
+	} catch (e) {
+		GenOut.textContent = "❌ Error: " + e;
+	}
+}
+
+// RevokeGenKey revokes the generated key and shows the revoke coze.  The
+// generated key, now with `rvk`, is no longer saved.
+async function RevokeGenKey() {
+	if (!confirm("Revoke the generated key?  A revoked key cannot sign.")) {
+		return;
+	}
+	try {
+		let coze = await Coze.Revoke(GenCozeKey, "Revoked from the Coze verifier.");
+		GenKeySaved = false;
+		document.getElementById('SignExampleBtn').disabled = true;
+		document.getElementById('RevokeKeyBtn').disabled = true;
+		GenOut.textContent = "⚠️ Revoke coze:\n" + JSON.stringify(coze, null, " ");
+		ShowGenKey();
+	} catch (e) {
+		GenOut.textContent = "❌ Error: " + e;
+	}
 }
 
 function ClearAll() {
@@ -183,6 +703,11 @@ function ClearAll() {
 function Reset() {
 	OutMsg.innerText = "❌ Invalid";
 	RvkMsg.innerText = "";
+	KeyReport.textContent = "";
+	VerifyReport.textContent = "";
+	ShowKey(null);
+	QRCanvas.hidden = true;
+	QRMsg.textContent = "";
 
 	// Meta
 	MetaAlg.textContent = "";
@@ -192,8 +717,10 @@ function Reset() {
 	MetaTyp.textContent = "";
 	MetaCan.textContent = "";
 	MetaCad.textContent = "";
+	MetaCadHex.textContent = "";
 	MetaSig.textContent = "";
 	MetaCzd.textContent = "";
+	MetaCzdHex.textContent = "";
 }
 
 
@@ -202,11 +729,7 @@ async function Meta(coze, key) {
 	let meta = {}
 
 	// Set fields for meta.  May be empty on "contextual" cozies.
-	if ('alg' in key) {
-		meta = await Coze.Meta(coze, key.alg);
-	} else {
-		meta = await Coze.Meta(coze);
-	}
+	meta = await Coze.Meta(coze, key);
 
 	console.log(meta)
 
@@ -221,4 +744,11 @@ async function Meta(coze, key) {
 	MetaCad.textContent = meta.cad;
 	MetaSig.textContent = meta.sig;
 	MetaCzd.textContent = meta.czd;
+	// Digests are also shown in hex, as used by many other tools.
+	if ('cad' in meta) {
+		MetaCadHex.textContent = "(hex: " + Coze.B64utToHex(meta.cad) + ")";
+	}
+	if ('czd' in meta) {
+		MetaCzdHex.textContent = "(hex: " + Coze.B64utToHex(meta.czd) + ")";
+	}
 }
\ No newline at end of file